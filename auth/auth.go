@@ -59,8 +59,8 @@ type Auth struct {
 	// Name is a friendly name for this auth config
 	Name string
 
-	// Type can be one of jwt or header
-	Type string `jsonschema:"title=Type,enum=jwt,enum=header"`
+	// Type can be one of jwt, header or session
+	Type string `jsonschema:"title=Type,enum=jwt,enum=header,enum=session"`
 
 	// The name of the cookie that holds the authentication token
 	Cookie string `jsonschema:"title=Cookie Name"`
@@ -90,6 +90,9 @@ type Auth struct {
 		Exists bool
 	}
 
+	// Session authentication
+	Session SessionConfig
+
 	// Magic.link authentication
 	// MagicLink struct {
 	// 	Secret string
@@ -122,6 +125,9 @@ func NewAuthHandlerFunc(ac Auth) (HandlerFunc, error) {
 		case "header":
 			h, err = HeaderHandler(ac)
 
+		case "session":
+			h, err = SessionHandler(ac)
+
 		// case "magiclink":
 		// 	h, err = MagicLinkHandler(ac, next)
 
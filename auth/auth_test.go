@@ -1,10 +1,15 @@
 package auth_test
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/dosco/graphjin/auth/v3"
+	"github.com/dosco/graphjin/core/v3"
+	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +42,121 @@ func TestJWTTokenInAuthorizationHeader(t *testing.T) {
 
 	assert.Equal(t, 1234567890, auth.UserIDInt(c))
 }
+
+type testSessionStore struct {
+	sessions map[string]*auth.Session
+}
+
+func (s *testSessionStore) Get(_ context.Context, id string) (*auth.Session, error) {
+	sess, ok := s.sessions[id]
+	if !ok || time.Now().After(sess.ExpiresAt) {
+		return nil, nil
+	}
+	return sess, nil
+}
+
+func (s *testSessionStore) Touch(_ context.Context, id string, expiresAt time.Time) error {
+	s.sessions[id].ExpiresAt = expiresAt
+	return nil
+}
+
+func (s *testSessionStore) Delete(_ context.Context, id string) error {
+	delete(s.sessions, id)
+	return nil
+}
+
+func TestSessionHandler(t *testing.T) {
+	store := &testSessionStore{sessions: map[string]*auth.Session{
+		"abc": {UserID: "42", Role: "admin", ExpiresAt: time.Now().Add(10 * time.Minute)},
+	}}
+
+	ac := auth.Auth{Type: "session", Cookie: "sid"}
+	ac.Session.TTL = time.Hour
+	ac.Session.SetStore(store)
+
+	ah, err := auth.NewAuthHandlerFunc(ac)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "https://test.com", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "abc"})
+
+	c, err := ah(nil, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, auth.UserIDInt(c))
+	assert.Equal(t, "admin", c.Value(core.UserRoleKey))
+
+	// the expiry slides when less than half the idle timeout is left
+	assert.True(t, time.Until(store.sessions["abc"].ExpiresAt) > 50*time.Minute)
+
+	// logout invalidates the session
+	lh, err := auth.LogoutHandler(ac)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "https://test.com/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "abc"})
+	lh.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "https://test.com", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "abc"})
+
+	c, err = ah(nil, req)
+	assert.NoError(t, err)
+	assert.False(t, auth.IsAuth(c))
+}
+
+func TestSessionHandlerNoStore(t *testing.T) {
+	_, err := auth.NewAuthHandlerFunc(auth.Auth{Type: "session"})
+	assert.Error(t, err)
+}
+
+func TestSupabaseToken(t *testing.T) {
+	ah, err := auth.JwtHandler(auth.Auth{
+		JWT: auth.JWTConfig{
+			Provider:  "supabase",
+			ProjectID: "abcdef",
+			Secret:    "super-secret-jwt-token",
+		},
+	})
+	assert.NoError(t, err)
+
+	claims := jwt.MapClaims{
+		"sub":          "8f0e3c9a-6b1d-4c57-9a2e-1f3b5d7c9e01",
+		"aud":          "authenticated",
+		"iss":          "https://abcdef.supabase.co/auth/v1",
+		"role":         "authenticated",
+		"user_role":    "editor",
+		"app_metadata": map[string]interface{}{"provider": "github"},
+		"exp":          time.Now().Add(time.Hour).Unix(),
+	}
+	sign := func(claims jwt.MapClaims) string {
+		tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).
+			SignedString([]byte("super-secret-jwt-token"))
+		assert.NoError(t, err)
+		return tok
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://test.com", nil)
+	req.Header.Set("Authorization", "Bearer "+sign(claims))
+
+	c, err := ah(nil, req)
+	assert.NoError(t, err)
+	assert.Equal(t, claims["sub"], auth.UserID(c))
+	assert.Equal(t, "editor", c.Value(core.UserRoleKey))
+	assert.Equal(t, "github", c.Value(core.UserIDProviderKey))
+
+	// tokens of other projects are rejected
+	claims["iss"] = "https://other.supabase.co/auth/v1"
+	req.Header.Set("Authorization", "Bearer "+sign(claims))
+
+	_, err = ah(nil, req)
+	assert.Error(t, err)
+}
+
+func TestFirebaseRequiresProjectID(t *testing.T) {
+	_, err := auth.JwtHandler(auth.Auth{
+		JWT: auth.JWTConfig{Provider: "firebase"},
+	})
+	assert.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 
 	"github.com/dosco/graphjin/auth/v3/provider"
+	"github.com/dosco/graphjin/core/v3"
 )
 
 const (
@@ -62,7 +63,12 @@ func JwtHandler(ac Auth) (HandlerFunc, error) {
 			}
 
 			ctx, err = jwtProvider.SetContextValues(ctx, claims)
-			return ctx, err
+			if err != nil {
+				return ctx, err
+			}
+			// used by the claim variables
+			ctx = context.WithValue(ctx, core.UserClaimsKey, map[string]interface{}(claims))
+			return ctx, nil
 		}
 		return nil, fmt.Errorf("invalid claims")
 	}, nil
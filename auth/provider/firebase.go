@@ -12,7 +12,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/dosco/graphjin/core/v3"
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
@@ -32,19 +31,37 @@ var firebasePublicKeys = firebasePKCache{
 }
 
 type FirebaseProvider struct {
-	aud    string
-	issuer string
+	aud         string
+	issuer      string
+	userIDClaim string
+	roleClaim   string
 }
 
-// NewFirebaseProvider creates a new Firebase JWT provider
+// NewFirebaseProvider creates a new Firebase JWT provider. Firebase ID tokens
+// have the project ID as the audience and are issued by
+// https://securetoken.google.com/<project id>
 func NewFirebaseProvider(config JWTConfig) (*FirebaseProvider, error) {
+	aud := config.Audience
+	if aud == "" {
+		aud = config.ProjectID
+	}
+	// without an audience tokens of any firebase project would be accepted
+	if aud == "" {
+		return nil, errors.New("firebase: project_id required")
+	}
 	issuer := config.Issuer
 	if issuer == "" {
-		issuer = firebaseIssuerPrefix + config.Audience
+		issuer = firebaseIssuerPrefix + aud
+	}
+	roleClaim := config.RoleClaim
+	if roleClaim == "" {
+		roleClaim = "role"
 	}
 	return &FirebaseProvider{
-		aud:    config.Audience,
-		issuer: issuer,
+		aud:         aud,
+		issuer:      issuer,
+		userIDClaim: config.UserIDClaim,
+		roleClaim:   roleClaim,
 	}, nil
 }
 
@@ -88,17 +105,9 @@ func (p *FirebaseProvider) VerifyIssuer(claims jwt.MapClaims) bool {
 	return iss == p.issuer
 }
 
-// SetContextValues sets the user ID and provider in the context
+// SetContextValues sets the user ID, role and sign-in provider in the context
 func (p *FirebaseProvider) SetContextValues(ctx context.Context, claims jwt.MapClaims) (context.Context, error) {
-	if claims == nil {
-		return ctx, errors.New("undefined claims")
-	}
-	sub, found := claims["sub"].(string)
-	if !found {
-		return ctx, errors.New("subject claim not found")
-	}
-	ctx = context.WithValue(ctx, core.UserIDKey, sub)
-	return ctx, nil
+	return setClaimValues(ctx, claims, p.userIDClaim, p.roleClaim, "firebase.sign_in_provider")
 }
 
 type firebaseKeyError struct {
@@ -3,15 +3,22 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/dosco/graphjin/core/v3"
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
 // JWTConfig struct contains JWT authentication related config values used by
 // the GraphJin service
 type JWTConfig struct {
-	// Provider can be one of auth0, firebase, jwks or other
-	Provider string `jsonschema:"title=JWT Provider,enum=auth0,enum=firebase,enum=jwks,enum=other"`
+	// Provider can be one of auth0, firebase, supabase, jwks or other
+	Provider string `jsonschema:"title=JWT Provider,enum=auth0,enum=firebase,enum=supabase,enum=jwks,enum=other"`
+
+	// Project ID of the Firebase project or the project ref of the Supabase
+	// project, used to set the expected issuer and audience of the tokens
+	ProjectID string `mapstructure:"project_id" jsonschema:"title=Project ID"`
 
 	// The secret key used for signing and encrypting the JWT token
 	Secret string `jsonschema:"title=JWT Secret Key"`
@@ -40,6 +47,15 @@ type JWTConfig struct {
 	// JWKSMinRefresh sets in minutes fallback value when tokens are refreshed, default
 	// to 60 minutes
 	JWKSMinRefresh int `mapstructure:"jwks_min_refresh" jsonschema:"title=JWKS Minimum Refresh Timeout (minutes)"`
+
+	// Claim holding the user id, nested claims are separated by a dot.
+	// Only used by the firebase and supabase providers. Defaults to 'sub'
+	UserIDClaim string `mapstructure:"user_id_claim" jsonschema:"title=User ID Claim"`
+
+	// Claim holding the role of the user, nested claims are separated by a
+	// dot. Only used by the firebase and supabase providers. Defaults to
+	// 'role' for firebase and 'user_role' for supabase
+	RoleClaim string `mapstructure:"role_claim" jsonschema:"title=Role Claim"`
 }
 
 // JWTProvider is the interface to define providers for doing JWT
@@ -58,6 +74,8 @@ func NewProvider(config JWTConfig) (JWTProvider, error) {
 		return NewAuth0Provider(config)
 	case "firebase":
 		return NewFirebaseProvider(config)
+	case "supabase":
+		return NewSupabaseProvider(config)
 	case "jwks":
 		return NewJWKSProvider(config)
 	default:
@@ -96,3 +114,44 @@ func getKey(config JWTConfig) (interface{}, error) {
 	}
 	return key, nil
 }
+
+// claimString returns the string value of a claim, nested claims are
+// separated by a dot. A claim with the full name is looked up first
+func claimString(claims jwt.MapClaims, name string) string {
+	if v, ok := claims[name].(string); ok {
+		return v
+	}
+	var v interface{} = map[string]interface{}(claims)
+	for _, k := range strings.Split(name, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[k]
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// setClaimValues sets the user ID, role and provider from the mapped claims
+func setClaimValues(ctx context.Context, claims jwt.MapClaims, userIDClaim, roleClaim, providerClaim string) (context.Context, error) {
+	if claims == nil {
+		return ctx, errors.New("undefined claims")
+	}
+	if userIDClaim == "" {
+		userIDClaim = "sub"
+	}
+	userID := claimString(claims, userIDClaim)
+	if userID == "" {
+		return ctx, fmt.Errorf("%s claim not found", userIDClaim)
+	}
+	ctx = context.WithValue(ctx, core.UserIDKey, userID)
+
+	if v := claimString(claims, roleClaim); v != "" {
+		ctx = context.WithValue(ctx, core.UserRoleKey, v)
+	}
+	if v := claimString(claims, providerClaim); v != "" {
+		ctx = context.WithValue(ctx, core.UserIDProviderKey, v)
+	}
+	return ctx, nil
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	supabaseAudience = "authenticated"
+	supabaseJWKSPath = "/.well-known/jwks.json"
+)
+
+type SupabaseProvider struct {
+	key         interface{}
+	cache       *keychainCache
+	aud         string
+	issuer      string
+	userIDClaim string
+	roleClaim   string
+}
+
+// NewSupabaseProvider creates a new Supabase JWT provider. Supabase tokens
+// are issued by https://<project ref>.supabase.co/auth/v1 for the audience
+// 'authenticated'. Tokens are verified with the JWT secret of the project
+// when set, otherwise with the signing keys from the JWKS endpoint of the
+// issuer
+func NewSupabaseProvider(config JWTConfig) (*SupabaseProvider, error) {
+	issuer := config.Issuer
+	if issuer == "" && config.ProjectID != "" {
+		issuer = fmt.Sprintf("https://%s.supabase.co/auth/v1", config.ProjectID)
+	}
+	if issuer == "" {
+		return nil, errors.New("supabase: project_id or issuer required")
+	}
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	aud := config.Audience
+	if aud == "" {
+		aud = supabaseAudience
+	}
+	roleClaim := config.RoleClaim
+	if roleClaim == "" {
+		roleClaim = "user_role"
+	}
+
+	p := &SupabaseProvider{
+		aud:         aud,
+		issuer:      issuer,
+		userIDClaim: config.UserIDClaim,
+		roleClaim:   roleClaim,
+	}
+
+	if config.Secret != "" || config.PubKey != "" {
+		key, err := getKey(config)
+		if err != nil {
+			return nil, err
+		}
+		p.key = key
+		return p, nil
+	}
+
+	jwksURL := config.JWKSURL
+	if jwksURL == "" {
+		jwksURL = issuer + supabaseJWKSPath
+	}
+	p.cache = newKeychainCache(jwksURL, config.JWKSRefresh, config.JWKSMinRefresh)
+	return p, nil
+}
+
+// KeyFunc returns a function that returns the key used to verify the JWT token
+func (p *SupabaseProvider) KeyFunc() jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if p.key != nil {
+			if _, ok := p.key.([]byte); ok {
+				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+				}
+			} else if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return p.key, nil
+		}
+
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+		}
+		kid, found := token.Header["kid"].(string)
+		if !found {
+			return nil, errors.New("kid not found")
+		}
+		return p.cache.getKey(kid)
+	}
+}
+
+// VerifyAudience checks if the audience claim is valid
+func (p *SupabaseProvider) VerifyAudience(claims jwt.MapClaims) bool {
+	if claims == nil {
+		return false
+	}
+	aud, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, a := range aud {
+		if a == p.aud {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIssuer checks if the issuer claim is valid
+func (p *SupabaseProvider) VerifyIssuer(claims jwt.MapClaims) bool {
+	if claims == nil {
+		return false
+	}
+	iss, err := claims.GetIssuer()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSuffix(iss, "/") == p.issuer
+}
+
+// SetContextValues sets the user ID, role and sign-in provider in the context
+func (p *SupabaseProvider) SetContextValues(ctx context.Context, claims jwt.MapClaims) (context.Context, error) {
+	return setClaimValues(ctx, claims, p.userIDClaim, p.roleClaim, "app_metadata.provider")
+}
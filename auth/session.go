@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+const defaultSessionCookie = "session"
+
+// Session is a server-side session looked up by the id in the session cookie
+type Session struct {
+	UserID   string
+	Role     string
+	Provider string
+
+	// ExpiresAt is zero for sessions that don't expire
+	ExpiresAt time.Time
+}
+
+// SessionStore is where the sessions are kept
+type SessionStore interface {
+	// Get returns the session, nil when it does not exist or has expired
+	Get(ctx context.Context, id string) (*Session, error)
+
+	// Touch moves the expiry of the session
+	Touch(ctx context.Context, id string, expiresAt time.Time) error
+
+	// Delete invalidates the session
+	Delete(ctx context.Context, id string) error
+}
+
+// CookieName returns the name of the cookie the auth reads its credentials
+// from, session auth defaults to 'session'. Empty when no cookie is used
+func (ac Auth) CookieName() string {
+	if ac.Cookie == "" && ac.Type == "session" {
+		return defaultSessionCookie
+	}
+	return ac.Cookie
+}
+
+// SessionConfig is the config of the session auth
+type SessionConfig struct {
+	// Store the sessions are looked up in: redis or table
+	Store string `jsonschema:"title=Session Store,enum=redis,enum=table"`
+
+	// Prefix of the session keys in Redis. Defaults to 'session:'
+	Prefix string `jsonschema:"title=Redis Key Prefix"`
+
+	// Table with the sessions, it needs the columns id, user_id, role and
+	// expires_at. Defaults to 'sessions'
+	Table string `jsonschema:"title=Sessions Table"`
+
+	// Database the sessions table is in. Defaults to the primary database
+	Database string `jsonschema:"title=Database"`
+
+	// Idle timeout of a session, every request moves the expiry by this much
+	// (sliding expiry). Zero leaves the expiry as it is
+	TTL time.Duration `jsonschema:"title=Idle Timeout"`
+
+	store SessionStore
+}
+
+// SetStore sets the store the session auth looks up sessions in
+func (sc *SessionConfig) SetStore(store SessionStore) {
+	sc.store = store
+}
+
+// SessionHandler is a middleware that looks up the session in the session
+// cookie and sets the user ID, role and provider of the session
+func SessionHandler(ac Auth) (HandlerFunc, error) {
+	store := ac.Session.store
+	if store == nil {
+		return nil, fmt.Errorf("auth '%s': no session store", ac.Name)
+	}
+	cookie := ac.CookieName()
+	ttl := ac.Session.TTL
+
+	return func(_ http.ResponseWriter, r *http.Request) (context.Context, error) {
+		ck, err := r.Cookie(cookie)
+		if err != nil || ck.Value == "" {
+			return nil, nil
+		}
+		c := r.Context()
+
+		sess, err := store.Get(c, ck.Value)
+		if err != nil {
+			return nil, err
+		}
+		if sess == nil || sess.UserID == "" {
+			return nil, nil
+		}
+
+		// Sessions are only touched once half the idle timeout has passed
+		// to save a write on every request
+		if ttl > 0 && !sess.ExpiresAt.IsZero() && time.Until(sess.ExpiresAt) < ttl/2 {
+			if err := store.Touch(c, ck.Value, time.Now().Add(ttl)); err != nil {
+				return nil, err
+			}
+		}
+
+		c = context.WithValue(c, core.UserIDKey, sess.UserID)
+		if sess.Role != "" {
+			c = context.WithValue(c, core.UserRoleKey, sess.Role)
+		}
+		if sess.Provider != "" {
+			c = context.WithValue(c, core.UserIDProviderKey, sess.Provider)
+		}
+		return c, nil
+	}, nil
+}
+
+// LogoutHandler invalidates the session in the session cookie and clears
+// the cookie
+func LogoutHandler(ac Auth) (http.Handler, error) {
+	store := ac.Session.store
+	if store == nil {
+		return nil, fmt.Errorf("auth '%s': no session store", ac.Name)
+	}
+	cookie := ac.CookieName()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if ck, err := r.Cookie(cookie); err == nil && ck.Value != "" {
+			if err := store.Delete(r.Context(), ck.Value); err != nil {
+				http.Error(w, "500 internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookie,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	}), nil
+}
@@ -24,7 +24,11 @@ By default, only safe (additive) operations are shown:
 - ADD CONSTRAINT
 - CREATE INDEX
 
-Use --destructive to also show DROP operations.`,
+Use --destructive to also show DROP operations.
+
+For MongoDB the tables in the config are used instead of db.graphql: missing
+collections are created with a JSON Schema validator and indexes are added
+for foreign keys, full-text columns and columns used in role filters.`,
 		Run: cmdDBDiff,
 	}
 	diffCmd.Flags().Bool("destructive", false, "Include DROP TABLE/COLUMN statements")
@@ -69,6 +73,34 @@ Use --destructive to also apply DROP operations.`,
 	syncCmd.Flags().Bool("yes", false, "Skip confirmation prompt")
 	c.AddCommand(syncCmd)
 
+	// Snapshot command - dump the discovered schema to a snapshot file
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write a schema snapshot of all configured databases",
+		Long: `Discover the schema of all configured databases and write it to a versioned
+snapshot file. The snapshot can be used to start GraphJin when a database is
+unreachable (see schema_snapshot) and as the baseline for 'db drift'.`,
+		Run: cmdDBSnapshot,
+	}
+	snapshotCmd.Flags().StringP("output", "o", "", "Output file path (default: schema_snapshot from config)")
+	c.AddCommand(snapshotCmd)
+
+	// Drift command - compare a schema snapshot against the database
+	driftCmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Show schema drift between a snapshot and the database",
+		Long: `Compare a schema snapshot against the live database schema (or a second
+snapshot using --against) and list the added and removed tables and columns,
+type changes and foreign key changes.
+
+Exits with status 1 when drift is found so it can be used to gate deployments.`,
+		Run: cmdDBDrift,
+	}
+	driftCmd.Flags().String("snapshot", "", "Snapshot file (default: schema_snapshot from config)")
+	driftCmd.Flags().String("against", "", "Compare against this snapshot file instead of the database")
+	driftCmd.Flags().String("format", "text", "Output format: text or json")
+	c.AddCommand(driftCmd)
+
 	// Seed command
 	seedCmd := &cobra.Command{
 		Use:   "seed",
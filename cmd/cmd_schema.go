@@ -261,6 +261,11 @@ func cmdDBSync(cmd *cobra.Command, args []string) {
 
 // computeSchemaDiff computes the diff between db.graphql and the database
 func computeSchemaDiff(destructive bool) ([]core.SchemaOperation, error) {
+	// MongoDB collections and indexes are synced from the tables in the config
+	if conf.DB.Type == "mongodb" {
+		return core.MongoSchemaSync(db, &conf.Core)
+	}
+
 	// Read db.graphql from config path
 	schemaPath := filepath.Join(cpath, "db.graphql")
 	schemaBytes, err := os.ReadFile(schemaPath)
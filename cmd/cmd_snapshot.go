@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/spf13/cobra"
+)
+
+// cmdDBSnapshot writes a schema snapshot of all configured databases
+func cmdDBSnapshot(cmd *cobra.Command, args []string) {
+	setup(cpath)
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	if outputPath == "" {
+		outputPath = snapshotPath()
+	}
+
+	ss, err := discoverSnapshot()
+	if err != nil {
+		log.Fatalf("Failed to discover schema: %s", err)
+	}
+
+	b, err := json.MarshalIndent(ss, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal snapshot: %s", err)
+	}
+
+	if err := os.WriteFile(outputPath, b, 0644); err != nil {
+		log.Fatalf("Failed to write snapshot file: %s", err)
+	}
+	log.Infof("Schema snapshot written: %s", outputPath)
+}
+
+// cmdDBDrift compares a schema snapshot against the database or another snapshot
+func cmdDBDrift(cmd *cobra.Command, args []string) {
+	setup(cpath)
+
+	snapshotFile, _ := cmd.Flags().GetString("snapshot")
+	against, _ := cmd.Flags().GetString("against")
+	format, _ := cmd.Flags().GetString("format")
+
+	if snapshotFile == "" {
+		snapshotFile = snapshotPath()
+	}
+
+	from, err := readSnapshot(snapshotFile)
+	if err != nil {
+		log.Fatalf("Failed to read snapshot: %s", err)
+	}
+
+	var to core.SchemaSnapshot
+	if against != "" {
+		to, err = readSnapshot(against)
+	} else {
+		to, err = discoverSnapshot()
+	}
+	if err != nil {
+		log.Fatalf("Failed to load schema: %s", err)
+	}
+
+	changes := core.DiffSchemaSnapshots(from, to)
+
+	switch format {
+	case "json":
+		if changes == nil {
+			changes = []core.SchemaChange{}
+		}
+		output, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal JSON: %s", err)
+		}
+		fmt.Println(string(output))
+	default:
+		if len(changes) == 0 {
+			log.Infof("No schema drift found")
+		}
+		for _, c := range changes {
+			fmt.Println(c.String())
+		}
+	}
+
+	if len(changes) != 0 {
+		os.Exit(1)
+	}
+}
+
+// snapshotPath returns the schema snapshot path from the config
+func snapshotPath() string {
+	name := conf.SchemaSnapshot
+	if name == "" {
+		name = "schema_snapshot.json"
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(cpath, name)
+}
+
+func readSnapshot(path string) (core.SchemaSnapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return core.SchemaSnapshot{}, err
+	}
+	return core.ParseSchemaSnapshot(b)
+}
+
+// discoverSnapshot discovers the live schema of all configured databases
+func discoverSnapshot() (core.SchemaSnapshot, error) {
+	if isMultiDBMode() {
+		connections, err := openMultiDBConnections()
+		if err != nil {
+			return core.SchemaSnapshot{}, err
+		}
+		defer func() {
+			for _, conn := range connections {
+				conn.Close() //nolint:errcheck
+			}
+		}()
+
+		dbTypes := make(map[string]string, len(connections))
+		for name := range connections {
+			dbTypes[name] = conf.Databases[name].Type
+		}
+		return core.DiscoverSchemaSnapshot(connections, dbTypes, conf.Blocklist)
+	}
+
+	initDB(true)
+
+	return core.DiscoverSchemaSnapshot(
+		map[string]*sql.DB{core.DefaultDBName: db},
+		map[string]string{core.DefaultDBName: conf.DB.Type},
+		conf.Blocklist)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dosco/graphjin/core/v3/internal/psql"
 	"github.com/dosco/graphjin/core/v3/internal/qcode"
 	"github.com/dosco/graphjin/core/v3/internal/sdata"
+	"github.com/dosco/graphjin/core/v3/internal/util"
 )
 
 type contextkey int
@@ -41,6 +42,9 @@ const (
 
 	// User role if pre-defined
 	UserRoleKey
+
+	// Claims of the users JWT (map[string]interface{}) used by the claim variables
+	UserClaimsKey
 )
 
 const (
@@ -57,6 +61,8 @@ type dbContext struct {
 	schema        *sdata.DBSchema // Processed schema with relationships
 	qcodeCompiler *qcode.Compiler // GraphQL to QCode compiler (validates against this DB's schema)
 	psqlCompiler  *psql.Compiler  // QCode to SQL compiler (generates this DB's dialect)
+	lazy          atomic.Bool     // Schema discovery deferred until first use
+	warm          sync.Mutex      // Held while a lazy database is discovered
 }
 
 // GraphJin struct is an instance of the GraphJin engine it holds all the required information like
@@ -69,8 +75,18 @@ type graphjinEngine struct {
 	allowList             *allow.List
 	encryptionKey         [32]byte
 	encryptionKeySet      bool
+	decryptionKeys        [][32]byte
+	replicas              map[string]*replicaSet
 	cache                 Cache
 	queries               sync.Map
+	canonical             sync.Map // query text to canonical hash, production only
+	plans                 *planCache
+	degraded              map[string]bool
+	prevDBInfos           map[string]*sdata.DBInfo // schemas before a reload
+	degradedMu            sync.Mutex
+	warmMu                sync.RWMutex // guards the table config replaced by lazy databases
+	namingStrategy        NamingStrategy
+	namer                 *util.Namer
 	roles                 map[string]*Role
 	roleStatement         string
 	roleStatementMetadata psql.Metadata
@@ -85,6 +101,7 @@ type graphjinEngine struct {
 	printFormat           []byte
 	opts                  []Option
 	done                  chan bool
+	readOnly              *atomic.Bool // shared with GraphJin to survive reloads
 
 	// All databases (including the primary/default) live here.
 	databases map[string]*dbContext
@@ -93,6 +110,12 @@ type graphjinEngine struct {
 
 	// Response cache provider (optional, set via OptionSetResponseCache)
 	responseCache ResponseCacheProvider
+	// Bulk loader for large inserts (optional, set via OptionSetBulkLoader)
+	bulkLoader BulkLoader
+	// Audit sink for mutations on audited tables (optional, set via OptionSetAuditSink)
+	auditSink AuditSink
+	// Metrics of the requests (optional, set via OptionSetMetrics)
+	metrics Metrics
 	// Cache key builder
 	cacheKeyBuilder *CacheKeyBuilder
 }
@@ -108,7 +131,7 @@ func (gj *graphjinEngine) primaryDB() *dbContext {
 // anyDatabaseReady returns true if at least one database has an initialized schema.
 func (gj *graphjinEngine) anyDatabaseReady() bool {
 	for _, ctx := range gj.databases {
-		if ctx.schema != nil {
+		if ctx.ready() {
 			return true
 		}
 	}
@@ -127,6 +150,9 @@ type GraphJin struct {
 
 	// Discovery document cache
 	discovery sync.Map // map[string]*DiscoveryDocument
+
+	// Mutations are rejected while set, see SetReadOnly
+	readOnly atomic.Bool
 }
 
 type Option func(*graphjinEngine) error
@@ -172,6 +198,32 @@ func (g *GraphJin) DatabaseNames() []string {
 	return gj.sortedDatabaseNames()
 }
 
+// EffectiveConfig returns a copy of the config the engine is running with,
+// after the databases are normalized and the defaults applied
+func (g *GraphJin) EffectiveConfig() (Config, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return Config{}, err
+	}
+	return *gj.conf.clone(), nil
+}
+
+// SchemaHashes returns the hex-encoded schema hash of each database with a
+// discovered schema, the same hash passed to OnSchemaChange callbacks
+func (g *GraphJin) SchemaHashes() map[string]string {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil
+	}
+	hashes := make(map[string]string, len(gj.databases))
+	for name, ctx := range gj.databases {
+		if !ctx.lazy.Load() && ctx.dbinfo != nil {
+			hashes[name] = fmt.Sprintf("%x", ctx.dbinfo.Hash())
+		}
+	}
+	return hashes
+}
+
 // fireAllSchemaCallbacks fires schema change callbacks for all databases with initialized schemas.
 func (g *GraphJin) fireAllSchemaCallbacks() {
 	gj, err := g.getEngine()
@@ -179,7 +231,7 @@ func (g *GraphJin) fireAllSchemaCallbacks() {
 		return
 	}
 	for name, ctx := range gj.databases {
-		if ctx.dbinfo != nil {
+		if !ctx.lazy.Load() && ctx.dbinfo != nil {
 			g.fireSchemaCallbacks(name, fmt.Sprintf("%x", ctx.dbinfo.Hash()))
 		}
 	}
@@ -194,7 +246,7 @@ func (g *GraphJin) generateAllDiscovery() {
 	}
 	ctx := context.Background()
 	for name, dbCtx := range gj.databases {
-		if dbCtx.schema == nil {
+		if !dbCtx.ready() {
 			continue
 		}
 		start := time.Now()
@@ -310,12 +362,18 @@ func (g *GraphJin) newGraphJin(conf *Config,
 		fs:          fs,
 		trace:       &tracer{},
 		done:        g.done,
+		readOnly:    &g.readOnly,
 	}
 
 	if gj.conf.DisableProdSecurity {
 		gj.prodSec = false
 	}
 
+	// on a reload only the tables that changed are discovered again
+	if prev, err1 := g.getEngine(); err1 == nil {
+		gj.prevDBInfos = prev.collectDBInfos()
+	}
+
 	// ordering of these initializer matter, do not re-order!
 
 	if err = gj.initCache(); err != nil {
@@ -359,6 +417,7 @@ func (g *GraphJin) newGraphJin(conf *Config,
 			return
 		}
 	}
+	gj.initNamer()
 
 	// Phase 1: Discover all databases (get raw schema metadata)
 	if err = gj.discoverAllDatabases(); err != nil {
@@ -388,6 +447,14 @@ func (g *GraphJin) newGraphJin(conf *Config,
 		if err = gj.initIntro(); err != nil {
 			return
 		}
+
+		if err = gj.initPlanCache(); err != nil {
+			return
+		}
+	}
+
+	if conf.DefaultDeny {
+		gj.logAccessReport()
 	}
 
 	if conf.SecretKey != "" {
@@ -396,6 +463,14 @@ func (g *GraphJin) newGraphJin(conf *Config,
 		gj.encryptionKeySet = true
 	}
 
+	// the current key is tried first followed by the previous keys
+	gj.decryptionKeys = append(gj.decryptionKeys, gj.encryptionKey)
+	for _, k := range conf.PreviousSecretKeys {
+		if k != "" {
+			gj.decryptionKeys = append(gj.decryptionKeys, sha256.Sum256([]byte(k)))
+		}
+	}
+
 	g.Store(gj)
 	return
 }
@@ -449,6 +524,8 @@ func OptionSetResponseCache(cache ResponseCacheProvider) Option {
 
 type Error struct {
 	Message string `json:"message"`
+	// Extensions holds the code and details of errors such as constraint violations
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
 // Result struct contains the output of the GraphQL function this includes resulting json from the
@@ -459,19 +536,22 @@ type Result struct {
 	name         string
 	sql          string
 	role         string
+	database     string
 	cacheControl string
 	cacheHit     bool
+	deprecation  *QueryDeprecation
 	Vars         json.RawMessage   `json:"-"`
 	Data         json.RawMessage   `json:"data,omitempty"`
 	Hash         [sha256.Size]byte `json:"-"`
 	Errors       []Error           `json:"errors,omitempty"`
 	Validation   []qcode.ValidErr  `json:"validation,omitempty"`
-	// Extensions   *extensions     `json:"extensions,omitempty"`
+	Extensions   *Extensions       `json:"extensions,omitempty"`
 }
 
 // RequestConfig is used to pass request specific config values to the GraphQL and Subscribe functions. Dynamic variables can be set here.
 type RequestConfig struct {
 	ns *string
+	db *string
 
 	// APQKey is set when using GraphJin with automatic persisted queries
 	APQKey string
@@ -496,6 +576,20 @@ func (rc *RequestConfig) GetNamespace() (string, bool) {
 	return "", false
 }
 
+// SetDatabase is used to run the whole request against one of the configured databases,
+// for example a reporting replica or a tenant shard with the same tables
+func (rc *RequestConfig) SetDatabase(name string) {
+	rc.db = &name
+}
+
+// GetDatabase is used to get the database the request is run against
+func (rc *RequestConfig) GetDatabase() (string, bool) {
+	if rc.db != nil {
+		return *rc.db, true
+	}
+	return "", false
+}
+
 // GraphQL function is our main function it takes a GraphQL query compiles it
 // to SQL and executes returning the resulting JSON.
 //
@@ -523,6 +617,7 @@ func (g *GraphJin) GraphQL(c context.Context,
 	// get query from apq cache if apq key exists
 	if rc != nil && rc.APQKey != "" {
 		queryBytes, inCache = gj.cache.Get(APQ_PX + rc.APQKey)
+		gj.cacheLookup(CacheAPQ, inCache)
 	}
 
 	// query not found in apq cache so use original query
@@ -537,11 +632,22 @@ func (g *GraphJin) GraphQL(c context.Context,
 	}
 	r := gj.newGraphqlReq(rc, h.Operation, h.Name, queryBytes, vars)
 
+	// federation router queries are answered by looking up entities
+	// by primary key
+	if gj.conf.EnableFederation && h.Operation == "query" && isFederationQuery(queryBytes) {
+		var fr Result
+		var ok bool
+		if fr, ok, err = gj.federationQuery(c1, r); ok || err != nil {
+			res = &fr
+			return
+		}
+	}
+
 	// if production security enabled then get query and metadata
 	// from allow list
 	if gj.prodSec {
 		var item allow.Item
-		item, err = gj.allowList.GetByName(h.Name, true)
+		item, err = gj.getAllowItem(r.namespace, h.Name, true)
 		if err != nil {
 			err = fmt.Errorf("%w: %s", err, h.Name)
 			return
@@ -601,13 +707,13 @@ func (g *GraphJin) GraphQLByName(c context.Context,
 	c1, span := gj.spanStart(c, "GraphJin Query")
 	defer span.End()
 
-	item, err := gj.allowList.GetByName(name, gj.prod)
+	r := gj.newGraphqlReq(rc, "", name, nil, vars)
+
+	item, err := gj.getAllowItem(r.namespace, name, gj.prod)
 	if err != nil {
 		err = fmt.Errorf("%w: %s", err, name)
 		return
 	}
-
-	r := gj.newGraphqlReq(rc, "", name, nil, vars)
 	r.Set(item)
 
 	res, err = gj.queryWithResult(c1, r)
@@ -638,6 +744,14 @@ type GraphqlReq struct {
 	vars          json.RawMessage
 	aschema       map[string]json.RawMessage
 	requestconfig *RequestConfig
+	// database is set when the request config overrides the database
+	database string
+	// dynamic is set for generated queries (eg. CRUD routes) that must
+	// be compiled on every request even in production mode
+	dynamic     bool
+	deprecation *QueryDeprecation
+	// approved is the staged statement of an approved mutation
+	approved string
 }
 
 type GraphqlResponse struct {
@@ -667,6 +781,9 @@ func (gj *graphjinEngine) newGraphqlReq(rc *RequestConfig,
 	} else {
 		r.namespace = gj.namespace
 	}
+	if rc != nil && rc.db != nil {
+		r.database = *rc.db
+	}
 	return
 }
 
@@ -677,6 +794,7 @@ func (r *GraphqlReq) Set(item allow.Item) {
 	r.name = item.Name
 	r.query = item.Query
 	r.aschema = item.ActionJSON
+	r.deprecation = newQueryDeprecation(item.Deprecation)
 }
 
 // GraphQL function is our main function it takes a GraphQL query compiles it
@@ -690,9 +808,17 @@ func (gj *graphjinEngine) query(c context.Context, r GraphqlReq) (
 	resp GraphqlResponse, err error,
 ) {
 	resp.res = Result{
-		namespace: r.namespace,
-		operation: r.operation,
-		name:      r.name,
+		namespace:   r.namespace,
+		operation:   r.operation,
+		name:        r.name,
+		deprecation: r.deprecation,
+	}
+
+	if r.deprecation != nil {
+		if err = r.deprecation.checkSunset(r.name, time.Now()); err != nil {
+			resp.res.Errors = newError(err)
+			return
+		}
 	}
 
 	if !gj.prodSec && r.name == "IntrospectionQuery" {
@@ -705,16 +831,27 @@ func (gj *graphjinEngine) query(c context.Context, r GraphqlReq) (
 		return
 	}
 
+	if r.operation == qcode.QTMutation && gj.isReadOnly() {
+		err = ErrReadOnly
+		resp.res.Errors = newError(err)
+		return
+	}
+
 	if !gj.anyDatabaseReady() {
 		err = fmt.Errorf("no tables found in any database; schema not initialized")
 		return
 	}
 
+	c, cancel := gj.withTimeout(c, r.operation)
+	defer cancel()
+
 	s, err := newGState(c, gj, r)
 	if err != nil {
 		return
 	}
-	err = s.compileAndExecuteWrapper(c)
+	start := time.Now()
+	err = s.constraintError(s.compileAndExecuteWrapper(c))
+	s.recordMetrics(start, err)
 
 	resp.qc = s.qcode()
 	resp.res.sql = s.sql()
@@ -728,7 +865,18 @@ func (gj *graphjinEngine) query(c context.Context, r GraphqlReq) (
 	resp.res.Data = json.RawMessage(s.data)
 	resp.res.Hash = s.dhash
 	resp.res.role = s.role
+	resp.res.database = s.databaseNames()
 	resp.res.cacheHit = s.cacheHit
+	if s.truncated || len(s.rolledBack) != 0 || len(s.nplus1) != 0 ||
+		len(s.omitted) != 0 || s.pending != "" {
+		resp.res.Extensions = &Extensions{
+			Truncated:  s.truncated,
+			RolledBack: s.rolledBack,
+			NPlusOne:   s.nplus1,
+			Omitted:    s.omitted,
+			Pending:    s.pending,
+		}
+	}
 
 	if err != nil {
 		resp.res.Errors = newError(err)
@@ -824,6 +972,10 @@ func getFS(conf *Config) (fs FS, err error) {
 
 // newError creates a new error list
 func newError(err error) (errList []Error) {
+	var ce *ConstraintError
+	if errors.As(err, &ce) {
+		return []Error{{Message: ce.Message, Extensions: ce.extensions()}}
+	}
 	errList = []Error{{Message: err.Error()}}
 	return
 }
@@ -938,7 +1090,7 @@ func (g *GraphJin) SchemaReady() bool {
 		return false
 	}
 	for _, ctx := range gj.databases {
-		if ctx.schema != nil {
+		if ctx.ready() {
 			return true
 		}
 	}
@@ -964,7 +1116,7 @@ func (gj *graphjinEngine) getTables(database string) []TableInfo {
 			continue
 		}
 		ctx := gj.databases[dbName]
-		if ctx.schema == nil {
+		if !ctx.ready() {
 			continue
 		}
 		tables := ctx.schema.GetTables()
@@ -1240,6 +1392,8 @@ type OperationPermission struct {
 	Columns          []string          `json:"columns,omitempty"`
 	Presets          map[string]string `json:"presets,omitempty"`
 	DisableFunctions bool              `json:"disable_functions,omitempty"`
+	AllowLock        bool              `json:"allow_lock,omitempty"`
+	BlockOperators   []string          `json:"block_operators,omitempty"`
 }
 
 // TablePermissions represents per-table permission details for a role
@@ -1256,10 +1410,11 @@ type TablePermissions struct {
 
 // RoleAudit represents the complete permission audit for a role
 type RoleAudit struct {
-	Name     string             `json:"name"`
-	Match    string             `json:"match,omitempty"`
-	Tables   []TablePermissions `json:"tables"`
-	FixGuide string             `json:"fix_guide"`
+	Name           string             `json:"name"`
+	Match          string             `json:"match,omitempty"`
+	BlockOperators []string           `json:"block_operators,omitempty"`
+	Tables         []TablePermissions `json:"tables"`
+	FixGuide       string             `json:"fix_guide"`
 }
 
 // ExplainQuery compiles a GraphQL query without executing it.
@@ -1692,7 +1847,7 @@ func (gj *graphjinEngine) findDatabasesWithTable(tableName string) []string {
 	var matches []string
 	for _, dbName := range gj.sortedDatabaseNames() {
 		ctx := gj.databases[dbName]
-		if ctx == nil || ctx.schema == nil {
+		if ctx == nil || !ctx.ready() {
 			continue
 		}
 		if _, err := ctx.schema.Find("", tableName); err == nil {
@@ -1734,20 +1889,22 @@ func (gj *graphjinEngine) auditRolePermissions(roleName string) (*RoleAudit, err
 	}
 
 	audit := &RoleAudit{
-		Name:  role.Name,
-		Match: role.Match,
+		Name:           role.Name,
+		Match:          role.Match,
+		BlockOperators: role.BlockOperators,
 	}
 
+	deny := gj.conf.DefaultDeny
 	for _, rt := range role.Tables {
 		tp := TablePermissions{
 			TableName: rt.Name,
 			Schema:    rt.Schema,
 			ReadOnly:  rt.ReadOnly,
-			Query:     buildQueryPermission(rt.Query),
-			Insert:    buildInsertPermission(rt.Insert, rt.ReadOnly),
-			Update:    buildUpdatePermission(rt.Update, rt.ReadOnly),
-			Upsert:    buildUpsertPermission(rt.Upsert, rt.ReadOnly),
-			Delete:    buildDeletePermission(rt.Delete, rt.ReadOnly),
+			Query:     buildQueryPermission(rt.Query, deny),
+			Insert:    buildInsertPermission(rt.Insert, rt.ReadOnly, deny),
+			Update:    buildUpdatePermission(rt.Update, rt.ReadOnly, deny),
+			Upsert:    buildUpsertPermission(rt.Upsert, rt.ReadOnly, deny),
+			Delete:    buildDeletePermission(rt.Delete, rt.ReadOnly, deny),
 		}
 		audit.Tables = append(audit.Tables, tp)
 	}
@@ -1760,9 +1917,9 @@ func (gj *graphjinEngine) auditRolePermissions(roleName string) (*RoleAudit, err
 	return audit, nil
 }
 
-func buildQueryPermission(q *Query) *OperationPermission {
+func buildQueryPermission(q *Query, deny bool) *OperationPermission {
 	if q == nil {
-		return &OperationPermission{Allowed: true}
+		return &OperationPermission{Allowed: !deny, Blocked: deny}
 	}
 	return &OperationPermission{
 		Allowed:          !q.Block,
@@ -1771,15 +1928,17 @@ func buildQueryPermission(q *Query) *OperationPermission {
 		Filters:          q.Filters,
 		Columns:          q.Columns,
 		DisableFunctions: q.DisableFunctions,
+		AllowLock:        q.AllowLock,
+		BlockOperators:   q.BlockOperators,
 	}
 }
 
-func buildInsertPermission(ins *Insert, readOnly bool) *OperationPermission {
+func buildInsertPermission(ins *Insert, readOnly, deny bool) *OperationPermission {
 	if readOnly {
 		return &OperationPermission{Allowed: false, Blocked: true}
 	}
 	if ins == nil {
-		return &OperationPermission{Allowed: true}
+		return &OperationPermission{Allowed: !deny, Blocked: deny}
 	}
 	return &OperationPermission{
 		Allowed: !ins.Block,
@@ -1790,12 +1949,12 @@ func buildInsertPermission(ins *Insert, readOnly bool) *OperationPermission {
 	}
 }
 
-func buildUpdatePermission(upd *Update, readOnly bool) *OperationPermission {
+func buildUpdatePermission(upd *Update, readOnly, deny bool) *OperationPermission {
 	if readOnly {
 		return &OperationPermission{Allowed: false, Blocked: true}
 	}
 	if upd == nil {
-		return &OperationPermission{Allowed: true}
+		return &OperationPermission{Allowed: !deny, Blocked: deny}
 	}
 	return &OperationPermission{
 		Allowed: !upd.Block,
@@ -1806,12 +1965,12 @@ func buildUpdatePermission(upd *Update, readOnly bool) *OperationPermission {
 	}
 }
 
-func buildUpsertPermission(ups *Upsert, readOnly bool) *OperationPermission {
+func buildUpsertPermission(ups *Upsert, readOnly, deny bool) *OperationPermission {
 	if readOnly {
 		return &OperationPermission{Allowed: false, Blocked: true}
 	}
 	if ups == nil {
-		return &OperationPermission{Allowed: true}
+		return &OperationPermission{Allowed: !deny, Blocked: deny}
 	}
 	return &OperationPermission{
 		Allowed: !ups.Block,
@@ -1822,12 +1981,12 @@ func buildUpsertPermission(ups *Upsert, readOnly bool) *OperationPermission {
 	}
 }
 
-func buildDeletePermission(del *Delete, readOnly bool) *OperationPermission {
+func buildDeletePermission(del *Delete, readOnly, deny bool) *OperationPermission {
 	if readOnly {
 		return &OperationPermission{Allowed: false, Blocked: true}
 	}
 	if del == nil {
-		return &OperationPermission{Allowed: true}
+		return &OperationPermission{Allowed: !deny, Blocked: deny}
 	}
 	return &OperationPermission{
 		Allowed: !del.Block,
@@ -1839,18 +1998,20 @@ func buildDeletePermission(del *Delete, readOnly bool) *OperationPermission {
 
 // SavedQueryInfo represents a saved query from the allow list
 type SavedQueryInfo struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace,omitempty"`
-	Operation string `json:"operation"` // query or mutation
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Operation   string            `json:"operation"` // query or mutation
+	Deprecation *QueryDeprecation `json:"deprecation,omitempty"`
 }
 
 // SavedQueryDetails represents full details of a saved query
 type SavedQueryDetails struct {
-	Name      string                 `json:"name"`
-	Namespace string                 `json:"namespace,omitempty"`
-	Operation string                 `json:"operation"`
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables,omitempty"`
+	Name        string                 `json:"name"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Operation   string                 `json:"operation"`
+	Query       string                 `json:"query"`
+	Variables   map[string]interface{} `json:"variables,omitempty"`
+	Deprecation *QueryDeprecation      `json:"deprecation,omitempty"`
 }
 
 // ListSavedQueries returns all saved queries from the allow list
@@ -1868,9 +2029,10 @@ func (g *GraphJin) ListSavedQueries() ([]SavedQueryInfo, error) {
 	result := make([]SavedQueryInfo, 0, len(items))
 	for _, item := range items {
 		result = append(result, SavedQueryInfo{
-			Name:      item.Name,
-			Namespace: item.Namespace,
-			Operation: item.Operation,
+			Name:        item.Name,
+			Namespace:   item.Namespace,
+			Operation:   item.Operation,
+			Deprecation: newQueryDeprecation(item.Deprecation),
 		})
 	}
 	return result, nil
@@ -1889,10 +2051,11 @@ func (g *GraphJin) GetSavedQuery(name string) (*SavedQueryDetails, error) {
 	}
 
 	details := &SavedQueryDetails{
-		Name:      item.Name,
-		Namespace: item.Namespace,
-		Operation: item.Operation,
-		Query:     string(item.Query),
+		Name:        item.Name,
+		Namespace:   item.Namespace,
+		Operation:   item.Operation,
+		Query:       string(item.Query),
+		Deprecation: newQueryDeprecation(item.Deprecation),
 	}
 
 	// Parse action JSON if present
@@ -1992,12 +2155,13 @@ func splitFragmentName(name string) (string, string) {
 
 // DatabaseStats represents statistics and info for a database connection
 type DatabaseStats struct {
-	Name       string     `json:"name"`
-	Type       string     `json:"type"`
-	IsDefault  bool       `json:"isDefault"`
-	ReadOnly   bool       `json:"readOnly"`
-	TableCount int        `json:"tableCount"`
-	Pool       *PoolStats `json:"pool,omitempty"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	IsDefault  bool          `json:"isDefault"`
+	ReadOnly   bool          `json:"readOnly"`
+	TableCount int           `json:"tableCount"`
+	Pool       *PoolStats    `json:"pool,omitempty"`
+	Replicas   *ReplicaStats `json:"replicas,omitempty"`
 }
 
 // PoolStats represents connection pool statistics
@@ -2035,7 +2199,7 @@ func (g *GraphJin) GetAllDatabaseStats() []DatabaseStats {
 		}
 
 		// Get table count from schema
-		if ctx.schema != nil {
+		if ctx.ready() {
 			tables := ctx.schema.GetTables()
 			count := 0
 			for _, t := range tables {
@@ -2061,6 +2225,10 @@ func (g *GraphJin) GetAllDatabaseStats() []DatabaseStats {
 			}
 		}
 
+		if rs, ok := gj.replicas[name]; ok {
+			ds.Replicas = rs.stats()
+		}
+
 		stats = append(stats, ds)
 	}
 
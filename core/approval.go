@@ -0,0 +1,430 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/dialect"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// ErrNotPending is returned when approving or rejecting a mutation that
+// does not exist or was already reviewed
+var ErrNotPending = errors.New("approval: mutation not found or not pending")
+
+const (
+	approvalPending  = "pending"
+	approvalApproved = "approved"
+	approvalRejected = "rejected"
+)
+
+// PendingMutation is a mutation staged until it is approved
+type PendingMutation struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Namespace string    `json:"namespace,omitempty"`
+	Database  string    `json:"database"`
+	Name      string    `json:"name,omitempty"`
+	Query     string    `json:"query"`
+	// Statement is the compiled SQL of the mutation
+	Statement string          `json:"statement"`
+	Vars      json.RawMessage `json:"variables,omitempty"`
+	// Variables set by the server for the request (eg. header variables)
+	RequestVars json.RawMessage `json:"request_variables,omitempty"`
+	UserID      json.RawMessage `json:"user_id,omitempty"`
+	Role        string          `json:"role"`
+}
+
+// needsApproval returns true if the mutation changes a table listed in
+// the approvals config
+func (s *gstate) needsApproval() bool {
+	conf := s.gj.conf.Approvals
+	qc := s.cs.st.qc
+
+	if len(conf.Tables) == 0 || conf.Table == "" {
+		return false
+	}
+	if qc.Type != qcode.QTMutation || qc.SType == qcode.QTCall {
+		return false
+	}
+
+	for _, m := range qc.Mutates {
+		if _, ok := auditOps[m.Type]; !ok {
+			continue
+		}
+		if slices.Contains(conf.Tables, m.Ti.Name) ||
+			slices.Contains(conf.Tables, m.Ti.Schema+"."+m.Ti.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// stageMutation saves the compiled mutation and its variables as pending
+// instead of running it, the id of the pending mutation is returned in the
+// response extensions
+func (s *gstate) stageMutation(c context.Context) (err error) {
+	dbCtx := s.gj.primaryDB()
+	if dbCtx.dbtype == "mongodb" {
+		return fmt.Errorf("approval: not supported by %s", dbCtx.dbtype)
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	id := hex.EncodeToString(b)
+
+	dbName := s.database
+	if dbName == "" {
+		dbName = s.gj.defaultDB
+	}
+
+	userID, err := json.Marshal(c.Value(UserIDKey))
+	if err != nil {
+		return
+	}
+
+	var reqVars []byte
+	if rc := s.r.requestconfig; rc != nil && len(rc.Vars) != 0 {
+		vars := make(map[string]interface{}, len(rc.Vars))
+		for k := range rc.Vars {
+			vars[k], _ = rc.varValue(k)
+		}
+		if reqVars, err = json.Marshal(vars); err != nil {
+			return
+		}
+	}
+
+	cols := []string{
+		"id", "created_at", "status", "namespace", "db_name", "query_name",
+		"query", "statement", "variables", "request_variables", "user_id", "user_role",
+	}
+	vals := []interface{}{
+		id, time.Now().UTC(), approvalPending, s.r.namespace, dbName, s.r.name,
+		string(s.r.query), s.cs.st.sql, nullString(s.r.vars), nullString(reqVars),
+		string(userID), s.role,
+	}
+
+	d := dbCtx.psqlCompiler.GetDialect()
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO ` + quotedTable(d, s.gj.conf.Approvals.Table) + ` (`)
+	for i, col := range cols {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(d.QuoteIdentifier(col))
+	}
+	sb.WriteString(`) VALUES (`)
+	for i := range cols {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(d.BindVar(i + 1))
+	}
+	sb.WriteString(`)`)
+
+	q, vals, err := prepareQueryArgsForDB(dbCtx.dbtype, sb.String(), vals)
+	if err != nil {
+		return
+	}
+
+	// use the request transaction when it is on the database of the
+	// approvals table
+	if tx := s.tx(); tx != nil && s.getTargetDBCtx() == dbCtx {
+		_, err = tx.ExecContext(c, q, vals...)
+	} else {
+		_, err = dbCtx.db.ExecContext(c, q, vals...)
+	}
+	if err != nil {
+		return fmt.Errorf("approval: %w", err)
+	}
+
+	s.pending = id
+	return
+}
+
+// PendingMutations returns the mutations waiting to be approved
+func (g *GraphJin) PendingMutations(c context.Context) ([]PendingMutation, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	return gj.pendingMutations(c, nil, "")
+}
+
+// ApproveMutation runs a pending mutation. It must be approved by a role
+// other than the one that staged it and in the approver roles of the
+// approvals config, admin when none are listed. The mutation runs as the user that staged it and
+// must still compile to the staged statement.
+func (g *GraphJin) ApproveMutation(c context.Context, id string) (res *Result, err error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return
+	}
+
+	c1, span := gj.spanStart(c, "Approve Mutation")
+	defer span.End()
+
+	dbCtx := gj.primaryDB()
+	tx, err := dbCtx.db.BeginTx(c1, nil)
+	if err != nil {
+		span.Error(err)
+		return
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	pm, err := gj.reviewMutation(c1, tx, id, approvalApproved)
+	if err != nil {
+		span.Error(err)
+		return
+	}
+
+	rc := &RequestConfig{}
+	rc.SetNamespace(pm.Namespace)
+	if len(pm.RequestVars) != 0 {
+		if err = json.Unmarshal(pm.RequestVars, &rc.Vars); err != nil {
+			return
+		}
+	}
+	// the review and the mutation commit together when they are on the
+	// same database. Otherwise the review commits first so the mutation
+	// runs at most once, when it fails the row stays approved and the
+	// mutation has to be staged again
+	sameDB := pm.Database == gj.defaultDB
+	if sameDB {
+		rc.Tx = tx
+	} else if err = tx.Commit(); err != nil {
+		span.Error(err)
+		return
+	}
+
+	r := gj.newGraphqlReq(rc, "mutation", pm.Name, []byte(pm.Query), pm.Vars)
+	r.database = pm.Database
+	r.dynamic = true
+	r.approved = pm.Statement
+
+	// the staged role is set on the context so userRole returns it
+	// without running the roles query again
+	var userID interface{}
+	if err = json.Unmarshal(pm.UserID, &userID); err != nil {
+		return
+	}
+	c2 := withUserRole(c1, userID, pm.Role)
+
+	resp, err := gj.query(c2, r)
+	res = &resp.res
+	if err == nil && len(res.Errors) != 0 {
+		err = errors.New(res.Errors[0].Message)
+	}
+	if err != nil {
+		span.Error(err)
+		return
+	}
+
+	if sameDB {
+		if err = tx.Commit(); err != nil {
+			span.Error(err)
+		}
+	}
+	return
+}
+
+// RejectMutation marks a pending mutation as rejected so it cannot be
+// approved, the same roles that can approve it can reject it
+func (g *GraphJin) RejectMutation(c context.Context, id string) error {
+	gj, err := g.getEngine()
+	if err != nil {
+		return err
+	}
+
+	tx, err := gj.primaryDB().db.BeginTx(c, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = gj.reviewMutation(c, tx, id, approvalRejected); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// reviewMutation checks the caller can review the pending mutation and
+// sets its status, the update only succeeds for a pending mutation so
+// it cannot be reviewed twice
+func (gj *graphjinEngine) reviewMutation(c context.Context,
+	tx *sql.Tx,
+	id, status string,
+) (pm PendingMutation, err error) {
+	conf := gj.conf.Approvals
+	if conf.Table == "" {
+		err = errors.New("approval: no approvals table configured")
+		return
+	}
+
+	list, err := gj.pendingMutations(c, tx, id)
+	if err != nil {
+		return
+	}
+	if len(list) == 0 {
+		err = ErrNotPending
+		return
+	}
+	pm = list[0]
+
+	// the reviewer role can come from the roles query, it runs in the
+	// review transaction
+	role, err := gj.userRole(c, &RequestConfig{Tx: tx})
+	if err != nil {
+		return
+	}
+	if role == pm.Role {
+		err = fmt.Errorf("approval: must be reviewed by a role other than '%s'", pm.Role)
+		return
+	}
+	if !slices.Contains(approverRoles(conf), role) {
+		err = fmt.Errorf("approval: role '%s' cannot review mutations", role)
+		return
+	}
+
+	var reviewer interface{}
+	if v := c.Value(UserIDKey); v != nil {
+		reviewer = fmt.Sprintf("%v", v)
+	}
+
+	dbCtx := gj.primaryDB()
+	d := dbCtx.psqlCompiler.GetDialect()
+	q := `UPDATE ` + quotedTable(d, conf.Table) + ` SET ` +
+		d.QuoteIdentifier("status") + ` = ` + d.BindVar(1) + `, ` +
+		d.QuoteIdentifier("reviewed_by") + ` = ` + d.BindVar(2) + `, ` +
+		d.QuoteIdentifier("reviewed_role") + ` = ` + d.BindVar(3) + `, ` +
+		d.QuoteIdentifier("reviewed_at") + ` = ` + d.BindVar(4) + ` WHERE ` +
+		d.QuoteIdentifier("id") + ` = ` + d.BindVar(5) + ` AND ` +
+		d.QuoteIdentifier("status") + ` = ` + d.BindVar(6)
+
+	q, vals, err := prepareQueryArgsForDB(dbCtx.dbtype, q, []interface{}{
+		status, reviewer, role, time.Now().UTC(), id, approvalPending,
+	})
+	if err != nil {
+		return
+	}
+
+	res, err := tx.ExecContext(c, q, vals...)
+	if err != nil {
+		err = fmt.Errorf("approval: %w", err)
+		return
+	}
+	if n, err1 := res.RowsAffected(); err1 == nil && n == 0 {
+		err = ErrNotPending
+	}
+	return
+}
+
+// approverRoles returns the roles allowed to review pending mutations,
+// only admin when none are configured
+func approverRoles(conf ApprovalConfig) []string {
+	if len(conf.Roles) == 0 {
+		return []string{"admin"}
+	}
+	return conf.Roles
+}
+
+// pendingMutations reads the pending mutations or the one with the id
+func (gj *graphjinEngine) pendingMutations(c context.Context,
+	tx *sql.Tx,
+	id string,
+) (list []PendingMutation, err error) {
+	conf := gj.conf.Approvals
+	if conf.Table == "" {
+		return
+	}
+
+	dbCtx := gj.primaryDB()
+	d := dbCtx.psqlCompiler.GetDialect()
+
+	cols := []string{
+		"id", "created_at", "namespace", "db_name", "query_name", "query",
+		"statement", "variables", "request_variables", "user_id", "user_role",
+	}
+	for i := range cols {
+		cols[i] = d.QuoteIdentifier(cols[i])
+	}
+
+	q := `SELECT ` + strings.Join(cols, `, `) + ` FROM ` + quotedTable(d, conf.Table) +
+		` WHERE ` + d.QuoteIdentifier("status") + ` = ` + d.BindVar(1)
+	vals := []interface{}{approvalPending}
+
+	if id != "" {
+		q += ` AND ` + d.QuoteIdentifier("id") + ` = ` + d.BindVar(2)
+		vals = append(vals, id)
+	}
+	q += ` ORDER BY ` + d.QuoteIdentifier("created_at")
+
+	q, vals, err = prepareQueryArgsForDB(dbCtx.dbtype, q, vals)
+	if err != nil {
+		return
+	}
+
+	var rows *sql.Rows
+	if tx != nil {
+		rows, err = tx.QueryContext(c, q, vals...)
+	} else {
+		rows, err = dbCtx.db.QueryContext(c, q, vals...)
+	}
+	if err != nil {
+		err = fmt.Errorf("approval: %w", err)
+		return
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var pm PendingMutation
+		var ns, name, vars, reqVars, userID sql.NullString
+
+		err = rows.Scan(&pm.ID, &pm.CreatedAt, &ns, &pm.Database, &name, &pm.Query,
+			&pm.Statement, &vars, &reqVars, &userID, &pm.Role)
+		if err != nil {
+			return
+		}
+		pm.Namespace = ns.String
+		pm.Name = name.String
+		if vars.Valid {
+			pm.Vars = json.RawMessage(vars.String)
+		}
+		if reqVars.Valid {
+			pm.RequestVars = json.RawMessage(reqVars.String)
+		}
+		if userID.Valid {
+			pm.UserID = json.RawMessage(userID.String)
+		}
+		list = append(list, pm)
+	}
+	err = rows.Err()
+	return
+}
+
+// quotedTable quotes a table name that can include the schema
+func quotedTable(d dialect.Dialect, table string) string {
+	parts := strings.Split(table, ".")
+	for i, v := range parts {
+		parts[i] = d.QuoteIdentifier(v)
+	}
+	return strings.Join(parts, ".")
+}
+
+// nullString returns nil for an empty value so it is stored as null
+func nullString(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
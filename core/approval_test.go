@@ -0,0 +1,282 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestApproveMutation(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE payments (id INTEGER PRIMARY KEY, amount INTEGER, user_id INTEGER);
+		CREATE TABLE pending_mutations (id TEXT PRIMARY KEY, created_at TIMESTAMP,
+			status TEXT, namespace TEXT, db_name TEXT, query_name TEXT, query TEXT,
+			statement TEXT, variables TEXT, request_variables TEXT, user_id TEXT,
+			user_role TEXT, reviewed_by TEXT, reviewed_role TEXT, reviewed_at TIMESTAMP);
+		INSERT INTO payments (id, amount, user_id) VALUES (1, 100, 5);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Approvals: core.ApprovalConfig{
+			Tables: []string{"payments"},
+			Table:  "pending_mutations",
+			Roles:  []string{"admin"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := context.WithValue(t.Context(), core.UserIDKey, 5)
+
+	res, err := gj.GraphQL(c, `mutation updatePayment {
+		payments(id: $id, update: $data) { id amount }
+	}`, json.RawMessage(`{"id": 1, "data": {"amount": 500}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions == nil || res.Extensions.Pending == "" {
+		t.Fatalf("expected the mutation to be staged, got %+v", res)
+	}
+	id := res.Extensions.Pending
+
+	var amount int
+	if err := db.QueryRow(`SELECT amount FROM payments WHERE id = 1`).Scan(&amount); err != nil {
+		t.Fatal(err)
+	}
+	if amount != 100 {
+		t.Fatalf("expected the staged mutation not to run, got amount %d", amount)
+	}
+
+	list, err := gj.PendingMutations(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != id || list[0].Role != "user" ||
+		list[0].Name != "updatePayment" || list[0].Statement == "" {
+		t.Fatalf("unexpected pending mutations %+v", list)
+	}
+
+	// the role that staged the mutation cannot approve it
+	if _, err := gj.ApproveMutation(c, id); err == nil {
+		t.Fatal("expected approval by the same role to fail")
+	}
+
+	admin := context.WithValue(context.WithValue(t.Context(), core.UserIDKey, 1),
+		core.UserRoleKey, "admin")
+
+	res, err = gj.ApproveMutation(admin, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"payments":{"id":1,"amount":500}}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	var status, reviewer string
+	err = db.QueryRow(`SELECT p.amount, m.status, m.reviewed_by FROM payments p, pending_mutations m
+		WHERE p.id = 1 AND m.id = ?`, id).Scan(&amount, &status, &reviewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount != 500 || status != "approved" || reviewer != "1" {
+		t.Fatalf("expected the approved mutation to run, got %d %s %s", amount, status, reviewer)
+	}
+
+	if _, err := gj.ApproveMutation(admin, id); !errors.Is(err, core.ErrNotPending) {
+		t.Fatalf("expected a second approval to fail with ErrNotPending, got %v", err)
+	}
+}
+
+func TestApproveMutationOtherDatabase(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name, ddl string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+		if _, err := db.Exec(ddl); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	appDB := openDB("app", `
+		CREATE TABLE pending_mutations (id TEXT PRIMARY KEY, created_at TIMESTAMP,
+			status TEXT, namespace TEXT, db_name TEXT, query_name TEXT, query TEXT,
+			statement TEXT, variables TEXT, request_variables TEXT, user_id TEXT,
+			user_role TEXT, reviewed_by TEXT, reviewed_role TEXT, reviewed_at TIMESTAMP);
+	`)
+	billingDB := openDB("billing", `
+		CREATE TABLE payments (id INTEGER PRIMARY KEY, amount INTEGER);
+		INSERT INTO payments (id, amount) VALUES (1, 100), (2, 200);
+	`)
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			"app":     {Type: "sqlite"},
+			"billing": {Type: "sqlite"},
+		},
+		Tables: []core.Table{
+			{Name: "pending_mutations", Database: "app"},
+			{Name: "payments", Database: "billing"},
+		},
+		Approvals: core.ApprovalConfig{
+			Tables: []string{"payments"},
+			Table:  "pending_mutations",
+		},
+	}
+	gj, err := core.NewGraphJin(conf, appDB,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"app": appDB, "billing": billingDB}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := context.WithValue(t.Context(), core.UserIDKey, 5)
+	stage := func(id int) string {
+		t.Helper()
+		res, err := gj.GraphQL(c, `mutation {
+			payments(id: $id, update: $data) { id amount }
+		}`, json.RawMessage(fmt.Sprintf(`{"id": %d, "data": {"amount": 500}}`, id)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Extensions == nil || res.Extensions.Pending == "" {
+			t.Fatalf("expected the mutation to be staged, got %+v", res)
+		}
+		return res.Extensions.Pending
+	}
+	status := func(id string) (v string) {
+		t.Helper()
+		err := appDB.QueryRow(`SELECT status FROM pending_mutations WHERE id = ?`, id).Scan(&v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	admin := context.WithValue(context.WithValue(t.Context(), core.UserIDKey, 1),
+		core.UserRoleKey, "admin")
+
+	id := stage(1)
+	res, err := gj.ApproveMutation(admin, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"payments":{"id":1,"amount":500}}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+	if v := status(id); v != "approved" {
+		t.Fatalf("expected the mutation to be approved, got %s", v)
+	}
+
+	// the review commits before the mutation runs on the other database,
+	// a failed mutation stays approved and cannot run again
+	id = stage(2)
+	if _, err := billingDB.Exec(`DROP TABLE payments`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gj.ApproveMutation(admin, id); err == nil {
+		t.Fatal("expected the mutation to fail")
+	}
+	if v := status(id); v != "approved" {
+		t.Fatalf("expected the failed mutation to stay approved, got %s", v)
+	}
+	if _, err := gj.ApproveMutation(admin, id); !errors.Is(err, core.ErrNotPending) {
+		t.Fatalf("expected a second approval to fail with ErrNotPending, got %v", err)
+	}
+}
+
+func TestRejectMutation(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE payments (id INTEGER PRIMARY KEY, amount INTEGER);
+		CREATE TABLE pending_mutations (id TEXT PRIMARY KEY, created_at TIMESTAMP,
+			status TEXT, namespace TEXT, db_name TEXT, query_name TEXT, query TEXT,
+			statement TEXT, variables TEXT, request_variables TEXT, user_id TEXT,
+			user_role TEXT, reviewed_by TEXT, reviewed_role TEXT, reviewed_at TIMESTAMP);
+		CREATE TABLE users (id INTEGER PRIMARY KEY, is_admin BOOLEAN);
+		INSERT INTO users (id, is_admin) VALUES (1, true), (2, false);
+		INSERT INTO payments (id, amount) VALUES (1, 100);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only admin can review when no approver roles are configured
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		RolesQuery:       `SELECT * FROM users WHERE id = $user_id`,
+		Roles:            []core.Role{{Name: "admin", Match: `is_admin = true`}},
+		Approvals: core.ApprovalConfig{
+			Tables: []string{"payments"},
+			Table:  "pending_mutations",
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `mutation {
+		payments(id: $id, delete: true) { id }
+	}`, json.RawMessage(`{"id": 1}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions == nil || res.Extensions.Pending == "" {
+		t.Fatalf("expected the mutation to be staged, got %+v", res)
+	}
+
+	user := context.WithValue(t.Context(), core.UserIDKey, 2)
+	if err := gj.RejectMutation(user, res.Extensions.Pending); err == nil {
+		t.Fatal("expected a user not in the approver roles not to reject")
+	}
+
+	// the reviewer gets the admin role from the roles query
+	c := context.WithValue(t.Context(), core.UserIDKey, 1)
+	if err := gj.RejectMutation(c, res.Extensions.Pending); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gj.ApproveMutation(c, res.Extensions.Pending); !errors.Is(err, core.ErrNotPending) {
+		t.Fatalf("expected a rejected mutation not to be approved, got %v", err)
+	}
+
+	var n int
+	if err := db.QueryRow(`SELECT count(*) FROM payments`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the rejected delete not to run, got %d rows", n)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
 )
 
 // argList function is used to create a list of arguments to pass
@@ -86,6 +88,16 @@ func (gj *graphjinEngine) argList(c context.Context,
 					vl[i] = nil
 				}
 				ar.cindxs = append(ar.cindxs, i)
+			} else if cv, ok := gj.claimVar(p.Name); ok {
+				// claim variables can never be set by the request
+				v, err := cv.value(c)
+				if err != nil {
+					return ar, err
+				}
+				vl[i] = convertBoolIfNeeded(pc, v)
+			} else if v, ok := rc.varValue(p.Name); ok {
+				// variables set by the server take precedence over the request
+				vl[i] = convertBoolIfNeeded(pc, v)
 			} else if v, ok := fields[p.Name]; ok {
 				varIsNull := bytes.Equal(v, []byte("null"))
 
@@ -118,24 +130,20 @@ func (gj *graphjinEngine) argList(c context.Context,
 				} else {
 					vl[i] = parseVarVal(v)
 				}
+				// 64-bit integers can be sent as strings to keep them exact
+				if s, ok := vl[i].(string); ok && !p.IsArray && sdata.IsBigIntType(p.Type) {
+					if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+						vl[i] = n
+					}
+				}
+				if err := gj.checkTimeArg(p, vl[i]); err != nil {
+					return ar, err
+				}
 				// Oracle's PL/SQL BOOLEAN can't be used in SQL WHERE clauses
 				// Convert Go bool to int (1/0) before it reaches the driver
 				vl[i] = convertBoolIfNeeded(pc, vl[i])
 
-			} else if rc != nil {
-				if v, ok := rc.Vars[p.Name]; ok {
-					switch v1 := v.(type) {
-					case (func() string):
-						vl[i] = v1()
-					case (func() int):
-						vl[i] = v1()
-					case (func() bool):
-						vl[i] = convertBoolIfNeeded(pc, v1())
-					default:
-						vl[i] = convertBoolIfNeeded(pc, v)
-					}
-				}
-			} else {
+			} else if rc == nil && p.Type != psql.TimezoneType {
 				return ar, argErr(p)
 			}
 		}
@@ -150,6 +158,26 @@ func (gj *graphjinEngine) argList(c context.Context,
 	return ar, nil
 }
 
+// varValue returns the value of a variable from the request config
+func (rc *RequestConfig) varValue(name string) (interface{}, bool) {
+	if rc == nil {
+		return nil, false
+	}
+	v, ok := rc.Vars[name]
+	if !ok {
+		return nil, false
+	}
+	switch v1 := v.(type) {
+	case (func() string):
+		return v1(), true
+	case (func() int):
+		return v1(), true
+	case (func() bool):
+		return v1(), true
+	}
+	return v, true
+}
+
 func parseVarVal(v json.RawMessage) interface{} {
 	switch v[0] {
 	case '[', '{':
@@ -0,0 +1,400 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+// auditOps are the names of the mutation types recorded in the audit log
+var auditOps = map[qcode.MType]string{
+	qcode.MTInsert: "insert",
+	qcode.MTUpdate: "update",
+	qcode.MTUpsert: "upsert",
+	qcode.MTDelete: "delete",
+}
+
+// AuditSink receives the audit records of a mutation. It is called in the
+// transaction of the mutation before it commits, returning an error rolls
+// back the mutation
+type AuditSink interface {
+	WriteAudit(c context.Context, tx *sql.Tx, recs []AuditRecord) error
+}
+
+// AuditRecord is the change a mutation made to an audited table
+type AuditRecord struct {
+	Time      time.Time
+	Database  string
+	Table     string
+	Operation string
+	// Name of the GraphQL operation
+	Name   string
+	UserID any
+	Role   string
+	Vars   json.RawMessage
+	Rows   []AuditRow
+}
+
+// AuditRow holds the old and new values of a row, old is empty for
+// inserted rows and new for deleted rows
+type AuditRow struct {
+	ID  string          `json:"id"`
+	Old json.RawMessage `json:"old,omitempty"`
+	New json.RawMessage `json:"new,omitempty"`
+}
+
+// Refs returns the rows changed by the mutation
+func (ar AuditRecord) Refs() []RowRef {
+	refs := make([]RowRef, 0, len(ar.Rows))
+	for _, r := range ar.Rows {
+		refs = append(refs, RowRef{Table: ar.Table, ID: r.ID})
+	}
+	return refs
+}
+
+// OptionSetAuditSink sets the sink that receives the audit records of
+// mutations on the tables listed in the audit config
+func OptionSetAuditSink(sink AuditSink) Option {
+	return func(s *graphjinEngine) error {
+		s.auditSink = sink
+		return nil
+	}
+}
+
+// auditedMutates returns the root mutations on the audited tables
+func (s *gstate) auditedMutates() (ms []qcode.Mutate) {
+	conf := s.gj.conf.Audit
+	qc := s.cs.st.qc
+
+	if len(conf.Tables) == 0 || (conf.Table == "" && s.gj.auditSink == nil) {
+		return
+	}
+	if qc.Type != qcode.QTMutation || qc.SType == qcode.QTCall {
+		return
+	}
+
+	for _, m := range qc.Mutates {
+		if _, ok := auditOps[m.Type]; !ok || m.ParentID != -1 {
+			continue
+		}
+		if slices.Contains(conf.Tables, m.Ti.Name) ||
+			slices.Contains(conf.Tables, m.Ti.Schema+"."+m.Ti.Name) {
+			ms = append(ms, m)
+		}
+	}
+	return
+}
+
+// executeAudited runs a mutation on audited tables and records it in the
+// same transaction. The old values of updated and deleted rows are read
+// before the mutation runs and the new values after.
+func (s *gstate) executeAudited(c context.Context,
+	conn *sql.Conn,
+	ms []qcode.Mutate,
+	run func(context.Context, *sql.Conn) error,
+) (err error) {
+	dbCtx := s.getTargetDBCtx()
+	if dbCtx.dbtype == "mongodb" {
+		return fmt.Errorf("audit: not supported by %s", dbCtx.dbtype)
+	}
+
+	// use a transaction of our own unless the request is already in one
+	if s.tx() == nil {
+		if s.optTx, err = conn.BeginTx(c, nil); err != nil {
+			return
+		}
+		defer func() {
+			if err == nil {
+				err = s.optTx.Commit()
+			} else {
+				s.optTx.Rollback() //nolint:errcheck
+			}
+		}()
+	}
+	tx := s.tx()
+
+	c1, span := s.gj.spanStart(c, "Audit Mutation")
+	defer span.End()
+
+	recs := make([]AuditRecord, len(ms))
+	before := make([][]string, len(ms))
+	old := make([]map[string]json.RawMessage, len(ms))
+
+	for i, m := range ms {
+		if m.Type != qcode.MTUpdate && m.Type != qcode.MTDelete {
+			continue
+		}
+		if before[i], err = s.auditKeys(c1, tx, m); err != nil {
+			span.Error(err)
+			return
+		}
+		if old[i], err = s.auditRows(c1, tx, m.Ti, before[i]); err != nil {
+			span.Error(err)
+			return
+		}
+	}
+
+	if err = run(c, conn); err != nil {
+		return
+	}
+
+	dbName := s.database
+	if dbName == "" {
+		dbName = s.gj.defaultDB
+	}
+	now := time.Now().UTC()
+
+	for i, m := range ms {
+		ids := before[i]
+		if m.Type != qcode.MTDelete {
+			for _, r := range s.auditResponseRefs(m) {
+				if !slices.Contains(ids, r.ID) {
+					ids = append(ids, r.ID)
+				}
+			}
+		}
+
+		var cur map[string]json.RawMessage
+		if m.Type != qcode.MTDelete {
+			if cur, err = s.auditRows(c1, tx, m.Ti, ids); err != nil {
+				span.Error(err)
+				return
+			}
+		}
+
+		rows := make([]AuditRow, 0, len(ids))
+		for _, id := range ids {
+			rows = append(rows, AuditRow{ID: id, Old: old[i][id], New: cur[id]})
+		}
+
+		recs[i] = AuditRecord{
+			Time:      now,
+			Database:  dbName,
+			Table:     m.Ti.Name,
+			Operation: auditOps[m.Type],
+			Name:      s.r.name,
+			UserID:    c.Value(UserIDKey),
+			Role:      s.role,
+			Vars:      s.r.vars,
+			Rows:      rows,
+		}
+	}
+
+	if table := s.gj.conf.Audit.Table; table != "" {
+		if err = s.writeAuditTable(c1, tx, table, recs); err != nil {
+			span.Error(err)
+			return
+		}
+	}
+	if s.gj.auditSink != nil {
+		if err = s.gj.auditSink.WriteAudit(c1, tx, recs); err != nil {
+			span.Error(err)
+			return
+		}
+	}
+	return
+}
+
+// auditKeys returns the primary keys of the rows the update or delete m
+// will change
+func (s *gstate) auditKeys(c context.Context, tx *sql.Tx, m qcode.Mutate) (ids []string, err error) {
+	var w bytes.Buffer
+	md, err := s.getTargetPsqlCompiler().CompileMutationKeys(&w, s.cs.st.qc, m)
+	if err != nil {
+		return
+	}
+
+	args, err := s.gj.argList(c, md, s.vmap, s.r.requestconfig, false, s.getTargetPsqlCompiler())
+	if err != nil {
+		return
+	}
+
+	q, vals, err := prepareQueryArgsForDB(s.getTargetDBCtx().dbtype, w.String(), args.values)
+	if err != nil {
+		return
+	}
+
+	rows, err := tx.QueryContext(c, q, vals...)
+	if err != nil {
+		return
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var v any
+		if err = rows.Scan(&v); err != nil {
+			return
+		}
+		ids = append(ids, auditID(v))
+	}
+	err = rows.Err()
+	return
+}
+
+// auditRows reads the rows with the primary keys and returns them as json
+// objects keyed by primary key
+func (s *gstate) auditRows(c context.Context,
+	tx *sql.Tx,
+	ti sdata.DBTable,
+	ids []string,
+) (res map[string]json.RawMessage, err error) {
+	if len(ids) == 0 {
+		return
+	}
+	d := s.getTargetPsqlCompiler().GetDialect()
+	pk := ti.PrimaryCol.Name
+
+	var sb strings.Builder
+	sb.WriteString(`SELECT * FROM `)
+	if ti.Schema != "" {
+		sb.WriteString(d.QuoteIdentifier(ti.Schema) + `.`)
+	}
+	sb.WriteString(d.QuoteIdentifier(ti.Name))
+	sb.WriteString(` WHERE ` + d.QuoteIdentifier(pk) + ` IN (`)
+
+	vals := make([]any, len(ids))
+	for i, id := range ids {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(d.BindVar(i + 1))
+		vals[i] = id
+	}
+	sb.WriteString(`)`)
+
+	q, vals, err := prepareQueryArgsForDB(s.getTargetDBCtx().dbtype, sb.String(), vals)
+	if err != nil {
+		return
+	}
+
+	rows, err := tx.QueryContext(c, q, vals...)
+	if err != nil {
+		return
+	}
+	defer rows.Close() //nolint:errcheck
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return
+	}
+
+	res = make(map[string]json.RawMessage, len(ids))
+	vl := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vl {
+		ptrs[i] = &vl[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(ptrs...); err != nil {
+			return
+		}
+		row := make(map[string]any, len(cols))
+		for i, col := range cols {
+			if b, ok := vl[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = vl[i]
+			}
+		}
+		var b []byte
+		if b, err = json.Marshal(row); err != nil {
+			return
+		}
+		res[auditID(row[pk])] = b
+	}
+	err = rows.Err()
+	return
+}
+
+// auditResponseRefs returns the rows of the mutation m found in its
+// response, inserted rows are only found when their primary key is selected
+func (s *gstate) auditResponseRefs(m qcode.Mutate) []RowRef {
+	if len(s.data) == 0 || m.Ti.PrimaryCol.Name == "" {
+		return nil
+	}
+
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(s.data, &res); err != nil {
+		return nil
+	}
+	if v, ok := res["data"]; ok {
+		res = nil
+		if err := json.Unmarshal(v, &res); err != nil {
+			return nil
+		}
+	}
+
+	var v any
+	d := json.NewDecoder(bytes.NewReader(res[m.Key]))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil
+	}
+	return extractIDsFromData(m.Ti.Name, m.Ti.PrimaryCol.Name, v)
+}
+
+// writeAuditTable inserts the audit records into the audit table
+func (s *gstate) writeAuditTable(c context.Context, tx *sql.Tx, table string, recs []AuditRecord) error {
+	d := s.getTargetPsqlCompiler().GetDialect()
+	cols := []string{
+		"created_at", "db_name", "table_name", "operation",
+		"query_name", "user_id", "user_role", "variables", "row_data",
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO ` + quotedTable(d, table) + ` (`)
+	for i, col := range cols {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(d.QuoteIdentifier(col))
+	}
+	sb.WriteString(`) VALUES (`)
+	for i := range cols {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(d.BindVar(i + 1))
+	}
+	sb.WriteString(`)`)
+
+	for _, r := range recs {
+		rows, err := json.Marshal(r.Rows)
+		if err != nil {
+			return err
+		}
+
+		var userID any
+		if r.UserID != nil {
+			userID = fmt.Sprintf("%v", r.UserID)
+		}
+		q, vals, err := prepareQueryArgsForDB(s.getTargetDBCtx().dbtype, sb.String(), []any{
+			r.Time, r.Database, r.Table, r.Operation,
+			r.Name, userID, r.Role, nullString(r.Vars), string(rows),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(c, q, vals...); err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+	}
+	return nil
+}
+
+// auditID returns a primary key value as a string
+func auditID(v any) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return stringifyID(v)
+}
@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testAuditSink struct {
+	recs []core.AuditRecord
+}
+
+func (ts *testAuditSink) WriteAudit(c context.Context, tx *sql.Tx, recs []core.AuditRecord) error {
+	ts.recs = append(ts.recs, recs...)
+	return nil
+}
+
+func TestAuditMutations(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE audit_log (id INTEGER PRIMARY KEY, created_at TIMESTAMP,
+			db_name TEXT, table_name TEXT, operation TEXT, query_name TEXT,
+			user_id TEXT, user_role TEXT, variables TEXT, row_data TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Jane');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Audit: core.AuditConfig{
+			Tables: []string{"users"},
+			Table:  "audit_log",
+		},
+	}
+	sink := &testAuditSink{}
+	gj, err := core.NewGraphJin(conf, db,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetAuditSink(sink))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := context.WithValue(t.Context(), core.UserIDKey, 5)
+
+	_, err = gj.GraphQL(c, `mutation updateUser {
+		users(id: $id, update: $data) { id name }
+	}`, json.RawMessage(`{"id": 1, "data": {"name": "Janet"}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = gj.GraphQL(c, `mutation {
+		users(insert: $data) { id }
+	}`, json.RawMessage(`{"data": {"id": 2, "name": "Bob"}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = gj.GraphQL(c, `mutation deleteUser {
+		users(id: $id, delete: true) { id }
+	}`, json.RawMessage(`{"id": 1}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.recs) != 3 {
+		t.Fatalf("expected 3 audit records, got %d", len(sink.recs))
+	}
+
+	upd := sink.recs[0]
+	if upd.Operation != "update" || upd.Table != "users" || upd.Name != "updateUser" ||
+		upd.Role != "user" || upd.UserID != 5 || len(upd.Rows) != 1 {
+		t.Fatalf("unexpected update record %+v", upd)
+	}
+	if exp := `{"id":1,"name":"Jane"}`; string(upd.Rows[0].Old) != exp {
+		t.Fatalf("expected old row %s, got %s", exp, upd.Rows[0].Old)
+	}
+	if exp := `{"id":1,"name":"Janet"}`; string(upd.Rows[0].New) != exp {
+		t.Fatalf("expected new row %s, got %s", exp, upd.Rows[0].New)
+	}
+	if refs := upd.Refs(); len(refs) != 1 || refs[0] != (core.RowRef{Table: "users", ID: "1"}) {
+		t.Fatalf("unexpected refs %+v", refs)
+	}
+
+	ins := sink.recs[1]
+	if ins.Operation != "insert" || len(ins.Rows) != 1 ||
+		ins.Rows[0].Old != nil || string(ins.Rows[0].New) != `{"id":2,"name":"Bob"}` {
+		t.Fatalf("unexpected insert record %+v", ins)
+	}
+
+	del := sink.recs[2]
+	if del.Operation != "delete" || len(del.Rows) != 1 ||
+		string(del.Rows[0].Old) != `{"id":1,"name":"Janet"}` || del.Rows[0].New != nil {
+		t.Fatalf("unexpected delete record %+v", del)
+	}
+
+	var op, userID, rows string
+	err = db.QueryRow(`SELECT operation, user_id, row_data FROM audit_log ORDER BY id LIMIT 1`).
+		Scan(&op, &userID, &rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `[{"id":"1","old":{"id":1,"name":"Jane"},"new":{"id":1,"name":"Janet"}}]`
+	if op != "update" || userID != "5" || rows != exp {
+		t.Fatalf("unexpected audit table row %s %s %s", op, userID, rows)
+	}
+}
+
+func TestAuditRollback(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Jane');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the audit table is missing so writing the record fails
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Audit: core.AuditConfig{
+			Tables: []string{"users"},
+			Table:  "audit_log",
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = gj.GraphQL(t.Context(), `mutation {
+		users(id: $id, update: $data) { id }
+	}`, json.RawMessage(`{"id": 1, "data": {"name": "Janet"}}`), nil)
+	if err == nil {
+		t.Fatal("expected the audit write to fail")
+	}
+
+	var name string
+	if err := db.QueryRow(`SELECT name FROM users WHERE id = 1`).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Jane" {
+		t.Fatalf("expected the update to be rolled back, got %s", name)
+	}
+}
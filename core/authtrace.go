@@ -0,0 +1,56 @@
+package core
+
+import (
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// Authorization rules reported for omitted fields
+const (
+	RuleRoleBlock     = qcode.RuleRoleBlock
+	RuleUserNeeded    = qcode.RuleUserNeeded
+	RuleRoleColumns   = qcode.RuleRoleColumns
+	RuleFunctions     = qcode.RuleFunctions
+	RuleBlocklist     = qcode.RuleBlocklist
+	RuleRoleDirective = qcode.RuleRoleDirective
+)
+
+// OmittedField is a table or field left out of a result by an authorization
+// rule, these are only reported with auth_trace in development mode
+type OmittedField struct {
+	Path   string `json:"path"`
+	Rule   string `json:"rule"`
+	Role   string `json:"role,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// omittedFields returns the tables and fields of the query left out by
+// authorization rules
+func omittedFields(qc *qcode.QCode) []OmittedField {
+	var omitted []OmittedField
+
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+		for _, o := range sel.Omitted {
+			path := selectPath(qc.Selects, sel.ID)
+			if o.Field != "" {
+				path += "." + o.Field
+			}
+			omitted = append(omitted, OmittedField{
+				Path:   path,
+				Rule:   o.Rule,
+				Role:   o.Role,
+				Reason: o.Reason,
+			})
+		}
+	}
+	return omitted
+}
+
+// checkOmitted reports the fields left out by authorization rules, this is
+// only done in development mode with auth_trace enabled
+func (s *gstate) checkOmitted() {
+	if s.gj.prod || !s.gj.conf.AuthTrace || s.cs == nil || s.cs.st.qc == nil {
+		return
+	}
+	s.omitted = omittedFields(s.cs.st.qc)
+}
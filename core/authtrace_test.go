@@ -0,0 +1,128 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAuthTrace(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, password TEXT);
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL,
+			owner_id INTEGER REFERENCES users(id));
+		INSERT INTO users (id, email, password) VALUES (1, 'a@b.c', 'secret');
+		INSERT INTO products (id, name, price, owner_id) VALUES (1, 'Lamp', 10, 1);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		AuthTrace:        true,
+		Roles: []core.Role{
+			{Name: "anon", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{Columns: []string{"id", "name", "owner_id"}}},
+				{Name: "users", Query: &core.Query{Block: true}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		products {
+			id
+			name
+			price
+			owner { email }
+		}
+	}`
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `{"products":[{"id":1,"name":"Lamp","owner":null}]}`
+	if got := string(res.Data); got != exp {
+		t.Fatalf("unexpected data: %s", got)
+	}
+
+	if res.Extensions == nil {
+		t.Fatal("expected the omitted fields in the extensions")
+	}
+	rules := make(map[string]string)
+	for _, o := range res.Extensions.Omitted {
+		rules[o.Path] = o.Rule
+		if o.Role != "anon" || o.Reason == "" {
+			t.Errorf("unexpected omission: %+v", o)
+		}
+	}
+	if rules["products.price"] != core.RuleRoleColumns {
+		t.Errorf("expected price to be left out by the role columns, got %v", rules)
+	}
+	if rules["products.owner"] != core.RuleRoleBlock {
+		t.Errorf("expected owner to be left out by the role block, got %v", rules)
+	}
+}
+
+func TestAuthTraceAllFieldsOmitted(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		INSERT INTO products (id, name, price) VALUES (1, 'Lamp', 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		AuthTrace:        true,
+		Roles: []core.Role{
+			{Name: "anon", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{Columns: []string{"id"}}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), `query { products { price } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(res.Data); got != `{"products":null}` {
+		t.Fatalf("unexpected data: %s", got)
+	}
+	if res.Extensions == nil || len(res.Extensions.Omitted) != 1 ||
+		res.Extensions.Omitted[0].Path != "products.price" {
+		t.Fatalf("expected price to be omitted, got %+v", res.Extensions)
+	}
+}
@@ -0,0 +1,97 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestBigIntAsString(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE accounts (id INTEGER PRIMARY KEY, ext_id BIGINT, code INTEGER);
+		INSERT INTO accounts (id, ext_id, code) VALUES
+			(1, 9007199254740993, 10), (2, 42, 20);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		BigIntAsString:   true,
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query {
+		accounts(order_by: { id: asc }) { id ext_id code }
+	}`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"accounts":[{"id":1,"ext_id":"9007199254740993","code":10},{"id":2,"ext_id":"42","code":20}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	res, err = gj.GraphQL(t.Context(), `query ($ext_id: BigInt) {
+		accounts(where: { ext_id: { eq: $ext_id } }) { id }
+	}`, []byte(`{"ext_id": "9007199254740993"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = `{"accounts":[{"id":1}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+}
+
+func TestColumnAsString(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE accounts (id INTEGER PRIMARY KEY, ext_id BIGINT, code INTEGER);
+		INSERT INTO accounts (id, ext_id, code) VALUES (1, 9007199254740993, 10);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Tables: []core.Table{{
+			Name:    "accounts",
+			Columns: []core.Column{{Name: "code", AsString: true}},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query { accounts { id ext_id code } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"accounts":[{"id":1,"ext_id":9007199254740993,"code":"10"}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+}
@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestBlockOperators(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+		INSERT INTO products (id, name, price) VALUES (1, 'Lamp', 10);
+		INSERT INTO users (id, email) VALUES (1, 'a@b.c');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Roles: []core.Role{
+			{Name: "anon", BlockOperators: []string{"regex"}, Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{BlockOperators: []string{"ilike"}}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anon := context.Background()
+	user := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	tests := []struct {
+		name  string
+		c     context.Context
+		query string
+		err   string
+	}{
+		{"table operator", anon, `query { products(where: { name: { ilike: "%la%" } }) { id } }`,
+			"operator 'ilike' is blocked for role 'anon' on column: products.name"},
+		{"role operator", anon, `query { users(where: { email: { regex: "a" } }) { id } }`,
+			"operator 'regex' is blocked for role 'anon' on column: users.email"},
+		{"nested operator", anon, `query { products(where: { or: [{ id: 1 }, { name: { _ilike: "%la%" } }] }) { id } }`,
+			"operator 'ilike' is blocked"},
+		{"allowed operator", anon, `query { products(where: { name: { like: "%La%" } }) { id } }`, ""},
+		{"other table", anon, `query { users(where: { email: { ilike: "%a%" } }) { id } }`, ""},
+		{"other role", user, `query { products(where: { name: { ilike: "%la%" } }) { id } }`, ""},
+	}
+
+	for _, tt := range tests {
+		_, err := gj.GraphQL(tt.c, tt.query, nil, nil)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	conf = &core.Config{
+		DBType: "sqlite",
+		Roles:  []core.Role{{Name: "anon", BlockOperators: []string{"sounds_like"}}},
+	}
+	if _, err := core.NewGraphJin(conf, db); err == nil {
+		t.Fatal("expected an error for an unknown operator")
+	}
+}
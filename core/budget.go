@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// ErrBudgetExceeded is returned along with the partial results of a request
+// that went over its query budget
+var ErrBudgetExceeded = errors.New("query budget exceeded")
+
+// budget tracks the sub-queries, rows and time used by a request
+type budget struct {
+	conf     QueryBudget
+	start    time.Time
+	deadline time.Time
+
+	mu         sync.Mutex
+	subQueries int
+	rows       int
+	err        error
+}
+
+// newBudget returns nil when no budget limits or request deadline are set.
+// The request deadline replaces the max time when it comes first
+func newBudget(conf QueryBudget, start, deadline time.Time) *budget {
+	if conf.MaxSubQueries <= 0 && conf.MaxRows <= 0 && conf.MaxTime <= 0 && deadline.IsZero() {
+		return nil
+	}
+	b := &budget{conf: conf, start: start}
+	if conf.MaxTime > 0 {
+		b.deadline = start.Add(conf.MaxTime)
+	}
+	if !deadline.IsZero() && (b.deadline.IsZero() || deadline.Before(b.deadline)) {
+		b.deadline = deadline
+	}
+	return b
+}
+
+// maxTime returns the time the request had for its sub-queries
+func (b *budget) maxTime() time.Duration {
+	return b.deadline.Sub(b.start).Round(time.Millisecond)
+}
+
+// context bounds the sub-queries by the budget deadline
+func (b *budget) context(c context.Context) (context.Context, context.CancelFunc) {
+	if b == nil || b.deadline.IsZero() {
+		return c, func() {}
+	}
+	return context.WithDeadline(c, b.deadline)
+}
+
+// acquire reserves a sub-query, it fails once the budget is used up
+func (b *budget) acquire() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	switch {
+	case b.conf.MaxSubQueries > 0 && b.subQueries >= b.conf.MaxSubQueries:
+		return b.exceed("max sub-queries of %d", b.conf.MaxSubQueries)
+	case !b.deadline.IsZero() && !time.Now().Before(b.deadline):
+		return b.exceed("max time of %s", b.maxTime())
+	}
+	b.subQueries++
+	return nil
+}
+
+// addRows counts the rows returned by a sub-query, it fails when they would
+// go over the max rows in which case the rows should be dropped
+func (b *budget) addRows(data []byte, keyed bool) error {
+	if b == nil || b.conf.MaxRows <= 0 {
+		return nil
+	}
+	n := countRows(data, keyed)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rows+n > b.conf.MaxRows {
+		return b.exceed("max rows of %d", b.conf.MaxRows)
+	}
+	b.rows += n
+	return nil
+}
+
+// check turns a sub-query that ran out of time into a budget error
+func (b *budget) check(err error) error {
+	if b == nil || !errors.Is(err, context.DeadlineExceeded) || b.deadline.IsZero() {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceed("max time of %s", b.maxTime())
+}
+
+// exceeded returns the error recorded when the budget was used up
+func (b *budget) exceeded() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// exceed records the first limit hit, must be called with the lock held
+func (b *budget) exceed(format string, args ...interface{}) error {
+	if b.err == nil {
+		b.err = fmt.Errorf("%w: %s, results are partial", ErrBudgetExceeded, fmt.Sprintf(format, args...))
+	}
+	return b.err
+}
+
+// countRows returns the number of rows in a sub-query result. Arrays count
+// each of their items, when keyed the data is an object of root fields and
+// the rows of each field are counted
+func countRows(data []byte, keyed bool) int {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0
+	}
+	return countValueRows(v, keyed)
+}
+
+func countValueRows(v interface{}, keyed bool) int {
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v)
+	case map[string]interface{}:
+		if !keyed {
+			return 1
+		}
+		n := 0
+		for _, fv := range v {
+			n += countValueRows(fv, false)
+		}
+		return n
+	case nil:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// withTimeout bounds the context by the default timeout for the operation
+// type, an earlier deadline already set on the context is kept
+func (gj *graphjinEngine) withTimeout(c context.Context, op qcode.QType) (context.Context, context.CancelFunc) {
+	var d time.Duration
+	switch op {
+	case qcode.QTQuery:
+		d = gj.conf.Timeouts.Query
+	case qcode.QTMutation:
+		d = gj.conf.Timeouts.Mutation
+	case qcode.QTSubscription:
+		d = gj.conf.Timeouts.Subscription
+	}
+	if d <= 0 {
+		return c, func() {}
+	}
+	return context.WithTimeout(c, d)
+}
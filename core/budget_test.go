@@ -0,0 +1,153 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestQueryBudget(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name, ddl string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+		if _, err := db.Exec(ddl); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	mainDB := openDB("main", `
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+	`)
+	auditDB := openDB("audit", `
+		CREATE TABLE audit_logs (id INTEGER PRIMARY KEY, action TEXT);
+		INSERT INTO audit_logs (id, action) VALUES (1, 'x'), (2, 'y');
+	`)
+
+	newGJ := func(b core.QueryBudget) *core.GraphJin {
+		conf := &core.Config{
+			DBType:           "sqlite",
+			DisableAllowList: true,
+			Budget:           b,
+			Databases: map[string]core.DatabaseConfig{
+				"main":  {Type: "sqlite"},
+				"audit": {Type: "sqlite"},
+			},
+			Tables: []core.Table{
+				{Name: "products", Database: "main"},
+				{Name: "audit_logs", Database: "audit"},
+			},
+		}
+		gj, err := core.NewGraphJin(conf, mainDB,
+			core.OptionSetFS(core.NewOsFS(dir)),
+			core.OptionSetDatabases(map[string]*sql.DB{"main": mainDB, "audit": auditDB}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+
+	gql := `query { products { id } audit_logs { id } }`
+
+	type result struct {
+		Products  []struct{ ID int } `json:"products"`
+		AuditLogs []struct{ ID int } `json:"audit_logs"`
+	}
+
+	run := func(gj *core.GraphJin) (result, error) {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+		var r result
+		if res != nil && len(res.Data) != 0 {
+			if err := json.Unmarshal(res.Data, &r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return r, err
+	}
+
+	r, err := run(newGJ(core.QueryBudget{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Products) != 3 || len(r.AuditLogs) != 2 {
+		t.Fatalf("expected all rows without a budget, got %+v", r)
+	}
+
+	// only the first database (sorted by name) runs
+	r, err = run(newGJ(core.QueryBudget{MaxSubQueries: 1}))
+	if !errors.Is(err, core.ErrBudgetExceeded) {
+		t.Fatalf("expected a budget error, got %v", err)
+	}
+	if len(r.AuditLogs) != 2 || r.Products != nil {
+		t.Fatalf("expected partial results, got %+v", r)
+	}
+
+	// rows that would go over the max are dropped
+	r, err = run(newGJ(core.QueryBudget{MaxRows: 2}))
+	if !errors.Is(err, core.ErrBudgetExceeded) {
+		t.Fatalf("expected a budget error, got %v", err)
+	}
+	if len(r.AuditLogs)+len(r.Products) > 2 {
+		t.Fatalf("expected at most 2 rows, got %+v", r)
+	}
+
+	_, err = run(newGJ(core.QueryBudget{MaxSubQueries: 2, MaxRows: 5}))
+	if err != nil {
+		t.Fatalf("expected the query to fit the budget, got %v", err)
+	}
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'a');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(to core.OperationTimeouts) *core.GraphJin {
+		conf := &core.Config{DBType: "sqlite", DisableAllowList: true, Timeouts: to}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+
+	gql := `query { products { id } }`
+
+	// the mutation timeout does not apply to queries
+	gj := newGJ(core.OperationTimeouts{Mutation: time.Nanosecond})
+	if _, err := gj.GraphQL(context.Background(), gql, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	gj = newGJ(core.OperationTimeouts{Query: time.Nanosecond})
+	_, err = gj.GraphQL(context.Background(), gql, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the query to time out, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// ErrBulkLoadNotSupported is returned by a BulkLoader that cannot bulk load
+// into the database, the insert then falls back to a regular SQL insert
+var ErrBulkLoadNotSupported = errors.New("bulk load not supported")
+
+// BulkLoader inserts rows using the database's native bulk loading
+// protocol (eg. COPY FROM STDIN for Postgres, LOAD DATA LOCAL for MySQL
+// or an unordered insertMany for MongoDB)
+type BulkLoader interface {
+	// BulkLoad inserts the rows on the connection and returns the
+	// number of rows inserted
+	BulkLoad(c context.Context, conn *sql.Conn, req BulkLoadRequest) (int64, error)
+}
+
+// BulkLoadRequest holds the rows to be loaded into a table
+type BulkLoadRequest struct {
+	DBType  string
+	Schema  string
+	Table   string
+	Columns []string
+	// Each row has a value for every column, values are the decoded json
+	// values (string, bool, int64, float64 or nil) and nested objects or
+	// arrays are passed as json.RawMessage
+	Rows [][]any
+}
+
+// OptionSetBulkLoader sets the bulk loader used for inserts with more rows
+// than the bulk_insert_threshold config
+func OptionSetBulkLoader(bl BulkLoader) Option {
+	return func(s *graphjinEngine) error {
+		s.bulkLoader = bl
+		return nil
+	}
+}
+
+// tryBulkInsert hands a large insert to the bulk loader and returns true
+// if it did. Only single table inserts from a variable with no presets
+// qualify since the bulk loader does not run any SQL of ours.
+func (s *gstate) tryBulkInsert(c context.Context, conn *sql.Conn) (bool, error) {
+	threshold := s.gj.conf.BulkInsertThreshold
+	qc := s.cs.st.qc
+
+	if s.gj.bulkLoader == nil || threshold <= 0 || conn == nil || s.tx() != nil {
+		return false, nil
+	}
+	if qc.SType != qcode.QTInsert || len(qc.Mutates) != 1 || len(qc.Roots) != 1 {
+		return false, nil
+	}
+
+	m := qc.Mutates[0]
+	if !m.IsJSON || !m.Array || len(m.RCols) != 0 {
+		return false, nil
+	}
+	for _, col := range m.Cols {
+		if col.Set {
+			return false, nil
+		}
+	}
+
+	val := s.vmap[qc.ActionVar]
+	if len(val) == 0 {
+		val = qc.ActionVal
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(val, &items); err != nil || len(items) <= threshold {
+		return false, nil
+	}
+
+	req := BulkLoadRequest{
+		DBType:  s.getTargetDBCtx().dbtype,
+		Schema:  m.Ti.Schema,
+		Table:   m.Ti.Name,
+		Columns: make([]string, len(m.Cols)),
+		Rows:    make([][]any, len(items)),
+	}
+	for i, col := range m.Cols {
+		req.Columns[i] = col.Col.Name
+	}
+
+	for i, item := range items {
+		row := make([]any, len(m.Cols))
+		for j, col := range m.Cols {
+			v, err := bulkValue(item[col.FieldName])
+			if err != nil {
+				return true, fmt.Errorf("bulk insert: row %d: %s: %w", i, col.FieldName, err)
+			}
+			row[j] = v
+		}
+		req.Rows[i] = row
+	}
+
+	c1, span := s.gj.spanStart(c, "Execute Bulk Insert")
+	defer span.End()
+
+	n, err := s.gj.bulkLoader.BulkLoad(c1, conn, req)
+	if errors.Is(err, ErrBulkLoadNotSupported) {
+		return false, nil
+	}
+	if err != nil {
+		span.Error(err)
+		return true, err
+	}
+
+	sel := qc.Selects[qc.Roots[0]]
+	s.data, err = json.Marshal(map[string]any{
+		sel.FieldName: map[string]int64{"count": n},
+	})
+	return true, err
+}
+
+// bulkValue decodes a json value for the bulk loader
+func bulkValue(v json.RawMessage) (any, error) {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	switch v[0] {
+	case '{', '[':
+		return v, nil
+	case '"':
+		var s string
+		err := json.Unmarshal(v, &s)
+		return s, err
+	}
+
+	var val any
+	d := json.NewDecoder(bytes.NewReader(v))
+	d.UseNumber()
+	if err := d.Decode(&val); err != nil {
+		return nil, err
+	}
+	if n, ok := val.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+		return n.Float64()
+	}
+	return val, nil
+}
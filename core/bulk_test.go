@@ -0,0 +1,193 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newBulkTestDB(t *testing.T, opts ...core.Option) (*sql.DB, *core.GraphJin) {
+	return newBulkTestDBWithConfig(t, &core.Config{DBType: "sqlite", DisableAllowList: true}, opts...)
+}
+
+func newBulkTestDBWithConfig(t *testing.T, conf *core.Config, opts ...core.Option) (*sql.DB, *core.GraphJin) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		INSERT INTO products (id, name, price) VALUES
+			(1, 'Lamp', 10), (2, 'Desk', 100), (3, 'Chair', 50);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append(opts, core.OptionSetFS(core.NewOsFS(dir)))
+	gj, err := core.NewGraphJin(conf, db, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, gj
+}
+
+func TestBulkUpdate(t *testing.T) {
+	db, gj := newBulkTestDB(t)
+
+	gql := `mutation {
+		products(update: $data, order_by: { id: asc }) { id name price }
+	}`
+	vars := `{ "data": [
+		{ "id": 1, "name": "Desk Lamp", "price": 12 },
+		{ "id": 3, "name": "Office Chair", "price": 55 }
+	] }`
+	res, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"products":[{"id":1,"name":"Desk Lamp","price":12.0},` +
+		`{"id":3,"name":"Office Chair","price":55.0}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	var name string
+	if err := db.QueryRow(`SELECT name FROM products WHERE id = 2`).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Desk" {
+		t.Fatalf("expected row 2 to be unchanged, got %s", name)
+	}
+}
+
+func TestBulkUpdateMissingKey(t *testing.T) {
+	_, gj := newBulkTestDB(t)
+
+	gql := `mutation {
+		products(update: $data) { id }
+	}`
+	vars := `{ "data": [{ "id": 1, "name": "Desk Lamp" }, { "name": "Office Chair" }] }`
+	_, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err == nil || !strings.Contains(err.Error(), "missing primary key") {
+		t.Fatalf("expected missing primary key error, got %v", err)
+	}
+}
+
+type testBulkLoader struct {
+	req core.BulkLoadRequest
+	err error
+}
+
+func (bl *testBulkLoader) BulkLoad(c context.Context, conn *sql.Conn, req core.BulkLoadRequest) (int64, error) {
+	if bl.err != nil {
+		return 0, bl.err
+	}
+	bl.req = req
+	return int64(len(req.Rows)), nil
+}
+
+func TestBulkInsertLoader(t *testing.T) {
+	bl := &testBulkLoader{}
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true, BulkInsertThreshold: 2}
+	db, gj := newBulkTestDBWithConfig(t, conf, core.OptionSetBulkLoader(bl))
+
+	gql := `mutation {
+		products(insert: $data) { id }
+	}`
+	vars := `{ "data": [
+		{ "id": 10, "name": "Pen", "price": 1.5 },
+		{ "id": 11, "name": "Ink", "price": null },
+		{ "id": 12, "name": "Pad", "price": 3 }
+	] }`
+	res, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"products":{"count":3}}` {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+	if bl.req.Table != "products" || len(bl.req.Rows) != 3 || len(bl.req.Columns) != 3 {
+		t.Fatalf("unexpected bulk load request: %+v", bl.req)
+	}
+	for i, col := range bl.req.Columns {
+		if col == "price" && (bl.req.Rows[0][i] != 1.5 || bl.req.Rows[1][i] != nil) {
+			t.Fatalf("unexpected price values: %v, %v", bl.req.Rows[0][i], bl.req.Rows[1][i])
+		}
+		if col == "id" && bl.req.Rows[2][i] != int64(12) {
+			t.Fatalf("unexpected id value: %#v", bl.req.Rows[2][i])
+		}
+	}
+
+	// at or below the threshold the regular insert is used
+	vars = `{ "data": [{ "id": 10, "name": "Pen" }, { "id": 11, "name": "Ink" }] }`
+	res, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"products":[{"id":10},{"id":11}]}` {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	// loaders that do not support the database fall back to sql
+	bl.err = core.ErrBulkLoadNotSupported
+	vars = `{ "data": [{ "id": 20, "name": "A" }, { "id": 21, "name": "B" }, { "id": 22, "name": "C" }] }`
+	if _, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT count(*) FROM products WHERE id >= 20`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 rows inserted, got %d", n)
+	}
+
+	bl.err = errors.New("copy failed")
+	if _, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil); err == nil {
+		t.Fatal("expected bulk load error")
+	}
+}
+
+func TestSkipReturning(t *testing.T) {
+	_, gj := newBulkTestDB(t)
+
+	gql := `mutation @skipReturning {
+		products(insert: $data) { id name }
+	}`
+	vars := `{ "data": [{ "id": 10, "name": "Pen" }, { "id": 11, "name": "Ink" }] }`
+	res, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"products":{"affected_rows":2}}` {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	gql = `mutation @skip_returning {
+		products(where: { price: { gt: 20 } }, update: $data) { id }
+	}`
+	vars = `{ "data": { "name": "Sold out" } }`
+	res, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"products":{"affected_rows":2}}` {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	gql = `query @skipReturning { products { id } }`
+	if _, err = gj.GraphQL(context.Background(), gql, nil, nil); err == nil {
+		t.Fatal("expected an error for @skipReturning on a query")
+	}
+}
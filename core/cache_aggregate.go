@@ -0,0 +1,251 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// Aggregates carry no row ids so cached responses with aggregates are
+// indexed under scope refs of their table instead. Aggregates over the
+// whole table are indexed under aggScopeAll, aggregates filtered to a single
+// partition under aggScopeAny and the hash of the partition values
+const (
+	aggScopePx  = "agg:"
+	aggScopeAll = aggScopePx + "all"
+	aggScopeAny = aggScopePx + "any"
+)
+
+// cachePartition returns the partition columns of a table
+func (gj *graphjinEngine) cachePartition(table string) []string {
+	for _, t := range gj.tables() {
+		if t.Name == table {
+			return t.CachePartition
+		}
+	}
+	return nil
+}
+
+// aggregateRefs returns the scope refs of the aggregate selects of a query
+func (s *gstate) aggregateRefs(c context.Context, qc *qcode.QCode) (refs []RowRef) {
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+		if !sel.GroupCols && !sel.Count && !sel.Exists {
+			continue
+		}
+		if sel.SkipRender != qcode.SkipTypeNone {
+			continue
+		}
+		table := sel.Ti.Name
+
+		part := s.gj.cachePartition(table)
+		vals, ok := s.partitionValues(c, sel, part)
+		if len(part) == 0 || !ok {
+			refs = append(refs, RowRef{Table: table, ID: aggScopeAll})
+			continue
+		}
+		refs = append(refs,
+			RowRef{Table: table, ID: aggScopeAny},
+			RowRef{Table: table, ID: partitionScope(part, vals)})
+	}
+	return
+}
+
+// partitionValues returns the values the where clause of the select pins
+// the partition columns to, only equality filters joined with and count
+func (s *gstate) partitionValues(c context.Context,
+	sel *qcode.Select,
+	part []string,
+) (map[string]string, bool) {
+	if len(part) == 0 || sel.Where.Exp == nil {
+		return nil, false
+	}
+	vals := make(map[string]string, len(part))
+	s.collectPartitionValues(c, sel, sel.Where.Exp, part, vals)
+
+	for _, col := range part {
+		if _, ok := vals[col]; !ok {
+			return nil, false
+		}
+	}
+	return vals, true
+}
+
+func (s *gstate) collectPartitionValues(c context.Context,
+	sel *qcode.Select,
+	ex *qcode.Exp,
+	part []string,
+	vals map[string]string,
+) {
+	switch ex.Op {
+	case qcode.OpAnd:
+		for _, ch := range ex.Children {
+			s.collectPartitionValues(c, sel, ch, part, vals)
+		}
+
+	case qcode.OpEquals:
+		if len(ex.Joins) != 0 || len(ex.Left.Path) != 0 ||
+			ex.Left.Col.Table != sel.Ti.Name {
+			return
+		}
+		col := ex.Left.Col.Name
+		if !slices.Contains(part, col) {
+			return
+		}
+		if v, ok := s.expValue(c, ex); ok {
+			vals[col] = v
+		}
+	}
+}
+
+// expValue returns the value an expression compares with as a string
+func (s *gstate) expValue(c context.Context, ex *qcode.Exp) (string, bool) {
+	switch ex.Right.ValType {
+	case qcode.ValStr, qcode.ValNum, qcode.ValBool:
+		return ex.Right.Val, true
+
+	case qcode.ValVar:
+		switch ex.Right.Val {
+		case "user_id", "userID", "userId":
+			if v := c.Value(UserIDKey); v != nil {
+				return stringifyID(v), true
+			}
+			return "", false
+		}
+		raw, ok := s.vmap[ex.Right.Val]
+		if !ok {
+			return "", false
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil || v == nil {
+			return "", false
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", false
+		}
+		return stringifyID(v), true
+	}
+	return "", false
+}
+
+// partitionScope returns the scope ref id of a partition
+func partitionScope(part []string, vals map[string]string) string {
+	var sb strings.Builder
+	for _, col := range part {
+		sb.WriteString(col)
+		sb.WriteByte('=')
+		sb.WriteString(vals[col])
+		sb.WriteByte(0)
+	}
+	h := sha256.Sum256([]byte(sb.String()))
+	return aggScopePx + hex.EncodeToString(h[:8])
+}
+
+// mutationAggregateRefs returns the scope refs of the aggregates a mutation
+// changes. Changed rows with all partition columns in the response only
+// invalidate the aggregates of their partition, otherwise the aggregates of
+// every partition of the table are invalidated
+func (gj *graphjinEngine) mutationAggregateRefs(qc *qcode.QCode, data []byte) []RowRef {
+	var dataField, wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(data, &dataField); err != nil {
+		return nil
+	}
+	// the result may be wrapped in a data field
+	if d, ok := dataField["data"]; ok {
+		_ = json.Unmarshal(d, &wrapped)
+	}
+
+	var refs []RowRef
+	for _, m := range qc.Mutates {
+		if m.Type == qcode.MTNone {
+			continue
+		}
+		table := m.Ti.Name
+		refs = append(refs, RowRef{Table: table, ID: aggScopeAll})
+
+		part := gj.cachePartition(table)
+		if len(part) == 0 {
+			continue
+		}
+
+		// rows moved to another partition leave the old one unknown
+		rows, ok := dataField[m.Key]
+		if !ok {
+			rows = wrapped[m.Key]
+		}
+		scopes, ok := rowScopes(part, rows)
+		if !ok || (m.Type != qcode.MTInsert && setsColumn(m, part)) {
+			refs = append(refs, RowRef{Table: table, ID: aggScopeAny})
+			continue
+		}
+		for _, sc := range scopes {
+			refs = append(refs, RowRef{Table: table, ID: sc})
+		}
+	}
+	return refs
+}
+
+// rowScopes returns the partition scopes of the rows in a mutation response,
+// false when a row is missing a partition column
+func rowScopes(part []string, data json.RawMessage) ([]string, bool) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		var row map[string]interface{}
+		if err := json.Unmarshal(data, &row); err != nil || row == nil {
+			return nil, false
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, false
+	}
+
+	var scopes []string
+	for _, row := range rows {
+		vals := make(map[string]string, len(part))
+		for _, col := range part {
+			v, ok := row[col]
+			if !ok || v == nil {
+				return nil, false
+			}
+			vals[col] = stringifyID(v)
+		}
+		if sc := partitionScope(part, vals); !slices.Contains(scopes, sc) {
+			scopes = append(scopes, sc)
+		}
+	}
+	return scopes, true
+}
+
+// setsColumn returns true if the mutation sets any of the columns
+func setsColumn(m qcode.Mutate, cols []string) bool {
+	for _, c := range m.Cols {
+		if slices.Contains(cols, c.Col.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedAggregateRefs returns the scope refs invalidating every cached
+// aggregate of the changed tables, used for changes made outside GraphJin
+func changedAggregateRefs(refs []RowRef) []RowRef {
+	var out []RowRef
+	seen := make(map[string]struct{})
+	for _, ref := range refs {
+		if _, ok := seen[ref.Table]; ok || strings.HasPrefix(ref.ID, aggScopePx) {
+			continue
+		}
+		seen[ref.Table] = struct{}{}
+		out = append(out,
+			RowRef{Table: ref.Table, ID: aggScopeAll},
+			RowRef{Table: ref.Table, ID: aggScopeAny})
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestAggregateCacheScopes(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, account_id INTEGER, total INTEGER);
+		INSERT INTO orders (id, account_id, total) VALUES (1, 1, 10), (2, 2, 20);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc := &testResponseCache{data: make(map[string][]byte)}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Tables: []core.Table{{
+			Name:           "orders",
+			CachePartition: []string{"account_id"},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetResponseCache(rc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(gql, vars string) {
+		t.Helper()
+		var v json.RawMessage
+		if vars != "" {
+			v = json.RawMessage(vars)
+		}
+		res, err := gj.GraphQL(context.Background(), gql, v, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 0 {
+			t.Fatal(res.Errors[0].Message)
+		}
+	}
+
+	// scopes returns the aggregate scope refs of the last cached response
+	scopes := func(gql, vars string) []string {
+		t.Helper()
+		run(gql, vars)
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		var ids []string
+		for _, ref := range rc.refs[len(rc.refs)-1] {
+			if strings.HasPrefix(ref.ID, "agg:") {
+				ids = append(ids, ref.ID)
+			}
+		}
+		return ids
+	}
+
+	// invalidated returns the aggregate scope refs invalidated by a mutation
+	invalidated := func(gql, vars string) []string {
+		t.Helper()
+		rc.mu.Lock()
+		rc.invalidated = nil
+		rc.mu.Unlock()
+
+		run(gql, vars)
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		var ids []string
+		for _, ref := range rc.invalidated {
+			if ref.Table == "orders" && strings.HasPrefix(ref.ID, "agg:") {
+				ids = append(ids, ref.ID)
+			}
+		}
+		return ids
+	}
+
+	all := scopes(`query all { orders { sum_total } }`, "")
+	if !slices.Equal(all, []string{"agg:all"}) {
+		t.Fatalf("expected table scope for an unfiltered aggregate, got %v", all)
+	}
+
+	acc1 := scopes(`query acc1 { orders(where: { account_id: { eq: 1 } }) { sum_total } }`, "")
+	if len(acc1) != 2 || acc1[0] != "agg:any" {
+		t.Fatalf("expected partition scope, got %v", acc1)
+	}
+	acc2 := scopes(`query acc2($acc: Int) { orders(where: { account_id: { eq: $acc } }) { sum_total } }`,
+		`{"acc": 2}`)
+	if len(acc2) != 2 || acc2[1] == acc1[1] {
+		t.Fatalf("expected another partition scope, got %v and %v", acc1, acc2)
+	}
+
+	list := scopes(`query list { orders { id total } }`, "")
+	if len(list) != 0 {
+		t.Fatalf("expected no aggregate scopes for a list, got %v", list)
+	}
+
+	ids := invalidated(`mutation { orders(insert: { id: 3, account_id: 2, total: 5 }) { id account_id } }`, "")
+	if !slices.Contains(ids, "agg:all") || !slices.Contains(ids, acc2[1]) ||
+		slices.Contains(ids, acc1[1]) || slices.Contains(ids, "agg:any") {
+		t.Fatalf("expected only the partition of the new row invalidated, got %v", ids)
+	}
+
+	ids = invalidated(`mutation { orders(update: { total: 7 }, where: { id: { eq: 1 } }) { id } }`, "")
+	if !slices.Contains(ids, "agg:all") || !slices.Contains(ids, "agg:any") {
+		t.Fatalf("expected every partition invalidated without the partition column, got %v", ids)
+	}
+
+	ids = invalidated(`mutation { orders(update: { account_id: 2 }, where: { id: { eq: 1 } }) { id account_id } }`, "")
+	if !slices.Contains(ids, "agg:any") {
+		t.Fatalf("expected every partition invalidated when a row moves, got %v", ids)
+	}
+
+	rc.mu.Lock()
+	rc.invalidated = nil
+	rc.mu.Unlock()
+	if err := gj.NotifyChanges(context.Background(), []core.RowRef{{Table: "orders", ID: "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !slices.Contains(rc.invalidated, core.RowRef{Table: "orders", ID: "agg:any"}) {
+		t.Fatalf("expected external changes to invalidate every aggregate, got %v", rc.invalidated)
+	}
+}
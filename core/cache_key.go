@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -17,7 +18,8 @@ func NewCacheKeyBuilder() *CacheKeyBuilder {
 }
 
 // Build creates a cache key from query parameters and context.
-// The key is a SHA256 hash of: query identifier + query text + variables + user_id + role
+// The key is a SHA256 hash of: query identifier + query text + variables + user_id + role.
+// Variables are normalized so key order and formatting don't change the key.
 func (b *CacheKeyBuilder) Build(
 	ctx context.Context,
 	opName string,
@@ -48,7 +50,7 @@ func (b *CacheKeyBuilder) Build(
 	// Include variables
 	if len(vars) > 0 {
 		h.Write([]byte(":vars:"))
-		h.Write(vars)
+		h.Write(canonicalVars(vars))
 	}
 
 	// Include role for permission isolation
@@ -63,6 +65,22 @@ func (b *CacheKeyBuilder) Build(
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// canonicalVars re-encodes the variables with sorted keys and no whitespace
+func canonicalVars(vars json.RawMessage) []byte {
+	d := json.NewDecoder(bytes.NewReader(vars))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return vars
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return vars
+	}
+	return b
+}
+
 // ShouldCache determines if a query should be cached.
 // Only named queries and APQ queries are cached (skip anonymous).
 func (b *CacheKeyBuilder) ShouldCache(opName, apqKey string) bool {
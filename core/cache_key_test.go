@@ -333,3 +333,39 @@ func TestShouldCache(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheKeyBuilder_VariableOrder(t *testing.T) {
+	builder := NewCacheKeyBuilder()
+	ctx := context.Background()
+	query := []byte(`query GetUsers($limit: Int, $id: Int) { users(limit: $limit, id: $id) { id } }`)
+
+	key1 := builder.Build(ctx, "GetUsers", "", query, json.RawMessage(`{"id": 1, "limit": 10}`), "user")
+	key2 := builder.Build(ctx, "GetUsers", "", query, json.RawMessage(`{ "limit":10,"id":1 }`), "user")
+
+	if key1 != key2 {
+		t.Errorf("expected same key for variables in a different order, got %s vs %s", key1, key2)
+	}
+}
+
+func TestQueryHash(t *testing.T) {
+	hash := func(query string) string {
+		s := gstate{gj: &graphjinEngine{}, r: GraphqlReq{query: []byte(query)}}
+		return s.queryHash()
+	}
+
+	h1 := hash(`query GetUser($id: Int, $email: String) { user(id: $id, email: $email) { id ...f } }
+		fragment f on user { email }`)
+	h2 := hash(`query GetUser($email: String, $id: Int) {
+		user: user(id: $id, email: $email) {
+			id
+			email
+		}
+	}`)
+	if h1 != h2 {
+		t.Errorf("expected same hash for equivalent queries, got %s vs %s", h1, h2)
+	}
+
+	if h3 := hash(`query GetUser($id: Int) { user(id: $id) { id } }`); h1 == h3 {
+		t.Errorf("expected different hashes for different queries")
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+const (
+	// noLimitRows is the number of rows assumed for lists without a limit
+	noLimitRows = 1000
+	// aggregateCost is added for each select that aggregates its rows
+	aggregateCost = 100
+	// maxQueryCost caps the estimated cost of a query
+	maxQueryCost = 1 << 20
+)
+
+type cacheHintsKey struct{}
+
+// CacheHints are passed on the context to the response cache when a response
+// is stored so the cache can pick the TTL of the response
+type CacheHints struct {
+	// Cost is the estimated cost of the query, cheap queries have a low cost
+	// and nested lists and aggregates raise it
+	Cost int
+
+	// TTL is set with the @cache(ttl:) directive and overrides the TTL
+	// picked by the cache, zero when not set
+	TTL time.Duration
+}
+
+// CacheHintsFromContext returns the cache hints of the response being stored
+func CacheHintsFromContext(c context.Context) (CacheHints, bool) {
+	ch, ok := c.Value(cacheHintsKey{}).(CacheHints)
+	return ch, ok
+}
+
+// WithCacheHints returns a context holding the cache hints
+func WithCacheHints(c context.Context, ch CacheHints) context.Context {
+	return context.WithValue(c, cacheHintsKey{}, ch)
+}
+
+// withCacheHints adds the cache hints of the query to the context
+func withCacheHints(c context.Context, qc *qcode.QCode) context.Context {
+	return WithCacheHints(c, CacheHints{
+		Cost: queryCost(qc),
+		TTL:  time.Duration(qc.Cache.TTL) * time.Second,
+	})
+}
+
+// queryCost estimates the cost of a query as the rows it can read: each
+// list reads up to its limit of rows for every row of its parent and each
+// aggregate adds a fixed cost for every row of its parent
+func queryCost(qc *qcode.QCode) int {
+	cost := 0
+	for _, id := range qc.Roots {
+		cost += selectCost(qc, id, 1)
+	}
+	return min(cost, maxQueryCost)
+}
+
+func selectCost(qc *qcode.QCode, id int32, parentRows int) int {
+	sel := &qc.Selects[id]
+	if sel.SkipRender != qcode.SkipTypeNone {
+		return 0
+	}
+
+	rows := min(parentRows*selectRows(sel), maxQueryCost)
+	cost := rows
+	if sel.GroupCols || sel.Count || sel.Exists {
+		cost += parentRows * aggregateCost
+	} else {
+		for _, f := range sel.Fields {
+			if f.Type == qcode.FieldTypeFunc && f.Func.Agg {
+				cost += parentRows * aggregateCost
+				break
+			}
+		}
+	}
+
+	for _, cid := range sel.Children {
+		if cost += selectCost(qc, cid, rows); cost >= maxQueryCost {
+			return maxQueryCost
+		}
+	}
+	return cost
+}
+
+// selectRows returns the rows a select can return for each parent row
+func selectRows(sel *qcode.Select) int {
+	switch {
+	case sel.Singular:
+		return 1
+	case sel.Paging.NoLimit:
+		return noLimitRows
+	case sel.Paging.LimitVar != "" && sel.Paging.MaxLimit > 0:
+		return int(sel.Paging.MaxLimit)
+	case sel.Paging.Limit > 0:
+		return int(sel.Paging.Limit)
+	}
+	return noLimitRows
+}
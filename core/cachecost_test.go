@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCacheHints(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT,
+			user_id INTEGER REFERENCES users(id));
+		INSERT INTO users (id, name) VALUES (1, 'a'), (2, 'b');
+		INSERT INTO posts (id, title, user_id) VALUES (1, 'x', 1), (2, 'y', 2);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc := &testResponseCache{data: make(map[string][]byte)}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetResponseCache(rc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hints := func(gql string) core.CacheHints {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Errors) != 0 {
+			t.Fatal(res.Errors[0].Message)
+		}
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		if len(rc.hints) == 0 {
+			t.Fatal("expected cache hints")
+		}
+		return rc.hints[len(rc.hints)-1]
+	}
+
+	one := hints(`query one { users(id: 1) { id name } }`)
+	list := hints(`query list { users(limit: 10) { id name } }`)
+	nested := hints(`query nested { users(limit: 10) { id posts(limit: 5) { id } } }`)
+	agg := hints(`query agg { users { count_id } }`)
+
+	if one.Cost != 1 {
+		t.Fatalf("expected cost 1 for a single row, got %d", one.Cost)
+	}
+	if list.Cost != 10 {
+		t.Fatalf("expected cost 10 for a list of 10, got %d", list.Cost)
+	}
+	if nested.Cost != 60 {
+		t.Fatalf("expected cost 60 for nested lists, got %d", nested.Cost)
+	}
+	if agg.Cost <= list.Cost {
+		t.Fatalf("expected aggregate to cost more than a list, got %d", agg.Cost)
+	}
+	if one.TTL != 0 {
+		t.Fatalf("expected no ttl override, got %s", one.TTL)
+	}
+
+	ttl := hints(`query ttl @cache(ttl: 90) { users(limit: 3) { id } }`)
+	if ttl.TTL != 90*time.Second {
+		t.Fatalf("expected ttl 90s, got %s", ttl.TTL)
+	}
+
+	_, err = gj.GraphQL(context.Background(), `query zero @cache(ttl: 0) { users { id } }`, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for a zero ttl")
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+)
+
+// NotifyChanges tells GraphJin about rows changed outside of it, for example
+// by another service writing to the database directly. Cached responses
+// containing the rows are invalidated and subscriptions reading the changed
+// tables are polled right away instead of waiting for the next poll.
+func (g *GraphJin) NotifyChanges(c context.Context, refs []RowRef) error {
+	gj, err := g.getEngine()
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	if gj.responseCache != nil {
+		// the changed rows may be counted by any cached aggregate
+		err = gj.responseCache.InvalidateRows(c,
+			append(refs[:len(refs):len(refs)], changedAggregateRefs(refs)...))
+	}
+
+	changed := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		changed[ref.Table] = struct{}{}
+	}
+
+	gj.subs.Range(func(_, v any) bool {
+		if s, ok := v.(*sub); ok && s.readsTables(changed) {
+			select {
+			case s.poke <- struct{}{}:
+			default:
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// readsTables returns true if the subscription query reads any of the tables
+func (s *sub) readsTables(tables map[string]struct{}) bool {
+	st := s.tables.Load()
+	if st == nil {
+		return false
+	}
+	for t := range *st {
+		if _, ok := tables[t]; ok {
+			return true
+		}
+	}
+	return false
+}
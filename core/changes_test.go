@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestNotifyChanges(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE chats (id INTEGER PRIMARY KEY, body TEXT);
+		INSERT INTO chats (id, body) VALUES (1, 'hello');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// poll so rarely that only a notification can deliver the change
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		SubsPollDuration: time.Hour,
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m, err := gj.Subscribe(ctx, `subscription { chats(id: 1) { id body } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Unsubscribe()
+
+	select {
+	case <-m.Result:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the first result")
+	}
+
+	if _, err := db.Exec(`UPDATE chats SET body = 'changed' WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	err = gj.NotifyChanges(ctx, []core.RowRef{{Table: "chats", ID: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case res := <-m.Result:
+		if !strings.Contains(string(res.Data), "changed") {
+			t.Errorf("expected the changed row, got %s", res.Data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not refreshed by the change notification")
+	}
+}
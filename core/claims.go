@@ -0,0 +1,192 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ClaimVar maps a claim of the users JWT to a variable that can be used in
+// role filters, presets and queries (eg. { org_id: { eq: $claim_org_id } })
+type ClaimVar struct {
+	// Name of the variable (eg. claim_org_id will be $claim_org_id)
+	Name string `jsonschema:"title=Variable Name"`
+
+	// Name of the claim, nested claims are separated by a dot (eg. app.org_id).
+	// A claim with the full name is looked up before the nested one
+	Claim string `jsonschema:"title=Claim Name"`
+
+	// Type of the claim value. Defaults to string
+	Type string `jsonschema:"title=Type,enum=string,enum=int,enum=float,enum=bool,enum=[]string,enum=[]int,enum=[]float,enum=[]bool"`
+
+	// Fail requests of authenticated users that don't have the claim,
+	// otherwise the variable is null
+	Required bool `jsonschema:"title=Required,default=false"`
+}
+
+// validateClaimVars checks the claim variables and sets their defaults
+func validateClaimVars(c *Config) error {
+	names := make(map[string]struct{}, len(c.ClaimVars))
+
+	for i := range c.ClaimVars {
+		cv := &c.ClaimVars[i]
+
+		if cv.Name == "" {
+			return fmt.Errorf("claim_variables: name required")
+		}
+		if cv.Claim == "" {
+			return fmt.Errorf("claim_variables: %s: claim required", cv.Name)
+		}
+		if _, ok := names[cv.Name]; ok {
+			return fmt.Errorf("claim_variables: %s: duplicate variable", cv.Name)
+		}
+		names[cv.Name] = struct{}{}
+
+		switch cv.Name {
+		case "user_id", "userID", "userId",
+			"user_id_raw", "userIDRaw", "userIdRaw",
+			"user_id_provider", "userIDProvider", "userIdProvider",
+			"user_role", "userRole", "cursor":
+			return fmt.Errorf("claim_variables: %s: reserved variable name", cv.Name)
+		}
+		if _, ok := c.Vars[cv.Name]; ok {
+			return fmt.Errorf("claim_variables: %s: already defined in variables", cv.Name)
+		}
+
+		if cv.Type == "" {
+			cv.Type = "string"
+		}
+		switch strings.TrimPrefix(cv.Type, "[]") {
+		case "string", "int", "float", "bool":
+		default:
+			return fmt.Errorf("claim_variables: %s: invalid type '%s'", cv.Name, cv.Type)
+		}
+	}
+	return nil
+}
+
+// checkClaimVars validates the claim variables at the start of a request so
+// a missing or badly typed claim fails the request before anything is compiled
+func (gj *graphjinEngine) checkClaimVars(c context.Context) error {
+	for i := range gj.conf.ClaimVars {
+		if _, err := gj.conf.ClaimVars[i].value(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claimVar returns the claim variable with the name
+func (gj *graphjinEngine) claimVar(name string) (*ClaimVar, bool) {
+	for i := range gj.conf.ClaimVars {
+		if gj.conf.ClaimVars[i].Name == name {
+			return &gj.conf.ClaimVars[i], true
+		}
+	}
+	return nil, false
+}
+
+// value returns the value of the claim from the claims on the context. Lists
+// are returned as json so they can be used with the in and has operators
+func (cv *ClaimVar) value(c context.Context) (interface{}, error) {
+	claims, _ := c.Value(UserClaimsKey).(map[string]interface{})
+
+	v, ok := lookupClaim(claims, cv.Claim)
+	if !ok || v == nil {
+		// anonymous requests have no claims
+		if cv.Required && c.Value(UserIDKey) != nil {
+			return nil, fmt.Errorf("claim '%s' required for variable '%s'", cv.Claim, cv.Name)
+		}
+		return nil, nil
+	}
+
+	t, isList := strings.CutPrefix(cv.Type, "[]")
+	if !isList {
+		v1, err := claimValue(t, v)
+		if err != nil {
+			return nil, fmt.Errorf("claim '%s': %w", cv.Claim, err)
+		}
+		return v1, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("claim '%s': expected a list of type '%s': %T", cv.Claim, t, v)
+	}
+	vals := make([]interface{}, len(list))
+	for i, e := range list {
+		v1, err := claimValue(t, e)
+		if err != nil {
+			return nil, fmt.Errorf("claim '%s': %w", cv.Claim, err)
+		}
+		vals[i] = v1
+	}
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(b), nil
+}
+
+// lookupClaim finds the claim by its full name and then as a dotted path
+func lookupClaim(claims map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := claims[name]; ok {
+		return v, true
+	}
+	var v interface{} = claims
+	for _, k := range strings.Split(name, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// claimValue converts a claim value to the type, json numbers are float64
+func claimValue(t string, v interface{}) (interface{}, error) {
+	switch t {
+	case "string":
+		if v1, ok := v.(string); ok {
+			return v1, nil
+		}
+	case "int":
+		switch v1 := v.(type) {
+		case float64:
+			if v1 == math.Trunc(v1) {
+				return int64(v1), nil
+			}
+		case int64:
+			return v1, nil
+		case int:
+			return int64(v1), nil
+		case json.Number:
+			if n, err := v1.Int64(); err == nil {
+				return n, nil
+			}
+		}
+	case "float":
+		switch v1 := v.(type) {
+		case float64:
+			return v1, nil
+		case int64:
+			return float64(v1), nil
+		case int:
+			return float64(v1), nil
+		case json.Number:
+			if n, err := v1.Float64(); err == nil {
+				return n, nil
+			}
+		}
+	case "bool":
+		if v1, ok := v.(bool); ok {
+			return v1, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a value of type '%s': %v", t, v)
+}
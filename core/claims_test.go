@@ -0,0 +1,143 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestClaimVars(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, org_id INTEGER);
+		INSERT INTO products (id, name, org_id) VALUES (1, 'Lamp', 1), (2, 'Desk', 2), (3, 'Sofa', 3);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		ClaimVars: []core.ClaimVar{
+			{Name: "claim_org_id", Claim: "app.org_id", Type: "int", Required: true},
+			{Name: "claim_orgs", Claim: "orgs", Type: "[]int"},
+		},
+		Roles: []core.Role{
+			{Name: "user", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{
+					Filters: []string{`{ org_id: { eq: $claim_org_id } }`},
+				}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withClaims := func(claims map[string]interface{}) context.Context {
+		c := context.WithValue(context.Background(), core.UserIDKey, 1)
+		return context.WithValue(c, core.UserClaimsKey, claims)
+	}
+
+	gql := `query { products(order_by: { id: asc }) { id name } }`
+
+	t.Run("filter", func(t *testing.T) {
+		c := withClaims(map[string]interface{}{
+			"app": map[string]interface{}{"org_id": float64(2)},
+		})
+		res, err := gj.GraphQL(c, gql, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := `{"products":[{"id":2,"name":"Desk"}]}`; string(res.Data) != exp {
+			t.Fatalf("unexpected data: %s", res.Data)
+		}
+	})
+
+	t.Run("request cannot override", func(t *testing.T) {
+		c := withClaims(map[string]interface{}{
+			"app": map[string]interface{}{"org_id": float64(2)},
+		})
+		vars := []byte(`{ "claim_org_id": 1 }`)
+		res, err := gj.GraphQL(c, gql, vars, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := `{"products":[{"id":2,"name":"Desk"}]}`; string(res.Data) != exp {
+			t.Fatalf("unexpected data: %s", res.Data)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		c := withClaims(map[string]interface{}{
+			"app.org_id": float64(1),
+			"orgs":       []interface{}{float64(1), float64(3)},
+		})
+		gql := `query { products(where: { org_id: { in: $claim_orgs } }, order_by: { id: asc }) { id } }`
+		res, err := gj.GraphQL(c, gql, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the role filter still applies
+		if exp := `{"products":[{"id":1}]}`; string(res.Data) != exp {
+			t.Fatalf("unexpected data: %s", res.Data)
+		}
+	})
+
+	t.Run("missing required claim", func(t *testing.T) {
+		_, err := gj.GraphQL(withClaims(map[string]interface{}{}), gql, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "claim 'app.org_id' required") {
+			t.Fatalf("expected a missing claim error, got %v", err)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		c := withClaims(map[string]interface{}{"app.org_id": "two"})
+		_, err := gj.GraphQL(c, gql, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "expected a value of type 'int'") {
+			t.Fatalf("expected a type error, got %v", err)
+		}
+	})
+}
+
+func TestClaimVarsConfig(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	if _, err := db.Exec(`CREATE TABLE products (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cv  core.ClaimVar
+		err string
+	}{
+		{core.ClaimVar{Name: "org", Claim: "org", Type: "uuid"}, "invalid type"},
+		{core.ClaimVar{Name: "user_id", Claim: "sub"}, "reserved variable name"},
+		{core.ClaimVar{Name: "org"}, "claim required"},
+	}
+	for _, tt := range tests {
+		conf := &core.Config{DBType: "sqlite", ClaimVars: []core.ClaimVar{tt.cv}}
+		_, err := core.NewGraphJin(conf, db)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%+v: expected error '%s', got %v", tt.cv, tt.err, err)
+		}
+	}
+}
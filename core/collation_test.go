@@ -0,0 +1,70 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCaseInsensitiveOrderAndFilter(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES
+			(1, 'bob'), (2, 'Alice'), (3, 'Carol'), (4, 'alice');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		gql  string
+		want string
+	}{
+		{
+			name: "asc_ci",
+			gql:  `query { users(order_by: { name: asc_ci, id: asc }) { id } }`,
+			want: `{"users":[{"id":2},{"id":4},{"id":1},{"id":3}]}`,
+		},
+		{
+			name: "desc_ci",
+			gql:  `query { users(order_by: { name: desc_ci, id: asc }) { id } }`,
+			want: `{"users":[{"id":3},{"id":1},{"id":2},{"id":4}]}`,
+		},
+		{
+			name: "eq_ci",
+			gql:  `query { users(where: { name: { eq_ci: "ALICE" } }, order_by: { id: asc }) { id } }`,
+			want: `{"users":[{"id":2},{"id":4}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := gj.GraphQL(context.Background(), tt.gql, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(res.Data); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
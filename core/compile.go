@@ -0,0 +1,83 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+)
+
+// CompiledStatement is a query compiled for one database without being run
+type CompiledStatement struct {
+	Database  string      `json:"database"`
+	DBType    string      `json:"db_type"`
+	Statement string      `json:"statement,omitempty"`
+	Params    []ParamInfo `json:"params,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// CompileOnly compiles the query for every configured database and returns
+// the generated statement (SQL or a MongoDB pipeline) and its parameters per
+// database, sorted by database name. Nothing is executed. A database that
+// cannot compile the query, for example because the table is missing, has
+// its error set instead. The role defaults to anon.
+func (g *GraphJin) CompileOnly(query string, vars json.RawMessage, role string) ([]CompiledStatement, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	if !gj.anyDatabaseReady() {
+		return nil, fmt.Errorf("schema not initialized")
+	}
+
+	if _, err := graph.Parse([]byte(query)); err != nil {
+		return nil, err
+	}
+
+	var vmap map[string]json.RawMessage
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &vmap); err != nil {
+			return nil, fmt.Errorf("variables: %w", err)
+		}
+	}
+
+	if role == "" {
+		role = "anon"
+	}
+
+	var list []CompiledStatement
+	for _, name := range gj.sortedDatabaseNames() {
+		ctx := gj.databases[name]
+		if ctx.qcodeCompiler == nil || ctx.psqlCompiler == nil {
+			continue
+		}
+		cs := CompiledStatement{Database: name, DBType: ctx.dbtype}
+
+		qc, err := ctx.qcodeCompiler.Compile([]byte(query), vmap, role, gj.namespace)
+		if err != nil {
+			cs.Error = err.Error()
+			list = append(list, cs)
+			continue
+		}
+
+		var w bytes.Buffer
+		md, err := ctx.psqlCompiler.Compile(&w, qc)
+		if err != nil {
+			cs.Error = err.Error()
+			list = append(list, cs)
+			continue
+		}
+
+		cs.Statement = w.String()
+		for _, p := range md.Params() {
+			cs.Params = append(cs.Params, ParamInfo{
+				Name:    p.Name,
+				Type:    p.Type,
+				IsArray: p.IsArray,
+			})
+		}
+		list = append(list, cs)
+	}
+	return list, nil
+}
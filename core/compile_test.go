@@ -0,0 +1,81 @@
+package core_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCompileOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name, schema string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+		if _, err := db.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	mainDB := openDB("main", `CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);`)
+	copyDB := openDB("copy", `CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);`)
+	auditDB := openDB("audit", `CREATE TABLE audit_logs (id INTEGER PRIMARY KEY, action TEXT);`)
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			"main":  {Type: "sqlite"},
+			"copy":  {Type: "sqlite"},
+			"audit": {Type: "sqlite"},
+		},
+		Tables: []core.Table{{Name: "products", Database: "main"}},
+	}
+	gj, err := core.NewGraphJin(conf, mainDB,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"main": mainDB, "copy": copyDB, "audit": auditDB}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := gj.CompileOnly(`query { products(id: $id) { id name } }`,
+		json.RawMessage(`{"id": 1}`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("expected a statement per database, got %+v", list)
+	}
+
+	for _, cs := range list {
+		switch cs.Database {
+		case "audit":
+			if cs.Error == "" {
+				t.Errorf("expected an error for the database without the table: %+v", cs)
+			}
+		case "main", "copy":
+			if cs.Error != "" || cs.DBType != "sqlite" || !strings.Contains(cs.Statement, "products") {
+				t.Errorf("unexpected statement for %s: %+v", cs.Database, cs)
+			}
+			if len(cs.Params) != 1 || cs.Params[0].Name != "id" {
+				t.Errorf("expected the id parameter for %s, got %+v", cs.Database, cs.Params)
+			}
+		default:
+			t.Errorf("unexpected database: %s", cs.Database)
+		}
+	}
+
+	if _, err := gj.CompileOnly(`query { products { id `, nil, ""); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
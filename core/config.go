@@ -63,6 +63,19 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.Naming.Strategy {
+	case "", "snake_case", "camelCase":
+	default:
+		return fmt.Errorf("naming: unknown strategy %q: supported strategies are snake_case and camelCase", c.Naming.Strategy)
+	}
+
+	if tz := c.Timezone.Output; tz != "" && !timezoneRe.MatchString(tz) {
+		return fmt.Errorf("timezone: invalid output timezone %q", tz)
+	}
+	if v := c.Timezone.Variable; v != "" && !varNameRe.MatchString(v) {
+		return fmt.Errorf("timezone: invalid variable name %q", v)
+	}
+
 	// Validate partition configs
 	for _, t := range c.Tables {
 		if t.Partition != nil {
@@ -73,6 +86,14 @@ func (c *Config) Validate() error {
 				return fmt.Errorf("table %q: partition default_range_days must not be negative", t.Name)
 			}
 		}
+		if t.Shards != nil {
+			if t.Shards.Key == "" {
+				return fmt.Errorf("table %q: shards key must not be empty", t.Name)
+			}
+			if len(t.Shards.Databases) == 0 {
+				return fmt.Errorf("table %q: shards databases must not be empty", t.Name)
+			}
+		}
 	}
 
 	return nil
@@ -87,6 +108,12 @@ func (c *Config) clone() *Config {
 	if c.Tables != nil {
 		out.Tables = make([]Table, len(c.Tables))
 		copy(out.Tables, c.Tables)
+		for i, t := range c.Tables {
+			if t.Columns != nil {
+				out.Tables[i].Columns = make([]Column, len(t.Columns))
+				copy(out.Tables[i].Columns, t.Columns)
+			}
+		}
 	}
 
 	if c.Databases != nil {
@@ -180,6 +207,11 @@ type Config struct {
 	// Is used to encrypt opaque values such as the cursor. Auto-generated when not set
 	SecretKey string `mapstructure:"secret_key" json:"secret_key" yaml:"secret_key"  jsonschema:"title=Secret Key"`
 
+	// Previous secret keys still accepted when decrypting cursors and other opaque
+	// values. Set this to the old secret key when rotating the secret key so
+	// clients paginating with existing cursors are not broken
+	PreviousSecretKeys []string `mapstructure:"previous_secret_keys" json:"previous_secret_keys" yaml:"previous_secret_keys" jsonschema:"title=Previous Secret Keys"`
+
 	// When set to true it disables the allow list workflow
 	DisableAllowList bool `mapstructure:"disable_allow_list" json:"disable_allow_list" yaml:"disable_allow_list" jsonschema:"title=Disable Allow List,default=false"`
 
@@ -188,11 +220,22 @@ type Config struct {
 	// in production mode.
 	EnableSchema bool `mapstructure:"enable_schema" json:"enable_schema" yaml:"enable_schema" jsonschema:"title=Enable Schema,default=false"`
 
+	// Path to a schema snapshot file holding the discovered schema of all
+	// configured databases. In dev mode the snapshot is written after discovery,
+	// when a database is unreachable at startup its schema is loaded from the
+	// snapshot instead and GraphJin starts in a degraded mode
+	SchemaSnapshot string `mapstructure:"schema_snapshot" json:"schema_snapshot" yaml:"schema_snapshot" jsonschema:"title=Schema Snapshot File"`
+
 	// When set to true an introspection json file will be generated in dev mode.
 	// This file can be used with other GraphQL tooling to generate clients, enable
 	// autocomplete, etc
 	EnableIntrospection bool `mapstructure:"enable_introspection" json:"enable_introspection" yaml:"enable_introspection" jsonschema:"title=Generate introspection JSON,default=false"`
 
+	// When set to true GraphJin serves as an Apollo Federation subgraph. The
+	// _service and _entities queries of the router are answered and tables
+	// with a single column primary key are entities keyed by it
+	EnableFederation bool `mapstructure:"enable_federation" json:"enable_federation" yaml:"enable_federation" jsonschema:"title=Enable Federation,default=false"`
+
 	// Forces the database session variable 'user.id' to be set to the user id
 	SetUserID bool `mapstructure:"set_user_id" json:"set_user_id" yaml:"set_user_id" jsonschema:"title=Set User ID,default=false"`
 
@@ -201,6 +244,11 @@ type Config struct {
 	// they have to be added to the 'anon' role config
 	DefaultBlock bool `mapstructure:"default_block" json:"default_block" yaml:"default_block" jsonschema:"title=Block tables for anonymous users,default=true"`
 
+	// This blocks all tables for all roles, only the tables and operations
+	// (query, insert, update, upsert, delete) listed under a role are allowed.
+	// What each role can access is logged at startup.
+	DefaultDeny bool `mapstructure:"default_deny" json:"default_deny" yaml:"default_deny" jsonschema:"title=Deny access unless granted to the role,default=false"`
+
 	// This is a list of variables that can be leveraged in your queries.
 	// (eg. variable admin_id will be $admin_id in the query)
 	Vars map[string]string `mapstructure:"variables" json:"variables" yaml:"variables" jsonschema:"title=Variables"`
@@ -208,6 +256,16 @@ type Config struct {
 	// This is a list of variables that map to http header values
 	HeaderVars map[string]string `mapstructure:"header_variables" json:"header_variables" yaml:"header_variables" jsonschema:"title=Header Variables"`
 
+	// This is a list of variables that map to request metadata: client_ip,
+	// user_agent, geo_country, geo_region, geo_city or header:<name>
+	// (eg. variable created_ip: client_ip will be $created_ip in the query)
+	RequestVars map[string]string `mapstructure:"request_variables" json:"request_variables" yaml:"request_variables" jsonschema:"title=Request Variables"`
+
+	// This is a list of variables that map to claims of the users JWT, the
+	// claims are type checked at the start of every request
+	// (eg. variable claim_org_id will be $claim_org_id in the query)
+	ClaimVars []ClaimVar `mapstructure:"claim_variables" json:"claim_variables" yaml:"claim_variables" jsonschema:"title=Claim Variables"`
+
 	// A list of tables and columns that should disallowed in any and all queries
 	Blocklist []string `jsonschema:"title=Block List"`
 
@@ -222,6 +280,11 @@ type Config struct {
 	// All function specific configuration such as return types
 	Functions []Function `jsonschema:"title=Functions"`
 
+	// Named SQL filters that can be used in role filters and where clauses
+	// as { _filter: "active_users" }. Columns in the filter are checked
+	// against the table the filter is used on.
+	Filters map[string]string `jsonschema:"title=Named Filters,example=status = 'active' AND deleted_at IS NULL"`
+
 	// An SQL query if set enables attribute based access control. This query is
 	// used to fetch the user attribute that then dynamically define the users role
 	RolesQuery string `mapstructure:"roles_query" json:"roles_query" yaml:"roles_query" jsonschema:"title=Roles Query"`
@@ -237,6 +300,18 @@ type Config struct {
 	// Log warnings and other debug information
 	Debug bool `jsonschema:"title=Debug,default=false"`
 
+	// Report the tables and fields left out of a result by authorization rules
+	// (role blocks, column lists, the blocklist, ifRole directives) in the
+	// response extensions. Fields the role can't query are left out instead
+	// of failing the query. Only used in development mode
+	AuthTrace bool `mapstructure:"auth_trace" json:"auth_trace" yaml:"auth_trace" jsonschema:"title=Authorization Trace,default=false"`
+
+	// Add the role and namespace to the comment on the generated SQL
+	// (eg. /* action='getUsers',...,role='user' */) so load seen in
+	// pg_stat_activity or the slow query log can be traced back to the
+	// query. Can be set per database with the query_tags of the database
+	QueryTags bool `mapstructure:"query_tags" json:"query_tags" yaml:"query_tags" jsonschema:"title=Query Tags,default=false"`
+
 	// Log SQL Query variable values
 	LogVars bool `mapstructure:"log_vars" json:"log_vars" yaml:"log_vars" jsonschema:"title=Log Variables,default=false"`
 
@@ -248,6 +323,48 @@ type Config struct {
 	// the query or the table role config.
 	DefaultLimit int `mapstructure:"default_limit" json:"default_limit" yaml:"default_limit" jsonschema:"title=Default Row Limit,default=20"`
 
+	// Return an error instead of clamping the limit when a query asks for
+	// more rows than the max_limit of the table or role
+	MaxLimitError bool `mapstructure:"max_limit_error" json:"max_limit_error" yaml:"max_limit_error" jsonschema:"title=Error on Max Limit,default=false"`
+
+	// Max size in bytes of a query response. Larger responses fail unless
+	// truncate_responses is set. Zero disables the limit
+	MaxResponseBytes int `mapstructure:"max_response_bytes" json:"max_response_bytes" yaml:"max_response_bytes" jsonschema:"title=Max Response Bytes,default=0"`
+
+	// Trim the trailing items of list fields in responses over max_response_bytes
+	// and mark the response with a truncated extension instead of failing
+	TruncateResponses bool `mapstructure:"truncate_responses" json:"truncate_responses" yaml:"truncate_responses" jsonschema:"title=Truncate Responses,default=false"`
+
+	// Limits the sub-queries, rows and time a single request can use when it
+	// fans out across databases and remote APIs
+	Budget QueryBudget `mapstructure:"budget" json:"budget" yaml:"budget" jsonschema:"title=Query Budget"`
+
+	// Default timeouts for queries, mutations and subscriptions. The earlier
+	// of the timeout and the request context deadline bounds the SQL queries
+	// and the time left for database and remote joins
+	Timeouts OperationTimeouts `mapstructure:"timeouts" json:"timeouts" yaml:"timeouts" jsonschema:"title=Operation Timeouts"`
+
+	// Overrides merged over this config for requests made in a namespace. Each
+	// namespace can also have its own allow list folder (queries/<namespace>)
+	Namespaces map[string]NamespaceConfig `mapstructure:"namespaces" json:"namespaces" yaml:"namespaces" jsonschema:"title=Namespaces"`
+
+	// Inserts with more rows than this are handed to the database's bulk
+	// loader (eg. COPY for Postgres) and return the inserted count instead
+	// of the rows. Zero disables bulk loading
+	BulkInsertThreshold int `mapstructure:"bulk_insert_threshold" json:"bulk_insert_threshold" yaml:"bulk_insert_threshold" jsonschema:"title=Bulk Insert Threshold,default=0"`
+
+	// Record the mutations on a list of tables with the old and new values
+	// of the changed rows
+	Audit AuditConfig `mapstructure:"audit" json:"audit" yaml:"audit" jsonschema:"title=Audit Log"`
+
+	// Stage the mutations on a list of tables until they are approved by
+	// another role
+	Approvals ApprovalConfig `mapstructure:"approvals" json:"approvals" yaml:"approvals" jsonschema:"title=Mutation Approvals"`
+
+	// Run a list of mutations in SERIALIZABLE or SNAPSHOT transactions that
+	// are retried on serialization failures
+	Serializable SerializableConfig `mapstructure:"serializable" json:"serializable" yaml:"serializable" jsonschema:"title=Serializable Mutations"`
+
 	// Disable all aggregation functions like count, sum, etc
 	DisableAgg bool `mapstructure:"disable_agg_functions" json:"disable_agg_functions" yaml:"disable_agg_functions" jsonschema:"title=Disable Aggregations,default=false"`
 
@@ -258,9 +375,44 @@ type Config struct {
 	// return mock data based on the query structure.
 	MockDB bool `mapstructure:"mock_db" json:"mock_db" yaml:"mock_db" jsonschema:"title=Mock DB,default=false"`
 
+	// Return decimal and numeric columns as strings so precision is not lost
+	// when they are parsed as floats, they are typed as the Decimal scalar
+	DecimalAsString bool `mapstructure:"decimal_as_string" json:"decimal_as_string" yaml:"decimal_as_string" jsonschema:"title=Decimal As String,default=false"`
+
+	// Return 64-bit integer columns as strings since javascript clients lose
+	// precision above 2^53, they are typed as the BigInt scalar
+	BigIntAsString bool `mapstructure:"bigint_as_string" json:"bigint_as_string" yaml:"bigint_as_string" jsonschema:"title=BigInt As String,default=false"`
+
+	// Timezone timestamp columns are returned in and checks on timestamp
+	// values in filters and variables
+	Timezone TimezoneConfig `mapstructure:"timezone" json:"timezone" yaml:"timezone" jsonschema:"title=Timezone"`
+
 	// Enable automatic coversion of camel case in GraphQL to snake case in SQL
 	EnableCamelcase bool `mapstructure:"enable_camelcase" json:"enable_camelcase" yaml:"enable_camelcase" jsonschema:"title=Enable Camel Case,default=false"`
 
+	// Naming strategy used to expose table and column names in GraphQL, includes
+	// acronym handling and per-table and per-column name overrides
+	Naming Naming `mapstructure:"naming" json:"naming" yaml:"naming" jsonschema:"title=Naming Strategy"`
+
+	// When set to true conventional CRUD REST routes are exposed for every table
+	// (eg. GET /users, POST /users, PATCH /users/{id}). These queries are compiled
+	// through the same role system as any other request. In production a table and
+	// operation is only served when it is configured on the role
+	EnableCRUD bool `mapstructure:"enable_crud" json:"enable_crud" yaml:"enable_crud" jsonschema:"title=Enable CRUD Routes,default=false"`
+
+	// When set to true and production mode is enabled the compiled SQL of the
+	// allow-listed queries is persisted under the plans folder when each query
+	// and role is first compiled, and loaded at startup so SQL generation is
+	// skipped after a restart. Plans are keyed by query hash, schema checksum
+	// and database dialect so stale entries are ignored after a schema or
+	// config change
+	EnablePlanCache bool `mapstructure:"enable_plan_cache" json:"enable_plan_cache" yaml:"enable_plan_cache" jsonschema:"title=Enable Plan Cache,default=false"`
+
+	// When set to true relationships between Postgres foreign data wrapper
+	// tables and tables on another server are fetched with separate batched
+	// queries like cross-database joins instead of being joined in SQL
+	SeparateForeignJoins bool `mapstructure:"separate_foreign_joins" json:"separate_foreign_joins" yaml:"separate_foreign_joins" jsonschema:"title=Separate Foreign Table Joins,default=false"`
+
 	// When enabled GraphJin runs with production level security defaults.
 	// For example allow lists are enforced.
 	Production bool `jsonschema:"title=Production Mode,default=false"`
@@ -350,6 +502,20 @@ type DatabaseConfig struct {
 	// Read-only mode — blocks all mutations and DDL against this database.
 	// Once set in config, cannot be changed at runtime via MCP tools.
 	ReadOnly bool `mapstructure:"read_only" json:"read_only" yaml:"read_only" jsonschema:"title=Read Only"`
+
+	// Reads routed to read replicas are also sent to a second replica when the
+	// first has not answered after this delay, the first result returned is
+	// used. Zero disables hedging
+	HedgeDelay time.Duration `mapstructure:"hedge_delay" json:"hedge_delay" yaml:"hedge_delay" jsonschema:"title=Hedge Delay,example=20ms"`
+
+	// Discover the schema of this database when it is first used instead of
+	// at startup. The default database and databases referenced by cross
+	// database foreign keys are always discovered at startup
+	Lazy bool `mapstructure:"lazy" json:"lazy" yaml:"lazy" jsonschema:"title=Lazy Schema Discovery"`
+
+	// Add the role and namespace to the comment on the SQL run on this
+	// database, overrides the query_tags of the config
+	QueryTags *bool `mapstructure:"query_tags" json:"query_tags,omitempty" yaml:"query_tags,omitempty" jsonschema:"title=Query Tags"`
 }
 
 // SnowflakeKeyPairConfig allows external services to inject Snowflake key pair
@@ -375,12 +541,75 @@ type Table struct {
 	Database  string `mapstructure:"database" json:"database" yaml:"database" jsonschema:"title=Database"`
 	Blocklist []string
 	Columns   []Column
+	// Relationships merged into the discovered schema, used with databases
+	// that do not define foreign keys
+	Relationships []Relationship `mapstructure:"relationships" json:"relationships" yaml:"relationships" jsonschema:"title=Relationships"`
+	// GraphQL name exposed for the table (eg. customers for a legacy cust_tbl table)
+	Alias string `mapstructure:"alias" json:"alias" yaml:"alias" jsonschema:"title=Alias,example=customers"`
+	// Reason shown in introspection to mark the table as deprecated
+	Deprecated string `mapstructure:"deprecated" json:"deprecated" yaml:"deprecated" jsonschema:"title=Deprecation Reason"`
 	// Permitted order by options
 	OrderBy map[string][]string `mapstructure:"order_by" json:"order_by" yaml:"order_by" jsonschema:"title=Order By Options,example=created_at desc"`
+	// Generates the primary key on insert when it is not in the data and the
+	// column has no database default (uuidv7, ulid or snowflake)
+	IDGenerator string `mapstructure:"id_generator" json:"id_generator" yaml:"id_generator" jsonschema:"title=ID Generator,enum=uuidv7,enum=ulid,enum=snowflake"`
+	// Default limit (number of rows) for the table when a limit is not defined
+	// in the query or the table role config
+	DefaultLimit int `mapstructure:"default_limit" json:"default_limit" yaml:"default_limit" jsonschema:"title=Default Row Limit"`
+	// Max limit (number of rows) for the table, larger limits are clamped
+	MaxLimit int `mapstructure:"max_limit" json:"max_limit" yaml:"max_limit" jsonschema:"title=Max Row Limit"`
 	// Partition configuration for warehouse-optimized queries (Snowflake, BigQuery).
+	// Range partitioned Postgres tables get their partition key from discovery.
 	// When set, queries without a filter on the partition column will either get a
 	// default time-range filter injected or produce a warning.
 	Partition *PartitionConfig `mapstructure:"partition" json:"partition,omitempty" yaml:"partition,omitempty" jsonschema:"title=Partition Configuration"`
+	// Shards declares the table as split across several of the configured
+	// databases, requests are routed to one of them by a shard key variable
+	Shards *ShardConfig `mapstructure:"shards" json:"shards,omitempty" yaml:"shards,omitempty" jsonschema:"title=Shard Configuration"`
+	// TailCache declares the table as append-only (eg. events or logs), cached
+	// lists ordered by the tail column only fetch the rows added since
+	TailCache *TailCacheConfig `mapstructure:"tail_cache" json:"tail_cache,omitempty" yaml:"tail_cache,omitempty" jsonschema:"title=Tail Cache"`
+	// Constraints sets the messages returned when a mutation violates a
+	// CHECK or unique constraint of the table
+	Constraints []ConstraintConfig `mapstructure:"constraints" json:"constraints,omitempty" yaml:"constraints,omitempty" jsonschema:"title=Constraint Messages"`
+	// CachePartition lists the columns that partition the table (eg. a tenant
+	// or account column). Cached aggregates filtered on these columns are only
+	// invalidated by changes to rows of the same partition
+	CachePartition []string `mapstructure:"cache_partition" json:"cache_partition,omitempty" yaml:"cache_partition,omitempty" jsonschema:"title=Cache Partition Columns,example=account_id"`
+}
+
+// ConstraintConfig is the message for a CHECK or unique constraint, it is
+// matched by name or, for databases that don't name the constraint in their
+// errors (eg. SQLite unique constraints), by its columns
+type ConstraintConfig struct {
+	// Name of the constraint in the database
+	Name string `mapstructure:"name" json:"name" yaml:"name" jsonschema:"title=Constraint Name,example=users_email_key"`
+	// Columns of the constraint, reported for databases that don't name them
+	Columns []string `mapstructure:"columns" json:"columns,omitempty" yaml:"columns,omitempty" jsonschema:"title=Constraint Columns,example=email"`
+	// Message returned to the client
+	Message string `mapstructure:"message" json:"message" yaml:"message" jsonschema:"title=Message,example=This email is already registered"`
+}
+
+// TailCacheConfig enables differential caching for an append-only table. A
+// cached list ordered by the tail column is kept and only the rows with a
+// tail value larger than the largest cached one are fetched and merged in.
+// Rows must never be updated or deleted and the tail column must only grow.
+type TailCacheConfig struct {
+	// Column that only grows as rows are added, defaults to the primary key
+	Column string `mapstructure:"column" json:"column,omitempty" yaml:"column,omitempty" jsonschema:"title=Tail Column,example=id"`
+}
+
+// ShardConfig declares the databases holding the shards of a table. The hash
+// of the shard key variable picks the database for a request, so the order of
+// the databases must not change once data is written to them.
+type ShardConfig struct {
+	// Databases holding the shards, keys in Config.Databases
+	Databases []string `mapstructure:"databases" json:"databases" yaml:"databases" jsonschema:"title=Shard Databases,example=shard1"`
+	// Key is the name of the variable holding the shard key (e.g., "account_id").
+	Key string `mapstructure:"key" json:"key" yaml:"key" jsonschema:"title=Shard Key Variable,example=account_id"`
+	// ScatterGather runs queries without the shard key on every shard and
+	// concatenates the results. Otherwise such requests fail.
+	ScatterGather bool `mapstructure:"scatter_gather" json:"scatter_gather,omitempty" yaml:"scatter_gather,omitempty" jsonschema:"title=Scatter Gather,default=false"`
 }
 
 // PartitionConfig declares the partition key for a warehouse table.
@@ -395,6 +624,130 @@ type PartitionConfig struct {
 	DefaultRangeDays int `mapstructure:"default_range_days" json:"default_range_days,omitempty" yaml:"default_range_days,omitempty" jsonschema:"title=Default Range Days,example=30"`
 }
 
+// Relationship declares a relationship of a table for databases without
+// foreign keys. For example a many_to_many relationship from products to
+// categories through product_categories:
+//
+//	type: many_to_many
+//	column: id
+//	related_to: categories.id
+//	through: product_categories
+//	through_columns: [product_id, category_id]
+type Relationship struct {
+	// Relationship type: one_to_many, many_to_many or polymorphic
+	Type string `mapstructure:"type" json:"type" yaml:"type" jsonschema:"title=Type,enum=one_to_many,enum=many_to_many,enum=polymorphic,default=one_to_many"`
+	// Column of this table holding the reference, for many_to_many the column
+	// of this table referenced by the join table
+	Column string `mapstructure:"column" json:"column" yaml:"column" jsonschema:"title=Column,example=user_id"`
+	// Related table and column, for polymorphic the type column and the
+	// column referenced on the related tables
+	RelatedTo string `mapstructure:"related_to" json:"related_to" yaml:"related_to" jsonschema:"title=Related To,example=users.id,example=subject_type.id"`
+	// Join table used by many_to_many
+	Through string `mapstructure:"through" json:"through" yaml:"through" jsonschema:"title=Join Table,example=product_categories"`
+	// Join table columns referencing this table and the related table
+	ThroughColumns []string `mapstructure:"through_columns" json:"through_columns" yaml:"through_columns" jsonschema:"title=Join Table Columns"`
+	// Name of the virtual table used to query a polymorphic relationship
+	Name string `mapstructure:"name" json:"name" yaml:"name" jsonschema:"title=Polymorphic Table Name,example=subject"`
+}
+
+// Naming configures how database table and column names map to GraphQL names
+type Naming struct {
+	// Naming strategy: snake_case (names are used as is) or camelCase.
+	// Defaults to camelCase when enable_camelcase is set
+	Strategy string `mapstructure:"strategy" json:"strategy" yaml:"strategy" jsonschema:"title=Strategy,enum=snake_case,enum=camelCase"`
+	// Acronyms kept in upper case with camelCase (eg. ID turns user_id into userID)
+	Acronyms []string `mapstructure:"acronyms" json:"acronyms" yaml:"acronyms" jsonschema:"title=Acronyms,example=ID,example=URL"`
+	// GraphQL names for tables keyed by table name
+	Tables map[string]string `mapstructure:"tables" json:"tables" yaml:"tables" jsonschema:"title=Table Names"`
+	// GraphQL names for columns keyed by table.column or column for all tables
+	Columns map[string]string `mapstructure:"columns" json:"columns" yaml:"columns" jsonschema:"title=Column Names"`
+}
+
+// QueryBudget limits the work done by a request that fans out into sub-queries
+// (per database root queries, database joins and remote joins). Zero values
+// disable a limit
+type QueryBudget struct {
+	// Max sub-queries a request can run
+	MaxSubQueries int `mapstructure:"max_sub_queries" json:"max_sub_queries" yaml:"max_sub_queries" jsonschema:"title=Max Sub-Queries"`
+	// Max rows returned by all the sub-queries of a request
+	MaxRows int `mapstructure:"max_rows" json:"max_rows" yaml:"max_rows" jsonschema:"title=Max Rows"`
+	// Max time from the start of the request after which no sub-queries are run
+	MaxTime time.Duration `mapstructure:"max_time" json:"max_time" yaml:"max_time" jsonschema:"title=Max Time,example=5s"`
+}
+
+// AuditConfig lists the tables whose mutations are recorded. Records are
+// written to the audit table, the sink set with OptionSetAuditSink or both
+type AuditConfig struct {
+	// Tables to audit
+	Tables []string `mapstructure:"tables" json:"tables" yaml:"tables" jsonschema:"title=Audited Tables,example=users"`
+	// Table the records are inserted into, in the database of the mutation
+	Table string `mapstructure:"table" json:"table" yaml:"table" jsonschema:"title=Audit Table,example=audit_log"`
+}
+
+// ApprovalConfig lists the tables whose mutations are saved as pending
+// and only run once approved with ApproveMutation
+type ApprovalConfig struct {
+	// Tables that need approval to change
+	Tables []string `mapstructure:"tables" json:"tables" yaml:"tables" jsonschema:"title=Tables,example=payments"`
+	// Table the pending mutations are saved in, in the default database
+	Table string `mapstructure:"table" json:"table" yaml:"table" jsonschema:"title=Pending Mutations Table,example=pending_mutations"`
+	// Roles allowed to approve, only admin when empty. The role that
+	// staged a mutation cannot approve it
+	Roles []string `mapstructure:"roles" json:"roles" yaml:"roles" jsonschema:"title=Approver Roles"`
+}
+
+// SerializableConfig lists the mutations run in a transaction with a strict
+// isolation level. A mutation that fails with a serialization failure or a
+// deadlock is rolled back and run again up to the max attempts
+type SerializableConfig struct {
+	// Names of the mutations (GraphQL operation names)
+	Mutations []string `mapstructure:"mutations" json:"mutations" yaml:"mutations" jsonschema:"title=Mutations,example=decrementStock"`
+	// Isolation level, serializable (default) or snapshot (MSSQL)
+	Isolation string `mapstructure:"isolation" json:"isolation" yaml:"isolation" jsonschema:"title=Isolation Level,enum=serializable,enum=snapshot,default=serializable"`
+	// Times a mutation is run before the serialization failure is returned
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts" yaml:"max_attempts" jsonschema:"title=Max Attempts,default=3"`
+}
+
+// TimezoneConfig normalizes the timezone of timestamp columns, timestamps
+// stored without a timezone are taken to be in UTC
+type TimezoneConfig struct {
+	// Timezone timestamp columns are returned in, eg. UTC or Europe/Berlin
+	Output string `mapstructure:"output" json:"output" yaml:"output" jsonschema:"title=Output Timezone,example=UTC"`
+	// Variable that sets the output timezone for a request, defaults to the
+	// output timezone or UTC when the request does not set it
+	Variable string `mapstructure:"variable" json:"variable" yaml:"variable" jsonschema:"title=Timezone Variable,example=tz"`
+	// Reject timestamp values in filters and variables without a timezone offset
+	RequireOffset bool `mapstructure:"require_offset" json:"require_offset" yaml:"require_offset" jsonschema:"title=Require Timezone Offset,default=false"`
+}
+
+// OperationTimeouts sets the default timeout for each operation type. Zero
+// values disable a timeout
+type OperationTimeouts struct {
+	// Timeout for queries
+	Query time.Duration `mapstructure:"query" json:"query" yaml:"query" jsonschema:"title=Query Timeout,example=10s"`
+	// Timeout for mutations
+	Mutation time.Duration `mapstructure:"mutation" json:"mutation" yaml:"mutation" jsonschema:"title=Mutation Timeout,example=30s"`
+	// Timeout for each subscription query run to check for updates
+	Subscription time.Duration `mapstructure:"subscription" json:"subscription" yaml:"subscription" jsonschema:"title=Subscription Timeout,example=5s"`
+}
+
+// NamespaceConfig is merged over the base config for requests made in the
+// namespace. Zero values inherit the base config
+type NamespaceConfig struct {
+	// Tables and columns (regular expressions) that can't be used in the
+	// namespace, added to the base block list
+	Blocklist []string `mapstructure:"blocklist" json:"blocklist" yaml:"blocklist" jsonschema:"title=Block List"`
+
+	// Max size in bytes of a query response
+	MaxResponseBytes int `mapstructure:"max_response_bytes" json:"max_response_bytes" yaml:"max_response_bytes" jsonschema:"title=Max Response Bytes"`
+
+	// Trim list fields in responses over max_response_bytes instead of failing
+	TruncateResponses *bool `mapstructure:"truncate_responses" json:"truncate_responses" yaml:"truncate_responses" jsonschema:"title=Truncate Responses"`
+
+	// Limits the sub-queries, rows and time a single request can use
+	Budget QueryBudget `mapstructure:"budget" json:"budget" yaml:"budget" jsonschema:"title=Query Budget"`
+}
+
 // Configuration for a database table column
 type Column struct {
 	Name       string
@@ -403,6 +756,12 @@ type Column struct {
 	Array      bool
 	FullText   bool   `mapstructure:"full_text" json:"full_text" yaml:"full_text" jsonschema:"title=Full Text Search"`
 	ForeignKey string `mapstructure:"related_to" json:"related_to" yaml:"related_to" jsonschema:"title=Related To,example=other_table.id_column,example=users.id"`
+	// GraphQL name exposed for the column
+	Alias string `mapstructure:"alias" json:"alias" yaml:"alias" jsonschema:"title=Alias,example=email_address"`
+	// Reason shown in introspection to mark the column as deprecated
+	Deprecated string `mapstructure:"deprecated" json:"deprecated" yaml:"deprecated" jsonschema:"title=Deprecation Reason"`
+	// Return the column value as a string, eg. for 64-bit integer ids
+	AsString bool `mapstructure:"as_string" json:"as_string" yaml:"as_string" jsonschema:"title=As String,default=false"`
 }
 
 // Configuration for a database function
@@ -410,15 +769,30 @@ type Function struct {
 	Name       string
 	Schema     string
 	ReturnType string `mapstructure:"return_type" json:"return_type" yaml:"return_type" jsonschema:"title=Return Type,example=boolean,example=record"`
+	// Mutation exposes the function as a mutation root,
+	// eg. mutation { approve_order(args: { id: 5 }) }
+	Mutation bool `mapstructure:"mutation" json:"mutation" yaml:"mutation" jsonschema:"title=Callable as Mutation,default=false"`
+	// Result is the shape of the mutation result: scalar, row or table.
+	// Defaults to table for functions returning records and scalar for others
+	Result string `mapstructure:"result" json:"result" yaml:"result" jsonschema:"title=Mutation Result,enum=scalar,enum=row,enum=table"`
+	// Roles allowed to call the function as a mutation, all roles but anon when empty
+	Roles []string `mapstructure:"roles" json:"roles" yaml:"roles" jsonschema:"title=Roles"`
 }
 
 // Configuration for user role
 type Role struct {
 	Name    string
 	Comment string
-	Match   string      `jsonschema:"title=Related To,example=other_table.id_column,example=users.id"`
-	Tables  []RoleTable `jsonschema:"title=Table Configuration for Role"`
-	tm      map[string]*RoleTable
+	Match   string `jsonschema:"title=Related To,example=other_table.id_column,example=users.id"`
+	// Roles to inherit the table configs and blocked operators from, later
+	// roles override earlier ones and this role overrides them all
+	Extends []string `mapstructure:"extends" json:"extends" yaml:"extends" jsonschema:"title=Extends Roles"`
+	// Where clause operators blocked on every table for this role, eg. regex or has_in_common
+	BlockOperators []string `mapstructure:"block_operators" json:"block_operators" yaml:"block_operators" jsonschema:"title=Blocked Operators"`
+	// Allow this role to pass database query hints with the @hint directive
+	AllowHints bool        `mapstructure:"allow_hints" json:"allow_hints" yaml:"allow_hints" jsonschema:"title=Allow Query Hints"`
+	Tables     []RoleTable `jsonschema:"title=Table Configuration for Role"`
+	tm         map[string]*RoleTable
 }
 
 // Table configuration for a specific role (user role)
@@ -437,11 +811,17 @@ type RoleTable struct {
 // Table configuration for querying a table with a role
 type Query struct {
 	Limit int
+	// Max limit for the table with this role, larger limits are clamped
+	MaxLimit int `mapstructure:"max_limit" json:"max_limit" yaml:"max_limit"`
 	// Use filters to enforce table wide things like { disabled: false } where you never want disabled users to be shown.
 	Filters          []string
 	Columns          []string
 	DisableFunctions bool `mapstructure:"disable_functions" json:"disable_functions" yaml:"disable_functions"`
-	Block            bool
+	// Allow queries to lock the selected rows with the lock argument (FOR UPDATE / FOR SHARE)
+	AllowLock bool `mapstructure:"allow_lock" json:"allow_lock" yaml:"allow_lock"`
+	// Where clause operators blocked on this table for the role, eg. ilike
+	BlockOperators []string `mapstructure:"block_operators" json:"block_operators" yaml:"block_operators"`
+	Block          bool
 }
 
 // Table configuration for inserting into a table with a role
@@ -536,6 +916,14 @@ type Resolver interface {
 	Resolve(context.Context, ResolverReq) ([]byte, error)
 }
 
+// BatchResolver is a resolver that can fetch the data for many ids with a
+// single request. The ids of a remote selection are passed to ResolveBatch
+// together and a value must be returned for each of them in the same order.
+type BatchResolver interface {
+	Resolver
+	ResolveBatch(context.Context, []ResolverReq) ([][]byte, error)
+}
+
 // ResolverProps is a map of properties from the resolver config to be passed
 // to the customer resolver's builder (new) function
 type ResolverProps map[string]interface{}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+const (
+	// ErrCodeUniqueViolation is the error code of a unique constraint violation
+	ErrCodeUniqueViolation = "UNIQUE_VIOLATION"
+	// ErrCodeCheckViolation is the error code of a CHECK constraint violation
+	ErrCodeCheckViolation = "CHECK_VIOLATION"
+)
+
+// ConstraintError is returned when a mutation violates a CHECK or unique
+// constraint. The message is the one set in the table config or a default
+type ConstraintError struct {
+	// Code is ErrCodeUniqueViolation or ErrCodeCheckViolation
+	Code       string
+	Constraint string
+	Table      string
+	Columns    []string
+	Message    string
+	// Err is the database driver error
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Message
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// extensions returns the GraphQL error extensions of the violation
+func (e *ConstraintError) extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code}
+	if e.Constraint != "" {
+		ext["constraint"] = e.Constraint
+	}
+	if e.Table != "" {
+		ext["table"] = e.Table
+	}
+	if len(e.Columns) != 0 {
+		ext["columns"] = e.Columns
+	}
+	return ext
+}
+
+// constraintMatcher matches the error message of a constraint violation.
+// The named groups of the pattern are constraint, table and columns
+type constraintMatcher struct {
+	code string
+	re   *regexp.Regexp
+}
+
+// constraintMatchers are the constraint violation messages of each dialect,
+// the drivers are matched by their messages so no driver is imported
+var constraintMatchers = map[string][]constraintMatcher{
+	"postgres": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`violates unique constraint "(?P<constraint>[^"]+)"`)},
+		{ErrCodeCheckViolation, regexp.MustCompile(`new row for relation "(?P<table>[^"]+)" violates check constraint "(?P<constraint>[^"]+)"`)},
+		{ErrCodeCheckViolation, regexp.MustCompile(`violates check constraint "(?P<constraint>[^"]+)"`)},
+	},
+	"mysql": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`Duplicate entry '.*' for key '(?:(?P<table>[^'.]+)\.)?(?P<constraint>[^'.]+)'`)},
+		{ErrCodeCheckViolation, regexp.MustCompile("Check constraint '(?P<constraint>[^']+)' is violated")},
+		{ErrCodeCheckViolation, regexp.MustCompile("CONSTRAINT `(?P<constraint>[^`]+)` failed for (?:`[^`]+`\\.)?`(?P<table>[^`]+)`")},
+	},
+	"sqlite": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`UNIQUE constraint failed: (?P<columns>.+)$`)},
+		{ErrCodeCheckViolation, regexp.MustCompile(`CHECK constraint failed: (?P<constraint>.+)$`)},
+	},
+	"mssql": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`Violation of (?:UNIQUE KEY|PRIMARY KEY) constraint '(?P<constraint>[^']+)'\. Cannot insert duplicate key in object '(?:[^'.]+\.)?(?P<table>[^']+)'`)},
+		{ErrCodeUniqueViolation, regexp.MustCompile(`Cannot insert duplicate key row in object '(?:[^'.]+\.)?(?P<table>[^']+)' with unique index '(?P<constraint>[^']+)'`)},
+		{ErrCodeCheckViolation, regexp.MustCompile(`conflicted with the CHECK constraint "(?P<constraint>[^"]+)"\. The conflict occurred in database "[^"]+", table "(?:[^".]+\.)?(?P<table>[^"]+)"(?:, column '(?P<columns>[^']+)')?`)},
+	},
+	"oracle": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`ORA-00001: unique constraint \((?:[^.)]+\.)?(?P<constraint>[^)]+)\) violated`)},
+		{ErrCodeCheckViolation, regexp.MustCompile(`ORA-02290: check constraint \((?:[^.)]+\.)?(?P<constraint>[^)]+)\) violated`)},
+	},
+	"mongodb": {
+		{ErrCodeUniqueViolation, regexp.MustCompile(`E11000 duplicate key error collection: [^.]+\.(?P<table>\S+) index: (?P<constraint>\S+)`)},
+	},
+}
+
+// uniqueKeyRe matches the detail of a postgres unique violation
+var uniqueKeyRe = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// constraintError turns a constraint violation of a mutation into a
+// ConstraintError with the configured message, other errors are returned
+// as they are. The columns are added to the validation errors
+func (s *gstate) constraintError(err error) error {
+	var ce *ConstraintError
+	if err == nil || s.r.operation != qcode.QTMutation || errors.As(err, &ce) {
+		return err
+	}
+
+	dbType := s.gj.conf.DBType
+	if ctx := s.getTargetDBCtx(); ctx != nil {
+		dbType = ctx.dbtype
+	}
+	if ce = parseConstraintError(dbType, err); ce == nil {
+		return err
+	}
+	s.gj.constraintMessage(ce)
+
+	name := ce.Constraint
+	if name == "" {
+		name = strings.ToLower(ce.Code)
+	}
+	for _, col := range ce.Columns {
+		s.verrs = append(s.verrs, qcode.ValidErr{FieldName: col, Constraint: name})
+	}
+	return ce
+}
+
+// parseConstraintError returns the constraint violation in the error of a
+// database driver or nil
+func parseConstraintError(dbType string, err error) *ConstraintError {
+	switch dbType {
+	case "", "postgres":
+		dbType = "postgres"
+	case "mariadb":
+		dbType = "mysql"
+	}
+
+	msg := err.Error()
+	for _, m := range constraintMatchers[dbType] {
+		match := m.re.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		ce := &ConstraintError{Code: m.code, Err: err}
+
+		for i, name := range m.re.SubexpNames() {
+			switch name {
+			case "constraint":
+				ce.Constraint = match[i]
+			case "table":
+				ce.Table = match[i]
+			case "columns":
+				ce.Columns = constraintColumns(match[i], &ce.Table)
+			}
+		}
+
+		// postgres drivers keep the columns of a unique violation in the
+		// error detail and out of the message
+		if dbType == "postgres" && len(ce.Columns) == 0 {
+			if k := uniqueKeyRe.FindStringSubmatch(errField(err, "Detail")); k != nil {
+				ce.Columns = constraintColumns(k[1], nil)
+			}
+		}
+		if ce.Table == "" {
+			ce.Table = errField(err, "TableName", "Table")
+		}
+
+		if dbType == "oracle" || dbType == "mssql" {
+			ce.Table = strings.ToLower(ce.Table)
+			for i := range ce.Columns {
+				ce.Columns[i] = strings.ToLower(ce.Columns[i])
+			}
+		}
+		return ce
+	}
+	return nil
+}
+
+// constraintColumns splits a list of columns, columns qualified with the
+// table name (eg. users.email) set the table
+func constraintColumns(list string, table *string) []string {
+	var cols []string
+	for _, c := range strings.Split(list, ",") {
+		c = strings.Trim(strings.TrimSpace(c), `"`)
+		if i := strings.LastIndexByte(c, '.'); i != -1 {
+			if table != nil {
+				*table = c[:i]
+			}
+			c = c[i+1:]
+		}
+		if c != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// errField returns the first of the string fields found on the driver error,
+// eg. the detail and table of the postgres drivers
+func errField(err error, names ...string) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for _, n := range names {
+			if f := v.FieldByName(n); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+	}
+	return ""
+}
+
+// constraintMessage sets the message of the violation from the table config
+// or a default message
+func (gj *graphjinEngine) constraintMessage(ce *ConstraintError) {
+	if table, c := gj.constraintConfig(ce); c != nil {
+		if ce.Table == "" {
+			ce.Table = table
+		}
+		if ce.Constraint == "" {
+			ce.Constraint = c.Name
+		}
+		if len(ce.Columns) == 0 {
+			ce.Columns = c.Columns
+		}
+		ce.Message = c.Message
+	}
+
+	if ce.Message != "" {
+		return
+	}
+	switch {
+	case ce.Code == ErrCodeUniqueViolation && len(ce.Columns) != 0:
+		ce.Message = fmt.Sprintf("a row with this %s already exists",
+			strings.Join(ce.Columns, ", "))
+	case ce.Code == ErrCodeUniqueViolation:
+		ce.Message = fmt.Sprintf("unique constraint '%s' violated", ce.Constraint)
+	default:
+		ce.Message = fmt.Sprintf("check constraint '%s' violated", ce.Constraint)
+	}
+}
+
+// constraintConfig returns the table and config of the violated constraint,
+// it is matched by name or when unnamed by its columns
+func (gj *graphjinEngine) constraintConfig(ce *ConstraintError) (string, *ConstraintConfig) {
+	for _, t := range gj.tables() {
+		if ce.Table != "" && !strings.EqualFold(t.Name, ce.Table) {
+			continue
+		}
+		for i, c := range t.Constraints {
+			if constraintMatches(c, ce) {
+				return t.Name, &t.Constraints[i]
+			}
+		}
+	}
+	return "", nil
+}
+
+// constraintMatches returns true when the config is for the violated constraint
+func constraintMatches(c ConstraintConfig, ce *ConstraintError) bool {
+	if ce.Constraint != "" {
+		return strings.EqualFold(c.Name, ce.Constraint)
+	}
+	if len(c.Columns) == 0 || len(c.Columns) != len(ce.Columns) {
+		return false
+	}
+	for i := range c.Columns {
+		if !strings.EqualFold(c.Columns[i], ce.Columns[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestParseConstraintError(t *testing.T) {
+	tests := []struct {
+		dbType string
+		msg    string
+		want   ConstraintError
+	}{
+		{"postgres", `ERROR: duplicate key value violates unique constraint "users_email_key" (SQLSTATE 23505)`,
+			ConstraintError{Code: ErrCodeUniqueViolation, Constraint: "users_email_key"}},
+		{"postgres", `ERROR: new row for relation "users" violates check constraint "users_age_check" (SQLSTATE 23514)`,
+			ConstraintError{Code: ErrCodeCheckViolation, Constraint: "users_age_check", Table: "users"}},
+		{"mysql", `Error 1062 (23000): Duplicate entry 'a@b.com' for key 'users.email'`,
+			ConstraintError{Code: ErrCodeUniqueViolation, Constraint: "email", Table: "users"}},
+		{"mysql", `Error 3819 (HY000): Check constraint 'users_chk_1' is violated.`,
+			ConstraintError{Code: ErrCodeCheckViolation, Constraint: "users_chk_1"}},
+		{"mariadb", "Error 4025 (23000): CONSTRAINT `age_check` failed for `app`.`users`",
+			ConstraintError{Code: ErrCodeCheckViolation, Constraint: "age_check", Table: "users"}},
+		{"sqlite", `UNIQUE constraint failed: users.first, users.last`,
+			ConstraintError{Code: ErrCodeUniqueViolation, Table: "users", Columns: []string{"first", "last"}}},
+		{"mssql", `mssql: Violation of UNIQUE KEY constraint 'UQ_users_email'. Cannot insert duplicate key in object 'dbo.users'. The duplicate key value is (a@b.com).`,
+			ConstraintError{Code: ErrCodeUniqueViolation, Constraint: "UQ_users_email", Table: "users"}},
+		{"mssql", `mssql: The INSERT statement conflicted with the CHECK constraint "CK_users_age". The conflict occurred in database "app", table "dbo.Users", column 'Age'.`,
+			ConstraintError{Code: ErrCodeCheckViolation, Constraint: "CK_users_age", Table: "users", Columns: []string{"age"}}},
+		{"oracle", `ORA-00001: unique constraint (APP.USERS_EMAIL_UK) violated`,
+			ConstraintError{Code: ErrCodeUniqueViolation, Constraint: "USERS_EMAIL_UK"}},
+		{"oracle", `ORA-02290: check constraint (APP.USERS_AGE_CK) violated`,
+			ConstraintError{Code: ErrCodeCheckViolation, Constraint: "USERS_AGE_CK"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			ce := parseConstraintError(tt.dbType, errors.New(tt.msg))
+			if ce == nil {
+				t.Fatalf("expected a constraint error for: %s", tt.msg)
+			}
+			ce.Err = nil
+			if !reflect.DeepEqual(*ce, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, *ce)
+			}
+		})
+	}
+
+	if ce := parseConstraintError("postgres", errors.New("connection refused")); ce != nil {
+		t.Fatalf("unexpected constraint error: %+v", ce)
+	}
+}
+
+// pgError has the fields of the postgres driver errors read for the detail
+type pgError struct {
+	Message   string
+	Detail    string
+	TableName string
+}
+
+func (e *pgError) Error() string { return e.Message }
+
+func TestParseConstraintErrorDetail(t *testing.T) {
+	err := &pgError{
+		Message:   `ERROR: duplicate key value violates unique constraint "users_email_key" (SQLSTATE 23505)`,
+		Detail:    "Key (email)=(a@b.com) already exists.",
+		TableName: "users",
+	}
+	ce := parseConstraintError("postgres", err)
+	if ce == nil || ce.Table != "users" || !reflect.DeepEqual(ce.Columns, []string{"email"}) {
+		t.Fatalf("expected the table and columns from the error fields: %+v", ce)
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE members (
+			id INTEGER PRIMARY KEY,
+			email TEXT UNIQUE,
+			age INTEGER CONSTRAINT members_age_check CHECK (age >= 18)
+		);
+		INSERT INTO members (id, email, age) VALUES (1, 'a@b.com', 30);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Tables: []Table{{
+			Name: "members",
+			Constraints: []ConstraintConfig{
+				{Name: "members_email_key", Columns: []string{"email"}, Message: "This email is already registered"},
+			},
+		}},
+	}
+	gj, err := NewGraphJin(conf, db, OptionSetFS(NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `mutation { members(insert: $data) { id } }`
+
+	res, err := gj.GraphQL(context.Background(), gql,
+		[]byte(`{ "data": { "email": "a@b.com", "age": 20 } }`), nil)
+
+	var ce *ConstraintError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected a constraint error, got %v", err)
+	}
+	if ce.Message != "This email is already registered" || ce.Constraint != "members_email_key" {
+		t.Fatalf("expected the configured message: %+v", ce)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != ErrCodeUniqueViolation {
+		t.Fatalf("expected the error code in the extensions: %+v", res.Errors)
+	}
+	if len(res.Validation) != 1 || res.Validation[0].FieldName != "email" {
+		t.Fatalf("expected a validation error for the column: %+v", res.Validation)
+	}
+
+	res, err = gj.GraphQL(context.Background(), gql,
+		[]byte(`{ "data": { "email": "c@d.com", "age": 12 } }`), nil)
+	if !errors.As(err, &ce) || ce.Code != ErrCodeCheckViolation {
+		t.Fatalf("expected a check violation, got %v", err)
+	}
+	if ce.Message != "check constraint 'members_age_check' violated" || ce.Table != "" {
+		t.Fatalf("expected the default message: %+v", ce)
+	}
+	if res.Errors[0].Extensions["constraint"] != "members_age_check" {
+		t.Fatalf("expected the constraint in the extensions: %+v", res.Errors)
+	}
+}
@@ -74,7 +74,7 @@ func (gj *graphjinEngine) executeRoleQuery(c context.Context,
 		return
 	}
 
-	needsConn := ((rc != nil && rc.Tx == nil) && conn == nil)
+	needsConn := ((rc == nil || rc.Tx == nil) && conn == nil)
 	if needsConn {
 		c1, span := gj.spanStart(c, "Get Connection")
 		defer span.End()
@@ -151,6 +151,12 @@ func (r *Result) Role() string {
 	return r.role
 }
 
+// Returns the database the query ran on, a comma separated list when it
+// ran on several
+func (r *Result) Database() string {
+	return r.database
+}
+
 // Returns the SQL query string for the query result
 func (r *Result) SQL() string {
 	return r.sql
@@ -166,6 +172,11 @@ func (r *Result) CacheHit() bool {
 	return r.cacheHit
 }
 
+// Returns the deprecation of the saved query or nil
+func (r *Result) Deprecation() *QueryDeprecation {
+	return r.deprecation
+}
+
 // debugLogStmt logs the query statement for debugging
 func (s *gstate) debugLogStmt() {
 	st := s.cs.st
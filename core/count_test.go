@@ -0,0 +1,94 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCountRoot(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, owner_id INTEGER, price REAL);
+		INSERT INTO products (id, owner_id, price) VALUES
+			(1, 1, 10), (2, 1, 20), (3, 2, 30), (4, 2, 40), (5, 2, 50);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Roles: []core.Role{
+			{Name: "user", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{
+					Filters: []string{"{ owner_id: { eq: $user_id } }"},
+				}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anon := context.Background()
+	user := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	tests := []struct {
+		name  string
+		c     context.Context
+		query string
+		exp   string
+	}{
+		{"all rows", anon, `query { products_count }`,
+			`{"products_count": 5}`},
+		{"where", anon, `query { products_count(where: { price: { gt: 25 } }) }`,
+			`{"products_count": 3}`},
+		{"alias with rows", anon, `query { total: products_count products(limit: 1) { id } }`,
+			`{"total": 5, "products": [{"id": 1}]}`},
+		{"role filter", user, `query { products_count }`,
+			`{"products_count": 2}`},
+	}
+
+	for _, tt := range tests {
+		res, err := gj.GraphQL(tt.c, tt.query, nil, nil)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		var got, exp map[string]any
+		if err := json.Unmarshal(res.Data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.exp), &exp); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.exp, res.Data)
+		}
+	}
+
+	for _, q := range []string{
+		`query { products_count { id } }`,
+		`query { products_count(limit: 1) }`,
+	} {
+		if _, err := gj.GraphQL(anon, q, nil, nil); err == nil {
+			t.Errorf("%s: expected an error", q)
+		}
+	}
+}
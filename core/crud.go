@@ -0,0 +1,262 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+// CRUDBasePath is the route prefix the service uses for CRUD routes
+const CRUDBasePath = "/api/v1/crud"
+
+// CRUDOp is a conventional REST operation on a single table
+type CRUDOp int
+
+const (
+	// CRUDList fetches a page of rows (GET /<table>)
+	CRUDList CRUDOp = iota + 1
+	// CRUDRead fetches a single row by primary key (GET /<table>/{id})
+	CRUDRead
+	// CRUDCreate inserts a row (POST /<table>)
+	CRUDCreate
+	// CRUDUpdate updates a row by primary key (PATCH /<table>/{id})
+	CRUDUpdate
+	// CRUDDelete deletes a row by primary key (DELETE /<table>/{id})
+	CRUDDelete
+)
+
+// String returns the operation name used in generated query names
+func (op CRUDOp) String() string {
+	switch op {
+	case CRUDList:
+		return "list"
+	case CRUDRead:
+		return "read"
+	case CRUDCreate:
+		return "create"
+	case CRUDUpdate:
+		return "update"
+	case CRUDDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// CRUDRequest describes a single CRUD operation against a table
+type CRUDRequest struct {
+	Op    CRUDOp
+	Table string
+
+	// Primary key value, required for read, update and delete
+	ID string
+
+	// Paging for list operations. Zero values fall back to the role
+	// and default limits
+	Limit  int
+	Offset int
+
+	// Row values as a JSON object, required for create and update
+	Data json.RawMessage
+}
+
+// CRUD executes a conventional CRUD operation on a table. The GraphQL query
+// is generated from the table schema and compiled through the same role system
+// as any other request, it is never added to the allow list. In production the
+// role must have the table configured for the operation. Requires
+// `enable_crud` to be set in the config.
+func (g *GraphJin) CRUD(c context.Context,
+	req CRUDRequest,
+	rc *RequestConfig,
+) (res *Result, err error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return
+	}
+
+	if !gj.conf.EnableCRUD {
+		err = errors.New("crud: not enabled")
+		return
+	}
+
+	c1, span := gj.spanStart(c, "GraphJin CRUD")
+	defer span.End()
+
+	_, dbCtx, err := gj.resolveUniqueTableDatabase(req.Table)
+	if err != nil {
+		return
+	}
+
+	t, err := dbCtx.schema.Find("", req.Table)
+	if err != nil {
+		return
+	}
+
+	role, err := gj.userRole(c1, rc)
+	if err != nil {
+		return
+	}
+
+	var rt *RoleTable
+	if r, ok := gj.roles[role]; ok {
+		rt = r.GetTable(t.Schema, t.Name)
+	}
+
+	// CRUD queries skip the allow list so in production only the tables
+	// and operations configured for the role are served
+	if gj.prodSec && !crudAllowed(rt, req.Op) {
+		err = fmt.Errorf("crud: %s on %s not allowed for role %s", req.Op, t.Name, role)
+		return
+	}
+
+	var cols []string
+	if rt != nil && rt.Query != nil {
+		cols = rt.Query.Columns
+	}
+
+	name, query, vars, err := buildCRUDQuery(t, cols, req)
+	if err != nil {
+		return
+	}
+
+	h, err := graph.FastParse(query)
+	if err != nil {
+		return
+	}
+
+	r := gj.newGraphqlReq(rc, h.Operation, name, []byte(query), vars)
+	r.dynamic = true
+
+	res, err = gj.queryWithResult(c1, r)
+	return
+}
+
+// crudAllowed returns true when the role table is configured for the
+// operation and does not block it
+func crudAllowed(rt *RoleTable, op CRUDOp) bool {
+	if rt == nil {
+		return false
+	}
+	switch op {
+	case CRUDList, CRUDRead:
+		return rt.Query != nil && !rt.Query.Block
+	case CRUDCreate:
+		return !rt.ReadOnly && rt.Insert != nil && !rt.Insert.Block
+	case CRUDUpdate:
+		return !rt.ReadOnly && rt.Update != nil && !rt.Update.Block
+	case CRUDDelete:
+		return !rt.ReadOnly && rt.Delete != nil && !rt.Delete.Block
+	}
+	return false
+}
+
+// buildCRUDQuery generates the GraphQL query, its name and variables for a
+// CRUD request. When allowed is not empty only those columns are selected.
+func buildCRUDQuery(t sdata.DBTable, allowed []string, req CRUDRequest) (
+	name, query string, vars json.RawMessage, err error,
+) {
+	if t.Blocked {
+		err = fmt.Errorf("crud: table blocked: %s", t.Name)
+		return
+	}
+
+	needsID := req.Op == CRUDRead || req.Op == CRUDUpdate || req.Op == CRUDDelete
+	if needsID {
+		if t.PrimaryCol.Name == "" {
+			err = fmt.Errorf("crud: table has no primary key: %s", t.Name)
+			return
+		}
+		if req.ID == "" {
+			err = fmt.Errorf("crud: %s requires an id", req.Op)
+			return
+		}
+	}
+
+	needsData := req.Op == CRUDCreate || req.Op == CRUDUpdate
+	if needsData && len(req.Data) == 0 {
+		err = fmt.Errorf("crud: %s requires a request body", req.Op)
+		return
+	}
+
+	sel := crudSelection(t, allowed)
+	if sel == "" {
+		err = fmt.Errorf("crud: no columns available: %s", t.Name)
+		return
+	}
+
+	name = "crud_" + t.Name + "_" + req.Op.String()
+	v := make(map[string]json.RawMessage)
+
+	var opType, args string
+	switch req.Op {
+	case CRUDList:
+		opType = "query"
+		var pa []string
+		if req.Limit > 0 {
+			pa = append(pa, "limit: "+strconv.Itoa(req.Limit))
+		}
+		if req.Offset > 0 {
+			pa = append(pa, "offset: "+strconv.Itoa(req.Offset))
+		}
+		if len(pa) != 0 {
+			args = "(" + strings.Join(pa, ", ") + ")"
+		}
+	case CRUDRead:
+		opType, args = "query", "(id: $id)"
+	case CRUDCreate:
+		opType, args = "mutation", "(insert: $data)"
+	case CRUDUpdate:
+		opType, args = "mutation", "(id: $id, update: $data)"
+	case CRUDDelete:
+		opType, args = "mutation", "(id: $id, delete: true)"
+	default:
+		err = fmt.Errorf("crud: unknown operation: %d", req.Op)
+		return
+	}
+
+	query = opType + " " + name + " { " + t.Name + args + " { " + sel + " } }"
+
+	if needsID {
+		if v["id"], err = json.Marshal(req.ID); err != nil {
+			return
+		}
+	}
+	if needsData {
+		v["data"] = req.Data
+	}
+	if len(v) != 0 {
+		vars, err = json.Marshal(v)
+	}
+	return
+}
+
+// crudSelection returns the space separated list of columns to select
+func crudSelection(t sdata.DBTable, allowed []string) string {
+	var am map[string]struct{}
+	if len(allowed) != 0 {
+		am = make(map[string]struct{}, len(allowed))
+		for _, c := range allowed {
+			am[strings.ToLower(c)] = struct{}{}
+		}
+	}
+
+	cols := make([]string, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		if col.Blocked {
+			continue
+		}
+		if am != nil {
+			if _, ok := am[strings.ToLower(col.Name)]; !ok {
+				continue
+			}
+		}
+		cols = append(cols, col.Name)
+	}
+	return strings.Join(cols, " ")
+}
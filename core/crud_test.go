@@ -0,0 +1,161 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestBuildCRUDQuery(t *testing.T) {
+	schema, err := sdata.GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := schema.Find("", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		req     CRUDRequest
+		allowed []string
+		query   string
+		vars    string
+	}{
+		{
+			name:    "list",
+			req:     CRUDRequest{Op: CRUDList, Table: "users", Limit: 5, Offset: 10},
+			allowed: []string{"id", "email"},
+			query:   "query crud_users_list { users(limit: 5, offset: 10) { id email } }",
+		},
+		{
+			name:    "read",
+			req:     CRUDRequest{Op: CRUDRead, Table: "users", ID: "3"},
+			allowed: []string{"id"},
+			query:   "query crud_users_read { users(id: $id) { id } }",
+			vars:    `{"id":"3"}`,
+		},
+		{
+			name:    "create",
+			req:     CRUDRequest{Op: CRUDCreate, Table: "users", Data: json.RawMessage(`{"email":"a@b.c"}`)},
+			allowed: []string{"id"},
+			query:   "mutation crud_users_create { users(insert: $data) { id } }",
+			vars:    `{"data":{"email":"a@b.c"}}`,
+		},
+		{
+			name:    "update",
+			req:     CRUDRequest{Op: CRUDUpdate, Table: "users", ID: "3", Data: json.RawMessage(`{"phone":"1"}`)},
+			allowed: []string{"id"},
+			query:   "mutation crud_users_update { users(id: $id, update: $data) { id } }",
+			vars:    `{"data":{"phone":"1"},"id":"3"}`,
+		},
+		{
+			name:    "delete",
+			req:     CRUDRequest{Op: CRUDDelete, Table: "users", ID: "3"},
+			allowed: []string{"id"},
+			query:   "mutation crud_users_delete { users(id: $id, delete: true) { id } }",
+			vars:    `{"id":"3"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, query, vars, err := buildCRUDQuery(users, tt.allowed, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != "crud_users_"+tt.name {
+				t.Errorf("name = %q", name)
+			}
+			if query != tt.query {
+				t.Errorf("query = %q, want %q", query, tt.query)
+			}
+			if string(vars) != tt.vars {
+				t.Errorf("vars = %s, want %s", vars, tt.vars)
+			}
+			if _, err := graph.Parse([]byte(query)); err != nil {
+				t.Errorf("generated query does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildCRUDQueryErrors(t *testing.T) {
+	schema, err := sdata.GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := schema.Find("", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := buildCRUDQuery(users, nil, CRUDRequest{Op: CRUDRead}); err == nil {
+		t.Error("expected error for read without id")
+	}
+	if _, _, _, err := buildCRUDQuery(users, nil, CRUDRequest{Op: CRUDCreate}); err == nil {
+		t.Error("expected error for create without body")
+	}
+	if _, _, _, err := buildCRUDQuery(users, []string{"nope"}, CRUDRequest{Op: CRUDList}); err == nil {
+		t.Error("expected error when no columns are allowed")
+	}
+}
+
+func TestCRUDAllowed(t *testing.T) {
+	rt := &RoleTable{
+		Query:  &Query{},
+		Insert: &Insert{},
+		Update: &Update{Block: true},
+	}
+
+	tests := []struct {
+		rt  *RoleTable
+		op  CRUDOp
+		exp bool
+	}{
+		{nil, CRUDList, false},
+		{rt, CRUDList, true},
+		{rt, CRUDRead, true},
+		{rt, CRUDCreate, true},
+		{rt, CRUDUpdate, false},
+		{rt, CRUDDelete, false},
+		{&RoleTable{ReadOnly: true, Insert: &Insert{}}, CRUDCreate, false},
+		{&RoleTable{Query: &Query{Block: true}}, CRUDRead, false},
+	}
+	for _, tt := range tests {
+		if got := crudAllowed(tt.rt, tt.op); got != tt.exp {
+			t.Errorf("%s: expected %v, got %v", tt.op, tt.exp, got)
+		}
+	}
+}
+
+func TestOpenAPIIncludesCRUDPaths(t *testing.T) {
+	g, err := NewTestGraphJin(map[string]string{"default": "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gj, _ := g.getEngine()
+	gj.conf = &Config{EnableCRUD: true}
+
+	spec := &OpenAPIDocument{Paths: make(map[string]PathItem)}
+	g.generateCRUDPaths(spec, gj)
+
+	p, ok := spec.Paths["/users/{id}"]
+	if !ok {
+		t.Fatal("missing /users/{id} path")
+	}
+	if p.Get == nil || p.Patch == nil || p.Delete == nil {
+		t.Error("expected get, patch and delete operations on /users/{id}")
+	}
+	if len(p.Servers) != 1 || p.Servers[0].URL != CRUDBasePath {
+		t.Errorf("unexpected servers: %+v", p.Servers)
+	}
+
+	p, ok = spec.Paths["/users"]
+	if !ok || p.Get == nil || p.Post == nil {
+		t.Error("expected get and post operations on /users")
+	}
+}
@@ -4,14 +4,81 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/sha256"
 	"encoding/base64"
 )
 
+// keyIDLen is the length of the key id written ahead of the nonce in
+// every encrypted value
+const keyIDLen = 4
+
+// keyID returns the id of the key embedded in encrypted values so the
+// matching key can be picked when decrypting after a key rotation
+func keyID(key [32]byte) []byte {
+	h := sha256.Sum256(key[:])
+	return h[:keyIDLen]
+}
+
+// cryptKey is a decryption key along with its key id
+type cryptKey struct {
+	id  []byte
+	gcm cipher.AEAD
+}
+
+// newCryptKeys returns the decryption keys for the given secrets
+func newCryptKeys(keys [][32]byte) ([]cryptKey, error) {
+	ck := make([]cryptKey, 0, len(keys))
+	for _, k := range keys {
+		block, err := aes.NewCipher(k[:])
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ck = append(ck, cryptKey{id: keyID(k), gcm: gcm})
+	}
+	return ck, nil
+}
+
+// openValue decrypts a decoded value. Values carrying the id of a known key
+// are opened with that key, older values without a key id are tried
+// against every key
+func openValue(keys []cryptKey, v, dst []byte) ([]byte, bool) {
+	if len(v) > keyIDLen {
+		for _, k := range keys {
+			if !bytes.Equal(v[:keyIDLen], k.id) {
+				continue
+			}
+			if out, ok := openWithKey(k, v[keyIDLen:], dst); ok {
+				return out, true
+			}
+		}
+	}
+	for _, k := range keys {
+		if out, ok := openWithKey(k, v, dst); ok {
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+func openWithKey(k cryptKey, v, dst []byte) ([]byte, bool) {
+	ns := k.gcm.NonceSize()
+	if len(v) < ns {
+		return nil, false
+	}
+	out, err := k.gcm.Open(dst[:0], v[:ns], v[ns:], nil)
+	return out, err == nil
+}
+
 // encryptValues encrypts the values in the data using the given key
 // data: the data to encrypt
 // encPrefix: the prefix to search for the values to encrypt
 // decPrefix: the prefix to replace the values with
 // nonce: the nonce to use for encryption
+// key: the key to use for encryption, its key id is written ahead of the nonce
 func encryptValues(
 	data, encPrefix, decPrefix, nonce []byte,
 	key [32]byte) ([]byte, error) {
@@ -66,6 +133,9 @@ func encryptValues(
 		}
 		b.Write(data[s:(s + e)])
 		b.Write(decPrefix)
+		if _, err := b64.Write(keyID(key)); err != nil {
+			return nil, err
+		}
 		if _, err := b64.Write(nonce); err != nil {
 			return nil, err
 		}
@@ -83,25 +153,20 @@ func encryptValues(
 	return b.Bytes(), nil
 }
 
-// decryptValues decrypts the values in the data using the given key
+// decryptValues decrypts the values in the data using the given keys
 // data: the data to decrypt
 // prefix: the prefix to search for the values to decrypt
-// key: the key to use for decryption
-func decryptValues(data, prefix []byte, key [32]byte) ([]byte, error) {
+// keys: the current key followed by any previous keys still accepted
+func decryptValues(data, prefix []byte, keys ...[32]byte) ([]byte, error) {
 	var s, e int
 	if e = bytes.Index(data[s:], prefix); e == -1 {
 		return data, nil
 	}
 
 	var b bytes.Buffer
-	var buf [500]byte
+	var buf, pbuf [500]byte
 
-	block, err := aes.NewCipher(key[:])
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
+	ck, err := newCryptKeys(keys)
 	if err != nil {
 		return nil, err
 	}
@@ -132,13 +197,9 @@ func decryptValues(data, prefix []byte, key [32]byte) ([]byte, error) {
 
 		var out1 []byte
 		if !fail {
-			out1, err = gcm.Open(
-				out[gcm.NonceSize():][:0],
-				out[:gcm.NonceSize()],
-				out[gcm.NonceSize():],
-				nil,
-			)
-			fail = err != nil
+			var ok bool
+			out1, ok = openValue(ck, out, pbuf[:])
+			fail = !ok
 		}
 
 		if s == 0 {
@@ -72,3 +72,31 @@ func TestCryptFirstEncyptedValue(t *testing.T) {
 	out1 := firstCursorValue(jsb, []byte("boo"))
 	assert.Empty(t, out1)
 }
+
+func TestCryptKeyRotation(t *testing.T) {
+	encPrefix := "__gj:foobar:"
+	decPrefix := "__gj:enc:"
+
+	js := []byte(fmt.Sprintf(
+		`{ posts_cursor: "%s12345" }`, encPrefix))
+
+	expjs := []byte(`{ posts_cursor: "12345" }`)
+
+	oldKey := sha256.Sum256([]byte("old"))
+	newKey := sha256.Sum256([]byte("new"))
+	nonce := sha256.Sum256(js)
+
+	out1, err := encryptValues(
+		js, []byte(encPrefix), []byte(decPrefix), nonce[:], oldKey)
+	assert.NoErrorFatal(t, err)
+
+	// decrypts with the previous key after a rotation
+	out2, err := decryptValues(out1, []byte(decPrefix), newKey, oldKey)
+	assert.NoErrorFatal(t, err)
+	assert.Equals(t, expjs, out2)
+
+	// left as is once the previous key is dropped
+	out3, err := decryptValues(out1, []byte(decPrefix), newKey)
+	assert.NoErrorFatal(t, err)
+	assert.Equals(t, out1, out3)
+}
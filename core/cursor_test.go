@@ -0,0 +1,94 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCursorSecretKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(secret string, previous ...string) *core.GraphJin {
+		conf := &core.Config{
+			DBType:             "sqlite",
+			DisableAllowList:   true,
+			SecretKey:          secret,
+			PreviousSecretKeys: previous,
+		}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+
+	gql := `query {
+		products(first: 2, after: $cursor, order_by: { id: asc }) {
+			id
+		}
+		products_cursor
+	}`
+
+	type page struct {
+		Products []struct{ ID int }
+		Cursor   string `json:"products_cursor"`
+	}
+
+	fetch := func(gj *core.GraphJin, cursor string) page {
+		t.Helper()
+		vars, _ := json.Marshal(map[string]interface{}{"cursor": cursor})
+		res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p page
+		if err := json.Unmarshal(res.Data, &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	p1 := fetch(newGJ("old-secret"), "")
+	if len(p1.Products) != 2 || p1.Cursor == "" {
+		t.Fatalf("unexpected first page: %+v", p1)
+	}
+
+	// a cursor issued with the old secret still works after the rotation
+	rotated := newGJ("new-secret", "old-secret")
+	p2 := fetch(rotated, p1.Cursor)
+	if len(p2.Products) != 2 || p2.Products[0].ID != 3 {
+		t.Fatalf("expected the second page after rotation, got %+v", p2)
+	}
+
+	// new cursors are issued with the new secret
+	p3 := fetch(newGJ("new-secret"), p2.Cursor)
+	if len(p3.Products) != 1 || p3.Products[0].ID != 5 {
+		t.Fatalf("expected the last page with the new secret, got %+v", p3)
+	}
+
+	// without the old secret the cursor is ignored and paging starts over
+	p4 := fetch(newGJ("new-secret"), p1.Cursor)
+	if len(p4.Products) == 0 || p4.Products[0].ID != 1 {
+		t.Fatalf("expected the old cursor to be ignored, got %+v", p4)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -34,8 +36,12 @@ func (s *gstate) execDatabaseJoins(c context.Context) (err error) {
 	if len(from) == 0 {
 		return nil // No IDs to join on
 	}
+	s.countCalls(from, sfmap)
 
 	// Execute queries against target databases and get replacement data
+	c, cancel := s.budget.context(c)
+	defer cancel()
+
 	to, err := s.resolveDatabaseJoins(c, from, sfmap)
 	if err != nil {
 		return
@@ -89,6 +95,15 @@ func (s *gstate) resolveDatabaseJoins(
 		// Extract parent ID value
 		idVal := jsn.Value(id.Value)
 
+		// Stop fanning out once the budget is used up
+		if len(idVal) != 0 && string(idVal) != "null" {
+			if err := s.budget.acquire(); err != nil {
+				to[i] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+				wg.Done()
+				continue
+			}
+		}
+
 		go func(n int, idVal []byte, sel *qcode.Select, dbCtx *dbContext, parentTable string) {
 			defer wg.Done()
 
@@ -108,6 +123,12 @@ func (s *gstate) resolveDatabaseJoins(
 			}
 
 			b, err := s.executeDatabaseJoinQuery(ctx1, dbCtx, sel, idVal)
+			if err = s.budget.check(err); errors.Is(err, ErrBudgetExceeded) {
+				to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+				span.Error(err)
+				span.End()
+				return
+			}
 			if err != nil {
 				cerrMutex.Lock()
 				cerr = fmt.Errorf("database join %s.%s: %w", dbCtx.name, sel.Table, err)
@@ -124,6 +145,11 @@ func (s *gstate) resolveDatabaseJoins(
 			// Unwrap root JSON object: {"orders": [...]} -> [...]
 			b = jsn.Strip(b, [][]byte{[]byte(sel.Table)})
 
+			if err := s.budget.addRows(b, false); err != nil {
+				to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+				return
+			}
+
 			// Filter to only requested fields if specified
 			var ob bytes.Buffer
 			if len(sel.Fields) != 0 {
@@ -166,6 +192,9 @@ func (s *gstate) executeDatabaseJoinQuery(
 	if err != nil {
 		return nil, fmt.Errorf("qcode compile failed: %w", err)
 	}
+	if err := s.checkNamespaceBlocklist(qc); err != nil {
+		return nil, err
+	}
 
 	// Compile to SQL using the target database's SQL compiler
 	var sqlBuf bytes.Buffer
@@ -380,7 +409,7 @@ func (s *gstate) mergeRootResults(results []dbResult) error {
 
 		for k, v := range obj {
 			if _, exists := merged[k]; exists {
-				return fmt.Errorf("duplicate key '%s' in multi-database result", k)
+				return fmt.Errorf("duplicate key '%s' in multi-database result, use an alias for each database", k)
 			}
 			merged[k] = v
 		}
@@ -401,15 +430,15 @@ func (gj *graphjinEngine) isMultiDB() bool {
 }
 
 // buildDatabaseQuery creates a new GraphQL query containing only the specified root fields.
-// It parses the original query, filters to include only the given fields, and reconstructs
-// a valid GraphQL query string.
+// It parses the original query, filters to include only the given fields (by alias or
+// name), and reconstructs a valid GraphQL query string.
 func (s *gstate) buildDatabaseQuery(rootFields []string) ([]byte, error) {
 	op, err := graph.Parse(s.r.query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
 
-	// Build a set of allowed root field names
+	// Build a set of allowed root field keys
 	allowed := make(map[string]bool, len(rootFields))
 	for _, f := range rootFields {
 		allowed[f] = true
@@ -418,7 +447,7 @@ func (s *gstate) buildDatabaseQuery(rootFields []string) ([]byte, error) {
 	// Find the root field IDs we want to keep
 	keepFieldIDs := make(map[int32]bool)
 	for _, f := range op.Fields {
-		if f.ParentID == -1 && allowed[f.Name] {
+		if f.ParentID == -1 && allowed[fieldKey(f)] {
 			keepFieldIDs[f.ID] = true
 			// Also mark all descendants
 			markDescendants(op.Fields, f.ID, keepFieldIDs)
@@ -452,7 +481,10 @@ func (s *gstate) buildDatabaseQuery(rootFields []string) ([]byte, error) {
 			}
 			buf.WriteString("$")
 			buf.WriteString(v.Name)
-			// We don't have type info, so we'll rely on the compiler to infer
+			if v.Type != "" {
+				buf.WriteString(": ")
+				buf.WriteString(v.Type)
+			}
 		}
 		buf.WriteString(")")
 	}
@@ -585,8 +617,24 @@ func (s *gstate) executeParallelRoots(c context.Context) error {
 	var wg sync.WaitGroup
 	results := make([]dbResult, len(s.dbGroups))
 
-	i := 0
-	for dbName, rootFields := range s.dbGroups {
+	c, cancel := s.budget.context(c)
+	defer cancel()
+
+	// Sorted so the same databases are skipped when the budget runs out
+	dbNames := make([]string, 0, len(s.dbGroups))
+	for dbName := range s.dbGroups {
+		dbNames = append(dbNames, dbName)
+	}
+	sort.Strings(dbNames)
+
+	for i, dbName := range dbNames {
+		rootFields := s.dbGroups[dbName]
+
+		if err := s.budget.acquire(); err != nil {
+			results[i] = dbResult{database: dbName, data: nullRootFields(rootFields)}
+			continue
+		}
+
 		wg.Add(1)
 		go func(idx int, db string, fields []string) {
 			defer wg.Done()
@@ -596,21 +644,40 @@ func (s *gstate) executeParallelRoots(c context.Context) error {
 			defer span.End()
 
 			data, err := s.executeForDatabaseRoots(ctx1, db, fields)
-			if err != nil {
+			if err = s.budget.check(err); err != nil {
 				span.Error(err)
 			}
 
+			if errors.Is(err, ErrBudgetExceeded) {
+				data, err = nullRootFields(fields), nil
+			} else if err == nil && s.budget.addRows(data, true) != nil {
+				data = nullRootFields(fields)
+			}
+
 			results[idx] = dbResult{
 				database: db,
 				data:     data,
 				err:      err,
 			}
 		}(i, dbName, rootFields)
-		i++
 	}
 
 	wg.Wait()
-	return s.mergeRootResults(results)
+	if err := s.mergeRootResults(results); err != nil {
+		return err
+	}
+	return s.budget.exceeded()
+}
+
+// nullRootFields returns a result with the root fields set to null, used for
+// the databases skipped when the budget runs out
+func nullRootFields(fields []string) json.RawMessage {
+	obj := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		obj[f] = json.RawMessage("null")
+	}
+	data, _ := json.Marshal(obj)
+	return data
 }
 
 // executeForDatabaseRoots builds a sub-query for the specified root fields,
@@ -654,6 +721,9 @@ func (s *gstate) executeForDatabaseRoots(ctx context.Context, dbName string, roo
 	if err != nil {
 		return nil, fmt.Errorf("qcode compile failed for %s: %w", dbName, err)
 	}
+	if err := s.checkNamespaceBlocklist(qc); err != nil {
+		return nil, err
+	}
 
 	// Compile SQL
 	var sqlBuf bytes.Buffer
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRequestDatabaseOverride(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+		_, err = db.Exec(`
+			CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+			INSERT INTO products (id, name) VALUES (1, '` + name + `');
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	primary := openDB("primary")
+	reporting := openDB("reporting")
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			core.DefaultDBName: {Type: "sqlite"},
+			"reporting":        {Type: "sqlite"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, primary,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"reporting": reporting}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query getProducts { products { name } }`
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"products":[{"name":"primary"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	rc := &core.RequestConfig{}
+	rc.SetDatabase("reporting")
+	res, err = gj.GraphQL(context.Background(), gql, nil, rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"products":[{"name":"reporting"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	rc.SetDatabase("missing")
+	if _, err = gj.GraphQL(context.Background(), gql, nil, rc); err == nil {
+		t.Fatal("expected an error for an unknown database")
+	}
+}
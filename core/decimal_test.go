@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDecimalAsString(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE payments (id INTEGER PRIMARY KEY, amount DECIMAL(20,2), fee REAL);
+		INSERT INTO payments (id, amount, fee) VALUES
+			(1, '1234.56', 1.5), (2, 12.5, 0.25);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		DecimalAsString:  true,
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query {
+		payments(order_by: { id: asc }) { id amount fee }
+	}`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"payments":[{"id":1,"amount":"1234.56","fee":1.5},{"id":2,"amount":"12.5","fee":0.25}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	res, err = gj.GraphQL(t.Context(), `query ($amount: Decimal) {
+		payments(where: { amount: { eq: $amount } }) { id }
+	}`, []byte(`{"amount": "12.5"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = `{"payments":[{"id":2}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+}
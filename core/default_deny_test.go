@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDefaultDeny(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+		INSERT INTO products (id, name, price) VALUES (1, 'Lamp', 10);
+		INSERT INTO users (id, email) VALUES (1, 'a@b.c');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		DefaultDeny:      true,
+		Roles: []core.Role{
+			{Name: "anon", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{Columns: []string{"id", "name"}}},
+			}},
+			{Name: "user", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{}, Insert: &core.Insert{}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anon := context.Background()
+	user := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	tests := []struct {
+		name    string
+		c       context.Context
+		query   string
+		allowed bool
+	}{
+		{"anon granted query", anon, `query { products { id name } }`, true},
+		{"anon column not granted", anon, `query { products { id price } }`, false},
+		{"anon mutation not granted", anon, `mutation { products(insert: { id: 2, name: "Desk" }) { id } }`, false},
+		{"user granted query", user, `query { products { id price } }`, true},
+		{"user granted insert", user, `mutation { products(insert: { id: 3, name: "Chair" }) { id } }`, true},
+		{"user update not granted", user, `mutation { products(id: 1, update: { name: "Desk" }) { id } }`, false},
+	}
+
+	for _, tt := range tests {
+		_, err := gj.GraphQL(tt.c, tt.query, nil, nil)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	// queries on blocked tables return null
+	for _, c := range []context.Context{anon, user} {
+		res, err := gj.GraphQL(c, `query { users { id } }`, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(res.Data) != `{"users":null}` {
+			t.Fatalf("expected users to be blocked: %s", res.Data)
+		}
+	}
+
+	audit, err := gj.AuditRolePermissions("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tp := audit.Tables[0]; !tp.Query.Allowed || !tp.Insert.Allowed || tp.Update.Allowed || tp.Delete.Allowed {
+		t.Fatalf("unexpected permissions: %+v", tp)
+	}
+}
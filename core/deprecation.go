@@ -0,0 +1,70 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/allow"
+)
+
+// ErrQueryRetired is returned when a deprecated saved query is used after
+// its sunset date
+var ErrQueryRetired = errors.New("query retired")
+
+// QueryDeprecation is set on saved queries using the @deprecated directive
+//
+//	query getProductsV1 @deprecated(sunset: "2026-12-31", replacement: "getProducts") { ... }
+type QueryDeprecation struct {
+	Reason      string `json:"reason,omitempty"`
+	Sunset      string `json:"sunset,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// newQueryDeprecation returns the deprecation of a saved query or nil
+func newQueryDeprecation(d *allow.Deprecation) *QueryDeprecation {
+	if d == nil {
+		return nil
+	}
+	return &QueryDeprecation{
+		Reason:      d.Reason,
+		Sunset:      d.Sunset,
+		Replacement: d.Replacement,
+	}
+}
+
+// SunsetTime returns the start of the sunset date in UTC
+func (d *QueryDeprecation) SunsetTime() (time.Time, bool) {
+	if d.Sunset == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", d.Sunset)
+	return t, err == nil
+}
+
+// Message returns a description of the deprecation for warnings and errors
+func (d *QueryDeprecation) Message(name string) string {
+	msg := fmt.Sprintf("query %s is deprecated", name)
+	if d.Reason != "" {
+		msg += ": " + d.Reason
+	}
+	if d.Sunset != "" {
+		msg += fmt.Sprintf(" (sunset %s)", d.Sunset)
+	}
+	if d.Replacement != "" {
+		msg += fmt.Sprintf(", use %s instead", d.Replacement)
+	}
+	return msg
+}
+
+// checkSunset returns an error if the query is past its sunset date
+func (d *QueryDeprecation) checkSunset(name string, now time.Time) error {
+	if t, ok := d.SunsetTime(); ok && !now.Before(t) {
+		if d.Replacement != "" {
+			return fmt.Errorf("%w: %s was retired on %s, use %s instead",
+				ErrQueryRetired, name, d.Sunset, d.Replacement)
+		}
+		return fmt.Errorf("%w: %s was retired on %s", ErrQueryRetired, name, d.Sunset)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDeprecatedQuery(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'Lamp');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]string{
+		"getProducts": `query getProducts { products { id name } }`,
+		"getProductsV1": `query getProductsV1 @deprecated(reason: "renamed", sunset: "2999-01-01", replacement: "getProducts") {
+			products { id name } }`,
+		"getProductsV0": `query getProductsV0 @deprecated(sunset: "2020-01-01", replacement: "getProducts") {
+			products { id name } }`,
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "queries"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, q := range queries {
+		if err := os.WriteFile(filepath.Join(dir, "queries", name+".gql"), []byte(q), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQLByName(context.Background(), "getProducts", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Deprecation() != nil {
+		t.Fatal("expected getProducts not to be deprecated")
+	}
+
+	res, err = gj.GraphQLByName(context.Background(), "getProductsV1", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Data) != `{"products":[{"id":1,"name":"Lamp"}]}` {
+		t.Fatalf("unexpected data: %s", res.Data)
+	}
+	d := res.Deprecation()
+	if d == nil || d.Sunset != "2999-01-01" || d.Replacement != "getProducts" || d.Reason != "renamed" {
+		t.Fatalf("unexpected deprecation: %+v", d)
+	}
+
+	_, err = gj.GraphQLByName(context.Background(), "getProductsV0", nil, nil)
+	if !errors.Is(err, core.ErrQueryRetired) || !strings.Contains(err.Error(), "use getProducts instead") {
+		t.Fatalf("expected the query to be retired: %v", err)
+	}
+
+	list, err := gj.ListSavedQueries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deprecated := 0
+	for _, q := range list {
+		if q.Deprecation != nil {
+			deprecated++
+		}
+	}
+	if deprecated != 2 {
+		t.Fatalf("expected 2 deprecated queries: %+v", list)
+	}
+
+	spec, err := gj.GenerateOpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := spec.Paths["/getProductsV1"].Get; op == nil || !op.Deprecated {
+		t.Fatalf("expected getProductsV1 to be deprecated in the openapi spec: %+v", spec.Paths["/getProductsV1"])
+	}
+	if op := spec.Paths["/getProducts"].Get; op == nil || op.Deprecated {
+		t.Fatalf("expected getProducts not to be deprecated in the openapi spec")
+	}
+
+	gql := `query badSunset @deprecated(sunset: "soon") { products { id } }`
+	if _, err = gj.GraphQL(context.Background(), gql, nil, nil); err == nil {
+		t.Fatal("expected an error for an invalid sunset date")
+	}
+}
@@ -113,7 +113,7 @@ func (g *GraphJin) GenerateDiscovery(ctx context.Context, database string) (*Dis
 	if !ok {
 		return nil, fmt.Errorf("database not found: %s", database)
 	}
-	if dbCtx.schema == nil {
+	if !dbCtx.ready() {
 		return nil, fmt.Errorf("database %s: schema not ready", database)
 	}
 
@@ -381,7 +381,7 @@ func writeQuerySyntaxReference(sb *strings.Builder, defaultLimit int) {
 	sb.WriteString("Comparison: eq, neq, gt, gte, lt, lte\n")
 	sb.WriteString("List:       in, nin (not_in)          — MUST be arrays: { id: { in: [1,2,3] } }\n")
 	sb.WriteString("Null:       is_null                   — { col: { is_null: true } }\n")
-	sb.WriteString("Text:       like, ilike, regex        — ilike needs % wildcards: { name: { ilike: \"%bike%\" } }\n")
+	sb.WriteString("Text:       like, ilike, regex, eq_ci — ilike needs % wildcards: { name: { ilike: \"%bike%\" } }\n")
 	sb.WriteString("JSON:       has_key, has_key_any, has_key_all, contains, contained_in\n")
 	sb.WriteString("Logical:    and, or, not              — { and: [{ price: { gt: 10 } }, { price: { lt: 100 } }] }\n")
 	sb.WriteString("```\n\n")
@@ -813,7 +813,7 @@ func (g *GraphJin) writeNamespaceRouting(sb *strings.Builder, gj *graphjinEngine
 			isDefault = " **(default)**"
 		}
 		tableCount := 0
-		if ctx.schema != nil {
+		if ctx.ready() {
 			tableCount = len(ctx.schema.GetTables())
 		}
 		sb.WriteString(fmt.Sprintf("- `%s`: %s, %d tables%s\n", name, ctx.dbtype, tableCount, isDefault))
@@ -1237,7 +1237,7 @@ func (g *GraphJin) SubscribeDiscovery(ctx context.Context, database string) (*Di
 			return nil, err
 		}
 		for _, name := range gj.sortedDatabaseNames() {
-			if gj.databases[name].schema != nil {
+			if gj.databases[name].ready() {
 				doc, err := g.GenerateDiscovery(ctx, name)
 				if err != nil {
 					continue
@@ -0,0 +1,85 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDistinctKeepsFields(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, category TEXT, name TEXT, price REAL);
+		INSERT INTO products (id, category, name, price) VALUES
+			(1, 'books', 'novel', 10), (2, 'books', 'atlas', 30),
+			(3, 'games', 'chess', 20), (4, 'games', 'go', 15),
+			(5, 'toys', 'ball', 5);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		exp   string
+	}{
+		{"first by order", `query {
+			products(distinct: [category], order_by: { price: desc }) { id category name }
+		}`,
+			`{"products": [
+				{"id": 2, "category": "books", "name": "atlas"},
+				{"id": 3, "category": "games", "name": "chess"},
+				{"id": 5, "category": "toys", "name": "ball"}]}`},
+		{"distinct desc", `query {
+			products(distinct: [category], order_by: { category: desc, price: asc }) { id category }
+		}`,
+			`{"products": [
+				{"id": 5, "category": "toys"},
+				{"id": 4, "category": "games"},
+				{"id": 1, "category": "books"}]}`},
+		{"with where and limit", `query {
+			products(distinct: [category], where: { price: { lt: 25 } }, order_by: { price: asc }, limit: 2) { id category price }
+		}`,
+			`{"products": [
+				{"id": 1, "category": "books", "price": 10},
+				{"id": 4, "category": "games", "price": 15}]}`},
+	}
+
+	for _, tt := range tests {
+		res, err := gj.GraphQL(context.Background(), tt.query, nil, nil)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		var got, exp map[string]any
+		if err := json.Unmarshal(res.Data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.exp), &exp); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.exp, res.Data)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDurationOperators(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, created_at TIMESTAMP);
+		INSERT INTO events (id, created_at) VALUES
+			(1, datetime('now', '-2 hours')),
+			(2, datetime('now', '-3 days')),
+			(3, datetime('now', '-10 days'));`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		where string
+		exp   string
+	}{
+		{`{ created_at: { within_last: "PT12H" } }`, `{"events":[{"id":1}]}`},
+		{`{ created_at: { within_last: "P7D" } }`, `{"events":[{"id":1},{"id":2}]}`},
+		{`{ created_at: { older_than: "P1W" } }`, `{"events":[{"id":3}]}`},
+	}
+
+	for _, tt := range tests {
+		res, err := gj.GraphQL(t.Context(), `query {
+			events(where: `+tt.where+`, order_by: { id: asc }) { id }
+		}`, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(res.Data) != tt.exp {
+			t.Fatalf("%s: expected %s, got %s", tt.where, tt.exp, res.Data)
+		}
+	}
+
+	_, err = gj.GraphQL(t.Context(), `query {
+		events(where: { created_at: { within_last: "P1M" } }) { id }
+	}`, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for a duration in months")
+	}
+}
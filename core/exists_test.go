@@ -0,0 +1,88 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestExistsRoot(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, owner_id INTEGER, price REAL);
+		INSERT INTO products (id, owner_id, price) VALUES (1, 1, 10), (2, 2, 20);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Roles: []core.Role{
+			{Name: "user", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{
+					Filters: []string{"{ owner_id: { eq: $user_id } }"},
+				}},
+			}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anon := context.Background()
+	user := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	tests := []struct {
+		name  string
+		c     context.Context
+		query string
+		exp   string
+	}{
+		{"any row", anon, `query { products_exists }`,
+			`{"products_exists": true}`},
+		{"no match", anon, `query { products_exists(where: { price: { gt: 100 } }) }`,
+			`{"products_exists": false}`},
+		{"with count", anon, `query { products_exists products_count }`,
+			`{"products_exists": true, "products_count": 2}`},
+		{"role filter", user, `query { products_exists(where: { id: { eq: 2 } }) }`,
+			`{"products_exists": false}`},
+	}
+
+	for _, tt := range tests {
+		res, err := gj.GraphQL(tt.c, tt.query, nil, nil)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		var got, exp map[string]any
+		if err := json.Unmarshal(res.Data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.exp), &exp); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.exp, res.Data)
+		}
+	}
+
+	if _, err := gj.GraphQL(anon, `query { products_exists { id } }`, nil, nil); err == nil {
+		t.Error("expected an error selecting fields on an exists query")
+	}
+}
@@ -0,0 +1,306 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+const federationLink = `extend schema @link(url: "https://specs.apollo.dev/federation/v2.3", import: ["@key"])`
+
+// entityKeys returns the entity types of the schema with their key field,
+// tables with a single column primary key are entities keyed by it
+func (gj *graphjinEngine) entityKeys() map[string]string {
+	keys := make(map[string]string)
+
+	for _, dbName := range gj.sortedDatabaseNames() {
+		ctx := gj.databases[dbName]
+		if !ctx.ready() {
+			continue
+		}
+		for _, t := range ctx.schema.GetTables() {
+			if t.Blocked || len(t.Columns) == 0 || len(t.PrimaryCols) != 1 {
+				continue
+			}
+			name := gj.namer.Table(t.Name)
+			if _, ok := keys[name]; ok {
+				continue
+			}
+			keys[name] = gj.namer.Column(t.Name, t.PrimaryCol.Name)
+		}
+	}
+	return keys
+}
+
+// getServiceSDL returns the SDL of the schema as a federation subgraph
+func (gj *graphjinEngine) getServiceSDL() (data []byte, err error) {
+	var ok bool
+	if data, ok = gj.cache.Get("_fed_sdl"); ok {
+		return
+	}
+
+	schema, err := gj.introSchema()
+	if err != nil {
+		return
+	}
+
+	data = []byte(federationLink + "\n\n" + writeSDL(schema, gj.entityKeys()))
+	gj.cache.Set("_fed_sdl", data)
+	return
+}
+
+// isFederationQuery returns true if the query asks for the _service or
+// _entities fields of a federation router
+func isFederationQuery(query []byte) bool {
+	return bytes.Contains(query, []byte("_service")) ||
+		bytes.Contains(query, []byte("_entities"))
+}
+
+// federationQuery answers the _service and _entities queries of a
+// federation router. Entities are fetched with a query per type that looks
+// up the rows by primary key with the selection asked for by the router.
+func (gj *graphjinEngine) federationQuery(c context.Context, r GraphqlReq) (res Result, ok bool, err error) {
+	res = Result{
+		namespace: r.namespace,
+		operation: qcode.QTQuery,
+		name:      r.name,
+	}
+
+	op, err := graph.Parse(r.query)
+	if err != nil {
+		return
+	}
+
+	for _, f := range op.Fields {
+		if f.ParentID == -1 && (f.Name == "_service" || f.Name == "_entities") {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return
+	}
+
+	var vars map[string]json.RawMessage
+	if len(r.vars) != 0 {
+		if err = json.Unmarshal(r.vars, &vars); err != nil {
+			return
+		}
+	}
+
+	var data bytes.Buffer
+	data.WriteByte('{')
+	n := 0
+
+	for i := range op.Fields {
+		f := &op.Fields[i]
+		if f.ParentID != -1 {
+			continue
+		}
+
+		key := f.Name
+		if f.Alias != "" {
+			key = f.Alias
+		}
+
+		var v []byte
+		switch f.Name {
+		case "_service":
+			var sdl []byte
+			if sdl, err = gj.getServiceSDL(); err != nil {
+				return
+			}
+			if v, err = json.Marshal(map[string]string{"sdl": string(sdl)}); err != nil {
+				return
+			}
+		case "_entities":
+			if v, err = gj.resolveEntities(c, r, &op, f, vars); err != nil {
+				return
+			}
+		default:
+			err = fmt.Errorf("federation: '%s' cannot be queried with _service or _entities", f.Name)
+			return
+		}
+
+		if n != 0 {
+			data.WriteByte(',')
+		}
+		data.WriteString(strconv.Quote(key))
+		data.WriteByte(':')
+		data.Write(v)
+		n++
+	}
+	data.WriteByte('}')
+
+	res.Data = data.Bytes()
+	return
+}
+
+// resolveEntities returns the entities for the representations in the
+// order they were asked for, representations not found are null
+func (gj *graphjinEngine) resolveEntities(c context.Context,
+	r GraphqlReq,
+	op *graph.Operation,
+	f *graph.Field,
+	vars map[string]json.RawMessage,
+) ([]byte, error) {
+	var reps []map[string]json.RawMessage
+
+	for _, a := range f.Args {
+		if a.Name != "representations" {
+			continue
+		}
+		if a.Val == nil || a.Val.Type != graph.NodeVar {
+			return nil, errors.New("federation: representations must be passed as a variable")
+		}
+		if err := json.Unmarshal(vars[a.Val.Val], &reps); err != nil {
+			return nil, fmt.Errorf("federation: invalid representations: %w", err)
+		}
+	}
+
+	// the selection for each type and if __typename is asked for
+	members := make(map[string]*graph.Field)
+	typename := false
+	for _, cid := range f.Children {
+		cf := &op.Fields[cid]
+		switch {
+		case cf.Name == "__typename":
+			typename = true
+		case len(cf.Children) != 0:
+			members[cf.Name] = cf
+		}
+	}
+
+	keys := gj.entityKeys()
+	entities := make([]map[string]json.RawMessage, len(reps))
+	byType := make(map[string][]int)
+	var order []string
+
+	for i, rep := range reps {
+		var name string
+		if err := json.Unmarshal(rep["__typename"], &name); err != nil || name == "" {
+			return nil, fmt.Errorf("federation: representation %d has no __typename", i)
+		}
+		if _, ok := byType[name]; !ok {
+			order = append(order, name)
+		}
+		byType[name] = append(byType[name], i)
+	}
+
+	for _, name := range order {
+		k, ok := keys[name]
+		if !ok {
+			return nil, fmt.Errorf("federation: '%s' is not an entity", name)
+		}
+
+		idx := byType[name]
+		ids := make([]json.RawMessage, 0, len(idx))
+		for _, i := range idx {
+			v, ok := reps[i][k]
+			if !ok {
+				return nil, fmt.Errorf("federation: representation %d has no '%s'", i, k)
+			}
+			ids = append(ids, v)
+		}
+
+		rows, err := gj.fetchEntities(c, r, op, members[name], name, k, ids, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range idx {
+			row, ok := rows[entityID(reps[i][k])]
+			if !ok {
+				continue
+			}
+			if typename || row["__typename"] != nil {
+				row["__typename"] = json.RawMessage(strconv.Quote(name))
+			}
+			entities[i] = row
+		}
+	}
+	return json.Marshal(entities)
+}
+
+// fetchEntities looks up the rows of an entity type by primary key and
+// returns them keyed by it
+func (gj *graphjinEngine) fetchEntities(c context.Context,
+	r GraphqlReq,
+	op *graph.Operation,
+	member *graph.Field,
+	name string,
+	k string,
+	ids []json.RawMessage,
+	vars map[string]json.RawMessage,
+) (map[string]map[string]json.RawMessage, error) {
+	var q bytes.Buffer
+	q.WriteString("query { _fed_rows: " + name +
+		"(where: { " + k + ": { in: $_fed_ids } }, limit: " +
+		strconv.Itoa(len(ids)) + ") { _fed_key: " + k)
+
+	if member != nil {
+		for _, cid := range member.Children {
+			q.WriteByte(' ')
+			graph.WriteField(&q, op.Fields, cid)
+		}
+	}
+	q.WriteString(" } }")
+
+	v := make(map[string]json.RawMessage, len(vars)+1)
+	for name, val := range vars {
+		v[name] = val
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+	v["_fed_ids"] = b
+
+	vb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r1 := gj.newGraphqlReq(r.requestconfig, "query", "", q.Bytes(), vb)
+	r1.namespace = r.namespace
+	r1.dynamic = true
+
+	resp, err := gj.query(c, r1)
+	if err == nil && len(resp.res.Errors) != 0 {
+		err = errors.New(resp.res.Errors[0].Message)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("federation: %s: %w", name, err)
+	}
+
+	var data struct {
+		Rows []map[string]json.RawMessage `json:"_fed_rows"`
+	}
+	if err := json.Unmarshal(resp.res.Data, &data); err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]map[string]json.RawMessage, len(data.Rows))
+	for _, row := range data.Rows {
+		id := entityID(row["_fed_key"])
+		delete(row, "_fed_key")
+		rows[id] = row
+	}
+	return rows, nil
+}
+
+// entityID returns a key value as a string so ids sent as strings match
+// numeric primary keys
+func entityID(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(v))
+}
@@ -0,0 +1,72 @@
+package core_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestFederation(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, full_name TEXT);
+		INSERT INTO users (id, full_name) VALUES (1, 'Ann'), (2, 'Bob');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		EnableFederation: true,
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query { _service { sdl } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svc struct {
+		Service struct {
+			SDL string `json:"sdl"`
+		} `json:"_service"`
+	}
+	if err := json.Unmarshal(res.Data, &svc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(svc.Service.SDL, `type users @key(fields: "id")`) {
+		t.Fatalf("expected users to be an entity, got %s", svc.Service.SDL)
+	}
+
+	res, err = gj.GraphQL(t.Context(), `query ($representations: [_Any!]!) {
+		_entities(representations: $representations) {
+			__typename
+			... on users { full_name }
+		}
+	}`, json.RawMessage(`{"representations": [
+		{"__typename": "users", "id": "2"},
+		{"__typename": "users", "id": 3},
+		{"__typename": "users", "id": 1}
+	]}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"_entities":[{"__typename":"users","full_name":"Bob"},null,{"__typename":"users","full_name":"Ann"}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSeparateForeignJoins(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	if _, err := db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), total INTEGER);
+		INSERT INTO users (id, name) VALUES (1, 'Ada'), (2, 'Grace');
+		INSERT INTO orders (id, user_id, total) VALUES (1, 1, 50), (2, 1, 20), (3, 2, 10);`); err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(separate bool) *GraphJin {
+		// orders stands in for a foreign data wrapper table
+		di, err := sdata.GetDBInfo(db, "sqlite", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range di.Tables {
+			if di.Tables[i].Name == "orders" {
+				di.Tables[i].ForeignServer = "warehouse"
+			}
+		}
+
+		conf := &Config{DBType: "sqlite", DisableAllowList: true, SeparateForeignJoins: separate}
+		g := &GraphJin{done: make(chan bool)}
+		if err := g.newGraphJin(conf, db, di, NewOsFS(dir)); err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+
+	query := `query { users(order_by: { id: asc }) { id orders(order_by: { id: asc }) { id total } } }`
+
+	for _, separate := range []bool{false, true} {
+		gj := newGJ(separate)
+
+		list, err := gj.CompileOnly(query, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].Error != "" {
+			t.Fatalf("unexpected statements: %+v", list)
+		}
+		if joined := strings.Contains(list[0].Statement, "__orders_db_join"); joined != separate {
+			t.Errorf("separate %v: unexpected statement: %s", separate, list[0].Statement)
+		}
+
+		res, err := gj.GraphQL(context.Background(), query, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := `{"users":[{"id":1,"orders":[{"id":1,"total":50},{"id":2,"total":20}]},{"id":2,"orders":[{"id":3,"total":10}]}]}`
+		if string(res.Data) != exp {
+			t.Errorf("separate %v: expected %s, got %s", separate, exp, res.Data)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGeneratedColumns(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE line_items (
+			id INTEGER PRIMARY KEY,
+			price INTEGER NOT NULL,
+			qty INTEGER NOT NULL,
+			total INTEGER GENERATED ALWAYS AS (price * qty) STORED,
+			label TEXT GENERATED ALWAYS AS ('x' || qty) VIRTUAL
+		);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values for generated columns are left out of the insert
+	gql := `mutation { line_items(insert: $data) { id total label } }`
+	vars := `{ "data": { "price": 5, "qty": 3, "total": 100, "label": "nope" } }`
+	res, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct {
+		LineItems []struct {
+			ID    int
+			Total int
+			Label string
+		} `json:"line_items"`
+	}
+	if err := json.Unmarshal(res.Data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.LineItems) != 1 || v.LineItems[0].Total != 15 || v.LineItems[0].Label != "x3" {
+		t.Fatalf("expected the generated values to be returned: %s", res.Data)
+	}
+
+	// and from updates
+	gql = `mutation { line_items(id: $id, update: $data) { total } }`
+	vars = `{ "id": 1, "data": { "qty": 4, "total": 100 } }`
+	res, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(res.Data), `"total": 20`) && !strings.Contains(string(res.Data), `"total":20`) {
+		t.Fatalf("expected the generated value to be updated: %s", res.Data)
+	}
+
+	// generated columns are not part of the mutation input types
+	b, err := gj.GetSDL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdl := string(b)
+	input := sdl[strings.Index(sdl, "input insertline_itemsInput"):]
+	input = input[:strings.Index(input, "}")]
+	if strings.Contains(input, "total") || !strings.Contains(input, "price") {
+		t.Fatalf("unexpected insert input: %s", input)
+	}
+}
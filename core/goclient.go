@@ -0,0 +1,373 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/allow"
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// GenerateGoClient generates the Go source of a typed client for the saved
+// queries and mutations in the allow list. Each query gets a method on the
+// Client with a struct for its variables and one for its result, backed by
+// GraphQLByName. Subscriptions and queries that fail to compile are skipped.
+func (g *GraphJin) GenerateGoClient(pkg string) ([]byte, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := gj.allowList.ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queries: %w", err)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	gen := goClientGen{gj: gj, types: make(map[string]bool)}
+
+	for _, item := range items {
+		if item.Namespace != gj.namespace {
+			continue
+		}
+		if err := gen.addQuery(item); err != nil {
+			gj.log.Printf("WRN go client: %s: %s", item.Name, err)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by graphjin. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString(`import (
+	"context"
+	"encoding/json"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+// Client runs the saved queries with typed variables and results
+type Client struct {
+	gj *core.GraphJin
+	rc *core.RequestConfig
+}
+
+// NewClient returns a client for the saved queries, rc is passed on to
+// every request and can be nil
+func NewClient(gj *core.GraphJin, rc *core.RequestConfig) *Client {
+	return &Client{gj: gj, rc: rc}
+}
+
+func (c *Client) run(ctx context.Context, name string, vars, res interface{}) error {
+	var vb json.RawMessage
+	if vars != nil {
+		b, err := json.Marshal(vars)
+		if err != nil {
+			return err
+		}
+		vb = b
+	}
+	r, err := c.gj.GraphQLByName(ctx, name, vb, c.rc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(r.Data, res)
+}
+`)
+	b.Write(gen.w.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("go client: %w", err)
+	}
+	return src, nil
+}
+
+type goClientGen struct {
+	gj    *graphjinEngine
+	w     bytes.Buffer
+	types map[string]bool
+}
+
+// addQuery writes the variables and result types and the method for a
+// saved query
+func (gen *goClientGen) addQuery(item allow.Item) error {
+	op, err := graph.Parse(item.Query)
+	if err != nil {
+		return err
+	}
+	if op.Type == graph.OpSub {
+		return nil
+	}
+
+	qc, md, err := gen.compile(item)
+	if err != nil {
+		return err
+	}
+
+	name := goName(item.Name)
+	if gen.types[name] {
+		return fmt.Errorf("duplicate name: %s", name)
+	}
+	gen.types[name] = true
+
+	vars := gen.vars(op, md)
+	if len(vars) != 0 {
+		fmt.Fprintf(&gen.w, "\n// %sVars holds the variables of %s\ntype %sVars struct {\n",
+			name, item.Name, name)
+		for _, v := range vars {
+			gen.w.WriteString(v)
+		}
+		gen.w.WriteString("}\n")
+	}
+
+	var nested []*qcode.Select
+	fmt.Fprintf(&gen.w, "\n// %sResult is the result of %s\ntype %sResult struct {\n",
+		name, item.Name, name)
+	for _, id := range qc.Roots {
+		nested = append(nested, gen.selectField(qc, &qc.Selects[id], name)...)
+	}
+	gen.w.WriteString("}\n")
+
+	for len(nested) != 0 {
+		sel := nested[0]
+		nested = nested[1:]
+		nested = append(nested, gen.selectType(qc, sel, name)...)
+	}
+
+	fmt.Fprintf(&gen.w, "\n// %s runs the saved query %s\n", name, item.Name)
+	if len(vars) != 0 {
+		fmt.Fprintf(&gen.w, "func (c *Client) %s(ctx context.Context, vars %sVars) (%sResult, error) {\n",
+			name, name, name)
+		fmt.Fprintf(&gen.w, "\tvar res %sResult\n\terr := c.run(ctx, %q, vars, &res)\n\treturn res, err\n}\n",
+			name, item.Name)
+	} else {
+		fmt.Fprintf(&gen.w, "func (c *Client) %s(ctx context.Context) (%sResult, error) {\n",
+			name, name)
+		fmt.Fprintf(&gen.w, "\tvar res %sResult\n\terr := c.run(ctx, %q, nil, &res)\n\treturn res, err\n}\n",
+			name, item.Name)
+	}
+	return nil
+}
+
+// compile compiles the saved query on the first database that can
+func (gen *goClientGen) compile(item allow.Item) (*qcode.QCode, psql.Metadata, error) {
+	var err error
+	for _, dbName := range gen.gj.sortedDatabaseNames() {
+		ctx := gen.gj.databases[dbName]
+		if ctx.qcodeCompiler == nil || ctx.psqlCompiler == nil {
+			continue
+		}
+		var qc *qcode.QCode
+		if qc, err = ctx.qcodeCompiler.Compile(item.Query, item.ActionJSON, "admin", item.Namespace); err != nil {
+			continue
+		}
+		var md psql.Metadata
+		if md, err = ctx.psqlCompiler.Compile(&bytes.Buffer{}, qc); err != nil {
+			continue
+		}
+		return qc, md, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("no database with compiler available")
+	}
+	return nil, psql.Metadata{}, err
+}
+
+// vars returns the fields of the variables struct, declared variables use
+// their GraphQL type and the others the type of the column they are used with
+func (gen *goClientGen) vars(op graph.Operation, md psql.Metadata) []string {
+	var fields []string
+	seen := make(map[string]bool)
+
+	for _, vd := range op.VarDef {
+		seen[vd.Name] = true
+		t := "json.RawMessage"
+		if vt, err := parseVarType(vd.Type); vd.Type != "" && err == nil {
+			t = goVarType(vt)
+		}
+		fields = append(fields, goStructField(vd.Name, t, t[0] == '*' || t[0] == '[' || t == "json.RawMessage"))
+	}
+
+	for _, p := range md.Params() {
+		if seen[p.Name] || isBuiltinVar(p.Name) {
+			continue
+		}
+		seen[p.Name] = true
+		t := goColType(p.Type, p.IsArray)
+		if !strings.HasPrefix(t, "[]") && t != "json.RawMessage" {
+			t = "*" + t
+		}
+		fields = append(fields, goStructField(p.Name, t, true))
+	}
+	return fields
+}
+
+// selectField writes the field for a select and returns the select when it
+// needs a type of its own
+func (gen *goClientGen) selectField(qc *qcode.QCode, sel *qcode.Select, query string) []*qcode.Select {
+	var nested []*qcode.Select
+
+	var t string
+	switch {
+	case sel.SkipRender == qcode.SkipTypeDrop:
+		return nil
+	case sel.Count:
+		t = "int"
+	case sel.Exists:
+		t = "bool"
+	case sel.Call:
+		t = "*" + goColType(sel.Func.Type, false)
+	case sel.SkipRender == qcode.SkipTypeRemote || sel.Type == qcode.SelTypeUnion:
+		t = "json.RawMessage"
+	default:
+		nested = append(nested, sel)
+		t = selectTypeName(qc, sel, query)
+		if sel.Singular {
+			t = "*" + t
+		} else {
+			t = "[]" + t
+		}
+	}
+	gen.w.WriteString(goStructField(sel.FieldName, t, false))
+
+	if sel.Paging.Cursor {
+		gen.w.WriteString(goStructField(sel.FieldName+"_cursor", "*string", false))
+	}
+	return nested
+}
+
+// selectType writes the struct type for the rows of a select
+func (gen *goClientGen) selectType(qc *qcode.QCode, sel *qcode.Select, query string) []*qcode.Select {
+	var nested []*qcode.Select
+
+	fmt.Fprintf(&gen.w, "\ntype %s struct {\n", selectTypeName(qc, sel, query))
+	if sel.Typename {
+		gen.w.WriteString(goStructField("__typename", "string", false))
+	}
+	for _, f := range sel.Fields {
+		if f.SkipRender == qcode.SkipTypeDrop {
+			continue
+		}
+		var t string
+		switch f.Type {
+		case qcode.FieldTypeFunc:
+			t = "*" + goColType(f.Func.Type, false)
+		default:
+			t = goColType(f.Col.Type, f.Col.Array)
+			if f.AsString {
+				t = "string"
+			}
+			if !f.Col.NotNull && !f.Col.PrimaryKey &&
+				!strings.HasPrefix(t, "[]") && t != "json.RawMessage" {
+				t = "*" + t
+			}
+		}
+		gen.w.WriteString(goStructField(f.FieldName, t, false))
+	}
+	for _, id := range sel.Children {
+		nested = append(nested, gen.selectField(qc, &qc.Selects[id], query)...)
+	}
+	gen.w.WriteString("}\n")
+	return nested
+}
+
+// selectTypeName returns the name of the struct type for a select, built
+// from the names of the query and the fields leading to it
+func selectTypeName(qc *qcode.QCode, sel *qcode.Select, query string) string {
+	return query + goName(strings.ReplaceAll(selectPath(qc.Selects, sel.ID), ".", "_"))
+}
+
+// goStructField returns a struct field for the json key
+func goStructField(key, t string, omitEmpty bool) string {
+	tag := key
+	if omitEmpty {
+		tag += ",omitempty"
+	}
+	return fmt.Sprintf("\t%s %s `json:%q`\n", goName(key), t, tag)
+}
+
+// goVarType returns the Go type for a GraphQL variable type, nullable
+// values are pointers
+func goVarType(vt *varType) string {
+	var t string
+	switch {
+	case vt.elem != nil:
+		return "[]" + strings.TrimPrefix(goVarType(vt.elem), "*")
+	case vt.name == "Int":
+		t = "int"
+	case vt.name == "Float":
+		t = "float64"
+	case vt.name == "Boolean":
+		t = "bool"
+	case vt.name == "String" || vt.name == "ID":
+		t = "string"
+	default:
+		return "json.RawMessage"
+	}
+	if !vt.nonNull {
+		t = "*" + t
+	}
+	return t
+}
+
+// goColType returns the Go type for a database column type
+func goColType(colType string, array bool) string {
+	ct := strings.ToLower(colType)
+	gt, list := getType(ct)
+
+	var t string
+	switch gt {
+	case "Int":
+		t = "int"
+		if strings.Contains(ct, "big") {
+			t = "int64"
+		}
+	case "Float":
+		t = "float64"
+	case "Boolean":
+		t = "bool"
+	case "JSON":
+		return "json.RawMessage"
+	default:
+		t = "string"
+	}
+	if array || list {
+		t = "[]" + t
+	}
+	return t
+}
+
+// goName returns the exported Go name for a GraphQL or column name
+func goName(s string) string {
+	var b strings.Builder
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	}) {
+		if strings.EqualFold(p, "id") {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	if b.Len() == 0 {
+		return "X"
+	}
+	return b.String()
+}
+
+// isBuiltinVar returns true for the variables set by GraphJin
+func isBuiltinVar(name string) bool {
+	switch name {
+	case "user_id", "userID", "userId",
+		"user_id_raw", "userIDRaw", "userIdRaw",
+		"user_id_provider", "userIDProvider", "userIdProvider",
+		"user_role", "userRole":
+		return true
+	}
+	return false
+}
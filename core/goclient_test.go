@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGenerateGoClient(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL, full_name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, user_id INTEGER REFERENCES users(id));
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := core.NewOsFS(dir)
+	queries := map[string]string{
+		"/queries/getUsers.gql": `query getUsers($limit: Int!, $email: String) {
+			users(limit: $limit, where: { email: { eq: $email } }) { id email full_name posts { id title } }
+		}`,
+		"/queries/getUser.gql": `query getUser { users(id: $id) { id email } }`,
+	}
+	for path, q := range queries {
+		if err := fs.Put(path, []byte(q)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &core.Config{DBType: "sqlite"}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mutations are saved with their variables on the first run
+	_, err = gj.GraphQL(context.Background(),
+		`mutation updatePost { posts(id: $id, update: $data) { id } }`,
+		json.RawMessage(`{"id": 1, "data": {"title": "a"}}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := gj.GenerateGoClient("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "client.go", src, 0); err != nil {
+		t.Fatalf("generated client does not parse: %s\n%s", err, src)
+	}
+
+	exp := []string{
+		"package client",
+		"func (c *Client) GetUsers(ctx context.Context, vars GetUsersVars) (GetUsersResult, error)",
+		"Limit int `json:\"limit\"`",
+		"Email *string `json:\"email,omitempty\"`",
+		"Users []GetUsersUsers `json:\"users\"`",
+		"FullName *string `json:\"full_name\"`",
+		"Posts []GetUsersUsersPosts `json:\"posts\"`",
+		"type GetUsersUsersPosts struct",
+		"Users *GetUserUsers `json:\"users\"`",
+		"ID *int `json:\"id,omitempty\"`",
+		"func (c *Client) UpdatePost(ctx context.Context, vars UpdatePostVars) (UpdatePostResult, error)",
+		"Data json.RawMessage `json:\"data,omitempty\"`",
+	}
+	// ignore the alignment of struct fields
+	out := strings.Join(strings.Fields(string(src)), " ")
+	for _, v := range exp {
+		if !strings.Contains(out, v) {
+			t.Errorf("expected %q in the generated client:\n%s", v, src)
+		}
+	}
+}
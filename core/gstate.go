@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,6 +27,8 @@ type gstate struct {
 	vmap  map[string]json.RawMessage
 	data  []byte
 	dhash [sha256.Size]byte
+	// qhash is the hash of the canonical query, see queryHash
+	qhash string
 	role  string
 	verrs []qcode.ValidErr
 	// database is the target database name for multi-database support.
@@ -34,21 +37,53 @@ type gstate struct {
 	// multiDB is true when the query spans multiple databases and requires
 	// parallel execution with result merging.
 	multiDB bool
-	// dbGroups maps database names to their root field names for multi-DB queries.
+	// dbGroups maps database names to their root field keys (alias or name)
+	// for multi-DB queries.
 	// Only populated when multiDB is true.
 	dbGroups map[string][]string
+	// shards are the databases a query without a shard key is run on
+	shards []string
+
+	// budget limits the sub-queries a request fans out into, nil when unset
+	budget *budget
+	// truncated is set when list fields were trimmed to fit max_response_bytes
+	truncated bool
+	// optTx is the transaction opened to run a mutation with @optional
+	// branches or on audited tables
+	optTx *sql.Tx
+	// rolledBack lists the @optional branches that were rolled back
+	rolledBack []string
+	// pending is the id of the mutation staged for approval
+	pending string
+	// calls counts the remote and database join requests per select
+	calls map[int32]int
+	// nplus1 lists the parts of the query that fan out per row (dev mode)
+	nplus1 []NPlusOneWarning
+	// omitted lists the fields left out by authorization rules (auth trace)
+	omitted []OmittedField
 
 	// Cache-related fields
-	cacheKey     string    // Cache key for this query
-	queryStarted time.Time // When query started (for race condition detection)
-	cacheHit     bool      // True if response was served from cache
-	skipCache    bool      // True if caching should be skipped for this query
+	cacheKey     string        // Cache key for this query
+	queryStarted time.Time     // When query started (for race condition detection)
+	cacheHit     bool          // True if response was served from cache
+	compileTime  time.Duration // Time spent compiling, only tracked with metrics
+	skipCache    bool          // True if caching should be skipped for this query
+	// tail is the cached list of an append-only table, only the rows added
+	// since are fetched, see tailcache.go
+	tail *tailEntry
+	// tailRun is set when the query was run with the tail statement
+	tailRun bool
 }
 
 type cstate struct {
 	sync.Once
 	st  stmt
 	err error
+
+	// tail is the statement that fetches the rows after the cached tail
+	tailOnce sync.Once
+	tail     *stmt
+	tailErr  error
 }
 
 type stmt struct {
@@ -63,21 +98,16 @@ func newGState(c context.Context, gj *graphjinEngine, r GraphqlReq) (s gstate, e
 	s.gj = gj
 	s.r = r
 
-	if v, ok := c.Value(UserRoleKey).(string); ok {
-		s.role = v
-	} else {
-		switch c.Value(UserIDKey).(type) {
-		case string, int:
-			s.role = "user"
-		default:
-			s.role = "anon"
-		}
+	s.role = contextRole(c)
+
+	if err = gj.checkClaimVars(c); err != nil {
+		return
 	}
 
 	// convert variable json to a go map also decrypted encrypted values
 	if len(r.vars) != 0 {
 		var vars json.RawMessage
-		vars, err = decryptValues(r.vars, decPrefix, s.gj.encryptionKey)
+		vars, err = decryptValues(r.vars, decPrefix, s.gj.decryptionKeys...)
 		if err != nil {
 			return
 		}
@@ -87,24 +117,52 @@ func newGState(c context.Context, gj *graphjinEngine, r GraphqlReq) (s gstate, e
 			return
 		}
 	}
+
+	// the database override applies to the whole request and is part of
+	// the key of the compiled query
+	if r.database != "" {
+		err = s.setDatabase(r.database)
+		return
+	}
+	err = s.routeShard()
 	return
 }
 
+// setDatabase runs the whole request against the named database
+func (s *gstate) setDatabase(name string) error {
+	if _, ok := s.gj.databases[name]; !ok {
+		return fmt.Errorf("database not found: %s", name)
+	}
+	dbCtx, ok := s.gj.GetDatabase(name)
+	if !ok {
+		return fmt.Errorf("database not ready: %s", name)
+	}
+	if dbCtx.qcodeCompiler == nil || dbCtx.psqlCompiler == nil {
+		return fmt.Errorf("database not ready: %s", name)
+	}
+	s.database = name
+	return nil
+}
+
 func (s *gstate) compile() (err error) {
-	if !s.gj.prodSec {
-		err = s.compileQueryForRole()
+	err = s.timeCompile(func() error {
+		if !s.gj.prodSec || s.r.dynamic {
+			return s.compileQueryForRole()
+		}
+		// In production mode and compile and cache the result
+		// In production mode the query is derived from the allow list
+		return s.compileQueryForRoleOnce()
+	})
+	if err != nil {
 		return
 	}
-
-	// In production mode and compile and cache the result
-	// In production mode the query is derived from the allow list
-	err = s.compileQueryForRoleOnce()
-	return
+	return s.checkNamespaceBlocklist(s.qcode())
 }
 
 func (s *gstate) compileQueryForRoleOnce() (err error) {
 	val, loaded := s.gj.queries.LoadOrStore(s.key(), &cstate{})
 	s.cs = val.(*cstate)
+	s.gj.cacheLookup(CacheQuery, loaded)
 
 	if !loaded {
 		s.cs.Do(func() {
@@ -132,6 +190,11 @@ func (s *gstate) compileQueryForRole() (err error) {
 		vars = s.vmap
 	}
 
+	// Database override: the whole request goes to the requested database
+	if s.database != "" {
+		return s.compileForDatabase(st, vars, s.gj.databases[s.database])
+	}
+
 	// Multi-DB mode: check if query spans multiple databases
 	if s.gj.isMultiDB() {
 		roots := s.extractAllRootFields()
@@ -187,12 +250,21 @@ func (s *gstate) compileWithCompilers(st stmt, vars map[string]json.RawMessage,
 		return
 	}
 
-	var w bytes.Buffer
-	if st.md, err = pc.Compile(&w, st.qc); err != nil {
-		return
-	}
+	p, fname, ok := s.gj.getPlan(s, dbName)
+	if ok {
+		st.md, st.sql = p.Meta, p.SQL
+	} else {
+		var w bytes.Buffer
+		if st.md, err = pc.Compile(&w, st.qc); err != nil {
+			return
+		}
+		st.sql = w.String()
 
-	st.sql = w.String()
+		if fname != "" {
+			p.SQL, p.Meta = st.sql, st.md
+			s.gj.savePlan(fname, p)
+		}
+	}
 	s.database = dbName
 
 	if s.cs == nil {
@@ -204,43 +276,109 @@ func (s *gstate) compileWithCompilers(st stmt, vars map[string]json.RawMessage,
 	return
 }
 
-// extractAllRootFields parses the GraphQL query to extract all root field names
+// rootField is a root field of a query, key is the alias or name its
+// result is returned under
+type rootField struct {
+	name     string
+	key      string
+	database string
+}
+
+// extractAllRootFields parses the GraphQL query to extract all root fields
 // without performing schema validation. Uses the graph parser for robust parsing.
-func (s *gstate) extractAllRootFields() []string {
+func (s *gstate) extractAllRootFields() []rootField {
 	op, err := graph.Parse(s.r.query)
 	if err != nil {
 		return nil
 	}
 
-	var roots []string
+	var roots []rootField
 	for _, f := range op.Fields {
 		// Root fields have ParentID == -1
 		if f.ParentID == -1 && f.Type != graph.FieldKeyword {
-			roots = append(roots, f.Name)
+			roots = append(roots, rootField{
+				name:     f.Name,
+				key:      fieldKey(f),
+				database: s.gj.fieldDatabase(f),
+			})
 		}
 	}
 	return roots
 }
 
-// groupRootsByDatabase maps root field names to their target databases.
-// Returns a map of database name to list of root field names.
+// rootKeys returns the keys of the root fields
+func rootKeys(roots []rootField) []string {
+	keys := make([]string, len(roots))
+	for i, r := range roots {
+		keys[i] = r.key
+	}
+	return keys
+}
+
+// fieldKey returns the key the result of a field is returned under
+func fieldKey(f graph.Field) string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// groupRootsByDatabase maps root fields to their target databases.
+// Returns a map of database name to list of root field keys, so aliases of
+// the same table can go to different databases.
 // After normalization, gj.defaultDB is always set and all tables have Database set.
-func (s *gstate) groupRootsByDatabase(roots []string) map[string][]string {
+func (s *gstate) groupRootsByDatabase(roots []rootField) map[string][]string {
 	byDB := make(map[string][]string)
 
 	for _, root := range roots {
-		db := s.gj.defaultDB
+		byDB[root.database] = append(byDB[root.database], root.key)
+	}
+	return byDB
+}
 
-		// Look up the table's database from config
-		for _, t := range s.gj.conf.Tables {
-			if t.Name == root && t.Database != "" {
-				db = t.Database
-				break
+// fieldDatabase returns the database a root field runs on, set with the
+// @database(name: ...) directive or else the database of its table
+func (gj *graphjinEngine) fieldDatabase(f graph.Field) string {
+	for _, d := range f.Directives {
+		if d.Name != "database" {
+			continue
+		}
+		for _, a := range d.Args {
+			if a.Name == "name" && a.Val != nil && a.Val.Val != "" {
+				return a.Val.Val
 			}
 		}
-		byDB[db] = append(byDB[db], root)
 	}
-	return byDB
+	return gj.rootDatabase(f.Name)
+}
+
+// rootDatabase returns the database a root field (table) belongs to
+func (gj *graphjinEngine) rootDatabase(root string) string {
+	// Look up the table's database from config
+	for _, t := range gj.tables() {
+		if t.Name == root && t.Database != "" {
+			return t.Database
+		}
+	}
+	return gj.defaultDB
+}
+
+// databaseNames returns the databases the request ran on
+func (s *gstate) databaseNames() string {
+	switch {
+	case len(s.shards) != 0:
+		return strings.Join(s.shards, ",")
+	case s.multiDB:
+		dbs := make([]string, 0, len(s.dbGroups))
+		for db := range s.dbGroups {
+			dbs = append(dbs, db)
+		}
+		sort.Strings(dbs)
+		return strings.Join(dbs, ",")
+	case s.database != "":
+		return s.database
+	}
+	return s.gj.defaultDB
 }
 
 // getTargetDBCtx returns the dbContext for the target database.
@@ -270,6 +408,8 @@ func (s *gstate) getTargetDB() *sql.DB {
 func (s *gstate) compileAndExecuteWrapper(c context.Context) (err error) {
 	// Record query start time for cache race condition detection
 	s.queryStarted = time.Now()
+	deadline, _ := c.Deadline()
+	s.budget = newBudget(s.queryBudget(), s.queryStarted, deadline)
 
 	// Try cache lookup for queries (before compilation)
 	if s.gj.responseCache != nil && s.r.operation == qcode.QTQuery {
@@ -278,9 +418,18 @@ func (s *gstate) compileAndExecuteWrapper(c context.Context) (err error) {
 		}
 	}
 
+	// Queries without a shard key run on every shard
+	if len(s.shards) != 0 {
+		if err = s.executeShards(c); err != nil {
+			return
+		}
+		err = s.checkResponseSize()
+		return
+	}
+
 	// Check for multi-database queries BEFORE compilation
 	// This is done by parsing root fields without schema validation
-	if s.gj.isMultiDB() {
+	if s.gj.isMultiDB() && s.database == "" {
 		roots := s.extractAllRootFields()
 		if len(roots) > 0 {
 			byDB := s.groupRootsByDatabase(roots)
@@ -291,6 +440,7 @@ func (s *gstate) compileAndExecuteWrapper(c context.Context) (err error) {
 				if err = s.executeParallelRoots(c); err != nil {
 					return
 				}
+				err = s.checkResponseSize()
 				return
 			}
 		}
@@ -318,13 +468,30 @@ func (s *gstate) compileAndExecuteWrapper(c context.Context) (err error) {
 		}
 	}
 
-	// Handle cross-database joins (in-process calls to other databases)
-	if s.gj.isMultiDB() && countDatabaseJoins(cs.st.qc) > 0 {
+	// Handle cross-database joins (in-process calls to other databases),
+	// joins with foreign tables can be run this way on a single database
+	if countDatabaseJoins(cs.st.qc) > 0 {
 		if err = s.execDatabaseJoins(c); err != nil {
 			return
 		}
 	}
 
+	if err = s.mergeTail(); err != nil {
+		return
+	}
+
+	s.checkNPlusOne()
+	s.checkOmitted()
+
+	if err = s.checkResponseSize(); err != nil {
+		return
+	}
+
+	// Partial results from going over the budget are returned but not cached
+	if err = s.budget.exceeded(); err != nil {
+		return
+	}
+
 	// Cache the response for queries, or invalidate cache for mutations
 	if s.gj.responseCache != nil {
 		if s.r.operation == qcode.QTQuery && !s.skipCache {
@@ -394,20 +561,30 @@ func (s *gstate) compileAndExecute(c context.Context) (err error) {
 	// set default variables
 	s.setDefaultVars()
 
-	var conn *sql.Conn
-
-	if s.tx() == nil {
-		// get a database connection from the target database
-		c1, span1 := s.gj.spanStart(c, "Get Connection")
-		defer span1.End()
+	// only fetch the rows added after the cached tail of the list
+	if s.tail != nil {
+		if err = s.useTailStmt(); err != nil {
+			return
+		}
+	}
 
-		db := s.getTargetDB()
-		err = retryOperation(c1, func() (err1 error) {
-			conn, err1 = db.Conn(c1)
+	// mutations on tables that need approval are staged instead of run,
+	// once approved they must still compile to the staged statement
+	if s.r.approved != "" {
+		if s.cs.st.sql != s.r.approved {
+			err = errors.New("approval: the mutation no longer compiles to the approved statement")
 			return
-		})
-		if err != nil {
-			span1.Error(err)
+		}
+	} else if s.needsApproval() {
+		err = s.stageMutation(c)
+		return
+	}
+
+	var conn *sql.Conn
+
+	// reads sent to the replicas do not hold a connection to the primary
+	if s.tx() == nil && s.readReplicas() == nil {
+		if conn, err = s.targetConn(c); err != nil {
 			return
 		}
 		defer conn.Close() //nolint:errcheck
@@ -427,8 +604,28 @@ func (s *gstate) compileAndExecute(c context.Context) (err error) {
 		}
 	}
 
+	// mutations with @optional branches run inside a savepoint
+	run := s.execute
+	if s.cs.st.qc.HasOptional() {
+		run = s.executeOptional
+	}
+
+	// mutations on audited tables are recorded in the same transaction
+	if ms := s.auditedMutates(); len(ms) != 0 {
+		run1 := run
+		run = func(c context.Context, conn *sql.Conn) error {
+			return s.executeAudited(c, conn, ms, run1)
+		}
+	}
+
+	// listed mutations run serializable and are retried on conflicts
+	if s.isSerializable() {
+		err = s.executeSerializable(c, conn, run)
+		return
+	}
+
 	// execute query
-	err = s.execute(c, conn)
+	err = run(c, conn)
 	return
 }
 
@@ -438,8 +635,26 @@ func (s *gstate) setDefaultVars() {
 	}
 
 	for _, v := range s.cs.st.qc.Vars {
-		s.vmap[v.Name] = v.Val
+		if v.Val != nil {
+			s.vmap[v.Name] = v.Val
+		}
+	}
+}
+
+// targetConn returns a connection from the target database
+func (s *gstate) targetConn(c context.Context) (conn *sql.Conn, err error) {
+	c1, span := s.gj.spanStart(c, "Get Connection")
+	defer span.End()
+
+	db := s.getTargetDB()
+	err = retryOperation(c1, func() (err1 error) {
+		conn, err1 = db.Conn(c1)
+		return
+	})
+	if err != nil {
+		span.Error(err)
 	}
+	return
 }
 
 func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
@@ -448,6 +663,10 @@ func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
 		return
 	}
 
+	if ok, err1 := s.tryBulkInsert(c, conn); ok || err1 != nil {
+		return err1
+	}
+
 	var args args
 	if args, err = s.argList(c); err != nil {
 		return
@@ -460,6 +679,14 @@ func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
 	dialect := s.getTargetPsqlCompiler().GetDialect()
 	parts := dialect.SplitQuery(cs.st.sql)
 
+	// scripts are not sent to the replicas
+	if len(parts) > 1 && conn == nil && s.tx() == nil {
+		if conn, err = s.targetConn(c); err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck
+	}
+
 	if len(parts) > 1 {
 		// Multi-statement script execution
 		c1, span := s.gj.spanStart(c, "Execute Script")
@@ -532,11 +759,17 @@ func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
 
 						// Parse ID from JSON
 						var rowMap map[string]interface{}
-						if err = json.Unmarshal(b, &rowMap); err != nil {
+						d := json.NewDecoder(bytes.NewReader(b))
+						d.UseNumber()
+						if err = d.Decode(&rowMap); err != nil {
 							return err
 						}
 
-						if idVal, ok := rowMap["id"]; ok {
+						switch idVal := rowMap["id"].(type) {
+						case nil:
+						case string:
+							ids = append(ids, "'"+strings.ReplaceAll(idVal, "'", "''")+"'")
+						default:
 							ids = append(ids, fmt.Sprintf("%v", idVal))
 						}
 					}
@@ -620,11 +853,18 @@ func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
 		span.Error(err)
 		return err
 	}
+	querySQL = s.limitResponseSQL(s.getTargetDBCtx().dbtype, querySQL)
 
 	var row *sql.Row
 	if tx := s.tx(); tx != nil {
 		row = tx.QueryRowContext(c1, querySQL, queryArgs...)
 		err = row.Scan(&s.data)
+	} else if rs := s.readReplicas(); rs != nil {
+		delay := s.gj.conf.Databases[s.getTargetDBCtx().name].HedgeDelay
+		err = retryOperationForDB(c1, dbType, func() (err1 error) {
+			s.data, err1 = rs.read(c1, delay, querySQL, queryArgs)
+			return
+		})
 	} else {
 		err = retryOperationForDB(c1, dbType, func() (err1 error) {
 			row = conn.QueryRowContext(c1, querySQL, queryArgs...)
@@ -656,6 +896,9 @@ func (s *gstate) execute(c context.Context, conn *sql.Conn) (err error) {
 	if err != nil {
 		return
 	}
+	if err = s.checkLimitedResponse(); err != nil {
+		return
+	}
 
 	s.dhash = sha256.Sum256(s.data)
 
@@ -741,6 +984,10 @@ func (s *gstate) validateAndUpdateVars(c context.Context) (err error) {
 		return nil
 	}
 
+	if err = s.checkVarTypes(); err != nil {
+		return
+	}
+
 	if len(qc.Consts) != 0 {
 		s.verrs = qc.ProcessConstraints(s.vmap)
 		if len(s.verrs) != 0 {
@@ -748,9 +995,77 @@ func (s *gstate) validateAndUpdateVars(c context.Context) (err error) {
 			return
 		}
 	}
+
+	if err = s.checkLimitVars(); err != nil {
+		return
+	}
+
+	if err = s.checkOrderByVars(); err != nil {
+		return
+	}
+
+	err = s.generateIDs()
 	return
 }
 
+// checkLimitVars clamps limit variables to the max limit of the table or
+// returns an error when max_limit_error is enabled
+func (s *gstate) checkLimitVars() error {
+	for _, sel := range s.cs.st.qc.Selects {
+		p := sel.Paging
+		if p.LimitVar == "" || p.MaxLimit == 0 {
+			continue
+		}
+
+		v, ok := s.vmap[p.LimitVar]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(string(v), 10, 32)
+		if err != nil || n <= int64(p.MaxLimit) {
+			continue
+		}
+
+		if s.gj.conf.MaxLimitError {
+			return fmt.Errorf("%s: limit %d is over the max limit of %d",
+				sel.FieldName, n, p.MaxLimit)
+		}
+		s.vmap[p.LimitVar] = json.RawMessage(strconv.Itoa(int(p.MaxLimit)))
+	}
+	return nil
+}
+
+// checkOrderByVars fails when the value of an order_by variable is not one
+// of the keys it was compiled with
+func (s *gstate) checkOrderByVars() error {
+	for _, sel := range s.cs.st.qc.Selects {
+		var name string
+		keys := make(map[string]struct{})
+		for _, ob := range sel.OrderBy {
+			if ob.KeyVar != "" {
+				name = ob.KeyVar
+				keys[ob.Key] = struct{}{}
+			}
+		}
+		if name == "" {
+			continue
+		}
+
+		v, ok := s.vmap[name]
+		if !ok || string(v) == "null" {
+			continue
+		}
+		var key string
+		if err := json.Unmarshal(v, &key); err != nil {
+			return fmt.Errorf("variable '%s': expecting an order_by key", name)
+		}
+		if _, ok := keys[key]; !ok {
+			return fmt.Errorf("variable '%s': invalid order_by key '%s' for %s", name, key, sel.FieldName)
+		}
+	}
+	return nil
+}
+
 func (s *gstate) sql() (sql string) {
 	if s.cs != nil && s.cs.st.qc != nil {
 		sql = s.cs.st.sql
@@ -773,6 +1088,9 @@ func (s *gstate) qcode() (qc *qcode.QCode) {
 }
 
 func (s *gstate) tx() (tx *sql.Tx) {
+	if s.optTx != nil {
+		return s.optTx
+	}
 	if s.r.requestconfig != nil {
 		tx = s.r.requestconfig.Tx
 	}
@@ -789,13 +1107,53 @@ func (s *gstate) key() (key string) {
 			dbs = append(dbs, db)
 		}
 		sort.Strings(dbs)
-		key = s.r.namespace + s.r.name + s.role + strings.Join(dbs, ",")
+		key = s.r.namespace + s.queryHash() + s.role + strings.Join(dbs, ",")
 	} else {
-		key = s.r.namespace + s.r.name + s.role + s.database
+		key = s.r.namespace + s.queryHash() + s.role + s.database
 	}
 	return
 }
 
+// queryHash identifies the query by the hash of its canonical form so
+// queries that only differ in formatting, fragments or the order of variable
+// definitions share the compiled statement and cached responses. The saved
+// variables of allow listed queries are included since they can change how
+// mutations compile.
+func (s *gstate) queryHash() string {
+	if s.qhash != "" {
+		return s.qhash
+	}
+
+	// allow listed queries are identified by namespace and name since
+	// the saved variables are per query
+	mk := s.r.namespace + "\x00" + s.r.name + "\x00" + string(s.r.query)
+	if s.gj.prodSec {
+		if v, ok := s.gj.canonical.Load(mk); ok {
+			s.qhash = v.(string)
+			return s.qhash
+		}
+	}
+
+	h := sha256.New()
+	if cq, err := graph.Canonical(s.r.query); err == nil {
+		h.Write(cq)
+	} else {
+		h.Write(s.r.query)
+	}
+	if len(s.r.aschema) != 0 {
+		if b, err := json.Marshal(s.r.aschema); err == nil {
+			h.Write(b)
+		}
+	}
+	s.qhash = hex.EncodeToString(h.Sum(nil))
+
+	// in production queries come from the allow list so the map stays small
+	if s.gj.prodSec {
+		s.gj.canonical.Store(mk, s.qhash)
+	}
+	return s.qhash
+}
+
 // tryCacheGet attempts to retrieve the response from cache.
 // Returns true if cache hit (s.data is populated), false otherwise.
 func (s *gstate) tryCacheGet(c context.Context) bool {
@@ -804,7 +1162,7 @@ func (s *gstate) tryCacheGet(c context.Context) bool {
 	}
 
 	// Build cache key
-	s.cacheKey = s.gj.cacheKeyBuilder.Build(c, s.r.name, s.getAPQKey(), s.r.query, s.r.vars, s.role)
+	s.cacheKey = s.gj.cacheKeyBuilder.Build(c, s.r.name, s.getAPQKey(), []byte(s.queryHash()), s.r.vars, s.role)
 
 	// Skip if anonymous query (no operation name or APQ key)
 	if s.cacheKey == "" || !s.gj.cacheKeyBuilder.ShouldCache(s.r.name, s.getAPQKey()) {
@@ -812,8 +1170,14 @@ func (s *gstate) tryCacheGet(c context.Context) bool {
 		return false
 	}
 
+	// Lists of append-only tables are cached with their tail
+	if s.gj.hasTailCache() && len(s.shards) == 0 && s.tailCacheGet(c) {
+		return false
+	}
+
 	// Try to get from cache
 	data, isStale, found := s.gj.responseCache.Get(c, s.cacheKey)
+	s.gj.cacheLookup(CacheResponse, found)
 	if !found {
 		return false
 	}
@@ -858,7 +1222,16 @@ func (s *gstate) tryCacheSet(c context.Context) {
 		return
 	}
 
+	// Aggregates are indexed under the scopes of their tables
+	refs = append(refs, s.aggregateRefs(c, qc)...)
+
+	if tl, ok := s.tailList(qc); ok {
+		s.tailCacheSet(withCacheHints(c, qc), tl, cleaned, refs)
+		return
+	}
+
 	// Store in cache
+	c = withCacheHints(c, qc)
 	_ = s.gj.responseCache.Set(c, s.cacheKey, cleaned, refs, s.queryStarted)
 }
 
@@ -875,6 +1248,7 @@ func (s *gstate) invalidateCache(c context.Context) {
 
 	// Extract affected row IDs from mutation response
 	refs := ExtractMutationRefs(cs.st.qc, s.data)
+	refs = append(refs, s.gj.mutationAggregateRefs(cs.st.qc, s.data)...)
 	if len(refs) > 0 {
 		_ = s.gj.responseCache.InvalidateRows(c, refs)
 	}
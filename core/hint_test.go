@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestHintDirective(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE INDEX users_name_idx ON users (name);
+		INSERT INTO users (id, name) VALUES (1, 'Jane');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Roles: []core.Role{
+			{Name: "anon", AllowHints: true},
+			{Name: "user"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := `query { users(where: { name: { eq: "Jane" } }) @hint(value: "INDEXED BY users_name_idx") { id name } }`
+
+	list, err := gj.CompileOnly(query, nil, "anon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Error != "" ||
+		!strings.Contains(list[0].Statement, "INDEXED BY users_name_idx") {
+		t.Fatalf("expected the hint in the statement, got %+v", list)
+	}
+
+	res, err := gj.GraphQL(t.Context(), query, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"users":[{"id":1,"name":"Jane"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	list, err = gj.CompileOnly(query, nil, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !strings.Contains(list[0].Error, "not allowed for role") {
+		t.Fatalf("expected the hint to be rejected for the role, got %+v", list)
+	}
+
+	list, err = gj.CompileOnly(`query { users @hint(value: "idx */ DROP TABLE users; /*") { id } }`, nil, "anon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !strings.Contains(list[0].Error, "invalid hint") {
+		t.Fatalf("expected an invalid hint error, got %+v", list)
+	}
+}
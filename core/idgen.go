@@ -0,0 +1,206 @@
+package core
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+func isIDGenerator(name string) bool {
+	switch name {
+	case "uuidv7", "ulid", "snowflake":
+		return true
+	}
+	return false
+}
+
+// generateIDs fills in the primary key of rows inserted into tables with an
+// id_generator, the keys are then part of the insert and are returned with
+// the mutation result like any other column
+func (s *gstate) generateIDs() error {
+	qc := s.cs.st.qc
+	if qc == nil || qc.Type != qcode.QTMutation {
+		return nil
+	}
+
+	var data any
+	var dataChanged bool
+
+	for _, m := range qc.Mutates {
+		if m.IDGen == "" {
+			continue
+		}
+
+		if m.IDVar != "" {
+			v, err := newID(m.IDGen)
+			if err != nil {
+				return err
+			}
+			if s.vmap == nil {
+				s.vmap = make(map[string]json.RawMessage)
+			}
+			s.vmap[m.IDVar] = v
+			continue
+		}
+
+		if data == nil {
+			d := json.NewDecoder(bytes.NewReader(s.vmap[qc.ActionVar]))
+			d.UseNumber()
+			if err := d.Decode(&data); err != nil {
+				return fmt.Errorf("id generator: %w", err)
+			}
+		}
+
+		if err := fillIDs(data, m.Path, m.Ti.PrimaryCol.Name, m.IDGen); err != nil {
+			return err
+		}
+		dataChanged = true
+	}
+
+	if dataChanged {
+		v, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		s.vmap[qc.ActionVar] = v
+	}
+	return nil
+}
+
+// fillIDs walks the path into the data and sets the key on every object
+// found there that does not already have it
+func fillIDs(data any, path []string, key, gen string) error {
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			if err := fillIDs(item, path, key, gen); err != nil {
+				return err
+			}
+		}
+
+	case map[string]any:
+		if len(path) != 0 {
+			return fillIDs(v[path[0]], path[1:], key, gen)
+		}
+		if id, ok := v[key]; ok && id != nil {
+			return nil
+		}
+		id, err := newID(gen)
+		if err != nil {
+			return err
+		}
+		v[key] = id
+	}
+	return nil
+}
+
+func newID(gen string) (json.RawMessage, error) {
+	switch gen {
+	case "uuidv7":
+		id, err := newUUIDv7()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(id)
+
+	case "ulid":
+		id, err := newULID()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(id)
+
+	case "snowflake":
+		return json.RawMessage(strconv.FormatInt(snowflake.next(), 10)), nil
+	}
+	return nil, fmt.Errorf("invalid id_generator: %s", gen)
+}
+
+// newUUIDv7 returns a time ordered uuid (RFC 9562)
+func newUUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	ms := uint64(time.Now().UnixMilli())
+	b[0], b[1], b[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	b[3], b[4], b[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	b[6] = (b[6] & 0x0f) | 0x70
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a lexically sortable id, a 48 bit timestamp followed by
+// 80 random bits in crockford base32
+func newULID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	ms := uint64(time.Now().UnixMilli())
+	b[0], b[1], b[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	b[3], b[4], b[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = (lo >> 5) | (hi << 59)
+		hi >>= 5
+	}
+	return string(out[:]), nil
+}
+
+// snowflake ids are a 41 bit millisecond timestamp, a 10 bit node id
+// picked at random for the process and a 12 bit sequence
+var snowflake = newSnowflakeGen()
+
+const snowflakeEpoch = 1288834974657
+
+type snowflakeGen struct {
+	sync.Mutex
+	node int64
+	ms   int64
+	seq  int64
+}
+
+func newSnowflakeGen() *snowflakeGen {
+	var b [2]byte
+	_, _ = rand.Read(b[:])
+	return &snowflakeGen{node: int64(binary.BigEndian.Uint16(b[:]) & 0x3ff)}
+}
+
+func (g *snowflakeGen) next() int64 {
+	g.Lock()
+	defer g.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms < g.ms {
+		ms = g.ms
+	}
+	if ms == g.ms {
+		g.seq = (g.seq + 1) & 0xfff
+		if g.seq == 0 {
+			for ms <= g.ms {
+				ms = time.Now().UnixMilli()
+			}
+		}
+	} else {
+		g.seq = 0
+	}
+	g.ms = ms
+
+	return ((ms - snowflakeEpoch) << 22) | (g.node << 12) | g.seq
+}
@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newIDGenTestDB(t *testing.T) (*sql.DB, *core.GraphJin) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE notes (id TEXT PRIMARY KEY, body TEXT);
+		CREATE TABLE tickets (id TEXT PRIMARY KEY, body TEXT);
+		CREATE TABLE events (id INTEGER PRIMARY KEY, body TEXT);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Tables: []core.Table{
+			{Name: "notes", IDGenerator: "uuidv7"},
+			{Name: "tickets", IDGenerator: "ulid"},
+			{Name: "events", IDGenerator: "snowflake"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, gj
+}
+
+func TestIDGenerator(t *testing.T) {
+	db, gj := newIDGenTestDB(t)
+
+	uuidv7 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulid := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+	gql := `mutation { notes(insert: $data) { id body } }`
+	vars := `{ "data": [{ "body": "one" }, { "body": "two" }, { "id": "mine", "body": "three" }] }`
+	res, err := gj.GraphQL(context.Background(), gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var notes struct {
+		Notes []struct{ ID, Body string }
+	}
+	if err := json.Unmarshal(res.Data, &notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes.Notes) != 3 {
+		t.Fatalf("expected 3 notes: %s", res.Data)
+	}
+	for _, n := range notes.Notes {
+		if n.Body == "three" {
+			if n.ID != "mine" {
+				t.Fatalf("expected the given id to be kept: %s", res.Data)
+			}
+			continue
+		}
+		if !uuidv7.MatchString(n.ID) {
+			t.Fatalf("expected a uuidv7 id: %s", res.Data)
+		}
+	}
+
+	gql = `mutation { tickets(insert: { body: "help" }) { id body } }`
+	res, err = gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tickets struct {
+		Tickets []struct{ ID string }
+	}
+	if err := json.Unmarshal(res.Data, &tickets); err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets.Tickets) != 1 || !ulid.MatchString(tickets.Tickets[0].ID) {
+		t.Fatalf("expected a ulid id: %s", res.Data)
+	}
+
+	gql = `mutation { events(insert: $data) { id } }`
+	vars = `{ "data": { "body": "started" } }`
+	if _, err = gj.GraphQL(context.Background(), gql, []byte(vars), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var id int64
+	if err := db.QueryRow(`SELECT id FROM events`).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	if id < 1<<22 {
+		t.Fatalf("expected a snowflake id, got %d", id)
+	}
+}
+
+func TestIDGeneratorInvalid(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	if _, err = db.Exec(`CREATE TABLE notes (id TEXT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType: "sqlite",
+		Tables: []core.Table{{Name: "notes", IDGenerator: "uuidv4"}},
+	}
+	if _, err = core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(t.TempDir()))); err == nil {
+		t.Fatal("expected an error for an invalid id_generator")
+	}
+}
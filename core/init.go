@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -22,6 +23,20 @@ func (gj *graphjinEngine) initConfig() error {
 	// Normalize databases so the primary DB is always an entry in Databases
 	c.NormalizeDatabases()
 
+	// Declared relationships become foreign key and polymorphic table configs
+	if err := expandRelationships(c); err != nil {
+		return err
+	}
+
+	// Roles with extends inherit the config of their parent roles
+	if err := expandRoleInheritance(c); err != nil {
+		return err
+	}
+
+	if err := validateClaimVars(c); err != nil {
+		return err
+	}
+
 	tableMap := make(map[string]struct{})
 
 	for _, table := range c.Tables {
@@ -44,7 +59,8 @@ func (gj *graphjinEngine) initConfig() error {
 
 	gj.roles = make(map[string]*Role)
 
-	for i, role := range c.Roles {
+	for i := range c.Roles {
+		role := &c.Roles[i]
 		k := role.Name
 		if _, ok := gj.roles[(role.Name)]; ok {
 			return fmt.Errorf("duplicate role found: %s", role.Name)
@@ -57,7 +73,7 @@ func (gj *graphjinEngine) initConfig() error {
 			role.tm[t.Schema+t.Name] = &role.Tables[n]
 		}
 
-		gj.roles[k] = &c.Roles[i]
+		gj.roles[k] = role
 	}
 
 	// If user role not defined then create it
@@ -105,13 +121,35 @@ func (gj *graphjinEngine) addTableInfo(t Table) error {
 			obm[k] = append(obm[k], [2]string{vals[0], vals[1]})
 		}
 	}
+	if t.IDGenerator != "" && !isIDGenerator(t.IDGenerator) {
+		return fmt.Errorf("table %s: invalid id_generator: %s", t.Name, t.IDGenerator)
+	}
+
 	if gj.tmap == nil {
 		gj.tmap = make(map[string]qcode.TConfig)
 	}
-	gj.tmap[(t.Schema + t.Name)] = qcode.TConfig{OrderBy: obm}
+	gj.tmap[(t.Schema + t.Name)] = qcode.TConfig{
+		OrderBy:      obm,
+		IDGenerator:  t.IDGenerator,
+		DefaultLimit: t.DefaultLimit,
+		MaxLimit:     t.MaxLimit,
+	}
 	return nil
 }
 
+// orderPresets returns the sorted names of the order_by presets keyed by
+// schema and table
+func (gj *graphjinEngine) orderPresets() map[string][]string {
+	m := make(map[string][]string)
+	for k, tc := range gj.tableMap() {
+		for name := range tc.OrderBy {
+			m[k] = append(m[k], name)
+		}
+		sort.Strings(m[k])
+	}
+	return m
+}
+
 // getDBTableAliases returns a map of table aliases
 func getDBTableAliases(c *Config) map[string][]string {
 	m := make(map[string][]string, len(c.Tables))
@@ -170,16 +208,38 @@ func updateTable(conf *Config, dbInfo *sdata.DBInfo, table Table) error {
 	}
 
 	t1, err := dbInfo.GetTable(schema, table.Name)
+	if err != nil && dbInfo.Type == "mongodb" {
+		// collections without any sampled documents are not discovered
+		dbInfo.AddTable(sdata.NewDBTable(schema, table.Name, "", []sdata.DBColumn{
+			{ID: -1, Name: "_id", Type: "text", PrimaryKey: true, UniqueKey: true, NotNull: true},
+			{ID: -1, Name: "id", Type: "text", PrimaryKey: true, UniqueKey: true, NotNull: true},
+		}))
+		t1, err = dbInfo.GetTable(schema, table.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("table: %w", err)
 	}
 
 	for _, c := range table.Columns {
 		c1, err := dbInfo.GetColumn(schema, table.Name, c.Name)
+		if err != nil && dbInfo.Type == "mongodb" {
+			// the field may be missing from the sampled documents of the collection
+			c1, err = dbInfo.AddColumn(sdata.DBColumn{ID: -1, Schema: schema, Table: table.Name, Name: c.Name, Type: "text"})
+		}
 		if err != nil {
 			return err
 		}
 
+		// field types of schemaless databases are inferred from sampled
+		// documents, a type set in the config takes precedence
+		if c.Type != "" && dbInfo.Type == "mongodb" {
+			c1.Type = c.Type
+		}
+
+		if c.AsString {
+			c1.AsString = true
+		}
+
 		if c.Primary {
 			c1.PrimaryKey = true
 			t1.PrimaryCols = append(t1.PrimaryCols, *c1)
@@ -221,12 +281,18 @@ func addJsonTable(conf *Config, dbInfo *sdata.DBInfo, table Table) error {
 	}
 
 	// Allow json, jsonb, clob, and text types
-	// MariaDB stores JSON as longtext, so we need to accept text types as well
+	// MariaDB stores JSON as longtext and MSSQL as nvarchar, so we need to
+	// accept text types as well
 	validJSONTypes := map[string]bool{
 		"json": true, "jsonb": true, "clob": true,
 		"longtext": true, "text": true, "mediumtext": true,
+		"nvarchar": true, "varchar": true, "ntext": true,
 	}
-	if !validJSONTypes[bc.Type] {
+	colType := strings.ToLower(bc.Type)
+	if i := strings.IndexByte(colType, '('); i != -1 {
+		colType = colType[:i]
+	}
+	if !validJSONTypes[colType] {
 		return fmt.Errorf(
 			"json table: column '%s' in table '%s' is of type '%s'. Only JSON, JSONB, CLOB, or TEXT types are valid",
 			table.Name, table.Table, bc.Type)
@@ -258,7 +324,14 @@ func addJsonTable(conf *Config, dbInfo *sdata.DBInfo, table Table) error {
 		Type:       bc.Type,
 	}
 
-	nt := sdata.NewDBTable(bc.Schema, table.Name, bc.Type, columns)
+	// the table type marks it as an embedded json table, jsonb is kept
+	// since postgres uses it to pick the recordset function
+	tableType := "json"
+	if colType == "jsonb" {
+		tableType = "jsonb"
+	}
+
+	nt := sdata.NewDBTable(bc.Schema, table.Name, tableType, columns)
 	nt.PrimaryCol = col1
 	nt.SecondaryCol = bt.PrimaryCol
 
@@ -292,6 +365,85 @@ func addVirtualTable(conf *Config, di *sdata.DBInfo, t Table) error {
 	return nil
 }
 
+// expandRelationships converts the relationships declared on tables into
+// column foreign keys and polymorphic tables
+func expandRelationships(c *Config) error {
+	n := len(c.Tables)
+	for i := 0; i < n; i++ {
+		t := c.Tables[i]
+
+		for _, r := range t.Relationships {
+			switch r.Type {
+			case "", "one_to_many":
+				if r.Column == "" || r.RelatedTo == "" {
+					return fmt.Errorf("table '%s': relationship requires 'column' and 'related_to'", t.Name)
+				}
+				if err := addRelColumn(&c.Tables[i], Column{Name: r.Column, ForeignKey: r.RelatedTo}); err != nil {
+					return err
+				}
+
+			case "many_to_many":
+				if r.Column == "" || r.RelatedTo == "" || r.Through == "" || len(r.ThroughColumns) != 2 {
+					return fmt.Errorf("table '%s': many_to_many relationship requires 'column', 'related_to', 'through' and two 'through_columns'", t.Name)
+				}
+				jt := findTableConfig(c, t.Database, t.Schema, r.Through)
+				if jt == nil {
+					c.Tables = append(c.Tables, Table{Name: r.Through, Schema: t.Schema, Database: t.Database})
+					jt = &c.Tables[len(c.Tables)-1]
+				}
+				if err := addRelColumn(jt, Column{Name: r.ThroughColumns[0], ForeignKey: t.Name + "." + r.Column}); err != nil {
+					return err
+				}
+				if err := addRelColumn(jt, Column{Name: r.ThroughColumns[1], ForeignKey: r.RelatedTo}); err != nil {
+					return err
+				}
+
+			case "polymorphic":
+				if r.Name == "" || r.Column == "" || r.RelatedTo == "" {
+					return fmt.Errorf("table '%s': polymorphic relationship requires 'name', 'column' and 'related_to'", t.Name)
+				}
+				c.Tables = append(c.Tables, Table{
+					Name:     r.Name,
+					Type:     "polymorphic",
+					Database: t.Database,
+					Columns:  []Column{{Name: r.Column, ForeignKey: r.RelatedTo}},
+				})
+
+			default:
+				return fmt.Errorf("table '%s': unknown relationship type '%s'", t.Name, r.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// findTableConfig returns the config of a table in a database
+func findTableConfig(c *Config, database, schema, name string) *Table {
+	for i, t := range c.Tables {
+		if t.Database == database && t.Schema == schema && t.Name == name {
+			return &c.Tables[i]
+		}
+	}
+	return nil
+}
+
+// addRelColumn adds a foreign key column to a table config
+func addRelColumn(t *Table, col Column) error {
+	for i, c := range t.Columns {
+		if c.Name != col.Name {
+			continue
+		}
+		if c.ForeignKey != "" && c.ForeignKey != col.ForeignKey {
+			return fmt.Errorf("table '%s': column '%s' is already related to '%s'",
+				t.Name, c.Name, c.ForeignKey)
+		}
+		t.Columns[i].ForeignKey = col.ForeignKey
+		return nil
+	}
+	t.Columns = append(t.Columns, col)
+	return nil
+}
+
 // addForeignKeys adds foreign keys to the database info.
 // targetDB is the database name to process (after normalization, all tables have Database set).
 // allDBInfos provides access to other databases' metadata for cross-database FK resolution.
@@ -326,6 +478,10 @@ func addForeignKey(conf *Config, di *sdata.DBInfo, c Column, t Table, allDBInfos
 		schema = di.Schema
 	}
 	c1, err := di.GetColumn(schema, t.Name, c.Name)
+	if err != nil && di.Type == "mongodb" {
+		// the field may be missing from the sampled documents of the collection
+		c1, err = di.AddColumn(sdata.DBColumn{ID: -1, Schema: schema, Table: t.Name, Name: c.Name})
+	}
 	if err != nil {
 		return fmt.Errorf("config: add foreign key: %w", err)
 	}
@@ -386,6 +542,9 @@ func addForeignKey(conf *Config, di *sdata.DBInfo, c Column, t Table, allDBInfos
 	c1.FKeySchema = fk.Schema
 	c1.FKeyTable = fk.Table
 	c1.FKeyCol = c3.Name
+	if c1.Type == "" {
+		c1.Type = c3.Type
+	}
 
 	// Check if this is a recursive FK (same table pointing to itself)
 	if fk.Schema == schema && fk.Table == t.Name {
@@ -442,11 +601,102 @@ func addFunctions(conf *Config, di *sdata.DBInfo) error {
 	return nil
 }
 
+// getCalls returns the functions exposed as mutation roots that are found
+// in the database schema
+func getCalls(conf *Config, schema *sdata.DBSchema) (map[string]qcode.CallConfig, error) {
+	calls := make(map[string]qcode.CallConfig)
+
+	for _, f := range conf.Functions {
+		if !f.Mutation {
+			continue
+		}
+		// functions returning records are added to the schema as tables
+		fn, ok := schema.GetFunctions()[f.Name]
+		if !ok {
+			t, err := schema.Find(f.Schema, f.Name)
+			if err != nil || t.Type != "function" {
+				continue
+			}
+			fn = t.Func
+		}
+		if f.Schema != "" && fn.Schema != f.Schema {
+			continue
+		}
+
+		cc := qcode.CallConfig{Result: f.Result, Roles: f.Roles}
+		record := fn.Type == "record" && len(fn.Outputs) != 0
+
+		switch cc.Result {
+		case "":
+			cc.Result = qcode.CallResultScalar
+			if record {
+				cc.Result = qcode.CallResultTable
+			}
+		case qcode.CallResultScalar:
+		case qcode.CallResultRow, qcode.CallResultTable:
+			if !record {
+				return nil, fmt.Errorf("function %s: result '%s' needs a function returning records",
+					f.Name, cc.Result)
+			}
+		default:
+			return nil, fmt.Errorf("function %s: invalid result '%s', expecting scalar, row or table",
+				f.Name, cc.Result)
+		}
+		calls[f.Name] = cc
+	}
+	return calls, nil
+}
+
+// logAccessReport logs what each role can access when default deny is
+// enabled, one line per role and table
+func (gj *graphjinEngine) logAccessReport() {
+	names := make([]string, 0, len(gj.roles))
+	for k := range gj.roles {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		audit, err := gj.auditRolePermissions(name)
+		if err != nil || len(audit.Tables) == 0 {
+			gj.log.Printf("INF access: %s: no tables", name)
+			continue
+		}
+
+		for _, tp := range audit.Tables {
+			var ops []string
+			for _, op := range []struct {
+				name string
+				perm *OperationPermission
+			}{
+				{"query", tp.Query},
+				{"insert", tp.Insert},
+				{"update", tp.Update},
+				{"upsert", tp.Upsert},
+				{"delete", tp.Delete},
+			} {
+				if !op.perm.Allowed {
+					continue
+				}
+				if len(op.perm.Columns) != 0 {
+					ops = append(ops, fmt.Sprintf("%s (%s)", op.name, strings.Join(op.perm.Columns, ", ")))
+				} else {
+					ops = append(ops, op.name)
+				}
+			}
+			if len(ops) == 0 {
+				ops = append(ops, "none")
+			}
+			gj.log.Printf("INF access: %s: %s: %s", name, tp.TableName, strings.Join(ops, ", "))
+		}
+	}
+}
+
 // addRoles adds roles to the compiler
 func addRoles(c *Config, qc *qcode.Compiler) error {
 	for _, r := range c.Roles {
 		for _, t := range r.Tables {
-			if err := addRole(qc, r, t, c.DefaultBlock); err != nil {
+			if err := addRole(qc, r, t, c.DefaultBlock, c.DefaultDeny); err != nil {
 				return err
 			}
 		}
@@ -455,15 +705,42 @@ func addRoles(c *Config, qc *qcode.Compiler) error {
 	return nil
 }
 
+// roleBlockOperators returns the operators blocked on every table per role
+func roleBlockOperators(c *Config) map[string][]string {
+	var m map[string][]string
+	for _, r := range c.Roles {
+		if len(r.BlockOperators) == 0 {
+			continue
+		}
+		if m == nil {
+			m = make(map[string][]string)
+		}
+		m[r.Name] = r.BlockOperators
+	}
+	return m
+}
+
+// hintRoles returns the roles allowed to use the @hint directive
+func hintRoles(c *Config) []string {
+	var roles []string
+	for _, r := range c.Roles {
+		if r.AllowHints {
+			roles = append(roles, r.Name)
+		}
+	}
+	return roles
+}
+
 // addRole adds a role to the compiler
-func addRole(qc *qcode.Compiler, r Role, t RoleTable, defaultBlock bool) error {
-	ro := defaultBlock && r.Name == "anon"
+func addRole(qc *qcode.Compiler, r Role, t RoleTable, defaultBlock, defaultDeny bool) error {
+	ro := (defaultBlock && r.Name == "anon") || defaultDeny
 
 	if t.ReadOnly {
 		ro = true
 	}
 
-	query := qcode.QueryConfig{Block: false}
+	// with default deny an operation is only allowed if it is configured
+	query := qcode.QueryConfig{Block: defaultDeny}
 	insert := qcode.InsertConfig{Block: ro}
 	update := qcode.UpdateConfig{Block: ro}
 	upsert := qcode.UpsertConfig{Block: ro}
@@ -472,9 +749,12 @@ func addRole(qc *qcode.Compiler, r Role, t RoleTable, defaultBlock bool) error {
 	if t.Query != nil {
 		query = qcode.QueryConfig{
 			Limit:            t.Query.Limit,
+			MaxLimit:         t.Query.MaxLimit,
 			Filters:          t.Query.Filters,
 			Columns:          t.Query.Columns,
 			DisableFunctions: t.Query.DisableFunctions,
+			AllowLock:        t.Query.AllowLock,
+			BlockOperators:   t.Query.BlockOperators,
 			Block:            t.Query.Block,
 		}
 	}
@@ -524,6 +804,9 @@ func addRole(qc *qcode.Compiler, r Role, t RoleTable, defaultBlock bool) error {
 
 // GetTable returns a table from the role
 func (r *Role) GetTable(schema, name string) *RoleTable {
+	if t, ok := r.tm[schema+name]; ok {
+		return t
+	}
 	return r.tm[name]
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dosco/graphjin/core/v3/internal/psql"
@@ -14,10 +15,39 @@ import (
 )
 
 // discoverAllDatabases runs Phase 1: schema discovery for all databases.
-// This populates ctx.dbinfo for each database context.
+// This populates ctx.dbinfo for each database context. The databases are
+// discovered in parallel, lazy databases are skipped and discovered on
+// first use.
 func (gj *graphjinEngine) discoverAllDatabases() error {
-	for _, ctx := range gj.databases {
-		if err := gj.discoverDatabase(ctx); err != nil {
+	names := gj.sortedDatabaseNames()
+	errs := make([]error, len(names))
+	targets := fkTargetDatabases(gj.conf)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		ctx := gj.databases[name]
+		if gj.isLazyDatabase(ctx, targets) {
+			ctx.lazy.Store(true)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = gj.discoverDatabase(ctx)
+		}()
+	}
+	wg.Wait()
+	gj.prevDBInfos = nil
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	// In dev mode write the schema snapshot out for future offline startups
+	if gj.conf.SchemaSnapshot != "" && !gj.prod && len(gj.degraded) == 0 {
+		if err := gj.saveSchemaSnapshot(); err != nil {
 			return err
 		}
 	}
@@ -71,9 +101,14 @@ func (gj *graphjinEngine) discoverDatabase(ctx *dbContext) error {
 		return nil
 	}
 
-	dbinfo, err := sdata.GetDBInfo(ctx.db, ctx.dbtype, gj.conf.Blocklist)
+	dbinfo, err := sdata.GetDBInfoSince(ctx.db, ctx.dbtype, gj.conf.Blocklist,
+		gj.prevDBInfos[ctx.name])
 	if err != nil {
-		return fmt.Errorf("database %s: schema discovery failed: %w", ctx.name, err)
+		err = fmt.Errorf("database %s: schema discovery failed: %w", ctx.name, err)
+		if gj.conf.SchemaSnapshot == "" {
+			return err
+		}
+		return gj.discoverFromSnapshot(ctx, err)
 	}
 	ctx.dbinfo = dbinfo
 
@@ -91,12 +126,40 @@ func (gj *graphjinEngine) discoverDatabase(ctx *dbContext) error {
 	return nil
 }
 
+// discoverFromSnapshot loads the schema metadata for an unreachable database
+// from the schema snapshot file. The database is marked as degraded, queries
+// can still be compiled but will fail to execute until it becomes reachable.
+func (gj *graphjinEngine) discoverFromSnapshot(ctx *dbContext, discoveryErr error) error {
+	ss, err := gj.loadSchemaSnapshot()
+	if err != nil {
+		return fmt.Errorf("%w (%s)", discoveryErr, err)
+	}
+
+	ds, ok := ss.Databases[ctx.name]
+	if !ok {
+		return fmt.Errorf("%w (schema snapshot: database not found)", discoveryErr)
+	}
+
+	ctx.dbinfo = ds.dbInfo(gj.conf.Blocklist)
+	gj.degradedMu.Lock()
+	if gj.degraded == nil {
+		gj.degraded = make(map[string]bool)
+	}
+	gj.degraded[ctx.name] = true
+	gj.degradedMu.Unlock()
+
+	gj.log.Printf("WRN %s, using schema snapshot from %s",
+		discoveryErr, ss.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
 // finalizeAllDatabases runs Phase 3: schema + compiler creation for all databases.
 // This must be called after initResolvers() which may add remote tables to the
-// primary database's dbinfo.
+// primary database's dbinfo. The default database goes first so its tables
+// are routed to it when other databases have tables of the same name.
 func (gj *graphjinEngine) finalizeAllDatabases() error {
-	for _, ctx := range gj.databases {
-		if err := gj.finalizeDatabaseSchema(ctx); err != nil {
+	for _, name := range gj.sortedDatabaseNames() {
+		if err := gj.finalizeDatabaseSchema(gj.databases[name]); err != nil {
 			return err
 		}
 	}
@@ -190,16 +253,34 @@ func (gj *graphjinEngine) finalizeDatabaseSchema(ctx *dbContext) error {
 		return fmt.Errorf("database %s: schema creation failed: %w", ctx.name, err)
 	}
 
+	calls, err := getCalls(gj.conf, ctx.schema)
+	if err != nil {
+		return fmt.Errorf("database %s: %w", ctx.name, err)
+	}
+
 	// Create QCode compiler for this database
 	qcc := qcode.Config{
-		TConfig:             gj.tmap,
-		DefaultBlock:        gj.conf.DefaultBlock,
-		DefaultLimit:        gj.conf.DefaultLimit,
-		DisableAgg:          gj.conf.DisableAgg,
-		DisableFuncs:        gj.conf.DisableFuncs,
-		EnableCamelcase:     gj.conf.EnableCamelcase,
-		DBSchema:            ctx.schema.DBSchema(),
-		EnableCacheTracking: gj.conf.CacheTrackingEnabled,
+		TConfig:              gj.tmap,
+		DefaultBlock:         gj.conf.DefaultBlock,
+		DefaultDeny:          gj.conf.DefaultDeny,
+		DefaultLimit:         gj.conf.DefaultLimit,
+		MaxLimitError:        gj.conf.MaxLimitError,
+		DisableAgg:           gj.conf.DisableAgg,
+		DisableFuncs:         gj.conf.DisableFuncs,
+		EnableCamelcase:      gj.conf.camelCase(),
+		Namer:                gj.namer,
+		DBSchema:             ctx.schema.DBSchema(),
+		EnableCacheTracking:  gj.conf.CacheTrackingEnabled,
+		Calls:                calls,
+		Filters:              gj.conf.Filters,
+		BlockOperators:       roleBlockOperators(gj.conf),
+		HintRoles:            hintRoles(gj.conf),
+		SeparateForeignJoins: gj.conf.SeparateForeignJoins,
+		DecimalAsString:      gj.conf.DecimalAsString,
+		BigIntAsString:       gj.conf.BigIntAsString,
+		Timezone:             gj.conf.Timezone.Output != "" || gj.conf.Timezone.Variable != "",
+		RequireTimezone:      gj.conf.Timezone.RequireOffset,
+		AuthTrace:            gj.conf.AuthTrace && !gj.prod,
 	}
 
 	ctx.qcodeCompiler, err = qcode.NewCompiler(ctx.schema, qcc)
@@ -218,7 +299,10 @@ func (gj *graphjinEngine) finalizeDatabaseSchema(ctx *dbContext) error {
 		DBType:          ctx.schema.DBType(),
 		DBVersion:       ctx.schema.DBVersion(),
 		SecPrefix:       gj.printFormat,
-		EnableCamelcase: gj.conf.EnableCamelcase,
+		EnableCamelcase: gj.conf.camelCase(),
+		Timezone:        gj.conf.Timezone.Output,
+		TimezoneVar:     gj.conf.Timezone.Variable,
+		QueryTags:       gj.queryTagsEnabled(ctx.name),
 	})
 	ctx.psqlCompiler.SetSchemaInfo(ctx.schema.GetTables())
 
@@ -301,6 +385,12 @@ func (gj *graphjinEngine) GetDatabase(name string) (*dbContext, bool) {
 	}
 
 	ctx, ok := gj.databases[name]
+	if ok {
+		if err := gj.warmDatabase(ctx); err != nil {
+			gj.log.Printf("WRN %s", err)
+			return ctx, false
+		}
+	}
 	return ctx, ok
 }
 
@@ -380,7 +470,7 @@ func (gj *graphjinEngine) ensureDiscoveredTablesInConfig(ctx *dbContext) {
 func (gj *graphjinEngine) collectDBInfos() map[string]*sdata.DBInfo {
 	m := make(map[string]*sdata.DBInfo, len(gj.databases))
 	for name, ctx := range gj.databases {
-		if ctx.dbinfo != nil {
+		if !ctx.lazy.Load() && ctx.dbinfo != nil {
 			m[name] = ctx.dbinfo
 		}
 	}
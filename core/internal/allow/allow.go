@@ -33,12 +33,20 @@ const (
 )
 
 type Item struct {
-	Namespace  string
-	Operation  string
-	Name       string
-	ActionJSON map[string]json.RawMessage
-	Query      []byte
-	Fragments  []Fragment
+	Namespace   string
+	Operation   string
+	Name        string
+	ActionJSON  map[string]json.RawMessage
+	Query       []byte
+	Fragments   []Fragment
+	Deprecation *Deprecation
+}
+
+// Deprecation is set by the @deprecated directive on a saved query
+type Deprecation struct {
+	Reason      string
+	Sunset      string
+	Replacement string
 }
 
 type Fragment struct {
@@ -141,6 +149,84 @@ func (al *List) GetByName(name string, useCache bool) (item Item, err error) {
 	return
 }
 
+// GetByNamespace returns a query by name for a namespace. Queries in the
+// namespace folder (queries/<namespace>/<name>.gql) are used first, then
+// queries saved as <namespace>.<name>.gql and finally the shared queries.
+func (al *List) GetByNamespace(ns, name string, useCache bool) (item Item, err error) {
+	if ns == "" {
+		return al.GetByName(name, useCache)
+	}
+	if !lookupSegmentRe.MatchString(ns) {
+		return item, fmt.Errorf("%w: %q", ErrInvalidLookupName, ns)
+	}
+	if name, err = validateLookupName(name); err != nil {
+		return item, err
+	}
+
+	key := ns + "/" + name
+	if useCache {
+		if v, ok := al.cache.Get(key); ok {
+			item = v
+			return
+		}
+	}
+
+	nsPath := filepath.Join(QUERY_PATH, ns)
+	for _, ext := range []string{".gql", ".graphql"} {
+		var ok bool
+		if ok, err = al.fs.Exists(filepath.Join(nsPath, name+ext)); err != nil {
+			return
+		} else if ok {
+			item, err = al.get(nsPath, name, ext, false)
+			break
+		}
+	}
+
+	if item.Query == nil && err == nil {
+		item, err = al.GetByName(ns+"."+name, false)
+		if errors.Is(err, ErrUnknownGraphQLQuery) {
+			item, err = al.GetByName(name, false)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	item.Namespace = ns
+	if useCache {
+		al.cache.Add(key, item)
+	}
+	return
+}
+
+// ListNamespace returns all queries in the namespace folder
+func (al *List) ListNamespace(ns string) (items []Item, err error) {
+	if !lookupSegmentRe.MatchString(ns) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidLookupName, ns)
+	}
+
+	nsPath := filepath.Join(QUERY_PATH, ns)
+	files, err := al.fs.List(nsPath)
+	if err != nil {
+		// the namespace may not have a folder
+		return nil, nil
+	}
+
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		if ext != ".gql" && ext != ".graphql" {
+			continue
+		}
+		item, getErr := al.get(nsPath, strings.TrimSuffix(file, ext), ext, false)
+		if getErr != nil {
+			continue
+		}
+		item.Namespace = ns
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 // get returns a query by name
 func (al *List) get(queryPath, name, ext string, useCache bool) (item Item, err error) {
 	queryNS, queryName := splitName(name)
@@ -172,6 +258,7 @@ func (al *List) get(queryPath, name, ext string, useCache bool) (item Item, err
 	item.Operation = h.Operation
 	item.Name = queryName
 	item.Query = query
+	item.Deprecation = parseDeprecation(query)
 
 	if len(vars) != 0 {
 		if err = json.Unmarshal(vars, &item.ActionJSON); err != nil {
@@ -251,6 +338,37 @@ func (al *List) saveItem(item Item) (err error) {
 	return
 }
 
+// parseDeprecation returns the arguments of the @deprecated directive
+// on the operation, the directive is validated when the query is compiled
+func parseDeprecation(query []byte) *Deprecation {
+	op, err := graph.Parse(query)
+	if err != nil {
+		return nil
+	}
+
+	for _, d := range op.Directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		dep := &Deprecation{}
+		for _, arg := range d.Args {
+			if arg.Val == nil {
+				continue
+			}
+			switch arg.Name {
+			case "reason":
+				dep.Reason = arg.Val.Val
+			case "sunset":
+				dep.Sunset = arg.Val.Val
+			case "replacement":
+				dep.Replacement = arg.Val.Val
+			}
+		}
+		return dep
+	}
+	return nil
+}
+
 // splitName splits a name into namespace and name
 func splitName(name string) (string, string) {
 	i := strings.LastIndex(name, ".")
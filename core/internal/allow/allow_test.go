@@ -109,3 +109,46 @@ func TestGetByNameRejectsEscapingImports(t *testing.T) {
 		t.Fatalf("expected invalid import path error, got %v", err)
 	}
 }
+
+func TestGetByNamespace(t *testing.T) {
+	fs := &testFS{files: map[string][]byte{
+		"/queries/getProducts.gql":        []byte(`query getProducts { products { id } }`),
+		"/queries/getUsers.gql":           []byte(`query getUsers { users { id } }`),
+		"/queries/getOrders.gql":          []byte(`query getOrders { orders { id } }`),
+		"/queries/acme/getProducts.gql":   []byte(`query getProducts { products { id name } }`),
+		"/queries/acme.getUsers.gql":      []byte(`query getUsers { users { id email } }`),
+		"/queries/globex/getProducts.gql": []byte(`query getProducts { products { price } }`),
+	}}
+
+	al, err := New(nil, fs, true)
+	if err != nil {
+		t.Fatalf("new allow list: %v", err)
+	}
+
+	tests := []struct {
+		ns, name, query string
+	}{
+		{"acme", "getProducts", `products { id name }`},
+		{"acme", "getUsers", `users { id email }`},
+		{"acme", "getOrders", `orders { id }`},
+		{"globex", "getProducts", `products { price }`},
+		{"", "getProducts", `products { id }`},
+	}
+
+	for _, tt := range tests {
+		item, err := al.GetByNamespace(tt.ns, tt.name, true)
+		if err != nil {
+			t.Fatalf("%s.%s: %v", tt.ns, tt.name, err)
+		}
+		if !strings.Contains(string(item.Query), tt.query) {
+			t.Errorf("%s.%s: expected %q, got %q", tt.ns, tt.name, tt.query, item.Query)
+		}
+		if item.Namespace != tt.ns {
+			t.Errorf("%s.%s: expected namespace %q, got %q", tt.ns, tt.name, tt.ns, item.Namespace)
+		}
+	}
+
+	if _, err := al.GetByNamespace("../acme", "getProducts", false); !errors.Is(err, ErrInvalidLookupName) {
+		t.Fatalf("expected invalid lookup name error, got %v", err)
+	}
+}
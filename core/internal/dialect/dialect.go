@@ -48,6 +48,7 @@ type InlineChildRenderer interface {
 	GetConfigVar(name string) (string, bool) // Returns config var value and whether it exists
 	GetSecPrefix() string
 	GetRootWithCursor() *qcode.Select // Returns first root select with cursor pagination
+	RenderColumnValue(f qcode.Field, val func()) // Renders a column as a string or in the output timezone when needed
 }
 
 type Dialect interface {
@@ -62,6 +63,7 @@ type Dialect interface {
 	RenderCursorCTE(ctx Context, sel *qcode.Select)
 	RenderOrderBy(ctx Context, sel *qcode.Select)
 	RenderDistinctOn(ctx Context, sel *qcode.Select)
+	RenderLock(ctx Context, sel *qcode.Select)
     RenderFromEdge(ctx Context, sel *qcode.Select) // For embedded/JSONTable vs RecordSet
 
 	RenderJSONPath(ctx Context, table, col string, path []string)
@@ -90,6 +92,7 @@ type Dialect interface {
 	BindVar(i int) string
 	UseNamedParams() bool
 	SupportsLateral() bool
+	SupportsDistinctOn() bool
 	
 	// Identifier quoting - each dialect uses different quote characters
 	QuoteIdentifier(s string) string
@@ -132,6 +135,10 @@ type Dialect interface {
 	RenderVarDeclaration(ctx Context, name, typeName string)
 	RenderMutateToRecordSet(ctx Context, m *qcode.Mutate, n int, renderRoot func())
 	RenderSetSessionVar(ctx Context, name, value string) bool
+	// Savepoints are used to roll back the @optional branches of a mutation,
+	// they return false when the database does not support savepoints
+	RenderSavepoint(ctx Context, name string) bool
+	RenderRollbackToSavepoint(ctx Context, name string) bool
 	
 	RenderLinearInsert(ctx Context, m *qcode.Mutate, qc *qcode.QCode, varName string, renderColVal func(qcode.MColumn))
 	RenderLinearUpdate(ctx Context, m *qcode.Mutate, qc *qcode.QCode, varName string, renderColVal func(qcode.MColumn), renderWhere func())
@@ -165,11 +172,22 @@ type Dialect interface {
 	// For Oracle/MSSQL: inline parent's WHERE expression (no outer scope correlation)
 	// For Postgres/MySQL: return false to use default outer scope correlation
 	RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Select, ti sdata.DBTable, pkCol string) bool
+	// RenderRecursivePath renders the cycle guard path of a recursive CTE row as
+	// ',<key>,' for the anchor row (path is nil) or as path followed by '<key>,'
+	RenderRecursivePath(ctx Context, path, key func())
+	// RenderRecursiveCycleCheck renders a condition that is true when the key
+	// is not already on the path
+	RenderRecursiveCycleCheck(ctx Context, path, key func())
 
 	// JSON Null Fields (moves db-specific code from psql/query.go)
 	RenderJSONNullField(ctx Context, fieldName string)       // NULL field syntax
 	RenderJSONNullCursorField(ctx Context, fieldName string) // NULL cursor field syntax
 	RenderJSONRootSuffix(ctx Context)                        // FOR JSON PATH for MSSQL, empty for others
+	// Root field holding a plain scalar instead of a JSON value (count roots)
+	RenderJSONRootScalarField(ctx Context, key string, val func())
+	// JSON boolean telling whether the query rendered by renderQuery returns
+	// any rows (exists roots)
+	RenderExists(ctx Context, renderQuery func())
 
 	// Array Operations (moves db-specific code from psql/mutate.go)
 	RenderArraySelectPrefix(ctx Context)                     // ARRAY(SELECT vs (SELECT JSON_ARRAYAGG(
@@ -180,6 +198,10 @@ type Dialect interface {
 	// Column rendering (moves db-specific code from psql/columns.go)
 	RequiresJSONQueryWrapper() bool     // MariaDB needs JSON_QUERY wrapper for inline children
 	RequiresNullOnEmptySelect() bool    // MySQL/SQLite/MariaDB need NULL when no columns rendered
+	RenderAsString(ctx Context, val func()) // value as a string, keeps decimals and 64-bit ints exact
+	RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) // timestamp in the output timezone
+	RenderDuration(ctx Context, val func())      // interval as an ISO-8601 duration
+	RenderDurationValue(ctx Context, val func()) // ISO-8601 duration value compared to an interval
 }
 
 // NameMapSetter is an optional interface that dialects can implement
@@ -207,6 +229,15 @@ type FullMutationCompiler interface {
 	CompileFullMutation(ctx Context, qc *qcode.QCode) bool
 }
 
+// ParamTypesRenderer is an optional interface that FullQueryCompiler and
+// FullMutationCompiler dialects can implement to list the types of the
+// bound parameters in the compiled output. The output is expected to be a
+// JSON object and the types are rendered just before its closing brace.
+// This is used by MongoDB where the driver binds parameters itself.
+type ParamTypesRenderer interface {
+	RenderParamTypes(ctx Context, params []Param)
+}
+
 func GenericRenderMutationPostamble(ctx Context, qc *qcode.QCode) {
 	for k, cids := range qc.MUnions {
 		if len(cids) < 2 {
@@ -243,3 +274,46 @@ func GenericRenderMutationPostamble(ctx Context, qc *qcode.QCode) {
 }
 
 
+
+// renderInlineNamedFilter renders a named filter for dialects that render
+// child selects inline, columns of the select table use its alias
+func renderInlineNamedFilter(ctx Context, r InlineChildRenderer, sel *qcode.Select, ex *qcode.Exp) {
+	t := ex.Left.Table
+	if t == sel.Ti.Name && sel.ID >= 0 {
+		t = fmt.Sprintf("%s_%d", t, sel.ID)
+	} else if ex.Left.ID >= 0 {
+		t = fmt.Sprintf("%s_%d", t, ex.Left.ID)
+	}
+
+	ctx.WriteString(`(`)
+	for _, tok := range ex.SQL.Tokens {
+		if tok.Col {
+			r.ColWithTable(t, tok.Val)
+		} else {
+			ctx.WriteString(tok.Val)
+		}
+	}
+	ctx.WriteString(`)`)
+}
+
+// renderInlineOrderCol renders the column of an order by for dialects that
+// render child selects inline, case-insensitive ordering sorts on the lower
+// cased value
+func renderInlineOrderCol(ctx Context, r InlineChildRenderer, ob qcode.OrderBy, t, col string) {
+	if ob.CI && ob.Var == "" {
+		ctx.WriteString(`LOWER(`)
+		r.ColWithTable(t, col)
+		ctx.WriteString(`)`)
+		return
+	}
+	r.ColWithTable(t, col)
+}
+
+// distinctFilterNeeded returns true when an inline select has to emulate
+// distinct with a window function since the dialect has no DISTINCT ON
+func distinctFilterNeeded(sel *qcode.Select) bool {
+	return len(sel.DistinctOn) != 0 &&
+		!sel.GroupCols &&
+		sel.Rel.Type != sdata.RelEmbedded &&
+		sel.Ti.PrimaryCol.Name != ""
+}
@@ -225,6 +225,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 				t = fmt.Sprintf("%s_%d", t, sel.ID)
 			}
 			d.RenderTableAlias(ctx, t)
+			d.renderTableHint(ctx, sel)
 		}
 
 		// Render join tables for many-to-many relationships
@@ -241,10 +242,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 		}
 
 		// Render the relationship filter (WHERE clause)
-		if sel.Where.Exp != nil {
-			ctx.WriteString(` WHERE `)
-			d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
-		}
+		d.renderWhere(ctx, r, psel, sel)
 		d.renderGroupBy(ctx, r, sel)
 
 		// Render LIMIT 1 for singular
@@ -268,7 +266,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 					if sel.ID >= 0 {
 						t = fmt.Sprintf("%s_%d", t, sel.ID)
 					}
-					r.ColWithTable(t, ob.Col.Name)
+					renderInlineOrderCol(ctx, r, ob, t, ob.Col.Name)
 					switch ob.Order {
 					case qcode.OrderAsc:
 						ctx.WriteString(` ASC`)
@@ -288,6 +286,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 					t = fmt.Sprintf("%s_%d", t, sel.ID)
 				}
 				d.RenderTableAlias(ctx, t)
+				d.renderTableHint(ctx, sel)
 			}
 
 			// Render join tables for many-to-many relationships
@@ -297,10 +296,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 			}
 
 			// Render the relationship filter (WHERE clause)
-			if sel.Where.Exp != nil {
-				ctx.WriteString(` WHERE `)
-				d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
-			}
+			d.renderWhere(ctx, r, psel, sel)
 			d.renderGroupBy(ctx, r, sel)
 		} else {
 			// For root queries, use a subquery to apply ORDER BY and LIMIT before aggregation
@@ -360,6 +356,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 					t = fmt.Sprintf("%s_%d", t, sel.ID)
 				}
 				d.RenderTableAlias(ctx, t)
+				d.renderTableHint(ctx, sel)
 			}
 
 			// Add __cur CTE join for cursor pagination
@@ -382,10 +379,7 @@ func (d *MariaDBDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, p
 			}
 
 			// Render the relationship filter (WHERE clause)
-			if sel.Where.Exp != nil {
-				ctx.WriteString(` WHERE `)
-				d.renderWhereExp(ctx, r, nil, sel, sel.Where.Exp)
-			}
+			d.renderWhere(ctx, r, nil, sel)
 			d.renderGroupBy(ctx, r, sel)
 
 			// Render ORDER BY
@@ -521,7 +515,7 @@ func (d *MariaDBDialect) renderInlineJSONFields(ctx Context, r InlineChildRender
 			if isJSON {
 				ctx.WriteString(`JSON_QUERY(`)
 			}
-			r.ColWithTable(t, f.Col.Name)
+			r.RenderColumnValue(f, func() { r.ColWithTable(t, f.Col.Name) })
 			if isJSON {
 				ctx.WriteString(`, '$')`)
 			}
@@ -668,7 +662,7 @@ func (d *MariaDBDialect) renderBaseColumns(ctx Context, r InlineChildRenderer, s
 			}
 			ctx.WriteString(`)`)
 		} else {
-			r.ColWithTable(t, f.Col.Name)
+			r.RenderColumnValue(f, func() { r.ColWithTable(t, f.Col.Name) })
 		}
 
 		if f.FieldFilter.Exp != nil {
@@ -711,7 +705,7 @@ func (d *MariaDBDialect) renderBaseColumns(ctx Context, r InlineChildRenderer, s
 			ctx.WriteString(fmt.Sprintf("'%s'", ob.Key))
 			ctx.WriteString(` THEN `)
 		}
-		r.ColWithTable(t, col)
+		renderInlineOrderCol(ctx, r, ob, t, col)
 		if ob.KeyVar != "" && ob.Key != "" {
 			ctx.WriteString(` END`)
 		}
@@ -959,6 +953,87 @@ func (d *MariaDBDialect) RenderTableAlias(ctx Context, alias string) {
 	ctx.WriteString(` `)
 }
 
+// renderWhere renders the WHERE clause of an inline select, adding the
+// distinct filter when the select has distinct columns
+func (d *MariaDBDialect) renderWhere(ctx Context, r InlineChildRenderer, psel, sel *qcode.Select) {
+	if !distinctFilterNeeded(sel) {
+		if sel.Where.Exp != nil {
+			ctx.WriteString(` WHERE `)
+			d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+		}
+		return
+	}
+
+	ctx.WriteString(` WHERE `)
+	if sel.Where.Exp != nil {
+		ctx.WriteString(`(`)
+		d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+		ctx.WriteString(`) AND `)
+	}
+	d.renderDistinctFilter(ctx, r, psel, sel)
+}
+
+// renderDistinctFilter keeps the first row of every distinct group, MariaDB
+// has no DISTINCT ON so primary keys are matched against a ROW_NUMBER()
+// partition over the distinct columns
+func (d *MariaDBDialect) renderDistinctFilter(ctx Context, r InlineChildRenderer, psel, sel *qcode.Select) {
+	t := sel.Ti.Name
+	if sel.ID >= 0 {
+		t = fmt.Sprintf("%s_%d", t, sel.ID)
+	}
+	pk := sel.Ti.PrimaryCol.Name
+
+	r.ColWithTable(t, pk)
+	ctx.WriteString(` IN (SELECT `)
+	r.ColWithTable(`__gj_d`, pk)
+	ctx.WriteString(` FROM (SELECT `)
+	r.ColWithTable(t, pk)
+	ctx.WriteString(`, ROW_NUMBER() OVER (PARTITION BY `)
+	for i, col := range sel.DistinctOn {
+		if i != 0 {
+			ctx.WriteString(`, `)
+		}
+		r.ColWithTable(t, col.Name)
+	}
+	d.renderOrderBy(ctx, r, sel, "")
+	ctx.WriteString(`) AS `)
+	ctx.Quote(`__gj_rn`)
+	ctx.WriteString(` FROM `)
+	d.renderFromTable(ctx, r, sel, psel)
+	d.RenderTableAlias(ctx, t)
+	d.renderTableHint(ctx, sel)
+	if sel.Paging.Cursor {
+		ctx.WriteString(`, `)
+		ctx.Quote("__cur")
+	}
+	for _, join := range sel.Joins {
+		d.renderJoinWithAlias(ctx, r, psel, sel, join)
+	}
+	for _, ob := range sel.OrderBy {
+		if ob.Var != "" {
+			d.renderOrderByJoin(ctx, r, sel, ob)
+		}
+	}
+	if sel.Where.Exp != nil {
+		ctx.WriteString(` WHERE `)
+		d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+	}
+	ctx.WriteString(`)`)
+	d.RenderTableAlias(ctx, `__gj_d`)
+	ctx.WriteString(`WHERE `)
+	r.ColWithTable(`__gj_d`, `__gj_rn`)
+	ctx.WriteString(` = 1)`)
+}
+
+// renderTableHint writes the @hint of a select after its table alias,
+// MariaDB hints are index hints like USE INDEX
+func (d *MariaDBDialect) renderTableHint(ctx Context, sel *qcode.Select) {
+	if sel.Hint != "" {
+		ctx.WriteString(sel.Hint)
+		ctx.WriteString(` `)
+	}
+}
+
 // renderFromTable handles FROM clause for both regular tables and embedded JSON tables.
 // For embedded JSON tables (RelEmbedded), it uses JSON_TABLE to unpack the JSON column.
 // For regular tables, it uses the standard table name.
@@ -977,17 +1052,7 @@ func (d *MariaDBDialect) renderFromTable(ctx Context, r InlineChildRenderer, sel
 		ctx.WriteString(`.`)
 		ctx.Quote(sel.Rel.Left.Col.Name)
 		ctx.WriteString(`, '$[*]' COLUMNS(`)
-		for i, col := range sel.Ti.Columns {
-			if i != 0 {
-				ctx.WriteString(`, `)
-			}
-			ctx.WriteString(col.Name)
-			ctx.WriteString(` `)
-			ctx.WriteString(col.Type)
-			ctx.WriteString(` PATH '$.`)
-			ctx.WriteString(col.Name)
-			ctx.WriteString(`' ERROR ON ERROR`)
-		}
+		renderJSONTableColumns(ctx, sel)
 		ctx.WriteString(`)) AS `)
 		// Use tableName_ID pattern to match column reference expectations
 		t := sel.Ti.Name
@@ -1118,7 +1183,7 @@ func (d *MariaDBDialect) renderOrderBy(ctx Context, r InlineChildRenderer, sel *
 			ctx.WriteString(fmt.Sprintf("'%s'", ob.Key))
 			ctx.WriteString(` THEN `)
 		}
-		r.ColWithTable(t, col)
+		renderInlineOrderCol(ctx, r, ob, t, col)
 		if ob.KeyVar != "" && ob.Key != "" {
 			ctx.WriteString(` END `)
 		}
@@ -1152,6 +1217,9 @@ func (d *MariaDBDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel
 
 
 	switch ex.Op {
+	case qcode.OpNamedFilter:
+		renderInlineNamedFilter(ctx, r, sel, ex)
+
 	case qcode.OpAnd:
 		ctx.WriteString(`(`)
 		for i, child := range ex.Children {
@@ -1319,13 +1387,17 @@ func (d *MariaDBDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel
 		qcode.OpLike, qcode.OpNotLike, qcode.OpILike, qcode.OpNotILike,
 		qcode.OpSimilar, qcode.OpNotSimilar, qcode.OpRegex, qcode.OpNotRegex,
 		qcode.OpIRegex, qcode.OpNotIRegex,
-		qcode.OpHasKey, qcode.OpHasKeyAny, qcode.OpHasKeyAll:
+		qcode.OpHasKey, qcode.OpHasKeyAny, qcode.OpHasKeyAll,
+		qcode.OpEqualsCI:
 
 		if d.renderValPrefix(ctx, r, psel, sel, ex) {
 			return
 		}
 
 		ctx.WriteString(`((`)
+		if ex.Op == qcode.OpEqualsCI {
+			ctx.WriteString(`LOWER(`)
+		}
 
 		// Render left side
 		if ex.Left.Col.Name != "" {
@@ -1402,11 +1474,14 @@ func (d *MariaDBDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel
 				r.ColWithTable(t, ex.Left.Col.Name)
 			}
 		}
+		if ex.Op == qcode.OpEqualsCI {
+			ctx.WriteString(`)`)
+		}
 		ctx.WriteString(`) `)
 
 		// Render operator
 		switch ex.Op {
-		case qcode.OpEquals:
+		case qcode.OpEquals, qcode.OpEqualsCI:
 			ctx.WriteString(`=`)
 		case qcode.OpNotEquals:
 			ctx.WriteString(`!=`)
@@ -1428,7 +1503,11 @@ func (d *MariaDBDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel
 			ctx.WriteString(` NOT REGEXP`)
 		}
 
-		ctx.WriteString(` (`)
+		if ex.Op == qcode.OpEqualsCI {
+			ctx.WriteString(` LOWER(`)
+		} else {
+			ctx.WriteString(` (`)
+		}
 
 		// Render right side
 		if ex.Right.Col.Name != "" {
@@ -1514,6 +1593,11 @@ func (d *MariaDBDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel
 			}
 		} else if ex.Right.ValType == qcode.ValDBVar {
 			d.RenderVar(ctx, ex.Right.Val)
+		} else if ex.Right.ValType == qcode.ValNowMinus {
+			// Duration operators, the seconds are parsed by the qcode compiler
+			ctx.WriteString(`DATE_SUB(NOW(), INTERVAL `)
+			ctx.WriteString(ex.Right.Val)
+			ctx.WriteString(` SECOND)`)
 		} else {
 			d.RenderLiteral(ctx, ex.Right.Val, ex.Right.ValType)
 		}
@@ -1902,6 +1986,9 @@ func (d *MariaDBDialect) renderRecursiveInlineChild(ctx Context, r InlineChildRe
 	if sel.Paging.Limit > 0 && sel.Paging.Limit < int32(maxDepth) {
 		maxDepth = int(sel.Paging.Limit)
 	}
+	if sel.MaxDepth > 0 && int(sel.MaxDepth) < maxDepth {
+		maxDepth = int(sel.MaxDepth)
+	}
 
 	// Determine if we have aggregation functions
 	hasAggregation := false
@@ -1938,8 +2025,14 @@ func (d *MariaDBDialect) renderRecursiveInlineChild(ctx Context, r InlineChildRe
 				if i != 0 {
 					ctx.WriteString(`, `)
 				}
-				ctx.WriteString(`t.`)
-				ctx.Quote(ob.Col.Name)
+				if ob.CI {
+					ctx.WriteString(`LOWER(t.`)
+					ctx.Quote(ob.Col.Name)
+					ctx.WriteString(`)`)
+				} else {
+					ctx.WriteString(`t.`)
+					ctx.Quote(ob.Col.Name)
+				}
 				switch ob.Order {
 				case qcode.OrderAsc:
 					ctx.WriteString(` ASC`)
@@ -2515,6 +2608,13 @@ func (d *MariaDBDialect) RenderJSONRootSuffix(ctx Context) {
 	// MariaDB doesn't need any suffix
 }
 
+func (d *MariaDBDialect) RenderExists(ctx Context, renderQuery func()) {
+	// MariaDB has no JSON type to cast to, JSON_EXTRACT returns a JSON value
+	ctx.WriteString(`IF(EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`), JSON_EXTRACT('true', '$'), JSON_EXTRACT('false', '$'))`)
+}
+
 // Array Operations
 func (d *MariaDBDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`(SELECT JSON_ARRAYAGG(`)
@@ -2,6 +2,7 @@ package dialect
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -42,6 +43,12 @@ func (d *MongoDBDialect) SupportsLateral() bool {
 	return true
 }
 
+// SupportsDistinctOn returns false since distinct is rendered as a
+// $group stage in the aggregation pipeline.
+func (d *MongoDBDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 func (d *MongoDBDialect) SupportsReturning() bool {
 	// MongoDB returns documents after mutations
 	return true
@@ -103,7 +110,8 @@ func (d *MongoDBDialect) renderProjectFields(ctx Context, sel *qcode.Select) {
 		}
 		ctx.WriteString(`"`)
 		ctx.WriteString(f.Col.Name)
-		ctx.WriteString(`":1`)
+		ctx.WriteString(`":`)
+		d.renderProjectValue(ctx, f, f.Col.Name)
 		first = false
 	}
 	// Always include _id
@@ -256,12 +264,25 @@ func (d *MongoDBDialect) renderGraphLookup(ctx Context, sel *qcode.Select) {
 		ctx.WriteString(`"`)
 	}
 
+	d.renderGraphLookupMaxDepth(ctx, sel)
+
 	ctx.WriteString(`,"as":"`)
 	ctx.WriteString(sel.FieldName)
 	ctx.WriteString(`"}}`)
 	d.pipelineDepth++
 }
 
+// renderGraphLookupMaxDepth caps the $graphLookup traversal to the selects
+// max_depth. $graphLookup counts the first level as depth 0 and already skips
+// documents it has visited, so cycles need no extra guard.
+func (d *MongoDBDialect) renderGraphLookupMaxDepth(ctx Context, sel *qcode.Select) {
+	if sel.MaxDepth <= 0 {
+		return
+	}
+	ctx.WriteString(`,"maxDepth":`)
+	ctx.WriteString(strconv.Itoa(int(sel.MaxDepth - 1)))
+}
+
 func (d *MongoDBDialect) RenderJoinTables(ctx Context, sel *qcode.Select) {
 	// MongoDB doesn't have traditional JOIN tables
 }
@@ -349,7 +370,6 @@ func (d *MongoDBDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 }
 
 func (d *MongoDBDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
-	// MongoDB uses $group for distinct
 	if len(sel.DistinctOn) == 0 {
 		return
 	}
@@ -357,19 +377,34 @@ func (d *MongoDBDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 	if d.pipelineDepth > 0 {
 		ctx.WriteString(`,`)
 	}
+	d.renderDistinctStages(ctx, sel)
+	d.pipelineDepth++
+}
+
+func (d *MongoDBDialect) RenderLock(ctx Context, sel *qcode.Select) {
+}
+
+// renderDistinctStages groups documents on the distinct fields and keeps the
+// whole first document of each group ($first of $$ROOT) so the selected
+// fields survive the $group stage. A bare $group would only return _id.
+func (d *MongoDBDialect) renderDistinctStages(ctx Context, sel *qcode.Select) {
 	ctx.WriteString(`{"$group":{"_id":{`)
 	for i, col := range sel.DistinctOn {
 		if i != 0 {
 			ctx.WriteString(`,`)
 		}
+		colName := col.Name
+		if colName == "id" {
+			colName = "_id"
+		}
 		ctx.WriteString(`"`)
 		ctx.WriteString(col.Name)
 		ctx.WriteString(`":"$`)
-		ctx.WriteString(col.Name)
+		ctx.WriteString(colName)
 		ctx.WriteString(`"`)
 	}
-	ctx.WriteString(`}}}`)
-	d.pipelineDepth++
+	ctx.WriteString(`},"__doc":{"$first":"$$ROOT"}}},`)
+	ctx.WriteString(`{"$replaceRoot":{"newRoot":"$__doc"}}`)
 }
 
 func (d *MongoDBDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
@@ -719,6 +754,14 @@ func (d *MongoDBDialect) RenderSetSessionVar(ctx Context, name, value string) bo
 	return false
 }
 
+func (d *MongoDBDialect) RenderSavepoint(ctx Context, name string) bool {
+	return false
+}
+
+func (d *MongoDBDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	return false
+}
+
 func (d *MongoDBDialect) RenderLinearInsert(ctx Context, m *qcode.Mutate, qc *qcode.QCode, varName string, renderColVal func(qcode.MColumn)) {
 }
 
@@ -793,6 +836,10 @@ func (d *MongoDBDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Sel
 	return false
 }
 
+func (d *MongoDBDialect) RenderRecursivePath(ctx Context, path, key func()) {}
+
+func (d *MongoDBDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {}
+
 // JSON null field methods
 
 func (d *MongoDBDialect) RenderJSONNullField(ctx Context, fieldName string) {
@@ -809,6 +856,14 @@ func (d *MongoDBDialect) RenderJSONRootSuffix(ctx Context) {
 	// No suffix needed for MongoDB
 }
 
+func (d *MongoDBDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	d.RenderJSONRootField(ctx, key, val)
+}
+
+func (d *MongoDBDialect) RenderExists(ctx Context, renderQuery func()) {
+	// MongoDB uses a findOne query for exists roots (see renderScalarQuery)
+}
+
 // Array operations
 
 func (d *MongoDBDialect) RenderArraySelectPrefix(ctx Context) {
@@ -841,6 +896,49 @@ func (d *MongoDBDialect) RequiresNullOnEmptySelect() bool {
 	return false
 }
 
+func (d *MongoDBDialect) RenderAsString(ctx Context, val func()) {
+	val()
+}
+
+func (d *MongoDBDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	val()
+}
+
+func (d *MongoDBDialect) RenderDuration(ctx Context, val func()) {
+	val()
+}
+
+func (d *MongoDBDialect) RenderDurationValue(ctx Context, val func()) {
+	val()
+}
+
+// RenderParamTypes lists the parameter types in placeholder order so that
+// the driver can bind each value with the matching BSON type
+func (d *MongoDBDialect) RenderParamTypes(ctx Context, params []Param) {
+	ctx.WriteString(`,"param_types":[`)
+	for i, p := range params {
+		if i > 0 {
+			ctx.WriteString(`,`)
+		}
+		ctx.WriteString(`"`)
+		if p.IsArray {
+			ctx.WriteString(escapeJSONString(p.Type + "[]"))
+		} else {
+			ctx.WriteString(escapeJSONString(p.Type))
+		}
+		ctx.WriteString(`"`)
+	}
+	ctx.WriteString(`]`)
+}
+
+// paramType returns the type of the column a variable is compared against
+func paramType(exp *qcode.Exp) string {
+	if exp.Left.Col.Type != "" && !exp.Left.Col.Array {
+		return exp.Left.Col.Type
+	}
+	return "any"
+}
+
 // Helper to escape JSON strings
 func escapeJSONString(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
@@ -851,6 +949,57 @@ func escapeJSONString(s string) string {
 	return s
 }
 
+// likeToRegex converts a SQL LIKE pattern into an anchored regular expression.
+// Regex metacharacters are escaped, % and _ become .* and . and a backslash
+// escapes the next character. Leading and trailing % are dropped along with
+// the matching anchor so that prefix patterns like abc% become ^abc, which
+// MongoDB can serve from an index.
+func likeToRegex(pattern string) string {
+	var sb strings.Builder
+	var tokens []string
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			tokens = append(tokens, regexp.QuoteMeta(pattern[i:i+1]))
+		case c == '%':
+			// collapse runs of % into a single wildcard
+			if len(tokens) == 0 || tokens[len(tokens)-1] != ".*" {
+				tokens = append(tokens, ".*")
+			}
+		case c == '_':
+			tokens = append(tokens, ".")
+		default:
+			tokens = append(tokens, regexp.QuoteMeta(pattern[i:i+1]))
+		}
+	}
+
+	start, end := 0, len(tokens)
+	if end != 0 && tokens[0] == ".*" {
+		start++
+	} else {
+		sb.WriteByte('^')
+	}
+	anchorEnd := true
+	if end > start && tokens[end-1] == ".*" {
+		end--
+		anchorEnd = false
+	} else if start == end && start != 0 {
+		// a lone % matches everything
+		anchorEnd = false
+	}
+
+	for _, t := range tokens[start:end] {
+		sb.WriteString(t)
+	}
+	if anchorEnd {
+		sb.WriteByte('$')
+	}
+	return sb.String()
+}
+
 // CompileFullMutation implements FullMutationCompiler interface.
 // It generates the complete JSON mutation DSL for MongoDB, bypassing SQL generation.
 func (d *MongoDBDialect) CompileFullMutation(ctx Context, qc *qcode.QCode) bool {
@@ -2196,6 +2345,15 @@ func (d *MongoDBDialect) renderNulledQuery(ctx Context, sel *qcode.Select) {
 
 // renderAggregateQuery generates a single aggregate query for a root selection
 func (d *MongoDBDialect) renderAggregateQuery(ctx Context, qc *qcode.QCode, sel *qcode.Select) {
+	if sel.Count {
+		d.renderScalarQuery(ctx, "countDocuments", sel)
+		return
+	}
+	if sel.Exists {
+		d.renderScalarQuery(ctx, "exists", sel)
+		return
+	}
+
 	// Start the JSON query
 	ctx.WriteString(`{"operation":"aggregate","collection":"`)
 	ctx.WriteString(sel.Table)
@@ -2215,6 +2373,13 @@ func (d *MongoDBDialect) renderAggregateQuery(ctx Context, qc *qcode.QCode, sel
 		ctx.WriteString(`"`)
 	}
 
+	// Index hint from the @hint directive, an index name
+	if sel.Hint != "" {
+		ctx.WriteString(`,"hint":"`)
+		ctx.WriteString(escapeJSONString(sel.Hint))
+		ctx.WriteString(`"`)
+	}
+
 	ctx.WriteString(`,"pipeline":[`)
 
 	pipelineDepth := 0
@@ -2266,6 +2431,18 @@ func (d *MongoDBDialect) renderAggregateQuery(ctx Context, qc *qcode.QCode, sel
 		pipelineDepth++
 	}
 
+	// Keep the first document of every distinct group, $group does not
+	// preserve order so the documents are sorted again afterwards
+	if len(sel.DistinctOn) > 0 && !sel.GroupCols {
+		if pipelineDepth > 0 {
+			ctx.WriteString(`,`)
+		}
+		d.renderDistinctStages(ctx, sel)
+		ctx.WriteString(`,`)
+		d.renderSortStage(ctx, sel)
+		pipelineDepth++
+	}
+
 	// Add $skip stage if there's an offset (skip for aggregation queries)
 	if !sel.GroupCols && (sel.Paging.Offset > 0 || sel.Paging.OffsetVar != "") {
 		if pipelineDepth > 0 {
@@ -2335,6 +2512,27 @@ func (d *MongoDBDialect) renderAggregateQuery(ctx Context, qc *qcode.QCode, sel
 	ctx.WriteString(`}`)
 }
 
+// renderScalarQuery generates the countDocuments query for a <table>_count
+// root or the exists (findOne) query for a <table>_exists root
+func (d *MongoDBDialect) renderScalarQuery(ctx Context, op string, sel *qcode.Select) {
+	ctx.WriteString(`{"operation":"`)
+	ctx.WriteString(op)
+	ctx.WriteString(`","collection":"`)
+	ctx.WriteString(sel.Table)
+	ctx.WriteString(`","field_name":"`)
+	ctx.WriteString(sel.FieldName)
+	ctx.WriteString(`"`)
+
+	if sel.Where.Exp != nil {
+		if exp := filterOutVariableConditions(sel.Where.Exp); exp != nil {
+			ctx.WriteString(`,"filter":{`)
+			d.renderExpression(ctx, exp)
+			ctx.WriteString(`}`)
+		}
+	}
+	ctx.WriteString(`}`)
+}
+
 // renderCursorInfo generates cursor metadata for the driver to extract cursor values
 // and to apply seek-based filtering for cursor pagination.
 func (d *MongoDBDialect) renderCursorInfo(ctx Context, sel *qcode.Select) {
@@ -2534,24 +2732,19 @@ func (d *MongoDBDialect) renderLookupStageWithQC(ctx Context, parent, child *qco
 	}
 	ctx.WriteString(`}}}`)
 
-	// Add nested lookups for grandchildren FIRST (before $project)
+	// Sort and limit before the nested lookups so that grandchildren are only
+	// looked up for the documents that are kept and the sort can use columns
+	// that are not part of the projection. Embedded JSON children regroup the
+	// documents with $group, so in that case the sort has to come last.
+	hasEmbeddedChild := d.hasEmbeddedChild(child, qc)
+	if !hasEmbeddedChild {
+		d.renderLookupSortLimit(ctx, child)
+	}
+
+	// Add nested lookups for grandchildren (before $project)
 	// This is important for embedded JSON tables which use $unwind/$group
 	// and need to access the embedded array before it's projected out
-	hasEmbeddedChild := false
-	if qc != nil && len(child.Children) > 0 {
-		for _, grandchildID := range child.Children {
-			grandchild := &qc.Selects[grandchildID]
-			if grandchild.SkipRender != qcode.SkipTypeNone {
-				continue
-			}
-			if grandchild.Rel.Type == sdata.RelEmbedded {
-				hasEmbeddedChild = true
-			}
-			ctx.WriteString(`,`)
-			// Use the same lookup rendering recursively
-			d.renderLookupStageWithQC(ctx, child, grandchild, qc)
-		}
-	}
+	d.renderNestedLookups(ctx, child, qc)
 
 	// Add $project stage within the pipeline to select only requested fields
 	// Note: Skip $project if there was embedded processing - it handles projection differently
@@ -2630,24 +2823,73 @@ func (d *MongoDBDialect) renderLookupStageWithQC(ctx Context, parent, child *qco
 			first = false
 		}
 		// Also include grandchild field names (for embedded or looked up fields)
-		if qc != nil {
-			for _, grandchildID := range child.Children {
-				grandchild := &qc.Selects[grandchildID]
-				if grandchild.SkipRender != qcode.SkipTypeNone {
-					continue
-				}
-				if !first {
-					ctx.WriteString(`,`)
-				}
-				ctx.WriteString(`"`)
-				ctx.WriteString(grandchild.FieldName)
-				ctx.WriteString(`":1`)
-				first = false
-			}
-		}
+		d.renderNestedLookupNames(ctx, child, qc, first)
 		ctx.WriteString(`}}`)
 	}
 
+	if hasEmbeddedChild {
+		d.renderLookupSortLimit(ctx, child)
+	}
+
+	ctx.WriteString(`],"as":"`)
+	ctx.WriteString(child.FieldName)
+	ctx.WriteString(`"}}`)
+}
+
+// hasEmbeddedChild reports whether any rendered child of sel is an embedded JSON table
+func (d *MongoDBDialect) hasEmbeddedChild(sel *qcode.Select, qc *qcode.QCode) bool {
+	if qc == nil {
+		return false
+	}
+	for _, childID := range sel.Children {
+		child := &qc.Selects[childID]
+		if child.SkipRender == qcode.SkipTypeNone && child.Rel.Type == sdata.RelEmbedded {
+			return true
+		}
+	}
+	return false
+}
+
+// renderNestedLookups renders a lookup stage for every rendered child of sel,
+// recursing through renderLookupStageWithQC so that children of children at
+// any depth get their own $lookup, $sort and $limit stages
+func (d *MongoDBDialect) renderNestedLookups(ctx Context, sel *qcode.Select, qc *qcode.QCode) {
+	if qc == nil {
+		return
+	}
+	for _, childID := range sel.Children {
+		child := &qc.Selects[childID]
+		if child.SkipRender != qcode.SkipTypeNone {
+			continue
+		}
+		ctx.WriteString(`,`)
+		d.renderLookupStageWithQC(ctx, sel, child, qc)
+	}
+}
+
+// renderNestedLookupNames adds the field names of the children looked up by
+// renderNestedLookups to an open $project stage
+func (d *MongoDBDialect) renderNestedLookupNames(ctx Context, sel *qcode.Select, qc *qcode.QCode, first bool) {
+	if qc == nil {
+		return
+	}
+	for _, childID := range sel.Children {
+		child := &qc.Selects[childID]
+		if child.SkipRender != qcode.SkipTypeNone {
+			continue
+		}
+		if !first {
+			ctx.WriteString(`,`)
+		}
+		ctx.WriteString(`"`)
+		ctx.WriteString(child.FieldName)
+		ctx.WriteString(`":1`)
+		first = false
+	}
+}
+
+// renderLookupSortLimit renders the $sort and $limit stages of a lookup pipeline
+func (d *MongoDBDialect) renderLookupSortLimit(ctx Context, child *qcode.Select) {
 	// Add $sort stage if there's ordering, or default sort by _id for consistent results
 	// Use $sort_ordered to preserve field order (Go maps don't preserve order)
 	if len(child.OrderBy) > 0 {
@@ -2688,10 +2930,6 @@ func (d *MongoDBDialect) renderLookupStageWithQC(ctx Context, parent, child *qco
 		}
 		ctx.WriteString(`}`)
 	}
-
-	ctx.WriteString(`],"as":"`)
-	ctx.WriteString(child.FieldName)
-	ctx.WriteString(`"}}`)
 }
 
 // renderRecursiveLookup handles recursive (self-referential) relationships using $graphLookup
@@ -2737,6 +2975,7 @@ func (d *MongoDBDialect) renderRecursiveLookup(ctx Context, parent, child *qcode
 
 	// Add depthField to track hierarchy level
 	ctx.WriteString(`,"depthField":"__depth"`)
+	d.renderGraphLookupMaxDepth(ctx, child)
 
 	ctx.WriteString(`,"as":"`)
 	ctx.WriteString(child.FieldName)
@@ -2968,7 +3207,7 @@ func (d *MongoDBDialect) renderRecursiveWhereCondition(ctx Context, exp *qcode.E
 // renderRecursiveComparisonValue renders a value for comparison in recursive where
 func (d *MongoDBDialect) renderRecursiveComparisonValue(ctx Context, exp *qcode.Exp) {
 	if exp.Right.ValType == qcode.ValVar {
-		ctx.AddParam(Param{Name: exp.Right.Val, Type: "any"})
+		ctx.AddParam(Param{Name: exp.Right.Val, Type: paramType(exp)})
 	} else {
 		ctx.WriteString(exp.Right.Val)
 	}
@@ -3013,6 +3252,9 @@ func (d *MongoDBDialect) renderPolymorphicLookups(ctx Context, parent, polyChild
 		ctx.WriteString(unionMember.Table)
 		ctx.WriteString(`"]},{"$eq":["$_id","$$idVal"]}]}}}`)
 
+		// Add lookups for the union member's own children
+		d.renderNestedLookups(ctx, unionMember, qc)
+
 		// Add $project stage within the pipeline to select only requested fields
 		if len(unionMember.Fields) > 0 {
 			hasIdField := false
@@ -3045,9 +3287,11 @@ func (d *MongoDBDialect) renderPolymorphicLookups(ctx Context, parent, polyChild
 				}
 				ctx.WriteString(`"`)
 				ctx.WriteString(colName)
-				ctx.WriteString(`":1`)
+				ctx.WriteString(`":`)
+				d.renderProjectValue(ctx, f, colName)
 				first = false
 			}
+			d.renderNestedLookupNames(ctx, unionMember, qc, first)
 			ctx.WriteString(`}}`)
 		}
 
@@ -3119,6 +3363,12 @@ func (d *MongoDBDialect) renderM2MLookupViaJoinTable(ctx Context, parent, child
 	ctx.WriteString(`,{"$unwind":"$_target"}`)
 	ctx.WriteString(`,{"$replaceRoot":{"newRoot":"$_target"}}`)
 
+	hasEmbeddedChild := d.hasEmbeddedChild(child, qc)
+	if !hasEmbeddedChild {
+		d.renderLookupSortLimit(ctx, child)
+	}
+	d.renderNestedLookups(ctx, child, qc)
+
 	// Add $project for requested fields if specified
 	// Note: mongodriver's translateFieldsInMap converts "id" -> "_id" in keys,
 	// and translateIDFieldsBack converts "_id" -> "id" in results.
@@ -3158,9 +3408,14 @@ func (d *MongoDBDialect) renderM2MLookupViaJoinTable(ctx Context, parent, child
 				ctx.WriteString(`":1`)
 			}
 		}
+		d.renderNestedLookupNames(ctx, child, qc, first)
 		ctx.WriteString(`}}`)
 	}
 
+	if hasEmbeddedChild {
+		d.renderLookupSortLimit(ctx, child)
+	}
+
 	ctx.WriteString(`]`)
 	ctx.WriteString(`,"as":"`)
 	ctx.WriteString(child.FieldName)
@@ -3282,7 +3537,7 @@ func (d *MongoDBDialect) renderProjectStageWithChildren(ctx Context, sel *qcode.
 			ctx.WriteString(`"`)
 		} else {
 			// Normal field - use projection shorthand
-			ctx.WriteString(`1`)
+			d.renderProjectValue(ctx, f, sourceCol)
 		}
 		first = false
 	}
@@ -3908,17 +4163,16 @@ func (d *MongoDBDialect) renderComparisonValue(ctx Context, exp *qcode.Exp) {
 		ctx.WriteString(`]}`)
 	case qcode.OpLike:
 		ctx.WriteString(`{"$regex":"`)
-		// Convert SQL LIKE pattern to regex
-		pattern := strings.ReplaceAll(exp.Right.Val, "%", ".*")
-		pattern = strings.ReplaceAll(pattern, "_", ".")
-		ctx.WriteString(escapeJSONString(pattern))
+		ctx.WriteString(escapeJSONString(likeToRegex(exp.Right.Val)))
 		ctx.WriteString(`"}`)
 	case qcode.OpILike:
 		ctx.WriteString(`{"$regex":"`)
-		pattern := strings.ReplaceAll(exp.Right.Val, "%", ".*")
-		pattern = strings.ReplaceAll(pattern, "_", ".")
-		ctx.WriteString(escapeJSONString(pattern))
+		ctx.WriteString(escapeJSONString(likeToRegex(exp.Right.Val)))
 		ctx.WriteString(`","$options":"i"}`)
+	case qcode.OpEqualsCI:
+		ctx.WriteString(`{"$regex":"^`)
+		ctx.WriteString(escapeJSONString(regexp.QuoteMeta(exp.Right.Val)))
+		ctx.WriteString(`$","$options":"i"}`)
 	case qcode.OpRegex:
 		ctx.WriteString(`{"$regex":`)
 		d.renderValue(ctx, exp)
@@ -3957,7 +4211,7 @@ func (d *MongoDBDialect) renderValue(ctx Context, exp *qcode.Exp) {
 		// Runtime parameter - wrap in quotes for valid JSON
 		// The driver will substitute the actual value
 		ctx.WriteString(`"`)
-		ctx.AddParam(Param{Name: exp.Right.Val, Type: "any"})
+		ctx.AddParam(Param{Name: exp.Right.Val, Type: paramType(exp)})
 		ctx.WriteString(`"`)
 	case qcode.ValNum:
 		ctx.WriteString(exp.Right.Val)
@@ -4042,11 +4296,36 @@ func (d *MongoDBDialect) renderSortStage(ctx Context, sel *qcode.Select) {
 		}
 	}
 
+	// Case-insensitive ordering sorts on a lower cased copy of the field
+	hasCIOrder := false
+	for _, ob := range sel.OrderBy {
+		if ob.CI && ob.Var == "" {
+			hasCIOrder = true
+			break
+		}
+	}
+
 	// If we have list-based ordering, first add $addFields stage to compute positions
-	if hasListOrder {
+	if hasListOrder || hasCIOrder {
 		ctx.WriteString(`{"$addFields":{`)
 		first := true
 		for _, ob := range sel.OrderBy {
+			if ob.CI && ob.Var == "" {
+				if !first {
+					ctx.WriteString(`,`)
+				}
+				first = false
+				colName := ob.Col.Name
+				if colName == "id" {
+					colName = "_id"
+				}
+				ctx.WriteString(`"__sort_ci_`)
+				ctx.WriteString(ob.Col.Name)
+				ctx.WriteString(`":{"$toLower":"$`)
+				ctx.WriteString(colName)
+				ctx.WriteString(`"}`)
+				continue
+			}
 			if ob.Var != "" {
 				if !first {
 					ctx.WriteString(`,`)
@@ -4082,6 +4361,9 @@ func (d *MongoDBDialect) renderSortStage(ctx Context, sel *qcode.Select) {
 			// Use computed position field for list-based ordering
 			ctx.WriteString(`__sort_pos_`)
 			ctx.WriteString(ob.Col.Name)
+		} else if ob.CI {
+			ctx.WriteString(`__sort_ci_`)
+			ctx.WriteString(ob.Col.Name)
 		} else {
 			colName := ob.Col.Name
 			// Translate "id" to "_id"
@@ -4116,11 +4398,27 @@ func (d *MongoDBDialect) renderProjectStage(ctx Context, sel *qcode.Select) {
 		}
 		ctx.WriteString(`"`)
 		ctx.WriteString(colName)
-		ctx.WriteString(`":1`)
+		ctx.WriteString(`":`)
+		d.renderProjectValue(ctx, f, colName)
 	}
 	ctx.WriteString(`}}`)
 }
 
+// renderProjectValue renders the projection of a field, fields returned as
+// strings like 64-bit integers are converted with $toString
+func (d *MongoDBDialect) renderProjectValue(ctx Context, f qcode.Field, col string) {
+	if !f.AsString {
+		ctx.WriteString(`1`)
+		return
+	}
+	if col == "id" {
+		col = "_id"
+	}
+	ctx.WriteString(`{"$toString":"$`)
+	ctx.WriteString(col)
+	ctx.WriteString(`"}`)
+}
+
 // renderEmbeddedJSONStage handles JSON virtual tables (RelEmbedded).
 // The data is already embedded in the parent document as an array.
 // We need to:
@@ -0,0 +1,139 @@
+package dialect
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestLikeToRegex(t *testing.T) {
+	tests := []struct {
+		like  string
+		regex string
+	}{
+		{"abc", "^abc$"},
+		{"abc%", "^abc"},
+		{"%abc", "abc$"},
+		{"%abc%", "abc"},
+		{"a_c", "^a.c$"},
+		{"a%%c", "^a.*c$"},
+		{"%", ""},
+		{"", "^$"},
+		{"50% off (sale)", `^50.* off \(sale\)$`},
+		{"a.b*c", `^a\.b\*c$`},
+		{`100\%`, `^100%$`},
+		{`a\_b%`, `^a_b`},
+	}
+
+	for _, tt := range tests {
+		if got := likeToRegex(tt.like); got != tt.regex {
+			t.Errorf("likeToRegex(%q) = %q, want %q", tt.like, got, tt.regex)
+		}
+	}
+}
+
+func TestLikeToRegexMatching(t *testing.T) {
+	re := regexp.MustCompile(likeToRegex("50% off (sale)"))
+
+	if !re.MatchString("50% off (sale)") {
+		t.Error("expected literal match")
+	}
+	if !re.MatchString("50 percent off (sale)") {
+		t.Error("expected wildcard match")
+	}
+	if re.MatchString("x50% off (sale)") || re.MatchString("50% off sale") {
+		t.Error("unexpected match")
+	}
+}
+
+type testContext struct {
+	sb strings.Builder
+}
+
+func (c *testContext) Write(s string) (int, error)       { return c.sb.WriteString(s) }
+func (c *testContext) WriteString(s string) (int, error) { return c.sb.WriteString(s) }
+func (c *testContext) String() string                    { return c.sb.String() }
+
+func (c *testContext) AddParam(p Param) string                   { return "" }
+func (c *testContext) Quote(s string)                            { c.WriteString(s) }
+func (c *testContext) ColWithTable(table, col string)            { c.WriteString(table + "." + col) }
+func (c *testContext) RenderJSONFields(sel *qcode.Select)        {}
+func (c *testContext) IsTableMutated(table string) bool          { return false }
+func (c *testContext) RenderExp(ti sdata.DBTable, ex *qcode.Exp) {}
+func (c *testContext) GetStaticVar(name string) (string, bool)   { return "", false }
+func (c *testContext) GetSecPrefix() string                      { return "" }
+
+func TestLookupStageGrandchildren(t *testing.T) {
+	users := sdata.DBTable{Name: "users"}
+	products := sdata.DBTable{Name: "products"}
+	purchases := sdata.DBTable{Name: "purchases"}
+
+	qc := &qcode.QCode{Selects: []qcode.Select{
+		{
+			Field:    qcode.Field{ID: 0, FieldName: "users"},
+			Table:    "users",
+			Fields:   []qcode.Field{{FieldName: "id", Col: sdata.DBColumn{Name: "id"}}},
+			Children: []int32{1},
+		},
+		{
+			Field:  qcode.Field{ID: 1, ParentID: 0, FieldName: "products"},
+			Table:  "products",
+			Fields: []qcode.Field{{FieldName: "name", Col: sdata.DBColumn{Name: "name"}}},
+			Rel: sdata.DBRel{
+				Type:  sdata.RelOneToMany,
+				Left:  sdata.DBRelLeft{Ti: users, Col: sdata.DBColumn{Name: "id"}},
+				Right: sdata.DBRelRight{Ti: products, Col: sdata.DBColumn{Name: "owner_id"}},
+			},
+			OrderBy:  []qcode.OrderBy{{Col: sdata.DBColumn{Name: "price"}, Order: qcode.OrderDesc}},
+			Paging:   qcode.Paging{Limit: 5},
+			Children: []int32{2},
+		},
+		{
+			Field:  qcode.Field{ID: 2, ParentID: 1, FieldName: "purchases"},
+			Table:  "purchases",
+			Fields: []qcode.Field{{FieldName: "quantity", Col: sdata.DBColumn{Name: "quantity"}}},
+			Rel: sdata.DBRel{
+				Type:  sdata.RelOneToMany,
+				Left:  sdata.DBRelLeft{Ti: products, Col: sdata.DBColumn{Name: "id"}},
+				Right: sdata.DBRelRight{Ti: purchases, Col: sdata.DBColumn{Name: "product_id"}},
+			},
+			Paging:   qcode.Paging{Limit: 2},
+			Children: []int32{3},
+		},
+		{
+			Field:  qcode.Field{ID: 3, ParentID: 2, FieldName: "customer"},
+			Table:  "users",
+			Fields: []qcode.Field{{FieldName: "email", Col: sdata.DBColumn{Name: "email"}}},
+			Rel: sdata.DBRel{
+				Type:  sdata.RelOneToOne,
+				Left:  sdata.DBRelLeft{Ti: users, Col: sdata.DBColumn{Name: "id"}},
+				Right: sdata.DBRelRight{Ti: purchases, Col: sdata.DBColumn{Name: "customer_id"}},
+			},
+			Paging: qcode.Paging{Limit: 1},
+		},
+	}}
+
+	var ctx testContext
+	d := &MongoDBDialect{}
+	d.renderLookupStageWithQC(&ctx, &qc.Selects[0], &qc.Selects[1], qc)
+
+	exp := `{"$lookup":{"from":"products","let":{"joinValue":"$_id"},"pipeline":[` +
+		`{"$match":{"$expr":{"$eq":["$owner_id","$$joinValue"]}}},` +
+		`{"$sort_ordered":[["price",-1]]},{"$limit":5},` +
+		`{"$lookup":{"from":"purchases","let":{"joinValue":"$_id"},"pipeline":[` +
+		`{"$match":{"$expr":{"$eq":["$product_id","$$joinValue"]}}},` +
+		`{"$sort_ordered":[["_id",1]]},{"$limit":2},` +
+		`{"$lookup":{"from":"users","let":{"joinValue":"$customer_id"},"pipeline":[` +
+		`{"$match":{"$expr":{"$eq":["$_id","$$joinValue"]}}},` +
+		`{"$sort_ordered":[["_id",1]]},{"$limit":1},` +
+		`{"$project":{"_id":0,"email":"$email"}}],"as":"customer"}},` +
+		`{"$project":{"_id":0,"quantity":"$quantity","customer":1}}],"as":"purchases"}},` +
+		`{"$project":{"_id":0,"name":"$name","purchases":1}}],"as":"products"}}`
+
+	if got := ctx.String(); got != exp {
+		t.Errorf("unexpected lookup stage:\n got: %s\nwant: %s", got, exp)
+	}
+}
@@ -145,6 +145,10 @@ func (d *MSSQLDialect) SupportsLateral() bool {
 	return false
 }
 
+func (d *MSSQLDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 // SupportsReturning returns true because MSSQL has OUTPUT clause.
 func (d *MSSQLDialect) SupportsReturning() bool {
 	return true
@@ -427,6 +431,10 @@ func (d *MSSQLDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 			ctx.WriteString(` AS NVARCHAR(MAX)) + ',', ',' + `)
 			ctx.AddParam(Param{Name: ob.Var, Type: "text"})
 			ctx.WriteString(` + ',')`)
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -456,12 +464,22 @@ func (d *MSSQLDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 	// MSSQL does not support DISTINCT ON
 }
 
+func (d *MSSQLDialect) RenderLock(ctx Context, sel *qcode.Select) {
+}
+
 func (d *MSSQLDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	// Use OPENJSON for embedded JSON columns
 	ctx.WriteString(`OPENJSON(`)
 	ctx.ColWithTable(sel.Rel.Left.Col.Table, sel.Rel.Left.Col.Name)
 	ctx.WriteString(`) WITH (`)
+	d.renderOpenJSONColumns(ctx, sel)
+	ctx.WriteString(`) AS `)
+	ctx.Quote(sel.Table)
+}
 
+// renderOpenJSONColumns renders the OPENJSON WITH column list of an embedded
+// json table. Nested json values are returned as json using AS JSON.
+func (d *MSSQLDialect) renderOpenJSONColumns(ctx Context, sel *qcode.Select) {
 	for i, col := range sel.Ti.Columns {
 		if i != 0 {
 			ctx.WriteString(`, `)
@@ -472,9 +490,10 @@ func (d *MSSQLDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 		ctx.WriteString(` '$.`)
 		ctx.WriteString(col.Name)
 		ctx.WriteString(`'`)
+		if col.Type == "json" || col.Type == "jsonb" {
+			ctx.WriteString(` AS JSON`)
+		}
 	}
-	ctx.WriteString(`) AS `)
-	ctx.Quote(sel.Table)
 }
 
 // RenderJSONPath renders JSON path extraction for MSSQL.
@@ -950,10 +969,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 		d.RenderJoinTables(ctx, sel)
 
 		// Render WHERE clause
-		if sel.Where.Exp != nil {
-			ctx.WriteString(` WHERE `)
-			d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
-		}
+		d.renderWhere(ctx, r, psel, sel)
 		d.renderGroupBy(ctx, r, sel)
 		// Close the singular select with FOR JSON PATH
 		ctx.WriteString(` FOR JSON PATH, INCLUDE_NULL_VALUES, WITHOUT_ARRAY_WRAPPER)`)
@@ -976,10 +992,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 				d.RenderTableAlias(ctx, t)
 			}
 			// Render WHERE clause for filtering
-			if sel.Where.Exp != nil {
-				ctx.WriteString(` WHERE `)
-				d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
-			}
+			d.renderWhere(ctx, r, psel, sel)
 			ctx.WriteString(`)`)
 		} else {
 			// Correlated subquery - use FOR JSON PATH to produce array
@@ -1004,10 +1017,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 			d.RenderJoinTables(ctx, sel)
 
 			// Render WHERE clause
-			if sel.Where.Exp != nil {
-				ctx.WriteString(` WHERE `)
-				d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
-			}
+			d.renderWhere(ctx, r, psel, sel)
 			d.renderGroupBy(ctx, r, sel)
 
 			// Add ORDER BY if needed
@@ -1021,7 +1031,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 					if sel.ID >= 0 {
 						t = fmt.Sprintf("%s_%d", t, sel.ID)
 					}
-					r.ColWithTable(t, ob.Col.Name)
+					renderInlineOrderCol(ctx, r, ob, t, ob.Col.Name)
 					switch ob.Order {
 					case qcode.OrderAsc:
 						ctx.WriteString(` ASC`)
@@ -1064,10 +1074,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 					d.RenderTableAlias(ctx, t)
 				}
 				// Render WHERE clause for filtering
-				if sel.Where.Exp != nil {
-					ctx.WriteString(` WHERE `)
-					d.renderWhereExp(ctx, r, nil, sel, sel.Where.Exp)
-				}
+				d.renderWhere(ctx, r, nil, sel)
 				// Render ORDER BY and OFFSET/FETCH inside the subquery
 				d.renderOrderBy(ctx, r, sel, "")
 				if sel.Paging.Limit != 0 {
@@ -1106,10 +1113,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 				}
 				d.RenderJoinTables(ctx, sel)
 				// Render WHERE clause
-				if sel.Where.Exp != nil {
-					ctx.WriteString(` WHERE `)
-					d.renderWhereExp(ctx, r, nil, sel, sel.Where.Exp)
-				}
+				d.renderWhere(ctx, r, nil, sel)
 				d.renderGroupBy(ctx, r, sel)
 				d.renderOrderBy(ctx, r, sel, "")
 				if sel.Paging.Limit != 0 {
@@ -1142,10 +1146,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 						d.renderJoinWithAlias(ctx, r, nil, sel, join)
 					}
 					d.RenderJoinTables(ctx, sel)
-					if sel.Where.Exp != nil {
-						ctx.WriteString(` WHERE `)
-						d.renderWhereExp(ctx, r, nil, sel, sel.Where.Exp)
-					}
+					d.renderWhere(ctx, r, nil, sel)
 					// Use the FULL ORDER BY clause (all columns) to ensure we get values from the same row
 					// This is critical: if ORDER BY is (price DESC, id ASC), we must order by BOTH
 					// to get the correct row, not just order by the current column
@@ -1195,10 +1196,7 @@ func (d *MSSQLDialect) RenderInlineChild(ctx Context, r InlineChildRenderer, pse
 				d.RenderJoinTables(ctx, sel)
 
 				// Render WHERE clause
-				if sel.Where.Exp != nil {
-					ctx.WriteString(` WHERE `)
-					d.renderWhereExp(ctx, r, nil, sel, sel.Where.Exp)
-				}
+				d.renderWhere(ctx, r, nil, sel)
 				d.renderGroupBy(ctx, r, sel)
 
 				// Render ORDER BY
@@ -1238,6 +1236,9 @@ func (d *MSSQLDialect) renderRecursiveInlineChild(ctx Context, r InlineChildRend
 	if sel.Paging.Limit > 0 && int(sel.Paging.Limit) < maxDepth {
 		maxDepth = int(sel.Paging.Limit)
 	}
+	if sel.MaxDepth > 0 && int(sel.MaxDepth) < maxDepth {
+		maxDepth = int(sel.MaxDepth)
+	}
 
 	// 3. Build table aliases
 	t := sel.Ti.Name
@@ -1458,7 +1459,7 @@ func (d *MSSQLDialect) renderInlineJSONFields(ctx Context, r InlineChildRenderer
 			if isJSON {
 				ctx.WriteString(`JSON_QUERY(`)
 			}
-			r.ColWithTable(t, f.Col.Name)
+			r.RenderColumnValue(f, func() { r.ColWithTable(t, f.Col.Name) })
 			if isJSON {
 				ctx.WriteString(`, '$')`)
 			}
@@ -1622,7 +1623,7 @@ func (d *MSSQLDialect) renderBaseColumns(ctx Context, r InlineChildRenderer, sel
 			}
 			ctx.WriteString(`)`)
 		} else {
-			r.ColWithTable(t, f.Col.Name)
+			r.RenderColumnValue(f, func() { r.ColWithTable(t, f.Col.Name) })
 		}
 
 		if f.FieldFilter.Exp != nil {
@@ -1656,7 +1657,7 @@ func (d *MSSQLDialect) renderBaseColumns(ctx Context, r InlineChildRenderer, sel
 				t = fmt.Sprintf("%s_0", t)
 			}
 		}
-		r.ColWithTable(t, col)
+		renderInlineOrderCol(ctx, r, ob, t, col)
 		ctx.WriteString(fmt.Sprintf(` AS [_ord_%d]`, j))
 	}
 
@@ -1687,6 +1688,72 @@ func (d *MSSQLDialect) renderBaseColumns(ctx Context, r InlineChildRenderer, sel
 
 }
 
+// renderWhere renders the WHERE clause of an inline select, adding the
+// distinct filter when the select has distinct columns
+func (d *MSSQLDialect) renderWhere(ctx Context, r InlineChildRenderer, psel, sel *qcode.Select) {
+	if !distinctFilterNeeded(sel) {
+		if sel.Where.Exp != nil {
+			ctx.WriteString(` WHERE `)
+			d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+		}
+		return
+	}
+
+	ctx.WriteString(` WHERE `)
+	if sel.Where.Exp != nil {
+		ctx.WriteString(`(`)
+		d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+		ctx.WriteString(`) AND `)
+	}
+	d.renderDistinctFilter(ctx, r, psel, sel)
+}
+
+// renderDistinctFilter keeps the first row of every distinct group, MSSQL
+// has no DISTINCT ON so primary keys are matched against a ROW_NUMBER()
+// partition over the distinct columns
+func (d *MSSQLDialect) renderDistinctFilter(ctx Context, r InlineChildRenderer, psel, sel *qcode.Select) {
+	t := sel.Ti.Name
+	if sel.ID >= 0 {
+		t = fmt.Sprintf("%s_%d", t, sel.ID)
+	}
+	pk := sel.Ti.PrimaryCol.Name
+
+	r.ColWithTable(t, pk)
+	ctx.WriteString(` IN (SELECT `)
+	r.ColWithTable(`__gj_d`, pk)
+	ctx.WriteString(` FROM (SELECT `)
+	r.ColWithTable(t, pk)
+	ctx.WriteString(`, ROW_NUMBER() OVER (PARTITION BY `)
+	for i, col := range sel.DistinctOn {
+		if i != 0 {
+			ctx.WriteString(`, `)
+		}
+		r.ColWithTable(t, col.Name)
+	}
+	d.renderOrderBy(ctx, r, sel, "")
+	ctx.WriteString(`) AS `)
+	ctx.Quote(`__gj_rn`)
+	ctx.WriteString(` FROM `)
+	d.renderFromTable(ctx, r, sel, psel)
+	d.RenderTableAlias(ctx, t)
+	if sel.Paging.Cursor {
+		ctx.WriteString(`, [__cur]`)
+	}
+	for _, join := range sel.Joins {
+		d.renderJoinWithAlias(ctx, r, psel, sel, join)
+	}
+	d.RenderJoinTables(ctx, sel)
+	if sel.Where.Exp != nil {
+		ctx.WriteString(` WHERE `)
+		d.renderWhereExp(ctx, r, psel, sel, sel.Where.Exp)
+	}
+	ctx.WriteString(`)`)
+	d.RenderTableAlias(ctx, `__gj_d`)
+	ctx.WriteString(`WHERE `)
+	r.ColWithTable(`__gj_d`, `__gj_rn`)
+	ctx.WriteString(` = 1)`)
+}
+
 func (d *MSSQLDialect) renderFromTable(ctx Context, r InlineChildRenderer, sel *qcode.Select, psel *qcode.Select) {
 	if sel.Rel.Type == sdata.RelEmbedded {
 		// Use OPENJSON for embedded JSON columns
@@ -1699,17 +1766,7 @@ func (d *MSSQLDialect) renderFromTable(ctx Context, r InlineChildRenderer, sel *
 		ctx.WriteString(`.`)
 		ctx.Quote(sel.Rel.Left.Col.Name)
 		ctx.WriteString(`) WITH (`)
-		for i, col := range sel.Ti.Columns {
-			if i != 0 {
-				ctx.WriteString(`, `)
-			}
-			ctx.Quote(col.Name)
-			ctx.WriteString(` `)
-			ctx.WriteString(d.mssqlType(col.Type))
-			ctx.WriteString(` '$.`)
-			ctx.WriteString(col.Name)
-			ctx.WriteString(`'`)
-		}
+		d.renderOpenJSONColumns(ctx, sel)
 		ctx.WriteString(`) AS `)
 		t := sel.Ti.Name
 		if sel.ID >= 0 {
@@ -1813,7 +1870,7 @@ func (d *MSSQLDialect) renderOrderBy(ctx Context, r InlineChildRenderer, sel *qc
 				t = fmt.Sprintf("%s_0", t)
 			}
 		}
-		r.ColWithTable(t, col)
+		renderInlineOrderCol(ctx, r, ob, t, col)
 
 		if ob.KeyVar != "" && ob.Key != "" {
 			ctx.WriteString(` END`)
@@ -1838,6 +1895,9 @@ func (d *MSSQLDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel *
 	}
 
 	switch ex.Op {
+	case qcode.OpNamedFilter:
+		renderInlineNamedFilter(ctx, r, sel, ex)
+
 	case qcode.OpNop:
 		// No-op - don't render anything
 		return
@@ -1895,6 +1955,13 @@ func (d *MSSQLDialect) renderExp(ctx Context, r InlineChildRenderer, psel, sel *
 		d.renderValue(ctx, r, psel, sel, ex)
 		ctx.WriteString(`)`)
 
+	case qcode.OpEqualsCI:
+		ctx.WriteString(`(LOWER(`)
+		d.renderColumn(ctx, r, psel, sel, ex)
+		ctx.WriteString(`) = LOWER(`)
+		d.renderValue(ctx, r, psel, sel, ex)
+		ctx.WriteString(`))`)
+
 	case qcode.OpLike, qcode.OpNotLike, qcode.OpILike, qcode.OpNotILike:
 		ctx.WriteString(`(`)
 		d.renderColumn(ctx, r, psel, sel, ex)
@@ -2167,6 +2234,13 @@ func (d *MSSQLDialect) renderExistsExp(ctx Context, r InlineChildRenderer, psel,
 		ctx.WriteString(`NOT `)
 		d.renderExistsExp(ctx, r, psel, sel, ex.Children[0], relatedTable, relatedAlias)
 
+	case qcode.OpEqualsCI:
+		ctx.WriteString(`(LOWER(`)
+		d.renderExistsColumn(ctx, r, psel, sel, ex, relatedTable, relatedAlias)
+		ctx.WriteString(`) = LOWER(`)
+		d.renderExistsValue(ctx, r, psel, sel, ex, relatedTable, relatedAlias)
+		ctx.WriteString(`))`)
+
 	default:
 		// For other operators, render column = value with proper aliasing
 		ctx.WriteString(`(`)
@@ -2391,6 +2465,11 @@ func (d *MSSQLDialect) renderValue(ctx Context, r InlineChildRenderer, psel, sel
 		ctx.WriteString(`'`)
 	case qcode.ValList:
 		d.RenderList(ctx, ex)
+	case qcode.ValNowMinus:
+		// Duration operators, the seconds are parsed by the qcode compiler
+		ctx.WriteString(`DATEADD(second, -`)
+		ctx.WriteString(ex.Right.Val)
+		ctx.WriteString(`, GETDATE())`)
 	default:
 		ctx.WriteString(ex.Right.Val)
 	}
@@ -2843,6 +2922,20 @@ func (d *MSSQLDialect) RenderSetSessionVar(ctx Context, name, value string) bool
 	return false
 }
 
+// RenderSavepoint renders the SQL to create a savepoint in MSSQL
+func (d *MSSQLDialect) RenderSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`SAVE TRANSACTION `)
+	ctx.WriteString(name)
+	return true
+}
+
+// RenderRollbackToSavepoint renders the SQL to roll back to a savepoint in MSSQL
+func (d *MSSQLDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`ROLLBACK TRANSACTION `)
+	ctx.WriteString(name)
+	return true
+}
+
 func (d *MSSQLDialect) RenderLinearInsert(ctx Context, m *qcode.Mutate, qc *qcode.QCode, varName string, renderColVal func(qcode.MColumn)) {
 	// For linear execution, we don't use OUTPUT INSERTED.* because we need to capture
 	// the ID into a variable using SCOPE_IDENTITY()
@@ -3659,6 +3752,27 @@ func (d *MSSQLDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Selec
 	return false
 }
 
+func (d *MSSQLDialect) RenderRecursivePath(ctx Context, path, key func()) {
+	// Anchor and recursive members must have the exact same column type
+	ctx.WriteString(`CAST(`)
+	if path != nil {
+		path()
+	} else {
+		ctx.WriteString(`','`)
+	}
+	ctx.WriteString(` + CAST(`)
+	key()
+	ctx.WriteString(` AS NVARCHAR(MAX)) + ',' AS NVARCHAR(MAX))`)
+}
+
+func (d *MSSQLDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {
+	ctx.WriteString(`CHARINDEX(',' + CAST(`)
+	key()
+	ctx.WriteString(` AS NVARCHAR(MAX)) + ',', `)
+	path()
+	ctx.WriteString(`) = 0`)
+}
+
 // JSON Null Fields
 func (d *MSSQLDialect) RenderJSONNullField(ctx Context, fieldName string) {
 	ctx.WriteString(`NULL AS `)
@@ -3674,6 +3788,19 @@ func (d *MSSQLDialect) RenderJSONRootSuffix(ctx Context) {
 	ctx.WriteString(` FOR JSON PATH, INCLUDE_NULL_VALUES, WITHOUT_ARRAY_WRAPPER`)
 }
 
+func (d *MSSQLDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	val()
+	ctx.WriteString(` AS `)
+	ctx.Quote(key)
+}
+
+func (d *MSSQLDialect) RenderExists(ctx Context, renderQuery func()) {
+	// FOR JSON PATH renders BIT values as JSON booleans
+	ctx.WriteString(`CAST(CASE WHEN EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`) THEN 1 ELSE 0 END AS BIT)`)
+}
+
 // Array Operations
 func (d *MSSQLDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`(SELECT JSON_ARRAYAGG(`)
@@ -3709,3 +3836,31 @@ func (d *MSSQLDialect) RequiresJSONQueryWrapper() bool {
 func (d *MSSQLDialect) RequiresNullOnEmptySelect() bool {
 	return false // MSSQL doesn't need NULL when no columns rendered
 }
+
+func (d *MSSQLDialect) RenderAsString(ctx Context, val func()) {
+	ctx.WriteString(`CAST(`)
+	val()
+	ctx.WriteString(` AS NVARCHAR(100))`)
+}
+
+func (d *MSSQLDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`(`)
+	if withTZ {
+		val()
+	} else {
+		ctx.WriteString(`(`)
+		val()
+		ctx.WriteString(` AT TIME ZONE 'UTC')`)
+	}
+	ctx.WriteString(` AT TIME ZONE `)
+	tz()
+	ctx.WriteString(`)`)
+}
+
+func (d *MSSQLDialect) RenderDuration(ctx Context, val func()) {
+	val()
+}
+
+func (d *MSSQLDialect) RenderDurationValue(ctx Context, val func()) {
+	val()
+}
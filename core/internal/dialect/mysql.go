@@ -228,6 +228,10 @@ func (d *MySQLDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 			ctx.WriteString(`, (SELECT GROUP_CONCAT(id) FROM JSON_TABLE(`)
 			ctx.AddParam(Param{Name: ob.Var, Type: "text"})
 			ctx.WriteString(`, '$[*]' COLUMNS (id ` + ob.Col.Type + ` PATH '$')) AS a))`)
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -248,24 +252,74 @@ func (d *MySQLDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 	// MySQL does not support DISTINCT ON
 }
 
+func (d *MySQLDialect) RenderLock(ctx Context, sel *qcode.Select) {
+	switch sel.Lock.Mode {
+	case qcode.LockUpdate:
+		ctx.WriteString(` FOR UPDATE OF `)
+	case qcode.LockShare:
+		ctx.WriteString(` FOR SHARE OF `)
+	default:
+		return
+	}
+	ctx.Quote(sel.Table)
+
+	if sel.Lock.SkipLocked {
+		ctx.WriteString(` SKIP LOCKED`)
+	} else if sel.Lock.NoWait {
+		ctx.WriteString(` NOWAIT`)
+	}
+}
+
 func (d *MySQLDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	ctx.WriteString(`JSON_TABLE(`)
 	ctx.ColWithTable(sel.Rel.Left.Col.Table, sel.Rel.Left.Col.Name)
 	ctx.WriteString(`, '$[*]' COLUMNS(`)
+	renderJSONTableColumns(ctx, sel)
+	ctx.WriteString(`)) AS `)
+	ctx.Quote(sel.Table)
+}
 
+// renderJSONTableColumns renders the JSON_TABLE column list of an embedded
+// json table, shared by MySQL and MariaDB
+func renderJSONTableColumns(ctx Context, sel *qcode.Select) {
 	for i, col := range sel.Ti.Columns {
 		if i != 0 {
 			ctx.WriteString(`, `)
 		}
-		ctx.WriteString(col.Name)
+		ctx.Quote(col.Name)
 		ctx.WriteString(` `)
-		ctx.WriteString(col.Type)
+		ctx.WriteString(jsonTableType(col.Type))
 		ctx.WriteString(` PATH '$.`)
 		ctx.WriteString(col.Name)
 		ctx.WriteString(`' ERROR ON ERROR`)
 	}
-	ctx.WriteString(`)) AS`)
-	ctx.Quote(sel.Table)
+}
+
+// jsonTableType maps the configured column type to a JSON_TABLE column
+// type so the same json table config works across databases
+func jsonTableType(t string) string {
+	switch strings.ToLower(t) {
+	case "text", "string", "character varying", "varchar", "longtext", "mediumtext":
+		return "text"
+	case "json", "jsonb":
+		return "json"
+	case "int", "integer", "int4":
+		return "int"
+	case "int8", "bigint":
+		return "bigint"
+	case "int2", "smallint":
+		return "smallint"
+	case "float", "float4", "real":
+		return "float"
+	case "float8", "double", "double precision":
+		return "double"
+	case "bool", "boolean":
+		return "boolean"
+	case "timestamp", "timestamptz", "timestamp without time zone", "timestamp with time zone":
+		return "datetime"
+	default:
+		return t
+	}
 }
 
 func (d *MySQLDialect) RenderJSONPath(ctx Context, table, col string, path []string) {
@@ -697,6 +751,10 @@ func (d *MySQLDialect) SupportsLateral() bool {
 	return true
 }
 
+func (d *MySQLDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 // RenderInlineChild is not used for MySQL since it supports LATERAL joins
 func (d *MySQLDialect) RenderInlineChild(ctx Context, renderer InlineChildRenderer, psel, sel *qcode.Select) {
 	// MySQL uses LATERAL joins, so this is not called
@@ -1007,6 +1065,20 @@ func (d *MySQLDialect) RenderSetSessionVar(ctx Context, name, value string) bool
 	return true
 }
 
+// RenderSavepoint renders the SQL to create a savepoint in MySQL
+func (d *MySQLDialect) RenderSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
+// RenderRollbackToSavepoint renders the SQL to roll back to a savepoint in MySQL
+func (d *MySQLDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`ROLLBACK TO SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
 // Helper to join path for MySQL
 func joinPathMySQL(ctx Context, prefix string, path []string, enableCamelcase bool) {
 	ctx.WriteString(prefix)
@@ -1674,6 +1746,28 @@ func (d *MySQLDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Selec
 	return false // MySQL supports outer scope correlation in CTEs
 }
 
+func (d *MySQLDialect) RenderRecursivePath(ctx Context, path, key func()) {
+	// MySQL sizes recursive CTE columns from the anchor row so the
+	// path needs an explicit width
+	ctx.WriteString(`CAST(CONCAT(`)
+	if path != nil {
+		path()
+	} else {
+		ctx.WriteString(`','`)
+	}
+	ctx.WriteString(`, `)
+	key()
+	ctx.WriteString(`, ',') AS CHAR(4000))`)
+}
+
+func (d *MySQLDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {
+	ctx.WriteString(`INSTR(`)
+	path()
+	ctx.WriteString(`, CONCAT(',', `)
+	key()
+	ctx.WriteString(`, ',')) = 0`)
+}
+
 // JSON Null Fields
 func (d *MySQLDialect) RenderJSONNullField(ctx Context, fieldName string) {
 	ctx.WriteString(`'`)
@@ -1691,6 +1785,19 @@ func (d *MySQLDialect) RenderJSONRootSuffix(ctx Context) {
 	// MySQL doesn't need any suffix
 }
 
+func (d *MySQLDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	ctx.WriteString(`'`)
+	ctx.WriteString(key)
+	ctx.WriteString(`', `)
+	val()
+}
+
+func (d *MySQLDialect) RenderExists(ctx Context, renderQuery func()) {
+	ctx.WriteString(`IF(EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`), CAST('true' AS JSON), CAST('false' AS JSON))`)
+}
+
 // Array Operations
 func (d *MySQLDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`(SELECT JSON_ARRAYAGG(`)
@@ -1727,3 +1834,26 @@ func (d *MySQLDialect) RequiresJSONQueryWrapper() bool {
 func (d *MySQLDialect) RequiresNullOnEmptySelect() bool {
 	return true // MySQL needs NULL when no columns rendered
 }
+
+func (d *MySQLDialect) RenderAsString(ctx Context, val func()) {
+	ctx.WriteString(`CAST(`)
+	val()
+	ctx.WriteString(` AS CHAR)`)
+}
+
+// RenderTimezone converts from UTC since MySQL timestamps carry no timezone
+func (d *MySQLDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`CONVERT_TZ(`)
+	val()
+	ctx.WriteString(`, '+00:00', `)
+	tz()
+	ctx.WriteString(`)`)
+}
+
+func (d *MySQLDialect) RenderDuration(ctx Context, val func()) {
+	val()
+}
+
+func (d *MySQLDialect) RenderDurationValue(ctx Context, val func()) {
+	val()
+}
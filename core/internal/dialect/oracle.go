@@ -237,6 +237,10 @@ func (d *OracleDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 			ctx.WriteString(`"_GJ_OB_`)
 			ctx.WriteString(strings.ToUpper(ob.Col.Name))
 			ctx.WriteString(`"."ORD"`)
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -264,6 +268,9 @@ func (d *OracleDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 	// Oracle doesn't support DISTINCT ON
 }
 
+func (d *OracleDialect) RenderLock(ctx Context, sel *qcode.Select) {
+}
+
 func (d *OracleDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	ctx.WriteString(`JSON_TABLE(`)
 	ctx.ColWithTable(sel.Rel.Left.Col.Table, sel.Rel.Left.Col.Name)
@@ -708,6 +715,10 @@ func (d *OracleDialect) SupportsLateral() bool {
 	return true
 }
 
+func (d *OracleDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 // RenderInlineChild is not used for Oracle since it supports LATERAL joins
 func (d *OracleDialect) RenderInlineChild(ctx Context, renderer InlineChildRenderer, psel, sel *qcode.Select) {
 	// Oracle uses LATERAL joins, so this is not called
@@ -1063,6 +1074,20 @@ func (d *OracleDialect) RenderSetSessionVar(ctx Context, name, value string) boo
 	return true
 }
 
+// RenderSavepoint renders the SQL to create a savepoint in Oracle
+func (d *OracleDialect) RenderSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
+// RenderRollbackToSavepoint renders the SQL to roll back to a savepoint in Oracle
+func (d *OracleDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`ROLLBACK TO SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
 func (d *OracleDialect) RenderArray(ctx Context, items []string) {
 	// Oracle has no direct array literal syntax simple enough for this context, 
 	// unless PL/SQL or type constructor.
@@ -1635,6 +1660,26 @@ func (d *OracleDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Sele
 	return false
 }
 
+func (d *OracleDialect) RenderRecursivePath(ctx Context, path, key func()) {
+	ctx.WriteString(`CAST(`)
+	if path != nil {
+		path()
+	} else {
+		ctx.WriteString(`','`)
+	}
+	ctx.WriteString(` || `)
+	key()
+	ctx.WriteString(` || ',' AS VARCHAR2(4000))`)
+}
+
+func (d *OracleDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {
+	ctx.WriteString(`INSTR(`)
+	path()
+	ctx.WriteString(`, ',' || `)
+	key()
+	ctx.WriteString(` || ',') = 0`)
+}
+
 // JSON Null Fields
 func (d *OracleDialect) RenderJSONNullField(ctx Context, fieldName string) {
 	ctx.WriteString(`KEY '`)
@@ -1652,6 +1697,19 @@ func (d *OracleDialect) RenderJSONRootSuffix(ctx Context) {
 	// Oracle doesn't need any suffix
 }
 
+func (d *OracleDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	ctx.WriteString(`KEY '`)
+	ctx.WriteString(key)
+	ctx.WriteString(`' VALUE `)
+	val()
+}
+
+func (d *OracleDialect) RenderExists(ctx Context, renderQuery func()) {
+	ctx.WriteString(`CASE WHEN EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`) THEN 'true' ELSE 'false' END FORMAT JSON`)
+}
+
 // Array Operations
 func (d *OracleDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`(SELECT JSON_ARRAYAGG(`)
@@ -1686,3 +1744,33 @@ func (d *OracleDialect) RequiresJSONQueryWrapper() bool {
 func (d *OracleDialect) RequiresNullOnEmptySelect() bool {
 	return true // Oracle needs NULL when no columns rendered to avoid empty JSON_OBJECT()
 }
+
+func (d *OracleDialect) RenderAsString(ctx Context, val func()) {
+	ctx.WriteString(`TO_CHAR(`)
+	val()
+	ctx.WriteString(`)`)
+}
+
+func (d *OracleDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`(`)
+	if withTZ {
+		val()
+	} else {
+		ctx.WriteString(`FROM_TZ(CAST(`)
+		val()
+		ctx.WriteString(` AS TIMESTAMP), 'UTC')`)
+	}
+	ctx.WriteString(` AT TIME ZONE `)
+	tz()
+	ctx.WriteString(`)`)
+}
+
+func (d *OracleDialect) RenderDuration(ctx Context, val func()) {
+	val()
+}
+
+func (d *OracleDialect) RenderDurationValue(ctx Context, val func()) {
+	ctx.WriteString(`TO_DSINTERVAL(`)
+	val()
+	ctx.WriteString(`)`)
+}
@@ -145,6 +145,10 @@ func (d *PostgresDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 		}
 		if ob.Var != "" {
 			ctx.ColWithTable(`_gj_ob_`+ob.Col.Name, "ord")
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -183,6 +187,26 @@ func (d *PostgresDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 	ctx.WriteString(`) `)
 }
 
+// RenderLock renders the FOR UPDATE / FOR SHARE locking clause, OF limits
+// the lock to the selected table and not the tables joined for filtering
+func (d *PostgresDialect) RenderLock(ctx Context, sel *qcode.Select) {
+	switch sel.Lock.Mode {
+	case qcode.LockUpdate:
+		ctx.WriteString(` FOR UPDATE OF `)
+	case qcode.LockShare:
+		ctx.WriteString(` FOR SHARE OF `)
+	default:
+		return
+	}
+	ctx.Quote(sel.Table)
+
+	if sel.Lock.SkipLocked {
+		ctx.WriteString(` SKIP LOCKED`)
+	} else if sel.Lock.NoWait {
+		ctx.WriteString(` NOWAIT`)
+	}
+}
+
 func (d *PostgresDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	// jsonb_to_recordset
 	ctx.WriteString(sel.Ti.Type)
@@ -611,6 +635,10 @@ func (d *PostgresDialect) SupportsLateral() bool {
 	return true
 }
 
+func (d *PostgresDialect) SupportsDistinctOn() bool {
+	return true
+}
+
 // RenderInlineChild is not used for PostgreSQL since it supports LATERAL joins
 func (d *PostgresDialect) RenderInlineChild(ctx Context, renderer InlineChildRenderer, psel, sel *qcode.Select) {
 	// PostgreSQL uses LATERAL joins, so this is not called
@@ -856,6 +884,20 @@ func (d *PostgresDialect) RenderSetSessionVar(ctx Context, name, value string) b
 	return true
 }
 
+// RenderSavepoint renders the SQL to create a savepoint in Postgres
+func (d *PostgresDialect) RenderSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
+// RenderRollbackToSavepoint renders the SQL to roll back to a savepoint in Postgres
+func (d *PostgresDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`ROLLBACK TO SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
 // Helper to join path for Postgres
 func joinPathPostgres(ctx Context, prefix string, path []string, enableCamelcase bool) {
 	ctx.WriteString(prefix)
@@ -945,6 +987,26 @@ func (d *PostgresDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Se
 	return false // PostgreSQL supports outer scope correlation in CTEs
 }
 
+func (d *PostgresDialect) RenderRecursivePath(ctx Context, path, key func()) {
+	ctx.WriteString(`(`)
+	if path != nil {
+		path()
+	} else {
+		ctx.WriteString(`','`)
+	}
+	ctx.WriteString(` || CAST(`)
+	key()
+	ctx.WriteString(` AS text) || ',')`)
+}
+
+func (d *PostgresDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {
+	ctx.WriteString(`POSITION((',' || CAST(`)
+	key()
+	ctx.WriteString(` AS text) || ',') IN `)
+	path()
+	ctx.WriteString(`) = 0`)
+}
+
 // JSON Null Fields
 func (d *PostgresDialect) RenderJSONNullField(ctx Context, fieldName string) {
 	ctx.WriteString(`'`)
@@ -962,6 +1024,19 @@ func (d *PostgresDialect) RenderJSONRootSuffix(ctx Context) {
 	// PostgreSQL doesn't need any suffix
 }
 
+func (d *PostgresDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	ctx.WriteString(`'`)
+	ctx.WriteString(key)
+	ctx.WriteString(`', `)
+	val()
+}
+
+func (d *PostgresDialect) RenderExists(ctx Context, renderQuery func()) {
+	ctx.WriteString(`EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`)`)
+}
+
 // Array Operations
 func (d *PostgresDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`ARRAY(SELECT `)
@@ -996,3 +1071,45 @@ func (d *PostgresDialect) RequiresNullOnEmptySelect() bool {
 	return false // PostgreSQL doesn't need NULL when no columns rendered
 }
 
+func (d *PostgresDialect) RenderAsString(ctx Context, val func()) {
+	val()
+	ctx.WriteString(`::text`)
+}
+
+func (d *PostgresDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`(`)
+	if withTZ {
+		val()
+	} else {
+		ctx.WriteString(`(`)
+		val()
+		ctx.WriteString(` AT TIME ZONE 'UTC')`)
+	}
+	ctx.WriteString(` AT TIME ZONE `)
+	tz()
+	ctx.WriteString(`)`)
+}
+
+// RenderDuration renders the interval as an ISO-8601 duration like
+// P0Y0M1DT2H30M0S
+func (d *PostgresDialect) RenderDuration(ctx Context, val func()) {
+	parts := [...][2]string{
+		{"YEAR", "Y"}, {"MONTH", "M"}, {"DAY", "DT"}, {"HOUR", "H"}, {"MINUTE", "M"},
+	}
+	ctx.WriteString(`('P'`)
+	for _, p := range parts {
+		ctx.WriteString(` || EXTRACT(` + p[0] + ` FROM `)
+		val()
+		ctx.WriteString(`)::int || '` + p[1] + `'`)
+	}
+	ctx.WriteString(` || EXTRACT(SECOND FROM `)
+	val()
+	ctx.WriteString(`)::float8 || 'S')`)
+}
+
+func (d *PostgresDialect) RenderDurationValue(ctx Context, val func()) {
+	ctx.WriteString(`CAST(`)
+	val()
+	ctx.WriteString(` AS interval)`)
+}
+
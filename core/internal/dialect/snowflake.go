@@ -102,10 +102,23 @@ func (d *SnowflakeDialect) RenderJSONRootSuffix(ctx Context) {
 	ctx.WriteString(`) AS VARCHAR`)
 }
 
+func (d *SnowflakeDialect) RenderExists(ctx Context, renderQuery func()) {
+	// Snowflake only allows EXISTS in the WHERE clause
+	ctx.WriteString(`((SELECT COUNT(*) FROM (`)
+	renderQuery()
+	ctx.WriteString(` LIMIT 1)) > 0)`)
+}
+
 func (d *SnowflakeDialect) SupportsLateral() bool {
 	return false
 }
 
+// SupportsDistinctOn returns false because Snowflake has no DISTINCT ON,
+// distinct rows are picked with ROW_NUMBER() instead.
+func (d *SnowflakeDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 func (d *SnowflakeDialect) RenderInlineChild(ctx Context, renderer InlineChildRenderer, psel, sel *qcode.Select) {
 	renderer.RenderDefaultInlineChild(sel)
 }
@@ -191,6 +204,10 @@ func (d *SnowflakeDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 		}
 		if ob.Var != "" {
 			ctx.ColWithTable(`_gj_ob_`+ob.Col.Name, "ord")
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -215,6 +232,15 @@ func (d *SnowflakeDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 	}
 }
 
+// RenderDistinctOn renders nothing as Snowflake has no DISTINCT ON,
+// the compiler filters distinct rows with ROW_NUMBER() instead.
+func (d *SnowflakeDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
+}
+
+// RenderLock renders nothing as Snowflake has no row level locks
+func (d *SnowflakeDialect) RenderLock(ctx Context, sel *qcode.Select) {
+}
+
 func (d *SnowflakeDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	ctx.WriteString(`(SELECT `)
 	for i, col := range sel.Ti.Columns {
@@ -536,6 +562,15 @@ func (d *SnowflakeDialect) SupportsLinearExecution() bool {
 	return true
 }
 
+// Snowflake does not support savepoints
+func (d *SnowflakeDialect) RenderSavepoint(ctx Context, name string) bool {
+	return false
+}
+
+func (d *SnowflakeDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	return false
+}
+
 func (d *SnowflakeDialect) RenderSetup(ctx Context) {
 	ctx.WriteString(`DROP TABLE IF EXISTS `)
 	ctx.WriteString(d.idsTableName(ctx))
@@ -1129,6 +1164,23 @@ func (d *SnowflakeDialect) RequiresNullOnEmptySelect() bool {
 	return true
 }
 
+func (d *SnowflakeDialect) RenderAsString(ctx Context, val func()) {
+	ctx.WriteString(`TO_VARCHAR(`)
+	val()
+	ctx.WriteString(`)`)
+}
+
+func (d *SnowflakeDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`CONVERT_TIMEZONE(`)
+	if !withTZ {
+		ctx.WriteString(`'UTC', `)
+	}
+	tz()
+	ctx.WriteString(`, `)
+	val()
+	ctx.WriteString(`)`)
+}
+
 func (d *SnowflakeDialect) idsTableName(ctx Context) string {
 	return d.tempTableName(ctx, "_gj_ids")
 }
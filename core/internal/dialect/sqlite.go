@@ -29,6 +29,10 @@ func (d *SQLiteDialect) SupportsLateral() bool {
 	return false
 }
 
+func (d *SQLiteDialect) SupportsDistinctOn() bool {
+	return false
+}
+
 // RenderInlineChild for SQLite uses the default implementation from query.go
 // RenderInlineChild for SQLite uses the default implementation from query.go
 func (d *SQLiteDialect) RenderInlineChild(ctx Context, renderer InlineChildRenderer, psel, sel *qcode.Select) {
@@ -171,6 +175,10 @@ func (d *SQLiteDialect) RenderOrderBy(ctx Context, sel *qcode.Select) {
 			ctx.WriteString(`."value", '$."`)
 			ctx.WriteString(ob.Col.Name)
 			ctx.WriteString(`"')`)
+		} else if ob.CI {
+			ctx.WriteString(`LOWER(`)
+			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
+			ctx.WriteString(`)`)
 		} else {
 			ctx.ColWithTable(ob.Col.Table, ob.Col.Name)
 		}
@@ -224,6 +232,9 @@ func (d *SQLiteDialect) RenderIDCapture(ctx Context, varName string) {
 func (d *SQLiteDialect) RenderDistinctOn(ctx Context, sel *qcode.Select) {
 }
 
+func (d *SQLiteDialect) RenderLock(ctx Context, sel *qcode.Select) {
+}
+
 func (d *SQLiteDialect) RenderFromEdge(ctx Context, sel *qcode.Select) {
 	// Uses json_each for table function equivalent
 	ctx.WriteString(`json_each(`)
@@ -640,9 +651,9 @@ func (d *SQLiteDialect) RenderUpdate(ctx Context, m *qcode.Mutate, set func(), f
 	ctx.WriteString(` WHERE `)
 
 	// Add implicit join condition for JSON updates (only for Arrays where ID is in Input)
+	// the where clause that follows is joined to it with an AND
 	if m.IsJSON && m.Array {
 		renderPKJSONJoin(ctx, m)
-		ctx.WriteString(` AND `)
 	}
 
 	where()
@@ -659,9 +670,9 @@ func (d *SQLiteDialect) RenderUpdate(ctx Context, m *qcode.Mutate, set func(), f
 	ctx.WriteString(` WHERE `)
 
 	// Add implicit join condition for JSON updates (only for Arrays where ID is in Input)
+	// the where clause that follows is joined to it with an AND
 	if m.IsJSON && m.Array {
 		renderPKJSONJoin(ctx, m)
-		ctx.WriteString(` AND `)
 	}
 
 	where()
@@ -1443,6 +1454,20 @@ func (d *SQLiteDialect) RenderSetSessionVar(ctx Context, name, value string) boo
 	return false
 }
 
+// RenderSavepoint renders the SQL to create a savepoint in SQLite
+func (d *SQLiteDialect) RenderSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
+// RenderRollbackToSavepoint renders the SQL to roll back to a savepoint in SQLite
+func (d *SQLiteDialect) RenderRollbackToSavepoint(ctx Context, name string) bool {
+	ctx.WriteString(`ROLLBACK TO SAVEPOINT `)
+	ctx.WriteString(name)
+	return true
+}
+
 func (d *SQLiteDialect) RenderLinearValues(ctx Context, m *qcode.Mutate, renderRoot func()) {
 	// Custom implementation for Linear Execution values that handles variable injection
 	ctx.WriteString(`(SELECT `)
@@ -1573,6 +1598,26 @@ func (d *SQLiteDialect) RenderRecursiveAnchorWhere(ctx Context, psel *qcode.Sele
 	return false // SQLite supports outer scope correlation in CTEs
 }
 
+func (d *SQLiteDialect) RenderRecursivePath(ctx Context, path, key func()) {
+	ctx.WriteString(`(`)
+	if path != nil {
+		path()
+	} else {
+		ctx.WriteString(`','`)
+	}
+	ctx.WriteString(` || CAST(`)
+	key()
+	ctx.WriteString(` AS TEXT) || ',')`)
+}
+
+func (d *SQLiteDialect) RenderRecursiveCycleCheck(ctx Context, path, key func()) {
+	ctx.WriteString(`INSTR(`)
+	path()
+	ctx.WriteString(`, ',' || CAST(`)
+	key()
+	ctx.WriteString(` AS TEXT) || ',') = 0`)
+}
+
 // JSON Null Fields
 func (d *SQLiteDialect) RenderJSONNullField(ctx Context, fieldName string) {
 	ctx.WriteString(`'`)
@@ -1590,6 +1635,19 @@ func (d *SQLiteDialect) RenderJSONRootSuffix(ctx Context) {
 	// SQLite doesn't need any suffix
 }
 
+func (d *SQLiteDialect) RenderJSONRootScalarField(ctx Context, key string, val func()) {
+	ctx.WriteString(`'`)
+	ctx.WriteString(key)
+	ctx.WriteString(`', `)
+	val()
+}
+
+func (d *SQLiteDialect) RenderExists(ctx Context, renderQuery func()) {
+	ctx.WriteString(`json(CASE WHEN EXISTS(`)
+	renderQuery()
+	ctx.WriteString(`) THEN 'true' ELSE 'false' END)`)
+}
+
 // Array Operations
 func (d *SQLiteDialect) RenderArraySelectPrefix(ctx Context) {
 	ctx.WriteString(`(SELECT json_group_array(`)
@@ -1625,6 +1683,27 @@ func (d *SQLiteDialect) RequiresNullOnEmptySelect() bool {
 	return true // SQLite needs NULL when no columns rendered
 }
 
+func (d *SQLiteDialect) RenderAsString(ctx Context, val func()) {
+	ctx.WriteString(`CAST(`)
+	val()
+	ctx.WriteString(` AS TEXT)`)
+}
+
+// RenderTimezone always normalizes to UTC, SQLite has no timezone database
+func (d *SQLiteDialect) RenderTimezone(ctx Context, val func(), tz func(), withTZ bool) {
+	ctx.WriteString(`strftime('%Y-%m-%dT%H:%M:%fZ', `)
+	val()
+	ctx.WriteString(`)`)
+}
+
+func (d *SQLiteDialect) RenderDuration(ctx Context, val func()) {
+	val()
+}
+
+func (d *SQLiteDialect) RenderDurationValue(ctx Context, val func()) {
+	val()
+}
+
 // renderPKIDExpr renders the expression stored as `id` in _gj_ids.
 // For single PK: just the column. For composite PK: json_object('col1', col1, 'col2', col2).
 func renderPKIDExpr(ctx Context, m *qcode.Mutate) {
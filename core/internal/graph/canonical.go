@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+// Canonical returns a normalized form of the query. Queries that only differ
+// in whitespace, comments, fragment definitions, the order of variable
+// definitions or aliases that repeat the field name have the same canonical
+// form. Anything that changes the compiled result like field order, argument
+// values or the operation name is kept.
+func Canonical(gql []byte) ([]byte, error) {
+	op, err := Parse(gql)
+	if err != nil {
+		return nil, err
+	}
+
+	var w bytes.Buffer
+	switch op.Type {
+	case OpMutate:
+		w.WriteString("mutation")
+	case OpSub:
+		w.WriteString("subscription")
+	default:
+		w.WriteString("query")
+	}
+
+	if op.Name != "" {
+		w.WriteByte(' ')
+		w.WriteString(op.Name)
+	}
+
+	if len(op.VarDef) != 0 {
+		vars := make([]VarDef, len(op.VarDef))
+		copy(vars, op.VarDef)
+		sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+
+		w.WriteByte('(')
+		for i, v := range vars {
+			if i != 0 {
+				w.WriteByte(',')
+			}
+			w.WriteByte('$')
+			w.WriteString(v.Name)
+			if v.Type != "" {
+				w.WriteByte(':')
+				w.WriteString(v.Type)
+			}
+			if v.Val != nil {
+				w.WriteByte('=')
+				writeNode(&w, v.Val)
+			}
+		}
+		w.WriteByte(')')
+	}
+
+	writeArgs(&w, op.Args)
+	writeDirectives(&w, op.Directives)
+
+	w.WriteByte('{')
+	n := 0
+	for i := range op.Fields {
+		if op.Fields[i].ParentID != -1 {
+			continue
+		}
+		if n != 0 {
+			w.WriteByte(' ')
+		}
+		writeField(&w, op.Fields, int32(i))
+		n++
+	}
+	w.WriteByte('}')
+
+	return w.Bytes(), nil
+}
+
+// WriteField writes the field with the id along with its arguments,
+// directives and selection in the canonical form
+func WriteField(w *bytes.Buffer, fields []Field, id int32) {
+	writeField(w, fields, id)
+}
+
+func writeField(w *bytes.Buffer, fields []Field, id int32) {
+	f := &fields[id]
+
+	if f.Type == FieldMember {
+		w.WriteString("...on ")
+	} else if f.Alias != "" && f.Alias != f.Name {
+		w.WriteString(f.Alias)
+		w.WriteByte(':')
+	}
+	w.WriteString(f.Name)
+
+	// union members carry a copy of the parent args
+	if f.Type != FieldMember {
+		writeArgs(w, f.Args)
+	}
+	writeDirectives(w, f.Directives)
+
+	if len(f.Children) == 0 {
+		return
+	}
+	w.WriteByte('{')
+	for i, cid := range f.Children {
+		if i != 0 {
+			w.WriteByte(' ')
+		}
+		writeField(w, fields, cid)
+	}
+	w.WriteByte('}')
+}
+
+func writeArgs(w *bytes.Buffer, args []Arg) {
+	if len(args) == 0 {
+		return
+	}
+	w.WriteByte('(')
+	for i, a := range args {
+		if i != 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(a.Name)
+		w.WriteByte(':')
+		writeNode(w, a.Val)
+	}
+	w.WriteByte(')')
+}
+
+func writeDirectives(w *bytes.Buffer, dirs []Directive) {
+	for _, d := range dirs {
+		w.WriteByte('@')
+		w.WriteString(d.Name)
+		writeArgs(w, d.Args)
+	}
+}
+
+func writeNode(w *bytes.Buffer, n *Node) {
+	if n == nil {
+		w.WriteString("null")
+		return
+	}
+
+	switch n.Type {
+	case NodeStr:
+		w.WriteString(strconv.Quote(n.Val))
+	case NodeVar:
+		w.WriteByte('$')
+		w.WriteString(n.Val)
+	case NodeList:
+		w.WriteByte('[')
+		for i, c := range n.Children {
+			if i != 0 {
+				w.WriteByte(',')
+			}
+			writeNode(w, c)
+		}
+		w.WriteByte(']')
+	case NodeObj:
+		w.WriteByte('{')
+		for i, c := range n.Children {
+			if i != 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(c.Name)
+			w.WriteByte(':')
+			writeNode(w, c)
+		}
+		w.WriteByte('}')
+	default:
+		w.WriteString(n.Val)
+	}
+}
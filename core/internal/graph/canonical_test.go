@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestParseNestedFragments(t *testing.T) {
+	gql := []byte(`
+	query {
+		users {
+			...userFields
+		}
+	}
+
+	fragment userFields on user {
+		id
+		...contactFields
+	}
+
+	fragment contactFields on user {
+		email
+	}`)
+
+	op, err := Parse(gql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(op.Fields) != 3 || op.Fields[1].Name != "id" || op.Fields[2].Name != "email" {
+		t.Fatalf("unexpected fields: %+v", op.Fields)
+	}
+
+	_, err = Parse([]byte(`
+	query { users { ...a } }
+	fragment a on user { id ...b }
+	fragment b on user { email ...a }`))
+	if err == nil {
+		t.Fatal("expected an error for a fragment cycle")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	same := [][]string{
+		{
+			`query getUsers($limit: Int, $id: Int) { users(limit: $limit, where: { id: { eq: $id } }) { id email } }`,
+			`
+			# fetch users
+			query getUsers($id: Int, $limit: Int) {
+				users: users(limit: $limit, where: { id: { eq: $id } }) {
+					id
+					...f
+				}
+			}
+			fragment f on users { email }`,
+		},
+		{
+			`fragment b on users { email } query { users { ...a } } fragment a on users { id ...b }`,
+			`query { users { ...a } } fragment a on users { id ...b } fragment b on users { email }`,
+			`{ users { id email } }`,
+		},
+	}
+
+	for _, qs := range same {
+		exp, err := Canonical([]byte(qs[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, q := range qs[1:] {
+			got, err := Canonical([]byte(q))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(exp) {
+				t.Errorf("expected %s, got %s", exp, got)
+			}
+		}
+	}
+
+	different := [][2]string{
+		{`query a { users { id } }`, `query b { users { id } }`},
+		{`{ users { id email } }`, `{ users { email id } }`},
+		{`{ users { id } }`, `{ people: users { id } }`},
+		{`{ users(limit: 1) { id } }`, `{ users(limit: 2) { id } }`},
+		{`{ users(order_by: { id: asc, email: desc }) { id } }`, `{ users(order_by: { email: desc, id: asc }) { id } }`},
+	}
+
+	for _, qs := range different {
+		a, err := Canonical([]byte(qs[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Canonical([]byte(qs[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(a) == string(b) {
+			t.Errorf("expected different forms for %s and %s", qs[0], qs[1])
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"unsafe"
 )
@@ -76,6 +78,9 @@ type Field struct {
 
 type VarDef struct {
 	Name string
+	// Type is the declared type of the variable (eg. [Int!]!), empty when
+	// the variable is not typed
+	Type string
 	Val  *Node
 }
 
@@ -119,6 +124,11 @@ type Parser struct {
 	items []item
 	json  bool
 	err   error
+
+	// fragAt holds the position of each fragment definition so fragments
+	// can be parsed when first used, in any order they are defined
+	fragAt   map[string]int
+	fragBusy map[string]bool
 }
 
 func Parse(gql []byte) (op Operation, err error) {
@@ -151,7 +161,7 @@ func Parse(gql []byte) (op Operation, err error) {
 
 		if p.peekVal(fragmentToken) {
 			p.ignore()
-			if _, err = p.parseFragment(); err != nil {
+			if err = p.skipFragment(); err != nil {
 				return
 			}
 
@@ -166,6 +176,12 @@ func Parse(gql []byte) (op Operation, err error) {
 		}
 	}
 
+	for _, name := range p.fragOrder() {
+		if _, err = p.fragment(name); err != nil {
+			return
+		}
+	}
+
 	p.reset(qs)
 	if op, err = p.parseOp(); err != nil {
 		return
@@ -184,6 +200,82 @@ func Parse(gql []byte) (op Operation, err error) {
 	return
 }
 
+// skipFragment records where a fragment is defined and moves past it,
+// the fragment is parsed later when it is first used
+func (p *Parser) skipFragment() error {
+	if !p.peek(itemName) {
+		return errors.New("fragment: missing name")
+	}
+	if p.fragAt == nil {
+		p.fragAt = make(map[string]int)
+	}
+	p.fragAt[p.peekNext()] = p.pos
+
+	for !p.peek(itemObjOpen) {
+		if p.peek(itemEOF) {
+			return errors.New("fragment: expecting a '{'")
+		}
+		p.ignore()
+	}
+
+	depth := 0
+	for {
+		switch {
+		case p.peek(itemEOF):
+			return errors.New("fragment: end reached before fragment was closed")
+		case p.peek(itemObjOpen):
+			depth++
+		case p.peek(itemObjClose):
+			depth--
+		}
+		p.ignore()
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// fragOrder returns the names of the defined fragments in the order
+// they appear in the query
+func (p *Parser) fragOrder() []string {
+	names := make([]string, 0, len(p.fragAt))
+	for name := range p.fragAt {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return p.fragAt[names[i]] < p.fragAt[names[j]]
+	})
+	return names
+}
+
+// fragment returns the named fragment parsing it if needed. Fragments
+// can use other fragments defined before or after them.
+func (p *Parser) fragment(name string) (Fragment, error) {
+	if fr, ok := p.frags[name]; ok {
+		return fr, nil
+	}
+
+	at, ok := p.fragAt[name]
+	if !ok {
+		return Fragment{}, fmt.Errorf("fragment not defined: %s", name)
+	}
+
+	if p.fragBusy[name] {
+		return Fragment{}, fmt.Errorf("fragment cycle: %s", name)
+	}
+	if p.fragBusy == nil {
+		p.fragBusy = make(map[string]bool)
+	}
+	p.fragBusy[name] = true
+	defer delete(p.fragBusy, name)
+
+	pos := p.pos
+	p.reset(at)
+	fr, err := p.parseFragment()
+	p.reset(pos)
+	return fr, err
+}
+
 func (p *Parser) parseFragment() (frag Fragment, err error) {
 	s := p.curr().pos
 
@@ -416,7 +508,6 @@ func (p *Parser) parseNormalFields(st *Stack, fields []Field) ([]Field, error) {
 func (p *Parser) parseFragmentFields(st *Stack, fields []Field) ([]Field, error) {
 	var err error
 	var fr Fragment
-	var ok bool
 
 	pid := st.Peek()
 
@@ -445,9 +536,8 @@ func (p *Parser) parseFragmentFields(st *Stack, fields []Field) ([]Field, error)
 
 		name := p.val(p.next())
 
-		fr, ok = p.frags[name]
-		if !ok {
-			return nil, fmt.Errorf("fragment not defined: %s", name)
+		if fr, err = p.fragment(name); err != nil {
+			return nil, err
 		}
 
 		ff := fr.Fields
@@ -557,7 +647,16 @@ func (p *Parser) parseOpParams(op *Operation) (err error) {
 func (p *Parser) parseVarDef(op *Operation) (err error) {
 	name := p.val(p.next())
 
+	var typ string
+	if p.peek(itemColon) {
+		p.ignore()
+		typ = p.parseVarType()
+	}
+
 	if !p.peek(itemEquals) {
+		if typ != "" {
+			op.VarDef = append(op.VarDef, VarDef{Name: name, Type: typ})
+		}
 		return
 	}
 	p.ignore()
@@ -577,10 +676,31 @@ func (p *Parser) parseVarDef(op *Operation) (err error) {
 		return
 	}
 
-	op.VarDef = append(op.VarDef, VarDef{Name: name, Val: val})
+	op.VarDef = append(op.VarDef, VarDef{Name: name, Type: typ, Val: val})
 	return
 }
 
+// parseVarType reads a variable type like [Int!]! and returns it as a string
+func (p *Parser) parseVarType() string {
+	var sb strings.Builder
+	for {
+		switch {
+		case p.peek(itemListOpen):
+			sb.WriteByte('[')
+		case p.peek(itemListClose):
+			sb.WriteByte(']')
+		case p.peek(itemRequired):
+			sb.WriteByte('!')
+		case p.peek(itemName):
+			sb.WriteString(p.val(p.next()))
+			continue
+		default:
+			return sb.String()
+		}
+		p.ignore()
+	}
+}
+
 func (p *Parser) parseArgs(args []Arg) ([]Arg, error) {
 	var err error
 
@@ -0,0 +1,104 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestFunctionCallMutation(t *testing.T) {
+	cols := []sdata.DBColumn{
+		{Schema: "public", Table: "products", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "products", Name: "name", Type: "text"},
+	}
+	fns := []sdata.DBFunction{
+		{
+			Schema:  "public",
+			Name:    "get_top_products",
+			Type:    "record",
+			Inputs:  []sdata.DBFuncParam{{ID: 1, Name: "n", Type: "integer"}},
+			Outputs: []sdata.DBFuncParam{{ID: 2, Name: "id", Type: "bigint"}, {ID: 3, Name: "name", Type: "text"}},
+		},
+		{
+			Schema: "public",
+			Name:   "text2score",
+			Type:   "numeric",
+			Inputs: []sdata.DBFuncParam{{ID: 1, Name: "text", Type: "text"}, {ID: 2, Name: "weight", Type: "integer"}},
+		},
+	}
+	di := sdata.NewDBInfo("", 110000, "public", "db", cols, fns, nil)
+
+	schema, err := sdata.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qc, err := qcode.NewCompiler(schema, qcode.Config{
+		DBSchema: schema.DBSchema(),
+		Calls: map[string]qcode.CallConfig{
+			"text2score":       {Result: qcode.CallResultScalar},
+			"get_top_products": {Result: qcode.CallResultTable, Roles: []string{"admin"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		dbType string
+		gql    string
+		role   string
+		exp    []string
+	}{
+		{"scalar", "", `mutation { text2score(args: { text: $text }) }`, "user",
+			[]string{`'text2score', public.text2score($1)`}},
+		{"scalar mysql", "mysql", `mutation { score: text2score(args: { weight: 2, text: "shoes" }) }`, "user",
+			[]string{`'score', public.text2score('shoes', '2')`}},
+		{"table", "", `mutation { get_top_products(args: { n: 3 }) { id name } }`, "admin",
+			[]string{`FROM get_top_products('3') AS "get_top_products"`}},
+		{"table mysql", "mysql", `mutation { get_top_products(args: { n: 3 }) { id name } }`, "admin",
+			[]string{"FROM get_top_products('3') AS `get_top_products`"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := psql.NewCompiler(psql.Config{DBType: tt.dbType})
+
+			reqQC, err := qc.Compile([]byte(tt.gql), nil, tt.role, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := pc.CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+
+	blocked := []struct {
+		gql  string
+		role string
+	}{
+		{`mutation { text2score(args: { text: "shoes" }) }`, "anon"},
+		{`mutation { get_top_products(args: { n: 3 }) { id } }`, "user"},
+		{`mutation { text2score(args: { text: "shoes" }) { score } }`, "user"},
+		{`mutation { text2score(args: { weight: 2 }) }`, "user"},
+	}
+
+	for _, tt := range blocked {
+		if _, err := qc.Compile([]byte(tt.gql), nil, tt.role, ""); err == nil {
+			t.Errorf("expected an error for role %s: %s", tt.role, tt.gql)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestCaseInsensitiveOrderAndFilter(t *testing.T) {
+	gql := `query {
+		users(where: { full_name: { eq_ci: "ADA" } }, order_by: { email: desc_ci }) {
+			id
+		}
+	}`
+
+	tests := []struct {
+		dbType string
+		exp    []string
+	}{
+		{"postgres", []string{
+			`((LOWER("users"."full_name")) = LOWER('ADA'))`,
+			`ORDER BY LOWER("users"."email") DESC`,
+		}},
+		{"mysql", []string{
+			"((LOWER(`users`.`full_name`)) = LOWER('ADA'))",
+			"ORDER BY LOWER(`users`.`email`) DESC",
+		}},
+		{"mariadb", []string{
+			"((LOWER(`users_0`.`full_name`)) = LOWER('ADA'))",
+			"LOWER(`users_0`.`email`) AS _ord_0",
+			"ORDER BY LOWER(`users_0`.`email`) DESC",
+		}},
+		{"mssql", []string{
+			"(LOWER([users_0].[full_name]) = LOWER(N'ADA'))",
+			"ORDER BY LOWER([users_0].[email]) DESC",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qc, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			reqQC, err := qc.Compile([]byte(gql), nil, "admin", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := psql.NewCompiler(psql.Config{DBType: tt.dbType}).CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+}
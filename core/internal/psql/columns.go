@@ -51,7 +51,9 @@ func (c *compilerContext) renderStdColumn(sel *qcode.Select, f qcode.Field) {
 
 
 
-	c.colWithTableID(sel.Table, sel.ID, f.Col.Name)
+	c.RenderColumnValue(f, func() {
+		c.colWithTableID(sel.Table, sel.ID, f.Col.Name)
+	})
 
 	if f.FieldFilter.Exp != nil {
 		c.w.WriteString(` ELSE null END)`)
@@ -0,0 +1,70 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestDistinctDialects(t *testing.T) {
+	gql := `query {
+		products(distinct: [name], where: { price: { lt: 10 } }, order_by: { price: desc }) {
+			id
+			name
+		}
+	}`
+
+	tests := []struct {
+		dbType string
+		exp    []string
+	}{
+		{"postgres", []string{
+			`SELECT DISTINCT ON ("products"."name")`,
+			`ORDER BY "products"."name" ASC, "products"."price" DESC`,
+		}},
+		{"mysql", []string{
+			"WHERE (((`products`.`price`) < 10)) AND `products`.`id` IN (SELECT `__gj_d`.`id` FROM (SELECT `products`.`id`, ROW_NUMBER() OVER (PARTITION BY `products`.`name` ORDER BY `products`.`name` ASC, `products`.`price` DESC) AS `__gj_rn`",
+			"WHERE `__gj_d`.`__gj_rn` = 1)",
+		}},
+		{"mariadb", []string{
+			"WHERE (((`products_0`.`price`) < (10))) AND `products_0`.`id` IN (SELECT `__gj_d`.`id` FROM (SELECT `products_0`.`id`, ROW_NUMBER() OVER (PARTITION BY `products_0`.`name` ORDER BY `products_0`.`name` ASC, `products_0`.`price` DESC) AS `__gj_rn`",
+			"WHERE `__gj_d`.`__gj_rn` = 1)",
+		}},
+		{"mssql", []string{
+			`WHERE (([products_0].[price] < 10)) AND [products_0].[id] IN (SELECT [__gj_d].[id] FROM (SELECT [products_0].[id], ROW_NUMBER() OVER (PARTITION BY [products_0].[name] ORDER BY [products_0].[name] ASC, [products_0].[price] DESC) AS [__gj_rn]`,
+			`WHERE [__gj_d].[__gj_rn] = 1)`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qc, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			reqQC, err := qc.Compile([]byte(gql), nil, "admin", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := psql.NewCompiler(psql.Config{DBType: tt.dbType}).CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+}
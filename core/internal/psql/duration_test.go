@@ -0,0 +1,72 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestDurationOpsDialects(t *testing.T) {
+	gql := `query {
+		products(where: { created_at: { within_last: "P1DT2H" } }) {
+			id
+		}
+		users(where: { created_at: { older_than: "PT30M" } }) {
+			id
+		}
+	}`
+
+	tests := []struct {
+		dbType string
+		exp    []string
+	}{
+		{"postgres", []string{
+			`(("products"."created_at") >= (CURRENT_TIMESTAMP - INTERVAL '93600 seconds'))`,
+			`(("users"."created_at") < (CURRENT_TIMESTAMP - INTERVAL '1800 seconds'))`,
+		}},
+		{"mysql", []string{
+			"((`products`.`created_at`) >= DATE_SUB(NOW(), INTERVAL 93600 SECOND))",
+			"((`users`.`created_at`) < DATE_SUB(NOW(), INTERVAL 1800 SECOND))",
+		}},
+		{"mariadb", []string{
+			"((`products_1`.`created_at`) >= (DATE_SUB(NOW(), INTERVAL 93600 SECOND)))",
+			"((`users_0`.`created_at`) < (DATE_SUB(NOW(), INTERVAL 1800 SECOND)))",
+		}},
+		{"mssql", []string{
+			`([products_1].[created_at] >= DATEADD(second, -93600, GETDATE()))`,
+			`([users_0].[created_at] < DATEADD(second, -1800, GETDATE()))`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qc, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			reqQC, err := qc.Compile([]byte(gql), nil, "admin", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := psql.NewCompiler(psql.Config{DBType: tt.dbType}).CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+}
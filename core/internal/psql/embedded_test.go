@@ -0,0 +1,105 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestEmbeddedJSONTable(t *testing.T) {
+	cols := []sdata.DBColumn{
+		{Schema: "public", Table: "products", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "products", Name: "name", Type: "text"},
+		{Schema: "public", Table: "products", Name: "variants", Type: "json"},
+	}
+	di := sdata.NewDBInfo("", 80000, "public", "db", cols, nil, nil)
+
+	// the variants json column as a table, the same way it is set up
+	// from the tables config
+	bt, err := di.GetTable("public", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nt := sdata.NewDBTable("public", "variants", "json", []sdata.DBColumn{
+		{ID: -1, Schema: "public", Table: "variants", Name: "sku", Type: "text"},
+		{ID: -1, Schema: "public", Table: "variants", Name: "qty", Type: "integer"},
+		{ID: -1, Schema: "public", Table: "variants", Name: "options", Type: "json"},
+	})
+	nt.PrimaryCol = bt.Columns[2]
+	nt.PrimaryCol.PrimaryKey = true
+	nt.SecondaryCol = bt.PrimaryCol
+	di.AddTable(nt)
+
+	schema, err := sdata.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qc, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		products(limit: 2) {
+			id
+			variants(where: { qty: { gt: 1 } }) {
+				sku
+				qty
+				options
+			}
+		}
+	}`
+
+	tests := []struct {
+		dbType string
+		exp    []string
+	}{
+		{"postgres", []string{
+			`json_to_recordset("products"."variants") AS "variants"(sku text, qty integer, options json)`,
+			`(("variants"."qty") > 1)`,
+		}},
+		{"mysql", []string{
+			"JSON_TABLE(",
+			"COLUMNS(`sku` text PATH '$.sku' ERROR ON ERROR, `qty` int PATH '$.qty' ERROR ON ERROR, " +
+				"`options` json PATH '$.options' ERROR ON ERROR)",
+			"((`variants`.`qty`) > 1)",
+		}},
+		{"mariadb", []string{
+			"JSON_TABLE(",
+			"COLUMNS(`sku` text PATH '$.sku' ERROR ON ERROR, `qty` int PATH '$.qty' ERROR ON ERROR, " +
+				"`options` json PATH '$.options' ERROR ON ERROR)",
+			"((`variants_1`.`qty`) > (1))",
+		}},
+		{"mssql", []string{
+			"OPENJSON(",
+			"WITH ([sku] NVARCHAR(MAX) '$.sku', [qty] INT '$.qty', [options] NVARCHAR(MAX) '$.options' AS JSON)",
+			"([variants_1].[qty] > 1)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			pc := psql.NewCompiler(psql.Config{DBType: tt.dbType})
+
+			reqQC, err := qc.Compile([]byte(gql), nil, "admin", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := pc.CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+}
@@ -117,11 +117,33 @@ func (c *expContext) renderNestedExp(st *util.StackInf, ex *qcode.Exp) {
 	}
 }
 
+// renderNamedFilter renders the SQL of a named filter with its columns
+// qualified by the table alias
+func (c *expContext) renderNamedFilter(ex *qcode.Exp) {
+	c.w.WriteString(`(`)
+	for _, t := range ex.SQL.Tokens {
+		switch {
+		case !t.Col:
+			c.w.WriteString(t.Val)
+		case ex.Left.ID == -1:
+			c.colWithTable(ex.Left.Table, t.Val)
+		default:
+			c.colWithTableID(ex.Left.Table, ex.Left.ID, t.Val)
+		}
+	}
+	c.w.WriteString(`)`)
+}
+
 func (c *expContext) renderOp(ex *qcode.Exp) {
 	if ex.Op == qcode.OpNop {
 		return
 	}
 
+	if ex.Op == qcode.OpNamedFilter {
+		c.renderNamedFilter(ex)
+		return
+	}
+
 	if c.renderValPrefix(ex) {
 		return
 	}
@@ -177,6 +199,9 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 		}
 
 		c.w.WriteString(`((`)
+		if ex.Op == qcode.OpEqualsCI {
+			c.w.WriteString(`LOWER(`)
+		}
 
 		// Handle JSON path operations
 		if len(ex.Left.Path) > 0 {
@@ -202,6 +227,9 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 				c.colWithTableID(table, ex.Left.ID, colName)
 			}
 		}
+		if ex.Op == qcode.OpEqualsCI {
+			c.w.WriteString(`)`)
+		}
 		c.w.WriteString(`) `)
 	}
 
@@ -209,6 +237,8 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 	switch ex.Op {
 	case qcode.OpEquals:
 		c.w.WriteString(`=`)
+	case qcode.OpEqualsCI:
+		c.w.WriteString(`=`)
 	case qcode.OpNotEquals:
 		c.w.WriteString(`!=`)
 	case qcode.OpNotDistinct:
@@ -266,9 +296,16 @@ func (c *expContext) renderOp(ex *qcode.Exp) {
 	}
 	c.w.WriteString(` `)
 
-	switch ex.Right.ValType {
-	case qcode.ValList:
+	switch {
+	case ex.Right.ValType == qcode.ValList:
 		c.renderList(ex)
+	case ex.Op == qcode.OpEqualsCI:
+		c.w.WriteString(`LOWER(`)
+		c.renderVal(ex)
+		c.w.WriteString(`)`)
+	case ex.Left.Col.IsInterval() &&
+		(ex.Right.ValType == qcode.ValStr || ex.Right.ValType == qcode.ValVar):
+		c.dialect.RenderDurationValue(c, func() { c.renderVal(ex) })
 	default:
 		c.renderVal(ex)
 	}
@@ -304,6 +341,9 @@ func (c *expContext) renderVal(ex *qcode.Exp) {
 	case ex.Right.ValType == qcode.ValPartitionBound:
 		c.renderPartitionBound(ex.Right.Val)
 
+	case ex.Right.ValType == qcode.ValNowMinus:
+		c.renderNowMinus(ex.Right.Val)
+
 	case !ex.Right.Col.Array && (ex.Op == qcode.OpContains ||
 		ex.Op == qcode.OpContainedIn ||
 		ex.Op == qcode.OpHasInCommon):
@@ -449,3 +489,39 @@ func (c *expContext) renderPartitionBound(daysStr string) {
 		c.w.WriteString(` days')`)
 	}
 }
+
+// renderNowMinus renders a dialect-specific "now minus N seconds" expression
+// for the duration operators, the seconds are parsed by the qcode compiler
+func (c *expContext) renderNowMinus(secs string) {
+	if _, err := strconv.ParseInt(secs, 10, 64); err != nil {
+		c.err = fmt.Errorf("invalid duration: %s", secs)
+		return
+	}
+
+	switch c.dialect.Name() {
+	case "snowflake":
+		c.w.WriteString(`DATEADD(second, -`)
+		c.w.WriteString(secs)
+		c.w.WriteString(`, CURRENT_TIMESTAMP())`)
+	case "mysql", "mariadb":
+		c.w.WriteString(`DATE_SUB(NOW(), INTERVAL `)
+		c.w.WriteString(secs)
+		c.w.WriteString(` SECOND)`)
+	case "mssql":
+		c.w.WriteString(`DATEADD(second, -`)
+		c.w.WriteString(secs)
+		c.w.WriteString(`, GETDATE())`)
+	case "oracle":
+		c.w.WriteString(`(SYSTIMESTAMP - NUMTODSINTERVAL(`)
+		c.w.WriteString(secs)
+		c.w.WriteString(`, 'SECOND'))`)
+	case "sqlite":
+		c.w.WriteString(`datetime('now', '-`)
+		c.w.WriteString(secs)
+		c.w.WriteString(` seconds')`)
+	default: // postgres
+		c.w.WriteString(`(CURRENT_TIMESTAMP - INTERVAL '`)
+		c.w.WriteString(secs)
+		c.w.WriteString(` seconds')`)
+	}
+}
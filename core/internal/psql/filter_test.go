@@ -0,0 +1,103 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestNamedFilter(t *testing.T) {
+	schema, err := sdata.GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filters := map[string]string{
+		"active_users": "phone IS NOT NULL AND email LIKE '%@%'",
+		"recent":       "created_at > now() - interval '7 days'",
+		"cheap":        `"price" < 10 AND CAST(price AS numeric) > 0`,
+		"bad_column":   "status = 'active'",
+	}
+
+	qc, err := qcode.NewCompiler(schema, qcode.Config{
+		DBSchema: schema.DBSchema(),
+		Filters:  filters,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = qc.AddRole("user", "public", "users", qcode.TRConfig{
+		Query: qcode.QueryConfig{Filters: []string{`{ _filter: "active_users" }`}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		dbType string
+		gql    string
+		role   string
+		exp    string
+	}{
+		{"role filter", "", `query { users { id } }`, "user",
+			`WHERE ("users"."phone" IS NOT NULL AND "users"."email" LIKE '%@%')`},
+		{"where", "", `query { products(where: { _filter: "recent" }) { id } }`, "admin",
+			`("products"."created_at" > now() - interval '7 days')`},
+		{"nested where", "", `query { products(where: { users: { _filter: "active_users" } }) { id } }`, "admin",
+			`("users"."phone" IS NOT NULL AND "users"."email" LIKE '%@%')`},
+		{"quoted column", "mysql", `query { products(where: { _filter: "cheap" }) { id } }`, "admin",
+			"(`products`.`price` < 10 AND CAST(`products`.`price` AS numeric) > 0)"},
+		{"inline", "mariadb", `query { products(where: { _filter: "cheap" }) { id } }`, "admin",
+			"(`products_0`.`price` < 10 AND CAST(`products_0`.`price` AS numeric) > 0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := psql.NewCompiler(psql.Config{DBType: tt.dbType})
+
+			reqQC, err := qc.Compile([]byte(tt.gql), nil, tt.role, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := pc.CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(sql), tt.exp) {
+				t.Errorf("expected %s in: %s", tt.exp, sql)
+			}
+		})
+	}
+
+	invalid := []string{
+		`query { products(where: { _filter: "bad_column" }) { id } }`,
+		`query { products(where: { _filter: "missing" }) { id } }`,
+	}
+	for _, gql := range invalid {
+		if _, err := qc.Compile([]byte(gql), nil, "admin", ""); err == nil {
+			t.Errorf("expected an error: %s", gql)
+		}
+	}
+
+	// filters are checked against the table when roles are loaded
+	err = qc.AddRole("user", "public", "products", qcode.TRConfig{
+		Query: qcode.QueryConfig{Filters: []string{`{ _filter: "active_users" }`}},
+	})
+	if err == nil {
+		t.Error("expected an error for a filter using columns not on the table")
+	}
+
+	_, err = qcode.NewCompiler(schema, qcode.Config{
+		Filters: map[string]string{"drop": "true; DROP TABLE users"},
+	})
+	if err == nil {
+		t.Error("expected an error for a filter with multiple statements")
+	}
+}
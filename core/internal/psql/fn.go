@@ -15,6 +15,16 @@ func (c *compilerContext) renderTableFunction(sel *qcode.Select) {
 	c.alias(sel.Table)
 }
 
+// renderCallFunction renders a scalar function called by a mutation,
+// the name is schema qualified since MSSQL requires it
+func (c *compilerContext) renderCallFunction(sel *qcode.Select) {
+	name := sel.Func.Name
+	if sel.Func.Schema != "" {
+		name = sel.Func.Schema + "." + name
+	}
+	c.renderFunction(name, sel.Args)
+}
+
 func (c *compilerContext) renderFieldFunction(sel *qcode.Select, f qcode.Field) {
 	switch f.Func.Name {
 	case "search_rank":
@@ -0,0 +1,69 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestHintDialects(t *testing.T) {
+	tests := []struct {
+		dbType string
+		hint   string
+		exp    []string
+	}{
+		{"postgres", "IndexScan(products products_name_idx)", []string{
+			`/*+ IndexScan(products products_name_idx) */ /* action=`,
+		}},
+		{"mysql", "USE INDEX (products_name_idx)", []string{
+			"FROM `public`.`products` AS `products` USE INDEX (products_name_idx) WHERE",
+		}},
+		{"mariadb", "FORCE INDEX (products_name_idx)", []string{
+			"FROM `public`.`products` AS `products_0` FORCE INDEX (products_name_idx)  WHERE",
+		}},
+		{"mssql", "MAXDOP 1, RECOMPILE", []string{
+			`AS [__root_x]  OPTION (MAXDOP 1, RECOMPILE)`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qc, err := qcode.NewCompiler(schema, qcode.Config{
+				DBSchema:  schema.DBSchema(),
+				HintRoles: []string{"admin"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gql := `query {
+				products(where: { name: { eq: "Apple" } }) @hint(value: "` + tt.hint + `") {
+					id
+				}
+			}`
+			reqQC, err := qc.Compile([]byte(gql), nil, "admin", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := psql.NewCompiler(psql.Config{DBType: tt.dbType}).CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected %s in: %s", v, sql)
+				}
+			}
+		})
+	}
+}
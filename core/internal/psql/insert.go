@@ -19,6 +19,9 @@ func (c *compilerContext) renderInsert() {
 		case m.Rel.Type == sdata.RelOneToOne && m.Type == qcode.MTConnect:
 			i = c.renderComma(i)
 			c.renderOneToOneConnectStmt(m)
+		case m.Type == qcode.MTLink:
+			i = c.renderComma(i)
+			c.renderLinkStmt(m)
 		}
 	}
 }
@@ -0,0 +1,71 @@
+package psql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestLockDialects(t *testing.T) {
+	gql := `query {
+		products(lock: { mode: update, skip_locked: true }, order_by: { id: asc }, limit: 5) {
+			id
+		}
+	}`
+
+	tests := []struct {
+		dbType string
+		exp    string
+	}{
+		{"postgres", `LIMIT 5 FOR UPDATE OF "products" SKIP LOCKED`},
+		{"mysql", "LIMIT 5 FOR UPDATE OF `products` SKIP LOCKED"},
+		// the other databases reject the lock argument
+		{"mariadb", ""},
+		{"mssql", ""},
+		{"sqlite", ""},
+		{"oracle", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qc, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = qc.AddRole("user", "public", "products", qcode.TRConfig{
+				Query: qcode.QueryConfig{AllowLock: true},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reqQC, err := qc.Compile([]byte(gql), nil, "user", "")
+			if tt.exp == "" {
+				if err == nil {
+					t.Fatal("expected lock to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, sql, err := psql.NewCompiler(psql.Config{DBType: tt.dbType}).CompileEx(reqQC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(sql), tt.exp) {
+				t.Errorf("expected %s in: %s", tt.exp, sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package psql_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestCompileManyToManyConnect(t *testing.T) {
+	cols := []sdata.DBColumn{
+		{Schema: "public", Table: "products", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "products", Name: "name", Type: "text"},
+		{Schema: "public", Table: "categories", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "categories", Name: "name", Type: "text"},
+		{Schema: "public", Table: "product_categories", Name: "product_id", Type: "bigint", FKeySchema: "public", FKeyTable: "products", FKeyCol: "id"},
+		{Schema: "public", Table: "product_categories", Name: "category_id", Type: "bigint", FKeySchema: "public", FKeyTable: "categories", FKeyCol: "id"},
+	}
+	schema, err := sdata.NewDBSchema(sdata.NewDBInfo("postgres", 110000, "public", "db", cols, nil, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qcomp, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcomp := psql.NewCompiler(psql.Config{})
+
+	tests := []struct {
+		name, gql, data string
+		exp             []string
+	}{
+		{
+			name: "insert",
+			gql:  `mutation { products(insert: $data) { id categories { id } } }`,
+			data: `{ "name": "Lamp", "categories": { "connect": { "id": [1, 2] } } }`,
+			exp: []string{
+				`RETURNING "public"."products".*), "product_categories" AS (INSERT INTO "public"."product_categories" ("product_id", "category_id") SELECT "_x_products"."id", "categories"."id" FROM "_sg_input" i, "products" _x_products, "public"."categories" WHERE`,
+			},
+		},
+		{
+			name: "update",
+			gql:  `mutation { products(update: $data, id: 3) { id categories { id } } }`,
+			data: `{ "name": "Lamp", "categories": { "connect": { "id": 5 }, "disconnect": { "id": [1, 2] } } }`,
+			exp: []string{
+				`INSERT INTO "public"."product_categories" ("product_id", "category_id") SELECT "_x_products"."id", "categories"."id"`,
+				`DELETE FROM "public"."product_categories" USING "_sg_input" i, "products" _x_products WHERE (("product_categories"."product_id") = ("_x_products"."id")) AND (("product_categories"."category_id") IN (SELECT "categories"."id" FROM "public"."categories" WHERE`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := map[string]json.RawMessage{"data": json.RawMessage(tt.data)}
+			qc, err := qcomp.Compile([]byte(tt.gql), vars, "user", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, sql, err := pcomp.CompileEx(qc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.exp {
+				if !strings.Contains(string(sql), v) {
+					t.Errorf("expected sql to contain:\n%s\ngot:\n%s", v, sql)
+				}
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package psql
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/dialect"
 )
 
 func (c *Compiler) RenderVar(w *bytes.Buffer, md *Metadata, vv string) {
@@ -77,6 +80,34 @@ func (c *compilerContext) renderParam(p Param) {
 	c.w.WriteString(c.dialect.BindVar(id))
 }
 
+// renderParamTypes adds the parameter types to the output of dialects that
+// compile the whole statement themselves and bind the parameters in the driver
+func (c *compilerContext) renderParamTypes() {
+	ptr, ok := c.dialect.(dialect.ParamTypesRenderer)
+	if !ok || len(c.md.params) == 0 {
+		return
+	}
+
+	b := c.w.Bytes()
+	if len(b) == 0 || b[len(b)-1] != '}' {
+		return
+	}
+	c.w.Truncate(len(b) - 1)
+
+	params := make([]dialect.Param, len(c.md.params))
+	for i, p := range c.md.params {
+		params[i] = dialect.Param{
+			Name:        p.Name,
+			Type:        p.Type,
+			IsArray:     p.IsArray,
+			IsNotNull:   p.IsNotNull,
+			WrapInArray: p.WrapInArray,
+		}
+	}
+	ptr.RenderParamTypes(c, params)
+	c.w.WriteByte('}')
+}
+
 func (md Metadata) Params() []Param {
 	return md.params
 }
@@ -91,3 +122,35 @@ func parseVar(v string) (string, string) {
 	}
 	return v, dt
 }
+
+type metadataJSON struct {
+	CT     string  `json:"ct,omitempty"`
+	Poll   bool    `json:"poll,omitempty"`
+	Params []Param `json:"params,omitempty"`
+}
+
+// MarshalJSON encodes the metadata so that compiled statements can be persisted
+func (md Metadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(metadataJSON{CT: md.ct, Poll: md.poll, Params: md.params})
+}
+
+// UnmarshalJSON restores metadata encoded with MarshalJSON
+func (md *Metadata) UnmarshalJSON(b []byte) error {
+	var v metadataJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	md.ct = v.CT
+	md.poll = v.Poll
+	md.params = v.Params
+	md.pindex = nil
+	for i, p := range v.Params {
+		if md.pindex == nil {
+			md.pindex = make(map[string]int)
+		}
+		if _, ok := md.pindex[p.Name]; !ok {
+			md.pindex[p.Name] = i + 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package psql
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestMongoDBParamTypes(t *testing.T) {
+	schema, err := sdata.GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qcCompiler, err := qcode.NewCompiler(schema, qcode.Config{DBSchema: schema.DBSchema()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query { products(where: { price: { gt: $price } }, limit: $limit) { id name } }`
+	qc, err := qcCompiler.Compile([]byte(gql), nil, "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	co := NewCompiler(Config{DBType: "mongodb"})
+
+	var w bytes.Buffer
+	md, err := co.Compile(&w, qc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dsl struct {
+		ParamTypes []string `json:"param_types"`
+	}
+	if err := json.Unmarshal(w.Bytes(), &dsl); err != nil {
+		t.Fatalf("invalid query DSL: %s: %s", err, w.String())
+	}
+
+	params := md.Params()
+	if len(dsl.ParamTypes) != len(params) {
+		t.Fatalf("expected %d param types, got %v", len(params), dsl.ParamTypes)
+	}
+	for i, p := range params {
+		if p.Name == "price" && p.Type != "numeric(7,2)" {
+			t.Errorf("expected price param to have the column type, got %s", p.Type)
+		}
+		if dsl.ParamTypes[i] != p.Type {
+			t.Errorf("param %d: expected type %s, got %s", i+1, p.Type, dsl.ParamTypes[i])
+		}
+	}
+}
@@ -31,6 +31,7 @@ func (co *Compiler) compileMutation(
 	// This is used by MongoDB which generates JSON mutation DSL, not SQL
 	if fmc, ok := co.dialect.(dialect.FullMutationCompiler); ok {
 		if fmc.CompileFullMutation(&c, qc) {
+			c.renderParamTypes()
 			return
 		}
 	}
@@ -68,6 +69,46 @@ func (co *Compiler) compileMutation(
 
 }
 
+// CompileMutationKeys renders a select of the primary keys of the rows the
+// root update or delete m will change, it uses the same filters as the
+// mutation so running it first in the same transaction finds those rows
+func (co *Compiler) CompileMutationKeys(w *bytes.Buffer, qc *qcode.QCode, m qcode.Mutate) (Metadata, error) {
+	var md Metadata
+
+	if _, ok := co.dialect.(dialect.FullMutationCompiler); ok {
+		return md, fmt.Errorf("mutation keys: not supported on %s", co.dialect.Name())
+	}
+	if m.ParentID != -1 || m.Ti.PrimaryCol.Name == "" {
+		return md, fmt.Errorf("mutation keys: no primary key for '%s'", m.Ti.Name)
+	}
+
+	c := compilerContext{
+		md:       &md,
+		w:        w,
+		qc:       qc,
+		Compiler: co,
+	}
+	sel := &qc.Selects[m.SelID]
+
+	c.w.WriteString(`SELECT `)
+	c.colWithTable(m.Ti.Name, m.Ti.PrimaryCol.Name)
+	c.w.WriteString(` FROM `)
+	c.table(sel, m.Ti.Schema, m.Ti.Name, false)
+
+	if sel.Where.Exp != nil {
+		c.w.WriteString(` WHERE `)
+		c.renderExp(m.Ti, sel.Where.Exp, false)
+		if m.Where.Exp != nil {
+			c.w.WriteString(` AND `)
+			c.renderExp(m.Ti, m.Where.Exp, false)
+		}
+	} else if m.Where.Exp != nil {
+		c.w.WriteString(` WHERE `)
+		c.renderExp(m.Ti, m.Where.Exp, false)
+	}
+	return md, c.err
+}
+
 func (c *compilerContext) compileLinearMutation() {
 	// Linear execution: Flat script of statements
 
@@ -150,7 +191,10 @@ func (c *compilerContext) compileLinearMutation() {
 				// So here I should just render the standard filters.
 
 				hasWhere := false
-				if m.IsJSON {
+				if m.IsJSON && m.Array && c.dialect.Name() == "sqlite" {
+					// the dialect joins each row on its primary key
+					hasWhere = true
+				} else if m.IsJSON {
 					if c.dialect.Name() == "postgres" {
 						for i, pkCol := range m.Ti.PrimaryCols {
 							if i > 0 {
@@ -188,7 +232,7 @@ func (c *compilerContext) compileLinearMutation() {
 						// For child updates (m.ParentID != -1), the JSON input doesn't contain the child's PK.
 						// The row to update is determined by the parent's FK, not by JSON table join.
 						// Only add JSON table join for root updates that need it.
-						if m.ParentID == -1 && (len(c.qc.Selects) == 0 || c.qc.Selects[m.SelID].Where.Exp == nil) {
+						if m.ParentID == -1 && (len(c.qc.Selects) == 0 || c.qc.Selects[m.SelID].Where.Exp == nil || m.Array) {
 							for i, pkCol := range m.Ti.PrimaryCols {
 								if i > 0 {
 									c.w.WriteString(" AND ")
@@ -205,10 +249,10 @@ func (c *compilerContext) compileLinearMutation() {
 				}
 
 				if m.ParentID == -1 {
-					if hasWhere {
-						c.w.WriteString(" AND ")
-					}
 					if len(c.qc.Selects) > 0 && c.qc.Selects[m.SelID].Where.Exp != nil {
+						if hasWhere {
+							c.w.WriteString(" AND ")
+						}
 						c.renderExp(m.Ti, c.qc.Selects[m.SelID].Where.Exp, false)
 						hasWhere = true
 					}
@@ -319,6 +363,12 @@ func (c *compilerContext) compileLinearMutation() {
 				}
 			}
 			c.dialect.RenderLinearDisconnect(c, &m, c.qc, vName, renderFilter)
+
+		case qcode.MTLink:
+			c.renderLinearLinkStmt(m)
+
+		case qcode.MTUnlink:
+			c.renderLinearUnlinkStmt(m)
 		}
 		c.w.WriteString(`; `)
 	}
@@ -397,8 +447,8 @@ func (c *compilerContext) renderColumnValue(m qcode.Mutate, col qcode.MColumn) {
 			vk = v[1:]
 			isVar = true
 		}
-	} else {
-		field := m.Data.CMap[col.FieldName]
+	} else if field, ok := m.Data.CMap[col.FieldName]; ok {
+		// generated keys are not in the data the query was compiled with
 		v = field.Val
 		vk = v
 
@@ -816,6 +866,139 @@ func (c *compilerContext) renderOneToOneDisconnectStmt(m qcode.Mutate) {
 	c.w.WriteString(`)`)
 }
 
+func (c *compilerContext) renderLinkStmt(m qcode.Mutate) {
+	// Insert a join table row for every related row matching the connect
+	// filter. Eg. Create a product and connect it to a few categories
+	// through product_categories.
+	c.renderJoinCteName(m)
+	c.w.WriteString(` AS (`)
+	c.renderLinkInsert(m)
+	c.w.WriteString(` SELECT `)
+	c.colWithTable(("_x_" + m.Join.Right.Col.Table), m.Join.Right.Col.Name)
+	c.w.WriteString(`, `)
+	c.colWithTable(m.Rel.Left.Col.Table, m.Rel.Left.Col.Name)
+	c.w.WriteString(` FROM `)
+	c.renderLinkFrom(m)
+	c.w.WriteString(`, `)
+	c.table(nil, m.Rel.Left.Ti.Schema, m.Rel.Left.Ti.Name, false)
+	c.w.WriteString(` WHERE `)
+	c.renderExpPath(m.Rel.Left.Ti, m.Where.Exp, false, m.Path)
+	c.renderReturning(m)
+	c.w.WriteString(`)`)
+}
+
+func (c *compilerContext) renderUnlinkStmt(m qcode.Mutate) {
+	// Delete the join table rows of the parent for the related rows
+	// matching the disconnect filter.
+	c.renderCteNameWithID(m)
+	c.w.WriteString(` AS (DELETE FROM `)
+	c.table(nil, m.Ti.Schema, m.Ti.Name, false)
+	c.w.WriteString(` USING `)
+	c.renderLinkFrom(m)
+	c.w.WriteString(` WHERE ((`)
+	c.colWithTable(m.Ti.Name, m.Join.Left.Col.Name)
+	c.w.WriteString(`) = (`)
+	c.colWithTable(("_x_" + m.Join.Right.Col.Table), m.Join.Right.Col.Name)
+	c.w.WriteString(`)) AND `)
+	c.renderUnlinkFilter(m, m.Path)
+	c.renderReturning(m)
+	c.w.WriteString(`)`)
+}
+
+func (c *compilerContext) renderLinearLinkStmt(m qcode.Mutate) {
+	c.renderLinkInsert(m)
+	c.w.WriteString(` SELECT `)
+	c.renderParentColumnVar(m)
+	c.w.WriteString(`, `)
+	c.colWithTable(m.Rel.Left.Col.Table, m.Rel.Left.Col.Name)
+	c.w.WriteString(` FROM `)
+	c.table(nil, m.Rel.Left.Ti.Schema, m.Rel.Left.Ti.Name, false)
+	c.w.WriteString(` WHERE `)
+	c.renderExpPath(m.Rel.Left.Ti, m.Where.Exp, false, nil)
+}
+
+func (c *compilerContext) renderLinearUnlinkStmt(m qcode.Mutate) {
+	c.w.WriteString(`DELETE FROM `)
+	c.table(nil, m.Ti.Schema, m.Ti.Name, false)
+	c.w.WriteString(` WHERE ((`)
+	c.colWithTable(m.Ti.Name, m.Join.Left.Col.Name)
+	c.w.WriteString(`) = (`)
+	c.renderParentColumnVar(m)
+	c.w.WriteString(`)) AND `)
+	c.renderUnlinkFilter(m, nil)
+}
+
+func (c *compilerContext) renderLinkInsert(m qcode.Mutate) {
+	c.w.WriteString(`INSERT INTO `)
+	c.table(nil, m.Ti.Schema, m.Ti.Name, false)
+	c.w.WriteString(` (`)
+	c.quoted(m.Join.Left.Col.Name)
+	c.w.WriteString(`, `)
+	c.quoted(m.Rel.Right.Col.Name)
+	c.w.WriteString(`)`)
+}
+
+func (c *compilerContext) renderUnlinkFilter(m qcode.Mutate, path []string) {
+	c.w.WriteString(`((`)
+	c.colWithTable(m.Ti.Name, m.Rel.Right.Col.Name)
+	c.w.WriteString(`) IN (SELECT `)
+	c.colWithTable(m.Rel.Left.Col.Table, m.Rel.Left.Col.Name)
+	c.w.WriteString(` FROM `)
+	c.table(nil, m.Rel.Left.Ti.Schema, m.Rel.Left.Ti.Name, false)
+	c.w.WriteString(` WHERE `)
+	c.renderExpPath(m.Rel.Left.Ti, m.Where.Exp, false, path)
+	c.w.WriteString(`))`)
+}
+
+// renderLinkFrom renders the parent table (and the json input) that
+// link and unlink statements select the parent key from
+func (c *compilerContext) renderLinkFrom(m qcode.Mutate) {
+	if m.IsJSON {
+		c.quoted("_sg_input")
+		c.w.WriteString(` i`)
+		c.renderNestedRelTables(m, true, 1)
+	} else {
+		c.renderNestedRelTables(m, true, 0)
+	}
+}
+
+// renderParentColumnVar renders the value of the parent column referenced
+// by the join table using the variables captured by linear execution
+func (c *compilerContext) renderParentColumnVar(m qcode.Mutate) {
+	pm := c.qc.Mutates[m.ParentID]
+	col := m.Join.Right.Col.Name
+
+	if len(pm.Ti.PrimaryCols) != 0 && pm.Ti.PrimaryCols[0].Name == col {
+		c.dialect.RenderVar(c, c.getVarName(pm))
+		return
+	}
+	c.w.WriteString(`(SELECT `)
+	c.quoted(col)
+	c.w.WriteString(` FROM `)
+	c.table(nil, pm.Ti.Schema, pm.Ti.Name, false)
+	c.w.WriteString(` WHERE `)
+	c.renderPKWhereVars(pm)
+	c.w.WriteString(`)`)
+}
+
+// renderJoinCteName names the cte after the join table so the rows it
+// inserts are visible to the query that follows. This is only done for
+// inserts where the join table is not mutated more than once since
+// the cte would otherwise hide the existing rows
+func (c *compilerContext) renderJoinCteName(m qcode.Mutate) {
+	if c.qc.SType != qcode.QTInsert {
+		c.renderCteNameWithID(m)
+		return
+	}
+	for _, m1 := range c.qc.Mutates {
+		if m1.ID != m.ID && m1.Ti.Name == m.Ti.Name {
+			c.renderCteNameWithID(m)
+			return
+		}
+	}
+	c.quoted(m.Ti.Name)
+}
+
 func (c *compilerContext) renderOneToManyModifiers(m qcode.Mutate) int {
 	i := 0
 	for id := range m.DependsOn {
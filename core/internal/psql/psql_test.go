@@ -37,6 +37,7 @@ func TestMain(m *testing.M) {
 				"{ price: { gt: 0 } }",
 				"{ price: { lt: 8 } }",
 			},
+			AllowLock: true,
 		},
 		Insert: qcode.InsertConfig{
 			Presets: map[string]string{
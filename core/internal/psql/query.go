@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/dosco/graphjin/core/v3/internal/dialect"
 	"github.com/dosco/graphjin/core/v3/internal/qcode"
@@ -23,6 +24,9 @@ type Param struct {
 	WrapInArray bool // For MySQL/MariaDB: wrap single JSON object in array for JSON_TABLE
 }
 
+// TimezoneType is the type of the parameter holding the timezone of a request
+const TimezoneType = "timezone"
+
 type Metadata struct {
 	ct     string
 	poll   bool
@@ -47,6 +51,12 @@ type Config struct {
 	DBVersion       int
 	SecPrefix       []byte
 	EnableCamelcase bool
+	// Timezone timestamp columns are rendered in
+	Timezone string
+	// TimezoneVar is the variable that overrides the timezone per request
+	TimezoneVar string
+	// QueryTags adds the role and namespace of the query to the SQL comment
+	QueryTags bool
 }
 
 type Compiler struct {
@@ -55,6 +65,9 @@ type Compiler struct {
 	cv              int    // db version
 	pf              []byte // security prefix
 	enableCamelcase bool
+	tz              string
+	tzVar           string
+	queryTags       bool
 }
 
 func (c *Compiler) GetDialect() dialect.Dialect {
@@ -111,6 +124,9 @@ func NewCompiler(conf Config) *Compiler {
 		cv:              conf.DBVersion,
 		pf:              conf.SecPrefix,
 		enableCamelcase: conf.EnableCamelcase,
+		tz:              conf.Timezone,
+		tzVar:           conf.TimezoneVar,
+		queryTags:       conf.QueryTags,
 	}
 }
 
@@ -132,10 +148,27 @@ func (co *Compiler) Compile(w *bytes.Buffer, qc *qcode.QCode) (Metadata, error)
 		return md, fmt.Errorf("qcode is nil")
 	}
 
+	hints, err := co.statementHints(qc)
+	if err != nil {
+		return md, err
+	}
+
+	// pg_hint_plan only reads the hints in the first comment
+	if hints != "" && co.dialect.Name() == "postgres" {
+		w.WriteString(`/*+ ` + hints + ` */ `)
+	}
+
 	// Skip SQL comment for MongoDB (it generates JSON, not SQL) and Snowflake emulator.
 	// The current Snowflake emulator drops result rows when a leading block comment is present.
 	if co.dialect.Name() != "mongodb" && co.dialect.Name() != "snowflake" {
-		w.WriteString(`/* action='` + qc.Name + `',controller='graphql',framework='graphjin' */ `)
+		w.WriteString(`/* action='` + qc.Name + `',controller='graphql',framework='graphjin'`)
+		if co.queryTags {
+			if qc.Namespace != "" {
+				w.WriteString(`,ns='` + tagValue(qc.Namespace) + `'`)
+			}
+			w.WriteString(`,role='` + tagValue(qc.Role) + `'`)
+		}
+		w.WriteString(` */ `)
 	}
 
 	switch qc.Type {
@@ -146,17 +179,65 @@ func (co *Compiler) Compile(w *bytes.Buffer, qc *qcode.QCode) (Metadata, error)
 		err = co.CompileQuery(w, qc, &md)
 
 	case qcode.QTMutation:
-		co.compileMutation(w, qc, &md)
+		if qc.SType == qcode.QTCall {
+			err = co.CompileQuery(w, qc, &md)
+		} else {
+			co.compileMutation(w, qc, &md)
+		}
 
 	default:
 		err = fmt.Errorf("unknown operation type %d", qc.Type)
 	}
 
+	if hints != "" && co.dialect.Name() == "mssql" {
+		w.WriteString(` OPTION (` + hints + `)`)
+	}
+
 	// md.ct = qc.Schema.DBType()
 
 	return md, err
 }
 
+// tagValue drops the characters of a value that are not allowed in the SQL
+// comment so it cannot end the comment or the quoted value
+func tagValue(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_', r == '-', r == '.', r == ':':
+			return r
+		}
+		return -1
+	}, v)
+}
+
+// statementHints returns the @hint values that apply to the whole statement
+// joined for the dialect. MySQL, MariaDB and SQLite hints are written after
+// the table of their select and MongoDB hints are set on the aggregation.
+func (co *Compiler) statementHints(qc *qcode.QCode) (string, error) {
+	var hints []string
+	for i := range qc.Selects {
+		if h := qc.Selects[i].Hint; h != "" {
+			hints = append(hints, h)
+		}
+	}
+	if len(hints) == 0 {
+		return "", nil
+	}
+
+	switch co.dialect.Name() {
+	case "postgres":
+		return strings.Join(hints, " "), nil
+	case "mssql":
+		return strings.Join(hints, ", "), nil
+	case "mysql", "mariadb", "sqlite", "mongodb":
+		return "", nil
+	default:
+		return "", fmt.Errorf("@hint: not supported on %s", co.dialect.Name())
+	}
+}
+
 func (co *Compiler) RenderSetSessionVar(name, value string) string {
 	var w bytes.Buffer
 	// Provide a minimal Context implementation over bytes.Buffer
@@ -180,6 +261,25 @@ func (co *Compiler) RenderSetSessionVar(name, value string) string {
 	return ""
 }
 
+// RenderSavepoint returns the SQL to create a savepoint, empty if the
+// database does not support savepoints
+func (co *Compiler) RenderSavepoint(name string) string {
+	var w bytes.Buffer
+	if co.dialect.RenderSavepoint(&compilerContext{w: &w}, name) {
+		return w.String()
+	}
+	return ""
+}
+
+// RenderRollbackToSavepoint returns the SQL to roll back to a savepoint
+func (co *Compiler) RenderRollbackToSavepoint(name string) string {
+	var w bytes.Buffer
+	if co.dialect.RenderRollbackToSavepoint(&compilerContext{w: &w}, name) {
+		return w.String()
+	}
+	return ""
+}
+
 func (co *Compiler) CompileQuery(
 	w *bytes.Buffer,
 	qc *qcode.QCode,
@@ -201,6 +301,7 @@ func (co *Compiler) CompileQuery(
 			Compiler: co,
 		}
 		if fqc.CompileFullQuery(c, qc) {
+			c.renderParamTypes()
 			return c.err
 		}
 	}
@@ -277,6 +378,21 @@ func (co *Compiler) CompileQuery(
 			}
 
 		default:
+			if sel.Count || sel.Exists || sel.Call {
+				c.dialect.RenderJSONRootScalarField(c, sel.FieldName, func() {
+					switch {
+					case sel.Call:
+						c.renderCallFunction(sel)
+					case sel.Count:
+						c.renderCount(sel)
+					default:
+						c.dialect.RenderExists(c, func() { c.renderExistsQuery(sel) })
+					}
+				})
+				i++
+				continue
+			}
+
 			c.dialect.RenderJSONRootField(c, sel.FieldName, func() {
 				if !c.dialect.SupportsLateral() {
 					// Dialects without LATERAL use inline subqueries
@@ -597,6 +713,10 @@ func (c *compilerContext) IsTableMutated(table string) bool {
 		return false
 	}
 	for _, m := range c.qc.Mutates {
+		// join table rows linked or unlinked have no captured ids
+		if m.Type == qcode.MTLink || m.Type == qcode.MTUnlink {
+			continue
+		}
 		if m.Ti.Name == table {
 			return true
 		}
@@ -629,6 +749,23 @@ func (c *compilerContext) renderBaseSelect(sel *qcode.Select) {
 	c.renderGroupBy(sel)
 	c.renderOrderBy(sel)
 	c.renderLimit(sel)
+	c.renderLock(sel)
+}
+
+// renderCount renders a <table>_count root as a COUNT(*) subquery
+// without fetching any rows
+func (c *compilerContext) renderCount(sel *qcode.Select) {
+	c.w.WriteString(`(SELECT COUNT(*)`)
+	c.renderFrom(sel)
+	c.renderWhere(sel)
+	c.w.WriteString(`)`)
+}
+
+// renderExistsQuery renders the query checked by a <table>_exists root
+func (c *compilerContext) renderExistsQuery(sel *qcode.Select) {
+	c.w.WriteString(`SELECT 1`)
+	c.renderFrom(sel)
+	c.renderWhere(sel)
 }
 
 func (c *compilerContext) renderLimit(sel *qcode.Select) {
@@ -641,7 +778,7 @@ func (c *compilerContext) renderFrom(sel *qcode.Select) {
 	// For mutations, use just the table name (no schema) so the CTE created
 	// by INSERT/UPDATE/DELETE shadows the physical table name. This allows
 	// the SELECT to query the mutation's result set instead of the full table.
-	if c.qc.Type == qcode.QTMutation {
+	if c.qc.Type == qcode.QTMutation && c.qc.SType != qcode.QTCall {
 		c.quoted(sel.Table)
 		c.dialect.RenderTableAlias(c, sel.Table)
 		return
@@ -661,6 +798,19 @@ func (c *compilerContext) renderFrom(sel *qcode.Select) {
 
 	default:
 		c.table(sel, sel.Ti.Schema, sel.Ti.Name, true)
+		c.renderTableHint(sel)
+	}
+}
+
+// renderTableHint writes the @hint of a select after its table for the
+// dialects with per-table hints like USE INDEX or INDEXED BY
+func (c *compilerContext) renderTableHint(sel *qcode.Select) {
+	if sel.Hint == "" {
+		return
+	}
+	switch c.dialect.Name() {
+	case "mysql", "mariadb", "sqlite":
+		c.w.WriteString(` ` + sel.Hint)
 	}
 }
 
@@ -676,6 +826,17 @@ func (c *compilerContext) renderCursorCTE(sel *qcode.Select) {
 }
 
 func (c *compilerContext) renderWhere(sel *qcode.Select) {
+	if c.distinctFilterNeeded(sel) {
+		c.w.WriteString(` WHERE `)
+		if sel.Where.Exp != nil {
+			c.w.WriteString(`(`)
+			c.renderExp(sel.Ti, sel.Where.Exp, false)
+			c.w.WriteString(`) AND `)
+		}
+		c.renderDistinctFilter(sel)
+		return
+	}
+
 	if sel.Rel.Type == sdata.RelNone && sel.Where.Exp == nil {
 		return
 	}
@@ -684,6 +845,52 @@ func (c *compilerContext) renderWhere(sel *qcode.Select) {
 	c.renderExp(sel.Ti, sel.Where.Exp, false)
 }
 
+// distinctFilterNeeded returns true when distinct has to be emulated
+// with a window function since the dialect has no DISTINCT ON
+func (c *compilerContext) distinctFilterNeeded(sel *qcode.Select) bool {
+	return len(sel.DistinctOn) != 0 &&
+		!sel.GroupCols &&
+		!c.dialect.SupportsDistinctOn() &&
+		sel.Rel.Type != sdata.RelEmbedded &&
+		sel.Ti.PrimaryCol.Name != ""
+}
+
+// renderDistinctFilter keeps the first row (by the select's order) of every
+// distinct group by matching primary keys against a ROW_NUMBER() partition
+// over the distinct columns. Unlike GROUP BY this leaves the selected
+// columns untouched.
+func (c *compilerContext) renderDistinctFilter(sel *qcode.Select) {
+	pk := sel.Ti.PrimaryCol.Name
+
+	c.colWithTable(sel.Table, pk)
+	c.w.WriteString(` IN (SELECT `)
+	c.colWithTable(`__gj_d`, pk)
+	c.w.WriteString(` FROM (SELECT `)
+	c.colWithTable(sel.Table, pk)
+	c.w.WriteString(`, ROW_NUMBER() OVER (PARTITION BY `)
+	for i, col := range sel.DistinctOn {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+		c.colWithTable(sel.Table, col.Name)
+	}
+	c.renderOrderBy(sel)
+	c.w.WriteString(`) AS `)
+	c.quoted(`__gj_rn`)
+	c.renderFrom(sel)
+	c.renderJoinTables(sel)
+	c.renderFromCursor(sel)
+	if sel.Where.Exp != nil {
+		c.w.WriteString(` WHERE `)
+		c.renderExp(sel.Ti, sel.Where.Exp, false)
+	}
+	c.w.WriteString(`)`)
+	c.dialect.RenderTableAlias(c, `__gj_d`)
+	c.w.WriteString(` WHERE `)
+	c.colWithTable(`__gj_d`, `__gj_rn`)
+	c.w.WriteString(` = 1)`)
+}
+
 func (c *compilerContext) renderGroupBy(sel *qcode.Select) {
 	if !sel.GroupCols || len(sel.BCols) == 0 {
 		return
@@ -718,3 +925,7 @@ func (c *compilerContext) renderOrderBy(sel *qcode.Select) {
 func (c *compilerContext) renderDistinctOn(sel *qcode.Select) {
 	c.dialect.RenderDistinctOn(c, sel)
 }
+
+func (c *compilerContext) renderLock(sel *qcode.Select) {
+	c.dialect.RenderLock(c, sel)
+}
@@ -633,6 +633,23 @@ func recursiveTableChildren(t *testing.T) {
 	compileGQLToPSQL(t, gql, vars, "user")
 }
 
+func recursiveTableMaxDepth(t *testing.T) {
+	gql := `query {
+		comments(id: $id) {
+			id
+			replies: comments(find: "children", max_depth: 2) {
+				id
+			}
+		}
+	}`
+
+	vars := map[string]json.RawMessage{
+		"id": json.RawMessage(`6`),
+	}
+
+	compileGQLToPSQL(t, gql, vars, "user")
+}
+
 func nullForAuthRequiredInAnon(t *testing.T) {
 	gql := `query {
 		products {
@@ -724,6 +741,7 @@ func TestCompileQuery(t *testing.T) {
 	t.Run("jsonColumnAsTable", jsonColumnAsTable)
 	t.Run("recursiveTableParents", recursiveTableParents)
 	t.Run("recursiveTableChildren", recursiveTableChildren)
+	t.Run("recursiveTableMaxDepth", recursiveTableMaxDepth)
 	t.Run("withCursor", withCursor)
 	t.Run("nullForAuthRequiredInAnon", nullForAuthRequiredInAnon)
 	t.Run("blockedQuery", blockedQuery)
@@ -733,10 +751,55 @@ func TestCompileQuery(t *testing.T) {
 	t.Run("distinctWithAggMultiple", distinctWithAggMultiple)
 	t.Run("distinctWithAggAndWhere", distinctWithAggAndWhere)
 	t.Run("aggWithoutDistinct", aggWithoutDistinct)
+	t.Run("distinctOrderBy", distinctOrderBy)
+	t.Run("lockForUpdate", lockForUpdate)
+	t.Run("lockNotAllowed", lockNotAllowed)
 	t.Run("partitionFilterInSQL", partitionFilterInSQL)
 	t.Run("warehouseColumnProjection", warehouseColumnProjection)
 }
 
+// distinctOrderBy verifies the DISTINCT ON columns lead the ORDER BY
+// as Postgres requires, followed by the user's own ordering.
+func distinctOrderBy(t *testing.T) {
+	gql := `query {
+		products(distinct: [name], order_by: { price: desc }) {
+			id
+			name
+		}
+	}`
+	sql := compileGQLToPSQLString(t, gql, nil, "user")
+
+	if !strings.Contains(sql, `DISTINCT ON ("products"."name")`) {
+		t.Errorf("expected DISTINCT ON the name column: %s", sql)
+	}
+	if !strings.Contains(sql, `ORDER BY "products"."name" ASC, "products"."price" DESC`) {
+		t.Errorf("expected ORDER BY to start with the distinct column: %s", sql)
+	}
+}
+
+func lockForUpdate(t *testing.T) {
+	gql := `query {
+		products(lock: { mode: update, skip_locked: true }, order_by: { id: asc }, limit: 5) {
+			id
+			name
+		}
+	}`
+	sql := compileGQLToPSQLString(t, gql, nil, "user")
+
+	if !strings.Contains(sql, `LIMIT 5 FOR UPDATE OF "products" SKIP LOCKED`) {
+		t.Errorf("expected a FOR UPDATE SKIP LOCKED clause: %s", sql)
+	}
+}
+
+func lockNotAllowed(t *testing.T) {
+	gql := `query {
+		products(lock: { mode: share }) {
+			id
+		}
+	}`
+	compileGQLToPSQLExpectErr(t, gql, nil, "anon")
+}
+
 // --- distinct + aggregation tests ---
 // These verify that GROUP BY uses only the distinct columns, not the PK.
 // Bug: __gj_id (PK) was included in GROUP BY making every group unique (count=1).
@@ -0,0 +1,55 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestQueryTags(t *testing.T) {
+	di := sdata.GetTestDBInfo()
+	di.Type = "postgres"
+	schema, err := sdata.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qcc, err := qcode.NewCompiler(schema, qcode.Config{
+		DBSchema:  schema.DBSchema(),
+		HintRoles: []string{"user"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query, role, ns string
+		exp             string
+	}{
+		{`query getUsers { users { id } }`, "user", "",
+			`/* action='getUsers',controller='graphql',framework='graphjin',role='user' */ SELECT`},
+		{`query getUsers { users { id } }`, "user", "app*/;--1",
+			`/* action='getUsers',controller='graphql',framework='graphjin',ns='app--1',role='user' */ SELECT`},
+		// pg_hint_plan only reads the first comment
+		{`query getUsers { users @hint(value: "SeqScan(users)") { id } }`, "user", "",
+			`/*+ SeqScan(users) */ /* action='getUsers',controller='graphql',framework='graphjin',role='user' */ SELECT`},
+	}
+
+	co := NewCompiler(Config{QueryTags: true})
+	for _, tt := range tests {
+		qc, err := qcc.Compile([]byte(tt.query), nil, tt.role, tt.ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var w bytes.Buffer
+		if _, err := co.Compile(&w, qc); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(w.String(), tt.exp) {
+			t.Errorf("expected the sql to start with %q: %s", tt.exp, w.String())
+		}
+	}
+}
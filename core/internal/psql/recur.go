@@ -45,6 +45,10 @@ func (c *compilerContext) renderRecursiveCTEColumnList(sel *qcode.Select) {
 		}
 		c.quoted(col.Col.Name)
 	}
+	c.w.WriteString(`, `)
+	c.quoted("__rdepth")
+	c.w.WriteString(`, `)
+	c.quoted("__rpath")
 }
 
 func (c *compilerContext) renderRecursiveSelect(sel *qcode.Select) {
@@ -57,6 +61,13 @@ func (c *compilerContext) renderRecursiveSelect(sel *qcode.Select) {
 		c.w.WriteString(`(SELECT `)
 	}
 	c.renderRecursiveBaseColumns(sel)
+	c.w.WriteString(`, 0`)
+	c.alias("__rdepth")
+	c.w.WriteString(`, `)
+	c.dialect.RenderRecursivePath(c, nil, func() {
+		c.colWithTable(sel.Ti.Name, sel.Ti.PrimaryCol.Name)
+	})
+	c.alias("__rpath")
 	c.renderFrom(psel)
 	c.w.WriteString(` WHERE `)
 	// Use dialect-specific WHERE clause for recursive CTE anchor
@@ -80,12 +91,31 @@ func (c *compilerContext) renderRecursiveSelect(sel *qcode.Select) {
 	c.dialect.RenderRecursiveLimit1(c)
 	c.w.WriteString(`) UNION ALL `)
 
+	rcte := "__rcte_" + sel.Rel.Right.Ti.Name
+	path := func() { c.colWithTable(rcte, "__rpath") }
+	key := func() { c.colWithTable(sel.Ti.Name, sel.Ti.PrimaryCol.Name) }
+
 	c.w.WriteString(`SELECT `)
 	c.renderRecursiveBaseColumns(sel)
+	c.w.WriteString(`, `)
+	c.colWithTable(rcte, "__rdepth")
+	c.w.WriteString(` + 1, `)
+	c.dialect.RenderRecursivePath(c, path, key)
 	c.renderFrom(sel)
 	c.w.WriteString(`, `)
-	c.quoted("__rcte_" + sel.Rel.Right.Ti.Name)
+	c.quoted(rcte)
 	c.renderWhere(sel)
+
+	// Stop at max_depth and never revisit a row already on the path so
+	// cyclic data cannot recurse forever
+	if sel.MaxDepth > 0 {
+		c.w.WriteString(` AND `)
+		c.colWithTable(rcte, "__rdepth")
+		c.w.WriteString(` < `)
+		int32String(c.w, sel.MaxDepth)
+	}
+	c.w.WriteString(` AND `)
+	c.dialect.RenderRecursiveCycleCheck(c, path, key)
 }
 
 func (c *compilerContext) renderRecursiveBaseColumns(sel *qcode.Select) {
@@ -0,0 +1,61 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestTimezoneColumns(t *testing.T) {
+	tests := []struct {
+		dbType string
+		exp    string
+	}{
+		{"postgres", `(("users_0"."created_at" AT TIME ZONE 'UTC') AT TIME ZONE COALESCE($1, 'Europe/Berlin')) AS "created_at"`},
+		{"mysql", "CONVERT_TZ(`users_0`.`created_at`, '+00:00', COALESCE(?, 'Europe/Berlin')) AS `created_at`"},
+		{"mariadb", "CONVERT_TZ(`users_0`.`created_at`, '+00:00', COALESCE(?, 'Europe/Berlin')) AS 'created_at'"},
+		{"mssql", `(([users_0].[created_at] AT TIME ZONE 'UTC') AT TIME ZONE COALESCE(@p1, 'Europe/Berlin')) AS [created_at]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dbType, func(t *testing.T) {
+			di := sdata.GetTestDBInfo()
+			di.Type = tt.dbType
+			schema, err := sdata.NewDBSchema(di, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			qcCompiler, err := qcode.NewCompiler(schema, qcode.Config{
+				DBSchema: schema.DBSchema(),
+				Timezone: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			qc, err := qcCompiler.Compile([]byte(`query { users { id created_at } }`), nil, "user", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			co := NewCompiler(Config{DBType: tt.dbType, Timezone: "Europe/Berlin", TimezoneVar: "tz"})
+
+			var w bytes.Buffer
+			md, err := co.Compile(&w, qc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(w.String(), tt.exp) {
+				t.Fatalf("expected %s in: %s", tt.exp, w.String())
+			}
+			if p := md.Params(); len(p) != 1 || p[0].Name != "tz" || p[0].Type != TimezoneType {
+				t.Fatalf("expected a tz param, got %v", p)
+			}
+		})
+	}
+}
@@ -20,6 +20,12 @@ func (c *compilerContext) renderUpdate() {
 		case m.Rel.Type == sdata.RelOneToOne && m.Type == qcode.MTDisconnect:
 			i = c.renderComma(i)
 			c.renderOneToOneDisconnectStmt(m)
+		case m.Type == qcode.MTLink:
+			i = c.renderComma(i)
+			c.renderLinkStmt(m)
+		case m.Type == qcode.MTUnlink:
+			i = c.renderComma(i)
+			c.renderUnlinkStmt(m)
 		}
 	}
 }
@@ -79,6 +85,12 @@ func (c *compilerContext) renderUpdateStmt(m qcode.Mutate, embedded bool) {
 					c.w.WriteString(` AND `)
 					c.renderExpPath(m.Ti, m.Where.Exp, false, append(m.Path, "where"))
 				}
+			} else if m.Array {
+				c.renderBulkUpdateJoin(m)
+				if sel.Where.Exp != nil {
+					c.w.WriteString(` AND `)
+					c.renderExp(m.Ti, sel.Where.Exp, false)
+				}
 			} else {
 				c.renderExp(m.Ti, sel.Where.Exp, false)
 			}
@@ -89,3 +101,23 @@ func (c *compilerContext) renderUpdateStmt(m qcode.Mutate, embedded bool) {
 		}
 	})
 }
+
+// renderBulkUpdateJoin matches each row of a bulk update (a list of rows
+// passed to update) with the table row that has the same primary key
+func (c *compilerContext) renderBulkUpdateJoin(m qcode.Mutate) {
+	i := 0
+	for _, col := range m.Cols {
+		if !m.Ti.IsPKCol(col.Col.Name) {
+			continue
+		}
+		if i != 0 {
+			c.w.WriteString(` AND `)
+		}
+		c.w.WriteString(`((`)
+		c.colWithTable(m.Ti.Name, col.Col.Name)
+		c.w.WriteString(`) = (`)
+		c.colWithTable("t", col.FieldName)
+		c.w.WriteString(`))`)
+		i++
+	}
+}
@@ -20,6 +20,24 @@ func singleUpdate(t *testing.T) {
 	compileGQLToPSQL(t, gql, vars, "anon")
 }
 
+func bulkUpdate(t *testing.T) {
+	gql := `mutation {
+		products(update: $data) {
+			id
+			name
+		}
+	}`
+
+	vars := map[string]json.RawMessage{
+		"data": json.RawMessage(`[
+			{ "id": 1, "name": "Apple", "price": 1.25 },
+			{ "id": 2, "name": "Orange", "price": 0.75 }
+		]`),
+	}
+
+	compileGQLToPSQL(t, gql, vars, "admin")
+}
+
 func simpleUpdateWithPresets(t *testing.T) {
 	gql := `mutation {
 		products(update: $data id: $id) {
@@ -289,6 +307,7 @@ func multiRootUpdate(t *testing.T) {
 
 func TestCompileUpdate(t *testing.T) {
 	t.Run("singleUpdate", singleUpdate)
+	t.Run("bulkUpdate", bulkUpdate)
 	t.Run("simpleUpdateWithPresets", simpleUpdateWithPresets)
 	t.Run("nestedUpdateManyToMany", nestedUpdateManyToMany)
 	//t.Run("nestedUpdateOneToMany", nestedUpdateOneToMany)
@@ -156,3 +156,42 @@ func (c *compilerContext) GetRootWithCursor() *qcode.Select {
 	}
 	return nil
 }
+
+// RenderColumnValue renders a column value, intervals are rendered as
+// ISO-8601 durations, timestamps are converted to the output timezone and
+// values returned as strings are cast
+func (c *compilerContext) RenderColumnValue(f qcode.Field, val func()) {
+	if f.Col.IsInterval() {
+		col := val
+		val = func() { c.dialect.RenderDuration(c, col) }
+	}
+	if f.Timezone && (c.tz != "" || c.tzVar != "") {
+		col := val
+		val = func() {
+			c.dialect.RenderTimezone(c, col, c.renderTimezone, f.Col.HasTimezone())
+		}
+	}
+	if f.AsString {
+		c.dialect.RenderAsString(c, val)
+	} else {
+		val()
+	}
+}
+
+// renderTimezone renders the output timezone, the timezone variable of the
+// request is used when set
+func (c *compilerContext) renderTimezone() {
+	tz := c.tz
+	if tz == "" {
+		tz = "UTC"
+	}
+	if c.tzVar == "" {
+		c.squoted(tz)
+		return
+	}
+	c.w.WriteString(`COALESCE(`)
+	c.renderParam(Param{Name: c.tzVar, Type: TimezoneType})
+	c.w.WriteString(`, `)
+	c.squoted(tz)
+	c.w.WriteString(`)`)
+}
@@ -23,7 +23,7 @@ func (co *Compiler) compileSelectArgs(sel *Select, args []graph.Arg, role string
 			err = co.compileArgWhere(sel, a, role)
 
 		case "orderBy", "order_by", "order":
-			err = co.compileArgOrderBy(sel, a)
+			err = co.compileArgOrderBy(sel, a, role)
 
 		case "distinctOn", "distinct_on", "distinct":
 			err = co.compileArgDistinctOn(sel, a)
@@ -49,9 +49,18 @@ func (co *Compiler) compileSelectArgs(sel *Select, args []graph.Arg, role string
 		case "find":
 			err = co.compileArgFind(sel, a)
 
+		case "max_depth", "maxDepth":
+			err = co.compileArgMaxDepth(sel, a)
+
 		case "args":
 			err = co.compileArgArgs(sel, a)
 
+		case "lock":
+			err = co.compileArgLock(sel, a)
+
+		case "partition":
+			err = co.compileArgPartition(sel, a)
+
 		// case "includeIf", "include_if":
 		// 	err = co.compileArgSkipIncludeIf(false, sel, &sel.Field, a, role)
 
@@ -97,6 +106,21 @@ func (co *Compiler) compileArgFind(sel *Select, arg graph.Arg) (err error) {
 	return nil
 }
 
+func (co *Compiler) compileArgMaxDepth(sel *Select, arg graph.Arg) (err error) {
+	if err = validateArg(arg, graph.NodeNum); err != nil {
+		return
+	}
+	n, err := strconv.ParseInt(arg.Val.Val, 10, 32)
+	if err != nil {
+		return
+	}
+	if n < 1 {
+		return fmt.Errorf("must be greater than zero")
+	}
+	sel.MaxDepth = int32(n)
+	return
+}
+
 func (co *Compiler) compileArgID(sel *Select, arg graph.Arg) (err error) {
 	if sel.ParentID != -1 {
 		return fmt.Errorf("can only be specified at the query root")
@@ -222,7 +246,7 @@ func (co *Compiler) compileArgWhere(sel *Select, arg graph.Arg, role string) (er
 	return
 }
 
-func (co *Compiler) compileArgOrderBy(sel *Select, arg graph.Arg) (err error) {
+func (co *Compiler) compileArgOrderBy(sel *Select, arg graph.Arg, role string) (err error) {
 	if err = validateArg(arg, graph.NodeObj, graph.NodeVar); err != nil {
 		return
 	}
@@ -239,7 +263,7 @@ func (co *Compiler) compileArgOrderBy(sel *Select, arg graph.Arg) (err error) {
 		return co.compileArgOrderByObj(sel, node, cm)
 
 	case graph.NodeVar:
-		return co.compileArgOrderByVar(sel, node, cm)
+		return co.compileArgOrderByVar(sel, node, cm, role)
 	}
 
 	return nil
@@ -286,6 +310,96 @@ func (co *Compiler) compileArgArgs(sel *Select, arg graph.Arg) (err error) {
 	return
 }
 
+func (co *Compiler) compileArgLock(sel *Select, arg graph.Arg) (err error) {
+	if err = validateArg(arg, graph.NodeObj); err != nil {
+		return
+	}
+
+	lock := Lock{Mode: LockUpdate}
+
+	for _, cn := range arg.Val.Children {
+		switch cn.Name {
+		case "mode":
+			if cn.Type != graph.NodeLabel && cn.Type != graph.NodeStr {
+				return fmt.Errorf("mode: expecting 'update' or 'share'")
+			}
+			switch strings.ToLower(cn.Val) {
+			case "update":
+				lock.Mode = LockUpdate
+			case "share":
+				lock.Mode = LockShare
+			default:
+				return fmt.Errorf("mode: invalid value '%s', expecting 'update' or 'share'", cn.Val)
+			}
+
+		case "skip_locked", "skipLocked":
+			if cn.Type != graph.NodeBool {
+				return fmt.Errorf("%s: expecting a boolean", cn.Name)
+			}
+			lock.SkipLocked = cn.Val == "true"
+
+		case "nowait", "noWait":
+			if cn.Type != graph.NodeBool {
+				return fmt.Errorf("%s: expecting a boolean", cn.Name)
+			}
+			lock.NoWait = cn.Val == "true"
+
+		default:
+			return fmt.Errorf("unknown lock option '%s'", cn.Name)
+		}
+	}
+
+	if lock.SkipLocked && lock.NoWait {
+		return fmt.Errorf("skip_locked and nowait cannot be used together")
+	}
+	sel.Lock = lock
+	return nil
+}
+
+// compileArgPartition adds a range filter on the partition key of the table
+// so the database can prune the partitions outside of it
+func (co *Compiler) compileArgPartition(sel *Select, arg graph.Arg) (err error) {
+	if err = validateArg(arg, graph.NodeObj); err != nil {
+		return
+	}
+
+	if sel.Ti.PartitionKey == "" {
+		return fmt.Errorf("table '%s' has no partition key", sel.Ti.Name)
+	}
+	cid, ok := sel.Ti.GetColumnIndex(sel.Ti.PartitionKey)
+	if !ok {
+		return fmt.Errorf("partition column '%s' not found in table '%s'",
+			sel.Ti.PartitionKey, sel.Ti.Name)
+	}
+
+	for _, cn := range arg.Val.Children {
+		var ex *Exp
+		switch cn.Name {
+		case "from":
+			ex = newExpOp(OpGreaterOrEquals)
+		case "to":
+			ex = newExpOp(OpLesserThan)
+		default:
+			return fmt.Errorf("unknown partition option '%s', expecting 'from' or 'to'", cn.Name)
+		}
+		ex.Left.Col = sel.Ti.Columns[cid]
+
+		switch cn.Type {
+		case graph.NodeStr:
+			ex.Right.ValType = ValStr
+		case graph.NodeNum:
+			ex.Right.ValType = ValNum
+		case graph.NodeVar:
+			ex.Right.ValType = ValVar
+		default:
+			return fmt.Errorf("%s: expecting a string, number or variable", cn.Name)
+		}
+		ex.Right.Val = cn.Val
+		addAndFilter(&sel.Where, ex)
+	}
+	return nil
+}
+
 func (co *Compiler) compileArgDistinctOn(sel *Select, arg graph.Arg) (err error) {
 	if err = validateArg(arg,
 		graph.NodeList, graph.NodeLabel,
@@ -301,12 +415,7 @@ func (co *Compiler) compileArgDistinctOn(sel *Select, arg graph.Arg) (err error)
 		if col, err = sel.Ti.GetColumn(node.Val); err != nil {
 			return
 		}
-		switch co.s.DBType() {
-		case "mysql":
-			sel.OrderBy = append(sel.OrderBy, OrderBy{Order: OrderAsc, Col: col})
-		default:
-			sel.DistinctOn = append(sel.DistinctOn, col)
-		}
+		sel.DistinctOn = append(sel.DistinctOn, col)
 	}
 
 	for _, cn := range node.Children {
@@ -314,12 +423,7 @@ func (co *Compiler) compileArgDistinctOn(sel *Select, arg graph.Arg) (err error)
 		if col, err = sel.Ti.GetColumn(cn.Val); err != nil {
 			return
 		}
-		switch co.s.DBType() {
-		case "mysql":
-			sel.OrderBy = append(sel.OrderBy, OrderBy{Order: OrderAsc, Col: col})
-		default:
-			sel.DistinctOn = append(sel.DistinctOn, col)
-		}
+		sel.DistinctOn = append(sel.DistinctOn, col)
 	}
 
 	return
@@ -339,12 +443,14 @@ func (co *Compiler) compileArgLimit(sel *Select, arg graph.Arg) (err error) {
 			return
 		}
 		sel.Paging.Limit = int32(n)
+		sel.Paging.Implicit = false
 
 	case graph.NodeVar:
 		if co.s.DBType() == "mysql" {
 			return dbArgErr("limit", "number", "mysql")
 		}
 		sel.Paging.LimitVar = node.Val
+		sel.Paging.Implicit = false
 	}
 	return
 }
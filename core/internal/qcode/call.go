@@ -0,0 +1,122 @@
+package qcode
+
+import (
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+const (
+	CallResultScalar = "scalar"
+	CallResultRow    = "row"
+	CallResultTable  = "table"
+)
+
+// CallConfig exposes a database function as a mutation root
+type CallConfig struct {
+	// Result is the shape of the value returned: scalar, row or table
+	Result string
+	// Roles allowed to call the function, all roles but anon when empty
+	Roles []string
+}
+
+func (cc CallConfig) allowed(role string) bool {
+	if len(cc.Roles) == 0 {
+		return role != "anon"
+	}
+	for _, r := range cc.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// isCall returns true if the mutation root calls a database function
+func (co *Compiler) isCall(f graph.Field) bool {
+	_, ok := co.c.Calls[f.Name]
+	return ok
+}
+
+// compileCall sets up a root that calls a database function. Scalar
+// functions return their value while functions returning records are
+// selected from like a table. Returns true for scalar calls since they
+// need no further compiling.
+func (co *Compiler) compileCall(qc *QCode, sel *Select, field graph.Field, role string) (bool, error) {
+	cc := co.c.Calls[field.Name]
+
+	if !cc.allowed(role) {
+		return false, fmt.Errorf("call blocked: %s (role: %s)", field.Name, role)
+	}
+	if co.s.DBType() == "mongodb" {
+		return false, fmt.Errorf("%s: function calls are not supported with mongodb", field.Name)
+	}
+
+	if cc.Result != CallResultScalar {
+		sel.Singular = cc.Result == CallResultRow
+		return false, nil
+	}
+
+	fn, ok := co.s.GetFunctions()[field.Name]
+	if !ok {
+		return false, fmt.Errorf("db function not found: %s", field.Name)
+	}
+	if len(field.Children) != 0 {
+		return false, fmt.Errorf("%s: scalar function calls cannot select fields", sel.FieldName)
+	}
+
+	for _, arg := range field.Args {
+		if arg.Name != "args" {
+			return false, fmt.Errorf("%s: argument '%s' is not supported on function calls",
+				sel.FieldName, arg.Name)
+		}
+		if err := validateArg(arg, graph.NodeObj); err != nil {
+			return false, err
+		}
+		args, err := newArgs(sel, fn, arg)
+		if err != nil {
+			return false, err
+		}
+		if sel.Args, err = callArgs(fn, args); err != nil {
+			return false, err
+		}
+	}
+
+	sel.Call = true
+	sel.Singular = true
+	sel.Func = fn
+	sel.Table = fn.Name
+	sel.Schema = fn.Schema
+
+	qc.Roots = append(qc.Roots, sel.ID)
+	return true, nil
+}
+
+// callArgs orders the arguments by the function inputs and passes them
+// by position since not all databases support named arguments
+func callArgs(fn sdata.DBFunction, args []Arg) ([]Arg, error) {
+	named := make(map[string]Arg, len(args))
+	for _, a := range args {
+		if a.Name == "" {
+			return args, nil
+		}
+		named[a.Name] = a
+	}
+
+	res := make([]Arg, 0, len(args))
+	for _, in := range fn.Inputs {
+		a, ok := named[in.Name]
+		if !ok {
+			break
+		}
+		a.Name = ""
+		res = append(res, a)
+		delete(named, in.Name)
+	}
+
+	for k := range named {
+		return nil, fmt.Errorf("db function %s: argument '%s' follows a missing argument", fn.Name, k)
+	}
+	return res, nil
+}
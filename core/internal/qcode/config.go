@@ -1,24 +1,75 @@
 package qcode
 
+import (
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3/internal/util"
+)
+
 type Config struct {
 	Vars            map[string]string
 	TConfig         map[string]TConfig
 	DefaultBlock    bool
+	DefaultDeny     bool
 	DefaultLimit    int
+	MaxLimitError   bool
 	DisableAgg      bool
 	DisableFuncs    bool
 	EnableCamelcase bool
+	Namer           *util.Namer
 	DBSchema        string
 	Validators      map[string]Validator
 
 	// EnableCacheTracking injects __gj_id fields with primary keys for cache row tracking
 	EnableCacheTracking bool
 
+	// Calls are the database functions exposed as mutation roots
+	Calls map[string]CallConfig
+
+	// Filters are the named SQL filters used as { _filter: "name" }
+	Filters map[string]string
+
+	// BlockOperators are the where clause operators blocked on every table
+	// for a role
+	BlockOperators map[string][]string
+
+	// SeparateForeignJoins runs the joins between tables on different
+	// servers (foreign data wrapper tables) as separate queries like
+	// cross-database joins
+	SeparateForeignJoins bool
+
+	// HintRoles are the roles allowed to use the @hint directive
+	HintRoles []string
+
+	// DecimalAsString renders decimal and numeric columns as strings so
+	// their precision is kept
+	DecimalAsString bool
+
+	// BigIntAsString renders 64-bit integer columns as strings so
+	// javascript clients do not lose precision
+	BigIntAsString bool
+
+	// Timezone converts timestamp columns to the output timezone
+	Timezone bool
+
+	// RequireTimezone rejects timestamp literals in filters that have
+	// no timezone offset
+	RequireTimezone bool
+
+	// AuthTrace leaves the fields blocked for the role out of queries instead
+	// of failing them and records why every field or table was left out
+	AuthTrace bool
+
 	defTrv trval
 }
 
 type TConfig struct {
 	OrderBy map[string][][2]string
+	// IDGenerator fills the primary key on insert (uuidv7, ulid or snowflake)
+	IDGenerator string
+	// DefaultLimit and MaxLimit override the global limits for the table
+	DefaultLimit int
+	MaxLimit     int
 }
 
 type TRConfig struct {
@@ -31,9 +82,12 @@ type TRConfig struct {
 
 type QueryConfig struct {
 	Limit            int
+	MaxLimit         int
 	Filters          []string
 	Columns          []string
 	DisableFunctions bool
+	AllowLock        bool
+	BlockOperators   []string
 	Block            bool
 }
 
@@ -67,12 +121,15 @@ type trval struct {
 	role string
 
 	query struct {
-		limit   int32
-		fil     *Exp
-		filNU   bool
-		cols    map[string]struct{}
-		disable struct{ funcs bool }
-		block   bool
+		limit    int32
+		maxLimit int32
+		fil      *Exp
+		filNU    bool
+		cols     map[string]struct{}
+		disable  struct{ funcs bool }
+		lock     bool
+		blockOps map[ExpOp]string
+		block    bool
 	}
 
 	insert struct {
@@ -116,7 +173,7 @@ func (co *Compiler) AddRole(role, schema, table string, trc TRConfig) error {
 	trv := trval{role: role}
 
 	// query config
-	trv.query.fil, trv.query.filNU, err = compileFilter(co.s, ti, trc.Query.Filters, false)
+	trv.query.fil, trv.query.filNU, err = compileFilter(co.s, ti, trc.Query.Filters, false, co.filters)
 	if err != nil {
 		return err
 	}
@@ -124,8 +181,15 @@ func (co *Compiler) AddRole(role, schema, table string, trc TRConfig) error {
 	if trc.Query.Limit > 0 {
 		trv.query.limit = int32(trc.Query.Limit)
 	}
+	if trc.Query.MaxLimit > 0 {
+		trv.query.maxLimit = int32(trc.Query.MaxLimit)
+	}
 	trv.query.cols = makeSet(trc.Query.Columns)
 	trv.query.disable.funcs = trc.Query.DisableFunctions
+	trv.query.lock = trc.Query.AllowLock
+	if trv.query.blockOps, err = blockedOps(trc.Query.BlockOperators); err != nil {
+		return err
+	}
 	trv.query.block = trc.Query.Block
 
 	// insert config
@@ -134,7 +198,7 @@ func (co *Compiler) AddRole(role, schema, table string, trc TRConfig) error {
 	trv.insert.block = trc.Insert.Block
 
 	// update config
-	trv.update.fil, trv.update.filNU, err = compileFilter(co.s, ti, trc.Update.Filters, false)
+	trv.update.fil, trv.update.filNU, err = compileFilter(co.s, ti, trc.Update.Filters, false, co.filters)
 	if err != nil {
 		return err
 	}
@@ -143,7 +207,7 @@ func (co *Compiler) AddRole(role, schema, table string, trc TRConfig) error {
 	trv.update.block = trc.Update.Block
 
 	// upsert config
-	trv.upsert.fil, trv.upsert.filNU, err = compileFilter(co.s, ti, trc.Upsert.Filters, false)
+	trv.upsert.fil, trv.upsert.filNU, err = compileFilter(co.s, ti, trc.Upsert.Filters, false, co.filters)
 	if err != nil {
 		return err
 	}
@@ -152,7 +216,7 @@ func (co *Compiler) AddRole(role, schema, table string, trc TRConfig) error {
 	trv.upsert.block = trc.Upsert.Block
 
 	// delete config
-	trv.delete.fil, trv.delete.filNU, err = compileFilter(co.s, ti, trc.Delete.Filters, false)
+	trv.delete.fil, trv.delete.filNU, err = compileFilter(co.s, ti, trc.Delete.Filters, false, co.filters)
 	if err != nil {
 		return err
 	}
@@ -173,11 +237,12 @@ func (co *Compiler) getRole(role, schema, table, field string) trval {
 		k = (role + ":" + schema + ":" + table)
 	}
 
-	// For anon roles when a trval is not found return the default trval
+	// For anon roles (or all roles with default deny) when a trval is not
+	// found return the default trval
 	tr, ok := co.tr[k]
 	tr.role = role
 
-	if !ok && role == "anon" {
+	if !ok && (role == "anon" || co.c.DefaultDeny) {
 		return co.c.defTrv
 	}
 	return tr
@@ -189,7 +254,7 @@ func (co *Compiler) getTConfig(schema, name string) TConfig {
 
 func (trv *trval) filter(qt QType) (*Exp, bool) {
 	switch qt {
-	case QTQuery:
+	case QTQuery, QTCall:
 		return trv.query.fil, trv.query.filNU
 	case QTInsert:
 		return nil, false
@@ -205,7 +270,7 @@ func (trv *trval) filter(qt QType) (*Exp, bool) {
 
 func (trv *trval) columnAllowed(qt *QCode, name string) bool {
 	switch qt.SType {
-	case QTQuery:
+	case QTQuery, QTCall:
 		_, ok := trv.query.cols[name]
 		return ok || len(trv.query.cols) == 0
 	case QTInsert:
@@ -231,9 +296,16 @@ func (trv *trval) limit(qt QType) int32 {
 	return 0
 }
 
+func (trv *trval) maxLimit(qt QType) int32 {
+	if qt == QTQuery && trv.query.maxLimit != 0 {
+		return trv.query.maxLimit
+	}
+	return 0
+}
+
 func (trv *trval) isBlocked(qt QType) bool {
 	switch qt {
-	case QTQuery:
+	case QTQuery, QTCall:
 		return trv.query.block
 	case QTInsert:
 		return trv.insert.block
@@ -251,6 +323,22 @@ func (trv *trval) isFuncsBlocked() bool {
 	return trv.query.disable.funcs
 }
 
+// blockedOps returns the operators for the operator names
+func blockedOps(names []string) (map[ExpOp]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ops := make(map[ExpOp]string, len(names))
+	for _, name := range names {
+		op, ok := expOpByName(name)
+		if !ok {
+			return nil, fmt.Errorf("block_operators: unknown operator: %s", name)
+		}
+		ops[op] = name
+	}
+	return ops, nil
+}
+
 // func (trv *trval) isMutationBlocked(mt MType, name string) error {
 // 	var blocked bool
 // 	switch mt {
@@ -2,7 +2,9 @@ package qcode
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dosco/graphjin/core/v3/internal/graph"
 	"github.com/dosco/graphjin/core/v3/internal/sdata"
@@ -16,9 +18,18 @@ func (co *Compiler) compileOpDirectives(qc *QCode, dirs []graph.Directive) error
 		case "cacheControl":
 			err = co.compileDirectiveCacheControl(qc, d)
 
+		case "cache":
+			err = co.compileDirectiveCache(qc, d)
+
 		case "constraint", "validate":
 			err = co.compileDirectiveConstraint(qc, d)
 
+		case "skipReturning", "skip_returning":
+			err = co.compileDirectiveSkipReturning(qc, d)
+
+		case "deprecated":
+			err = co.compileDirectiveDeprecated(d)
+
 		default:
 			err = fmt.Errorf("unknown operation directive: %s", d.Name)
 		}
@@ -51,6 +62,9 @@ func (co *Compiler) compileSelectorDirectives(qc *QCode,
 		case "schema":
 			err = co.compileDirectiveSchema(sel, d)
 
+		case "database":
+			err = co.compileDirectiveDatabase(sel, d)
+
 		case "notRelated", "not_related":
 			err = co.compileDirectiveNotRelated(sel, d)
 
@@ -61,6 +75,12 @@ func (co *Compiler) compileSelectorDirectives(qc *QCode,
 			sel.Singular = true
 			sel.Paging.Limit = 1
 
+		case "optional":
+			err = co.compileDirectiveOptional(qc, sel, d)
+
+		case "hint":
+			err = co.compileDirectiveHint(qc, sel, d, role)
+
 		default:
 			err = fmt.Errorf("no such selector directive: %s", d.Name)
 		}
@@ -108,6 +128,38 @@ func (co *Compiler) compileDirectiveSchema(sel *Select, d graph.Directive) (err
 	return
 }
 
+// compileDirectiveDatabase sets the database a root select runs on, the
+// routing itself is done before the query is compiled
+func (co *Compiler) compileDirectiveDatabase(sel *Select, d graph.Directive) (err error) {
+	if sel.ParentID != -1 {
+		return fmt.Errorf("only allowed on root fields")
+	}
+	arg, err := getArg(d.Args, "name", graph.NodeStr, graph.NodeLabel)
+	if err != nil {
+		return
+	}
+	sel.Database = arg.Val.Val
+	return
+}
+
+// compileDirectiveHint sets the query hint of a select, the hint is written
+// to the statement so it is only allowed for the configured roles and must
+// follow the hint grammar of the database
+func (co *Compiler) compileDirectiveHint(qc *QCode, sel *Select, d graph.Directive, role string) (err error) {
+	if qc.Type != QTQuery {
+		return fmt.Errorf("only valid on queries")
+	}
+	if !co.hintRoles[role] {
+		return fmt.Errorf("not allowed for role '%s'", role)
+	}
+	arg, err := getArg(d.Args, "value", graph.NodeStr)
+	if err != nil {
+		return
+	}
+	sel.Hint, err = parseHint(co.s.DBType(), arg.Val.Val)
+	return
+}
+
 func (co *Compiler) compileDirectiveAddRemove(
 	remove bool,
 	sel *Select,
@@ -126,6 +178,10 @@ func (co *Compiler) compileDirectiveAddRemove(
 	case !remove && arg.Val.Val != role:
 		f.SkipRender = SkipTypeDrop
 	}
+	if f.SkipRender == SkipTypeDrop {
+		co.omitField(sel, f, RuleRoleDirective, role,
+			fmt.Sprintf("@%s(ifRole: %s)", d.Name, arg.Val.Val))
+	}
 	return
 }
 
@@ -171,6 +227,10 @@ func (co *Compiler) compileDirectiveSkipInclude(
 			case !skip && arg.Val.Val != role:
 				f.SkipRender = SkipTypeNulled
 			}
+			if f.SkipRender == SkipTypeNulled {
+				co.omitField(sel, f, RuleRoleDirective, role,
+					fmt.Sprintf("@%s(ifRole: %s)", d.Name, arg.Val.Val))
+			}
 
 		default:
 			return unknownArg(arg)
@@ -211,6 +271,19 @@ func (co *Compiler) compileDirectiveCacheControl(qc *QCode, d graph.Directive) (
 	return nil
 }
 
+func (co *Compiler) compileDirectiveCache(qc *QCode, d graph.Directive) (err error) {
+	arg, err := getArg(d.Args, "ttl", graph.NodeNum)
+	if err != nil {
+		return
+	}
+	ttl, err := strconv.ParseInt(arg.Val.Val, 10, 32)
+	if err != nil || ttl <= 0 {
+		return fmt.Errorf("@cache: ttl must be a positive number of seconds")
+	}
+	qc.Cache.TTL = int32(ttl)
+	return nil
+}
+
 func (co *Compiler) compileDirectiveConstraint(qc *QCode, d graph.Directive) (err error) {
 	a, err := getArg(d.Args, "variable", graph.NodeStr)
 	if err != nil {
@@ -230,6 +303,54 @@ func (co *Compiler) compileDirectiveConstraint(qc *QCode, d graph.Directive) (er
 	return
 }
 
+func (co *Compiler) compileDirectiveSkipReturning(qc *QCode, d graph.Directive) (err error) {
+	if qc.Type != QTMutation {
+		return fmt.Errorf("@%s: only valid on mutations", d.Name)
+	}
+	if len(d.Args) != 0 {
+		return unknownArg(d.Args[0])
+	}
+	qc.SkipReturning = true
+	return nil
+}
+
+// compileDirectiveDeprecated validates the arguments, the deprecation is
+// read from the saved query by the allow list and applied at execution
+func (co *Compiler) compileDirectiveDeprecated(d graph.Directive) (err error) {
+	for _, arg := range d.Args {
+		switch arg.Name {
+		case "reason", "replacement":
+			if err = validateArg(arg, graph.NodeStr); err != nil {
+				return
+			}
+		case "sunset":
+			if err = validateArg(arg, graph.NodeStr); err != nil {
+				return
+			}
+			if _, err = time.Parse("2006-01-02", arg.Val.Val); err != nil {
+				return fmt.Errorf("@deprecated: sunset must be a date (YYYY-MM-DD): %s", arg.Val.Val)
+			}
+		default:
+			return unknownArg(arg)
+		}
+	}
+	return nil
+}
+
+func (co *Compiler) compileDirectiveOptional(qc *QCode, sel *Select, d graph.Directive) error {
+	if qc.Type != QTMutation {
+		return fmt.Errorf("only valid on mutations")
+	}
+	if sel.ParentID == -1 {
+		return fmt.Errorf("not valid on the root of a mutation")
+	}
+	if len(d.Args) != 0 {
+		return unknownArg(d.Args[0])
+	}
+	sel.Optional = true
+	return nil
+}
+
 func (co *Compiler) compileDirectiveNotRelated(sel *Select, d graph.Directive) error {
 	sel.Rel.Type = sdata.RelSkip
 	return nil
@@ -154,6 +154,13 @@ func (ast *aexpst) parseNode(av aexp, node *graph.Node, selID int32) (*Exp, erro
 		return ex, nil
 	}
 
+	if name == "_filter" {
+		if err := ast.processNamedFilter(av, ex, node, selID); err != nil {
+			return nil, err
+		}
+		return ex, nil
+	}
+
 	switch node.Type {
 	// { column: { op: value } }
 	case graph.NodeObj:
@@ -196,6 +203,11 @@ func (ast *aexpst) parseNode(av aexp, node *graph.Node, selID int32) (*Exp, erro
 			return ex, nil
 		}
 
+		// Duration operators set their own value type
+		if ex.Right.ValType == ValNowMinus {
+			return ex, nil
+		}
+
 		if ast.savePath {
 			ex.Right.Path = append(ex.Right.Path, vn.Name)
 		}
@@ -313,6 +325,9 @@ func (ast *aexpst) processOpAndVal(av aexp, ex *Exp, node *graph.Node) (bool, er
 	case "eq", "equals":
 		ex.Op = OpEquals
 		ex.Right.Val = node.Val
+	case "eq_ci", "equalsCI", "equals_ci":
+		ex.Op = OpEqualsCI
+		ex.Right.Val = node.Val
 	case "neq", "notEquals", "not_equals":
 		ex.Op = OpNotEquals
 		ex.Right.Val = node.Val
@@ -396,6 +411,12 @@ func (ast *aexpst) processOpAndVal(av aexp, ex *Exp, node *graph.Node) (bool, er
 		ex.Op = OpDistinct
 		ex.Right.Val = node.Val
 
+	// Timestamp operators taking an ISO-8601 duration
+	case "withinLast", "within_last":
+		return processNowMinusOp(ex, node, OpGreaterOrEquals)
+	case "olderThan", "older_than":
+		return processNowMinusOp(ex, node, OpLesserThan)
+
 	// GIS/Spatial operators
 	case "st_dwithin", "stDWithin", "st_d_within", "dwithin":
 		return ast.processGeoOp(ex, node, OpGeoDistance)
@@ -423,6 +444,39 @@ func (ast *aexpst) processOpAndVal(av aexp, ex *Exp, node *graph.Node) (bool, er
 	return true, nil
 }
 
+// expOpByName returns the operator for a where clause operator name
+func expOpByName(name string) (ExpOp, bool) {
+	ex := newExp()
+	// the operator is set before the value is checked
+	_, _ = (&aexpst{}).processOpAndVal(aexp{}, ex, &graph.Node{Name: name})
+	return ex.Op, ex.Op != OpNop
+}
+
+// checkBlockedOps returns an error when the expression uses an operator
+// that is blocked for the role on the table of its column
+func (co *Compiler) checkBlockedOps(ex *Exp, role string) error {
+	if ex == nil {
+		return nil
+	}
+	if col := ex.Left.Col; col.Name != "" {
+		name, ok := co.blockOps[role][ex.Op]
+		if !ok {
+			tr := co.getRole(role, col.Schema, col.Table, "")
+			name, ok = tr.query.blockOps[ex.Op]
+		}
+		if ok {
+			return fmt.Errorf("[Where] operator '%s' is blocked for role '%s' on column: %s.%s",
+				name, role, col.Table, col.Name)
+		}
+	}
+	for _, c := range ex.Children {
+		if err := co.checkBlockedOps(c, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getExpType(node *graph.Node) (ValType, error) {
 	switch node.Type {
 	case graph.NodeStr:
@@ -686,7 +740,7 @@ func parseGeoUnit(val string) GeoUnit {
 }
 
 func (ast *aexpst) processColumn(av aexp, ex *Exp, node *graph.Node, selID int32) (bool, error) {
-	nn := ast.co.ParseName(node.Name)
+	nn := ast.co.ParseColumn(av.ti.Name, node.Name)
 
 	// Check for JSON path operators in column name (e.g., "validity_period->>issue_date")
 	if strings.Contains(nn, "->>") {
@@ -756,7 +810,7 @@ func (ast *aexpst) processColumn(av aexp, ex *Exp, node *graph.Node, selID int32
 
 func (ast *aexpst) processJSONPath(av aexp, ex *Exp, node *graph.Node, selID int32) (bool, error) {
 	// Check if this is a JSON/JSONB column with nested path
-	nn := ast.co.ParseName(node.Name)
+	nn := ast.co.ParseColumn(av.ti.Name, node.Name)
 	col, err := av.ti.GetColumn(nn)
 	if err != nil {
 		// Column doesn't exist at this level, might be a JSON path
@@ -864,7 +918,7 @@ func (ast *aexpst) processNestedTable(av aexp, ex *Exp, node *graph.Node) (bool,
 	// it as a nested table reference. This avoids FK columns (e.g. territoryid)
 	// being misinterpreted as relationship joins when they match a table name.
 	if node.Name != "" {
-		nn := ast.co.ParseName(node.Name)
+		nn := ast.co.ParseColumn(ti.Name, node.Name)
 		if _, colErr := ti.GetColumn(nn); colErr == nil {
 			return false, nil
 		}
@@ -887,7 +941,7 @@ func (ast *aexpst) processNestedTable(av aexp, ex *Exp, node *graph.Node) (bool,
 			k == "_and" || k == "_or" || k == "_not" {
 			break
 		}
-		curr = ast.co.ParseName(k)
+		curr = ast.co.ParseTable(k)
 
 		if curr == ti.Name {
 			continue
@@ -1,6 +1,7 @@
 package qcode
 
 import (
+	"bytes"
 	"fmt"
 	"strconv"
 	"strings"
@@ -19,6 +20,12 @@ func (co *Compiler) compileFields(
 	tr trval,
 	role string,
 ) (err error) {
+	if qc.SkipReturning && sel.ParentID == -1 {
+		if field, err = affectedRowsField(op, sel, field); err != nil {
+			return
+		}
+	}
+
 	sel.Fields = make([]Field, 0, len(field.Children))
 	sel.BCols = make([]Column, 0, len(field.Children))
 
@@ -26,6 +33,11 @@ func (co *Compiler) compileFields(
 		return
 	}
 
+	// nothing is left to select when auth trace left out every field
+	if len(sel.Fields) == 0 && len(sel.Omitted) != 0 {
+		sel.SkipRender = SkipTypeBlocked
+	}
+
 	if err = validateSelector(qc, sel, tr); err != nil {
 		return
 	}
@@ -44,6 +56,26 @@ func (co *Compiler) compileFields(
 	return nil
 }
 
+// affectedRowsField replaces the fields selected from a mutation with
+// a count of the rows it affected (affected_rows: count_<primary key>)
+func affectedRowsField(op *graph.Operation, sel *Select, field graph.Field) (graph.Field, error) {
+	if len(sel.Ti.PrimaryCols) == 0 {
+		return field, fmt.Errorf("%s: skip returning requires a primary key", sel.FieldName)
+	}
+
+	f := graph.Field{
+		ID:       int32(len(op.Fields)),
+		ParentID: field.ID,
+		Name:     "count_" + sel.Ti.PrimaryCols[0].Name,
+		Alias:    "affected_rows",
+	}
+	op.Fields = append(op.Fields, f)
+
+	sel.Singular = true
+	field.Children = []int32{f.ID}
+	return field, nil
+}
+
 func (co *Compiler) compileChildColumns(
 	st *util.StackInt32,
 	op *graph.Operation,
@@ -60,7 +92,7 @@ func (co *Compiler) compileChildColumns(
 		field := Field{ID: id, ParentID: sel.ID, Type: FieldTypeCol}
 		f := op.Fields[cid]
 
-		name := co.ParseName(f.Name)
+		name := co.ParseColumn(sel.Ti.Name, f.Name)
 
 		if f.Alias != "" {
 			field.FieldName = f.Alias
@@ -72,6 +104,13 @@ func (co *Compiler) compileChildColumns(
 		// these later to strip the response json
 		if sel.Rel.Type == sdata.RelRemote {
 			sel.Fields = append(sel.Fields, field)
+
+			var w bytes.Buffer
+			if sel.RemoteQuery != "" {
+				w.WriteString(sel.RemoteQuery + " ")
+			}
+			graph.WriteField(&w, op.Fields, cid)
+			sel.RemoteQuery = w.String()
 			continue
 		}
 
@@ -104,6 +143,10 @@ func (co *Compiler) compileChildColumns(
 
 		switch {
 		case isCol:
+			field.AsString = (field.Col.AsString && !field.Col.Array) ||
+				(co.c.DecimalAsString && field.Col.IsDecimal()) ||
+				(co.c.BigIntAsString && field.Col.IsBigInt())
+			field.Timezone = co.c.Timezone && field.Col.IsTimestamp()
 		case isFunc:
 			field.Type = FieldTypeFunc
 			field.Func = fn.Func
@@ -121,6 +164,13 @@ func (co *Compiler) compileChildColumns(
 			return err
 		}
 
+		if co.c.AuthTrace && qc.SType == QTQuery {
+			if rule, reason := deniedField(qc, field, tr); rule != "" {
+				co.omit(sel, field.FieldName, rule, role, reason)
+				continue
+			}
+		}
+
 		if field.Col.Blocked {
 			return fmt.Errorf("column: '%s.%s.%s' blocked",
 				field.Col.Schema,
@@ -338,6 +388,48 @@ func (co *Compiler) orderByClusterKeys(sel *Select) {
 	}
 }
 
+// orderByDistinctOn moves the distinct columns to the front of the ORDER BY
+// list. Postgres requires DISTINCT ON expressions to lead the ORDER BY and the
+// other dialects use the same ordering to pick which row of each distinct
+// group is kept, so the first row per group always follows the user's order.
+func (co *Compiler) orderByDistinctOn(sel *Select) error {
+	if len(sel.DistinctOn) == 0 {
+		return nil
+	}
+
+	switch co.s.DBType() {
+	case "", "postgres", "mongodb":
+	default:
+		if !sel.GroupCols && sel.Ti.PrimaryCol.Name == "" {
+			return fmt.Errorf("%s: distinct requires a primary key on table: %s",
+				sel.FieldName, sel.Ti.Name)
+		}
+	}
+
+	obList := make([]OrderBy, 0, len(sel.DistinctOn)+len(sel.OrderBy))
+	used := make(map[int]bool, len(sel.OrderBy))
+
+	for _, col := range sel.DistinctOn {
+		ob := OrderBy{Col: col, Order: OrderAsc}
+		for i, o := range sel.OrderBy {
+			if !used[i] && o.Var == "" && o.Col.Table == col.Table && o.Col.Name == col.Name {
+				ob, used[i] = o, true
+				break
+			}
+		}
+		sel.addBaseCol(Column{Col: col})
+		obList = append(obList, ob)
+	}
+
+	for i, ob := range sel.OrderBy {
+		if !used[i] {
+			obList = append(obList, ob)
+		}
+	}
+	sel.OrderBy = obList
+	return nil
+}
+
 func (co *Compiler) orderByIDCol(sel *Select) error {
 	if sel.Ti.PrimaryCol.Name == "" {
 		return fmt.Errorf("table requires primary key: %s", sel.Ti.Name)
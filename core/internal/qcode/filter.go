@@ -0,0 +1,228 @@
+package qcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+// NamedFilter is a SQL filter defined in the config and used in where
+// clauses and role filters as { _filter: "name" }
+type NamedFilter struct {
+	Name   string
+	Tokens []FilterToken
+}
+
+// FilterToken is a part of the filter SQL. Columns are marked so they
+// are rendered with the table alias.
+type FilterToken struct {
+	Val string
+	Col bool
+}
+
+type filterTokenType int8
+
+const (
+	ftOther filterTokenType = iota
+	ftWord
+	ftQuotedIdent
+	ftString
+	ftNumber
+	ftSpace
+)
+
+type filterToken struct {
+	typ filterTokenType
+	val string
+}
+
+// filterKeywords are the words in a filter that are not column names
+var filterKeywords = map[string]struct{}{
+	"and": {}, "or": {}, "not": {}, "is": {}, "null": {}, "in": {},
+	"like": {}, "ilike": {}, "between": {}, "true": {}, "false": {},
+	"exists": {}, "case": {}, "when": {}, "then": {}, "else": {}, "end": {},
+	"distinct": {}, "from": {}, "similar": {}, "to": {}, "any": {}, "all": {},
+	"some": {}, "escape": {}, "interval": {}, "collate": {}, "at": {},
+	"time": {}, "zone": {}, "as": {}, "current_date": {}, "current_time": {},
+	"current_timestamp": {}, "localtime": {}, "localtimestamp": {},
+	"unknown": {}, "glob": {}, "regexp": {}, "rlike": {},
+}
+
+// parseFilterSQL splits the filter SQL into tokens
+func parseFilterSQL(name, sql string) ([]filterToken, error) {
+	var tokens []filterToken
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ';':
+			return nil, fmt.Errorf("filter %s: ';' is not allowed", name)
+
+		case strings.HasPrefix(sql[i:], "--") || strings.HasPrefix(sql[i:], "/*"):
+			return nil, fmt.Errorf("filter %s: comments are not allowed", name)
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			j := i + 1
+			for j < len(sql) && strings.IndexByte(" \t\n\r", sql[j]) != -1 {
+				j++
+			}
+			tokens = append(tokens, filterToken{ftSpace, " "})
+			i = j
+
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if sql[j] != c {
+					continue
+				}
+				// a doubled quote is an escaped quote
+				if j+1 < len(sql) && sql[j+1] == c {
+					j++
+					continue
+				}
+				break
+			}
+			if j == len(sql) {
+				return nil, fmt.Errorf("filter %s: unterminated quote", name)
+			}
+			if c == '\'' {
+				tokens = append(tokens, filterToken{ftString, sql[i : j+1]})
+			} else {
+				tokens = append(tokens, filterToken{ftQuotedIdent, sql[i+1 : j]})
+			}
+			i = j + 1
+
+		case isFilterWordChar(c):
+			j := i + 1
+			for j < len(sql) && isFilterWordChar(sql[j]) {
+				j++
+			}
+			typ := ftWord
+			if c >= '0' && c <= '9' {
+				typ = ftNumber
+			}
+			tokens = append(tokens, filterToken{typ, sql[i:j]})
+			i = j
+
+		default:
+			tokens = append(tokens, filterToken{ftOther, string(c)})
+			i++
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("filter %s: empty filter", name)
+	}
+	return tokens, nil
+}
+
+func isFilterWordChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// bindFilter checks the filter against the table and marks the columns.
+// Words that are not keywords, function names or types must be columns
+// of the table.
+func bindFilter(name string, tokens []filterToken, ti sdata.DBTable) (*NamedFilter, error) {
+	nf := &NamedFilter{Name: name, Tokens: make([]FilterToken, 0, len(tokens))}
+
+	for i, t := range tokens {
+		switch t.typ {
+		case ftWord:
+			if filterNotColumn(tokens, i) {
+				break
+			}
+			col, err := ti.GetColumn(t.val)
+			if err != nil {
+				return nil, fmt.Errorf("filter %s: column '%s' not found on table '%s'",
+					name, t.val, ti.Name)
+			}
+			nf.Tokens = append(nf.Tokens, FilterToken{Val: col.Name, Col: true})
+			continue
+
+		case ftQuotedIdent:
+			col, err := ti.GetColumn(t.val)
+			if err != nil {
+				return nil, fmt.Errorf("filter %s: column '%s' not found on table '%s'",
+					name, t.val, ti.Name)
+			}
+			nf.Tokens = append(nf.Tokens, FilterToken{Val: col.Name, Col: true})
+			continue
+		}
+		nf.Tokens = append(nf.Tokens, FilterToken{Val: t.val})
+	}
+	return nf, nil
+}
+
+// filterNotColumn returns true if the word at i is a keyword, a function
+// name or a type in a cast
+func filterNotColumn(tokens []filterToken, i int) bool {
+	if _, ok := filterKeywords[strings.ToLower(tokens[i].val)]; ok {
+		return true
+	}
+
+	// function call
+	if n := filterSkipSpace(tokens, i+1, 1); n < len(tokens) && tokens[n].val == "(" {
+		return true
+	}
+
+	// type in a cast: col::type or CAST(col AS type)
+	if p := filterSkipSpace(tokens, i-1, -1); p >= 0 {
+		if strings.EqualFold(tokens[p].val, "as") {
+			return true
+		}
+		if tokens[p].val == ":" && p > 0 && tokens[p-1].val == ":" {
+			return true
+		}
+	}
+	return false
+}
+
+func filterSkipSpace(tokens []filterToken, i, dir int) int {
+	for i >= 0 && i < len(tokens) && tokens[i].typ == ftSpace {
+		i += dir
+	}
+	return i
+}
+
+func (co *Compiler) setNamedFilters(filters map[string]string) error {
+	co.filters = make(map[string][]filterToken, len(filters))
+	for k, v := range filters {
+		tokens, err := parseFilterSQL(k, v)
+		if err != nil {
+			return err
+		}
+		co.filters[k] = tokens
+	}
+	return nil
+}
+
+// processNamedFilter compiles { _filter: "name" } into the named filter
+// bound to the current table
+func (ast *aexpst) processNamedFilter(av aexp, ex *Exp, node *graph.Node, selID int32) error {
+	if node.Type != graph.NodeStr && node.Type != graph.NodeLabel {
+		return fmt.Errorf("[Where] _filter: expecting the name of a filter")
+	}
+	if ast.co.s.DBType() == "mongodb" {
+		return fmt.Errorf("[Where] _filter: filters are not supported with mongodb")
+	}
+
+	tokens, ok := ast.co.filters[node.Val]
+	if !ok {
+		return fmt.Errorf("[Where] _filter: filter not found: %s", node.Val)
+	}
+
+	nf, err := bindFilter(node.Val, tokens, av.ti)
+	if err != nil {
+		return err
+	}
+
+	ex.Op = OpNamedFilter
+	ex.Left.ID = selID
+	ex.Left.Table = av.ti.Name
+	ex.SQL = nf
+	return nil
+}
@@ -16,11 +16,12 @@ func _() {
 	_ = x[QTUpdate-5]
 	_ = x[QTDelete-6]
 	_ = x[QTUpsert-7]
+	_ = x[QTCall-8]
 }
 
-const _QType_name = "UnknownQuerySubcriptionMutationInsertUpdateDeleteUpsert"
+const _QType_name = "UnknownQuerySubcriptionMutationInsertUpdateDeleteUpsertCall"
 
-var _QType_index = [...]uint8{0, 7, 12, 23, 31, 37, 43, 49, 55}
+var _QType_index = [...]uint8{0, 7, 12, 23, 31, 37, 43, 49, 55, 59}
 
 func (i QType) String() string {
 	idx := int(i) - 0
@@ -173,11 +174,12 @@ func _() {
 	_ = x[ValDBVar-7]
 	_ = x[ValSubQuery-8]
 	_ = x[ValPartitionBound-9]
+	_ = x[ValNowMinus-10]
 }
 
-const _ValType_name = "ValStrValNumValBoolValListValObjValVarValDBVarValSubQueryRenders as NOW() - INTERVAL N days (dialect-specific)"
+const _ValType_name = "ValStrValNumValBoolValListValObjValVarValDBVarValSubQueryRenders as NOW() - INTERVAL N days (dialect-specific)ValNowMinus"
 
-var _ValType_index = [...]uint8{0, 6, 12, 19, 26, 32, 38, 46, 57, 110}
+var _ValType_index = [...]uint8{0, 6, 12, 19, 26, 32, 38, 46, 57, 110, 121}
 
 func (i ValType) String() string {
 	idx := int(i) - 1
@@ -238,11 +240,13 @@ func _() {
 	_ = x[OpGeoTouches-45]
 	_ = x[OpGeoOverlaps-46]
 	_ = x[OpGeoNear-47]
+	_ = x[OpEqualsCI-48]
+	_ = x[OpNamedFilter-49]
 }
 
-const _ExpOp_name = "OpNopOpAndOpOrOpNotOpEqualsOpNotEqualsOpGreaterOrEqualsOpLesserOrEqualsOpGreaterThanOpLesserThanOpInOpNotInOpLikeOpNotLikeOpILikeOpNotILikeOpSimilarOpNotSimilarOpRegexOpNotRegexOpIRegexOpNotIRegexOpContainsOpContainedInOpHasInCommonOpHasKeyOpHasKeyAnyOpHasKeyAllOpIsNullOpIsNotNullOpTsQueryOpFalseOpNotDistinctOpDistinctOpEqualsTrueOpNotEqualsTrueOpSelectExistsJSON path operator (->)JSON path text operator (->>)ST_DWithin - distance-based filteringST_Within - geometry A within BST_Contains - geometry A contains BST_Intersects - geometries intersectST_CoveredBy - geometry A covered by BST_Covers - geometry A covers BST_Touches - geometries touch at boundaryST_Overlaps - geometries overlapMongoDB $near / $nearSphere"
+const _ExpOp_name = "OpNopOpAndOpOrOpNotOpEqualsOpNotEqualsOpGreaterOrEqualsOpLesserOrEqualsOpGreaterThanOpLesserThanOpInOpNotInOpLikeOpNotLikeOpILikeOpNotILikeOpSimilarOpNotSimilarOpRegexOpNotRegexOpIRegexOpNotIRegexOpContainsOpContainedInOpHasInCommonOpHasKeyOpHasKeyAnyOpHasKeyAllOpIsNullOpIsNotNullOpTsQueryOpFalseOpNotDistinctOpDistinctOpEqualsTrueOpNotEqualsTrueOpSelectExistsJSON path operator (->)JSON path text operator (->>)ST_DWithin - distance-based filteringST_Within - geometry A within BST_Contains - geometry A contains BST_Intersects - geometries intersectST_CoveredBy - geometry A covered by BST_Covers - geometry A covers BST_Touches - geometries touch at boundaryST_Overlaps - geometries overlapMongoDB $near / $nearSpherecase-insensitive equalsnamed SQL filter"
 
-var _ExpOp_index = [...]uint16{0, 5, 10, 14, 19, 27, 38, 55, 71, 84, 96, 100, 107, 113, 122, 129, 139, 148, 160, 167, 177, 185, 196, 206, 219, 232, 240, 251, 262, 270, 281, 290, 297, 310, 320, 332, 347, 361, 384, 413, 450, 481, 516, 552, 590, 621, 662, 694, 721}
+var _ExpOp_index = [...]uint16{0, 5, 10, 14, 19, 27, 38, 55, 71, 84, 96, 100, 107, 113, 122, 129, 139, 148, 160, 167, 177, 185, 196, 206, 219, 232, 240, 251, 262, 270, 281, 290, 297, 310, 320, 332, 347, 361, 384, 413, 450, 481, 516, 552, 590, 621, 662, 694, 721, 744, 760}
 
 func (i ExpOp) String() string {
 	idx := int(i) - 0
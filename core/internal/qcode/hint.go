@@ -0,0 +1,340 @@
+package qcode
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	hintIdentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	hintNumRe   = regexp.MustCompile(`^[0-9]+$`)
+	// pg_hint_plan row corrections like #10, +10, -10 or *2.5
+	hintRowsRe = regexp.MustCompile(`^[#+*-]?[0-9]+(\.[0-9]+)?$`)
+	// MongoDB index names like name_1 or address.city_1
+	hintMongoRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+)
+
+// pgHints are the pg_hint_plan hints, Set is left out since it can change
+// any setting of the session
+var pgHints = []string{
+	"SeqScan", "TidScan", "IndexScan", "IndexOnlyScan", "BitmapScan",
+	"NoSeqScan", "NoTidScan", "NoIndexScan", "NoIndexOnlyScan", "NoBitmapScan",
+	"NestLoop", "HashJoin", "MergeJoin", "NoNestLoop", "NoHashJoin", "NoMergeJoin",
+	"Leading", "Memoize", "NoMemoize", "Rows", "Parallel",
+}
+
+// mssqlHints are the SQL Server query hints allowed in OPTION (...), the
+// value is true for the hints followed by a number
+var mssqlHints = map[string]bool{
+	"HASH JOIN":                             false,
+	"LOOP JOIN":                             false,
+	"MERGE JOIN":                            false,
+	"HASH GROUP":                            false,
+	"ORDER GROUP":                           false,
+	"CONCAT UNION":                          false,
+	"HASH UNION":                            false,
+	"MERGE UNION":                           false,
+	"FORCE ORDER":                           false,
+	"KEEP PLAN":                             false,
+	"KEEPFIXED PLAN":                        false,
+	"RECOMPILE":                             false,
+	"ROBUST PLAN":                           false,
+	"EXPAND VIEWS":                          false,
+	"OPTIMIZE FOR UNKNOWN":                  false,
+	"NO_PERFORMANCE_SPOOL":                  false,
+	"IGNORE_NONCLUSTERED_COLUMNSTORE_INDEX": false,
+	"DISABLE_OPTIMIZED_NESTED_LOOP":         false,
+	"FAST":                                  true,
+	"MAXDOP":                                true,
+	"MAXRECURSION":                          true,
+}
+
+// parseHint checks the @hint value against the hint grammar of the database
+// and returns the hint as it is written to the statement
+func parseHint(dbType, v string) (string, error) {
+	switch dbType {
+	case "postgres":
+		return parsePgHint(v)
+	case "mysql", "mariadb":
+		return parseIndexHint(v)
+	case "sqlite":
+		return parseSQLiteHint(v)
+	case "mssql":
+		return parseMSSQLHint(v)
+	case "mongodb":
+		if v = strings.TrimSpace(v); hintMongoRe.MatchString(v) {
+			return v, nil
+		}
+		return "", fmt.Errorf("invalid hint: expecting an index name")
+	}
+	return "", fmt.Errorf("not supported on %s", dbType)
+}
+
+// hintTokens splits a hint into words and the punctuation ( ) and , any
+// other character is an error
+func hintTokens(v string) ([]string, error) {
+	var tokens []string
+	start := -1
+	for i, r := range v {
+		word := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') || strings.ContainsRune("_.#+*-", r)
+		if word {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 {
+			tokens = append(tokens, v[start:i])
+			start = -1
+		}
+		switch r {
+		case ' ', '\t':
+		case '(', ')', ',':
+			tokens = append(tokens, string(r))
+		default:
+			return nil, fmt.Errorf("invalid hint: unexpected '%c'", r)
+		}
+	}
+	if start != -1 {
+		tokens = append(tokens, v[start:])
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid hint: empty")
+	}
+	return tokens, nil
+}
+
+// hintParser walks the tokens of a hint
+type hintParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *hintParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *hintParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *hintParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword consumes the next token if it is one of the keywords
+func (p *hintParser) keyword(kw ...string) (string, bool) {
+	for _, k := range kw {
+		if strings.EqualFold(p.peek(), k) {
+			p.pos++
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func (p *hintParser) expect(t string) error {
+	if v := p.next(); v != t {
+		return fmt.Errorf("invalid hint: expecting '%s' got '%s'", t, v)
+	}
+	return nil
+}
+
+// ident consumes an identifier
+func (p *hintParser) ident() (string, error) {
+	v := p.next()
+	if !hintIdentRe.MatchString(v) {
+		return "", fmt.Errorf("invalid hint: expecting a name got '%s'", v)
+	}
+	return v, nil
+}
+
+// parseIndexHint parses MySQL index hints:
+// {USE|FORCE|IGNORE} {INDEX|KEY} [FOR {JOIN|ORDER BY|GROUP BY}] (name, ...)
+func parseIndexHint(v string) (string, error) {
+	tokens, err := hintTokens(v)
+	if err != nil {
+		return "", err
+	}
+	p := &hintParser{tokens: tokens}
+
+	var hints []string
+	for !p.done() {
+		if len(hints) != 0 {
+			p.keyword(",")
+		}
+		var sb strings.Builder
+
+		kw, ok := p.keyword("USE", "FORCE", "IGNORE")
+		if !ok {
+			return "", fmt.Errorf("invalid hint: expecting USE, FORCE or IGNORE got '%s'", p.peek())
+		}
+		sb.WriteString(kw)
+
+		if kw, ok = p.keyword("INDEX", "KEY"); !ok {
+			return "", fmt.Errorf("invalid hint: expecting INDEX or KEY got '%s'", p.peek())
+		}
+		sb.WriteString(" " + kw)
+
+		if _, ok := p.keyword("FOR"); ok {
+			switch kw, _ := p.keyword("JOIN", "ORDER", "GROUP"); kw {
+			case "JOIN":
+				sb.WriteString(" FOR JOIN")
+			case "ORDER", "GROUP":
+				if _, ok := p.keyword("BY"); !ok {
+					return "", fmt.Errorf("invalid hint: expecting BY got '%s'", p.peek())
+				}
+				sb.WriteString(" FOR " + kw + " BY")
+			default:
+				return "", fmt.Errorf("invalid hint: expecting JOIN, ORDER BY or GROUP BY got '%s'", p.peek())
+			}
+		}
+
+		if err := p.expect("("); err != nil {
+			return "", err
+		}
+		var names []string
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return "", err
+			}
+			names = append(names, name)
+			if _, ok := p.keyword(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return "", err
+		}
+		sb.WriteString(" (" + strings.Join(names, ", ") + ")")
+		hints = append(hints, sb.String())
+	}
+	return strings.Join(hints, " "), nil
+}
+
+// parseSQLiteHint parses INDEXED BY name or NOT INDEXED
+func parseSQLiteHint(v string) (string, error) {
+	tokens, err := hintTokens(v)
+	if err != nil {
+		return "", err
+	}
+	p := &hintParser{tokens: tokens}
+
+	var hint string
+	switch kw, _ := p.keyword("INDEXED", "NOT"); kw {
+	case "INDEXED":
+		if _, ok := p.keyword("BY"); !ok {
+			return "", fmt.Errorf("invalid hint: expecting BY got '%s'", p.peek())
+		}
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		hint = "INDEXED BY " + name
+	case "NOT":
+		if _, ok := p.keyword("INDEXED"); !ok {
+			return "", fmt.Errorf("invalid hint: expecting INDEXED got '%s'", p.peek())
+		}
+		hint = "NOT INDEXED"
+	default:
+		return "", fmt.Errorf("invalid hint: expecting INDEXED BY or NOT INDEXED")
+	}
+	if !p.done() {
+		return "", fmt.Errorf("invalid hint: unexpected '%s'", p.peek())
+	}
+	return hint, nil
+}
+
+// parseMSSQLHint parses a comma separated list of SQL Server query hints
+func parseMSSQLHint(v string) (string, error) {
+	tokens, err := hintTokens(v)
+	if err != nil {
+		return "", err
+	}
+	p := &hintParser{tokens: tokens}
+
+	var hints []string
+	for !p.done() {
+		var words []string
+		for !p.done() && p.peek() != "," {
+			words = append(words, strings.ToUpper(p.next()))
+		}
+		p.keyword(",")
+
+		hint := strings.Join(words, " ")
+		if _, ok := mssqlHints[hint]; ok && !mssqlHints[hint] {
+			hints = append(hints, hint)
+			continue
+		}
+		if n := len(words); n > 1 && hintNumRe.MatchString(words[n-1]) {
+			if mssqlHints[strings.Join(words[:n-1], " ")] {
+				hints = append(hints, hint)
+				continue
+			}
+		}
+		return "", fmt.Errorf("invalid hint: unknown query hint '%s'", hint)
+	}
+	return strings.Join(hints, ", "), nil
+}
+
+// parsePgHint parses pg_hint_plan hints like IndexScan(orders orders_idx)
+// or Leading((a b) c)
+func parsePgHint(v string) (string, error) {
+	tokens, err := hintTokens(v)
+	if err != nil {
+		return "", err
+	}
+	p := &hintParser{tokens: tokens}
+
+	var hints []string
+	for !p.done() {
+		name, ok := p.keyword(pgHints...)
+		if !ok {
+			return "", fmt.Errorf("invalid hint: unknown hint '%s'", p.peek())
+		}
+		args, err := p.pgHintArgs(name == "Leading")
+		if err != nil {
+			return "", err
+		}
+		hints = append(hints, name+args)
+	}
+	return strings.Join(hints, " "), nil
+}
+
+// pgHintArgs parses the parenthesized arguments of a pg_hint_plan hint,
+// only Leading takes nested parentheses
+func (p *hintParser) pgHintArgs(nested bool) (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var args []string
+	for p.peek() != ")" {
+		switch t := p.peek(); {
+		case t == "":
+			return "", fmt.Errorf("invalid hint: expecting ')'")
+		case t == "(" && nested:
+			v, err := p.pgHintArgs(nested)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, v)
+		case hintIdentRe.MatchString(t), hintRowsRe.MatchString(t):
+			args = append(args, p.next())
+		default:
+			return "", fmt.Errorf("invalid hint: unexpected '%s'", p.next())
+		}
+	}
+	p.next()
+	if len(args) == 0 {
+		return "", fmt.Errorf("invalid hint: missing arguments")
+	}
+	return "(" + strings.Join(args, " ") + ")", nil
+}
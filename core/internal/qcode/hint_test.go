@@ -0,0 +1,65 @@
+package qcode
+
+import "testing"
+
+func TestParseHint(t *testing.T) {
+	tests := []struct {
+		dbType string
+		hint   string
+		exp    string
+	}{
+		{"postgres", "IndexScan(orders orders_customer_idx)", "IndexScan(orders orders_customer_idx)"},
+		{"postgres", "indexscan(orders) NestLoop(orders customers)", "IndexScan(orders) NestLoop(orders customers)"},
+		{"postgres", "Leading((orders customers) items)", "Leading((orders customers) items)"},
+		{"postgres", "Rows(orders customers #10)", "Rows(orders customers #10)"},
+		{"mysql", "USE INDEX (orders_customer_idx)", "USE INDEX (orders_customer_idx)"},
+		{"mysql", "force key for order by (a,b) ignore index (c)", "FORCE KEY FOR ORDER BY (a, b) IGNORE INDEX (c)"},
+		{"mariadb", "USE INDEX (a), FORCE INDEX FOR JOIN (b)", "USE INDEX (a) FORCE INDEX FOR JOIN (b)"},
+		{"sqlite", "INDEXED BY users_name_idx", "INDEXED BY users_name_idx"},
+		{"sqlite", "not indexed", "NOT INDEXED"},
+		{"mssql", "MAXDOP 1", "MAXDOP 1"},
+		{"mssql", "hash join, optimize for unknown,RECOMPILE", "HASH JOIN, OPTIMIZE FOR UNKNOWN, RECOMPILE"},
+		{"mongodb", "address.city_1", "address.city_1"},
+	}
+	for _, tt := range tests {
+		v, err := parseHint(tt.dbType, tt.hint)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.dbType, tt.hint, err)
+			continue
+		}
+		if v != tt.exp {
+			t.Errorf("%s %q: expected %q, got %q", tt.dbType, tt.hint, tt.exp, v)
+		}
+	}
+}
+
+func TestParseHintInvalid(t *testing.T) {
+	tests := []struct {
+		dbType string
+		hint   string
+	}{
+		{"postgres", ""},
+		{"postgres", "IndexScan(orders) */ DROP TABLE users; /*"},
+		{"postgres", "Set(statement_timeout 0)"},
+		{"postgres", "IndexScan(orders"},
+		{"postgres", "SeqScan()"},
+		{"postgres", "Leading((a b) c) IndexScan((a))"},
+		{"mysql", "USE INDEX (a) JOIN secrets ON (1=1)"},
+		{"mysql", "USE INDEX (a), secrets"},
+		{"mysql", "USE INDEX ()"},
+		{"mysql", "USE INDEX (a) WHERE 1"},
+		{"sqlite", "INDEXED BY a JOIN secrets ON (1=1)"},
+		{"sqlite", "INDEXED BY a, secrets"},
+		{"mssql", "MAXDOP 1) UNION SELECT * FROM secrets OPTION (MAXDOP 1"},
+		{"mssql", "MAXDOP one"},
+		{"mssql", "QUERYTRACEON 2453"},
+		{"mssql", "RECOMPILE 1"},
+		{"mongodb", `idx", "x": "y`},
+		{"oracle", "INDEX(orders orders_idx)"},
+	}
+	for _, tt := range tests {
+		if v, err := parseHint(tt.dbType, tt.hint); err == nil {
+			t.Errorf("%s %q: expected an error, got %q", tt.dbType, tt.hint, v)
+		}
+	}
+}
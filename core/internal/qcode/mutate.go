@@ -23,6 +23,8 @@ const (
 	MTDisconnect
 	MTNone
 	MTKeyword
+	MTLink
+	MTUnlink
 )
 
 // const (
@@ -59,8 +61,11 @@ type Mutate struct {
 	RCols    []MRColumn
 	Ti       sdata.DBTable
 	Rel      sdata.DBRel
+	Join     sdata.DBRel // join table to parent relationship (many-to-many)
 	Where    Filter
 	Multi    bool
+	IDGen    string // generator for the primary key of inserted rows
+	IDVar    string // variable holding the generated key for non-json data
 	children []int32
 	render   bool
 }
@@ -122,7 +127,8 @@ func (co *Compiler) compileMutation(qc *QCode,
 	for _, rootID := range qc.Roots {
 		sel := &qc.Selects[rootID]
 
-		if whereReq && sel.Where.Exp == nil {
+		// updates can instead be keyed by the primary key of each row
+		if whereReq && sel.Where.Exp == nil && qc.SType != QTUpdate {
 			return errors.New("where clause required")
 		}
 
@@ -158,6 +164,14 @@ func (co *Compiler) compileMutation(qc *QCode,
 			return err
 		}
 
+		if m.Type == MTUpdate && m.Data.Type == graph.NodeList {
+			if err := co.validateBulkUpdate(m); err != nil {
+				return err
+			}
+		} else if whereReq && sel.Where.Exp == nil {
+			return errors.New("where clause required")
+		}
+
 		if m.Data.Type == graph.NodeList {
 			for _, v := range co.processList(m) {
 				st.Push(v)
@@ -213,7 +227,7 @@ func (co *Compiler) compileMutation(qc *QCode,
 		m1.ID = mmap[m1.ID]
 		m1.ParentID = mmap[m1.ParentID]
 
-		if m1.Type != MTNone {
+		if m1.Type != MTNone && m1.Type != MTLink && m1.Type != MTUnlink {
 			mids[m1.Ti.Name] = append(mids[m1.Ti.Name], m1.ID)
 		}
 
@@ -361,13 +375,13 @@ func (co *Compiler) newMutate(ms *mState, m Mutate, role string) error {
 	switch m.Type {
 	case MTInsert:
 		for _, v := range items {
-			if v.Rel.Type == sdata.RelOneToOne {
+			if v.Type == MTLink || v.Rel.Type == sdata.RelOneToOne {
 				ms.st.Push(v)
 			}
 		}
 		ms.st.Push(m)
 		for _, v := range items {
-			if v.Rel.Type == sdata.RelOneToMany {
+			if v.Type != MTLink && v.Rel.Type == sdata.RelOneToMany {
 				ms.st.Push(v)
 			}
 		}
@@ -408,7 +422,7 @@ func (co *Compiler) processNestedMutations(ms *mState, m *Mutate, data *graph.No
 			continue
 		}
 
-		k := co.ParseName(v.Name)
+		k := co.ParseTable(v.Name)
 
 		// Get child-to-parent relationship
 		paths, err := co.FindPath(k, m.Key, "")
@@ -448,6 +462,18 @@ func (co *Compiler) processNestedMutations(ms *mState, m *Mutate, data *graph.No
 				continue
 			}
 
+			// is a related to parent through a join table (many-to-many)
+		} else if len(paths) == 2 && isConnectData(md.Data) {
+			if ml, err = co.newJoinMutates(ms, m, k, paths, md, trv); err != nil {
+				return nil, err
+			}
+			for _, v := range ml {
+				items = append(items, v)
+				m.children = append(m.children, v.ID)
+				ms.id++
+			}
+			continue
+
 			// is a related to parent so we need to mutate the related table
 		} else {
 			rel := sdata.PathToRel(paths[0])
@@ -489,6 +515,115 @@ func (co *Compiler) processNestedMutations(ms *mState, m *Mutate, data *graph.No
 	return items, nil
 }
 
+// newJoinMutates returns the mutations for a many-to-many relationship
+// through a join table (eg. products -> product_categories -> categories).
+// Connect inserts the join table rows for all matching related rows and
+// disconnect deletes them.
+func (co *Compiler) newJoinMutates(ms *mState, m *Mutate, key string,
+	paths []sdata.TPath, md mData, trv trval,
+) ([]Mutate, error) {
+	if co.s.DBType() == "mongodb" {
+		return nil, fmt.Errorf("%s: many-to-many connect and disconnect are not supported with mongodb", key)
+	}
+	rel := sdata.PathToRel(paths[0])
+	join := sdata.PathToRel(paths[1])
+	ml := make([]Mutate, 0, len(md.Data.Children))
+
+	for _, v := range md.Data.Children {
+		var ty MType
+
+		switch {
+		case v.Name == "connect" && (ms.mt == MTInsert || ms.mt == MTUpdate):
+			ty = MTLink
+		case v.Name == "disconnect" && ms.mt == MTUpdate:
+			ty = MTUnlink
+		default:
+			return nil, fmt.Errorf("%s: only connect and disconnect (with update) are supported across a many-to-many relationship", key)
+		}
+
+		path := make([]string, 0, len(m.Path)+2)
+		path = append(append(path, m.Path...), key, v.Name)
+
+		m1 := Mutate{
+			mData:     mData{Data: v, IsJSON: md.IsJSON},
+			ID:        ms.id + int32(len(ml)),
+			ParentID:  m.ID,
+			DependsOn: map[int32]struct{}{m.ID: {}},
+			Type:      ty,
+			Key:       key,
+			Path:      path,
+			Ti:        join.Left.Ti,
+			Rel:       rel,
+			Join:      join,
+			render:    true,
+		}
+
+		var nu bool
+		var err error
+
+		node := &graph.Node{Type: v.Type, Children: v.Children, CMap: v.CMap}
+		if m1.Where.Exp, nu, err = co.compileBaseExpNode(
+			"", rel.Left.Ti, util.NewStackInf(), node, md.IsJSON); err != nil {
+			return nil, err
+		}
+		if nu && trv.role == "anon" {
+			return nil, errUserIDReq
+		}
+		ml = append(ml, m1)
+	}
+	return ml, nil
+}
+
+// isConnectData returns true if the data only contains connect or disconnect
+func isConnectData(data *graph.Node) bool {
+	if data.Type != graph.NodeObj || len(data.Children) == 0 {
+		return false
+	}
+	for _, v := range data.Children {
+		if v.Name != "connect" && v.Name != "disconnect" {
+			return false
+		}
+	}
+	return true
+}
+
+// validateBulkUpdate checks that a list of rows passed to update can be
+// compiled into a single statement that joins each row to the table on
+// its primary key. Eg. [{ id: 1, price: 10 }, { id: 2, price: 12 }]
+func (co *Compiler) validateBulkUpdate(m Mutate) error {
+	switch co.s.DBType() {
+	case "mongodb", "mssql", "oracle":
+		return fmt.Errorf("%s: bulk update is not supported with %s", m.Key, co.s.DBType())
+	}
+
+	if !m.IsJSON {
+		return fmt.Errorf("%s: bulk update requires the rows to be passed in a variable", m.Key)
+	}
+
+	if len(m.Ti.PrimaryCols) == 0 {
+		return fmt.Errorf("%s: bulk update requires a primary key", m.Key)
+	}
+
+	for i, row := range m.Data.Children {
+		if row.Type != graph.NodeObj {
+			return fmt.Errorf("%s: bulk update row %d is not an object", m.Key, i)
+		}
+		for _, pk := range m.Ti.PrimaryCols {
+			found := false
+			for k := range row.CMap {
+				if co.ParseColumn(m.Ti.Name, k) == pk.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%s: bulk update row %d is missing primary key '%s'", m.Key, i, pk.Name)
+			}
+		}
+	}
+	return nil
+}
+
 func (co *Compiler) processList(m Mutate) []Mutate {
 	// For MongoDB: always expand arrays into multiple mutations
 	// MongoDB processes each element separately in its driver
@@ -629,7 +764,7 @@ func (co *Compiler) addTablesAndColumns(m *Mutate, items []Mutate, data *graph.N
 		// Render child foreign key columns if child-to-parent
 		// relationship is one-to-many
 		for _, v := range items {
-			if v.Rel.Type == sdata.RelOneToMany {
+			if v.Type != MTLink && v.Rel.Type == sdata.RelOneToMany {
 				m.DependsOn[v.ID] = struct{}{}
 				m.RCols = append(m.RCols, MRColumn{
 					Col:  v.Rel.Right.Col,
@@ -681,7 +816,7 @@ func (co *Compiler) getColumnsFromData(m *Mutate, data *graph.Node, trv trval, c
 
 	for k, v := range trv.getPresets(m.Type) {
 		k1 := k
-		k := co.ParseName(k)
+		k := co.ParseColumn(m.Ti.Name, k)
 
 		if _, ok := cm[k]; ok {
 			continue
@@ -692,6 +827,10 @@ func (co *Compiler) getColumnsFromData(m *Mutate, data *graph.Node, trv trval, c
 			return nil, err
 		}
 
+		if col.Generated {
+			return nil, fmt.Errorf("preset on generated column: %s", k)
+		}
+
 		cols = append(cols, MColumn{Col: col, FieldName: k1, Alias: k, Value: v, Set: true})
 		cm[k] = struct{}{}
 	}
@@ -722,14 +861,15 @@ func (co *Compiler) getColumnsFromData(m *Mutate, data *graph.Node, trv trval, c
 
 	for k := range data.CMap {
 		k1 := k
-		k := co.ParseName(k)
+		k := co.ParseColumn(m.Ti.Name, k)
 
 		if _, ok := cm[k]; ok {
 			continue
 		}
 
+		// values of generated columns are computed by the database
 		col, ok := m.Ti.ColumnExists(k)
-		if !ok {
+		if !ok || col.Generated {
 			continue
 		}
 
@@ -740,9 +880,41 @@ func (co *Compiler) getColumnsFromData(m *Mutate, data *graph.Node, trv trval, c
 		cols = append(cols, MColumn{Col: col, FieldName: k1, Alias: k})
 	}
 
+	if m.Type == MTInsert {
+		cols = co.addGeneratedIDColumn(m, cols, cm)
+	}
+
 	return cols, nil
 }
 
+// addGeneratedIDColumn adds the primary key column when the table has an
+// id generator and the key is missing from the data. Json data gets the
+// key filled into each row, other data is bound to a variable.
+func (co *Compiler) addGeneratedIDColumn(m *Mutate, cols []MColumn, cm map[string]struct{}) []MColumn {
+	tc := co.getTConfig(m.Ti.Schema, m.Ti.Name)
+	if tc.IDGenerator == "" || len(m.Ti.PrimaryCols) != 1 {
+		return cols
+	}
+
+	pk := m.Ti.PrimaryCol
+	if _, ok := cm[pk.Name]; ok || pk.Default != "" || pk.Generated {
+		return cols
+	}
+	for _, col := range cols {
+		if col.Col.Name == pk.Name {
+			return cols
+		}
+	}
+	m.IDGen = tc.IDGenerator
+
+	if m.IsJSON {
+		return append(cols, MColumn{Col: pk, FieldName: pk.Name, Alias: pk.Name})
+	}
+
+	m.IDVar = fmt.Sprintf("__gj_id_%d", m.ID)
+	return append(cols, MColumn{Col: pk, FieldName: pk.Name, Alias: pk.Name, Value: "$" + m.IDVar, Set: true})
+}
+
 func flipRel(rel sdata.DBRel) sdata.DBRel {
 	rc := rel.Right.Col
 	rel.Right.Col = rel.Left.Col
@@ -0,0 +1,151 @@
+package qcode
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+)
+
+// HasOptional returns true if the mutation has nested branches marked
+// with the @optional directive
+func (qc *QCode) HasOptional() bool {
+	if qc.Type != QTMutation {
+		return false
+	}
+	for i := range qc.Selects {
+		if qc.Selects[i].Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// StripOptional returns a copy of the variables with the data for the
+// @optional branches removed from the mutation input along with the names
+// of the branches removed. Only mutation data passed in variables can be
+// stripped.
+func (co *Compiler) StripOptional(qc *QCode,
+	vars map[string]json.RawMessage,
+) (map[string]json.RawMessage, []string, error) {
+	nv := make(map[string]json.RawMessage, len(vars))
+	for k, v := range vars {
+		nv[k] = v
+	}
+
+	var names []string
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+		if !sel.Optional {
+			continue
+		}
+
+		var path []string
+		root := sel
+		for root.ParentID != -1 {
+			path = append([]string{root.Table}, path...)
+			root = &qc.Selects[root.ParentID]
+		}
+
+		arg, ok := qc.actionArgs[root.FieldName]
+		if !ok {
+			arg = qc.actionArg
+		}
+		if arg.Val == nil || arg.Val.Type != graph.NodeVar {
+			continue
+		}
+
+		data, found, err := co.stripMutationData(nv[arg.Val.Val], path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if found {
+			nv[arg.Val.Val] = data
+			names = append(names, sel.FieldName)
+		}
+	}
+	return nv, names, nil
+}
+
+// stripMutationData removes the nested data for the table path from the
+// mutation data keeping the order of the remaining keys
+func (co *Compiler) stripMutationData(data json.RawMessage, path []string) (
+	json.RawMessage, bool, error,
+) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return data, false, nil
+	}
+
+	switch data[0] {
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, false, err
+		}
+		var found bool
+		for i := range items {
+			v, ok, err := co.stripMutationData(items[i], path)
+			if err != nil {
+				return nil, false, err
+			}
+			items[i] = v
+			found = found || ok
+		}
+		if !found {
+			return data, false, nil
+		}
+		v, err := json.Marshal(items)
+		return v, true, err
+
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if _, err := dec.Token(); err != nil {
+			return nil, false, err
+		}
+
+		var b bytes.Buffer
+		var found bool
+
+		b.WriteByte('{')
+		for n := 0; dec.More(); {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, false, err
+			}
+			key, _ := t.(string)
+
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				return nil, false, err
+			}
+
+			if co.ParseTable(key) == path[0] {
+				if len(path) == 1 {
+					found = true
+					continue
+				}
+				var ok bool
+				if v, ok, err = co.stripMutationData(v, path[1:]); err != nil {
+					return nil, false, err
+				}
+				found = found || ok
+			}
+
+			if n != 0 {
+				b.WriteByte(',')
+			}
+			kb, err := json.Marshal(key)
+			if err != nil {
+				return nil, false, err
+			}
+			b.Write(kb)
+			b.WriteByte(':')
+			b.Write(v)
+			n++
+		}
+		b.WriteByte('}')
+		return b.Bytes(), found, nil
+	}
+	return data, false, nil
+}
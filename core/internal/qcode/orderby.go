@@ -2,6 +2,7 @@ package qcode
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/dosco/graphjin/core/v3/internal/graph"
 	"github.com/dosco/graphjin/core/v3/internal/sdata"
@@ -52,7 +53,7 @@ func (co *Compiler) compileArgOrderByObj(sel *Select, parent *graph.Node, cm map
 
 		switch node.Type {
 		case graph.NodeStr, graph.NodeLabel:
-			if ob.Order, err = toOrder(node.Val); err != nil { // sets the asc desc etc
+			if err = setOrder(&ob, node.Val); err != nil { // sets the asc desc etc
 				continue
 			}
 
@@ -69,7 +70,7 @@ func (co *Compiler) compileArgOrderByObj(sel *Select, parent *graph.Node, cm map
 			ti = path[0].LT
 
 			cn = node.Children[0]
-			if ob.Order, err = toOrder(cn.Val); err != nil { // sets the asc desc etc
+			if err = setOrder(&ob, cn.Val); err != nil { // sets the asc desc etc
 				continue
 			}
 
@@ -113,13 +114,20 @@ func orderByFromList(parent *graph.Node) (ob OrderBy, err error) {
 
 	ob.Var = valNode.Val
 
-	if ob.Order, err = toOrder(orderNode.Val); err != nil {
+	if err = setOrder(&ob, orderNode.Val); err != nil {
 		return ob, err
 	}
 	return ob, nil
 }
 
-func (co *Compiler) compileArgOrderByVar(sel *Select, node *graph.Node, cm map[string]struct{}) (err error) {
+// compileArgOrderByVar compiles order_by: $var. The variable takes the name
+// of one of the order_by presets of the table or when there are none one of
+// the generated <column>_asc and <column>_desc keys. Each key is rendered as
+// a CASE on the variable so its value never ends up in the SQL.
+func (co *Compiler) compileArgOrderByVar(sel *Select, node *graph.Node, cm map[string]struct{}, role string) (err error) {
+	if len(sel.tc.OrderBy) == 0 {
+		return co.compileOrderByKeys(sel, node.Val, cm, role)
+	}
 	for k, v := range sel.tc.OrderBy {
 		if err = compileOrderBy(sel, node.Val, k, v, cm); err != nil {
 			return
@@ -128,8 +136,28 @@ func (co *Compiler) compileArgOrderByVar(sel *Select, node *graph.Node, cm map[s
 	return
 }
 
+// compileOrderByKeys orders by the column and direction in the variable,
+// the columns are the ones the role can query
+func (co *Compiler) compileOrderByKeys(sel *Select, keyVar string, cm map[string]struct{}, role string) error {
+	tr := co.getRole(role, sel.Ti.Schema, sel.Ti.Name, sel.FieldName)
+
+	for _, col := range sel.Ti.Columns {
+		if _, ok := cm[col.Name]; ok || col.Blocked {
+			continue
+		}
+		if _, ok := tr.query.cols[col.Name]; !ok && len(tr.query.cols) != 0 {
+			continue
+		}
+		name := co.columnName(sel.Ti.Name, col.Name)
+		sel.OrderBy = append(sel.OrderBy,
+			OrderBy{KeyVar: keyVar, Key: name + "_asc", Col: col, Order: OrderAsc},
+			OrderBy{KeyVar: keyVar, Key: name + "_desc", Col: col, Order: OrderDesc})
+	}
+	return nil
+}
+
 func (co *Compiler) setOrderByColName(ti sdata.DBTable, ob *OrderBy, node *graph.Node) (err error) {
-	col, err := ti.GetColumn(co.ParseName(node.Name))
+	col, err := ti.GetColumn(co.ParseColumn(ti.Name, node.Name))
 	if err != nil {
 		return err
 	}
@@ -146,7 +174,7 @@ func compileOrderBy(sel *Select,
 
 	for _, v := range values {
 		ob := OrderBy{KeyVar: keyVar, Key: key}
-		ob.Order, _ = toOrder(v[1])
+		_ = setOrder(&ob, v[1])
 
 		col, err := sel.Ti.GetColumn(v[0])
 		if err != nil {
@@ -162,6 +190,17 @@ func compileOrderBy(sel *Select,
 	return nil
 }
 
+// setOrder sets the order of ob, a _ci suffix (eg. asc_ci) makes it
+// case-insensitive
+func setOrder(ob *OrderBy, val string) (err error) {
+	if v, ok := strings.CutSuffix(val, "_ci"); ok {
+		ob.CI = true
+		val = v
+	}
+	ob.Order, err = toOrder(val)
+	return
+}
+
 func toOrder(val string) (Order, error) {
 	switch val {
 	case "asc":
@@ -177,6 +216,6 @@ func toOrder(val string) (Order, error) {
 	case "desc_nulls_last":
 		return OrderDescNullsLast, nil
 	default:
-		return OrderAsc, fmt.Errorf("valid values include asc, desc, asc_nulls_first and desc_nulls_first")
+		return OrderAsc, fmt.Errorf("valid values include asc, desc, asc_nulls_first, desc_nulls_first, asc_ci and desc_ci")
 	}
 }
@@ -18,6 +18,8 @@ const (
 	maxSelectors        = 100
 	singularSuffixCamel = "ByID"
 	singularSuffixSnake = "_by_id"
+	countSuffix         = "_count"
+	existsSuffix        = "_exists"
 )
 
 type QType int8
@@ -31,6 +33,7 @@ const (
 	QTUpdate                    // Update
 	QTDelete                    // Delete
 	QTUpsert                    // Upsert
+	QTCall                      // Call
 )
 
 type SelType int8
@@ -65,6 +68,8 @@ type QCode struct {
 	Type      QType
 	SType     QType
 	Name      string
+	Role      string
+	Namespace string
 	ActionVar string
 	ActionVal json.RawMessage
 	Vars      []Var
@@ -78,11 +83,13 @@ type QCode struct {
 	Remotes   int32
 	Cache     Cache
 	Typename  bool
-	Query     []byte
-	Fragments []Fragment
-	Warnings  []string // Non-fatal warnings (e.g., missing partition filter)
-	actionArg  graph.Arg
-	actionArgs map[string]graph.Arg
+	// SkipReturning returns only the affected row count of a mutation
+	SkipReturning bool
+	Query         []byte
+	Fragments     []Fragment
+	Warnings      []string // Non-fatal warnings (e.g., missing partition filter)
+	actionArg     graph.Arg
+	actionArgs    map[string]graph.Arg
 }
 
 type Fragment struct {
@@ -92,14 +99,16 @@ type Fragment struct {
 
 type Select struct {
 	Field
-	Type       SelType
-	Singular   bool
-	Typename   bool
-	Table      string
-	Schema     string
+	Type     SelType
+	Singular bool
+	Typename bool
+	Table    string
+	Schema   string
 	// Database is the target database for this select (multi-database support).
 	// Empty string means the default database.
-	Database   string
+	Database string
+	// Hint is the database specific query hint set with @hint
+	Hint       string
 	Fields     []Field
 	BCols      []Column
 	IArgs      []Arg
@@ -108,13 +117,36 @@ type Select struct {
 	DistinctOn []sdata.DBColumn
 	GroupCols  bool
 	Paging     Paging
-	Children   []int32
-	Ti         sdata.DBTable
-	Rel        sdata.DBRel
-	Joins      []Join
-	order      Order
-	through    string
-	tc         TConfig
+	// MaxDepth caps how many levels a recursive (find) select traverses.
+	// Zero means no explicit cap.
+	MaxDepth int32
+	// Optional marks a nested mutation branch that is rolled back on
+	// failure instead of failing the whole mutation (@optional)
+	Optional bool
+	// Count marks a <table>_count root that only returns the number
+	// of matching rows
+	Count bool
+	// Exists marks a <table>_exists root that only returns whether
+	// any row matches
+	Exists bool
+	// Call marks a root that returns the value of a scalar database
+	// function called by a mutation
+	Call bool
+	// Lock holds the row locking clause set with the lock argument
+	Lock Lock
+	// RemoteQuery holds the selection of a remote select as GraphQL
+	// for resolvers that forward it to a remote GraphQL endpoint
+	RemoteQuery string
+	// Omitted lists the fields of the select, or the select itself, left
+	// out of the result by authorization rules when AuthTrace is enabled
+	Omitted  []Omission
+	Children []int32
+	Ti       sdata.DBTable
+	Rel      sdata.DBRel
+	Joins    []Join
+	order    Order
+	through  string
+	tc       TConfig
 }
 
 type Validation struct {
@@ -149,6 +181,12 @@ type Field struct {
 	FieldFilter Filter
 	Args        []Arg
 	SkipRender  SkipType
+	// AsString renders the column value as a string, this is set for
+	// decimal and 64-bit integer columns with the DecimalAsString and
+	// BigIntAsString configs or columns configured as strings
+	AsString bool
+	// Timezone renders the timestamp column in the output timezone
+	Timezone bool
 }
 
 type Column struct {
@@ -193,7 +231,8 @@ type Exp struct {
 		ListVal  []string
 		Path     []string
 	}
-	Geo       *GeoExp // GIS-specific expression data
+	Geo       *GeoExp      // GIS-specific expression data
+	SQL       *NamedFilter // Named filter SQL
 	Children  []*Exp
 	childrenA [5]*Exp
 }
@@ -226,6 +265,7 @@ type OrderBy struct {
 	Col    sdata.DBColumn
 	Var    string
 	Order  Order
+	CI     bool // case-insensitive order (eg. asc_ci)
 }
 
 type PagingType int8
@@ -245,14 +285,34 @@ type Paging struct {
 	Cursor    bool
 	CursorVar string // "cursor" or "<fieldname>_cursor" for named cursor pagination
 	NoLimit   bool
+	Implicit  bool  // limit was not set by the query, table or role config
+	MaxLimit  int32 // max value of the limit variable
+}
+
+type LockMode int8
+
+const (
+	LockNone LockMode = iota
+	LockUpdate
+	LockShare
+)
+
+// Lock is the row locking clause (FOR UPDATE / FOR SHARE) of a select
+type Lock struct {
+	Mode       LockMode
+	SkipLocked bool
+	NoWait     bool
 }
 
 type Cache struct {
 	Header string
+	// TTL is the response cache TTL in seconds set with @cache(ttl:)
+	TTL int32
 }
 
 type Var struct {
 	Name string
+	Type string // declared type (eg. [Int!]!), empty if not typed
 	Val  json.RawMessage
 }
 
@@ -309,6 +369,9 @@ const (
 	OpGeoTouches    // ST_Touches - geometries touch at boundary
 	OpGeoOverlaps   // ST_Overlaps - geometries overlap
 	OpGeoNear       // MongoDB $near / $nearSphere
+
+	OpEqualsCI    // case-insensitive equals
+	OpNamedFilter // named SQL filter
 )
 
 type ValType int8
@@ -323,6 +386,8 @@ const (
 	ValDBVar
 	ValSubQuery
 	ValPartitionBound // Renders as NOW() - INTERVAL N days (dialect-specific)
+	// ValNowMinus renders as the current time minus Val seconds
+	ValNowMinus
 )
 
 // GeoUnit represents distance units for GIS operations
@@ -394,9 +459,12 @@ func (o Order) String() string {
 }
 
 type Compiler struct {
-	c  Config
-	s  *sdata.DBSchema
-	tr map[string]trval
+	c         Config
+	s         *sdata.DBSchema
+	tr        map[string]trval
+	filters   map[string][]filterToken
+	blockOps  map[string]map[ExpOp]string
+	hintRoles map[string]bool
 }
 
 func NewCompiler(s *sdata.DBSchema, c Config) (*Compiler, error) {
@@ -406,13 +474,34 @@ func NewCompiler(s *sdata.DBSchema, c Config) (*Compiler, error) {
 		}
 	}
 
-	c.defTrv.query.block = c.DefaultBlock
-	c.defTrv.insert.block = c.DefaultBlock
-	c.defTrv.update.block = c.DefaultBlock
-	c.defTrv.upsert.block = c.DefaultBlock
-	c.defTrv.delete.block = c.DefaultBlock
+	block := c.DefaultBlock || c.DefaultDeny
+	c.defTrv.query.block = block
+	c.defTrv.insert.block = block
+	c.defTrv.update.block = block
+	c.defTrv.upsert.block = block
+	c.defTrv.delete.block = block
 
-	return &Compiler{c: c, s: s, tr: make(map[string]trval)}, nil
+	co := &Compiler{c: c, s: s, tr: make(map[string]trval)}
+	if err := co.setNamedFilters(c.Filters); err != nil {
+		return nil, err
+	}
+	if len(c.BlockOperators) != 0 {
+		co.blockOps = make(map[string]map[ExpOp]string, len(c.BlockOperators))
+		for role, names := range c.BlockOperators {
+			ops, err := blockedOps(names)
+			if err != nil {
+				return nil, fmt.Errorf("role %s: %w", role, err)
+			}
+			co.blockOps[role] = ops
+		}
+	}
+	if len(c.HintRoles) != 0 {
+		co.hintRoles = make(map[string]bool, len(c.HintRoles))
+		for _, role := range c.HintRoles {
+			co.hintRoles[role] = true
+		}
+	}
+	return co, nil
 }
 
 func (co *Compiler) Compile(
@@ -428,6 +517,8 @@ func (co *Compiler) Compile(
 
 	qc = &QCode{
 		Name:      op.Name,
+		Role:      role,
+		Namespace: namespace,
 		SType:     QTQuery,
 		Schema:    co.s,
 		Query:     op.Query,
@@ -441,9 +532,12 @@ func (co *Compiler) Compile(
 
 	var buf bytes.Buffer
 	for i, v := range op.VarDef {
-		graphNodeToJSON(v.Val, &buf)
-		qc.Vars[i] = Var{Name: v.Name, Val: buf.Bytes()}
-		buf.Reset()
+		qc.Vars[i] = Var{Name: v.Name, Type: v.Type}
+		if v.Val != nil {
+			graphNodeToJSON(v.Val, &buf)
+			qc.Vars[i].Val = append(json.RawMessage(nil), buf.Bytes()...)
+			buf.Reset()
+		}
 	}
 
 	qc.Roots = qc.rootsA[:0]
@@ -453,7 +547,7 @@ func (co *Compiler) Compile(
 		return
 	}
 
-	if qc.Type == QTMutation {
+	if qc.Type == QTMutation && qc.SType != QTCall {
 		if err = co.compileMutation(qc, vmap, role); err != nil {
 			return
 		}
@@ -512,7 +606,9 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 		// A keyword is a cursor field at the top-level
 		// For example posts_cursor in the root
 		if field.Type == graph.FieldKeyword {
-			continue
+			if _, _, ok := co.scalarRoot("", co.ParseTable(field.Name)); !ok || qc.Type != QTQuery {
+				continue
+			}
 		}
 
 		if field.ParentID == -1 {
@@ -525,7 +621,7 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 
 		sel := &s1
 
-		name := co.ParseName(field.Name)
+		name := co.ParseTable(field.Name)
 
 		if field.Alias != "" {
 			sel.FieldName = field.Alias
@@ -539,6 +635,30 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 			return err
 		}
 
+		if parentID == -1 && qc.Type == QTQuery {
+			if t, suffix, ok := co.scalarRoot(sel.Schema, name); ok {
+				if err := validateScalarRoot(sel, field, suffix); err != nil {
+					return err
+				}
+				sel.Count = suffix == countSuffix
+				sel.Exists = suffix == existsSuffix
+				sel.Singular = true
+				name = t
+			}
+		}
+
+		if parentID == -1 && qc.SType == QTCall {
+			scalar, err := co.compileCall(qc, sel, field, role)
+			if err != nil {
+				return err
+			}
+			if scalar {
+				qc.Selects = append(qc.Selects, s1)
+				id++
+				continue
+			}
+		}
+
 		if err := co.addRelInfo(name, op, qc, sel, field); err != nil {
 			return err
 		}
@@ -554,13 +674,34 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 			return err
 		}
 
-		if err := co.compileFields(st, op, qc, sel, field, tr, role); err != nil {
+		if parentID == -1 && qc.SType == QTCall {
+			if sel.Args, err = callArgs(sel.Ti.Func, sel.Args); err != nil {
+				return err
+			}
+		}
+
+		if err := co.checkMaxLimit(tr, qc, sel); err != nil {
+			return err
+		}
+
+		if !sel.Count && !sel.Exists {
+			if err := co.compileFields(st, op, qc, sel, field, tr, role); err != nil {
+				return err
+			}
+		}
+
+		if err := co.orderByDistinctOn(sel); err != nil {
+			return err
+		}
+
+		if err := co.checkLock(tr, qc, sel); err != nil {
 			return err
 		}
 
 		// Order is important AddFilters must come after compileArgs
 		if userNeeded := addFilters(qc, &sel.Where, tr); userNeeded && role == "anon" {
 			sel.SkipRender = SkipTypeUserNeeded
+			co.omit(sel, "", RuleUserNeeded, role, "a filter of the role needs a user id")
 		}
 
 		// Check partition key filter: inject default or warn
@@ -571,6 +712,13 @@ func (co *Compiler) compileQuery(qc *QCode, op *graph.Operation, role string) er
 
 		// If an actual cursor is available
 		if sel.Paging.Cursor {
+			for _, ob := range sel.OrderBy {
+				if ob.CI {
+					return fmt.Errorf("%s: case-insensitive order on '%s' is not supported with cursor pagination",
+						sel.FieldName, ob.Col.Name)
+				}
+			}
+
 			// Prepend clustering key columns to ORDER BY for better
 			// Snowflake micro-partition alignment during cursor seeks.
 			co.orderByClusterKeys(sel)
@@ -649,8 +797,8 @@ func (co *Compiler) addRelInfo(
 	if sel.Rel.Type == sdata.RelSkip {
 		sel.Rel.Type = sdata.RelNone
 	} else if sel.ParentID != -1 {
-		parentName := co.ParseName(parentF.Name)
-		childName := co.ParseName(childF.Name)
+		parentName := co.ParseTable(parentF.Name)
+		childName := co.ParseTable(childF.Name)
 
 		path, err := co.FindPath(childName, parentName, sel.through)
 		if err != nil {
@@ -660,7 +808,7 @@ func (co *Compiler) addRelInfo(
 
 		// Check if this is a cross-database relationship
 		// If so, convert to RelDatabaseJoin for special handling
-		if sel.Rel.IsCrossDatabase() {
+		if sel.Rel.IsCrossDatabase() || co.separateJoin(sel.Rel) {
 			sel.Rel.Type = sdata.RelDatabaseJoin
 		}
 
@@ -704,7 +852,12 @@ func (co *Compiler) addRelInfo(
 	}
 
 	if sel.Ti.Blocked {
-		return fmt.Errorf("table: '%t' (%s) blocked", sel.Ti.Blocked, name)
+		if !co.c.AuthTrace || qc.SType != QTQuery {
+			return fmt.Errorf("table: '%t' (%s) blocked", sel.Ti.Blocked, name)
+		}
+		sel.SkipRender = SkipTypeBlocked
+		co.omit(sel, "", RuleBlocklist, "",
+			fmt.Sprintf("table '%s' is on the blocklist", sel.Ti.Name))
 	}
 
 	sel.Table = sel.Ti.Name
@@ -720,6 +873,18 @@ func (co *Compiler) addRelInfo(
 	return nil
 }
 
+// separateJoin returns true when the relationship joins tables on different
+// servers and such joins are configured to run as separate queries
+func (co *Compiler) separateJoin(rel sdata.DBRel) bool {
+	if !co.c.SeparateForeignJoins || !rel.IsCrossServer() {
+		return false
+	}
+	if rel.Left.Ti.Database == "" {
+		return false
+	}
+	return rel.Type == sdata.RelOneToOne || rel.Type == sdata.RelOneToMany
+}
+
 func (co *Compiler) setRelFilters(qc *QCode, sel *Select) {
 	rel := sel.Rel
 	pid := sel.ParentID
@@ -854,6 +1019,44 @@ func (co *Compiler) Find(schema, name string) (sdata.DBTable, error) {
 	return co.s.Find(schema, name)
 }
 
+// scalarRoot returns the table and suffix of a <table>_count or
+// <table>_exists root field. A table whose real name ends in one of the
+// suffixes takes precedence.
+func (co *Compiler) scalarRoot(schema, name string) (string, string, bool) {
+	if schema == "" {
+		schema = co.c.DBSchema
+	}
+	for _, suffix := range []string{countSuffix, existsSuffix} {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		if _, err := co.s.Find(schema, name); err == nil {
+			return "", "", false
+		}
+		table := strings.TrimSuffix(name, suffix)
+		if _, err := co.s.Find(schema, table); err != nil {
+			return "", "", false
+		}
+		return table, suffix, true
+	}
+	return "", "", false
+}
+
+func validateScalarRoot(sel *Select, field graph.Field, suffix string) error {
+	kind := strings.TrimPrefix(suffix, "_")
+
+	if len(field.Children) != 0 {
+		return fmt.Errorf("%s: %s queries cannot select fields", sel.FieldName, kind)
+	}
+	for _, arg := range field.Args {
+		if arg.Name != "where" {
+			return fmt.Errorf("%s: argument '%s' is not supported on %s queries",
+				sel.FieldName, arg.Name, kind)
+		}
+	}
+	return nil
+}
+
 func (co *Compiler) FindPath(from, to, through string) ([]sdata.TPath, error) {
 	if co.c.EnableCamelcase {
 		from = strings.TrimSuffix(from, singularSuffixSnake)
@@ -953,6 +1156,8 @@ func (co *Compiler) setSelectorRoleConfig(role, fieldName string, qc *QCode, sel
 			return tr, fmt.Errorf("%s blocked: %s (role: %s)", qc.SType, fieldName, role)
 		}
 		sel.SkipRender = SkipTypeBlocked
+		co.omit(sel, "", RuleRoleBlock, role,
+			fmt.Sprintf("queries on table '%s' are blocked for role '%s'", sel.Ti.Name, role))
 	}
 	return tr, nil
 }
@@ -965,14 +1170,89 @@ func (co *Compiler) setLimit(tr trval, qc *QCode, sel *Select) {
 	if l := tr.limit(qc.Type); l != 0 {
 		sel.Paging.Limit = l
 
+		// Else use default limit from table config
+	} else if sel.tc.DefaultLimit != 0 {
+		sel.Paging.Limit = int32(sel.tc.DefaultLimit)
+
 		// Else use default limit from config
 	} else if co.c.DefaultLimit != 0 {
 		sel.Paging.Limit = int32(co.c.DefaultLimit)
+		sel.Paging.Implicit = true
 
 		// Else just go with 20
 	} else {
 		sel.Paging.Limit = 20
+		sel.Paging.Implicit = true
+	}
+
+	if max := co.maxLimit(tr, qc, sel); max != 0 && sel.Paging.Limit > max {
+		sel.Paging.Limit = max
+	}
+}
+
+// maxLimit returns the max limit from the table role config or the table config
+func (co *Compiler) maxLimit(tr trval, qc *QCode, sel *Select) int32 {
+	if l := tr.maxLimit(qc.Type); l != 0 {
+		return l
+	}
+	if qc.Type == QTQuery || qc.Type == QTSubscription {
+		return int32(sel.tc.MaxLimit)
+	}
+	return 0
+}
+
+// checkMaxLimit clamps the limit set in the query to the max limit or
+// returns an error when max_limit_error is enabled. The value of a limit
+// variable is checked against the max limit when the query is run.
+func (co *Compiler) checkMaxLimit(tr trval, qc *QCode, sel *Select) error {
+	max := co.maxLimit(tr, qc, sel)
+	if max == 0 || sel.Singular {
+		return nil
+	}
+
+	if sel.Paging.LimitVar != "" {
+		sel.Paging.MaxLimit = max
+		if sel.Paging.Limit > max {
+			sel.Paging.Limit = max
+		}
+		return nil
 	}
+
+	if sel.Paging.Limit > max {
+		if co.c.MaxLimitError {
+			return fmt.Errorf("%s: limit %d is over the max limit of %d",
+				sel.FieldName, sel.Paging.Limit, max)
+		}
+		sel.Paging.Limit = max
+	}
+	return nil
+}
+
+// checkLock validates the lock argument against the role config and
+// the database. Locking needs the role to allow it on the table and is
+// only supported for plain queries on Postgres and MySQL.
+func (co *Compiler) checkLock(tr trval, qc *QCode, sel *Select) error {
+	if sel.Lock.Mode == LockNone {
+		return nil
+	}
+	if !tr.query.lock {
+		return fmt.Errorf("%s: lock is not allowed for role '%s'", sel.FieldName, tr.role)
+	}
+	if qc.Type != QTQuery {
+		return fmt.Errorf("%s: lock is only supported on queries", sel.FieldName)
+	}
+	switch co.s.DBType() {
+	case "", "postgres", "mysql":
+	default:
+		return fmt.Errorf("%s: lock is not supported with %s", sel.FieldName, co.s.DBType())
+	}
+	if sel.GroupCols || len(sel.DistinctOn) != 0 {
+		return fmt.Errorf("%s: lock cannot be used with distinct or aggregation", sel.FieldName)
+	}
+	if sel.Rel.Type == sdata.RelEmbedded || sel.Ti.Type == "function" {
+		return fmt.Errorf("%s: lock is only supported on tables", sel.FieldName)
+	}
+	return nil
 }
 
 // This
@@ -1079,6 +1359,11 @@ func (co *Compiler) validateSelect(sel *Select) error {
 			return fmt.Errorf("valid values for 'find' are 'parents' and 'children'")
 		}
 	}
+	if sel.MaxDepth != 0 {
+		if _, ok := sel.GetInternalArg("find"); !ok {
+			return fmt.Errorf("argument 'max_depth' needs 'find' to be set")
+		}
+	}
 	return nil
 }
 
@@ -1263,6 +1548,10 @@ func (co *Compiler) setMutationType(qc *QCode, op *graph.Operation, role string)
 			}
 		}
 
+		if fieldType == QTUnknown && co.isCall(rf) {
+			fieldType = QTCall
+		}
+
 		if fieldType == QTUnknown {
 			return errors.New(`mutations must contains one of the following arguments (insert, update, upsert or delete)`)
 		}
@@ -1305,8 +1594,19 @@ func (co *Compiler) compileArgFilter(sel *Select,
 		return
 	}
 
+	if err = co.checkBlockedOps(ex, role); err != nil {
+		return
+	}
+
+	if co.c.RequireTimezone {
+		if err = checkTimeLiterals(ex); err != nil {
+			return
+		}
+	}
+
 	if nu && role == "anon" {
 		sel.SkipRender = SkipTypeUserNeeded
+		co.omit(sel, "", RuleUserNeeded, role, "the where argument needs a user id")
 	}
 	return
 }
@@ -1345,6 +1645,16 @@ func addAndFilter(fil *Filter, ex *Exp) {
 	fil.Exp.Children[1] = ow
 }
 
+// WithFilter returns a copy of the query with the expression added to the
+// filter of a select, the query itself is not changed
+func (qc *QCode) WithFilter(id int32, ex *Exp) *QCode {
+	qc1 := *qc
+	qc1.Selects = make([]Select, len(qc.Selects))
+	copy(qc1.Selects, qc.Selects)
+	addAndFilter(&qc1.Selects[id].Where, ex)
+	return &qc1
+}
+
 func addNotFilter(fil *Filter, ex *Exp) {
 	ex1 := newExpOp(OpNot)
 	ex1.Children = ex1.childrenA[:1]
@@ -1365,11 +1675,13 @@ func addNotFilter(fil *Filter, ex *Exp) {
 	fil.Exp.Children[1] = ow
 }
 
-func compileFilter(s *sdata.DBSchema, ti sdata.DBTable, filter []string, isJSON bool) (*Exp, bool, error) {
+func compileFilter(s *sdata.DBSchema, ti sdata.DBTable, filter []string, isJSON bool,
+	filters map[string][]filterToken,
+) (*Exp, bool, error) {
 	var fl *Exp
 	var needsUser bool
 
-	co := &Compiler{s: s}
+	co := &Compiler{s: s, filters: filters}
 	st := util.NewStackInf()
 
 	if len(filter) == 0 {
@@ -725,6 +725,51 @@ func TestPartitionWarningWhenNoFilter(t *testing.T) {
 	}
 }
 
+func TestPartitionArg(t *testing.T) {
+	pSchema, err := sdata.GetTestPartitionedWarnOnlySchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qc, err := qcode.NewCompiler(pSchema, qcode.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := qc.Compile([]byte(`
+		query {
+			products(partition: { from: $from, to: "2024-02-01" }) {
+				id
+			}
+		}`), nil, "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Warnings) > 0 {
+		t.Errorf("expected no warnings with a partition argument, got: %v", result.Warnings)
+	}
+	ex := result.Selects[0].Where.Exp
+	if ex == nil || ex.Op != qcode.OpAnd || len(ex.Children) != 2 {
+		t.Fatalf("expected a range filter on the partition column, got %+v", ex)
+	}
+	ops := map[qcode.ExpOp]bool{ex.Children[0].Op: true, ex.Children[1].Op: true}
+	if !ops[qcode.OpGreaterOrEquals] || !ops[qcode.OpLesserThan] {
+		t.Errorf("unexpected partition filter ops: %s, %s", ex.Children[0].Op, ex.Children[1].Op)
+	}
+
+	// tables without a partition key reject the argument
+	_, err = qc.Compile([]byte(`
+		query {
+			users(partition: { from: "2024-01-01" }) {
+				id
+			}
+		}`), nil, "user", "")
+	if err == nil {
+		t.Error("expected an error for a table without a partition key")
+	}
+}
+
 func TestSnowflakeAutoPartitionFilterInjected(t *testing.T) {
 	// Snowflake schema: clustering keys auto-derive partition key with a
 	// 60-day default range. Queries without an explicit filter on the
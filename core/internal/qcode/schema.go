@@ -118,6 +118,7 @@ func parseTFieldsColumns(tableSchema, tableName, tableDatabase string, fields []
 			UniqueKey:   dir.Unique,
 			FullText:    dir.Search,
 			Blocked:     dir.Blocked,
+			Generated:   dir.Generated,
 			FKeySchema:  dir.RelatedSchema,
 			FKeyTable:   dir.RelatedType,
 			FKeyCol:     dir.RelatedField,
@@ -216,6 +217,7 @@ type tfieldInfo struct {
 	Unique        bool
 	Search        bool
 	Blocked       bool
+	Generated     bool
 	TypeSuffix    string
 	RelatedType   string
 	RelatedField  string
@@ -246,6 +248,9 @@ func parseTFieldDirectives(ft string, dir []graph.Directive) (tfi tfieldInfo, er
 		case "blocked":
 			tfi.Blocked = true
 
+		case "generated":
+			tfi.Generated = true
+
 		case "type":
 			arg, err = getArg(d.Args, "args", graph.NodeStr, graph.NodeLabel)
 			if err != nil {
@@ -0,0 +1,128 @@
+package qcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+)
+
+// timezone layouts accepted for timestamp values with an offset
+var tzLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z0700",
+}
+
+// HasTimezoneOffset returns true if the value is a timestamp with a
+// timezone offset
+func HasTimezoneOffset(v string) bool {
+	for _, l := range tzLayouts {
+		if _, err := time.Parse(l, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTimeLiterals returns an error when a timestamp column is compared
+// to a literal value without a timezone offset
+func checkTimeLiterals(ex *Exp) error {
+	if ex == nil {
+		return nil
+	}
+	if col := ex.Left.Col; col.Name != "" && col.IsTimestamp() {
+		var vals []string
+		switch {
+		case ex.Right.ValType == ValStr:
+			vals = []string{ex.Right.Val}
+		case ex.Right.ValType == ValList && ex.Right.ListType == ValStr:
+			vals = ex.Right.ListVal
+		}
+		for _, v := range vals {
+			if !HasTimezoneOffset(v) {
+				return fmt.Errorf("[Where] value for column '%s' must be a timestamp with a timezone offset: %s",
+					col.Name, v)
+			}
+		}
+	}
+	for _, c := range ex.Children {
+		if err := checkTimeLiterals(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processNowMinusOp compares the column to the current time minus the
+// duration, eg. { created_at: { within_last: "P7D" } }
+func processNowMinusOp(ex *Exp, node *graph.Node, op ExpOp) (bool, error) {
+	ex.Op = op
+	if node.Type != graph.NodeStr {
+		return false, fmt.Errorf("[Where] %s expects a duration like P7D or PT1H", node.Name)
+	}
+	secs, err := ParseDuration(node.Val)
+	if err != nil {
+		return false, fmt.Errorf("[Where] %s: %w", node.Name, err)
+	}
+	ex.Right.ValType = ValNowMinus
+	ex.Right.Val = strconv.FormatInt(secs, 10)
+	return true, nil
+}
+
+// ParseDuration returns the seconds in an ISO-8601 duration like P1DT2H,
+// years and months are not supported since their length varies
+func ParseDuration(v string) (int64, error) {
+	s := strings.ToUpper(v)
+	if len(s) < 2 || s[0] != 'P' {
+		return 0, fmt.Errorf("invalid duration: %s", v)
+	}
+
+	var secs int64
+	var n int64
+	var units int
+	var digits, inTime bool
+
+	for _, c := range s[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			n = n*10 + int64(c-'0')
+			digits = true
+			continue
+		case c == 'T' && !inTime && !digits:
+			inTime = true
+			continue
+		case !digits:
+			return 0, fmt.Errorf("invalid duration: %s", v)
+		}
+
+		switch {
+		case c == 'W' && !inTime:
+			secs += n * 7 * 86400
+		case c == 'D' && !inTime:
+			secs += n * 86400
+		case c == 'H' && inTime:
+			secs += n * 3600
+		case c == 'M' && inTime:
+			secs += n * 60
+		case c == 'S' && inTime:
+			secs += n
+		case c == 'Y' || c == 'M':
+			return 0, fmt.Errorf("durations with years or months are not supported: %s", v)
+		default:
+			return 0, fmt.Errorf("invalid duration: %s", v)
+		}
+		n, digits = 0, false
+		units++
+	}
+
+	if digits || units == 0 {
+		return 0, fmt.Errorf("invalid duration: %s", v)
+	}
+	return secs, nil
+}
@@ -0,0 +1,45 @@
+package qcode_test
+
+import (
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		val  string
+		secs int64
+		err  bool
+	}{
+		{val: "P7D", secs: 7 * 86400},
+		{val: "PT1H30M", secs: 5400},
+		{val: "P1W", secs: 7 * 86400},
+		{val: "P1DT2H3M4S", secs: 86400 + 7200 + 180 + 4},
+		{val: "pt15m", secs: 900},
+		{val: "P1M", err: true},
+		{val: "P1Y", err: true},
+		{val: "PT", err: true},
+		{val: "P", err: true},
+		{val: "7D", err: true},
+		{val: "P1H", err: true},
+		{val: "PT1D", err: true},
+	}
+
+	for _, tt := range tests {
+		secs, err := qcode.ParseDuration(tt.val)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.val)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.val, err)
+			continue
+		}
+		if secs != tt.secs {
+			t.Errorf("%s: expected %d seconds, got %d", tt.val, tt.secs, secs)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package qcode
+
+import "fmt"
+
+// Authorization rules that leave a field or table out of a result
+const (
+	RuleRoleBlock     = "role_block"        // the role config blocks queries on the table
+	RuleUserNeeded    = "user_needed"       // a filter needs a user id and the request is anonymous
+	RuleRoleColumns   = "role_columns"      // the column is not in the columns of the role
+	RuleFunctions     = "functions_blocked" // db functions are disabled for the role
+	RuleBlocklist     = "blocklist"         // the table or column is on the blocklist
+	RuleRoleDirective = "role_directive"    // @skip, @include, @add or @remove with ifRole
+)
+
+// Omission is a field or table left out of the result by an authorization
+// rule. These are only recorded when AuthTrace is enabled
+type Omission struct {
+	// Field is empty when the whole select is left out
+	Field  string
+	Rule   string
+	Role   string
+	Reason string
+}
+
+// omit records why a field or the whole select is left out of the result
+func (co *Compiler) omit(sel *Select, field, rule, role, reason string) {
+	if !co.c.AuthTrace {
+		return
+	}
+	for _, o := range sel.Omitted {
+		if o.Field == field && o.Rule == rule {
+			return
+		}
+	}
+	sel.Omitted = append(sel.Omitted, Omission{
+		Field:  field,
+		Rule:   rule,
+		Role:   role,
+		Reason: reason,
+	})
+}
+
+// omitField records the field or, for the field of the select itself, the
+// whole select
+func (co *Compiler) omitField(sel *Select, f *Field, rule, role, reason string) {
+	if f == &sel.Field {
+		co.omit(sel, "", rule, role, reason)
+	} else {
+		co.omit(sel, f.FieldName, rule, role, reason)
+	}
+}
+
+// deniedField returns the rule and reason a field can't be queried by the role
+func deniedField(qc *QCode, f Field, tr trval) (rule, reason string) {
+	switch {
+	case f.Col.Blocked:
+		return RuleBlocklist, fmt.Sprintf("column '%s.%s' is on the blocklist",
+			f.Col.Table, f.Col.Name)
+
+	case f.Type == FieldTypeCol && !tr.columnAllowed(qc, f.Col.Name):
+		return RuleRoleColumns, fmt.Sprintf("column '%s' is not in the columns of role '%s'",
+			f.Col.Name, tr.role)
+
+	case f.Type == FieldTypeFunc && tr.isFuncsBlocked():
+		return RuleFunctions, fmt.Sprintf("db functions are disabled for role '%s'", tr.role)
+
+	case f.Type == FieldTypeFunc && len(f.Args) != 0 && !tr.columnAllowed(qc, f.Args[0].Col.Name):
+		return RuleRoleColumns, fmt.Sprintf("column '%s' is not in the columns of role '%s'",
+			f.Args[0].Col.Name, tr.role)
+	}
+	return "", ""
+}
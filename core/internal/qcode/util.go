@@ -8,12 +8,42 @@ import (
 )
 
 func (co *Compiler) ParseName(name string) string {
+	if co.c.Namer != nil {
+		return co.c.Namer.DBName(name)
+	}
 	if co.c.EnableCamelcase {
 		return util.ToSnake(name)
 	}
 	return name
 }
 
+// ParseTable returns the database table name for a GraphQL name
+func (co *Compiler) ParseTable(name string) string {
+	if co.c.Namer != nil {
+		return co.c.Namer.ParseTable(name)
+	}
+	return co.ParseName(name)
+}
+
+// columnName returns the GraphQL name for a database column
+func (co *Compiler) columnName(table, col string) string {
+	if co.c.Namer != nil {
+		return co.c.Namer.Column(table, col)
+	}
+	if co.c.EnableCamelcase {
+		return util.ToCamel(col)
+	}
+	return col
+}
+
+// ParseColumn returns the database column name of a table for a GraphQL name
+func (co *Compiler) ParseColumn(table, name string) string {
+	if co.c.Namer != nil {
+		return co.c.Namer.ParseColumn(table, name)
+	}
+	return co.ParseName(name)
+}
+
 func GetQType(t graph.ParserType) QType {
 	switch t {
 	case graph.OpQuery:
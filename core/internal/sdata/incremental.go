@@ -0,0 +1,138 @@
+package sdata
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// tableChecksum is the checksum of the definition of a table
+type tableChecksum struct {
+	schema string // as named in the database
+	table  string
+	sum    string
+}
+
+// getTableChecksums returns the checksums of the tables keyed by schema
+// and table, nil is returned for databases that do not support them
+func getTableChecksums(db *sql.DB, dbType string) (map[string]tableChecksum, error) {
+	var sqlStmt string
+
+	switch dbType {
+	case "postgres", "":
+		sqlStmt = postgresChecksumsStmt
+	case "sqlite":
+		sqlStmt = sqliteChecksumsStmt
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Query(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table checksums: %w", err)
+	}
+	defer rows.Close()
+
+	m := make(map[string]tableChecksum)
+	for rows.Next() {
+		var tc tableChecksum
+		if err := rows.Scan(&tc.schema, &tc.table, &tc.sum); err != nil {
+			return nil, fmt.Errorf("error scanning table checksum: %w", err)
+		}
+		m[checksumKey(dbType, tc.schema, tc.table)] = tc
+	}
+	return m, rows.Err()
+}
+
+// checksumKey returns the key of a table as its columns are named after
+// discovery
+func checksumKey(dbType, schema, table string) string {
+	if dbType == "sqlite" {
+		return strings.ToLower(schema) + ":" + strings.ToLower(table)
+	}
+	return schema + ":" + table
+}
+
+// discoverColumnsSince returns the columns of the database and the checksums
+// of its tables. Only the columns of the tables that were added or changed
+// since the previous discovery are read, the others are taken from it.
+func discoverColumnsSince(db *sql.DB,
+	dbType string,
+	blockList []string,
+	prev *DBInfo,
+) ([]DBColumn, map[string]string, error) {
+	tcs, err := getTableChecksums(db, dbType)
+	if err != nil || tcs == nil {
+		// without checksums every discovery reads all the columns
+		cols, err := DiscoverColumns(db, dbType, blockList)
+		return cols, nil, err
+	}
+
+	sums := make(map[string]string, len(tcs))
+	for k, tc := range tcs {
+		sums[k] = tc.sum
+	}
+
+	if prev == nil || prev.checksums == nil || !sameBlockList(prev, blockList) {
+		cols, err := DiscoverColumns(db, dbType, blockList)
+		return cols, sums, err
+	}
+
+	var changed [][2]string
+	for k, tc := range tcs {
+		if s, ok := prev.checksums[k]; !ok || s != tc.sum {
+			changed = append(changed, [2]string{tc.schema, tc.table})
+		}
+	}
+
+	// columns of unchanged tables, dropped tables are left out
+	var cols []DBColumn
+	for _, c := range prev.discovered {
+		k := c.Schema + ":" + c.Table
+		if s, ok := sums[k]; ok && s == prev.checksums[k] {
+			cols = append(cols, c)
+		}
+	}
+
+	if len(changed) != 0 {
+		ccols, err := discoverColumns(db, dbType, blockList, changed)
+		if err != nil {
+			return nil, nil, err
+		}
+		cols = append(cols, ccols...)
+	}
+
+	for i := range cols {
+		cols[i].ID = int32(i)
+	}
+	return cols, sums, nil
+}
+
+// sameBlockList returns true when the columns of the previous discovery
+// were blocked with the same list
+func sameBlockList(prev *DBInfo, blockList []string) bool {
+	for _, c := range prev.discovered {
+		if c.Blocked != isInList(c.Name, blockList) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterTables limits a columns statement to the given tables
+func filterTables(sqlStmt string, tables [][2]string) string {
+	var sb strings.Builder
+	sb.WriteString(`SELECT * FROM (`)
+	sb.WriteString(strings.TrimRight(strings.TrimSpace(sqlStmt), ";"))
+	sb.WriteString(`) t WHERE t."schema" || ':' || t."table" IN (`)
+	for i, t := range tables {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(`'`)
+		sb.WriteString(strings.ReplaceAll(t[0]+":"+t[1], `'`, `''`))
+		sb.WriteString(`'`)
+	}
+	sb.WriteString(`)`)
+	return sb.String()
+}
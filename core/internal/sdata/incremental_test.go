@@ -0,0 +1,90 @@
+package sdata
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGetDBInfoSince(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	if _, err := db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE products (id INTEGER PRIMARY KEY, title TEXT);
+		CREATE TABLE tags (id INTEGER PRIMARY KEY);`); err != nil {
+		t.Fatal(err)
+	}
+
+	prev, err := GetDBInfo(db, "sqlite", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prev.checksums) != 3 {
+		t.Fatalf("expected a checksum per table, got %v", prev.checksums)
+	}
+
+	// marks the columns of the previous discovery to tell which are reused
+	for i := range prev.discovered {
+		prev.discovered[i].Comment = "previous"
+	}
+
+	if _, err := db.Exec(`
+		ALTER TABLE products ADD COLUMN price INTEGER;
+		DROP TABLE tags;
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id));`); err != nil {
+		t.Fatal(err)
+	}
+
+	di, err := GetDBInfoSince(db, "sqlite", nil, prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols := make(map[string]string)
+	var names []string
+	for _, c := range di.discovered {
+		k := c.Table + "." + c.Name
+		cols[k] = c.Comment
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	exp := "orders.id orders.user_id products.id products.price products.title users.id users.name"
+	if got := strings.Join(names, " "); got != exp {
+		t.Fatalf("expected columns %s, got %s", exp, got)
+	}
+	if cols["users.name"] != "previous" {
+		t.Error("expected the columns of the unchanged table to be reused")
+	}
+	if cols["products.title"] != "" || cols["orders.id"] != "" {
+		t.Error("expected the columns of changed and new tables to be read")
+	}
+
+	if _, err := di.GetTable("main", "tags"); err == nil {
+		t.Error("expected the dropped table to be removed")
+	}
+	c, err := di.GetColumn("main", "orders", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FKeyTable != "users" {
+		t.Errorf("expected the foreign key of the new table, got %+v", c)
+	}
+}
+
+func TestFilterTables(t *testing.T) {
+	got := filterTables("SELECT 1 AS x;\n", [][2]string{{"public", "users"}, {"public", "o'neil"}})
+	exp := `SELECT * FROM (SELECT 1 AS x) t WHERE t."schema" || ':' || t."table" IN ('public:users', 'public:o''neil')`
+	if got != exp {
+		t.Fatalf("expected %s, got %s", exp, got)
+	}
+}
@@ -102,6 +102,13 @@ func (r *DBRel) IsCrossDatabase() bool {
 	return leftDB != rightDB
 }
 
+// IsCrossServer returns true if the tables of this relationship are on
+// different servers, one of them being a foreign data wrapper table.
+// Joining them runs on the local server after fetching the foreign rows.
+func (r *DBRel) IsCrossServer() bool {
+	return r.Left.Ti.ForeignServer != r.Right.Ti.ForeignServer
+}
+
 // NewDBSchema creates a new database schema
 func NewDBSchema(
 	info *DBInfo,
@@ -14,12 +14,27 @@ var postgresInfo string
 //go:embed sql/postgres_columns.sql
 var postgresColumnsStmt string
 
+//go:embed sql/postgres_partitions.sql
+var postgresPartitionsStmt string
+
+//go:embed sql/postgres_checksums.sql
+var postgresChecksumsStmt string
+
+//go:embed sql/postgres_foreign_tables.sql
+var postgresForeignTablesStmt string
+
+//go:embed sql/postgres_generated.sql
+var postgresGeneratedStmt string
+
 //go:embed sql/mysql_info.sql
 var mysqlInfo string
 
 //go:embed sql/mysql_columns.sql
 var mysqlColumnsStmt string
 
+//go:embed sql/mysql_generated.sql
+var mysqlGeneratedStmt string
+
 //go:embed sql/sqlite_functions.sql
 var sqliteFunctionsStmt string
 
@@ -29,6 +44,12 @@ var sqliteInfo string
 //go:embed sql/sqlite_columns.sql
 var sqliteColumnsStmt string
 
+//go:embed sql/sqlite_checksums.sql
+var sqliteChecksumsStmt string
+
+//go:embed sql/sqlite_generated.sql
+var sqliteGeneratedStmt string
+
 //go:embed sql/oracle_functions.sql
 var oracleFunctionsStmt string
 
@@ -38,6 +59,9 @@ var oracleInfo string
 //go:embed sql/oracle_columns.sql
 var oracleColumnsStmt string
 
+//go:embed sql/oracle_generated.sql
+var oracleGeneratedStmt string
+
 //go:embed sql/mariadb_functions.sql
 var mariadbFunctionsStmt string
 
@@ -59,6 +83,9 @@ var mssqlColumnsStmt string
 //go:embed sql/mssql_view_pks.sql
 var mssqlViewPKsStmt string
 
+//go:embed sql/mssql_generated.sql
+var mssqlGeneratedStmt string
+
 //go:embed sql/snowflake_functions.sql
 var snowflakeFunctionsStmt string
 
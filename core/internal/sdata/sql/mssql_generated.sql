@@ -0,0 +1,9 @@
+SELECT
+    s.name AS [schema],
+    t.name AS [table],
+    c.name AS [column]
+FROM sys.columns c
+JOIN sys.tables t ON c.object_id = t.object_id
+JOIN sys.schemas s ON t.schema_id = s.schema_id
+WHERE (c.is_computed = 1 OR c.is_identity = 1)
+  AND s.name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest')
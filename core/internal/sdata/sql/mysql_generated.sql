@@ -0,0 +1,14 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column"
+FROM information_schema.columns col
+WHERE (col.extra LIKE '%VIRTUAL GENERATED%'
+		OR col.extra LIKE '%STORED GENERATED%'
+		OR col.extra LIKE '%PERSISTENT GENERATED%')
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	);
@@ -0,0 +1,9 @@
+SELECT owner AS "schema", table_name AS "table", column_name AS "column"
+FROM all_tab_cols
+WHERE virtual_column = 'YES' AND hidden_column = 'NO'
+  AND owner NOT IN ('SYS', 'SYSTEM', 'XDB', 'MDSYS', 'CTXSYS', 'WMSYS', 'ORDSYS', 'OLAPSYS')
+UNION
+SELECT owner AS "schema", table_name AS "table", column_name AS "column"
+FROM all_tab_identity_cols
+WHERE generation_type = 'ALWAYS'
+  AND owner NOT IN ('SYS', 'SYSTEM', 'XDB', 'MDSYS', 'CTXSYS', 'WMSYS', 'ORDSYS', 'OLAPSYS')
@@ -0,0 +1,24 @@
+SELECT n.nspname AS "schema",
+	c.relname AS "table",
+	concat_ws(
+		'/',
+		c.xmin::text,
+		(
+			SELECT string_agg(a.xmin::text, ',' ORDER BY a.attnum)
+			FROM pg_attribute a
+			WHERE a.attrelid = c.oid
+				AND a.attnum > 0
+		),
+		(
+			SELECT string_agg(co.xmin::text, ',' ORDER BY co.oid)
+			FROM pg_constraint co
+			WHERE co.conrelid = c.oid
+				OR co.confrelid = c.oid
+		)
+	) AS checksum
+FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	AND c.relispartition = false
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND c.relname != 'schema_version';
@@ -70,7 +70,9 @@ FROM pg_attribute f
 	LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
 	LEFT JOIN pg_constraint co ON co.conrelid = c.oid
 	AND f.attnum = ANY (co.conkey)
+	AND co.conparentid = 0
 WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	AND c.relispartition = false
 	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
 	AND c.relname != 'schema_version'
 	AND f.attnum > 0
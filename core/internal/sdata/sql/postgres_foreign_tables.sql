@@ -0,0 +1,8 @@
+SELECT n.nspname AS "schema",
+	c.relname AS "table",
+	s.srvname AS "server"
+FROM pg_foreign_table ft
+	JOIN pg_class c ON c.oid = ft.ftrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_foreign_server s ON s.oid = ft.ftserver
+WHERE n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,11 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	a.attname as "column"
+FROM pg_catalog.pg_attribute a
+	JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p')
+	AND a.attnum > 0
+	AND NOT a.attisdropped
+	AND (a.attgenerated <> '' OR a.attidentity = 'a')
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
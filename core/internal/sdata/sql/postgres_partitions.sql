@@ -0,0 +1,12 @@
+SELECT n.nspname AS "schema",
+	c.relname AS "table",
+	a.attname AS "column"
+FROM pg_partitioned_table p
+	JOIN pg_class c ON c.oid = p.partrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_attribute a ON a.attrelid = p.partrelid
+	AND a.attnum = p.partattrs[0]
+WHERE p.partstrat = 'r'
+	AND p.partnatts = 1
+	AND c.relispartition = false
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,14 @@
+SELECT 'main' AS "schema",
+  m.name AS "table",
+  COALESCE(m.sql, '') || COALESCE((
+    -- content-backed FTS5 tables change the full text columns of the table
+    SELECT group_concat(fm.sql)
+    FROM sqlite_master fm
+    WHERE fm.type = 'table'
+      AND fm.sql LIKE '%USING fts5%'
+      AND fm.sql LIKE '%content=''' || m.name || '''%'
+  ), '') AS checksum
+FROM sqlite_master m
+WHERE (m.type = 'table' OR m.type = 'view')
+AND m.name NOT LIKE 'sqlite_%'
+AND m.name NOT LIKE '_gj_%';
@@ -24,9 +24,10 @@ SELECT
   COALESCE(fk."table", '') as foreignkey_table,
   COALESCE(fk."to", '') as foreignkey_column
 FROM sqlite_master m
-JOIN pragma_table_info(m.name) p
+JOIN pragma_table_xinfo(m.name) p
 LEFT JOIN pragma_foreign_key_list(m.name) fk ON fk."from" = p.name
 WHERE (m.type = 'table' OR m.type = 'view')
 AND m.name NOT LIKE 'sqlite_%'
 AND m.name NOT LIKE '_gj_%'
+AND p.hidden != 1
 ORDER BY m.name, p.cid;
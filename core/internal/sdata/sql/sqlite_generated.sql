@@ -0,0 +1,9 @@
+SELECT 'main' as "schema",
+  m.name as "table",
+  p.name as "column"
+FROM sqlite_master m
+JOIN pragma_table_xinfo(m.name) p
+WHERE m.type = 'table'
+AND p.hidden IN (2, 3)
+AND m.name NOT LIKE 'sqlite_%'
+AND m.name NOT LIKE '_gj_%';
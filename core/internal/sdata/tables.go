@@ -8,6 +8,7 @@ import (
 	"hash/fnv"
 	"io"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,6 +30,10 @@ type DBInfo struct {
 	colMap       map[string]int
 	tableMap     map[string]int
 	hash         int
+
+	// discovered columns and table checksums kept for incremental discovery
+	discovered []DBColumn
+	checksums  map[string]string
 }
 
 // DBTable holds the database table information
@@ -52,6 +57,7 @@ type DBTable struct {
 	ClusteringKeys     []string // Snowflake clustering key columns (normalized to snake_case)
 	PartitionKey       string   // Partition column name (from config, e.g., "created_at")
 	PartitionRangeDays int      // Default range in days for auto-injected partition filter (0 = warn only)
+	ForeignServer      string   // Postgres foreign data wrapper server of a foreign table
 	colMap             map[string]int
 }
 
@@ -68,6 +74,20 @@ func GetDBInfo(
 	db *sql.DB,
 	dbType string,
 	blockList []string,
+) (*DBInfo, error) {
+	return GetDBInfoSince(db, dbType, blockList, nil)
+}
+
+// GetDBInfoSince returns the database schema information, the columns of
+// the tables that have not changed since the previous discovery are reused
+// instead of being read again. Change detection uses per-table checksums
+// and is supported for Postgres and SQLite, for other databases or without
+// a previous discovery all the columns are read.
+func GetDBInfoSince(
+	db *sql.DB,
+	dbType string,
+	blockList []string,
+	prev *DBInfo,
 ) (*DBInfo, error) {
 	retryDelays := []time.Duration{
 		0,
@@ -83,7 +103,7 @@ func GetDBInfo(
 			time.Sleep(delay)
 		}
 
-		di, err := getDBInfoOnce(db, dbType, blockList)
+		di, err := getDBInfoOnce(db, dbType, blockList, prev)
 		if err == nil {
 			return di, nil
 		}
@@ -100,10 +120,12 @@ func getDBInfoOnce(
 	db *sql.DB,
 	dbType string,
 	blockList []string,
+	prev *DBInfo,
 ) (*DBInfo, error) {
 	var dbVersion int
 	var dbSchema, dbName string
 	var cols []DBColumn
+	var checksums map[string]string
 	var funcs []DBFunction
 	var compositeFKs []CompositeFKInfo
 
@@ -145,7 +167,7 @@ func getDBInfoOnce(
 
 	g.Go(func() error {
 		var err error
-		if cols, err = DiscoverColumns(db, dbType, blockList); err != nil {
+		if cols, checksums, err = discoverColumnsSince(db, dbType, blockList, prev); err != nil {
 			return err
 		}
 
@@ -171,6 +193,8 @@ func getDBInfoOnce(
 		funcs,
 		blockList)
 	di.CompositeFKs = compositeFKs
+	di.discovered = slices.Clone(cols)
+	di.checksums = checksums
 
 	// For Snowflake, discover clustering keys and attach to tables.
 	// Non-fatal: if this fails we just skip clustering optimization.
@@ -192,6 +216,29 @@ func getDBInfoOnce(
 		}
 	}
 
+	// For Postgres, partitions are left out of discovery and queries go to
+	// the partitioned parent. The key of a range partitioned table becomes
+	// its partition key so queries without a filter on it get a warning.
+	if dbType == "postgres" || dbType == "" {
+		if pk, err := discoverPartitionKeys(db); err == nil {
+			for i := range di.Tables {
+				key := di.Tables[i].Schema + ":" + di.Tables[i].Name
+				if col, ok := pk[key]; ok && di.Tables[i].PartitionKey == "" {
+					di.Tables[i].PartitionKey = col
+				}
+			}
+		}
+
+		// Foreign tables are tagged with their server, joins between
+		// tables on different servers cannot be run by one of them
+		if fs, err := discoverForeignTables(db); err == nil {
+			for i := range di.Tables {
+				key := di.Tables[i].Schema + ":" + di.Tables[i].Name
+				di.Tables[i].ForeignServer = fs[key]
+			}
+		}
+	}
+
 	return di, nil
 }
 
@@ -370,6 +417,24 @@ func (di *DBInfo) AddTable(t DBTable) {
 	di.tableMap[(t.Schema + ":" + t.Name)] = i
 }
 
+// AddColumn adds a column to a table of the DBInfo object. It is used with
+// schemaless databases where a field can be missing from the sampled documents
+func (di *DBInfo) AddColumn(c DBColumn) (*DBColumn, error) {
+	t, err := di.GetTable(c.Schema, c.Table)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := t.colMap[c.Name]; ok {
+		return nil, fmt.Errorf("column: '%s.%s.%s' already exists", c.Schema, c.Table, c.Name)
+	}
+
+	i := len(t.Columns)
+	t.Columns = append(t.Columns, c)
+	t.colMap[c.Name] = i
+	di.colMap[(c.Schema + ":" + c.Table + ":" + c.Name)] = i
+	return &t.Columns[i], nil
+}
+
 // GetTable returns a table from the DBInfo object
 func (di *DBInfo) GetColumn(schema, table, column string) (*DBColumn, error) {
 	t, err := di.GetTable(schema, table)
@@ -407,6 +472,7 @@ type DBColumn struct {
 	PrimaryKey   bool
 	UniqueKey    bool
 	FullText     bool
+	AsString     bool // value is returned as a string, set from the column config
 	FKRecursive  bool
 	FKeyDatabase string // Target database for cross-database FKs (empty = same db)
 	FKeySchema   string
@@ -422,6 +488,10 @@ type DBColumn struct {
 	IndexName    string
 	FKOnDelete   string
 	FKOnUpdate   string
+	// Generated is set for columns the database computes, generated columns
+	// and identity columns that always generate their value. They can't be
+	// written by mutations but are returned like any other column
+	Generated bool
 
 	// Original names before normalization (used to build dialect name maps for MSSQL)
 	OrigTable      string
@@ -431,6 +501,129 @@ type DBColumn struct {
 	OrigFKeyCol    string
 }
 
+// generatedStmt returns the statement listing the columns the database
+// computes, there is none for databases without such columns
+func generatedStmt(dbtype string) string {
+	switch dbtype {
+	case "postgres", "":
+		return postgresGeneratedStmt
+	case "mysql", "mariadb":
+		return mysqlGeneratedStmt
+	case "sqlite":
+		return sqliteGeneratedStmt
+	case "oracle":
+		return oracleGeneratedStmt
+	case "mssql":
+		return mssqlGeneratedStmt
+	}
+	return ""
+}
+
+// markGenerated flags the generated (computed) columns and the identity
+// columns that always generate their value. Errors are ignored, older
+// database versions without these columns just don't flag any
+func markGenerated(db *sql.DB, dbtype string, cmap map[string]DBColumn) {
+	stmt := generatedStmt(dbtype)
+	if stmt == "" {
+		return
+	}
+	rows, err := db.Query(stmt)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schema, table, column string
+		if err := rows.Scan(&schema, &table, &column); err != nil {
+			continue
+		}
+		if dbtype == "sqlite" || dbtype == "oracle" || dbtype == "mssql" {
+			column = util.ToSnake(column)
+			table = strings.ToLower(table)
+			schema = strings.ToLower(schema)
+		}
+		k := schema + ":" + table + ":" + column
+		if v, ok := cmap[k]; ok {
+			v.Generated = true
+			cmap[k] = v
+		}
+	}
+}
+
+// IsDecimal returns true for decimal and numeric columns, their exact
+// values lose precision when read as JSON numbers
+func (col DBColumn) IsDecimal() bool {
+	if col.Array {
+		return false
+	}
+	t := strings.ToLower(col.Type)
+	if i := strings.IndexRune(t, '('); i != -1 {
+		t = t[:i]
+	}
+	t = strings.TrimSpace(t)
+	return t == "decimal" || t == "numeric"
+}
+
+// IsBigInt returns true for 64-bit integer columns, their values above
+// 2^53 lose precision when read as JSON numbers by javascript clients
+func (col DBColumn) IsBigInt() bool {
+	return !col.Array && IsBigIntType(col.Type)
+}
+
+// IsBigIntType returns true if the type is a 64-bit integer type
+func IsBigIntType(t string) bool {
+	t = strings.ToLower(t)
+	if i := strings.IndexRune(t, '('); i != -1 {
+		if j := strings.IndexRune(t[i:], ')'); j != -1 {
+			t = t[:i] + t[i+j+1:]
+		}
+	}
+	switch strings.Join(strings.Fields(t), " ") {
+	case "bigint", "int8", "bigserial", "serial8", "long", "int64", "uint64",
+		"bigint unsigned", "unsigned bigint":
+		return true
+	}
+	return false
+}
+
+// IsTimestamp returns true for timestamp and datetime columns
+func (col DBColumn) IsTimestamp() bool {
+	ok, _ := IsTimestampType(col.Type)
+	return ok && !col.Array
+}
+
+// HasTimezone returns true for timestamp columns that store the timezone
+func (col DBColumn) HasTimezone() bool {
+	_, tz := IsTimestampType(col.Type)
+	return tz
+}
+
+// IsInterval returns true for interval columns
+func (col DBColumn) IsInterval() bool {
+	return !col.Array && strings.HasPrefix(strings.ToLower(col.Type), "interval")
+}
+
+// IsTimestampType returns true if the type is a timestamp type and if
+// values of the type carry a timezone
+func IsTimestampType(t string) (ok bool, withTZ bool) {
+	t = strings.ToLower(t)
+	if i := strings.IndexRune(t, '('); i != -1 {
+		if j := strings.IndexRune(t[i:], ')'); j != -1 {
+			t = t[:i] + t[i+j+1:]
+		}
+	}
+	switch strings.Join(strings.Fields(t), " ") {
+	case "timestamp", "timestamp without time zone", "datetime", "datetime2",
+		"smalldatetime", "timestamp_ntz":
+		return true, false
+	case "timestamptz", "timestamp with time zone", "timestamp with local time zone",
+		"datetimeoffset", "timestamp_tz", "timestamp_ltz":
+		return true, true
+	}
+	return false, false
+}
+
 // ColPair represents a column pair in a composite foreign key relationship.
 type ColPair struct {
 	L DBColumn // Local column
@@ -450,6 +643,12 @@ type CompositeFKInfo struct {
 
 // DiscoverColumns returns the columns of a table
 func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn, error) {
+	return discoverColumns(db, dbtype, blockList, nil)
+}
+
+// discoverColumns returns the columns of the tables, the tables are given
+// as [schema, table] pairs and all of them are returned when none are
+func discoverColumns(db *sql.DB, dbtype string, blockList []string, tables [][2]string) ([]DBColumn, error) {
 	var sqlStmt string
 
 	switch dbtype {
@@ -474,6 +673,10 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 		return nil, fmt.Errorf("unsupported database type %q: supported types are postgres, mysql, mariadb, sqlite, oracle, mssql, snowflake, mongodb", dbtype)
 	}
 
+	if len(tables) != 0 {
+		sqlStmt = filterTables(sqlStmt, tables)
+	}
+
 	rows, err := db.Query(sqlStmt)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching columns: %w", err)
@@ -607,6 +810,8 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 		// Silently ignore errors — falls back to config-based override
 	}
 
+	markGenerated(db, dbtype, cmap)
+
 	var cols []DBColumn
 	for _, c := range cmap {
 		cols = append(cols, c)
@@ -982,6 +1187,46 @@ func discoverClusteringKeys(db *sql.DB) (map[string][]string, error) {
 	return result, rows.Err()
 }
 
+// discoverPartitionKeys queries Postgres for the single column keys of range
+// partitioned tables. Returns a map of "schema:table" → column_name.
+func discoverPartitionKeys(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(postgresPartitionsStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching partition keys: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var schema, table, column string
+		if err := rows.Scan(&schema, &table, &column); err != nil {
+			return nil, fmt.Errorf("error scanning partition key row: %w", err)
+		}
+		result[schema+":"+table] = column
+	}
+	return result, rows.Err()
+}
+
+// discoverForeignTables returns the server of each Postgres foreign table
+// keyed by schema and table
+func discoverForeignTables(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(postgresForeignTablesStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching foreign tables: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var schema, table, server string
+		if err := rows.Scan(&schema, &table, &server); err != nil {
+			return nil, fmt.Errorf("error scanning foreign table row: %w", err)
+		}
+		result[schema+":"+table] = server
+	}
+	return result, rows.Err()
+}
+
 // autoSetPartitionFromClustering checks if the leading clustering key column
 // is a temporal type (date, timestamp, etc.) and, if so, sets it as the
 // table's partition key with a default 90-day range filter. This enables
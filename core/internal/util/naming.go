@@ -0,0 +1,152 @@
+package util
+
+import (
+	"strings"
+)
+
+// Namer converts database table and column names to the names exposed
+// in GraphQL and back. A nil Namer leaves all names unchanged.
+type Namer struct {
+	toName   func(string) string
+	toDBName func(string) string
+	camel    bool
+	acronyms map[string]string
+	tables   map[string]string
+	rtables  map[string]string
+	columns  map[string]string
+	rcolumns map[string]string
+}
+
+// NamerConfig configures a Namer. Table overrides are keyed by the
+// database table name and column overrides by 'table.column' or just
+// 'column' to apply to all tables.
+type NamerConfig struct {
+	Camel    bool
+	ToName   func(string) string
+	ToDBName func(string) string
+	Acronyms []string
+	Tables   map[string]string
+	Columns  map[string]string
+}
+
+// NewNamer returns a new Namer
+func NewNamer(c NamerConfig) *Namer {
+	n := &Namer{
+		toName:   c.ToName,
+		toDBName: c.ToDBName,
+		camel:    c.Camel,
+		acronyms: make(map[string]string, len(c.Acronyms)),
+		tables:   make(map[string]string, len(c.Tables)),
+		rtables:  make(map[string]string, len(c.Tables)),
+		columns:  make(map[string]string, len(c.Columns)),
+		rcolumns: make(map[string]string, len(c.Columns)),
+	}
+
+	if n.camel && n.toName == nil {
+		n.toName = n.camelName
+	}
+	if n.camel && n.toDBName == nil {
+		n.toDBName = ToSnake
+	}
+
+	for _, a := range c.Acronyms {
+		n.acronyms[strings.ToLower(a)] = strings.ToUpper(a)
+	}
+	for k, v := range c.Tables {
+		n.tables[k] = v
+		n.rtables[v] = k
+	}
+	for k, v := range c.Columns {
+		n.columns[k] = v
+		table, col := "", k
+		if i := strings.LastIndexByte(k, '.'); i != -1 {
+			table, col = k[:i], k[i+1:]
+		}
+		n.rcolumns[table+"."+v] = col
+	}
+	return n
+}
+
+// Name returns the GraphQL name for a database name
+func (n *Namer) Name(name string) string {
+	if n == nil || n.toName == nil {
+		return name
+	}
+	return n.toName(name)
+}
+
+// DBName returns the database name for a GraphQL name
+func (n *Namer) DBName(name string) string {
+	if n == nil || n.toDBName == nil {
+		return name
+	}
+	return n.toDBName(name)
+}
+
+// Table returns the GraphQL name for a database table
+func (n *Namer) Table(name string) string {
+	if n != nil {
+		if v, ok := n.tables[name]; ok {
+			return v
+		}
+	}
+	return n.Name(name)
+}
+
+// Column returns the GraphQL name for a column of a database table
+func (n *Namer) Column(table, name string) string {
+	if n != nil {
+		if v, ok := n.columns[table+"."+name]; ok {
+			return v
+		}
+		if v, ok := n.columns[name]; ok {
+			return v
+		}
+	}
+	return n.Name(name)
+}
+
+// ParseTable returns the database table name for a GraphQL name
+func (n *Namer) ParseTable(name string) string {
+	if n != nil {
+		if v, ok := n.rtables[name]; ok {
+			return v
+		}
+	}
+	return n.DBName(name)
+}
+
+// ParseColumn returns the database column name of a table for a GraphQL name
+func (n *Namer) ParseColumn(table, name string) string {
+	if n != nil {
+		if v, ok := n.rcolumns[table+"."+name]; ok {
+			return v
+		}
+		if v, ok := n.rcolumns["."+name]; ok {
+			return v
+		}
+	}
+	return n.DBName(name)
+}
+
+// camelName converts a snake case name to lowerCamelCase keeping
+// configured acronyms in upper case (eg. user_id -> userID)
+func (n *Namer) camelName(name string) string {
+	if len(n.acronyms) == 0 || strings.HasPrefix(name, "__") {
+		return ToCamel(name)
+	}
+
+	var sb strings.Builder
+	for i, w := range strings.Split(name, "_") {
+		switch a, ok := n.acronyms[strings.ToLower(w)]; {
+		case w == "":
+		case i == 0:
+			sb.WriteString(ToCamel(w))
+		case ok:
+			sb.WriteString(a)
+		default:
+			sb.WriteString(strings.ToUpper(w[:1]) + ToCamel(w[1:]))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package util
+
+import "testing"
+
+func TestNamer(t *testing.T) {
+	n := NewNamer(NamerConfig{
+		Camel:    true,
+		Acronyms: []string{"id", "URL"},
+		Tables:   map[string]string{"user_accounts": "members"},
+		Columns:  map[string]string{"users.email": "emailAddress", "created_at": "createdOn"},
+	})
+
+	names := [][]string{
+		{n.Name("user_id"), "userID"},
+		{n.Name("avatar_url"), "avatarURL"},
+		{n.Name("full_name"), "fullName"},
+		{n.Name("id"), "id"},
+		{n.Table("user_accounts"), "members"},
+		{n.Table("order_items"), "orderItems"},
+		{n.Column("users", "email"), "emailAddress"},
+		{n.Column("posts", "email"), "email"},
+		{n.Column("posts", "created_at"), "createdOn"},
+		{n.ParseTable("members"), "user_accounts"},
+		{n.ParseTable("orderItems"), "order_items"},
+		{n.ParseColumn("users", "emailAddress"), "email"},
+		{n.ParseColumn("posts", "createdOn"), "created_at"},
+		{n.ParseColumn("users", "userID"), "user_id"},
+	}
+	for i, v := range names {
+		if v[0] != v[1] {
+			t.Errorf("%d: expected %q, got %q", i, v[1], v[0])
+		}
+	}
+
+	var nn *Namer
+	if nn.Column("users", "user_id") != "user_id" || nn.ParseTable("users") != "users" {
+		t.Error("nil namer must not change names")
+	}
+}
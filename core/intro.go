@@ -25,21 +25,26 @@ const (
 	LOC_SUBSCRIPTION = "SUBSCRIPTION"
 	LOC_FIELD        = "FIELD"
 
-	SUFFIX_EXP      = "Expression"
-	SUFFIX_LISTEXP  = "ListExpression"
-	SUFFIX_INPUT    = "Input"
-	SUFFIX_ORDER_BY = "OrderByInput"
-	SUFFIX_WHERE    = "WhereInput"
-	SUFFIX_ARGS     = "ArgsInput"
-	SUFFIX_ENUM     = "Enum"
+	SUFFIX_EXP       = "Expression"
+	SUFFIX_LISTEXP   = "ListExpression"
+	SUFFIX_INPUT     = "Input"
+	SUFFIX_ORDER_BY  = "OrderByInput"
+	SUFFIX_ORDER_KEY = "OrderByKey"
+	SUFFIX_WHERE     = "WhereInput"
+	SUFFIX_ARGS      = "ArgsInput"
+	SUFFIX_ENUM      = "Enum"
 )
 
 var (
-	TYPE_STRING  = "String"
-	TYPE_INT     = "Int"
-	TYPE_BOOLEAN = "Boolean"
-	TYPE_FLOAT   = "Float"
-	TYPE_JSON    = "JSON"
+	TYPE_STRING    = "String"
+	TYPE_INT       = "Int"
+	TYPE_BOOLEAN   = "Boolean"
+	TYPE_FLOAT     = "Float"
+	TYPE_JSON      = "JSON"
+	TYPE_DECIMAL   = "Decimal"
+	TYPE_BIGINT    = "BigInt"
+	TYPE_DURATION  = "Duration"
+	TYPE_TIMESTAMP = "Timestamp"
 )
 
 type TypeRef struct {
@@ -130,6 +135,10 @@ var stdTypes = []FullType{
 		Kind:        KIND_SCALAR,
 		Name:        TYPE_JSON,
 		Description: "The `JSON` scalar type represents json data",
+	}, {
+		Kind:        KIND_SCALAR,
+		Name:        TYPE_DURATION,
+		Description: "The `Duration` scalar type represents intervals as ISO-8601 durations like `P1DT2H`",
 	}, {
 		Kind:       KIND_OBJECT,
 		Name:       "Query",
@@ -177,6 +186,12 @@ var stdTypes = []FullType{
 		}, {
 			Name:        "desc_nulls_last",
 			Description: "Descending nulls last order",
+		}, {
+			Name:        "asc_ci",
+			Description: "Ascending case-insensitive order",
+		}, {
+			Name:        "desc_ci",
+			Description: "Descending case-insensitive order",
 		}},
 	}, {
 		Kind:        KIND_SCALAR,
@@ -187,26 +202,85 @@ var stdTypes = []FullType{
 		Kind:        KIND_SCALAR,
 		Name:        "Cursor",
 		Description: "A cursor is an encoded string use for pagination",
+	}, {
+		Kind:        KIND_ENUM,
+		Name:        "LockMode",
+		Description: "Row locking modes",
+		EnumValues: []EnumValue{{
+			Name:        "update",
+			Description: "Lock the selected rows for update (FOR UPDATE)",
+		}, {
+			Name:        "share",
+			Description: "Lock the selected rows against updates (FOR SHARE)",
+		}},
+	}, {
+		Kind:        KIND_INPUT_OBJ,
+		Name:        "LockInput",
+		Description: "Lock the selected rows, must be allowed for the role",
+		Interfaces:  []TypeRef{},
+		InputFields: []InputValue{{
+			Name:        "mode",
+			Description: "Lock mode, defaults to update",
+			Type:        newTypeRef("", "LockMode", nil),
+		}, {
+			Name:        "skip_locked",
+			Description: "Skip rows locked by other transactions",
+			Type:        newTypeRef("", TYPE_BOOLEAN, nil),
+		}, {
+			Name:        "nowait",
+			Description: "Fail instead of waiting for rows locked by other transactions",
+			Type:        newTypeRef("", TYPE_BOOLEAN, nil),
+		}},
+	}, {
+		Kind:        KIND_INPUT_OBJ,
+		Name:        "PartitionInput",
+		Description: "Range of the partition key to query, lets the database skip other partitions",
+		Interfaces:  []TypeRef{},
+		InputFields: []InputValue{{
+			Name:        "from",
+			Description: "Start of the range, inclusive",
+			Type:        newTypeRef("", TYPE_STRING, nil),
+		}, {
+			Name:        "to",
+			Description: "End of the range, exclusive",
+			Type:        newTypeRef("", TYPE_STRING, nil),
+		}},
 	},
 }
 
 type Introspection struct {
-	schema      *sdata.DBSchema
-	camelCase   bool
-	types       map[string]FullType
-	enumValues  map[string]EnumValue
-	inputValues map[string]InputValue
-	result      IntroResult
+	schema     *sdata.DBSchema
+	namer      *util.Namer
+	deprecated map[string]string
+	limits     map[string]string
+	// limitDefaults is the default limit keyed by table, "" is the fallback
+	limitDefaults map[string]string
+	// orderPresets are the order_by presets keyed by schema and table
+	orderPresets map[string][]string
+	types        map[string]FullType
+	enumValues   map[string]EnumValue
+	inputValues  map[string]InputValue
+	// decimal is set when decimal columns are typed as the Decimal scalar
+	decimal bool
+	// bigint is set when 64-bit integer columns are typed as the BigInt scalar
+	bigint bool
+	result IntroResult
 }
 
 // introQuery returns the introspection query result
 func (gj *graphjinEngine) introQuery() (result json.RawMessage, err error) {
 	// Initialize the introspection object
 	in := Introspection{
-		camelCase:   gj.conf.EnableCamelcase,
-		types:       make(map[string]FullType),
-		enumValues:  make(map[string]EnumValue),
-		inputValues: make(map[string]InputValue),
+		namer:         gj.namer,
+		deprecated:    gj.conf.deprecations(),
+		limits:        gj.conf.limitDescriptions(),
+		limitDefaults: gj.conf.limitDefaults(),
+		orderPresets:  gj.orderPresets(),
+		types:         make(map[string]FullType),
+		enumValues:    make(map[string]EnumValue),
+		inputValues:   make(map[string]InputValue),
+		decimal:       gj.conf.DecimalAsString,
+		bigint:        gj.conf.BigIntAsString,
 	}
 
 	// Initialize the schema
@@ -244,13 +318,38 @@ func (gj *graphjinEngine) introQuery() (result json.RawMessage, err error) {
 	v = append(expAll, expJSON...)
 	in.addExpTypes(v, "JSON", newTypeRef("", "String", nil))
 
+	v = append(expAll, expScalar...)
+	in.addExpTypes(v, TYPE_DURATION, newTypeRef("", TYPE_DURATION, nil))
+	in.addExpTypes(append(v, expTime...), TYPE_TIMESTAMP, newTypeRef("", TYPE_STRING, nil))
+
+	if in.decimal {
+		in.addType(FullType{
+			Kind:        KIND_SCALAR,
+			Name:        TYPE_DECIMAL,
+			Description: "The `Decimal` scalar type represents decimal and numeric values as strings so their precision is kept",
+		})
+		v = append(expAll, expScalar...)
+		in.addExpTypes(v, TYPE_DECIMAL, newTypeRef("", TYPE_DECIMAL, nil))
+	}
+
+	// columns configured as strings can be 64-bit integers too
+	if in.bigint || gj.conf.hasStringColumns() {
+		in.addType(FullType{
+			Kind:        KIND_SCALAR,
+			Name:        TYPE_BIGINT,
+			Description: "The `BigInt` scalar type represents 64-bit integers as strings so javascript clients do not lose precision",
+		})
+		v = append(expAll, expScalar...)
+		in.addExpTypes(v, TYPE_BIGINT, newTypeRef("", TYPE_BIGINT, nil))
+	}
+
 	// Add the roles
 	in.addRolesEnumType(gj.roles)
 
 	// Aggregate tables and aliases from all database schemas (deterministic order)
 	for _, dbName := range gj.sortedDatabaseNames() {
 		ctx := gj.databases[dbName]
-		if ctx.schema == nil {
+		if !ctx.ready() {
 			continue
 		}
 		// Set the current schema for relationship lookups within addTable
@@ -321,8 +420,12 @@ func (in *Introspection) addTable(table sdata.DBTable, alias string) (err error)
 	if ftQS, err = in.addTableType(table, alias); err != nil {
 		return
 	}
-	in.addTypeTo("Query", ftQS)
-	in.addTypeTo("Subscription", ftQS)
+	reason := in.deprecated[table.Name]
+	if alias != "" {
+		reason = ""
+	}
+	in.addTypeTo("Query", ftQS, reason)
+	in.addTypeTo("Subscription", ftQS, reason)
 
 	var ftM FullType
 
@@ -330,7 +433,7 @@ func (in *Introspection) addTable(table sdata.DBTable, alias string) (err error)
 	if ftM, err = in.addInputType(table, ftQS); err != nil {
 		return
 	}
-	in.addTypeTo("Mutation", ftM)
+	in.addTypeTo("Mutation", ftM, reason)
 
 	// add tableByID type to query and subscription
 	var ftQSByID FullType
@@ -342,31 +445,94 @@ func (in *Introspection) addTable(table sdata.DBTable, alias string) (err error)
 	ftQSByID.Name += "ByID"
 	ftQSByID.addOrReplaceArg("id", newTypeRef(KIND_NONNULL, "", newTypeRef("", "ID", nil)))
 	in.addType(ftQSByID)
-	in.addTypeTo("Query", ftQSByID)
-	in.addTypeTo("Subscription", ftQSByID)
+	in.addTypeTo("Query", ftQSByID, reason)
+	in.addTypeTo("Subscription", ftQSByID, reason)
 
+	// add table_count and table_exists to query
+	if alias == "" {
+		in.addScalarField(table, ftQS, reason, "_count", TYPE_INT,
+			"Count the rows in table '%s'")
+		in.addScalarField(table, ftQS, reason, "_exists", TYPE_BOOLEAN,
+			"Check if any row exists in table '%s'")
+	}
 	return
 }
 
+// addScalarField adds a root like <table>_count that returns a scalar
+// computed from the rows matching the where argument
+func (in *Introspection) addScalarField(table sdata.DBTable, ft FullType, reason,
+	suffix, typ, desc string,
+) {
+	f := FieldObject{
+		Name:        in.getName(table.Name + suffix),
+		Description: fmt.Sprintf(desc, in.getTableName(table.Name)),
+		Args:        []InputValue{},
+		Type:        newTypeRef(KIND_NONNULL, "", newTypeRef("", typ, nil)),
+	}
+	for _, arg := range ft.InputFields {
+		if arg.Name == "where" {
+			f.Args = append(f.Args, arg)
+		}
+	}
+	f.IsDeprecated, f.DeprecationReason = deprecation(reason)
+
+	qt := in.types["Query"]
+	qt.Fields = append(qt.Fields, f)
+	in.types["Query"] = qt
+}
+
 // addTypeTo adds a type to the introspection schema
-func (in *Introspection) addTypeTo(op string, ft FullType) {
+func (in *Introspection) addTypeTo(op string, ft FullType, reason string) {
 	qt := in.types[op]
-	qt.Fields = append(qt.Fields, FieldObject{
+	f := FieldObject{
 		Name:        ft.Name,
 		Description: ft.Description,
 		Args:        ft.InputFields,
 		Type:        newTypeRef("", ft.Name, nil),
-	})
+	}
+	f.IsDeprecated, f.DeprecationReason = deprecation(reason)
+	qt.Fields = append(qt.Fields, f)
 	in.types[op] = qt
 }
 
+// deprecation returns the deprecation fields for a reason, an empty
+// reason means not deprecated
+func deprecation(reason string) (bool, *string) {
+	if reason == "" {
+		return false, nil
+	}
+	return true, &reason
+}
+
+// limitDescription returns the description of the limit argument of a table
+func (in *Introspection) limitDescription(table string) string {
+	if d, ok := in.limits[table]; ok {
+		return d
+	}
+	return in.limits[""]
+}
+
+// limitDefault returns the default limit of the table
+func (in *Introspection) limitDefault(table string) string {
+	if d, ok := in.limitDefaults[table]; ok {
+		return d
+	}
+	return in.limitDefaults[""]
+}
+
 // getName returns the name of the type
 func (in *Introspection) getName(name string) string {
-	if in.camelCase {
-		return util.ToCamel(name)
-	} else {
-		return name
-	}
+	return in.namer.Name(name)
+}
+
+// getTableName returns the name of the table
+func (in *Introspection) getTableName(name string) string {
+	return in.namer.Table(name)
+}
+
+// getColumnName returns the name of the column of a table
+func (in *Introspection) getColumnName(table, name string) string {
+	return in.namer.Column(table, name)
 }
 
 // addExpTypes adds the expression types to the introspection schema
@@ -407,11 +573,10 @@ func (in *Introspection) addTableTypeWithDepth(
 		Interfaces:  []TypeRef{},
 	}
 
-	name := table.Name
+	name := in.getTableName(table.Name)
 	if alias != "" {
-		name = alias
+		name = in.getName(alias)
 	}
-	name = in.getName(name)
 
 	ft.Name = name
 	ft.Description = table.Comment
@@ -480,28 +645,46 @@ func (in *Introspection) addTableTypeWithDepth(
 		}
 	}
 
-	ft.addArg("id", newTypeRef("", "ID", nil))
-	ft.addArg("limit", newTypeRef("", "Int", nil))
-	ft.addArg("offset", newTypeRef("", "Int", nil))
-	ft.addArg("distinctOn", newTypeRef("LIST", "", newTypeRef("", "String", nil)))
-	ft.addArg("first", newTypeRef("", "Int", nil))
-	ft.addArg("last", newTypeRef("", "Int", nil))
-	ft.addArg("after", newTypeRef("", "Cursor", nil))
-	ft.addArg("before", newTypeRef("", "Cursor", nil))
+	ft.addArgWithDesc("id", "Fetch a single row by its primary key", newTypeRef("", "ID", nil))
+	ft.addArgWithDefault("limit", in.limitDescription(table.Name),
+		in.limitDefault(table.Name), newTypeRef("", "Int", nil))
+	ft.addArgWithDefault("offset", "Number of rows to skip", "0", newTypeRef("", "Int", nil))
+	ft.addArgWithDesc("distinctOn", "Return only one row for each distinct value of these columns",
+		newTypeRef("LIST", "", newTypeRef("", "String", nil)))
+	ft.addArgWithDesc("first", "Number of rows to return from the start, enables cursor pagination",
+		newTypeRef("", "Int", nil))
+	ft.addArgWithDesc("last", "Number of rows to return from the end, enables cursor pagination",
+		newTypeRef("", "Int", nil))
+	ft.addArgWithDesc("after", "Return rows after this cursor, use the cursor from the previous page",
+		newTypeRef("", "Cursor", nil))
+	ft.addArgWithDesc("before", "Return rows before this cursor, use the cursor from the previous page",
+		newTypeRef("", "Cursor", nil))
+
+	ft.addArgWithDesc("lock", "Lock the selected rows (FOR UPDATE / FOR SHARE), must be allowed for the role",
+		newTypeRef("", "LockInput", nil))
+
+	if table.PartitionKey != "" {
+		ft.addArgWithDesc("partition", "Only query the partitions in this range of "+table.PartitionKey,
+			newTypeRef("", "PartitionInput", nil))
+	}
 
 	in.addOrderByType(table, &ft)
 	in.addWhereType(table, &ft)
 	in.addTableArgsType(table, &ft)
 
 	if hasSearch {
-		ft.addArg("search", newTypeRef("", "String", nil))
+		ft.addArgWithDesc("search", "Full-text search, rows are ranked by relevance",
+			newTypeRef("", "String", nil))
 	}
 
 	if depth > 1 {
 		return
 	}
 	if depth > 0 {
-		ft.addArg("find", newTypeRef("", "FindSearchInput", nil))
+		ft.addArgWithDesc("find", "Walk the recursive relationship to find parents or children",
+			newTypeRef("", "FindSearchInput", nil))
+		ft.addArgWithDesc("max_depth", "Maximum number of levels to walk with find",
+			newTypeRef("", "Int", nil))
 	}
 
 	in.addType(ft)
@@ -516,7 +699,7 @@ func (in *Introspection) addTableTypeWithDepth(
 
 // addColumnsEnumType adds an enum type for the columns of the table
 func (in *Introspection) addColumnsEnumType(t sdata.DBTable) (err error) {
-	tableName := in.getName(t.Name)
+	tableName := in.getTableName(t.Name)
 	ft := FullType{
 		Kind:        KIND_ENUM,
 		Name:        (t.Name + "Columns" + SUFFIX_ENUM),
@@ -526,10 +709,12 @@ func (in *Introspection) addColumnsEnumType(t sdata.DBTable) (err error) {
 		if c.Blocked {
 			continue
 		}
-		ft.EnumValues = append(ft.EnumValues, EnumValue{
-			Name:        in.getName(c.Name),
+		ev := EnumValue{
+			Name:        in.getColumnName(c.Table, c.Name),
 			Description: c.Comment,
-		})
+		}
+		ev.IsDeprecated, ev.DeprecationReason = deprecation(in.deprecated[c.Table+"."+c.Name])
+		ft.EnumValues = append(ft.EnumValues, ev)
 	}
 	in.addType(ft)
 	return
@@ -537,10 +722,12 @@ func (in *Introspection) addColumnsEnumType(t sdata.DBTable) (err error) {
 
 // addToTablesEnum accumulates a table into the tables enum (called per-database).
 func (in *Introspection) addToTablesEnum(t sdata.DBTable) {
-	in.enumValues[in.getName(t.Name)] = EnumValue{
-		Name:        in.getName(t.Name),
+	ev := EnumValue{
+		Name:        in.getTableName(t.Name),
 		Description: t.Comment,
 	}
+	ev.IsDeprecated, ev.DeprecationReason = deprecation(in.deprecated[t.Name])
+	in.enumValues[ev.Name] = ev
 }
 
 // finalizeTablesEnum writes the accumulated tables enum type.
@@ -598,13 +785,38 @@ func (in *Introspection) addOrderByType(t sdata.DBTable, ft *FullType) {
 			continue
 		}
 		ty.InputFields = append(ty.InputFields, InputValue{
-			Name:        in.getName(c.Name),
+			Name:        in.getColumnName(c.Table, c.Name),
 			Description: c.Comment,
 			Type:        newTypeRef("", "OrderDirection", nil),
 		})
 	}
 	in.addType(ty)
-	ft.addArg("orderBy", newTypeRef("", (t.Name+SUFFIX_ORDER_BY), nil))
+	ft.addArgWithDesc("orderBy", "Sort the rows by these columns",
+		newTypeRef("", (t.Name+SUFFIX_ORDER_BY), nil))
+
+	// values accepted by orderBy: $var, the presets of the table or when
+	// there are none a key for each column and direction
+	kt := FullType{
+		Kind:        KIND_ENUM,
+		Name:        (t.Name + SUFFIX_ORDER_KEY),
+		Description: "Values for an orderBy variable",
+	}
+	keys, ok := in.orderPresets[t.Schema+t.Name]
+	if !ok {
+		for _, c := range t.Columns {
+			if c.Blocked {
+				continue
+			}
+			name := in.getColumnName(c.Table, c.Name)
+			keys = append(keys, name+"_asc", name+"_desc")
+		}
+	}
+	for _, k := range keys {
+		kt.EnumValues = append(kt.EnumValues, EnumValue{Name: k})
+	}
+	if len(kt.EnumValues) != 0 {
+		in.addType(kt)
+	}
 }
 
 // addWhereType adds a where type to the introspection schema
@@ -626,20 +838,23 @@ func (in *Introspection) addWhereType(table sdata.DBTable, ft *FullType) {
 		if c.Blocked {
 			continue
 		}
-		ft := getTypeFromColumn(c)
+		ft := in.getColumnType(c)
+		if ft == TYPE_STRING && c.IsTimestamp() {
+			ft = TYPE_TIMESTAMP
+		}
 		if c.Array {
 			ft += SUFFIX_LISTEXP
 		} else {
 			ft += SUFFIX_EXP
 		}
 		ty.InputFields = append(ty.InputFields, InputValue{
-			Name:        in.getName(c.Name),
+			Name:        in.getColumnName(c.Table, c.Name),
 			Description: c.Comment,
 			Type:        newTypeRef("", ft, nil),
 		})
 	}
 	in.addType(ty)
-	ft.addArg("where", newTypeRef("", ty.Name, nil))
+	ft.addArgWithDesc("where", "Filter the rows", newTypeRef("", ty.Name, nil))
 }
 
 func (in *Introspection) addInputType(table sdata.DBTable, ft FullType) (retFT FullType, err error) {
@@ -650,18 +865,19 @@ func (in *Introspection) addInputType(table sdata.DBTable, ft FullType) (retFT F
 		InputFields: []InputValue{},
 	}
 	for _, c := range table.Columns {
-		if c.Blocked {
+		if c.Blocked || c.Generated {
 			continue
 		}
-		ft1 := getTypeFromColumn(c)
+		ft1 := in.getColumnType(c)
 		ty.InputFields = append(ty.InputFields, InputValue{
-			Name:        in.getName(c.Name),
+			Name:        in.getColumnName(c.Table, c.Name),
 			Description: c.Comment,
 			Type:        newTypeRef("", ft1, nil),
 		})
 	}
 	in.addType(ty)
-	ft.addArg("upsert", newTypeRef("", ty.Name, nil))
+	ft.addArgWithDesc("upsert", "Insert a row or update it if it already exists",
+		newTypeRef("", ty.Name, nil))
 
 	// insert
 	relNodes1, err := in.schema.GetFirstDegree(table)
@@ -684,13 +900,13 @@ func (in *Introspection) addInputType(table sdata.DBTable, ft FullType) (retFT F
 			continue
 		}
 		ty.InputFields = append(ty.InputFields, InputValue{
-			Name:        in.getName(t1.Name),
+			Name:        in.getTableName(t1.Name),
 			Description: t1.Comment,
 			Type:        newTypeRef("", ("insert" + t1.Name + SUFFIX_INPUT), nil),
 		})
 	}
 	in.addType(ty)
-	ft.addArg("insert", newTypeRef("", ty.Name, nil))
+	ft.addArgWithDesc("insert", "Insert one or more rows", newTypeRef("", ty.Name, nil))
 
 	// update
 	ty.Name = ("update" + table.Name + SUFFIX_INPUT)
@@ -703,35 +919,37 @@ func (in *Introspection) addInputType(table sdata.DBTable, ft FullType) (retFT F
 			continue
 		}
 		ty.InputFields[(fieldLen + i)] = InputValue{
-			Name:        in.getName(t1.Name),
+			Name:        in.getTableName(t1.Name),
 			Description: t1.Comment,
 			Type:        newTypeRef("", ("update" + t1.Name + SUFFIX_INPUT), nil),
 		}
 		i++
 	}
-	description1 := fmt.Sprintf("Connect to rows in table '%s' that match the expression", in.getName(table.Name))
+	description1 := fmt.Sprintf("Connect to rows in table '%s' that match the expression", in.getTableName(table.Name))
 	ty.InputFields = append(ty.InputFields, InputValue{
 		Name:        "connect",
 		Description: description1,
 		Type:        newTypeRef("", (table.Name + SUFFIX_WHERE), nil),
 	})
-	description2 := fmt.Sprintf("Disconnect from rows in table '%s' that match the expression", in.getName(table.Name))
+	description2 := fmt.Sprintf("Disconnect from rows in table '%s' that match the expression", in.getTableName(table.Name))
 	ty.InputFields = append(ty.InputFields, InputValue{
 		Name:        "disconnect",
 		Description: description2,
 		Type:        newTypeRef("", (table.Name + SUFFIX_WHERE), nil),
 	})
-	desciption3 := fmt.Sprintf("Update rows in table '%s' that match the expression", in.getName(table.Name))
+	desciption3 := fmt.Sprintf("Update rows in table '%s' that match the expression", in.getTableName(table.Name))
 	ty.InputFields = append(ty.InputFields, InputValue{
 		Name:        "where",
 		Description: desciption3,
 		Type:        newTypeRef("", (table.Name + SUFFIX_WHERE), nil),
 	})
 	in.addType(ty)
-	ft.addArg("update", newTypeRef("", ty.Name, nil))
+	ft.addArgWithDesc("update", "Update the rows matched by id or where",
+		newTypeRef("", ty.Name, nil))
 
 	// delete
-	ft.addArg("delete", newTypeRef("", TYPE_BOOLEAN, nil))
+	ft.addArgWithDesc("delete", "Delete the rows matched by id or where",
+		newTypeRef("", TYPE_BOOLEAN, nil))
 	retFT = ft
 	return
 }
@@ -743,7 +961,7 @@ func (in *Introspection) addTableArgsType(table sdata.DBTable, ft *FullType) {
 	}
 	ty := in.addArgsType(table, table.Func)
 	in.addType(ty)
-	ft.addArg("args", newTypeRef("", ty.Name, nil))
+	ft.addArgWithDesc("args", "Arguments of the table function", newTypeRef("", ty.Name, nil))
 }
 
 // addArgsType adds the arguments type to the introspection schema
@@ -782,10 +1000,10 @@ func (in *Introspection) addArgsType(table sdata.DBTable, fn sdata.DBFunction) (
 // getColumnField returns the field object for the given column
 func (in *Introspection) getColumnField(column sdata.DBColumn) (field FieldObject, err error) {
 	field.Args = []InputValue{}
-	field.Name = in.getName(column.Name)
+	field.Name = in.getColumnName(column.Table, column.Name)
 	typeValue := newTypeRef("", "String", nil)
 
-	if v, ok := in.types[getTypeFromColumn(column)]; ok {
+	if v, ok := in.types[in.getColumnType(column)]; ok {
 		typeValue.Name = &v.Name
 		typeValue.Kind = v.Kind
 	}
@@ -799,6 +1017,8 @@ func (in *Introspection) getColumnField(column sdata.DBColumn) (field FieldObjec
 	}
 
 	field.Type = typeValue
+	field.IsDeprecated, field.DeprecationReason = deprecation(
+		in.deprecated[column.Table+"."+column.Name])
 
 	field.Args = append(field.Args, InputValue{
 		Name: "includeIf", Type: newTypeRef("", (column.Table + SUFFIX_WHERE), nil),
@@ -842,14 +1062,16 @@ func (in *Introspection) getTableField(relNode sdata.RelNode) (
 	f FieldObject, skip bool, err error,
 ) {
 	f.Args = []InputValue{}
-	f.Name = in.getName(relNode.Name)
+	f.Name = in.getTableName(relNode.Name)
 
-	tn := in.getName(relNode.Table.Name)
+	tn := in.getTableName(relNode.Table.Name)
 	if _, ok := in.types[tn]; !ok && relNode.Type != sdata.RelRecursive {
 		skip = true
 		return
 	}
 
+	f.IsDeprecated, f.DeprecationReason = deprecation(in.deprecated[relNode.Table.Name])
+
 	switch relNode.Type {
 	case sdata.RelOneToOne:
 		f.Type = newTypeRef(KIND_LIST, "", newTypeRef("", tn, nil))
@@ -946,6 +1168,26 @@ func (ft *FullType) addArg(name string, tr *TypeRef) {
 	})
 }
 
+// addArgWithDesc adds an argument with a description to the full type
+func (ft *FullType) addArgWithDesc(name, desc string, tr *TypeRef) {
+	ft.InputFields = append(ft.InputFields, InputValue{
+		Name:        name,
+		Description: desc,
+		Type:        tr,
+	})
+}
+
+// addArgWithDefault adds an argument with a description and default value
+// to the full type
+func (ft *FullType) addArgWithDefault(name, desc, def string, tr *TypeRef) {
+	ft.InputFields = append(ft.InputFields, InputValue{
+		Name:         name,
+		Description:  desc,
+		Type:         tr,
+		DefaultValue: &def,
+	})
+}
+
 // addOrReplaceArg adds or replaces an argument to the full type
 func (ft *FullType) addOrReplaceArg(name string, tr *TypeRef) {
 	for i, a := range ft.InputFields {
@@ -973,6 +1215,22 @@ func newTypeRef(kind, name string, tr *TypeRef) *TypeRef {
 }
 
 // Returns the type of the given column. Returns ID if column is the primary key
+// getColumnType returns the GraphQL type of the column, decimal and 64-bit
+// integer columns are typed as Decimal and BigInt when they are returned
+// as strings
+func (in *Introspection) getColumnType(col sdata.DBColumn) string {
+	switch {
+	case col.PrimaryKey:
+	case in.decimal && col.IsDecimal():
+		return TYPE_DECIMAL
+	case col.IsBigInt() && (in.bigint || col.AsString):
+		return TYPE_BIGINT
+	case col.AsString && !col.Array:
+		return TYPE_STRING
+	}
+	return getTypeFromColumn(col)
+}
+
 func getTypeFromColumn(col sdata.DBColumn) (gqlType string) {
 	if col.PrimaryKey {
 		gqlType = "ID"
@@ -995,8 +1253,89 @@ func getType(t string) (gqlType string, list bool) {
 		gqlType = v
 	} else if t == "json" || t == "jsonb" {
 		gqlType = "JSON"
+	} else if strings.HasPrefix(t, "interval") {
+		gqlType = TYPE_DURATION
 	} else {
 		gqlType = "String"
 	}
 	return
 }
+
+// limitDescriptions returns the description of the limit argument keyed by
+// table, with the default and max limits of the table and its roles. The
+// description for tables without limits in the config is keyed by ""
+func (c *Config) limitDescriptions() map[string]string {
+	def := c.DefaultLimit
+	if def == 0 {
+		def = 20
+	}
+
+	dm := map[string]string{"": limitDescription(def, 0, nil)}
+
+	rm := make(map[string][]string)
+	for _, r := range c.Roles {
+		for _, rt := range r.Tables {
+			if rt.Query == nil || (rt.Query.Limit == 0 && rt.Query.MaxLimit == 0) {
+				continue
+			}
+			rm[rt.Name] = append(rm[rt.Name], fmt.Sprintf("role %s: %s",
+				r.Name, limitValues(rt.Query.Limit, rt.Query.MaxLimit)))
+		}
+	}
+
+	for _, t := range c.Tables {
+		if t.DefaultLimit != 0 || t.MaxLimit != 0 {
+			tdef := def
+			if t.DefaultLimit != 0 {
+				tdef = t.DefaultLimit
+			}
+			dm[t.Name] = limitDescription(tdef, t.MaxLimit, rm[t.Name])
+		}
+	}
+	for name, roles := range rm {
+		if _, ok := dm[name]; ok {
+			continue
+		}
+		dm[name] = limitDescription(def, 0, roles)
+	}
+	return dm
+}
+
+// limitDefaults returns the default limit keyed by table. The default for
+// tables without one in the config is keyed by ""
+func (c *Config) limitDefaults() map[string]string {
+	def := c.DefaultLimit
+	if def == 0 {
+		def = 20
+	}
+
+	dm := map[string]string{"": strconv.Itoa(def)}
+	for _, t := range c.Tables {
+		if t.DefaultLimit != 0 {
+			dm[t.Name] = strconv.Itoa(t.DefaultLimit)
+		}
+	}
+	return dm
+}
+
+// limitDescription describes the default and max limits
+func limitDescription(def, max int, roles []string) string {
+	d := "Number of rows to return (" + limitValues(def, max)
+	if len(roles) != 0 {
+		sort.Strings(roles)
+		d += "; " + strings.Join(roles, "; ")
+	}
+	return d + ")"
+}
+
+// limitValues formats the default and max limits
+func limitValues(def, max int) string {
+	var v []string
+	if def != 0 {
+		v = append(v, fmt.Sprintf("default %d", def))
+	}
+	if max != 0 {
+		v = append(v, fmt.Sprintf("max %d", max))
+	}
+	return strings.Join(v, ", ")
+}
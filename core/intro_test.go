@@ -216,3 +216,77 @@ func TestIntrospectionIncludesBothOperatorFormats(t *testing.T) {
 		}
 	}
 }
+
+func TestIntrospectionArgumentDocsAndDirectives(t *testing.T) {
+	di := sdata.GetTestDBInfo()
+	schema, err := sdata.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &Config{
+		DBType:       "postgres",
+		DefaultLimit: 50,
+		Tables:       []Table{{Name: "products", DefaultLimit: 10}},
+	}
+
+	gj := &graphjinEngine{
+		conf:      conf,
+		roles:     make(map[string]*Role),
+		defaultDB: "default",
+		databases: map[string]*dbContext{
+			"default": {name: "default", schema: schema},
+		},
+	}
+
+	result, err := gj.introQuery()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var introResult IntroResult
+	if err := json.Unmarshal(result, &introResult); err != nil {
+		t.Fatal(err)
+	}
+
+	args := make(map[string]map[string]InputValue)
+	for _, typ := range introResult.Schema.Types {
+		if typ.Name != "users" && typ.Name != "products" {
+			continue
+		}
+		args[typ.Name] = make(map[string]InputValue)
+		for _, a := range typ.InputFields {
+			args[typ.Name][a.Name] = a
+		}
+	}
+
+	for _, tc := range []struct{ table, arg, def string }{
+		{"users", "limit", "50"},
+		{"products", "limit", "10"},
+		{"products", "offset", "0"},
+	} {
+		a, ok := args[tc.table][tc.arg]
+		if !ok {
+			t.Fatalf("%s: missing argument %s", tc.table, tc.arg)
+		}
+		if a.DefaultValue == nil || *a.DefaultValue != tc.def {
+			t.Errorf("%s.%s: expected default %s, got %v", tc.table, tc.arg, tc.def, a.DefaultValue)
+		}
+	}
+
+	for _, name := range []string{"after", "first", "where", "orderBy"} {
+		if args["products"][name].Description == "" {
+			t.Errorf("products.%s: missing description", name)
+		}
+	}
+
+	dirs := make(map[string]DirectiveType)
+	for _, d := range introResult.Schema.Directives {
+		dirs[d.Name] = d
+	}
+	for _, name := range []string{"object", "optional", "through", "skipReturning", "deprecated", "cacheControl"} {
+		if d, ok := dirs[name]; !ok || d.Description == "" {
+			t.Errorf("missing directive @%s", name)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// isLazyDatabase returns true when the schema discovery of the database
+// can be deferred until it is first used
+func (gj *graphjinEngine) isLazyDatabase(ctx *dbContext, fkTargets map[string]bool) bool {
+	if ctx.name == gj.defaultDB || ctx.db == nil || ctx.dbinfo != nil || fkTargets[ctx.name] {
+		return false
+	}
+	return gj.conf.Databases[ctx.name].Lazy
+}
+
+// fkTargetDatabases returns the databases referenced by cross database
+// foreign keys, they are needed to set up the tables pointing to them
+func fkTargetDatabases(conf *Config) map[string]bool {
+	m := make(map[string]bool)
+	for _, t := range conf.Tables {
+		for _, c := range t.Columns {
+			if c.ForeignKey == "" {
+				continue
+			}
+			if fk, ok := c.getFK(""); ok && fk.Database != "" {
+				m[fk.Database] = true
+			}
+		}
+	}
+	return m
+}
+
+// warmDatabase discovers the schema of a lazy database and creates its
+// compilers, it does nothing for databases that are already set up. A
+// failed discovery is tried again on the next use.
+func (gj *graphjinEngine) warmDatabase(ctx *dbContext) error {
+	if !ctx.lazy.Load() {
+		return nil
+	}
+
+	ctx.warm.Lock()
+	defer ctx.warm.Unlock()
+
+	if !ctx.lazy.Load() {
+		return nil
+	}
+
+	if err := gj.discoverDatabase(ctx); err != nil {
+		return err
+	}
+
+	// finalizing updates the table config shared by all databases, it is
+	// copied first since requests and the compilers of the other databases
+	// are reading it. The old config is put back when it fails
+	gj.warmMu.Lock()
+	defer gj.warmMu.Unlock()
+
+	tmap, tables := gj.tmap, gj.conf.Tables
+	gj.tmap = maps.Clone(tmap)
+	gj.conf.Tables = slices.Clone(tables)
+
+	if err := gj.finalizeDatabaseSchema(ctx); err != nil {
+		gj.tmap, gj.conf.Tables = tmap, tables
+		ctx.dbinfo = nil
+		return err
+	}
+
+	// the schema of the database is read by others once it is not lazy
+	ctx.lazy.Store(false)
+	return nil
+}
+
+// ready returns true when the schema of the database is set up. The schema
+// of a lazy database is written while it warms up so it's only read after
+func (ctx *dbContext) ready() bool {
+	return !ctx.lazy.Load() && ctx.schema != nil
+}
+
+// tables returns the table config. Lazy databases replace it with an updated
+// copy when they warm up, it is never changed in place
+func (gj *graphjinEngine) tables() []Table {
+	gj.warmMu.RLock()
+	defer gj.warmMu.RUnlock()
+	return gj.conf.Tables
+}
+
+// tableMap returns the table settings keyed by schema and table, it is
+// replaced the same way as the table config
+func (gj *graphjinEngine) tableMap() map[string]qcode.TConfig {
+	gj.warmMu.RLock()
+	defer gj.warmMu.RUnlock()
+	return gj.tmap
+}
+
+// WarmDatabases discovers the schema of the named lazy databases, or of all
+// of them when no names are given, so that the first requests to them do
+// not wait for it. The databases are discovered in parallel.
+func (g *GraphJin) WarmDatabases(names ...string) error {
+	gj, err := g.getEngine()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = gj.sortedDatabaseNames()
+	}
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		ctx, ok := gj.databases[name]
+		if !ok {
+			errs[i] = fmt.Errorf("database not found: %s", name)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = gj.warmDatabase(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
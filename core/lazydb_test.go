@@ -0,0 +1,175 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLazyDatabase(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+		return db
+	}
+
+	mainDB := openDB("main")
+	reportsDB := openDB("reports")
+
+	if _, err := mainDB.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Ada');`); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			"main":    {Type: "sqlite"},
+			"reports": {Type: "sqlite", Lazy: true},
+		},
+		Tables: []core.Table{
+			{Name: "users", Database: "main"},
+			{Name: "orders", Database: "reports"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, mainDB,
+		core.OptionSetDatabases(map[string]*sql.DB{"main": mainDB, "reports": reportsDB}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the table is created after startup, it is only found when the
+	// database is discovered on first use
+	if _, err := reportsDB.Exec(`
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER);
+		INSERT INTO orders (id, total) VALUES (1, 50);`); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), `query { orders { id total } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"orders":[{"id":1,"total":50}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	res, err = gj.GraphQL(context.Background(), `query { users { id name } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"users":[{"id":1,"name":"Ada"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	if err := gj.WarmDatabases(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gj.WarmDatabases("missing"); err == nil {
+		t.Fatal("expected an error for an unknown database")
+	}
+}
+
+func TestLazyDatabaseConcurrentWarm(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name, schema string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+		if _, err := db.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	mainDB := openDB("main", `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Ada');`)
+	reportsDB := openDB("reports", `
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER);
+		INSERT INTO orders (id, total) VALUES (1, 50);`)
+	archiveDB := openDB("archive", `
+		CREATE TABLE invoices (id INTEGER PRIMARY KEY, amount INTEGER);
+		INSERT INTO invoices (id, amount) VALUES (1, 70);`)
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			"main":    {Type: "sqlite"},
+			"reports": {Type: "sqlite", Lazy: true},
+			"archive": {Type: "sqlite", Lazy: true},
+		},
+		Tables: []core.Table{
+			{Name: "users", Database: "main"},
+			{Name: "orders", Database: "reports"},
+			{Name: "invoices", Database: "archive"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, mainDB,
+		core.OptionSetDatabases(map[string]*sql.DB{
+			"main": mainDB, "reports": reportsDB, "archive": archiveDB,
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]string{
+		`query { users { id name } }`:      `{"users":[{"id":1,"name":"Ada"}]}`,
+		`query { orders { id total } }`:    `{"orders":[{"id":1,"total":50}]}`,
+		`query { invoices { id amount } }`: `{"invoices":[{"id":1,"amount":70}]}`,
+	}
+
+	// requests to the warm and lazy databases, the table listing and the
+	// warm up of both lazy databases all run at the same time
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 4; i++ {
+		for q, exp := range queries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := gj.GraphQL(context.Background(), q, nil, nil)
+				if err == nil && string(res.Data) != exp {
+					err = fmt.Errorf("expected %s, got %s", exp, res.Data)
+				}
+				if err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			gj.GetTables()
+		}()
+		go func() {
+			defer wg.Done()
+			if err := gj.WarmDatabases(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
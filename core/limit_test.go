@@ -0,0 +1,107 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestTableAndRoleLimits(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c'), (4, 'd'), (5, 'e');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(maxLimitError bool) *core.GraphJin {
+		conf := &core.Config{
+			DBType:           "sqlite",
+			DisableAllowList: true,
+			MaxLimitError:    maxLimitError,
+			Tables:           []core.Table{{Name: "products", DefaultLimit: 2, MaxLimit: 3}},
+			Roles: []core.Role{{Name: "user", Tables: []core.RoleTable{
+				{Name: "products", Query: &core.Query{Limit: 1, MaxLimit: 4}},
+			}}},
+		}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+	gj := newGJ(false)
+
+	anon := context.Background()
+	user := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	tests := []struct {
+		name  string
+		c     context.Context
+		query string
+		vars  string
+		rows  int
+	}{
+		{"table default", anon, `query { products { id } }`, "", 2},
+		{"table max", anon, `query { products(limit: 10) { id } }`, "", 3},
+		{"table max with variable", anon, `query { products(limit: $n) { id } }`, `{"n": 10}`, 3},
+		{"under table max", anon, `query { products(limit: 1) { id } }`, "", 1},
+		{"role default", user, `query { products { id } }`, "", 1},
+		{"role max", user, `query { products(limit: 10) { id } }`, "", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vars json.RawMessage
+			if tt.vars != "" {
+				vars = json.RawMessage(tt.vars)
+			}
+			res, err := gj.GraphQL(tt.c, tt.query, vars, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var data struct{ Products []struct{ ID int } }
+			if err := json.Unmarshal(res.Data, &data); err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Products) != tt.rows {
+				t.Fatalf("expected %d rows: %s", tt.rows, res.Data)
+			}
+		})
+	}
+
+	res, err := gj.GraphQL(anon, `query IntrospectionQuery { __schema { types { name } } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := "Number of rows to return (default 2, max 3; role user: default 1, max 4)"
+	if !strings.Contains(string(res.Data), desc) {
+		t.Fatalf("expected the limit description in the introspection result")
+	}
+
+	gj = newGJ(true)
+	_, err = gj.GraphQL(anon, `query { products(limit: 10) { id } }`, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "over the max limit of 3") {
+		t.Fatalf("expected a max limit error: %v", err)
+	}
+
+	_, err = gj.GraphQL(anon, `query { products(limit: $n) { id } }`, json.RawMessage(`{"n": 10}`), nil)
+	if err == nil || !strings.Contains(err.Error(), "over the max limit of 3") {
+		t.Fatalf("expected a max limit error for the variable: %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/allow"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// Lint finding kinds
+const (
+	LintCompileError      = "compile_error"
+	LintUnknownColumn     = "unknown_column"
+	LintUnknownTable      = "unknown_table"
+	LintRoleError         = "role_error"
+	LintBlocked           = "blocked"
+	LintMissingIndex      = "missing_index"
+	LintUnboundedSelect   = "unbounded_select"
+	LintCrossDatabaseJoin = "cross_database_join"
+	LintWarning           = "warning"
+)
+
+// Lint finding severities
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+	LintSeverityInfo    = "info"
+)
+
+// LintFinding is an issue found in a saved query
+type LintFinding struct {
+	Query      string `json:"query"`
+	Namespace  string `json:"namespace,omitempty"`
+	Kind       string `json:"kind"`
+	Severity   string `json:"severity"`
+	Role       string `json:"role,omitempty"`
+	Database   string `json:"database,omitempty"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Lint checks every saved query in the allow list against the current
+// schema and role config. Queries that fail to compile for every role are
+// errors, columns used in filters without an index (primary key, unique,
+// full-text or @index in the schema file) and selects without a limit are
+// warnings, while role specific failures and cross-database joins are info.
+func (g *GraphJin) Lint() ([]LintFinding, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	if !gj.anyDatabaseReady() {
+		return nil, fmt.Errorf("schema not initialized")
+	}
+
+	items, err := gj.allowList.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+
+	var findings []LintFinding
+	for _, item := range items {
+		findings = append(findings, gj.lintQuery(item)...)
+	}
+	return findings, nil
+}
+
+// lintRoles returns the roles to compile queries for, user first since
+// saved queries are mostly written for signed in users
+func (gj *graphjinEngine) lintRoles() []string {
+	roles := make([]string, 0, len(gj.roles))
+	for k := range gj.roles {
+		if k != "user" {
+			roles = append(roles, k)
+		}
+	}
+	sort.Strings(roles)
+	return append([]string{"user"}, roles...)
+}
+
+func (gj *graphjinEngine) lintQuery(item allow.Item) []LintFinding {
+	var findings []LintFinding
+	var qcs []*qcode.QCode
+	var roleErrs []LintFinding
+
+	newFinding := func(kind, severity, msg string) LintFinding {
+		return LintFinding{
+			Query:     item.Name,
+			Namespace: item.Namespace,
+			Kind:      kind,
+			Severity:  severity,
+			Message:   msg,
+		}
+	}
+
+	for _, role := range gj.lintRoles() {
+		rqcs, err := gj.lintCompile(item, role)
+		if err != nil {
+			f := newFinding(LintRoleError, LintSeverityInfo, err.Error())
+			f.Role = role
+			roleErrs = append(roleErrs, f)
+			continue
+		}
+
+		for _, qc := range rqcs {
+			for _, sel := range qc.Selects {
+				if sel.SkipRender == qcode.SkipTypeBlocked {
+					f := newFinding(LintBlocked, LintSeverityInfo,
+						fmt.Sprintf("%s is blocked for role %s", sel.FieldName, role))
+					f.Role, f.Table = role, sel.Table
+					findings = append(findings, f)
+				}
+			}
+		}
+
+		// the first role that compiles is used for the schema checks
+		if qcs == nil {
+			qcs = rqcs
+		}
+	}
+
+	if qcs == nil {
+		// the error of the first role (user) is reported
+		f := roleErrs[0]
+		f.Role = ""
+		f.Severity = LintSeverityError
+		f.Kind = lintErrorKind(f.Message)
+		return []LintFinding{f}
+	}
+	findings = append(roleErrs, findings...)
+
+	indexed := make(map[string]struct{})
+	for _, qc := range qcs {
+		for _, w := range qc.Warnings {
+			findings = append(findings, newFinding(LintWarning, LintSeverityWarning, w))
+		}
+
+		for _, sel := range qc.Selects {
+			if sel.SkipRender == qcode.SkipTypeDatabaseJoin {
+				f := newFinding(LintCrossDatabaseJoin, LintSeverityInfo,
+					fmt.Sprintf("%s is joined from database %s", sel.FieldName, sel.Database))
+				f.Database, f.Table = sel.Database, sel.Table
+				findings = append(findings, f)
+				continue
+			}
+			if sel.SkipRender != qcode.SkipTypeNone {
+				continue
+			}
+
+			if qc.Type == qcode.QTQuery && !sel.Singular &&
+				sel.Paging.Implicit && sel.Paging.LimitVar == "" {
+				f := newFinding(LintUnboundedSelect, LintSeverityWarning,
+					fmt.Sprintf("%s has no limit, the default limit of %d rows is used",
+						sel.FieldName, sel.Paging.Limit))
+				f.Database, f.Table = sel.Database, sel.Table
+				f.Suggestion = fmt.Sprintf("add a limit: %s(limit: %d)", sel.FieldName, sel.Paging.Limit)
+				findings = append(findings, f)
+			}
+
+			for _, col := range lintFilterColumns(sel.Where.Exp, sel.Ti.Name) {
+				k := sel.Ti.Name + "." + col
+				if _, ok := indexed[k]; ok {
+					continue
+				}
+				indexed[k] = struct{}{}
+
+				f := newFinding(LintMissingIndex, LintSeverityWarning,
+					fmt.Sprintf("%s filters on %s which has no index", sel.FieldName, k))
+				f.Database, f.Table, f.Column = sel.Database, sel.Ti.Name, col
+				f.Suggestion = lintIndexSuggestion(&sel, col)
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// lintCompile compiles a saved query for a role, queries across databases
+// are compiled once per database
+func (gj *graphjinEngine) lintCompile(item allow.Item, role string) ([]*qcode.QCode, error) {
+	var r GraphqlReq
+	r.Set(item)
+
+	s, err := newGState(context.Background(), gj, r)
+	if err != nil {
+		return nil, err
+	}
+	s.role = role
+
+	if err := s.compileQueryForRole(); err != nil {
+		return nil, err
+	}
+
+	if !s.multiDB {
+		return []*qcode.QCode{s.cs.st.qc}, nil
+	}
+
+	var qcs []*qcode.QCode
+	for dbName, rootFields := range s.dbGroups {
+		dbCtx, ok := gj.GetDatabase(dbName)
+		if !ok {
+			return nil, fmt.Errorf("database not found: %s", dbName)
+		}
+		q, err := s.buildDatabaseQuery(rootFields)
+		if err != nil {
+			return nil, err
+		}
+		qc, err := dbCtx.qcodeCompiler.Compile(q, r.aschema, role, r.namespace)
+		if err != nil {
+			return nil, err
+		}
+		qcs = append(qcs, qc)
+	}
+	return qcs, nil
+}
+
+// lintFilterColumns returns the columns of the table used in a filter
+// that are not indexed
+func lintFilterColumns(exp *qcode.Exp, table string) []string {
+	if exp == nil {
+		return nil
+	}
+
+	var cols []string
+	if c := exp.Left.Col; c.Name != "" && c.Table == table && len(exp.Joins) == 0 &&
+		!c.PrimaryKey && !c.UniqueKey && !c.Index && !c.FullText {
+		cols = append(cols, c.Name)
+	}
+	for _, child := range exp.Children {
+		cols = append(cols, lintFilterColumns(child, table)...)
+	}
+	return cols
+}
+
+// lintIndexSuggestion suggests adding an index for the column or
+// filtering on one of the indexed columns of the table instead
+func lintIndexSuggestion(sel *qcode.Select, col string) string {
+	var indexed []string
+	for _, c := range sel.Ti.Columns {
+		if c.PrimaryKey || c.UniqueKey || c.Index || c.FullText {
+			indexed = append(indexed, c.Name)
+		}
+	}
+
+	s := fmt.Sprintf("add an index: CREATE INDEX %s_%s_idx ON %s (%s), and mark the column with @index in the db schema file",
+		sel.Ti.Name, col, sel.Ti.Name, col)
+	if len(indexed) != 0 {
+		s += fmt.Sprintf("; or change the filter to an indexed column: %s", strings.Join(indexed, ", "))
+	}
+	return s
+}
+
+func lintErrorKind(msg string) string {
+	switch {
+	case strings.Contains(msg, "not a column"),
+		strings.Contains(msg, "column") && strings.Contains(msg, "not found"):
+		return LintUnknownColumn
+	case strings.Contains(msg, "table") && strings.Contains(msg, "not found"):
+		return LintUnknownTable
+	}
+	return LintCompileError
+}
@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queries := map[string]string{
+		"getProduct":     `query getProduct { products(id: $id) { id name } }`,
+		"cheapProducts":  `query cheapProducts { products(where: { price: { lt: 10 } }) { id name } }`,
+		"productWeights": `query productWeights { products(limit: 5) { id weight } }`,
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "queries"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, q := range queries {
+		if err := os.WriteFile(filepath.Join(dir, "queries", name+".gql"), []byte(q), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := gj.Lint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]core.LintFinding)
+	for _, f := range findings {
+		got[f.Query] = append(got[f.Query], f)
+	}
+
+	if len(got["getProduct"]) != 0 {
+		t.Fatalf("expected no findings for getProduct: %+v", got["getProduct"])
+	}
+
+	var index, unbounded bool
+	for _, f := range got["cheapProducts"] {
+		switch f.Kind {
+		case core.LintMissingIndex:
+			index = f.Column == "price" && f.Table == "products" &&
+				strings.Contains(f.Suggestion, "indexed column: id")
+		case core.LintUnboundedSelect:
+			unbounded = f.Severity == core.LintSeverityWarning &&
+				f.Suggestion == "add a limit: products(limit: 20)"
+		}
+	}
+	if !index || !unbounded {
+		t.Fatalf("expected missing index and unbounded select findings: %+v", got["cheapProducts"])
+	}
+
+	if f := got["productWeights"]; len(f) != 1 ||
+		f[0].Kind != core.LintUnknownColumn || f[0].Severity != core.LintSeverityError {
+		t.Fatalf("expected an unknown column error: %+v", f)
+	}
+}
@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestManyToManyConnectDisconnect(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE categories (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE product_categories (
+			product_id INTEGER REFERENCES products(id),
+			category_id INTEGER REFERENCES categories(id));
+		INSERT INTO categories (id, name) VALUES (1, 'Lighting'), (2, 'Office'), (3, 'Garden');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	gql := `mutation {
+		products(insert: $data) { id }
+	}`
+	vars := `{ "data": {
+		"id": 1,
+		"name": "Lamp",
+		"categories": { "connect": { "id": [1, 2] } }
+	} }`
+	if _, err := gj.GraphQL(ctx, gql, []byte(vars), nil); err != nil {
+		t.Fatal(err)
+	}
+	assertProductCategories(t, db, "1,2")
+
+	gql = `mutation {
+		products(where: { id: 1 }, update: $data) {
+			id
+			categories(order_by: { id: asc }) { id }
+		}
+	}`
+	vars = `{ "data": {
+		"categories": {
+			"connect": { "id": 3 },
+			"disconnect": { "id": 1 }
+		}
+	} }`
+	res, err := gj.GraphQL(ctx, gql, []byte(vars), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"products":[{"id":1,"categories":[{"id":2},{"id":3}]}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+	assertProductCategories(t, db, "2,3")
+}
+
+func assertProductCategories(t *testing.T, db *sql.DB, exp string) {
+	t.Helper()
+
+	var ids string
+	err := db.QueryRow(`SELECT group_concat(category_id) FROM
+		(SELECT category_id FROM product_categories
+		WHERE product_id = 1 ORDER BY category_id)`).Scan(&ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids != exp {
+		t.Fatalf("expected categories %s, got %s", exp, ids)
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import "time"
+
+// Names of the internal caches reported to Metrics.CacheLookup
+const (
+	// CacheAPQ is the cache of automatic persisted queries
+	CacheAPQ = "apq"
+	// CacheQuery is the cache of compiled queries, only used in production
+	CacheQuery = "query"
+	// CacheResponse is the response cache set with OptionSetResponseCache
+	CacheResponse = "response"
+	// CachePlan is the cache of persisted query plans, see EnablePlanCache
+	CachePlan = "plan"
+)
+
+// Metrics receives the measurements of the requests run by GraphJin so
+// applications embedding it can record them in their own metrics system.
+// The methods are called on the request path and should not block
+type Metrics interface {
+	// Query is called once a request has run
+	Query(m QueryMetrics)
+
+	// CacheLookup is called on every lookup of an internal cache
+	CacheLookup(cache string, hit bool)
+}
+
+// QueryMetrics holds the measurements of a request
+type QueryMetrics struct {
+	// Name of the GraphQL operation, empty for anonymous queries
+	Name      string
+	Operation OpType
+	Role      string
+	// Databases the request ran on, comma separated when there are more than one
+	Database string
+
+	// Time taken to compile the query to SQL
+	CompileTime time.Duration
+	// Time taken to run the request excluding the compile time
+	ExecuteTime time.Duration
+	// Rows returned by the root fields
+	Rows int
+	// The response came from the response cache
+	CacheHit bool
+	Err      error
+}
+
+// OptionSetMetrics sets the metrics implementation that receives the
+// measurements of every request
+func OptionSetMetrics(m Metrics) Option {
+	return func(s *graphjinEngine) error {
+		s.metrics = m
+		return nil
+	}
+}
+
+// cacheLookup reports a lookup of an internal cache
+func (gj *graphjinEngine) cacheLookup(cache string, hit bool) {
+	if gj.metrics != nil {
+		gj.metrics.CacheLookup(cache, hit)
+	}
+}
+
+// recordMetrics reports the measurements of the request started at start
+func (s *gstate) recordMetrics(start time.Time, err error) {
+	if s.gj.metrics == nil {
+		return
+	}
+	res := Result{operation: s.r.operation}
+
+	execTime := time.Since(start) - s.compileTime
+	if execTime < 0 {
+		execTime = 0
+	}
+
+	var rows int
+	if len(s.data) != 0 {
+		rows = countRows(s.data, true)
+	}
+
+	s.gj.metrics.Query(QueryMetrics{
+		Name:        s.r.name,
+		Operation:   res.Operation(),
+		Role:        s.role,
+		Database:    s.databaseNames(),
+		CompileTime: s.compileTime,
+		ExecuteTime: execTime,
+		Rows:        rows,
+		CacheHit:    s.cacheHit,
+		Err:         err,
+	})
+}
+
+// timeCompile adds the time taken by fn to the compile time of the request
+func (s *gstate) timeCompile(fn func() error) error {
+	if s.gj.metrics == nil {
+		return fn()
+	}
+	start := time.Now()
+	err := fn()
+	s.compileTime += time.Since(start)
+	return err
+}
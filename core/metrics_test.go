@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testMetrics struct {
+	mu      sync.Mutex
+	queries []core.QueryMetrics
+	lookups map[string][]bool
+}
+
+func (m *testMetrics) Query(qm core.QueryMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries = append(m.queries, qm)
+}
+
+func (m *testMetrics) CacheLookup(cache string, hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups[cache] = append(m.lookups[cache], hit)
+}
+
+func TestMetrics(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'Lamp'), (2, 'Desk'), (3, 'Sofa');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &testMetrics{lookups: make(map[string][]bool)}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// leave out the queries run by the startup checks
+	m.queries = nil
+
+	gql := `query getProducts { products { id name } }`
+	rc := &core.RequestConfig{APQKey: "getProducts"}
+
+	if _, err := gj.GraphQL(context.Background(), gql, nil, rc); err != nil {
+		t.Fatal(err)
+	}
+	// the query comes from the apq cache
+	if _, err := gj.GraphQL(context.Background(), "", nil, rc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(m.queries) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(m.queries))
+	}
+	for _, qm := range m.queries {
+		if qm.Name != "getProducts" || qm.Operation != core.OpQuery || qm.Role != "anon" {
+			t.Errorf("unexpected query metrics: %+v", qm)
+		}
+		if qm.Database == "" || qm.Rows != 3 || qm.Err != nil {
+			t.Errorf("unexpected query metrics: %+v", qm)
+		}
+		if qm.CompileTime <= 0 {
+			t.Errorf("expected the compile time to be measured: %+v", qm)
+		}
+	}
+
+	if apq := m.lookups[core.CacheAPQ]; len(apq) != 2 || apq[0] || !apq[1] {
+		t.Errorf("expected an apq cache miss then a hit, got %v", apq)
+	}
+
+	// errors are reported with the metrics
+	if _, err := gj.GraphQL(context.Background(), `query { products { nope } }`, nil, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if qm := m.queries[len(m.queries)-1]; qm.Err == nil {
+		t.Errorf("expected the error in the metrics: %+v", qm)
+	}
+}
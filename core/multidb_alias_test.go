@@ -0,0 +1,80 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMultiDBAliasedRoots(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+		_, err = db.Exec(`
+			CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+			INSERT INTO users (id, name) VALUES (1, '` + name + `');
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	mainDB := openDB("main")
+	archiveDB := openDB("archive")
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			"main":    {Type: "sqlite"},
+			"archive": {Type: "sqlite"},
+		},
+		Tables: []core.Table{{Name: "users", Database: "main"}},
+	}
+	gj, err := core.NewGraphJin(conf, mainDB,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"main": mainDB, "archive": archiveDB}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		current: users { name }
+		old: users @database(name: "archive") { name }
+	}`
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data struct {
+		Current []struct{ Name string }
+		Old     []struct{ Name string }
+	}
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Current) != 1 || data.Current[0].Name != "main" {
+		t.Errorf("expected current from the main database, got %s", res.Data)
+	}
+	if len(data.Old) != 1 || data.Old[0].Name != "archive" {
+		t.Errorf("expected old from the archive database, got %s", res.Data)
+	}
+
+	gql = `query { users { name } old: users @database(name: "missing") { name } }`
+	if res, err := gj.GraphQL(context.Background(), gql, nil, nil); err == nil {
+		t.Fatalf("expected an error for an unknown database, got %s", res.Data)
+	}
+}
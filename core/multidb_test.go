@@ -18,51 +18,53 @@ func TestCacheKeyIncludesDatabase(t *testing.T) {
 	tests := []struct {
 		name      string
 		namespace string
-		qname     string
+		query     string
 		role      string
 		database  string
-		wantKey   string
+		wantDB    string
 	}{
 		{
 			name:      "empty database (backward compatible)",
 			namespace: "ns1",
-			qname:     "getUsers",
+			query:     "query getUsers { users { id } }",
 			role:      "user",
 			database:  "",
-			wantKey:   "ns1getUsersuser",
+			wantDB:    "",
 		},
 		{
 			name:      "with database",
 			namespace: "ns1",
-			qname:     "getUsers",
+			query:     "query getUsers { users { id } }",
 			role:      "user",
 			database:  "main",
-			wantKey:   "ns1getUsersusermain",
+			wantDB:    "main",
 		},
 		{
 			name:      "different database same query",
 			namespace: "ns1",
-			qname:     "getUsers",
+			query:     "query getUsers { users { id } }",
 			role:      "user",
 			database:  "analytics",
-			wantKey:   "ns1getUsersuseranalytics",
+			wantDB:    "analytics",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := gstate{
+				gj: &graphjinEngine{},
 				r: GraphqlReq{
 					namespace: tt.namespace,
-					name:      tt.qname,
+					query:     []byte(tt.query),
 				},
 				role:     tt.role,
 				database: tt.database,
 			}
 
 			got := s.key()
-			if got != tt.wantKey {
-				t.Errorf("key() = %q, want %q", got, tt.wantKey)
+			want := tt.namespace + s.queryHash() + tt.role + tt.wantDB
+			if got != want {
+				t.Errorf("key() = %q, want %q", got, want)
 			}
 		})
 	}
@@ -71,14 +73,19 @@ func TestCacheKeyIncludesDatabase(t *testing.T) {
 // TestCacheKeyIsolation verifies that same query with different databases
 // produces different cache keys.
 func TestCacheKeyIsolation(t *testing.T) {
+	gj := &graphjinEngine{}
+	query := []byte("query getUsers { users { id } }")
+
 	s1 := gstate{
-		r:        GraphqlReq{namespace: "ns", name: "query"},
+		gj:       gj,
+		r:        GraphqlReq{namespace: "ns", name: "query", query: query},
 		role:     "user",
 		database: "db1",
 	}
 
 	s2 := gstate{
-		r:        GraphqlReq{namespace: "ns", name: "query"},
+		gj:       gj,
+		r:        GraphqlReq{namespace: "ns", name: "query", query: query},
 		role:     "user",
 		database: "db2",
 	}
@@ -457,19 +464,19 @@ func TestEnsureDiscoveredTablesInConfig(t *testing.T) {
 		s := &gstate{gj: gj}
 
 		// orders should route to ats_orders
-		byDB := s.groupRootsByDatabase([]string{"orders"})
+		byDB := s.groupRootsByDatabase(rootFieldsOf(gj, "orders"))
 		if db, ok := byDB["ats_orders"]; !ok || len(db) != 1 || db[0] != "orders" {
 			t.Errorf("expected orders routed to ats_orders, got %v", byDB)
 		}
 
 		// users should route to ats
-		byDB = s.groupRootsByDatabase([]string{"users"})
+		byDB = s.groupRootsByDatabase(rootFieldsOf(gj, "users"))
 		if db, ok := byDB["ats"]; !ok || len(db) != 1 || db[0] != "users" {
 			t.Errorf("expected users routed to ats, got %v", byDB)
 		}
 
 		// mixed roots
-		byDB = s.groupRootsByDatabase([]string{"users", "orders"})
+		byDB = s.groupRootsByDatabase(rootFieldsOf(gj, "users", "orders"))
 		if len(byDB) != 2 {
 			t.Errorf("expected 2 database groups, got %v", byDB)
 		}
@@ -1420,3 +1427,127 @@ func TestCloneConfigRoleTablesIsolation(t *testing.T) {
 		t.Error("original.Roles[0].Tables[0].ReadOnly was mutated through clone")
 	}
 }
+
+// TestExpandRelationships verifies declared relationships become column
+// foreign keys and polymorphic tables.
+func TestExpandRelationships(t *testing.T) {
+	conf := &Config{
+		Tables: []Table{
+			{
+				Name: "products",
+				Relationships: []Relationship{
+					{Column: "owner_id", RelatedTo: "users.id"},
+					{Type: "many_to_many", Column: "id", RelatedTo: "categories.id",
+						Through: "product_categories", ThroughColumns: []string{"product_id", "category_id"}},
+					{Type: "polymorphic", Name: "subject", Column: "subject_id", RelatedTo: "subject_type.id"},
+				},
+			},
+		},
+	}
+
+	if err := expandRelationships(conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(conf.Tables) != 3 {
+		t.Fatalf("expected 3 tables, got %d", len(conf.Tables))
+	}
+
+	exp := []Table{
+		{Name: "products", Columns: []Column{{Name: "owner_id", ForeignKey: "users.id"}}},
+		{Name: "product_categories", Columns: []Column{
+			{Name: "product_id", ForeignKey: "products.id"},
+			{Name: "category_id", ForeignKey: "categories.id"},
+		}},
+		{Name: "subject", Type: "polymorphic", Columns: []Column{{Name: "subject_id", ForeignKey: "subject_type.id"}}},
+	}
+	for i, e := range exp {
+		got := conf.Tables[i]
+		if got.Name != e.Name || got.Type != e.Type || !reflect.DeepEqual(got.Columns, e.Columns) {
+			t.Errorf("table %d: got %+v, want %+v", i, got, e)
+		}
+	}
+
+	conf = &Config{Tables: []Table{{
+		Name:          "products",
+		Columns:       []Column{{Name: "owner_id", ForeignKey: "accounts.id"}},
+		Relationships: []Relationship{{Column: "owner_id", RelatedTo: "users.id"}},
+	}}}
+	if err := expandRelationships(conf); err == nil {
+		t.Fatal("expected error for conflicting relationship")
+	}
+}
+
+// TestAddForeignKeyMongoDBMissingField verifies a declared relationship on a
+// field missing from the sampled documents adds the field to the collection.
+func TestAddForeignKeyMongoDBMissingField(t *testing.T) {
+	cols := []sdata.DBColumn{
+		{Schema: "public", Table: "users", Name: "_id", Type: "objectid", PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "posts", Name: "_id", Type: "objectid", PrimaryKey: true, UniqueKey: true},
+	}
+	di := sdata.NewDBInfo("mongodb", 0, "public", "app", cols, nil, nil)
+
+	conf := &Config{Tables: []Table{{
+		Name:          "posts",
+		Database:      "app",
+		Relationships: []Relationship{{Column: "author_id", RelatedTo: "users._id"}},
+	}}}
+	if err := expandRelationships(conf); err != nil {
+		t.Fatal(err)
+	}
+	if err := addForeignKeys(conf, di, "app", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	col, err := di.GetColumn("public", "posts", "author_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col.FKeyTable != "users" || col.FKeyCol != "_id" || col.Type != "objectid" {
+		t.Fatalf("unexpected column: %+v", col)
+	}
+}
+
+// TestUpdateTableMongoDBConfigOverride verifies config columns override the
+// sampled field types and add fields and collections that were not sampled.
+func TestUpdateTableMongoDBConfigOverride(t *testing.T) {
+	cols := []sdata.DBColumn{
+		{Schema: "public", Table: "posts", Name: "_id", Type: "text", PrimaryKey: true, UniqueKey: true},
+		{Schema: "public", Table: "posts", Name: "score", Type: "text"},
+	}
+	di := sdata.NewDBInfo("mongodb", 0, "public", "app", cols, nil, nil)
+
+	conf := &Config{Tables: []Table{
+		{Name: "posts", Database: "app", Columns: []Column{
+			{Name: "score", Type: "double precision"},
+			{Name: "tags", Type: "text", Array: true},
+		}},
+		{Name: "drafts", Database: "app", Columns: []Column{
+			{Name: "title", Type: "text"},
+		}},
+	}}
+	if err := addTables(conf, di, "app"); err != nil {
+		t.Fatal(err)
+	}
+
+	if col, err := di.GetColumn("public", "posts", "score"); err != nil || col.Type != "double precision" {
+		t.Fatalf("expected score to be a double precision column: %+v, %v", col, err)
+	}
+	if col, err := di.GetColumn("public", "posts", "tags"); err != nil || !col.Array {
+		t.Fatalf("expected tags to be an array column: %+v, %v", col, err)
+	}
+	if _, err := di.GetColumn("public", "drafts", "title"); err != nil {
+		t.Fatal(err)
+	}
+	if ti, err := di.GetTable("public", "drafts"); err != nil || ti.PrimaryCol.Name != "_id" {
+		t.Fatalf("expected drafts to have an _id primary key: %+v, %v", ti, err)
+	}
+}
+
+// rootFieldsOf returns the root fields for table names without aliases
+func rootFieldsOf(gj *graphjinEngine, names ...string) []rootField {
+	roots := make([]rootField, len(names))
+	for i, name := range names {
+		roots[i] = rootField{name: name, key: name, database: gj.rootDatabase(name)}
+	}
+	return roots
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dosco/graphjin/core/v3/internal/allow"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// getAllowItem returns the allow listed query for the namespace, queries
+// in the namespace folder override the shared queries
+func (gj *graphjinEngine) getAllowItem(ns, name string, useCache bool) (allow.Item, error) {
+	if _, ok := gj.conf.Namespaces[ns]; !ok {
+		return gj.allowList.GetByName(name, useCache)
+	}
+	return gj.allowList.GetByNamespace(ns, name, useCache)
+}
+
+// maxResponseBytes returns the response size limit for the request namespace
+func (s *gstate) maxResponseBytes() (limit int, truncate bool) {
+	limit = s.gj.conf.MaxResponseBytes
+	truncate = s.gj.conf.TruncateResponses
+
+	if nc, ok := s.gj.conf.Namespaces[s.r.namespace]; ok {
+		if nc.MaxResponseBytes != 0 {
+			limit = nc.MaxResponseBytes
+		}
+		if nc.TruncateResponses != nil {
+			truncate = *nc.TruncateResponses
+		}
+	}
+	return
+}
+
+// queryBudget returns the budget for the request namespace
+func (s *gstate) queryBudget() QueryBudget {
+	b := s.gj.conf.Budget
+
+	if nc, ok := s.gj.conf.Namespaces[s.r.namespace]; ok {
+		if nc.Budget.MaxSubQueries != 0 {
+			b.MaxSubQueries = nc.Budget.MaxSubQueries
+		}
+		if nc.Budget.MaxRows != 0 {
+			b.MaxRows = nc.Budget.MaxRows
+		}
+		if nc.Budget.MaxTime != 0 {
+			b.MaxTime = nc.Budget.MaxTime
+		}
+	}
+	return b
+}
+
+// checkNamespaceBlocklist fails the query when it uses a table or column
+// blocked in the request namespace
+func (s *gstate) checkNamespaceBlocklist(qc *qcode.QCode) error {
+	nc, ok := s.gj.conf.Namespaces[s.r.namespace]
+	if !ok || len(nc.Blocklist) == 0 || qc == nil {
+		return nil
+	}
+
+	for _, sel := range qc.Selects {
+		if sel.Ti.Name != "" && inBlocklist(sel.Ti.Name, nc.Blocklist) {
+			return fmt.Errorf("table blocked in namespace '%s': %s", s.r.namespace, sel.Ti.Name)
+		}
+		for _, f := range sel.Fields {
+			if f.Col.Name != "" && inBlocklist(f.Col.Name, nc.Blocklist) {
+				return fmt.Errorf("column blocked in namespace '%s': %s.%s",
+					s.r.namespace, sel.Ti.Name, f.Col.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// inBlocklist matches the name against the block list patterns the same
+// way as the base block list
+func inBlocklist(name string, list []string) bool {
+	for _, v := range list {
+		if ok, _ := regexp.MatchString("^"+v+"$", name); ok {
+			return true
+		}
+	}
+	return false
+}
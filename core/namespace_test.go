@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestNamespaceOverlay(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, cost INTEGER);
+		INSERT INTO products (id, name, cost) VALUES (1, 'shoe', 10), (2, 'hat', 5);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := core.NewOsFS(dir)
+	queries := map[string]string{
+		"/queries/getProducts.gql":      `query getProducts { products(order_by: { id: asc }) { id } }`,
+		"/queries/acme/getProducts.gql": `query getProducts { products(order_by: { id: asc }) { id name } }`,
+		"/queries/getCosts.gql":         `query getCosts { products(order_by: { id: asc }) { id cost } }`,
+	}
+	for path, q := range queries {
+		if err := fs.Put(path, []byte(q)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &core.Config{
+		DBType:     "sqlite",
+		Production: true,
+		Namespaces: map[string]core.NamespaceConfig{
+			"acme": {Blocklist: []string{"cost"}},
+			"tiny": {MaxResponseBytes: 10},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nsConf := func(ns string) *core.RequestConfig {
+		rc := &core.RequestConfig{}
+		rc.SetNamespace(ns)
+		return rc
+	}
+
+	tests := []struct {
+		name string
+		rc   *core.RequestConfig
+		op   string
+		exp  string
+	}{
+		{"shared query", nil, "getProducts",
+			`{"products":[{"id":1},{"id":2}]}`},
+		{"namespace query", nsConf("acme"), "getProducts",
+			`{"products":[{"id":1,"name":"shoe"},{"id":2,"name":"hat"}]}`},
+		{"blocked column", nsConf("acme"), "getCosts", ""},
+		{"not blocked", nil, "getCosts",
+			`{"products":[{"id":1,"cost":10},{"id":2,"cost":5}]}`},
+	}
+
+	for _, tt := range tests {
+		res, err := gj.GraphQLByName(context.Background(), tt.op, nil, tt.rc)
+		if tt.exp == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tt.name, res.Data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if string(res.Data) != tt.exp {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.exp, res.Data)
+		}
+	}
+
+	// tiny uses the shared query with a smaller response size limit
+	_, err = gj.GraphQLByName(context.Background(), "getProducts", nil, nsConf("tiny"))
+	if !errors.Is(err, core.ErrResponseTooLarge) {
+		t.Errorf("expected the namespace response size limit, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"github.com/dosco/graphjin/core/v3/internal/util"
+)
+
+// NamingStrategy converts database table and column names to GraphQL names
+// and back. Name overrides and acronyms from the naming config are applied
+// on top of the strategy.
+type NamingStrategy interface {
+	GraphQLName(name string) string
+	DBName(name string) string
+}
+
+// OptionSetNamingStrategy sets a custom naming strategy replacing the
+// strategy from the naming config
+func OptionSetNamingStrategy(ns NamingStrategy) Option {
+	return func(s *graphjinEngine) error {
+		s.namingStrategy = ns
+		return nil
+	}
+}
+
+// camelCase returns true when GraphQL names are camel cased
+func (c *Config) camelCase() bool {
+	return c.Naming.Strategy == "camelCase" ||
+		(c.Naming.Strategy == "" && c.EnableCamelcase)
+}
+
+// initNamer builds the namer used by introspection, the query compiler
+// and the OpenAPI generator
+func (gj *graphjinEngine) initNamer() {
+	nc := util.NamerConfig{
+		Camel:    gj.conf.camelCase(),
+		Acronyms: gj.conf.Naming.Acronyms,
+		Tables:   make(map[string]string, len(gj.conf.Naming.Tables)),
+		Columns:  make(map[string]string, len(gj.conf.Naming.Columns)),
+	}
+	for k, v := range gj.conf.Naming.Tables {
+		nc.Tables[k] = v
+	}
+	for k, v := range gj.conf.Naming.Columns {
+		nc.Columns[k] = v
+	}
+
+	// aliases defined on tables and columns take precedence
+	for _, t := range gj.conf.Tables {
+		if t.Alias != "" {
+			nc.Tables[t.Name] = t.Alias
+		}
+		for _, c := range t.Columns {
+			if c.Alias != "" {
+				nc.Columns[t.Name+"."+c.Name] = c.Alias
+			}
+		}
+	}
+
+	if ns := gj.namingStrategy; ns != nil {
+		nc.ToName = ns.GraphQLName
+		nc.ToDBName = ns.DBName
+	}
+	gj.namer = util.NewNamer(nc)
+}
+
+// deprecations returns the deprecation reasons of tables and columns
+// keyed by table or table.column
+func (c *Config) deprecations() map[string]string {
+	dm := make(map[string]string)
+	for _, t := range c.Tables {
+		if t.Deprecated != "" {
+			dm[t.Name] = t.Deprecated
+		}
+		for _, col := range t.Columns {
+			if col.Deprecated != "" {
+				dm[t.Name+"."+col.Name] = col.Deprecated
+			}
+		}
+	}
+	return dm
+}
+
+// hasStringColumns returns true if any column is configured to be returned
+// as a string
+func (c *Config) hasStringColumns() bool {
+	for _, t := range c.Tables {
+		for _, col := range t.Columns {
+			if col.AsString {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE user_accounts (id INTEGER PRIMARY KEY, full_name TEXT, email TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES user_accounts(id), title TEXT);
+		INSERT INTO user_accounts (id, full_name, email) VALUES (1, 'Ada Lovelace', 'ada@example.com');
+		INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'Notes');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType: "sqlite",
+		Naming: core.Naming{
+			Strategy: "camelCase",
+			Acronyms: []string{"ID"},
+			Tables:   map[string]string{"user_accounts": "members"},
+			Columns:  map[string]string{"user_accounts.email": "emailAddress"},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		members(where: { emailAddress: { eq: "ada@example.com" } }, order_by: { fullName: asc }) {
+			id
+			fullName
+			emailAddress
+			posts { userID title }
+		}
+	}`
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"members":[{"id":1,"fullName":"Ada Lovelace","emailAddress":"ada@example.com","posts":[{"userID":1,"title":"Notes"}]}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	conf = &core.Config{DBType: "sqlite", Naming: core.Naming{Strategy: "kebab"}}
+	if _, err := core.NewGraphJin(conf, db); err == nil {
+		t.Fatal("expected unknown naming strategy error")
+	}
+}
+
+func TestTableAndColumnAliases(t *testing.T) {
+	dir := t.TempDir()
+	fs := core.NewOsFS(dir)
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE cust_tbl (id INTEGER PRIMARY KEY, cust_nm TEXT);
+		INSERT INTO cust_tbl (id, cust_nm) VALUES (1, 'Ada'), (2, 'Grace'), (3, 'Alan');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:              "sqlite",
+		EnableIntrospection: true,
+		Tables: []core.Table{{
+			Name:       "cust_tbl",
+			Alias:      "customers",
+			Deprecated: "use the orders service",
+			Columns: []core.Column{
+				{Name: "cust_nm", Alias: "name", Deprecated: "use full_name"},
+			},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		customers(where: { name: { neq: "Alan" } }, order_by: { name: desc }) { id name }
+	}`
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"customers":[{"id":2,"name":"Grace"},{"id":1,"name":"Ada"}]}`; string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	b, err := fs.Get("intro.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var intro core.IntroResult
+	if err := json.Unmarshal(b, &intro); err != nil {
+		t.Fatal(err)
+	}
+
+	field := func(typeName, name string) (f core.FieldObject) {
+		for _, ty := range intro.Schema.Types {
+			if ty.Name != typeName {
+				continue
+			}
+			for _, f := range ty.Fields {
+				if f.Name == name {
+					return f
+				}
+			}
+		}
+		t.Fatalf("field %s.%s not found", typeName, name)
+		return
+	}
+
+	if f := field("Query", "customers"); !f.IsDeprecated || *f.DeprecationReason != "use the orders service" {
+		t.Fatalf("expected customers to be deprecated: %+v", f)
+	}
+	if f := field("customers", "name"); !f.IsDeprecated || *f.DeprecationReason != "use full_name" {
+		t.Fatalf("expected customers.name to be deprecated: %+v", f)
+	}
+	if f := field("customers", "id"); f.IsDeprecated {
+		t.Fatal("expected customers.id not to be deprecated")
+	}
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/jsn"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// N+1 warning kinds
+const (
+	NPlusOneRemoteJoin   = "remote_join"
+	NPlusOneDatabaseJoin = "database_join"
+	NPlusOneFunction     = "function"
+)
+
+// NPlusOneWarning is a part of a query that runs once for every row of a
+// list, these are only reported in development mode
+type NPlusOneWarning struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Calls   int    `json:"calls,omitempty"`
+	Message string `json:"message"`
+}
+
+// nPlusOneWarnings finds the remote joins, cross-database joins and
+// function fields in the query that fan out per row. Calls has the number
+// of remote and database join requests made for each select, remote joins
+// in batched are fetched with a single request and never fan out.
+func nPlusOneWarnings(qc *qcode.QCode, calls map[int32]int, batched map[int32]bool) []NPlusOneWarning {
+	var warnings []NPlusOneWarning
+
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+
+		var kind, msg string
+		switch sel.SkipRender {
+		case qcode.SkipTypeRemote:
+			if batched[sel.ID] {
+				continue
+			}
+			kind = NPlusOneRemoteJoin
+			msg = "remote api is called once for every row"
+		case qcode.SkipTypeDatabaseJoin:
+			kind = NPlusOneDatabaseJoin
+			msg = "database is queried once for every row"
+		}
+
+		if kind != "" {
+			if n := calls[sel.ID]; n > 1 || inList(qc.Selects, sel.ParentID) {
+				warnings = append(warnings, NPlusOneWarning{
+					Kind:    kind,
+					Path:    selectPath(qc.Selects, sel.ID),
+					Calls:   n,
+					Message: msg,
+				})
+			}
+			continue
+		}
+
+		if sel.SkipRender != qcode.SkipTypeNone || !inList(qc.Selects, sel.ID) {
+			continue
+		}
+
+		for _, f := range sel.Fields {
+			if f.Type != qcode.FieldTypeFunc || f.Func.Agg || f.SkipRender != qcode.SkipTypeNone {
+				continue
+			}
+			warnings = append(warnings, NPlusOneWarning{
+				Kind:    NPlusOneFunction,
+				Path:    selectPath(qc.Selects, sel.ID) + "." + f.FieldName,
+				Message: fmt.Sprintf("db function '%s' is called once for every row", f.Func.Name),
+			})
+		}
+	}
+	return warnings
+}
+
+// inList returns true if the select or any of its parents return a list
+func inList(selects []qcode.Select, id int32) bool {
+	for id != -1 {
+		if !selects[id].Singular {
+			return true
+		}
+		id = selects[id].ParentID
+	}
+	return false
+}
+
+// selectPath returns the dotted path of field names to the select
+func selectPath(selects []qcode.Select, id int32) string {
+	var path []string
+	for id != -1 {
+		path = append(path, selects[id].FieldName)
+		id = selects[id].ParentID
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return strings.Join(path, ".")
+}
+
+// countCalls records the number of requests made for each joined select
+func (s *gstate) countCalls(from []jsn.Field, sfmap map[string]*qcode.Select) {
+	if s.gj.prod {
+		return
+	}
+	if s.calls == nil {
+		s.calls = make(map[int32]int)
+	}
+	batched := s.batchedRemotes()
+	for _, f := range from {
+		if sel, ok := sfmap[string(f.Key)]; ok {
+			if batched[sel.ID] {
+				s.calls[sel.ID] = 1
+				continue
+			}
+			s.calls[sel.ID]++
+		}
+	}
+}
+
+// checkNPlusOne logs the parts of the query that fan out per row, this is
+// only done in development mode
+func (s *gstate) checkNPlusOne() {
+	if s.gj.prod || s.cs == nil || s.cs.st.qc == nil {
+		return
+	}
+
+	s.nplus1 = nPlusOneWarnings(s.cs.st.qc, s.calls, s.batchedRemotes())
+	for _, w := range s.nplus1 {
+		s.gj.log.Printf("WRN n+1: kind=%s path=%s calls=%d: %s", w.Kind, w.Path, w.Calls, w.Message)
+	}
+}
+
+// batchedRemotes returns the remote selects whose resolver fetches all
+// their ids with a single request
+func (s *gstate) batchedRemotes() map[int32]bool {
+	selects := s.cs.st.qc.Selects
+	batched := make(map[int32]bool)
+
+	for _, sel := range selects {
+		if sel.SkipRender != qcode.SkipTypeRemote {
+			continue
+		}
+		r, ok := s.gj.rmap[(sel.Table + selects[sel.ParentID].Table)]
+		if !ok {
+			continue
+		}
+		if _, ok := r.Fn.(BatchResolver); ok {
+			batched[sel.ID] = true
+		}
+	}
+	return batched
+}
@@ -0,0 +1,83 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type countResolver struct{}
+
+func (countResolver) Resolve(c context.Context, rr core.ResolverReq) ([]byte, error) {
+	return []byte(`{"amount": 10}`), nil
+}
+
+func TestNPlusOneWarnings(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'shoe'), (2, 'hat');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(prod bool) *core.GraphJin {
+		conf := &core.Config{DBType: "sqlite", Production: prod}
+		conf.Resolvers = []core.ResolverConfig{{
+			Name: "payments", Type: "counter", Table: "products", Column: "id",
+		}}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)),
+			core.OptionSetResolver("counter", func(v core.ResolverProps) (core.Resolver, error) {
+				return countResolver{}, nil
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+
+	gql := `query getProducts { products(order_by: { id: asc }) { id payments { amount } } }`
+
+	res, err := newGJ(false).GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions == nil || len(res.Extensions.NPlusOne) != 1 {
+		t.Fatalf("expected one n+1 warning, got %+v", res.Extensions)
+	}
+	w := res.Extensions.NPlusOne[0]
+	if w.Kind != core.NPlusOneRemoteJoin || w.Path != "products.payments" || w.Calls != 2 {
+		t.Fatalf("unexpected warning: %+v", w)
+	}
+
+	// a single row does not fan out
+	gql1 := `query getProduct { products(id: 1) { id payments { amount } } }`
+	res, err = newGJ(false).GraphQL(context.Background(), gql1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions != nil {
+		t.Fatalf("expected no warnings, got %+v", res.Extensions)
+	}
+
+	// no warnings in production, the query was saved to the allow list above
+	res, err = newGJ(true).GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions != nil {
+		t.Fatalf("expected no warnings in production, got %+v", res.Extensions)
+	}
+}
@@ -35,10 +35,12 @@ type OpenAPIServer struct {
 }
 
 type PathItem struct {
-	Get    *OpenAPIOperation `json:"get,omitempty"`
-	Post   *OpenAPIOperation `json:"post,omitempty"`
-	Put    *OpenAPIOperation `json:"put,omitempty"`
-	Delete *OpenAPIOperation `json:"delete,omitempty"`
+	Servers []OpenAPIServer   `json:"servers,omitempty"`
+	Get     *OpenAPIOperation `json:"get,omitempty"`
+	Post    *OpenAPIOperation `json:"post,omitempty"`
+	Put     *OpenAPIOperation `json:"put,omitempty"`
+	Patch   *OpenAPIOperation `json:"patch,omitempty"`
+	Delete  *OpenAPIOperation `json:"delete,omitempty"`
 }
 
 type OpenAPIOperation struct {
@@ -49,6 +51,7 @@ type OpenAPIOperation struct {
 	RequestBody *RequestBody        `json:"requestBody,omitempty"`
 	Responses   map[string]Response `json:"responses"`
 	Tags        []string            `json:"tags,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
 }
 
 type Parameter struct {
@@ -149,6 +152,10 @@ func (g *GraphJin) GenerateOpenAPISpec() (*OpenAPIDocument, error) {
 		spec.Paths[path] = pathItem
 	}
 
+	if gj.conf.EnableCRUD {
+		g.generateCRUDPaths(spec, gj)
+	}
+
 	return spec, nil
 }
 
@@ -184,20 +191,20 @@ func (g *GraphJin) generateComponents(components *OpenAPIComponents, gj *graphji
 	// Generate schema objects for each table using introspection logic (deterministic order)
 	for _, dbName := range gj.sortedDatabaseNames() {
 		ctx := gj.databases[dbName]
-		if ctx.schema == nil {
+		if !ctx.ready() {
 			continue
 		}
-		g.generateTablesForSchema(ctx.schema, components)
+		g.generateTablesForSchema(gj, ctx.schema, components)
 	}
 }
 
-func (g *GraphJin) generateTablesForSchema(dbSchema *sdata.DBSchema, components *OpenAPIComponents) {
+func (g *GraphJin) generateTablesForSchema(gj *graphjinEngine, dbSchema *sdata.DBSchema, components *OpenAPIComponents) {
 	for _, table := range dbSchema.GetTables() {
 		if table.Blocked || len(table.Columns) == 0 {
 			continue
 		}
 
-		tableName := cases.Title(language.English).String(table.Name)
+		tableName := gj.openAPISchemaName(table.Name)
 
 		// Generate table object schema
 		tableSchema := Schema{
@@ -211,7 +218,7 @@ func (g *GraphJin) generateTablesForSchema(dbSchema *sdata.DBSchema, components
 			}
 
 			fieldSchema := g.columnToOpenAPISchema(col)
-			tableSchema.Properties[col.Name] = fieldSchema
+			tableSchema.Properties[gj.namer.Column(table.Name, col.Name)] = fieldSchema
 		}
 
 		components.Schemas[tableName] = tableSchema
@@ -286,7 +293,15 @@ func (g *GraphJin) extractParameters(varDefs []graph.VarDef) []Parameter {
 		typeName := "String" // default type
 		required := false    // default to optional
 
-		if varDef.Val != nil {
+		var vt *varType
+		if varDef.Type != "" {
+			vt, _ = parseVarType(varDef.Type)
+		}
+
+		switch {
+		case vt != nil:
+			required = vt.nonNull
+		case varDef.Val != nil:
 			typeName = varDef.Val.Name
 			// Check if it's a non-null type (required)
 			if varDef.Val.Type == graph.NodeLabel && len(varDef.Val.Children) > 0 &&
@@ -295,12 +310,26 @@ func (g *GraphJin) extractParameters(varDefs []graph.VarDef) []Parameter {
 			}
 		}
 
+		var schema Schema
+		switch {
+		case vt != nil && vt.elem != nil:
+			for vt.elem != nil {
+				vt = vt.elem
+			}
+			item := g.graphQLTypeToOpenAPISchema(vt.name)
+			schema = Schema{Type: "array", Items: &item}
+		case vt != nil:
+			schema = g.graphQLTypeToOpenAPISchema(vt.name)
+		default:
+			schema = g.graphQLTypeToOpenAPISchema(typeName)
+		}
+
 		param := Parameter{
 			Name:        varDef.Name,
 			In:          "query",
 			Description: fmt.Sprintf("GraphQL variable: %s", varDef.Name),
 			Required:    required,
-			Schema:      g.graphQLTypeToOpenAPISchema(typeName),
+			Schema:      schema,
 		}
 		params = append(params, param)
 	}
@@ -374,7 +403,7 @@ func (g *GraphJin) generateDataSchemaFromQCode(qc *qcode.QCode, gj *graphjinEngi
 		rootSel := &qc.Selects[qc.Roots[0]]
 		tableName := rootSel.Ti.Name
 		if tableName != "" {
-			schemaName := cases.Title(language.English).String(tableName)
+			schemaName := gj.openAPISchemaName(tableName)
 			if rootSel.Singular {
 				return Schema{Ref: fmt.Sprintf("#/components/schemas/%s", schemaName)}
 			}
@@ -397,7 +426,7 @@ func (g *GraphJin) generateDataSchemaFromQCode(qc *qcode.QCode, gj *graphjinEngi
 		rootSel := &qc.Selects[rootID]
 		tableName := rootSel.Ti.Name
 		if tableName != "" {
-			schemaName := cases.Title(language.English).String(tableName)
+			schemaName := gj.openAPISchemaName(tableName)
 			if rootSel.Singular {
 				schema.Properties[rootSel.FieldName] = Schema{Ref: fmt.Sprintf("#/components/schemas/%s", schemaName)}
 			} else {
@@ -415,6 +444,16 @@ func (g *GraphJin) generateDataSchemaFromQCode(qc *qcode.QCode, gj *graphjinEngi
 }
 
 
+// openAPISchemaName returns the component schema name for a table
+// using the configured naming strategy
+func (gj *graphjinEngine) openAPISchemaName(table string) string {
+	name := gj.namer.Table(table)
+	if name == table {
+		return cases.Title(language.English).String(name)
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
 // columnToOpenAPISchema converts a database column to OpenAPI schema
 // Uses the same logic as GraphJin's getType and getTypeFromColumn functions
 func (g *GraphJin) columnToOpenAPISchema(col sdata.DBColumn) Schema {
@@ -449,13 +488,24 @@ func (g *GraphJin) columnToOpenAPISchema(col sdata.DBColumn) Schema {
 			} else {
 				format = "int32"
 			}
+			if gj, err := g.getEngine(); err == nil && gj.conf.BigIntAsString && col.IsBigInt() {
+				baseType = "string"
+				format = "int64"
+			}
 		case "Float":
 			baseType = "number"
 			format = "float"
+			if gj, err := g.getEngine(); err == nil && gj.conf.DecimalAsString && col.IsDecimal() {
+				baseType = "string"
+				format = "decimal"
+			}
 		case "Boolean":
 			baseType = "boolean"
 		case "JSON":
 			return Schema{Type: "object", AdditionalProperties: true, Description: description}
+		case "Duration":
+			baseType = "string"
+			format = "duration"
 		default:
 			description = fmt.Sprintf("Unknown SQL type: %s", col.Type)
 		}
@@ -528,6 +578,12 @@ func (g *GraphJin) generatePathItem(analysis *QueryAnalysis, components *OpenAPI
 			},
 		}
 
+		if d := newQueryDeprecation(analysis.Item.Deprecation); d != nil {
+			operation.Deprecated = true
+			operation.Description += ". " + d.Message(analysis.Item.Name)
+			operation.Responses["410"] = Response{Description: "Query retired after its sunset date"}
+		}
+
 		// Add parameters for GET requests
 		if method == "GET" && len(analysis.Parameters) > 0 {
 			operation.Parameters = analysis.Parameters
@@ -581,6 +637,124 @@ func (g *GraphJin) generatePathItem(analysis *QueryAnalysis, components *OpenAPI
 	return pathItem
 }
 
+// generateCRUDPaths adds the per-table CRUD routes. They are served from a
+// different base path than the saved query routes so each path item carries
+// its own server override.
+func (g *GraphJin) generateCRUDPaths(spec *OpenAPIDocument, gj *graphjinEngine) {
+	servers := []OpenAPIServer{{
+		URL:         CRUDBasePath,
+		Description: "GraphJin CRUD API Server",
+	}}
+
+	jsonResp := func(desc string, schema Schema) map[string]Response {
+		return map[string]Response{
+			"200": {
+				Description: desc,
+				Content: map[string]MediaType{
+					"application/json": {Schema: Schema{
+						Type: "object",
+						Properties: map[string]Schema{
+							"data":   schema,
+							"errors": {Ref: "#/components/schemas/GraphQLError"},
+						},
+					}},
+				},
+			},
+			"400": {
+				Description: "Bad request",
+				Content: map[string]MediaType{
+					"application/json": {Schema: Schema{Ref: "#/components/schemas/GraphJinResponse"}},
+				},
+			},
+		}
+	}
+
+	for _, dbName := range gj.sortedDatabaseNames() {
+		ctx := gj.databases[dbName]
+		if !ctx.ready() {
+			continue
+		}
+		for _, t := range ctx.schema.GetTables() {
+			if t.Blocked || len(t.Columns) == 0 {
+				continue
+			}
+			if _, ok := spec.Paths["/"+t.Name]; ok {
+				continue
+			}
+
+			schemaName := gj.openAPISchemaName(t.Name)
+			ref := Schema{Ref: fmt.Sprintf("#/components/schemas/%s", schemaName)}
+			list := Schema{Type: "array", Items: &ref}
+			data := Schema{Type: "object", Properties: map[string]Schema{t.Name: ref}}
+			listData := Schema{Type: "object", Properties: map[string]Schema{t.Name: list}}
+			body := &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: ref}},
+			}
+			tags := []string{schemaName}
+
+			spec.Paths["/"+t.Name] = PathItem{
+				Servers: servers,
+				Get: &OpenAPIOperation{
+					Summary:     fmt.Sprintf("List %s", t.Name),
+					OperationID: "list_" + t.Name,
+					Tags:        tags,
+					Parameters: []Parameter{
+						{Name: "limit", In: "query", Schema: Schema{Type: "integer"}},
+						{Name: "offset", In: "query", Schema: Schema{Type: "integer"}},
+					},
+					Responses: jsonResp("List of rows", listData),
+				},
+				Post: &OpenAPIOperation{
+					Summary:     fmt.Sprintf("Create %s", t.Name),
+					OperationID: "create_" + t.Name,
+					Tags:        tags,
+					RequestBody: body,
+					Responses:   jsonResp("Created row", data),
+				},
+			}
+
+			if t.PrimaryCol.Name == "" {
+				continue
+			}
+
+			idParam := []Parameter{{
+				Name:        "id",
+				In:          "path",
+				Description: fmt.Sprintf("Value of the primary key %s", t.PrimaryCol.Name),
+				Required:    true,
+				Schema:      g.columnToOpenAPISchema(t.PrimaryCol),
+			}}
+
+			spec.Paths["/"+t.Name+"/{id}"] = PathItem{
+				Servers: servers,
+				Get: &OpenAPIOperation{
+					Summary:     fmt.Sprintf("Get %s by id", t.Name),
+					OperationID: "read_" + t.Name,
+					Tags:        tags,
+					Parameters:  idParam,
+					Responses:   jsonResp("Row", data),
+				},
+				Patch: &OpenAPIOperation{
+					Summary:     fmt.Sprintf("Update %s by id", t.Name),
+					OperationID: "update_" + t.Name,
+					Tags:        tags,
+					Parameters:  idParam,
+					RequestBody: body,
+					Responses:   jsonResp("Updated row", data),
+				},
+				Delete: &OpenAPIOperation{
+					Summary:     fmt.Sprintf("Delete %s by id", t.Name),
+					OperationID: "delete_" + t.Name,
+					Tags:        tags,
+					Parameters:  idParam,
+					Responses:   jsonResp("Deleted row", data),
+				},
+			}
+		}
+	}
+}
+
 // GetOpenAPISpec returns the OpenAPI specification as JSON
 func (g *GraphJin) GetOpenAPISpec() ([]byte, error) {
 	spec, err := g.GenerateOpenAPISpec()
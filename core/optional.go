@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const optionalSavepoint = "gj_optional"
+
+// executeOptional runs a mutation that has @optional branches inside a
+// savepoint. If the mutation fails it is rolled back to the savepoint and
+// run again without the data for the optional branches so that the rest
+// of the mutation still commits.
+func (s *gstate) executeOptional(c context.Context, conn *sql.Conn) (err error) {
+	dbCtx := s.getTargetDBCtx()
+	pc := dbCtx.psqlCompiler
+
+	sp := pc.RenderSavepoint(optionalSavepoint)
+	if sp == "" {
+		return fmt.Errorf("@optional: savepoints not supported by %s", dbCtx.dbtype)
+	}
+
+	// use a transaction of our own unless the request is already in one
+	if s.tx() == nil {
+		if s.optTx, err = conn.BeginTx(c, nil); err != nil {
+			return
+		}
+		defer func() {
+			if err == nil {
+				err = s.optTx.Commit()
+			} else {
+				s.optTx.Rollback() //nolint:errcheck
+			}
+		}()
+	}
+	tx := s.tx()
+
+	if _, err = tx.ExecContext(c, sp); err != nil {
+		return
+	}
+
+	if err = s.execute(c, conn); err == nil {
+		return
+	}
+
+	vars, names, err1 := dbCtx.qcodeCompiler.StripOptional(s.cs.st.qc, s.vmap)
+	if err1 != nil || len(names) == 0 {
+		return
+	}
+
+	rb := pc.RenderRollbackToSavepoint(optionalSavepoint)
+	if _, err1 = tx.ExecContext(c, rb); err1 != nil {
+		return
+	}
+
+	// compile again without the optional branches, this must not touch
+	// the shared compiled query or plan caches
+	s.vmap = vars
+	s.r.aschema = nil
+	s.r.dynamic = true
+	s.cs = nil
+	s.verrs = nil
+	s.data = nil
+
+	if err = s.compileQueryForRole(); err != nil {
+		return
+	}
+	s.setDefaultVars()
+
+	if err = s.execute(c, conn); err != nil {
+		return
+	}
+	s.rolledBack = names
+	return
+}
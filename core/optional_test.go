@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestOptionalMutationBranch(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE addresses (
+			id INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users(id),
+			city TEXT NOT NULL
+		);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `mutation {
+		users(insert: $data) {
+			id
+			addresses @optional {
+				city
+			}
+		}
+	}`
+
+	count := func(table string) (n int) {
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	t.Run("branch succeeds", func(t *testing.T) {
+		vars := json.RawMessage(`{"data": {"id": 1, "name": "alice", "addresses": {"id": 1, "city": "Paris"}}}`)
+		res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Extensions != nil {
+			t.Fatalf("expected no rolled back branches, got %+v", res.Extensions)
+		}
+		if n := count("addresses"); n != 1 {
+			t.Fatalf("expected 1 address, got %d", n)
+		}
+	})
+
+	t.Run("branch rolled back", func(t *testing.T) {
+		vars := json.RawMessage(`{"data": {"id": 2, "name": "bob", "addresses": {"id": 2, "city": null}}}`)
+		res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Extensions == nil || len(res.Extensions.RolledBack) != 1 ||
+			res.Extensions.RolledBack[0] != "addresses" {
+			t.Fatalf("expected the addresses branch to be rolled back, got %+v", res.Extensions)
+		}
+		if n := count("users"); n != 2 {
+			t.Fatalf("expected the user to be inserted, got %d users", n)
+		}
+		if n := count("addresses"); n != 1 {
+			t.Fatalf("expected the address to be rolled back, got %d addresses", n)
+		}
+	})
+
+	t.Run("not valid on queries", func(t *testing.T) {
+		_, err := gj.GraphQL(context.Background(),
+			`query { users { id addresses @optional { city } } }`, nil, nil)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
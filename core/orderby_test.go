@@ -0,0 +1,59 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestOrderByVarKeys(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price REAL);
+		INSERT INTO products (id, name, price) VALUES (1, 'shoe', 20), (2, 'hat', 30), (3, 'bag', 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite"}
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query getProducts { products(order_by: $sort) { id } }`
+
+	tests := []struct {
+		vars string
+		exp  string
+	}{
+		{`{"sort": "price_desc"}`, `{"products":[{"id":2},{"id":1},{"id":3}]}`},
+		{`{"sort": "name_asc"}`, `{"products":[{"id":3},{"id":2},{"id":1}]}`},
+	}
+	for _, tt := range tests {
+		res, err := gj.GraphQL(context.Background(), gql, json.RawMessage(tt.vars), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(res.Data) != tt.exp {
+			t.Errorf("%s: expected %s, got %s", tt.vars, tt.exp, res.Data)
+		}
+	}
+
+	_, err = gj.GraphQL(context.Background(), gql,
+		json.RawMessage(`{"sort": "price desc; drop table products"}`), nil)
+	if err == nil {
+		t.Fatal("expected an error for an invalid order_by key")
+	}
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+const planPath = "/plans"
+
+// planEntry is the persisted form of a compiled statement. The query
+// code itself is not persisted since it references the live schema,
+// only the generated SQL and its parameter metadata are.
+type planEntry struct {
+	QueryHash string        `json:"query_hash"`
+	Schema    string        `json:"schema"`
+	Dialect   string        `json:"dialect"`
+	SQL       string        `json:"sql"`
+	Meta      psql.Metadata `json:"meta"`
+}
+
+type planCache struct {
+	sync.Mutex
+	fs      FS
+	plans   map[string]planEntry
+	schemas map[string]string
+}
+
+// initPlanCache loads the persisted plans. Queries are still compiled on
+// first use, plans that match the current schema checksum and dialect skip
+// SQL generation and the rest are compiled and saved.
+func (gj *graphjinEngine) initPlanCache() (err error) {
+	if !gj.conf.EnablePlanCache || !gj.prodSec || gj.fs == nil {
+		return
+	}
+
+	pc := &planCache{
+		fs:      gj.fs,
+		plans:   make(map[string]planEntry),
+		schemas: make(map[string]string, len(gj.databases)),
+	}
+
+	conf, err := json.Marshal(gj.conf)
+	if err != nil {
+		return
+	}
+
+	for name, dbCtx := range gj.databases {
+		if dbCtx.dbinfo != nil {
+			pc.schemas[name] = schemaChecksum(dbCtx.dbinfo, conf)
+		}
+	}
+
+	// plans folder will not exist on first boot
+	files, _ := gj.fs.List(planPath)
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".json") {
+			continue
+		}
+		b, err := gj.fs.Get(path.Join(planPath, f))
+		if err != nil {
+			continue
+		}
+		var p planEntry
+		if err := json.Unmarshal(b, &p); err != nil {
+			continue
+		}
+		if planFile(p.QueryHash, p.Schema, p.Dialect) != f {
+			continue
+		}
+		pc.plans[f] = p
+	}
+	gj.plans = pc
+	return nil
+}
+
+// schemaChecksum hashes the discovered schema along with the config. Tables
+// and columns are sorted since discovery order is not stable across boots.
+func schemaChecksum(di *sdata.DBInfo, conf []byte) string {
+	lines := []string{fmt.Sprintf("%s %d %s %s", di.Type, di.Version, di.Schema, di.Name)}
+	for _, t := range di.Tables {
+		lines = append(lines, fmt.Sprintf("%s.%s %s", t.Schema, t.Name, t.Type))
+		for _, c := range t.Columns {
+			c.ID = 0
+			lines = append(lines, c.String())
+		}
+	}
+	for _, fn := range di.Functions {
+		lines = append(lines, fn.String())
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, l := range lines {
+		h.Write([]byte(l))
+		h.Write([]byte{'\n'})
+	}
+	h.Write(conf)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// planQueryHash returns the hash identifying a query compiled for a role
+func planQueryHash(s *gstate, dbName string) string {
+	h := sha256.New()
+	for _, v := range []string{s.r.namespace, s.r.name, s.role, dbName} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	h.Write(s.r.query)
+	if len(s.r.aschema) != 0 {
+		if b, err := json.Marshal(s.r.aschema); err == nil {
+			h.Write(b)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func planFile(queryHash, schema, dialect string) string {
+	h := sha256.Sum256([]byte(queryHash + schema + dialect))
+	return hex.EncodeToString(h[:16]) + ".json"
+}
+
+// getPlan returns the persisted plan for the statement if one exists
+// for the current schema checksum and dialect
+func (gj *graphjinEngine) getPlan(s *gstate, dbName string) (p planEntry, fname string, ok bool) {
+	if gj.plans == nil || s.r.dynamic {
+		return
+	}
+	dbCtx, found := gj.GetDatabase(dbName)
+	if !found {
+		return
+	}
+	if dbName == "" {
+		dbName = gj.defaultDB
+	}
+	schema, found := gj.plans.schemas[dbName]
+	if !found {
+		return
+	}
+
+	qh := planQueryHash(s, dbName)
+	fname = planFile(qh, schema, dbCtx.dbtype)
+
+	gj.plans.Lock()
+	p, ok = gj.plans.plans[fname]
+	gj.plans.Unlock()
+	gj.cacheLookup(CachePlan, ok)
+
+	if !ok {
+		p = planEntry{QueryHash: qh, Schema: schema, Dialect: dbCtx.dbtype}
+	}
+	return
+}
+
+// savePlan persists a freshly compiled statement
+func (gj *graphjinEngine) savePlan(fname string, p planEntry) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+
+	gj.plans.Lock()
+	gj.plans.plans[fname] = p
+	gj.plans.Unlock()
+
+	if err := gj.plans.fs.Put(path.Join(planPath, fname), b); err != nil {
+		gj.log.Printf("WRN failed to save query plan: %s", err)
+	}
+}
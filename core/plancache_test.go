@@ -0,0 +1,99 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestPlanCachePersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Ada'), (2, 'Grace');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := core.NewOsFS(dir)
+	if err := fs.Put("/queries/getUsers.gql",
+		[]byte(`query getUsers { users(order_by: { id: asc }) { id name } }`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var m *testMetrics
+	newGJ := func() *core.GraphJin {
+		m = &testMetrics{lookups: make(map[string][]bool)}
+		conf := &core.Config{DBType: "sqlite", Production: true, EnablePlanCache: true}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(fs), core.OptionSetMetrics(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// queries are compiled on first use and not at startup
+		if len(m.lookups) != 0 {
+			t.Fatalf("expected nothing compiled at startup, got %v", m.lookups)
+		}
+		return gj
+	}
+
+	gj := newGJ()
+	res, err := gj.GraphQLByName(context.Background(), "getUsers", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"users":[{"id":1,"name":"Ada"},{"id":2,"name":"Grace"}]}`; string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	if hits := m.lookups[core.CachePlan]; len(hits) != 1 || hits[0] {
+		t.Fatalf("expected a plan cache miss, got %v", hits)
+	}
+
+	// the plan of the role the query was compiled for is persisted
+	files, err := fs.List("/plans")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 persisted plan, got %d", len(files))
+	}
+
+	// rewrite the persisted sql to prove a restart uses it
+	for _, f := range files {
+		p := filepath.Join(dir, "plans", f)
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b = []byte(strings.Replace(string(b), `ASC LIMIT 20`, `DESC LIMIT 1`, 1))
+		if err := os.WriteFile(p, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gj = newGJ()
+	res, err = gj.GraphQLByName(context.Background(), "getUsers", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"users":[{"id":2,"name":"Grace"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected persisted plan to be used, got: %s", res.Data)
+	}
+	if hits := m.lookups[core.CachePlan]; len(hits) != 1 || !hits[0] {
+		t.Fatalf("expected a plan cache hit, got %v", hits)
+	}
+}
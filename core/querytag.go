@@ -0,0 +1,9 @@
+package core
+
+// queryTagsEnabled returns true if the SQL run on the database is tagged
+func (gj *graphjinEngine) queryTagsEnabled(dbName string) bool {
+	if dbConf, ok := gj.conf.Databases[dbName]; ok && dbConf.QueryTags != nil {
+		return *dbConf.QueryTags
+	}
+	return gj.conf.QueryTags
+}
@@ -0,0 +1,74 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestQueryTags(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'Lamp');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true, QueryTags: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		tag   string
+	}{
+		{`query getProducts { products { id name } }`, "/* action='getProducts',controller='graphql',framework='graphjin',role='anon' */ "},
+		{`query { products { id } }`, "/* action='',controller='graphql',framework='graphjin',role='anon' */ "},
+	}
+	for _, tt := range tests {
+		res, err := gj.GraphQL(context.Background(), tt.query, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(res.SQL(), tt.tag) {
+			t.Errorf("expected the sql to start with %q: %s", tt.tag, res.SQL())
+		}
+		if !strings.Contains(string(res.Data), `"id":1`) {
+			t.Errorf("unexpected data: %s", res.Data)
+		}
+	}
+
+	// query tags can be turned off per database
+	off := false
+	conf = &core.Config{DBType: "sqlite", DisableAllowList: true, QueryTags: true,
+		Databases: map[string]core.DatabaseConfig{
+			core.DefaultDBName: {Type: "sqlite", QueryTags: &off},
+		}}
+	gj, err = core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := gj.GraphQL(context.Background(), tests[0].query, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(res.SQL(), "role=") {
+		t.Errorf("expected the sql not to be tagged: %s", res.SQL())
+	}
+}
@@ -0,0 +1,23 @@
+package core
+
+import "errors"
+
+// ErrReadOnly is returned for mutations while GraphJin is in read-only mode
+var ErrReadOnly = errors.New("read-only mode: mutations are disabled")
+
+// SetReadOnly turns read-only mode on or off. In read-only mode mutations
+// fail with ErrReadOnly while queries keep working. The mode is kept when
+// GraphJin is reloaded
+func (g *GraphJin) SetReadOnly(readOnly bool) {
+	g.readOnly.Store(readOnly)
+}
+
+// ReadOnly returns true when GraphJin is in read-only mode
+func (g *GraphJin) ReadOnly() bool {
+	return g.readOnly.Load()
+}
+
+// isReadOnly returns true when mutations must be rejected
+func (gj *graphjinEngine) isReadOnly() bool {
+	return gj.readOnly != nil && gj.readOnly.Load()
+}
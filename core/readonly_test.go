@@ -0,0 +1,78 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestReadOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'Lamp');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `mutation { products(insert: $data) { id } }`
+	vars := []byte(`{"data": {"id": 2, "name": "Desk"}}`)
+
+	gj.SetReadOnly(true)
+	if !gj.ReadOnly() {
+		t.Fatal("expected read-only mode")
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+	if !errors.Is(err, core.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Message != core.ErrReadOnly.Error() {
+		t.Errorf("expected the error in the result, got %+v", res.Errors)
+	}
+
+	// queries keep working
+	if _, err := gj.GraphQL(context.Background(), `query { products { id } }`, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mode is kept across reloads
+	if err := gj.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gj.GraphQL(context.Background(), gql, vars, nil); !errors.Is(err, core.ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly after reload, got %v", err)
+	}
+
+	gj.SetReadOnly(false)
+	if _, err := gj.GraphQL(context.Background(), gql, vars, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := db.QueryRow(`SELECT count(*) FROM products`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 products, got %d", n)
+	}
+}
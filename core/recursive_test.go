@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRecursiveMaxDepthAndCycles(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	// 1 <- 2 <- 3 <- 4 is a chain, 10 and 11 reply to each other
+	_, err = db.Exec(`
+		CREATE TABLE comments (
+			id INTEGER PRIMARY KEY,
+			body TEXT,
+			reply_to_id INTEGER REFERENCES comments(id)
+		);
+		INSERT INTO comments (id, body, reply_to_id) VALUES
+			(1, 'a', NULL), (2, 'b', 1), (3, 'c', 2), (4, 'd', 3),
+			(10, 'x', 11), (11, 'y', 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(gql string) []int {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out struct {
+			Comments struct {
+				Replies []struct {
+					ID int `json:"id"`
+				} `json:"replies"`
+			} `json:"comments"`
+		}
+		if err := json.Unmarshal(res.Data, &out); err != nil {
+			t.Fatal(err)
+		}
+		var v []int
+		for _, r := range out.Comments.Replies {
+			v = append(v, r.ID)
+		}
+		return v
+	}
+
+	got := ids(`query { comments(id: 1) { id
+		replies: comments(find: "children", order_by: { id: asc }) { id } } }`)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 descendants, got %v", got)
+	}
+
+	got = ids(`query { comments(id: 1) { id
+		replies: comments(find: "children", max_depth: 2, order_by: { id: asc }) { id } } }`)
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("expected [2 3] with max_depth 2, got %v", got)
+	}
+
+	got = ids(`query { comments(id: 10) { id
+		replies: comments(find: "parents") { id } } }`)
+	if len(got) != 1 || got[0] != 11 {
+		t.Fatalf("expected the cycle to stop at [11], got %v", got)
+	}
+
+	_, err = gj.GraphQL(context.Background(),
+		`query { comments(max_depth: 2) { id } }`, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for max_depth without find")
+	}
+}
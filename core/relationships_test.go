@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDeclaredRelationships(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	// no foreign keys are defined in the database
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE products (id INTEGER PRIMARY KEY, owner_id INTEGER, name TEXT);
+		CREATE TABLE categories (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE product_categories (product_id INTEGER, category_id INTEGER);
+		INSERT INTO users (id, name) VALUES (1, 'Ada');
+		INSERT INTO products (id, owner_id, name) VALUES (1, 1, 'Lamp'), (2, 1, 'Desk');
+		INSERT INTO categories (id, name) VALUES (1, 'Lighting'), (2, 'Office');
+		INSERT INTO product_categories (product_id, category_id) VALUES (1, 1), (1, 2), (2, 2);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType: "sqlite",
+		Tables: []core.Table{{
+			Name: "products",
+			Relationships: []core.Relationship{
+				{Column: "owner_id", RelatedTo: "users.id"},
+				{
+					Type:           "many_to_many",
+					Column:         "id",
+					RelatedTo:      "categories.id",
+					Through:        "product_categories",
+					ThroughColumns: []string{"product_id", "category_id"},
+				},
+			},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query {
+		users {
+			name
+			products(order_by: { id: asc }) {
+				name
+				categories(order_by: { id: asc }) { name }
+			}
+		}
+	}`
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"users":[{"name":"Ada","products":[` +
+		`{"name":"Lamp","categories":[{"name":"Lighting"},{"name":"Office"}]},` +
+		`{"name":"Desk","categories":[{"name":"Office"}]}]}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+}
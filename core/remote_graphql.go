@@ -0,0 +1,175 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+)
+
+// remoteGraphQL struct defines a remote GraphQL endpoint. The ids of a
+// remote selection are looked up with a single query that aliases the
+// root field once for each id
+type remoteGraphQL struct {
+	httpClient *http.Client
+	URL        string
+	Field      string
+	Arg        string
+	Debug      bool
+
+	SetHeaders []remoteHdrs
+}
+
+// newRemoteGraphQL creates a new remote GraphQL endpoint
+func newRemoteGraphQL(v map[string]interface{}, httpClient *http.Client) (*remoteGraphQL, error) {
+	rg := remoteGraphQL{
+		httpClient: httpClient,
+		Arg:        "id",
+	}
+
+	if v, ok := v["url"].(string); ok {
+		rg.URL = v
+	}
+	if v, ok := v["field"].(string); ok {
+		rg.Field = v
+	}
+	if v, ok := v["arg"].(string); ok && v != "" {
+		rg.Arg = v
+	}
+	if v, ok := v["debug"].(bool); ok {
+		rg.Debug = v
+	}
+	if v, ok := v["set_headers"].(map[string]string); ok {
+		for k, v1 := range v {
+			rh := remoteHdrs{Name: k, Value: v1}
+			rg.SetHeaders = append(rg.SetHeaders, rh)
+		}
+	}
+
+	if rg.URL == "" {
+		return nil, errors.New("remote_graphql: url required")
+	}
+	if rg.Field == "" {
+		return nil, errors.New("remote_graphql: field required")
+	}
+	return &rg, nil
+}
+
+// Resolve function resolves a remote GraphQL request for a single id
+func (r *remoteGraphQL) Resolve(c context.Context, rr ResolverReq) ([]byte, error) {
+	res, err := r.ResolveBatch(c, []ResolverReq{rr})
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// ResolveBatch function resolves the ids of a remote selection with a
+// single GraphQL query
+func (r *remoteGraphQL) ResolveBatch(c context.Context, reqs []ResolverReq) ([][]byte, error) {
+	var q bytes.Buffer
+	aliases := make(map[string]string, len(reqs))
+
+	q.WriteString("query {")
+	for _, rr := range reqs {
+		if _, ok := aliases[rr.ID]; ok {
+			continue
+		}
+		alias := "_" + strconv.Itoa(len(aliases))
+		aliases[rr.ID] = alias
+
+		q.WriteString(" " + alias + ": " + r.Field + "(" + r.Arg + ": ")
+		q.WriteString(remoteGraphQLID(rr))
+		q.WriteString(")")
+		if rr.Sel != nil && rr.Sel.RemoteQuery != "" {
+			q.WriteString(" { " + rr.Sel.RemoteQuery + " }")
+		}
+	}
+	q.WriteString(" }")
+
+	body, err := json.Marshal(map[string]string{"query": q.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(c, "POST", r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	for _, v := range r.SetHeaders {
+		req.Header.Set(v.Name, v.Value)
+	}
+
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to '%s': %v", r.URL, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if r.Debug {
+		reqDump, err := httputil.DumpRequestOut(req, true)
+		if err != nil {
+			return nil, err
+		}
+
+		resDump, err := httputil.DumpResponse(res, true)
+		if err != nil {
+			return nil, err
+		}
+
+		reqs[0].Log.Printf("DBG Remote GraphQL Request:\n%s\n%s",
+			reqDump, resDump)
+	}
+
+	if res.StatusCode != 200 {
+		return nil,
+			fmt.Errorf("server responded with a %d", res.StatusCode)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var gr struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &gr); err != nil {
+		return nil, err
+	}
+	if len(gr.Errors) != 0 {
+		return nil, errors.New(gr.Errors[0].Message)
+	}
+
+	vals := make([][]byte, len(reqs))
+	for i, rr := range reqs {
+		if v, ok := gr.Data[aliases[rr.ID]]; ok {
+			vals[i] = v
+		} else {
+			vals[i] = []byte("null")
+		}
+	}
+	return vals, nil
+}
+
+// remoteGraphQLID returns the id as a GraphQL literal, ids of numeric
+// columns are sent as numbers and all others as strings
+func remoteGraphQLID(rr ResolverReq) string {
+	if rr.Sel != nil && isNumericType(rr.Sel.Rel.Right.Col.Type) {
+		if _, err := strconv.ParseFloat(rr.ID, 64); err == nil {
+			return rr.ID
+		}
+	}
+	b, _ := json.Marshal(rr.ID)
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package core_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRemoteGraphQL(t *testing.T) {
+	var calls int32
+	var query string
+
+	// answers every aliased customer(id: N) field with a customer
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query = req.Query
+
+		var data []string
+		re := regexp.MustCompile(`(_\d+): customer\(id: (\d+)\)`)
+		for _, m := range re.FindAllStringSubmatch(req.Query, -1) {
+			data = append(data, fmt.Sprintf(`"%s":{"name":"customer %s","email":"c%s@example.com"}`,
+				m[1], m[2], m[2]))
+		}
+		fmt.Fprintf(w, `{"data": {%s}}`, strings.Join(data, ", "))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, customer_id INTEGER);
+		INSERT INTO products (id, customer_id) VALUES (1, 7), (2, 8), (3, 7);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	conf.Resolvers = []core.ResolverConfig{{
+		Name:   "customer",
+		Type:   "remote_graphql",
+		Table:  "products",
+		Column: "customer_id",
+		Props:  core.ResolverProps{"url": srv.URL, "field": "customer"},
+	}}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query {
+		products(order_by: { id: asc }) { id customer { name } }
+	}`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `{"products":[` +
+		`{"id":1,"customer":{"name":"customer 7"}},` +
+		`{"id":2,"customer":{"name":"customer 8"}},` +
+		`{"id":3,"customer":{"name":"customer 7"}}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected the customers to be fetched with one request, got %d", n)
+	}
+	if strings.Count(query, "customer(") != 2 || !strings.Contains(query, "{ name }") {
+		t.Fatalf("expected one lookup per customer with the selection, got %s", query)
+	}
+	if res.Extensions != nil && len(res.Extensions.NPlusOne) != 0 {
+		t.Fatalf("expected no n+1 warning for a batched remote join, got %+v", res.Extensions.NPlusOne)
+	}
+}
@@ -28,6 +28,10 @@ func (s *gstate) execRemoteJoin(c context.Context) (err error) {
 		err = errors.New("something wrong no remote ids found in db response")
 		return
 	}
+	s.countCalls(from, sfmap)
+
+	c, cancel := s.budget.context(c)
+	defer cancel()
 
 	to, err := s.resolveRemotes(c, from, sfmap)
 	if err != nil {
@@ -42,7 +46,8 @@ func (s *gstate) execRemoteJoin(c context.Context) (err error) {
 	return
 }
 
-// resolveRemotes fetches remote data for the marked insertion points
+// resolveRemotes fetches remote data for the marked insertion points,
+// the ids of a select using a batch resolver are fetched with one request
 func (s *gstate) resolveRemotes(
 	ctx context.Context,
 	from []jsn.Field,
@@ -55,11 +60,21 @@ func (s *gstate) resolveRemotes(
 	to := make([]jsn.Field, len(from))
 
 	var wg sync.WaitGroup
-	wg.Add(len(from))
 
 	var cerr error
 	var cerrMutex sync.Mutex
 
+	setErr := func(err error) {
+		cerrMutex.Lock()
+		cerr = err
+		cerrMutex.Unlock()
+	}
+
+	// insertion points of each select using a batch resolver
+	batches := make(map[*qcode.Select][]int)
+	var batchSels []*qcode.Select
+	ids := make([][]byte, len(from))
+
 	for i, id := range from {
 		// use the json key to find the related Select object
 		sel, ok := sfmap[string(id.Key)]
@@ -79,7 +94,23 @@ func (s *gstate) resolveRemotes(
 		if len(id) == 0 {
 			return nil, fmt.Errorf("invalid remote field id")
 		}
+		ids[i] = id
 
+		if _, ok := r.Fn.(BatchResolver); ok {
+			if _, ok := batches[sel]; !ok {
+				batchSels = append(batchSels, sel)
+			}
+			batches[sel] = append(batches[sel], i)
+			continue
+		}
+
+		// Stop fanning out once the budget is used up
+		if err := s.budget.acquire(); err != nil {
+			to[i] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+			continue
+		}
+
+		wg.Add(1)
 		go func(n int, id []byte, sel *qcode.Select) {
 			defer wg.Done()
 
@@ -90,12 +121,16 @@ func (s *gstate) resolveRemotes(
 			b, err := r.Fn.Resolve(ctx1, ResolverReq{
 				ID: string(id), Sel: sel, Log: s.gj.log, RequestConfig: s.r.requestconfig,
 			})
+			if err = s.budget.check(err); errors.Is(err, ErrBudgetExceeded) {
+				to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+				span.Error(err)
+				span.End()
+				return
+			}
 			if err != nil {
-				cerrMutex.Lock()
-				cerr = fmt.Errorf("%s: %s", sel.Table, err)
-				spanErr := cerr
-				cerrMutex.Unlock()
-				span.Error(spanErr)
+				err = fmt.Errorf("%s: %s", sel.Table, err)
+				setErr(err)
+				span.Error(err)
 			}
 			span.End()
 
@@ -103,32 +138,98 @@ func (s *gstate) resolveRemotes(
 				return
 			}
 
-			if len(r.Path) != 0 {
-				b = jsn.Strip(b, r.Path)
+			if to[n], err = s.remoteValue(r, sel, b); err != nil {
+				setErr(err)
 			}
+		}(i, id, sel)
+	}
 
-			var ob bytes.Buffer
+	for _, sel := range batchSels {
+		idx := batches[sel]
+		r := s.gj.rmap[(sel.Table + selects[sel.ParentID].Table)]
 
-			if len(sel.Fields) != 0 {
-				err = jsn.Filter(&ob, b, fieldsToList(sel.Fields))
-				if err != nil {
-					cerrMutex.Lock()
-					cerr = fmt.Errorf("%s: %w", sel.Table, err)
-					cerrMutex.Unlock()
-					return
+		// a batch is a single remote request
+		if err := s.budget.acquire(); err != nil {
+			for _, n := range idx {
+				to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx []int, sel *qcode.Select) {
+			defer wg.Done()
+
+			ctx1, span := s.gj.spanStart(ctx, "Execute Remote Batch Request")
+
+			reqs := make([]ResolverReq, len(idx))
+			for i, n := range idx {
+				reqs[i] = ResolverReq{
+					ID: string(ids[n]), Sel: sel, Log: s.gj.log, RequestConfig: s.r.requestconfig,
+				}
+			}
+
+			res, err := r.Fn.(BatchResolver).ResolveBatch(ctx1, reqs)
+			if err == nil && len(res) != len(reqs) {
+				err = fmt.Errorf("resolver returned %d values for %d ids", len(res), len(reqs))
+			}
+			if err = s.budget.check(err); errors.Is(err, ErrBudgetExceeded) {
+				for _, n := range idx {
+					to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
 				}
+				span.Error(err)
+				span.End()
+				return
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %s", sel.Table, err)
+				setErr(err)
+				span.Error(err)
+			}
+			span.End()
 
-			} else {
-				ob.WriteString("null")
+			if err != nil {
+				return
 			}
 
-			to[n] = jsn.Field{Key: []byte(sel.FieldName), Value: ob.Bytes()}
-		}(i, id, sel)
+			for i, n := range idx {
+				if to[n], err = s.remoteValue(r, sel, res[i]); err != nil {
+					setErr(err)
+					return
+				}
+			}
+		}(idx, sel)
 	}
 	wg.Wait()
 	return to, cerr
 }
 
+// remoteValue returns the fields selected from the remote data as the
+// replacement for an insertion point
+func (s *gstate) remoteValue(r resItem, sel *qcode.Select, b []byte) (jsn.Field, error) {
+	null := jsn.Field{Key: []byte(sel.FieldName), Value: []byte("null")}
+
+	if len(r.Path) != 0 {
+		b = jsn.Strip(b, r.Path)
+	}
+
+	var ob bytes.Buffer
+
+	if len(sel.Fields) != 0 {
+		if err := jsn.Filter(&ob, b, fieldsToList(sel.Fields)); err != nil {
+			return null, fmt.Errorf("%s: %w", sel.Table, err)
+		}
+	} else {
+		ob.WriteString("null")
+	}
+
+	if err := s.budget.addRows(ob.Bytes(), false); err != nil {
+		return null, nil
+	}
+
+	return jsn.Field{Key: []byte(sel.FieldName), Value: ob.Bytes()}, nil
+}
+
 // parentFieldIds fetches the field name used within the db response json
 func (s *gstate) parentFieldIds() ([][]byte, map[string]*qcode.Select, error) {
 	selects := s.cs.st.qc.Selects
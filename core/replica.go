@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// replicaSet holds the read replicas of a database and the hedging counters
+type replicaSet struct {
+	dbs       []*sql.DB
+	next      atomic.Uint64
+	reads     atomic.Int64
+	hedged    atomic.Int64
+	hedgeWins atomic.Int64
+}
+
+// ReplicaStats represents read replica and hedging statistics for a database
+type ReplicaStats struct {
+	Count     int     `json:"count"`
+	Reads     int64   `json:"reads"`
+	Hedged    int64   `json:"hedged"`
+	HedgeWins int64   `json:"hedgeWins"`
+	HedgeRate float64 `json:"hedgeRate"`
+}
+
+// OptionSetReadReplicas sets read replicas for a database. Queries run outside a
+// transaction are sent to the replicas in turn and hedged using the hedge_delay
+// of the database. Use DefaultDBName for the default database.
+func OptionSetReadReplicas(database string, replicas ...*sql.DB) Option {
+	return func(gj *graphjinEngine) error {
+		if len(replicas) == 0 {
+			return nil
+		}
+		if gj.replicas == nil {
+			gj.replicas = make(map[string]*replicaSet)
+		}
+		gj.replicas[database] = &replicaSet{dbs: replicas}
+		return nil
+	}
+}
+
+// readReplicas returns the replicas to run the query on or nil when it has to
+// run on the primary
+func (s *gstate) readReplicas() *replicaSet {
+	if len(s.gj.replicas) == 0 || s.r.operation != qcode.QTQuery ||
+		s.tx() != nil || s.gj.conf.SetUserID {
+		return nil
+	}
+	return s.gj.replicas[s.getTargetDBCtx().name]
+}
+
+// read runs the query on the next replica. When it has not answered after the
+// delay the query is also sent to the following replica and the first result
+// returned is used
+func (rs *replicaSet) read(c context.Context, delay time.Duration, query string, args []interface{}) ([]byte, error) {
+	rs.reads.Add(1)
+
+	n := uint64(len(rs.dbs))
+	i := (rs.next.Add(1) - 1) % n
+
+	if delay <= 0 || n == 1 {
+		return queryRowData(c, rs.dbs[i], query, args)
+	}
+
+	c, cancel := context.WithCancel(c)
+	defer cancel()
+
+	type result struct {
+		data  []byte
+		err   error
+		hedge bool
+	}
+	ch := make(chan result, 2)
+
+	run := func(db *sql.DB, hedge bool) {
+		data, err := queryRowData(c, db, query, args)
+		ch <- result{data: data, err: err, hedge: hedge}
+	}
+	go run(rs.dbs[i], false)
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case r := <-ch:
+		return r.data, r.err
+	case <-t.C:
+		rs.hedged.Add(1)
+		go run(rs.dbs[(i+1)%n], true)
+	}
+
+	// use the first success, or the last error when both fail
+	var err error
+	for pending := 2; pending > 0; pending-- {
+		r := <-ch
+		if r.err == nil || r.err == sql.ErrNoRows {
+			if r.hedge {
+				rs.hedgeWins.Add(1)
+			}
+			return r.data, r.err
+		}
+		err = r.err
+	}
+	return nil, err
+}
+
+func (rs *replicaSet) stats() *ReplicaStats {
+	st := &ReplicaStats{
+		Count:     len(rs.dbs),
+		Reads:     rs.reads.Load(),
+		Hedged:    rs.hedged.Load(),
+		HedgeWins: rs.hedgeWins.Load(),
+	}
+	if st.Reads != 0 {
+		st.HedgeRate = float64(st.Hedged) / float64(st.Reads)
+	}
+	return st
+}
+
+func queryRowData(c context.Context, db *sql.DB, query string, args []interface{}) (data []byte, err error) {
+	err = db.QueryRowContext(c, query, args...).Scan(&data)
+	return
+}
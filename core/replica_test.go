@@ -0,0 +1,118 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestReadReplicasHedging(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+		db.SetMaxOpenConns(1)
+
+		_, err = db.Exec(`
+			CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+			INSERT INTO products (id, name) VALUES (1, '` + name + `');
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	primary := openDB("primary")
+	slow := openDB("slow")
+	fast := openDB("fast")
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			core.DefaultDBName: {Type: "sqlite", HedgeDelay: 10 * time.Millisecond},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, primary,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetReadReplicas(core.DefaultDBName, slow, fast))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := func() string {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), `query { products { name } }`, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data struct{ Products []struct{ Name string } }
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if len(data.Products) != 1 {
+			t.Fatalf("unexpected result: %s", res.Data)
+		}
+		return data.Products[0].Name
+	}
+
+	// discovery reads from the replicas too, line up the next read with the
+	// first replica
+	before := gj.GetAllDatabaseStats()[0].Replicas
+	if before.Reads%2 == 1 {
+		query()
+		before = gj.GetAllDatabaseStats()[0].Replicas
+	}
+
+	// hold the only connection of the first replica so reads on it stall
+	conn, err := slow.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := query()
+	conn.Close() //nolint:errcheck
+
+	if name != "fast" {
+		t.Fatalf("expected the hedged read from the second replica, got %s", name)
+	}
+
+	// the next read goes to the second replica without hedging
+	if name := query(); name != "fast" {
+		t.Fatalf("expected a read from the second replica, got %s", name)
+	}
+
+	// replica reads do not wait for a connection to the primary
+	pconn, err := primary.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, cancel := context.WithTimeout(context.Background(), time.Second)
+	_, err = gj.GraphQL(c, `query { products { name } }`, nil, nil)
+	cancel()
+	pconn.Close() //nolint:errcheck
+	if err != nil {
+		t.Fatalf("expected the read to skip the busy primary, got %v", err)
+	}
+
+	stats := gj.GetAllDatabaseStats()
+	if len(stats) != 1 || stats[0].Replicas == nil {
+		t.Fatalf("expected replica stats, got %+v", stats)
+	}
+	rs := stats[0].Replicas
+	if rs.Count != 2 || rs.Reads-before.Reads != 3 || rs.Hedged-before.Hedged != 1 ||
+		rs.HedgeWins-before.HedgeWins != 1 || rs.HedgeRate == 0 {
+		t.Fatalf("unexpected replica stats: %+v", rs)
+	}
+}
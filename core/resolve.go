@@ -21,6 +21,9 @@ func (gj *graphjinEngine) newRTMap() map[string]ResolverFn {
 		"remote_api": func(v ResolverProps) (Resolver, error) {
 			return newRemoteAPI(v, gj.trace.NewHTTPClient())
 		},
+		"remote_graphql": func(v ResolverProps) (Resolver, error) {
+			return newRemoteGraphQL(v, gj.trace.NewHTTPClient())
+		},
 	}
 }
 
@@ -0,0 +1,347 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// ErrResponseTooLarge is returned when a response is over max_response_bytes
+// and truncation is disabled or cannot bring it under the limit
+var ErrResponseTooLarge = errors.New("response too large")
+
+// Extensions holds the GraphQL response extensions
+type Extensions struct {
+	// Truncated is set when list fields were trimmed to fit max_response_bytes
+	Truncated bool `json:"truncated,omitempty"`
+
+	// RolledBack lists the @optional mutation branches that failed and were
+	// rolled back while the rest of the mutation was committed
+	RolledBack []string `json:"rolledBack,omitempty"`
+
+	// NPlusOne lists the parts of the query that fan out per row, only
+	// set in development mode
+	NPlusOne []NPlusOneWarning `json:"nPlusOne,omitempty"`
+
+	// Omitted lists the tables and fields left out of the result by
+	// authorization rules, only set with auth_trace in development mode
+	Omitted []OmittedField `json:"omitted,omitempty"`
+
+	// Pending is the id of the mutation staged until it is approved
+	Pending string `json:"pending,omitempty"`
+}
+
+// checkResponseSize enforces max_response_bytes on the response data by
+// trimming list fields or failing with ErrResponseTooLarge
+func (s *gstate) checkResponseSize() error {
+	limit, truncate := s.maxResponseBytes()
+	if limit <= 0 || len(s.data) <= limit {
+		return nil
+	}
+
+	if truncate {
+		if data, ok := trimJSON(s.data, limit); ok && len(data) <= limit {
+			s.data = data
+			s.truncated = true
+			s.skipCache = true
+			return nil
+		}
+	}
+
+	n := len(s.data)
+	s.data = nil
+	return responseTooLarge(n, limit)
+}
+
+func responseTooLarge(n, limit int) error {
+	return fmt.Errorf("%w: %d bytes is over the max of %d bytes", ErrResponseTooLarge, n, limit)
+}
+
+// tooLargeKey is the key of the object the database returns in place of a
+// response over max_response_bytes, GraphQL fields cannot start with __
+const tooLargeKey = "__gj_too_large"
+
+// limitResponseSQL wraps a query so the database checks the size of the
+// response and returns only its size when it is over max_response_bytes,
+// this way a response over the limit is never read from the driver. With
+// truncation the whole response is needed to trim it, databases that cannot
+// wrap the query in a derived table are checked once the response is read
+func (s *gstate) limitResponseSQL(dbType, query string) string {
+	limit, truncate := s.maxResponseBytes()
+	if limit <= 0 || truncate || s.cs.st.qc.Type != qcode.QTQuery {
+		return query
+	}
+
+	var size, obj string
+	switch dbType {
+	case "postgres":
+		size = `octet_length(__gj_res.__root::text)`
+		obj = `json_build_object('` + tooLargeKey + `', `
+	case "mysql", "mariadb":
+		size = `LENGTH(__gj_res.__root)`
+		obj = `JSON_OBJECT('` + tooLargeKey + `', `
+	case "sqlite":
+		size = `length(CAST(__gj_res.__root AS BLOB))`
+		obj = `json_object('` + tooLargeKey + `', `
+	default:
+		return query
+	}
+
+	// comments like the pg_hint_plan hints must stay in front
+	i := leadingCommentsEnd(query)
+
+	var sb strings.Builder
+	sb.WriteString(query[:i])
+	sb.WriteString(`SELECT CASE WHEN `)
+	sb.WriteString(size)
+	sb.WriteString(` > `)
+	sb.WriteString(strconv.Itoa(limit))
+	sb.WriteString(` THEN `)
+	sb.WriteString(obj)
+	sb.WriteString(size)
+	sb.WriteString(`) ELSE __gj_res.__root END FROM (`)
+	sb.WriteString(query[i:])
+	sb.WriteString(`) AS __gj_res`)
+	return sb.String()
+}
+
+// leadingCommentsEnd returns the offset of the statement after the block
+// comments it starts with
+func leadingCommentsEnd(query string) int {
+	i := 0
+	for {
+		rest := strings.TrimLeft(query[i:], " \t\n\r")
+		if !strings.HasPrefix(rest, "/*") {
+			return i
+		}
+		n := strings.Index(rest, "*/")
+		if n == -1 {
+			return i
+		}
+		i = len(query) - len(rest) + n + len("*/")
+		for i < len(query) && query[i] == ' ' {
+			i++
+		}
+	}
+}
+
+// checkLimitedResponse fails with ErrResponseTooLarge when the database
+// returned the size of the response in place of a response over the limit
+func (s *gstate) checkLimitedResponse() error {
+	if !bytes.HasPrefix(s.data, []byte(`{"`+tooLargeKey+`"`)) {
+		return nil
+	}
+	var v map[string]int
+	if err := json.Unmarshal(s.data, &v); err != nil {
+		return err
+	}
+	limit, _ := s.maxResponseBytes()
+	s.data = nil
+	return responseTooLarge(v[tooLargeKey], limit)
+}
+
+// trimJSON copies the JSON value dropping the trailing items of arrays once
+// the output would go over limit bytes. Objects keep all their keys so the
+// output may still be over the limit when the scalars alone are too large
+func trimJSON(data []byte, limit int) ([]byte, bool) {
+	t := jsonTrimmer{in: data, limit: limit}
+	t.out.Grow(limit)
+	if !t.value(0) {
+		return nil, false
+	}
+	return t.out.Bytes(), t.truncated
+}
+
+type jsonTrimmer struct {
+	in        []byte
+	pos       int
+	out       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// value copies the next value, depth is the number of open arrays and objects
+// whose closing brackets still have to fit
+func (t *jsonTrimmer) value(depth int) bool {
+	t.skipSpace()
+	if t.pos >= len(t.in) {
+		return false
+	}
+	switch t.in[t.pos] {
+	case '[':
+		return t.array(depth)
+	case '{':
+		return t.object(depth)
+	default:
+		s := t.pos
+		if !t.skipValue() {
+			return false
+		}
+		t.out.Write(t.in[s:t.pos])
+		return true
+	}
+}
+
+func (t *jsonTrimmer) array(depth int) bool {
+	t.pos++
+	t.out.WriteByte('[')
+
+	for i := 0; ; i++ {
+		t.skipSpace()
+		if t.pos >= len(t.in) {
+			return false
+		}
+		if t.in[t.pos] == ']' {
+			break
+		}
+		if i != 0 {
+			if t.in[t.pos] != ',' {
+				return false
+			}
+			t.pos++
+		}
+
+		mark := t.out.Len()
+		if i != 0 {
+			t.out.WriteByte(',')
+		}
+		if !t.value(depth + 1) {
+			return false
+		}
+
+		// drop this item and the rest of the array when it does not fit
+		if t.out.Len()+depth+1 > t.limit {
+			t.out.Truncate(mark)
+			t.truncated = true
+			if !t.skipRest(']') {
+				return false
+			}
+			break
+		}
+	}
+	t.pos++
+	t.out.WriteByte(']')
+	return true
+}
+
+func (t *jsonTrimmer) object(depth int) bool {
+	t.pos++
+	t.out.WriteByte('{')
+
+	for i := 0; ; i++ {
+		t.skipSpace()
+		if t.pos >= len(t.in) {
+			return false
+		}
+		if t.in[t.pos] == '}' {
+			break
+		}
+		if i != 0 {
+			if t.in[t.pos] != ',' {
+				return false
+			}
+			t.pos++
+			t.out.WriteByte(',')
+			t.skipSpace()
+		}
+
+		// key
+		s := t.pos
+		if t.pos >= len(t.in) || t.in[t.pos] != '"' || !t.skipValue() {
+			return false
+		}
+		t.out.Write(t.in[s:t.pos])
+
+		t.skipSpace()
+		if t.pos >= len(t.in) || t.in[t.pos] != ':' {
+			return false
+		}
+		t.pos++
+		t.out.WriteByte(':')
+
+		if !t.value(depth + 1) {
+			return false
+		}
+	}
+	t.pos++
+	t.out.WriteByte('}')
+	return true
+}
+
+// skipRest skips the remaining items of an array or object up to the
+// closing bracket
+func (t *jsonTrimmer) skipRest(end byte) bool {
+	for {
+		t.skipSpace()
+		if t.pos >= len(t.in) {
+			return false
+		}
+		switch t.in[t.pos] {
+		case end:
+			return true
+		case ',', ':':
+			t.pos++
+		default:
+			if !t.skipValue() {
+				return false
+			}
+		}
+	}
+}
+
+// skipValue moves past the next value without copying it
+func (t *jsonTrimmer) skipValue() bool {
+	t.skipSpace()
+	if t.pos >= len(t.in) {
+		return false
+	}
+	switch c := t.in[t.pos]; c {
+	case '"':
+		for t.pos++; t.pos < len(t.in); t.pos++ {
+			switch t.in[t.pos] {
+			case '\\':
+				t.pos++
+			case '"':
+				t.pos++
+				return true
+			}
+		}
+		return false
+	case '[', '{':
+		end := byte(']')
+		if c == '{' {
+			end = '}'
+		}
+		t.pos++
+		if !t.skipRest(end) {
+			return false
+		}
+		t.pos++
+		return true
+	default:
+		for t.pos < len(t.in) {
+			switch t.in[t.pos] {
+			case ',', ']', '}', ' ', '\t', '\n', '\r':
+				return true
+			}
+			t.pos++
+		}
+		return true
+	}
+}
+
+func (t *jsonTrimmer) skipSpace() {
+	for t.pos < len(t.in) {
+		switch t.in[t.pos] {
+		case ' ', '\t', '\n', '\r':
+			t.pos++
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLimitResponseSQL(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	s := &gstate{
+		gj: &graphjinEngine{conf: &Config{MaxResponseBytes: 16}},
+		cs: &cstate{st: stmt{qc: &qcode.QCode{Type: qcode.QTQuery}}},
+	}
+	query := `/*+ hint */ /* action='q' */ SELECT json_object('a', ?) AS "__root"`
+
+	wrapped := s.limitResponseSQL("sqlite", query)
+	if exp := `/*+ hint */ /* action='q' */ SELECT CASE`; wrapped[:len(exp)] != exp {
+		t.Fatalf("expected the comments to stay in front: %s", wrapped)
+	}
+
+	tests := []struct {
+		val string
+		exp string
+		err bool
+	}{
+		{"short", `{"a":"short"}`, false},
+		{"a much longer value", "", true},
+	}
+	for _, tt := range tests {
+		s.data = nil
+		if err := db.QueryRow(wrapped, tt.val).Scan(&s.data); err != nil {
+			t.Fatal(err)
+		}
+		err := s.checkLimitedResponse()
+		if tt.err {
+			if !errors.Is(err, ErrResponseTooLarge) || s.data != nil {
+				t.Errorf("%s: expected a response too large error, got %v %s", tt.val, err, s.data)
+			}
+			continue
+		}
+		if err != nil || string(s.data) != tt.exp {
+			t.Errorf("%s: expected %s, got %s %v", tt.val, tt.exp, s.data, err)
+		}
+	}
+
+	// truncated responses are read in full so they can be trimmed
+	s.gj.conf.TruncateResponses = true
+	if v := s.limitResponseSQL("sqlite", query); v != query {
+		t.Errorf("expected the query to be left as is with truncation: %s", v)
+	}
+}
@@ -0,0 +1,94 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestMaxResponseBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES
+			(1, 'aaaaaaaaaa'), (2, 'bbbbbbbbbb'), (3, 'cccccccccc'),
+			(4, 'dddddddddd'), (5, 'eeeeeeeeee');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newGJ := func(maxBytes int, truncate bool) *core.GraphJin {
+		conf := &core.Config{
+			DBType:            "sqlite",
+			DisableAllowList:  true,
+			MaxResponseBytes:  maxBytes,
+			TruncateResponses: truncate,
+		}
+		gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gj
+	}
+
+	gql := `query { products(order_by: { id: asc }) { id name } }`
+
+	res, err := newGJ(0, false).GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := len(res.Data)
+
+	// over the limit without truncation fails
+	res, err = newGJ(full-1, false).GraphQL(context.Background(), gql, nil, nil)
+	if !errors.Is(err, core.ErrResponseTooLarge) {
+		t.Fatalf("expected a response too large error, got %v", err)
+	}
+	if len(res.Data) != 0 {
+		t.Fatalf("expected no data, got %s", res.Data)
+	}
+
+	// with truncation the list is trimmed to fit
+	limit := full / 2
+	res, err = newGJ(limit, true).GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) > limit {
+		t.Fatalf("expected at most %d bytes, got %d", limit, len(res.Data))
+	}
+	if res.Extensions == nil || !res.Extensions.Truncated {
+		t.Fatal("expected the truncated extension")
+	}
+
+	var data struct{ Products []struct{ ID int } }
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		t.Fatalf("expected valid json, got %s: %s", res.Data, err)
+	}
+	if n := len(data.Products); n == 0 || n >= 5 || data.Products[0].ID != 1 {
+		t.Fatalf("expected the first rows, got %s", res.Data)
+	}
+
+	// under the limit the response is left as is
+	res, err = newGJ(full, true).GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Extensions != nil || len(res.Data) != full {
+		t.Fatalf("expected the full response, got %s", res.Data)
+	}
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// maxResultDiffs bounds the differences returned for a pair of results
+const maxResultDiffs = 100
+
+// Kinds of differences between two results
+const (
+	DiffMissing = "missing" // key only in the left result
+	DiffExtra   = "extra"   // key only in the right result
+	DiffType    = "type"    // values of different json types
+	DiffLength  = "length"  // lists of different lengths
+	DiffOrder   = "order"   // lists with the same items in a different order
+	DiffValue   = "value"   // scalar values that are not equal
+)
+
+// ResultDiff is a difference between two results at a path like
+// $.users[0].email
+type ResultDiff struct {
+	Path  string `json:"path"`
+	Kind  string `json:"kind"`
+	Left  string `json:"left,omitempty"`
+	Right string `json:"right,omitempty"`
+}
+
+// DiffResults compares two json results and returns their differences,
+// the keys of objects are compared in sorted order. The items of lists are
+// compared pairwise unless the lists hold the same items in a different
+// order, which is reported as one difference. At most 100 differences are
+// returned.
+func DiffResults(left, right json.RawMessage) ([]ResultDiff, error) {
+	var lv, rv interface{}
+	if err := json.Unmarshal(left, &lv); err != nil {
+		return nil, fmt.Errorf("left result: %w", err)
+	}
+	if err := json.Unmarshal(right, &rv); err != nil {
+		return nil, fmt.Errorf("right result: %w", err)
+	}
+
+	var d resultDiffer
+	d.compare("$", lv, rv)
+	return d.diffs, nil
+}
+
+// DiffQuery runs a saved query on two of the configured databases and
+// returns the differences between the results, the left database is the
+// reference. Only queries can be compared.
+func (g *GraphJin) DiffQuery(c context.Context,
+	name string,
+	vars json.RawMessage,
+	rc *RequestConfig,
+	left, right string,
+) ([]ResultDiff, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	r := gj.newGraphqlReq(rc, "", name, nil, vars)
+	item, err := gj.getAllowItem(r.namespace, name, gj.prod)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+	if qcode.GetQTypeByName(item.Operation) != qcode.QTQuery {
+		return nil, fmt.Errorf("%s: only queries can be compared", name)
+	}
+
+	run := func(db string) (json.RawMessage, error) {
+		var rc1 RequestConfig
+		if rc != nil {
+			rc1 = *rc
+		}
+		rc1.SetDatabase(db)
+
+		res, err := g.GraphQLByName(c, name, vars, &rc1)
+		if err == nil && len(res.Errors) != 0 {
+			err = fmt.Errorf("%s", res.Errors[0].Message)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("database %s: %w", db, err)
+		}
+		return res.Data, nil
+	}
+
+	ld, err := run(left)
+	if err != nil {
+		return nil, err
+	}
+	rd, err := run(right)
+	if err != nil {
+		return nil, err
+	}
+	return DiffResults(ld, rd)
+}
+
+type resultDiffer struct {
+	diffs []ResultDiff
+}
+
+func (d *resultDiffer) add(path, kind string, left, right interface{}) {
+	if len(d.diffs) >= maxResultDiffs {
+		return
+	}
+	rd := ResultDiff{Path: path, Kind: kind}
+	if left != nil {
+		rd.Left = fmt.Sprint(left)
+	}
+	if right != nil {
+		rd.Right = fmt.Sprint(right)
+	}
+	d.diffs = append(d.diffs, rd)
+}
+
+func (d *resultDiffer) compare(path string, lv, rv interface{}) {
+	if len(d.diffs) >= maxResultDiffs {
+		return
+	}
+	if lt, rt := jsonTypeName(lv), jsonTypeName(rv); lt != rt {
+		d.add(path, DiffType, lt, rt)
+		return
+	}
+
+	switch l := lv.(type) {
+	case map[string]interface{}:
+		r := rv.(map[string]interface{})
+		keys := make([]string, 0, len(l)+len(r))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		for k := range r {
+			if _, ok := l[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			p := path + "." + k
+			v1, ok1 := l[k]
+			v2, ok2 := r[k]
+			switch {
+			case !ok2:
+				d.add(p, DiffMissing, nil, nil)
+			case !ok1:
+				d.add(p, DiffExtra, nil, nil)
+			default:
+				d.compare(p, v1, v2)
+			}
+		}
+
+	case []interface{}:
+		r := rv.([]interface{})
+		if len(l) != len(r) {
+			d.add(path, DiffLength, len(l), len(r))
+		} else if sameItems(l, r) {
+			if !sameOrder(l, r) {
+				d.add(path, DiffOrder, nil, nil)
+			}
+			return
+		}
+		for i := 0; i < min(len(l), len(r)); i++ {
+			d.compare(fmt.Sprintf("%s[%d]", path, i), l[i], r[i])
+		}
+
+	default:
+		if lv != rv {
+			d.add(path, DiffValue, lv, rv)
+		}
+	}
+}
+
+// sameItems returns true when both lists hold the same items in any order
+func sameItems(l, r []interface{}) bool {
+	count := make(map[string]int, len(l))
+	for _, v := range l {
+		count[itemKey(v)]++
+	}
+	for _, v := range r {
+		k := itemKey(v)
+		if count[k] == 0 {
+			return false
+		}
+		count[k]--
+	}
+	return true
+}
+
+// sameOrder returns true when both lists hold the same items in the same order
+func sameOrder(l, r []interface{}) bool {
+	for i := range l {
+		if itemKey(l[i]) != itemKey(r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// itemKey returns the json of a decoded value, object keys are sorted
+func itemKey(v interface{}) string {
+	var b bytes.Buffer
+	_ = json.NewEncoder(&b).Encode(v)
+	return b.String()
+}
+
+// jsonTypeName returns the name of the json type of a decoded value
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
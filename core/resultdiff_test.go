@@ -0,0 +1,117 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDiffResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right string
+		exp         []core.ResultDiff
+	}{
+		{"same", `{"users":[{"id":1,"name":"a"}]}`, `{"users":[{"name":"a","id":1}]}`, nil},
+		{"value", `{"user":{"id":1}}`, `{"user":{"id":2}}`,
+			[]core.ResultDiff{{Path: "$.user.id", Kind: core.DiffValue, Left: "1", Right: "2"}}},
+		{"type", `{"users":[{"id":1}]}`, `{"users":[{"id":"1"}]}`,
+			[]core.ResultDiff{{Path: "$.users[0].id", Kind: core.DiffType, Left: "number", Right: "string"}}},
+		{"keys", `{"user":{"id":1,"name":"a"}}`, `{"user":{"id":1,"email":"a"}}`,
+			[]core.ResultDiff{
+				{Path: "$.user.email", Kind: core.DiffExtra},
+				{Path: "$.user.name", Kind: core.DiffMissing},
+			}},
+		{"length", `{"users":[{"id":1},{"id":2}]}`, `{"users":[{"id":1}]}`,
+			[]core.ResultDiff{{Path: "$.users", Kind: core.DiffLength, Left: "2", Right: "1"}}},
+		{"order", `{"users":[{"id":1},{"id":2}]}`, `{"users":[{"id":2},{"id":1}]}`,
+			[]core.ResultDiff{{Path: "$.users", Kind: core.DiffOrder}}},
+		{"null", `{"user":{"id":1}}`, `{"user":null}`,
+			[]core.ResultDiff{{Path: "$.user", Kind: core.DiffType, Left: "object", Right: "null"}}},
+	}
+
+	for _, tt := range tests {
+		diffs, err := core.DiffResults(json.RawMessage(tt.left), json.RawMessage(tt.right))
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if !reflect.DeepEqual(diffs, tt.exp) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.exp, diffs)
+		}
+	}
+
+	if _, err := core.DiffResults(json.RawMessage(`{`), json.RawMessage(`{}`)); err == nil {
+		t.Fatal("expected an error for invalid json")
+	}
+}
+
+func TestDiffQuery(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name, schema string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+		if _, err := db.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	mainDB := openDB("main", `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Ada'), (2, 'Grace');`)
+	copyDB := openDB("copy", `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Ada'), (2, 'Grace Hopper'), (3, 'Edsger');`)
+
+	fs := core.NewOsFS(dir)
+	if err := fs.Put("/queries/getUsers.gql",
+		[]byte(`query getUsers { users(order_by: { id: asc }, limit: 2) { id name } }`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Put("/queries/addUser.gql",
+		[]byte(`mutation addUser { users(insert: { id: 4, name: "Alan" }) { id } }`)); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType: "sqlite",
+		Databases: map[string]core.DatabaseConfig{
+			"main": {Type: "sqlite"},
+			"copy": {Type: "sqlite"},
+		},
+		Tables: []core.Table{{Name: "users", Database: "main"}},
+	}
+	gj, err := core.NewGraphJin(conf, mainDB,
+		core.OptionSetFS(fs),
+		core.OptionSetDatabases(map[string]*sql.DB{"main": mainDB, "copy": copyDB}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := gj.DiffQuery(context.Background(), "getUsers", nil, nil, "main", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []core.ResultDiff{{Path: "$.users[1].name", Kind: core.DiffValue, Left: "Grace", Right: "Grace Hopper"}}
+	if !reflect.DeepEqual(diffs, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, diffs)
+	}
+
+	if _, err := gj.DiffQuery(context.Background(), "addUser", nil, nil, "main", "copy"); err == nil {
+		t.Fatal("expected mutations to be rejected")
+	}
+	if _, err := gj.DiffQuery(context.Background(), "getUsers", nil, nil, "main", "missing"); err == nil {
+		t.Fatal("expected an error for an unknown database")
+	}
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// expandRoleInheritance merges the roles listed in the extends of a role into
+// it. Parents are merged in the order they are listed, later ones overriding
+// earlier ones, and the role itself overrides them all:
+//   - tables are merged by schema and name, tables only on a parent are inherited
+//   - limits, filters and columns of the role replace the inherited ones when set
+//   - presets are merged with the keys of the role winning
+//   - blocked operators are the union of all of them
+//   - flags (block, read_only, allow_lock, allow_hints, ...) are set if set on any
+//
+// The match of a role is never inherited. Cycles and unknown roles are errors.
+func expandRoleInheritance(c *Config) error {
+	idx := make(map[string]int, len(c.Roles))
+	for i, r := range c.Roles {
+		idx[r.Name] = i
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(c.Roles))
+
+	var expand func(name string, path []string) error
+	expand = func(name string, path []string) error {
+		path = append(path, name)
+
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("roles: inheritance cycle: %s", strings.Join(path, " -> "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+
+		r := &c.Roles[idx[name]]
+		if len(r.Extends) != 0 {
+			var base Role
+			for _, p := range r.Extends {
+				if _, ok := idx[p]; !ok {
+					// the default roles have no config when they are not defined
+					if p == "user" || p == "anon" {
+						continue
+					}
+					return fmt.Errorf("role '%s': extends unknown role '%s'", name, p)
+				}
+				if err := expand(p, path); err != nil {
+					return err
+				}
+				base = mergeRole(base, c.Roles[idx[p]])
+			}
+			*r = mergeRole(base, *r)
+		}
+
+		state[name] = done
+		return nil
+	}
+
+	for _, r := range c.Roles {
+		if err := expand(r.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeRole returns the role over with the config of base inherited
+func mergeRole(base, over Role) Role {
+	r := over
+	r.BlockOperators = unionStrings(base.BlockOperators, over.BlockOperators)
+	r.AllowHints = base.AllowHints || over.AllowHints
+
+	r.Tables = make([]RoleTable, 0, len(base.Tables)+len(over.Tables))
+	r.Tables = append(r.Tables, base.Tables...)
+
+	for _, t := range over.Tables {
+		i := slices.IndexFunc(r.Tables, func(bt RoleTable) bool {
+			return bt.Schema == t.Schema && bt.Name == t.Name
+		})
+		if i == -1 {
+			r.Tables = append(r.Tables, t)
+		} else {
+			r.Tables[i] = mergeRoleTable(r.Tables[i], t)
+		}
+	}
+	return r
+}
+
+// mergeRoleTable returns the table config over with the config of base inherited
+func mergeRoleTable(base, over RoleTable) RoleTable {
+	t := over
+	t.ReadOnly = base.ReadOnly || over.ReadOnly
+
+	if base.Query != nil {
+		q := *base.Query
+		if o := over.Query; o != nil {
+			q.Limit = orInt(o.Limit, q.Limit)
+			q.MaxLimit = orInt(o.MaxLimit, q.MaxLimit)
+			q.Filters = orStrings(o.Filters, q.Filters)
+			q.Columns = orStrings(o.Columns, q.Columns)
+			q.DisableFunctions = q.DisableFunctions || o.DisableFunctions
+			q.AllowLock = q.AllowLock || o.AllowLock
+			q.BlockOperators = unionStrings(q.BlockOperators, o.BlockOperators)
+			q.Block = q.Block || o.Block
+		}
+		t.Query = &q
+	}
+
+	if base.Insert != nil {
+		v := *base.Insert
+		if o := over.Insert; o != nil {
+			v.Filters = orStrings(o.Filters, v.Filters)
+			v.Columns = orStrings(o.Columns, v.Columns)
+			v.Presets = mergePresets(v.Presets, o.Presets)
+			v.Block = v.Block || o.Block
+		}
+		t.Insert = &v
+	}
+
+	if base.Update != nil {
+		v := *base.Update
+		if o := over.Update; o != nil {
+			v.Filters = orStrings(o.Filters, v.Filters)
+			v.Columns = orStrings(o.Columns, v.Columns)
+			v.Presets = mergePresets(v.Presets, o.Presets)
+			v.Block = v.Block || o.Block
+		}
+		t.Update = &v
+	}
+
+	if base.Upsert != nil {
+		v := *base.Upsert
+		if o := over.Upsert; o != nil {
+			v.Filters = orStrings(o.Filters, v.Filters)
+			v.Columns = orStrings(o.Columns, v.Columns)
+			v.Presets = mergePresets(v.Presets, o.Presets)
+			v.Block = v.Block || o.Block
+		}
+		t.Upsert = &v
+	}
+
+	if base.Delete != nil {
+		v := *base.Delete
+		if o := over.Delete; o != nil {
+			v.Filters = orStrings(o.Filters, v.Filters)
+			v.Columns = orStrings(o.Columns, v.Columns)
+			v.Block = v.Block || o.Block
+		}
+		t.Delete = &v
+	}
+	return t
+}
+
+func orInt(v, def int) int {
+	if v != 0 {
+		return v
+	}
+	return def
+}
+
+func orStrings(v, def []string) []string {
+	if len(v) != 0 {
+		return v
+	}
+	return def
+}
+
+// unionStrings returns the values of a followed by the values of b not in a
+func unionStrings(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	res := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(res, v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// mergePresets returns the presets of base overridden by the presets of over
+func mergePresets(base, over map[string]string) map[string]string {
+	if len(base) == 0 {
+		return over
+	}
+	if len(over) == 0 {
+		return base
+	}
+	m := maps.Clone(base)
+	maps.Copy(m, over)
+	return m
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandRoleInheritance(t *testing.T) {
+	c := &Config{Roles: []Role{
+		{
+			Name:    "manager",
+			Extends: []string{"viewer", "editor"},
+			Tables: []RoleTable{
+				{Name: "orders", Query: &Query{Limit: 50, BlockOperators: []string{"ilike"}},
+					Insert: &Insert{Presets: map[string]string{"owner_id": "$user_id"}}},
+			},
+		},
+		{
+			Name:           "viewer",
+			Match:          "users.id",
+			BlockOperators: []string{"regex"},
+			Tables: []RoleTable{
+				{Name: "orders", Query: &Query{Limit: 10, Filters: []string{"{ deleted: false }"},
+					Columns: []string{"id", "total"}, BlockOperators: []string{"regex"}}},
+				{Name: "products", ReadOnly: true, Query: &Query{}},
+			},
+		},
+		{
+			Name:           "editor",
+			BlockOperators: []string{"has_in_common", "regex"},
+			Tables: []RoleTable{
+				{Name: "orders", Query: &Query{Columns: []string{"id", "total", "status"}},
+					Insert: &Insert{Columns: []string{"total"},
+						Presets: map[string]string{"owner_id": "0", "created_at": "now"}}},
+			},
+		},
+	}}
+
+	if err := expandRoleInheritance(c); err != nil {
+		t.Fatal(err)
+	}
+	m := c.Roles[0]
+
+	if m.Match != "" {
+		t.Errorf("expected the match not to be inherited, got %q", m.Match)
+	}
+	if exp := []string{"regex", "has_in_common"}; !reflect.DeepEqual(m.BlockOperators, exp) {
+		t.Errorf("expected the blocked operators %v, got %v", exp, m.BlockOperators)
+	}
+	if len(m.Tables) != 2 || m.Tables[0].Name != "orders" || m.Tables[1].Name != "products" {
+		t.Fatalf("unexpected tables: %+v", m.Tables)
+	}
+
+	q := m.Tables[0].Query
+	if q.Limit != 50 {
+		t.Errorf("expected the limit of the role, got %d", q.Limit)
+	}
+	if exp := []string{"{ deleted: false }"}; !reflect.DeepEqual(q.Filters, exp) {
+		t.Errorf("expected the filters of viewer, got %v", q.Filters)
+	}
+	if exp := []string{"id", "total", "status"}; !reflect.DeepEqual(q.Columns, exp) {
+		t.Errorf("expected the columns of editor, got %v", q.Columns)
+	}
+	if exp := []string{"regex", "ilike"}; !reflect.DeepEqual(q.BlockOperators, exp) {
+		t.Errorf("expected the blocked operators %v, got %v", exp, q.BlockOperators)
+	}
+
+	ins := m.Tables[0].Insert
+	exp := map[string]string{"owner_id": "$user_id", "created_at": "now"}
+	if !reflect.DeepEqual(ins.Presets, exp) || !reflect.DeepEqual(ins.Columns, []string{"total"}) {
+		t.Errorf("unexpected insert config: %+v", ins)
+	}
+	if !m.Tables[1].ReadOnly {
+		t.Error("expected the products table to be inherited read only")
+	}
+
+	// the parents are not changed
+	if c.Roles[1].Tables[0].Query.Limit != 10 || c.Roles[2].Tables[0].Insert.Presets["owner_id"] != "0" {
+		t.Error("expected the parent roles to be unchanged")
+	}
+
+	// expanding again gives the same roles
+	roles := c.Roles[0]
+	if err := expandRoleInheritance(c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Roles[0], roles) {
+		t.Error("expected expanding twice to give the same roles")
+	}
+}
+
+func TestExpandRoleInheritanceErrors(t *testing.T) {
+	tests := []struct {
+		roles []Role
+		err   string
+	}{
+		{[]Role{
+			{Name: "a", Extends: []string{"b"}},
+			{Name: "b", Extends: []string{"c"}},
+			{Name: "c", Extends: []string{"a"}},
+		}, "inheritance cycle: a -> b -> c -> a"},
+		{[]Role{{Name: "a", Extends: []string{"a"}}}, "inheritance cycle: a -> a"},
+		{[]Role{{Name: "a", Extends: []string{"missing"}}}, "extends unknown role 'missing'"},
+	}
+
+	for _, tt := range tests {
+		err := expandRoleInheritance(&Config{Roles: tt.roles})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("expected error %q, got %v", tt.err, err)
+		}
+	}
+
+	// the default roles can be extended when they are not defined
+	c := &Config{Roles: []Role{{Name: "a", Extends: []string{"user"}}}}
+	if err := expandRoleInheritance(c); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -56,3 +57,47 @@ func (gj *graphjinEngine) prepareRoleStmt() error {
 	gj.roleStatement = w.String()
 	return nil
 }
+
+// UserRole returns the role a request made with the context runs as. The
+// role set on the context is used as is, authenticated users get their role
+// from the roles_query when one is configured.
+func (g *GraphJin) UserRole(c context.Context) (string, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return "", err
+	}
+	return gj.userRole(c, nil)
+}
+
+// contextRole returns the role of the user making the request
+func contextRole(c context.Context) string {
+	if v, ok := c.Value(UserRoleKey).(string); ok {
+		return v
+	}
+	switch c.Value(UserIDKey).(type) {
+	case string, int:
+		return "user"
+	default:
+		return "anon"
+	}
+}
+
+// withUserRole returns a context for the user with the role set, used to
+// run a request as a user and role saved earlier
+func withUserRole(c context.Context, userID interface{}, role string) context.Context {
+	c = context.WithValue(c, UserRoleKey, role)
+	if userID != nil {
+		c = context.WithValue(c, UserIDKey, userID)
+	}
+	return c
+}
+
+// userRole returns the role of the user making the request the same way
+// newGState and compileAndExecute resolve it
+func (gj *graphjinEngine) userRole(c context.Context, rc *RequestConfig) (string, error) {
+	role := contextRole(c)
+	if role != "user" || !gj.abacEnabled {
+		return role, nil
+	}
+	return gj.executeRoleQuery(c, nil, nil, rc)
+}
@@ -0,0 +1,186 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestUserRole(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, is_admin BOOLEAN);
+		INSERT INTO users (id, is_admin) VALUES (1, true), (2, false);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		RolesQuery:       `SELECT * FROM users WHERE id = $user_id`,
+		Roles:            []core.Role{{Name: "admin", Match: `is_admin = true`}},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		c    context.Context
+		role string
+	}{
+		{"anon", context.Background(), "anon"},
+		{"role query", context.WithValue(context.Background(), core.UserIDKey, 1), "admin"},
+		{"role query default", context.WithValue(context.Background(), core.UserIDKey, 2), "user"},
+		{"context role", context.WithValue(
+			context.WithValue(context.Background(), core.UserIDKey, 1), core.UserRoleKey, "support"), "support"},
+	}
+	for _, tt := range tests {
+		role, err := gj.UserRole(tt.c)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if role != tt.role {
+			t.Errorf("%s: expected role %q, got %q", tt.name, tt.role, role)
+		}
+	}
+}
+
+func TestCRUDUserRole(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, is_admin BOOLEAN);
+		INSERT INTO users (id, email, is_admin) VALUES (1, 'a@example.com', true), (2, 'b@example.com', false);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		EnableCRUD:       true,
+		RolesQuery:       `SELECT * FROM users WHERE id = $user_id`,
+		Roles: []core.Role{
+			{Name: "user", Tables: []core.RoleTable{{Name: "users", Query: &core.Query{Columns: []string{"id"}}}}},
+			{Name: "admin", Match: `is_admin = true`},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the columns come from the role the roles query picks
+	tests := []struct {
+		id  int
+		exp string
+	}{
+		{1, `{"users":{"id":1,"email":"a@example.com","is_admin":1}}`},
+		{2, `{"users":{"id":2}}`},
+	}
+	for _, tt := range tests {
+		c := context.WithValue(context.Background(), core.UserIDKey, tt.id)
+		req := core.CRUDRequest{Op: core.CRUDRead, Table: "users", ID: strconv.Itoa(tt.id)}
+		res, err := gj.CRUD(c, req, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data, exp interface{}
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.exp), &exp); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(data, exp) {
+			t.Errorf("user %d: expected %s, got %s", tt.id, tt.exp, res.Data)
+		}
+	}
+}
+
+func TestCRUDProduction(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT);
+		CREATE TABLE payments (id INTEGER PRIMARY KEY, amount INTEGER);
+		INSERT INTO users (id, email) VALUES (1, 'a@example.com');
+		INSERT INTO payments (id, amount) VALUES (1, 100);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:     "sqlite",
+		Production: true,
+		EnableCRUD: true,
+		Roles: []core.Role{
+			{Name: "user", Tables: []core.RoleTable{{Name: "users", Query: &core.Query{}}}},
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	// only the table and operation configured on the role are served
+	res, err := gj.CRUD(c, core.CRUDRequest{Op: core.CRUDRead, Table: "users", ID: "1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"users":{"id":1,"email":"a@example.com"}}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	denied := []core.CRUDRequest{
+		{Op: core.CRUDList, Table: "payments"},
+		{Op: core.CRUDDelete, Table: "users", ID: "1"},
+	}
+	for _, req := range denied {
+		if _, err := gj.CRUD(c, req, nil); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("expected %s on %s to be refused, got %v", req.Op, req.Table, err)
+		}
+	}
+
+	var n int
+	if err := db.QueryRow(`SELECT count(*) FROM users`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected the user to remain, got %d rows", n)
+	}
+}
@@ -59,6 +59,7 @@ const schemaTemplate = `# dbinfo:{{if .Type}}{{ .Type }}{{else}}postgres{{end}},
 {{- if .UniqueKey}} @unique{{end}}
 {{- if .FullText}} @search{{end}}
 {{- if .Blocked}} @blocked{{end}}
+{{- if .Generated}} @generated{{end}}
 {{- end}}
 
 {{- define "func_args"}}
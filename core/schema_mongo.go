@@ -0,0 +1,254 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MongoSchemaSync computes the operations needed to bootstrap a MongoDB database
+// from the tables in the config. Missing collections are created with a JSON Schema
+// validator built from the configured columns, and indexes are added for foreign
+// keys, full-text columns and columns used in role filters. The SQL of each
+// operation is a query DSL statement for the MongoDB driver.
+func MongoSchemaSync(db *sql.DB, conf *Config) ([]SchemaOperation, error) {
+	indexes, err := mongoIndexes(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover database schema: %w", err)
+	}
+	return mongoSchemaOps(conf, indexes), nil
+}
+
+// mongoSchemaOps computes the sync operations given the index names of the
+// existing collections
+func mongoSchemaOps(conf *Config, indexes map[string]map[string]bool) []SchemaOperation {
+	filterCols := mongoFilterColumns(conf)
+	var ops []SchemaOperation
+
+	for _, t := range conf.Tables {
+		// skip aliases, embedded json and virtual tables
+		if t.Type != "" || t.Table != "" || len(t.Columns) == 0 {
+			continue
+		}
+
+		existing, exists := indexes[t.Name]
+		if !exists {
+			ops = append(ops, SchemaOperation{
+				Type:  "create_table",
+				Table: t.Name,
+				SQL:   mongoCreateCollection(t),
+			})
+		}
+
+		addIndex := func(col string, idx mongoIndex) {
+			if existing[idx.Name] {
+				return
+			}
+			ops = append(ops, SchemaOperation{
+				Type:   "add_index",
+				Table:  t.Name,
+				Column: col,
+				SQL:    mongoCreateIndex(t.Name, idx),
+			})
+		}
+
+		var textKeys [][]any
+		indexed := make(map[string]bool)
+
+		for _, c := range t.Columns {
+			switch {
+			case c.FullText:
+				textKeys = append(textKeys, []any{c.Name, "text"})
+			case c.ForeignKey != "" && !c.Primary:
+				indexed[c.Name] = true
+				addIndex(c.Name, mongoIndex{
+					Name: t.Name + "_" + c.Name + "_idx",
+					Keys: [][]any{{c.Name, 1}},
+				})
+			}
+		}
+
+		if len(textKeys) != 0 {
+			// MongoDB allows only one text index per collection
+			addIndex("", mongoIndex{Name: t.Name + "_text_idx", Keys: textKeys})
+		}
+
+		for _, col := range filterCols[t.Name] {
+			if indexed[col] || col == "id" || col == "_id" {
+				continue
+			}
+			addIndex(col, mongoIndex{
+				Name: t.Name + "_" + col + "_idx",
+				Keys: [][]any{{col, 1}},
+			})
+		}
+	}
+	return ops
+}
+
+type mongoIndex struct {
+	Name string  `json:"name"`
+	Keys [][]any `json:"keys"`
+}
+
+// mongoIndexes returns the index names of every collection in the database
+func mongoIndexes(db *sql.DB) (map[string]map[string]bool, error) {
+	rows, err := db.Query(`{"operation":"introspect_indexes"}`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	indexes := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, name string
+		if err := rows.Scan(&table, &name); err != nil {
+			return nil, err
+		}
+		if indexes[table] == nil {
+			indexes[table] = make(map[string]bool)
+		}
+		indexes[table][name] = true
+	}
+	return indexes, rows.Err()
+}
+
+// mongoCreateCollection renders a createCollection statement with a JSON Schema
+// validator for the configured columns. Values can always be null since the
+// column config has no not-null option.
+func mongoCreateCollection(t Table) string {
+	props := make(map[string]any, len(t.Columns))
+
+	for _, c := range t.Columns {
+		bt := mongoBSONTypes(c.Type)
+		if bt == nil {
+			continue
+		}
+		name := c.Name
+		if c.Primary && name == "id" {
+			name = "_id"
+		}
+		if c.Array {
+			props[name] = map[string]any{
+				"bsonType": []string{"array", "null"},
+				"items":    map[string]any{"bsonType": bt},
+			}
+		} else {
+			props[name] = map[string]any{"bsonType": append(bt, "null")}
+		}
+	}
+
+	q := map[string]any{
+		"operation":  "createCollection",
+		"collection": t.Name,
+	}
+	if len(props) != 0 {
+		q["validator"] = map[string]any{
+			"$jsonSchema": map[string]any{
+				"bsonType":   "object",
+				"properties": props,
+			},
+		}
+	}
+	return mongoStatement(q)
+}
+
+// mongoCreateIndex renders a createIndex statement
+func mongoCreateIndex(table string, idx mongoIndex) string {
+	return mongoStatement(map[string]any{
+		"operation":  "createIndex",
+		"collection": table,
+		"index":      idx,
+	})
+}
+
+func mongoStatement(q map[string]any) string {
+	// json.Marshal sorts map keys so the statements are stable
+	b, _ := json.Marshal(q)
+	return string(b)
+}
+
+// mongoBSONTypes maps a column type to the BSON types allowed for it,
+// nil is returned for types that are not validated
+func mongoBSONTypes(colType string) []string {
+	t := strings.ToLower(strings.TrimSpace(colType))
+	if n := strings.IndexByte(t, '('); n != -1 {
+		t = strings.TrimSpace(t[:n])
+	}
+	t = strings.TrimSuffix(t, "[]")
+
+	switch t {
+	case "int", "integer", "smallint", "bigint", "int2", "int4", "int8", "long":
+		return []string{"int", "long"}
+	case "float", "float4", "float8", "real", "double", "double precision", "numeric", "decimal":
+		return []string{"double", "decimal", "int", "long"}
+	case "bool", "boolean":
+		return []string{"bool"}
+	case "text", "string", "varchar", "character varying", "char", "character", "uuid":
+		return []string{"string"}
+	case "timestamp", "timestamptz", "timestamp with time zone",
+		"timestamp without time zone", "date", "datetime":
+		return []string{"date"}
+	case "json", "jsonb":
+		return []string{"object", "array"}
+	case "objectid":
+		return []string{"objectId"}
+	}
+	return nil
+}
+
+var filterKeyRe = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*:`)
+
+// mongoFilterColumns returns the configured columns of each table that are
+// used in the filters of a role
+func mongoFilterColumns(conf *Config) map[string][]string {
+	cols := make(map[string]map[string]bool)
+	for _, t := range conf.Tables {
+		m := make(map[string]bool, len(t.Columns))
+		for _, c := range t.Columns {
+			m[c.Name] = true
+		}
+		cols[t.Name] = m
+	}
+
+	found := make(map[string]map[string]bool)
+	add := func(table string, filters []string) {
+		for _, f := range filters {
+			for _, m := range filterKeyRe.FindAllStringSubmatch(f, -1) {
+				if !cols[table][m[1]] {
+					continue
+				}
+				if found[table] == nil {
+					found[table] = make(map[string]bool)
+				}
+				found[table][m[1]] = true
+			}
+		}
+	}
+
+	for _, r := range conf.Roles {
+		for _, t := range r.Tables {
+			if t.Query != nil {
+				add(t.Name, t.Query.Filters)
+			}
+			if t.Update != nil {
+				add(t.Name, t.Update.Filters)
+			}
+			if t.Delete != nil {
+				add(t.Name, t.Delete.Filters)
+			}
+		}
+	}
+
+	res := make(map[string][]string, len(found))
+	for table, m := range found {
+		for c := range m {
+			res[table] = append(res[table], c)
+		}
+		sort.Strings(res[table])
+	}
+	return res
+}
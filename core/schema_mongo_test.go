@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestMongoSchemaOps(t *testing.T) {
+	conf := &Config{
+		Tables: []Table{
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "objectid", Primary: true},
+					{Name: "email", Type: "text"},
+					{Name: "disabled", Type: "boolean"},
+				},
+			},
+			{
+				Name: "products",
+				Columns: []Column{
+					{Name: "id", Type: "objectid", Primary: true},
+					{Name: "name", Type: "text", FullText: true},
+					{Name: "description", Type: "text", FullText: true},
+					{Name: "price", Type: "numeric(7,2)"},
+					{Name: "tags", Type: "text", Array: true},
+					{Name: "owner_id", Type: "objectid", ForeignKey: "users.id"},
+				},
+			},
+			{Name: "me", Table: "users"},
+		},
+		Roles: []Role{{
+			Name: "user",
+			Tables: []RoleTable{
+				{Name: "users", Query: &Query{Filters: []string{"{ disabled: { eq: false } }"}}},
+				{Name: "products", Update: &Update{Filters: []string{"{ owner_id: { eq: $user_id } }"}}},
+			},
+		}},
+	}
+
+	// users exists and is already indexed on disabled
+	indexes := map[string]map[string]bool{
+		"users": {"_id_": true, "users_disabled_idx": true},
+	}
+
+	ops := mongoSchemaOps(conf, indexes)
+
+	exp := []SchemaOperation{
+		{
+			Type:  "create_table",
+			Table: "products",
+			SQL: `{"collection":"products","operation":"createCollection","validator":{"$jsonSchema":{"bsonType":"object","properties":{` +
+				`"_id":{"bsonType":["objectId","null"]},` +
+				`"description":{"bsonType":["string","null"]},` +
+				`"name":{"bsonType":["string","null"]},` +
+				`"owner_id":{"bsonType":["objectId","null"]},` +
+				`"price":{"bsonType":["double","decimal","int","long","null"]},` +
+				`"tags":{"bsonType":["array","null"],"items":{"bsonType":["string"]}}}}}}`,
+		},
+		{
+			Type:   "add_index",
+			Table:  "products",
+			Column: "owner_id",
+			SQL:    `{"collection":"products","index":{"name":"products_owner_id_idx","keys":[["owner_id",1]]},"operation":"createIndex"}`,
+		},
+		{
+			Type:  "add_index",
+			Table: "products",
+			SQL:   `{"collection":"products","index":{"name":"products_text_idx","keys":[["name","text"],["description","text"]]},"operation":"createIndex"}`,
+		},
+	}
+
+	if len(ops) != len(exp) {
+		t.Fatalf("expected %d operations, got %d: %+v", len(exp), len(ops), ops)
+	}
+	for i := range exp {
+		if ops[i] != exp[i] {
+			t.Errorf("operation %d:\n got: %+v\nwant: %+v", i, ops[i], exp[i])
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// builtinScalars are part of every GraphQL schema and left out of the SDL
+var builtinScalars = map[string]bool{
+	TYPE_BOOLEAN: true,
+	TYPE_FLOAT:   true,
+	TYPE_INT:     true,
+	TYPE_STRING:  true,
+	"ID":         true,
+}
+
+// GetSDL returns the GraphQL schema in the schema definition language, it is
+// built from the introspection result so both describe the same schema
+func (g *GraphJin) GetSDL() ([]byte, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+	return gj.getSDL()
+}
+
+func (gj *graphjinEngine) getSDL() (data []byte, err error) {
+	var ok bool
+	if data, ok = gj.cache.Get("_sdl"); ok {
+		return
+	}
+
+	schema, err := gj.introSchema()
+	if err != nil {
+		return
+	}
+
+	data = []byte(writeSDL(schema, nil))
+	gj.cache.Set("_sdl", data)
+	return
+}
+
+// introSchema returns the schema of the introspection result
+func (gj *graphjinEngine) introSchema() (IntrospectionSchema, error) {
+	intro, err := gj.getIntroResult()
+	if err != nil {
+		return IntrospectionSchema{}, err
+	}
+
+	var res IntroResult
+	if err = json.Unmarshal(intro, &res); err != nil {
+		return IntrospectionSchema{}, err
+	}
+	return res.Schema, nil
+}
+
+// writeSDL renders the introspection schema as SDL, object and input types
+// without fields are left out since they cannot be written in SDL. Object
+// types in keys get a federation @key directive with the key fields.
+func writeSDL(s IntrospectionSchema, keys map[string]string) string {
+	var sb strings.Builder
+	skip := make(map[string]bool)
+
+	for _, t := range s.Types {
+		switch {
+		case t.Kind == KIND_SCALAR && builtinScalars[t.Name]:
+			skip[t.Name] = true
+		case t.Kind == KIND_OBJECT && len(t.Fields) == 0,
+			t.Kind == KIND_INPUT_OBJ && len(t.InputFields) == 0,
+			t.Kind == KIND_ENUM && len(t.EnumValues) == 0:
+			skip[t.Name] = true
+		}
+	}
+
+	sb.WriteString("schema {\n")
+	for _, op := range []struct {
+		name string
+		t    *ShortFullType
+	}{
+		{"query", s.QueryType},
+		{"mutation", s.MutationType},
+		{"subscription", s.SubscriptionType},
+	} {
+		if op.t != nil && !skip[op.t.Name] {
+			sb.WriteString("  " + op.name + ": " + op.t.Name + "\n")
+		}
+	}
+	sb.WriteString("}\n")
+
+	for _, d := range s.Directives {
+		sb.WriteString("\n")
+		writeSDLDescription(&sb, d.Description, "")
+		sb.WriteString("directive @" + d.Name)
+		writeSDLArgs(&sb, d.Args)
+		if d.IsRepeatable {
+			sb.WriteString(" repeatable")
+		}
+		sb.WriteString(" on " + strings.Join(d.Locations, " | ") + "\n")
+	}
+
+	for _, t := range s.Types {
+		if skip[t.Name] {
+			continue
+		}
+		sb.WriteString("\n")
+		writeSDLDescription(&sb, t.Description, "")
+
+		switch t.Kind {
+		case KIND_SCALAR:
+			sb.WriteString("scalar " + t.Name + "\n")
+
+		case KIND_OBJECT:
+			sb.WriteString("type " + t.Name)
+			if k, ok := keys[t.Name]; ok {
+				sb.WriteString(` @key(fields: "` + k + `")`)
+			}
+			sb.WriteString(" {\n")
+			for _, f := range t.Fields {
+				writeSDLDescription(&sb, f.Description, "  ")
+				sb.WriteString("  " + f.Name)
+				writeSDLArgs(&sb, f.Args)
+				sb.WriteString(": " + sdlTypeRef(f.Type))
+				writeSDLDeprecated(&sb, f.IsDeprecated, f.DeprecationReason)
+				sb.WriteString("\n")
+			}
+			sb.WriteString("}\n")
+
+		case KIND_INPUT_OBJ:
+			sb.WriteString("input " + t.Name + " {\n")
+			for _, f := range t.InputFields {
+				writeSDLDescription(&sb, f.Description, "  ")
+				sb.WriteString("  " + sdlInputValue(f) + "\n")
+			}
+			sb.WriteString("}\n")
+
+		case KIND_ENUM:
+			sb.WriteString("enum " + t.Name + " {\n")
+			for _, v := range t.EnumValues {
+				writeSDLDescription(&sb, v.Description, "  ")
+				sb.WriteString("  " + v.Name)
+				writeSDLDeprecated(&sb, v.IsDeprecated, v.DeprecationReason)
+				sb.WriteString("\n")
+			}
+			sb.WriteString("}\n")
+
+		case KIND_UNION:
+			names := make([]string, 0, len(t.PossibleTypes))
+			for _, pt := range t.PossibleTypes {
+				names = append(names, sdlTypeRef(&pt))
+			}
+			sb.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+func writeSDLDescription(sb *strings.Builder, desc, indent string) {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return
+	}
+	desc = strings.ReplaceAll(desc, `"""`, `\"""`)
+	if !strings.Contains(desc, "\n") {
+		sb.WriteString(indent + `"""` + desc + `"""` + "\n")
+		return
+	}
+	sb.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(desc, "\n") {
+		sb.WriteString(indent + line + "\n")
+	}
+	sb.WriteString(indent + `"""` + "\n")
+}
+
+func writeSDLArgs(sb *strings.Builder, args []InputValue) {
+	if len(args) == 0 {
+		return
+	}
+	list := make([]string, 0, len(args))
+	for _, a := range args {
+		list = append(list, sdlInputValue(a))
+	}
+	sb.WriteString("(" + strings.Join(list, ", ") + ")")
+}
+
+func writeSDLDeprecated(sb *strings.Builder, deprecated bool, reason *string) {
+	if !deprecated {
+		return
+	}
+	sb.WriteString(" @deprecated")
+	if reason != nil && *reason != "" {
+		r, _ := json.Marshal(*reason)
+		sb.WriteString("(reason: " + string(r) + ")")
+	}
+}
+
+func sdlInputValue(v InputValue) string {
+	s := v.Name + ": " + sdlTypeRef(v.Type)
+	if v.DefaultValue != nil && *v.DefaultValue != "" {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+// sdlTypeRef returns a type reference like [String!]!
+func sdlTypeRef(tr *TypeRef) string {
+	if tr == nil {
+		return TYPE_STRING
+	}
+	switch tr.Kind {
+	case KIND_NONNULL:
+		return sdlTypeRef(tr.OfType) + "!"
+	case KIND_LIST:
+		return "[" + sdlTypeRef(tr.OfType) + "]"
+	}
+	if tr.Name != nil {
+		return *tr.Name
+	}
+	return sdlTypeRef(tr.OfType)
+}
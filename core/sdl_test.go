@@ -0,0 +1,67 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGetSDL(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, user_id INTEGER REFERENCES users(id));`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := gj.GetSDL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdl := string(b)
+
+	for _, v := range []string{
+		"schema {\n  query: Query\n",
+		"type Query {\n",
+		"type users {\n",
+		"  email(includeIf: usersWhereInput, skipIf: usersWhereInput): String!\n",
+		"scalar JSON\n",
+		"directive @hint(value: String) on FIELD\n",
+	} {
+		if !strings.Contains(sdl, v) {
+			t.Errorf("expected the sdl to contain %q", v)
+		}
+	}
+	if strings.Contains(sdl, "scalar String") || strings.Contains(sdl, "{\n}") {
+		t.Error("expected no builtin scalars or empty types")
+	}
+
+	// every type used must be defined
+	defined := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+	for _, m := range regexp.MustCompile(`(?m)^(?:type|input|enum|scalar|union) (\w+)`).FindAllStringSubmatch(sdl, -1) {
+		defined[m[1]] = true
+	}
+	for _, m := range regexp.MustCompile(`: \[?(\w+)`).FindAllStringSubmatch(sdl, -1) {
+		if !defined[m[1]] {
+			t.Errorf("type %s is used but not defined", m[1])
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+const (
+	// defaultSerializableAttempts is the number of times a serializable
+	// mutation is run when max_attempts is not set
+	defaultSerializableAttempts = 3
+	// serializableBackoff is the wait before the first retry, it doubles
+	// with each retry
+	serializableBackoff = 20 * time.Millisecond
+)
+
+// serializationFailures are the errors of each dialect for a transaction
+// that conflicted with another and can be run again
+var serializationFailures = map[string][]string{
+	"postgres": {"SQLSTATE 40001", "SQLSTATE 40P01", "could not serialize access",
+		"deadlock detected", "restart transaction"},
+	"mysql":  {"Error 1213", "Error 1205", "Deadlock found"},
+	"sqlite": {"database is locked", "database table is locked"},
+	"mssql": {"Snapshot isolation transaction aborted due to update conflict",
+		"chosen as the deadlock victim"},
+	"oracle": {"ORA-08177", "ORA-00060"},
+}
+
+// isSerializable returns true for mutations listed in the serializable config
+func (s *gstate) isSerializable() bool {
+	conf := s.gj.conf.Serializable
+	return s.r.operation == qcode.QTMutation && s.r.name != "" &&
+		slices.Contains(conf.Mutations, s.r.name)
+}
+
+// executeSerializable runs the mutation in a transaction with the configured
+// isolation level and runs it again when it fails with a serialization
+// failure. Mutations in a request transaction run in it without retries
+func (s *gstate) executeSerializable(c context.Context,
+	conn *sql.Conn,
+	run func(context.Context, *sql.Conn) error,
+) error {
+	if s.tx() != nil {
+		return run(c, conn)
+	}
+
+	dbType := s.getTargetDBCtx().dbtype
+	if dbType == "mongodb" || dbType == "snowflake" {
+		return fmt.Errorf("serializable: not supported by %s", dbType)
+	}
+
+	conf := s.gj.conf.Serializable
+	level, err := isolationLevel(conf.Isolation)
+	if err != nil {
+		return err
+	}
+
+	attempts := conf.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultSerializableAttempts
+	}
+	policy := retryPolicy{
+		delays:      make([]time.Duration, attempts),
+		shouldRetry: func(err error) bool { return isSerializationFailure(dbType, err) },
+	}
+	for i := range policy.delays {
+		policy.delays[i] = serializableBackoff << i
+	}
+
+	// each attempt starts from the state the mutation was compiled to
+	cs, vmap := s.cs, maps.Clone(s.vmap)
+
+	return retryOperationWithPolicy(c, policy, func() error {
+		s.cs, s.vmap = cs, maps.Clone(vmap)
+		s.data, s.verrs, s.rolledBack = nil, nil, nil
+		return s.runSerializable(c, conn, level, run)
+	})
+}
+
+// runSerializable runs the mutation once in a transaction of its own
+func (s *gstate) runSerializable(c context.Context,
+	conn *sql.Conn,
+	level sql.IsolationLevel,
+	run func(context.Context, *sql.Conn) error,
+) (err error) {
+	if s.optTx, err = conn.BeginTx(c, &sql.TxOptions{Isolation: level}); err != nil {
+		return
+	}
+	defer func() {
+		if err == nil {
+			err = s.optTx.Commit()
+		} else {
+			s.optTx.Rollback() //nolint:errcheck
+		}
+		s.optTx = nil
+	}()
+	return run(c, conn)
+}
+
+// isolationLevel returns the isolation level for the config value
+func isolationLevel(v string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(v) {
+	case "", "serializable":
+		return sql.LevelSerializable, nil
+	case "snapshot":
+		return sql.LevelSnapshot, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("serializable: invalid isolation: %s", v)
+}
+
+// isSerializationFailure returns true when the error is a serialization
+// failure or deadlock of the dialect
+func isSerializationFailure(dbType string, err error) bool {
+	switch dbType {
+	case "", "postgres":
+		dbType = "postgres"
+	case "mariadb":
+		dbType = "mysql"
+	}
+	msg := err.Error()
+	for _, f := range serializationFailures[dbType] {
+		if strings.Contains(msg, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSerializableMutation(t *testing.T) {
+	dir := t.TempDir()
+	dsn := "file:" + filepath.Join(dir, "app.db") + "?_busy_timeout=0"
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE stock (id INTEGER PRIMARY KEY, qty INTEGER NOT NULL);
+		INSERT INTO stock (id, qty) VALUES (1, 10);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Serializable: core.SerializableConfig{
+			Mutations:   []string{"decrementStock"},
+			MaxAttempts: 5,
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another connection holds the write lock for a while
+	lock, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close() //nolint:errcheck
+
+	tx, err := lock.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`UPDATE stock SET qty = qty WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `mutation decrementStock { stock(id: 1, update: { qty: 9 }) { id qty } }`
+	other := `mutation setStock { stock(id: 1, update: { qty: 8 }) { id qty } }`
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		tx.Commit() //nolint:errcheck
+	}()
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		t.Fatalf("expected the mutation to be retried: %v", err)
+	}
+	if !strings.Contains(string(res.Data), `"qty": 9`) && !strings.Contains(string(res.Data), `"qty":9`) {
+		t.Fatalf("unexpected result: %s", res.Data)
+	}
+
+	// mutations that are not listed fail on the conflict
+	if tx, err = lock.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`UPDATE stock SET qty = qty WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gj.GraphQL(context.Background(), other, nil, nil); err == nil ||
+		!strings.Contains(err.Error(), "locked") {
+		t.Fatalf("expected a locked database error, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+)
+
+// hasShards returns true if any table is sharded
+func (gj *graphjinEngine) hasShards() bool {
+	for _, t := range gj.tables() {
+		if t.Shards != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// shardConfig returns the shard config of a root field
+func (gj *graphjinEngine) shardConfig(root string) *ShardConfig {
+	for _, t := range gj.tables() {
+		if t.Name == root && t.Shards != nil {
+			return t.Shards
+		}
+	}
+	return nil
+}
+
+// routeShard sends requests on sharded tables to the shard picked by the
+// hash of the shard key variable. Queries without the key are run on every
+// shard when scatter gather is enabled.
+func (s *gstate) routeShard() error {
+	if !s.gj.hasShards() {
+		return nil
+	}
+
+	var sc *ShardConfig
+	var table string
+
+	for _, root := range s.extractAllRootFields() {
+		c := s.gj.shardConfig(root.name)
+		if c == nil {
+			continue
+		}
+		if sc != nil && (sc.Key != c.Key || !slices.Equal(sc.Databases, c.Databases)) {
+			return fmt.Errorf("tables '%s' and '%s' are sharded differently", table, root.name)
+		}
+		sc, table = c, root.name
+	}
+	if sc == nil {
+		return nil
+	}
+
+	v, ok := s.vmap[sc.Key]
+	if ok && string(v) != "null" {
+		return s.setDatabase(sc.Databases[shardIndex(v, len(sc.Databases))])
+	}
+
+	if !sc.ScatterGather || s.r.operation != qcode.QTQuery {
+		return fmt.Errorf("variable '%s' is required to pick the shard of '%s'", sc.Key, table)
+	}
+	for _, name := range sc.Databases {
+		if _, ok := s.gj.databases[name]; !ok {
+			return fmt.Errorf("database not found: %s", name)
+		}
+	}
+	s.shards = sc.Databases
+	return nil
+}
+
+// shardIndex hashes the shard key, strings are hashed without their quotes
+// so "42" and 42 go to the same shard
+func shardIndex(v json.RawMessage, n int) int {
+	var str string
+	if err := json.Unmarshal(v, &str); err == nil {
+		v = []byte(str)
+	}
+	h := fnv.New32a()
+	h.Write(v) //nolint:errcheck
+	return int(h.Sum32() % uint32(n))
+}
+
+// executeShards runs the query on every shard and concatenates the lists
+// each shard returns, in the order of the shards. Limits and ordering apply
+// to each shard on its own.
+func (s *gstate) executeShards(c context.Context) error {
+	roots := rootKeys(s.extractAllRootFields())
+	results := make([]dbResult, len(s.shards))
+
+	c, cancel := s.budget.context(c)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i, db := range s.shards {
+		if err := s.budget.acquire(); err != nil {
+			results[i] = dbResult{database: db, data: nullRootFields(roots)}
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, db string) {
+			defer wg.Done()
+
+			ctx1, span := s.gj.spanStart(c, "Execute Shard")
+			span.SetAttributesString(StringAttr{"query.database", db})
+			defer span.End()
+
+			data, err := s.executeForDatabaseRoots(ctx1, db, roots)
+			if err = s.budget.check(err); err != nil {
+				span.Error(err)
+			}
+			if err == nil && s.budget.addRows(data, true) != nil {
+				data = nullRootFields(roots)
+			}
+			results[idx] = dbResult{database: db, data: data, err: err}
+		}(i, db)
+	}
+	wg.Wait()
+
+	if err := s.gatherShardResults(results); err != nil {
+		return err
+	}
+	return s.budget.exceeded()
+}
+
+// gatherShardResults concatenates the lists returned for each root field,
+// singular fields take the first value that is not null
+func (s *gstate) gatherShardResults(results []dbResult) error {
+	merged := make(map[string]json.RawMessage)
+	lists := make(map[string][]json.RawMessage)
+
+	for _, r := range results {
+		if r.err != nil {
+			return fmt.Errorf("database %s: %w", r.database, r.err)
+		}
+		if len(r.data) == 0 {
+			continue
+		}
+
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(r.data, &obj); err != nil {
+			return fmt.Errorf("failed to parse result from %s: %w", r.database, err)
+		}
+
+		for k, v := range obj {
+			var items []json.RawMessage
+			if err := json.Unmarshal(v, &items); err == nil && items != nil {
+				lists[k] = append(lists[k], items...)
+				continue
+			}
+			if cur, ok := merged[k]; !ok || string(cur) == "null" {
+				merged[k] = v
+			}
+		}
+	}
+
+	for k, items := range lists {
+		v, err := json.Marshal(items)
+		if err != nil {
+			return fmt.Errorf("failed to marshal merged result: %w", err)
+		}
+		merged[k] = v
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("failed to marshal merged result: %w", err)
+	}
+	s.data = data
+	return nil
+}
@@ -0,0 +1,123 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestShardRouting(t *testing.T) {
+	dir := t.TempDir()
+
+	openDB := func(name string) *sql.DB {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name+".db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+		_, err = db.Exec(`CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT);`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != "primary" {
+			_, err = db.Exec(`INSERT INTO accounts (id, name) VALUES (1, '` + name + `')`)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return db
+	}
+
+	primary := openDB("primary")
+	shard1 := openDB("shard1")
+	shard2 := openDB("shard2")
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Databases: map[string]core.DatabaseConfig{
+			core.DefaultDBName: {Type: "sqlite"},
+			"shard1":           {Type: "sqlite"},
+			"shard2":           {Type: "sqlite"},
+		},
+		Tables: []core.Table{{
+			Name: "accounts",
+			Shards: &core.ShardConfig{
+				Databases: []string{"shard1", "shard2"},
+				Key:       "account_id",
+			},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, primary,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"shard1": shard1, "shard2": shard2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query getAccounts { accounts(where: { id: { neq: $account_id } }) { name } }`
+
+	shardOf := func(vars string) string {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), gql, json.RawMessage(vars), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data struct{ Accounts []struct{ Name string } }
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if len(data.Accounts) != 1 {
+			t.Fatalf("expected one account, got %s", res.Data)
+		}
+		return data.Accounts[0].Name
+	}
+
+	// the same key always goes to the same shard
+	seen := make(map[string]bool)
+	for _, id := range []string{"100", "101", "102", "103", "104", "105"} {
+		name := shardOf(`{"account_id": ` + id + `}`)
+		if shardOf(`{"account_id": "`+id+`"}`) != name {
+			t.Fatalf("expected %s and \"%s\" to go to the same shard", id, id)
+		}
+		seen[name] = true
+	}
+	if !seen["shard1"] || !seen["shard2"] {
+		t.Fatalf("expected keys to be spread over both shards, got %v", seen)
+	}
+
+	// without the shard key the request fails unless scatter gather is on
+	gql1 := `query getAllAccounts { accounts(order_by: { id: asc }) { name } }`
+	if _, err = gj.GraphQL(context.Background(), gql1, nil, nil); err == nil {
+		t.Fatal("expected an error without the shard key")
+	}
+
+	conf.Tables[0].Shards.ScatterGather = true
+	gj, err = core.NewGraphJin(conf, primary,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetDatabases(map[string]*sql.DB{"shard1": shard1, "shard2": shard2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"accounts":[{"name":"shard1"},{"name":"shard2"}]}`; string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	// mutations always need the shard key
+	_, err = gj.GraphQL(context.Background(),
+		`mutation addAccount { accounts(insert: { id: 2, name: "x" }) { id } }`, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for a mutation without the shard key")
+	}
+}
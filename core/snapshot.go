@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+// SchemaSnapshotVersion is the current version of the schema snapshot format
+const SchemaSnapshotVersion = 1
+
+// SchemaSnapshot holds the discovered schema metadata for all configured
+// databases. It can be used to boot GraphJin when a database is unreachable.
+type SchemaSnapshot struct {
+	Version   int                         `json:"version"`
+	CreatedAt time.Time                   `json:"created_at"`
+	Databases map[string]DatabaseSnapshot `json:"databases"`
+}
+
+// DatabaseSnapshot holds the discovered schema metadata for a single database
+type DatabaseSnapshot struct {
+	Type           string                  `json:"type"`
+	Version        int                     `json:"version"`
+	Schema         string                  `json:"schema"`
+	Name           string                  `json:"name"`
+	Columns        []sdata.DBColumn        `json:"columns"`
+	Functions      []sdata.DBFunction      `json:"functions,omitempty"`
+	CompositeFKs   []sdata.CompositeFKInfo `json:"composite_fks,omitempty"`
+	ClusteringKeys map[string][]string     `json:"clustering_keys,omitempty"`
+}
+
+// SchemaSnapshot returns the discovered schema metadata for all databases
+// as a versioned snapshot
+func (g *GraphJin) SchemaSnapshot() (ss SchemaSnapshot, err error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return
+	}
+	return gj.schemaSnapshot(), nil
+}
+
+// IsDegraded returns true when one or more databases were unreachable at
+// startup and their schema was loaded from the schema snapshot file
+func (g *GraphJin) IsDegraded() bool {
+	gj, err := g.getEngine()
+	if err != nil {
+		return false
+	}
+	gj.degradedMu.Lock()
+	defer gj.degradedMu.Unlock()
+	return len(gj.degraded) != 0
+}
+
+func (gj *graphjinEngine) schemaSnapshot() SchemaSnapshot {
+	ss := SchemaSnapshot{
+		Version:   SchemaSnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Databases: make(map[string]DatabaseSnapshot, len(gj.databases)),
+	}
+
+	for name, ctx := range gj.databases {
+		if ctx.lazy.Load() || ctx.dbinfo == nil {
+			continue
+		}
+		ss.Databases[name] = newDatabaseSnapshot(ctx.dbinfo)
+	}
+	return ss
+}
+
+func newDatabaseSnapshot(di *sdata.DBInfo) DatabaseSnapshot {
+	ds := DatabaseSnapshot{
+		Type:         di.Type,
+		Version:      di.Version,
+		Schema:       di.Schema,
+		Name:         di.Name,
+		Functions:    di.Functions,
+		CompositeFKs: di.CompositeFKs,
+	}
+
+	// only tables built from discovered columns, function, virtual
+	// and remote tables are recreated from config on startup
+	for _, t := range di.Tables {
+		if t.Type != "" {
+			continue
+		}
+		ds.Columns = append(ds.Columns, t.Columns...)
+
+		if len(t.ClusteringKeys) != 0 {
+			if ds.ClusteringKeys == nil {
+				ds.ClusteringKeys = make(map[string][]string)
+			}
+			ds.ClusteringKeys[t.Schema+":"+t.Name] = t.ClusteringKeys
+		}
+	}
+
+	sort.SliceStable(ds.Columns, func(i, j int) bool {
+		a, b := ds.Columns[i], ds.Columns[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.ID < b.ID
+	})
+	return ds
+}
+
+// dbInfo rebuilds the schema metadata from the snapshot
+func (ds DatabaseSnapshot) dbInfo(blocklist []string) *sdata.DBInfo {
+	di := sdata.NewDBInfo(ds.Type, ds.Version, ds.Schema, ds.Name,
+		ds.Columns, ds.Functions, blocklist)
+	di.CompositeFKs = ds.CompositeFKs
+
+	for i := range di.Tables {
+		t := &di.Tables[i]
+		if keys, ok := ds.ClusteringKeys[t.Schema+":"+t.Name]; ok {
+			t.ClusteringKeys = keys
+		}
+	}
+	return di
+}
+
+// ParseSchemaSnapshot parses a schema snapshot file
+func ParseSchemaSnapshot(b []byte) (ss SchemaSnapshot, err error) {
+	if err = json.Unmarshal(b, &ss); err != nil {
+		err = fmt.Errorf("schema snapshot: %w", err)
+		return
+	}
+	if ss.Version != SchemaSnapshotVersion {
+		err = fmt.Errorf("schema snapshot: unsupported version %d", ss.Version)
+	}
+	return
+}
+
+// loadSchemaSnapshot reads the configured schema snapshot file
+func (gj *graphjinEngine) loadSchemaSnapshot() (ss SchemaSnapshot, err error) {
+	b, err := gj.fs.Get(gj.conf.SchemaSnapshot)
+	if err != nil {
+		return
+	}
+	return ParseSchemaSnapshot(b)
+}
+
+// saveSchemaSnapshot writes the schema metadata of all databases to
+// the configured schema snapshot file
+func (gj *graphjinEngine) saveSchemaSnapshot() error {
+	b, err := json.MarshalIndent(gj.schemaSnapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return gj.fs.Put(gj.conf.SchemaSnapshot, b)
+}
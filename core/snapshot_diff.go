@@ -0,0 +1,231 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+// SchemaChange describes a single difference between two schema snapshots
+type SchemaChange struct {
+	// Type is one of add_database, remove_database, add_table, remove_table,
+	// add_column, remove_column, change_type, change_nullable, add_fk,
+	// remove_fk or change_fk
+	Type     string `json:"type"`
+	Database string `json:"database"`
+	Table    string `json:"table,omitempty"`
+	Column   string `json:"column,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+func (sc SchemaChange) String() string {
+	name := sc.Database
+	if sc.Table != "" {
+		name += ":" + sc.Table
+	}
+	if sc.Column != "" {
+		name += "." + sc.Column
+	}
+	switch {
+	case sc.From != "" && sc.To != "":
+		return fmt.Sprintf("%s %s (%s -> %s)", sc.Type, name, sc.From, sc.To)
+	case sc.To != "":
+		return fmt.Sprintf("%s %s (%s)", sc.Type, name, sc.To)
+	case sc.From != "":
+		return fmt.Sprintf("%s %s (%s)", sc.Type, name, sc.From)
+	}
+	return sc.Type + " " + name
+}
+
+// DiffSchemaSnapshots returns the changes needed to go from the first
+// snapshot to the second one. An empty result means there is no drift.
+func DiffSchemaSnapshots(from, to SchemaSnapshot) []SchemaChange {
+	var changes []SchemaChange
+
+	for _, name := range unionKeys(from.Databases, to.Databases) {
+		f, inFrom := from.Databases[name]
+		t, inTo := to.Databases[name]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, SchemaChange{Type: "add_database", Database: name})
+		case !inTo:
+			changes = append(changes, SchemaChange{Type: "remove_database", Database: name})
+		default:
+			changes = append(changes, diffDatabaseSnapshots(name, f, t)...)
+		}
+	}
+	return changes
+}
+
+func diffDatabaseSnapshots(dbName string, from, to DatabaseSnapshot) []SchemaChange {
+	var changes []SchemaChange
+
+	ft, tt := snapshotTables(from), snapshotTables(to)
+
+	for _, table := range unionKeys(ft, tt) {
+		fcols, inFrom := ft[table]
+		tcols, inTo := tt[table]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, SchemaChange{Type: "add_table", Database: dbName, Table: table})
+			continue
+		case !inTo:
+			changes = append(changes, SchemaChange{Type: "remove_table", Database: dbName, Table: table})
+			continue
+		}
+
+		for _, col := range unionKeys(fcols, tcols) {
+			fc, inFrom := fcols[col]
+			tc, inTo := tcols[col]
+			ch := SchemaChange{Database: dbName, Table: table, Column: col}
+
+			switch {
+			case !inFrom:
+				ch.Type, ch.To = "add_column", columnType(tc)
+				changes = append(changes, ch)
+				if fk := columnFK(tc); fk != "" {
+					ch.Type, ch.To = "add_fk", fk
+					changes = append(changes, ch)
+				}
+				continue
+			case !inTo:
+				ch.Type, ch.From = "remove_column", columnType(fc)
+				changes = append(changes, ch)
+				continue
+			}
+
+			if columnType(fc) != columnType(tc) {
+				ch.Type, ch.From, ch.To = "change_type", columnType(fc), columnType(tc)
+				changes = append(changes, ch)
+			}
+			if fc.NotNull != tc.NotNull {
+				ch.Type, ch.From, ch.To = "change_nullable", nullability(fc), nullability(tc)
+				changes = append(changes, ch)
+			}
+
+			ffk, tfk := columnFK(fc), columnFK(tc)
+			switch {
+			case ffk == tfk:
+			case ffk == "":
+				ch.Type, ch.From, ch.To = "add_fk", "", tfk
+				changes = append(changes, ch)
+			case tfk == "":
+				ch.Type, ch.From, ch.To = "remove_fk", ffk, ""
+				changes = append(changes, ch)
+			default:
+				ch.Type, ch.From, ch.To = "change_fk", ffk, tfk
+				changes = append(changes, ch)
+			}
+		}
+	}
+	return changes
+}
+
+func snapshotTables(ds DatabaseSnapshot) map[string]map[string]sdata.DBColumn {
+	tables := make(map[string]map[string]sdata.DBColumn)
+	for _, c := range ds.Columns {
+		key := c.Table
+		if c.Schema != "" {
+			key = c.Schema + "." + c.Table
+		}
+		if tables[key] == nil {
+			tables[key] = make(map[string]sdata.DBColumn)
+		}
+		tables[key][c.Name] = c
+	}
+	return tables
+}
+
+func columnType(c sdata.DBColumn) string {
+	if c.Array {
+		return c.Type + "[]"
+	}
+	return c.Type
+}
+
+func nullability(c sdata.DBColumn) string {
+	if c.NotNull {
+		return "not null"
+	}
+	return "null"
+}
+
+func columnFK(c sdata.DBColumn) string {
+	if c.FKeyTable == "" {
+		return ""
+	}
+	fk := c.FKeyTable + "." + c.FKeyCol
+	if c.FKeySchema != "" {
+		fk = c.FKeySchema + "." + fk
+	}
+	if c.FKeyDatabase != "" {
+		fk = c.FKeyDatabase + ":" + fk
+	}
+	return fk
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DiscoverSchemaSnapshot discovers the schema of the given databases and
+// returns it as a snapshot. The dbTypes map is keyed by database name.
+func DiscoverSchemaSnapshot(dbs map[string]*sql.DB,
+	dbTypes map[string]string,
+	blocklist []string,
+) (ss SchemaSnapshot, err error) {
+	ss = SchemaSnapshot{
+		Version:   SchemaSnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Databases: make(map[string]DatabaseSnapshot, len(dbs)),
+	}
+
+	for name, db := range dbs {
+		var di *sdata.DBInfo
+		if di, err = sdata.GetDBInfo(db, dbTypes[name], blocklist); err != nil {
+			err = fmt.Errorf("database %s: schema discovery failed: %w", name, err)
+			return
+		}
+		ss.Databases[name] = newDatabaseSnapshot(di)
+	}
+	return
+}
+
+// SchemaDrift compares the snapshot against the live schema of all
+// configured databases and returns the changes found
+func (g *GraphJin) SchemaDrift(ss SchemaSnapshot) ([]SchemaChange, error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	live := gj.schemaSnapshot()
+	for name, ctx := range gj.databases {
+		if ctx.db == nil {
+			continue
+		}
+		di, err := sdata.GetDBInfo(ctx.db, ctx.dbtype, gj.conf.Blocklist)
+		if err != nil {
+			return nil, fmt.Errorf("database %s: schema discovery failed: %w", name, err)
+		}
+		live.Databases[name] = newDatabaseSnapshot(di)
+	}
+
+	return DiffSchemaSnapshots(ss, live), nil
+}
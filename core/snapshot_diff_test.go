@@ -0,0 +1,72 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+func TestDiffSchemaSnapshots(t *testing.T) {
+	col := func(table, name, typ string) sdata.DBColumn {
+		return sdata.DBColumn{Schema: "public", Table: table, Name: name, Type: typ}
+	}
+	fk := func(c sdata.DBColumn, table, name string) sdata.DBColumn {
+		c.FKeySchema, c.FKeyTable, c.FKeyCol = "public", table, name
+		return c
+	}
+
+	from := SchemaSnapshot{Databases: map[string]DatabaseSnapshot{
+		"default": {Columns: []sdata.DBColumn{
+			col("users", "id", "integer"),
+			col("users", "email", "text"),
+			col("users", "age", "integer"),
+			fk(col("posts", "user_id", "integer"), "users", "id"),
+			col("posts", "id", "integer"),
+			col("tags", "id", "integer"),
+		}},
+		"legacy": {},
+	}}
+
+	notNull := col("users", "email", "varchar")
+	notNull.NotNull = true
+
+	to := SchemaSnapshot{Databases: map[string]DatabaseSnapshot{
+		"default": {Columns: []sdata.DBColumn{
+			col("users", "id", "integer"),
+			notNull,
+			col("users", "name", "text"),
+			col("posts", "user_id", "integer"),
+			fk(col("posts", "tag_id", "integer"), "tags", "id"),
+			col("posts", "id", "integer"),
+			col("comments", "id", "integer"),
+		}},
+		"analytics": {},
+	}}
+
+	got := DiffSchemaSnapshots(from, to)
+	exp := []SchemaChange{
+		{Type: "add_database", Database: "analytics"},
+		{Type: "add_table", Database: "default", Table: "public.comments"},
+		{Type: "add_column", Database: "default", Table: "public.posts", Column: "tag_id", To: "integer"},
+		{Type: "add_fk", Database: "default", Table: "public.posts", Column: "tag_id", To: "public.tags.id"},
+		{Type: "remove_fk", Database: "default", Table: "public.posts", Column: "user_id", From: "public.users.id"},
+		{Type: "remove_table", Database: "default", Table: "public.tags"},
+		{Type: "remove_column", Database: "default", Table: "public.users", Column: "age", From: "integer"},
+		{Type: "change_type", Database: "default", Table: "public.users", Column: "email", From: "text", To: "varchar"},
+		{Type: "change_nullable", Database: "default", Table: "public.users", Column: "email", From: "null", To: "not null"},
+		{Type: "add_column", Database: "default", Table: "public.users", Column: "name", To: "text"},
+		{Type: "remove_database", Database: "legacy"},
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		for _, c := range got {
+			t.Log(c)
+		}
+		t.Fatal("unexpected schema changes")
+	}
+
+	if changes := DiffSchemaSnapshots(to, to); len(changes) != 0 {
+		t.Fatalf("expected no drift, got %v", changes)
+	}
+}
@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSchemaSnapshotOfflineStartup(t *testing.T) {
+	dir := t.TempDir()
+	fs := core.NewOsFS(dir)
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), title TEXT);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", SchemaSnapshot: "snapshot.json"}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gj.IsDegraded() {
+		t.Fatal("expected a healthy startup")
+	}
+	live, err := gj.GetTableSchema("posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fs.Get("snapshot.json")
+	if err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	ss, err := core.ParseSchemaSnapshot(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss.Databases[core.DefaultDBName].Columns) != 5 {
+		t.Fatalf("unexpected snapshot: %+v", ss.Databases)
+	}
+
+	// a closed connection makes the database unreachable
+	offline, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline.Close() //nolint:errcheck
+
+	conf = &core.Config{DBType: "sqlite", Production: true, SchemaSnapshot: "snapshot.json"}
+	gj, err = core.NewGraphJin(conf, offline, core.OptionSetFS(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gj.IsDegraded() {
+		t.Fatal("expected a degraded startup")
+	}
+
+	ts, err := gj.GetTableSchema("posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// discovery does not guarantee column order
+	for _, v := range []*core.TableSchema{ts, live} {
+		sort.Slice(v.Columns, func(i, j int) bool { return v.Columns[i].Name < v.Columns[j].Name })
+	}
+	if !reflect.DeepEqual(ts, live) {
+		t.Fatalf("snapshot schema differs from live schema:\n%+v\n%+v", ts, live)
+	}
+
+	// without a snapshot the startup fails as before
+	conf = &core.Config{DBType: "sqlite", Production: true}
+	if _, err := core.NewGraphJin(conf, offline, core.OptionSetFS(fs)); err == nil {
+		t.Fatal("expected discovery to fail without a snapshot")
+	}
+}
+
+func TestParseSchemaSnapshotVersion(t *testing.T) {
+	if _, err := core.ParseSchemaSnapshot([]byte(`{"version": 99}`)); err == nil {
+		t.Fatal("expected unsupported version error")
+	}
+}
@@ -40,6 +40,10 @@ type sub struct {
 	del          chan *Member
 	updt         chan mmsg
 	done         chan struct{}
+	// poke triggers a poll right away when the tables read by the
+	// subscription are changed (see NotifyChanges)
+	poke   chan struct{}
+	tables atomic.Pointer[map[string]struct{}]
 
 	mval
 	sync.Once
@@ -107,7 +111,7 @@ func (g *GraphJin) Subscribe(
 	// from allow list
 	if gj.prodSec {
 		var item allow.Item
-		item, err = gj.allowList.GetByName(h.Name, true)
+		item, err = gj.getAllowItem(r.namespace, h.Name, true)
 		if err != nil {
 			return
 		}
@@ -133,11 +137,12 @@ func (g *GraphJin) SubscribeByName(
 		return
 	}
 
-	item, err := gj.allowList.GetByName(name, gj.prod)
+	r := gj.newGraphqlReq(rc, "subscription", name, nil, vars)
+
+	item, err := gj.getAllowItem(r.namespace, name, gj.prod)
 	if err != nil {
 		return
 	}
-	r := gj.newGraphqlReq(rc, "subscription", name, nil, vars)
 	r.Set(item)
 
 	m, err = gj.subscribe(c, r)
@@ -182,6 +187,7 @@ func (gj *graphjinEngine) subscribe(c context.Context, r GraphqlReq) (
 			del:  make(chan *Member),
 			updt: make(chan mmsg, 10),
 			done: make(chan struct{}),
+			poke: make(chan struct{}, 1),
 		})
 		sub := v.(*sub)
 
@@ -245,6 +251,12 @@ func (gj *graphjinEngine) initSub(c context.Context, sub *sub) (err error) {
 		sub.s.cs.st.sql = renderSubWrap(sub.s.cs.st, targetCtx.schema.DBType())
 	}
 
+	tables := make(map[string]struct{})
+	for _, sel := range sub.s.cs.st.qc.Selects {
+		tables[sel.Ti.Name] = struct{}{}
+	}
+	sub.tables.Store(&tables)
+
 	go gj.subController(sub)
 	return
 }
@@ -283,6 +295,9 @@ func (gj *graphjinEngine) subController(sub *sub) {
 		case <-time.After(ps):
 			sub.fanOutJobs(gj)
 
+		case <-sub.poke:
+			sub.fanOutJobs(gj)
+
 		case <-gj.done:
 			return
 		}
@@ -455,7 +470,8 @@ func (gj *graphjinEngine) subCheckUpdates(sub *sub, mv mval, start int) {
 	var rows *sql.Rows
 	var err error
 
-	c := context.Background()
+	c, cancel := gj.withTimeout(context.Background(), qcode.QTSubscription)
+	defer cancel()
 
 	// when params are not available we use a more optimized
 	// codepath that does not use a join query
@@ -559,7 +575,8 @@ func (gj *graphjinEngine) subCheckUpdates(sub *sub, mv mval, start int) {
 
 // subFirstQuery function is called on the graphjin struct to get the first query.
 func (gj *graphjinEngine) subFirstQuery(sub *sub, m *Member) (mmsg, error) {
-	c := context.Background()
+	c, cancel := gj.withTimeout(context.Background(), qcode.QTSubscription)
+	defer cancel()
 
 	// when params are not available we use a more optimized
 	// codepath that does not use a join query
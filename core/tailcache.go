@@ -0,0 +1,255 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+const (
+	// tailCachePx prefixes the response cache keys of tail cached lists
+	tailCachePx = "tail:"
+	// tailVar is the variable holding the largest cached tail value
+	tailVar = "__gj_tail"
+)
+
+// tailEntry is a cached list of an append-only table with the largest
+// value of the tail column in the list
+type tailEntry struct {
+	Max  json.RawMessage `json:"max"`
+	Data json.RawMessage `json:"data"`
+}
+
+// tailList is the root list of a query that can be tail cached
+type tailList struct {
+	sel   *qcode.Select
+	col   sdata.DBColumn
+	field string // key of the tail column in the rows
+	desc  bool
+}
+
+// hasTailCache returns true if any table is tail cached
+func (gj *graphjinEngine) hasTailCache() bool {
+	for _, t := range gj.tables() {
+		if t.TailCache != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// tailCacheConfig returns the tail cache config of a table
+func (gj *graphjinEngine) tailCacheConfig(table string) *TailCacheConfig {
+	for _, t := range gj.tables() {
+		if t.Name == table && t.TailCache != nil {
+			return t.TailCache
+		}
+	}
+	return nil
+}
+
+// tailList returns the root list of the query when it can be tail cached:
+// a single root list on a tail cached table, ordered by the tail column
+// first, without offset or cursor pagination, that selects the tail column
+func (s *gstate) tailList(qc *qcode.QCode) (tl tailList, ok bool) {
+	if qc == nil || qc.Type != qcode.QTQuery || len(qc.Roots) != 1 {
+		return
+	}
+	sel := &qc.Selects[qc.Roots[0]]
+
+	tc := s.gj.tailCacheConfig(sel.Table)
+	if tc == nil || sel.Singular || sel.Type != qcode.SelTypeNone ||
+		sel.GroupCols || len(sel.DistinctOn) != 0 || sel.Paging.Cursor ||
+		sel.Paging.Offset != 0 || sel.Paging.OffsetVar != "" {
+		return
+	}
+
+	colName := tc.Column
+	if colName == "" {
+		colName = sel.Ti.PrimaryCol.Name
+	}
+	if colName == "" || len(sel.OrderBy) == 0 {
+		return
+	}
+
+	ob := sel.OrderBy[0]
+	if ob.Col.Name != colName || ob.KeyVar != "" || ob.Var != "" {
+		return
+	}
+	switch ob.Order {
+	case qcode.OrderAsc:
+	case qcode.OrderDesc:
+		tl.desc = true
+	default:
+		return
+	}
+
+	for _, f := range sel.Fields {
+		if f.Type == qcode.FieldTypeCol && f.Col.Name == colName &&
+			f.SkipRender == qcode.SkipTypeNone {
+			tl.sel, tl.col, tl.field = sel, f.Col, f.FieldName
+			return tl, true
+		}
+	}
+	return
+}
+
+// tailCacheGet looks up the cached list of the query, the query then only
+// fetches the rows added since. Misses are reported by the response cache
+// lookup that follows
+func (s *gstate) tailCacheGet(c context.Context) bool {
+	data, _, found := s.gj.responseCache.Get(c, tailCachePx+s.cacheKey)
+	if !found {
+		return false
+	}
+	s.gj.cacheLookup(CacheResponse, true)
+
+	var te tailEntry
+	if err := json.Unmarshal(data, &te); err != nil || len(te.Max) == 0 {
+		return false
+	}
+	s.tail = &te
+	return true
+}
+
+// useTailStmt switches the compiled statement to one that only fetches the
+// rows with a tail value larger than the cached one. In production the
+// statement is compiled once with the query
+func (s *gstate) useTailStmt() error {
+	tl, ok := s.tailList(s.cs.st.qc)
+	if !ok {
+		s.tail = nil
+		return nil
+	}
+
+	cs := s.cs
+	cs.tailOnce.Do(func() {
+		cs.tail, cs.tailErr = s.compileTailStmt(cs.st, tl)
+	})
+	if cs.tailErr != nil {
+		return cs.tailErr
+	}
+
+	if s.vmap == nil {
+		s.vmap = make(map[string]json.RawMessage)
+	}
+	s.vmap[tailVar] = s.tail.Max
+	s.cs = &cstate{st: *cs.tail}
+	s.tailRun = true
+	return nil
+}
+
+// compileTailStmt compiles the query with the filter tail column > $__gj_tail
+// added to the root list
+func (s *gstate) compileTailStmt(st stmt, tl tailList) (*stmt, error) {
+	ex := &qcode.Exp{Op: qcode.OpGreaterThan}
+	ex.Left.ID = -1
+	ex.Left.Col = tl.col
+	ex.Right.ValType = qcode.ValVar
+	ex.Right.Val = tailVar
+
+	st.qc = st.qc.WithFilter(tl.sel.ID, ex)
+
+	var w bytes.Buffer
+	md, err := s.getTargetPsqlCompiler().Compile(&w, st.qc)
+	if err != nil {
+		return nil, err
+	}
+	st.md = md
+	st.sql = w.String()
+	return &st, nil
+}
+
+// mergeTail adds the rows fetched by the tail statement to the cached list
+// and trims it to the limit of the query
+func (s *gstate) mergeTail() error {
+	if !s.tailRun {
+		return nil
+	}
+	tl, ok := s.tailList(s.cs.st.qc)
+	if !ok {
+		return nil
+	}
+
+	var fresh, cached map[string]json.RawMessage
+	if err := json.Unmarshal(s.data, &fresh); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(s.tail.Data, &cached); err != nil {
+		return err
+	}
+
+	var newRows, oldRows []json.RawMessage
+	if err := json.Unmarshal(fresh[tl.sel.FieldName], &newRows); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(cached[tl.sel.FieldName], &oldRows); err != nil {
+		return err
+	}
+
+	var rows []json.RawMessage
+	if tl.desc {
+		rows = append(newRows, oldRows...)
+	} else {
+		rows = append(oldRows, newRows...)
+	}
+	if limit := s.tailLimit(tl.sel); limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	cached[tl.sel.FieldName] = b
+
+	s.data, err = json.Marshal(cached)
+	return err
+}
+
+// tailLimit returns the row limit of the list, 0 when unlimited
+func (s *gstate) tailLimit(sel *qcode.Select) int {
+	if sel.Paging.NoLimit {
+		return 0
+	}
+	if sel.Paging.LimitVar != "" {
+		if v, ok := s.vmap[sel.Paging.LimitVar]; ok {
+			if n, err := strconv.Atoi(string(v)); err == nil {
+				return n
+			}
+		}
+	}
+	return int(sel.Paging.Limit)
+}
+
+// tailCacheSet stores the list with the largest tail value, lists without
+// rows are not stored since there is no tail value to fetch from
+func (s *gstate) tailCacheSet(c context.Context, tl tailList, data []byte, refs []RowRef) {
+	var res map[string]json.RawMessage
+	if err := json.Unmarshal(data, &res); err != nil {
+		return
+	}
+	var rows []map[string]json.RawMessage
+	if err := json.Unmarshal(res[tl.sel.FieldName], &rows); err != nil || len(rows) == 0 {
+		return
+	}
+
+	last := rows[0]
+	if !tl.desc {
+		last = rows[len(rows)-1]
+	}
+	max, ok := last[tl.field]
+	if !ok || bytes.Equal(max, []byte("null")) {
+		return
+	}
+
+	b, err := json.Marshal(tailEntry{Max: max, Data: data})
+	if err != nil {
+		return
+	}
+	_ = s.gj.responseCache.Set(c, tailCachePx+s.cacheKey, b, refs, s.queryStarted)
+}
@@ -0,0 +1,137 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type testResponseCache struct {
+	mu          sync.Mutex
+	data        map[string][]byte
+	hints       []core.CacheHints
+	refs        [][]core.RowRef
+	invalidated []core.RowRef
+}
+
+func (rc *testResponseCache) Get(ctx context.Context, key string) ([]byte, bool, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	v, ok := rc.data[key]
+	return v, false, ok
+}
+
+func (rc *testResponseCache) Set(ctx context.Context, key string, data []byte, refs []core.RowRef, started time.Time) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.data[key] = data
+	rc.refs = append(rc.refs, refs)
+	if ch, ok := core.CacheHintsFromContext(ctx); ok {
+		rc.hints = append(rc.hints, ch)
+	}
+	return nil
+}
+
+func (rc *testResponseCache) InvalidateRows(ctx context.Context, refs []core.RowRef) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.invalidated = append(rc.invalidated, refs...)
+	return nil
+}
+
+func TestTailCache(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO events (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc := &testResponseCache{data: make(map[string][]byte)}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Tables: []core.Table{{
+			Name:      "events",
+			TailCache: &core.TailCacheConfig{},
+		}},
+	}
+	gj, err := core.NewGraphJin(conf, db,
+		core.OptionSetFS(core.NewOsFS(dir)),
+		core.OptionSetResponseCache(rc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(gql string) (string, string) {
+		t.Helper()
+		res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v struct {
+			Events []struct {
+				ID int `json:"id"`
+			} `json:"events"`
+		}
+		if err := json.Unmarshal(res.Data, &v); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, e := range v.Events {
+			ids = append(ids, string(rune('0'+e.ID)))
+		}
+		return strings.Join(ids, ","), res.SQL()
+	}
+
+	newest := `query newest { events(order_by: { id: desc }, limit: 3) { id name } }`
+	oldest := `query oldest { events(order_by: { id: asc }) { id name } }`
+
+	if ids, _ := run(newest); ids != "3,2,1" {
+		t.Fatalf("unexpected rows: %s", ids)
+	}
+	if ids, _ := run(oldest); ids != "1,2,3" {
+		t.Fatalf("unexpected rows: %s", ids)
+	}
+
+	if _, err := db.Exec(`INSERT INTO events (id, name) VALUES (4, 'd'), (5, 'e')`); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, sql := run(newest)
+	if ids != "5,4,3" {
+		t.Errorf("expected the new rows merged with the cached list, got %s", ids)
+	}
+	if !strings.Contains(sql, `"id") > `) {
+		t.Errorf("expected only the rows after the tail to be fetched: %s", sql)
+	}
+	if ids, _ := run(oldest); ids != "1,2,3,4,5" {
+		t.Errorf("expected the new rows appended to the cached list, got %s", ids)
+	}
+
+	// the merged list becomes the cached tail
+	if _, err := db.Exec(`INSERT INTO events (id, name) VALUES (6, 'f')`); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := run(newest); ids != "6,5,4" {
+		t.Errorf("unexpected rows: %s", ids)
+	}
+}
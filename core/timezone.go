@@ -0,0 +1,39 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dosco/graphjin/core/v3/internal/psql"
+	"github.com/dosco/graphjin/core/v3/internal/qcode"
+	"github.com/dosco/graphjin/core/v3/internal/sdata"
+)
+
+var (
+	// timezoneRe matches timezone names like UTC, Europe/Berlin and offsets
+	// like +05:30
+	timezoneRe = regexp.MustCompile(`^[A-Za-z0-9_+\-:/ ]{1,64}$`)
+	varNameRe  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// checkTimeArg validates the values of the timezone variable and when
+// required the timezone offset of timestamp values
+func (gj *graphjinEngine) checkTimeArg(p psql.Param, v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+
+	if p.Type == psql.TimezoneType {
+		if !timezoneRe.MatchString(s) {
+			return fmt.Errorf("variable '%s' is not a valid timezone: %s", p.Name, s)
+		}
+		return nil
+	}
+
+	if ok, _ := sdata.IsTimestampType(p.Type); ok && !p.IsArray &&
+		gj.conf.Timezone.RequireOffset && !qcode.HasTimezoneOffset(s) {
+		return fmt.Errorf("variable '%s' must be a timestamp with a timezone offset: %s", p.Name, s)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package core_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestTimezoneOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "main.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, starts_at TIMESTAMP, ends_at TIMESTAMPTZ);
+		INSERT INTO events (id, starts_at, ends_at) VALUES
+			(1, '2024-01-01 10:00:00', '2024-01-01T14:30:00+02:00');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{
+		DBType:           "sqlite",
+		DisableAllowList: true,
+		Timezone: core.TimezoneConfig{
+			Output:        "UTC",
+			RequireOffset: true,
+		},
+	}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gj.GraphQL(t.Context(), `query { events { id starts_at ends_at } }`, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"events":[{"id":1,"starts_at":"2024-01-01T10:00:00.000Z","ends_at":"2024-01-01T12:30:00.000Z"}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+
+	_, err = gj.GraphQL(t.Context(), `query {
+		events(where: { starts_at: { gt: "2023-12-31 10:00:00" } }) { id }
+	}`, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "timezone offset") {
+		t.Fatalf("expected a timezone offset error, got %v", err)
+	}
+
+	_, err = gj.GraphQL(t.Context(), `query ($after: String) {
+		events(where: { starts_at: { gt: $after } }) { id }
+	}`, []byte(`{"after": "2023-12-31 10:00:00"}`), nil)
+	if err == nil || !strings.Contains(err.Error(), "timezone offset") {
+		t.Fatalf("expected a timezone offset error, got %v", err)
+	}
+
+	res, err = gj.GraphQL(t.Context(), `query ($after: String) {
+		events(where: { starts_at: { gt: $after } }) { id }
+	}`, []byte(`{"after": "2023-12-31T10:00:00Z"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = `{"events":[{"id":1}]}`
+	if string(res.Data) != exp {
+		t.Fatalf("expected %s, got %s", exp, res.Data)
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3/internal/graph"
+)
+
+// TxRequest is a single operation run as part of a transaction by
+// GraphQLBatchTx. Either the query or the name of a saved query must be set.
+type TxRequest struct {
+	Name  string          `json:"name,omitempty"`
+	Query string          `json:"query,omitempty"`
+	Vars  json.RawMessage `json:"variables,omitempty"`
+}
+
+// GraphQLBatchTx runs several operations (usually named mutations) inside a
+// single database transaction. If any operation fails the transaction is
+// rolled back and the error is returned along with the results so far.
+// All operations must target the same database, cross-database requests
+// are rejected.
+func (g *GraphJin) GraphQLBatchTx(c context.Context,
+	reqs []TxRequest,
+	rc *RequestConfig,
+) (res []*Result, err error) {
+	gj, err := g.getEngine()
+	if err != nil {
+		return
+	}
+
+	if len(reqs) == 0 {
+		err = errors.New("transaction: no operations")
+		return
+	}
+
+	dbCtx, err := gj.txDatabase(reqs)
+	if err != nil {
+		return
+	}
+	if dbCtx.dbtype == "mongodb" {
+		err = fmt.Errorf("transaction: not supported for database type %s", dbCtx.dbtype)
+		return
+	}
+
+	c1, span := gj.spanStart(c, "GraphJin Transaction")
+	defer span.End()
+
+	tx, err := dbCtx.db.BeginTx(c1, nil)
+	if err != nil {
+		span.Error(err)
+		return
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var rc1 RequestConfig
+	if rc != nil {
+		rc1 = *rc
+	}
+	rc1.Tx = tx
+
+	for i, r := range reqs {
+		var r1 *Result
+		if r.Query != "" {
+			r1, err = g.GraphQL(c1, r.Query, r.Vars, &rc1)
+		} else {
+			r1, err = g.GraphQLByName(c1, r.Name, r.Vars, &rc1)
+		}
+		res = append(res, r1)
+
+		if err == nil && r1 != nil && len(r1.Errors) != 0 {
+			err = errors.New(r1.Errors[0].Message)
+		}
+		if err != nil {
+			err = fmt.Errorf("transaction: operation %d: %w", i, err)
+			span.Error(err)
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.Error(err)
+	}
+	return
+}
+
+// txDatabase returns the database all the operations in a transaction
+// target, an error is returned if they target more than one
+func (gj *graphjinEngine) txDatabase(reqs []TxRequest) (*dbContext, error) {
+	var dbName string
+
+	for i, r := range reqs {
+		query := []byte(r.Query)
+		if len(query) == 0 {
+			if r.Name == "" {
+				return nil, fmt.Errorf("transaction: operation %d: query or name required", i)
+			}
+			item, err := gj.allowList.GetByName(r.Name, gj.prod)
+			if err != nil {
+				return nil, fmt.Errorf("transaction: operation %d: %w: %s", i, err, r.Name)
+			}
+			query = item.Query
+		}
+
+		op, err := graph.Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("transaction: operation %d: %w", i, err)
+		}
+
+		for _, f := range op.Fields {
+			if f.ParentID != -1 || f.Type == graph.FieldKeyword {
+				continue
+			}
+			db := gj.fieldDatabase(f)
+			if dbName != "" && db != dbName {
+				return nil, fmt.Errorf("transaction: cross-database operations are not supported (%s, %s)",
+					dbName, db)
+			}
+			dbName = db
+		}
+	}
+
+	if dbName == "" {
+		dbName = gj.defaultDB
+	}
+	dbCtx, ok := gj.GetDatabase(dbName)
+	if !ok {
+		return nil, fmt.Errorf("transaction: database not found: %s", dbName)
+	}
+	return dbCtx, nil
+}
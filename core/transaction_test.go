@@ -0,0 +1,84 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestGraphQLBatchTx(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		INSERT INTO users (id, name) VALUES (1, 'alice');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insert := `mutation { users(insert: $data) { id } }`
+	update := `mutation { users(id: $id, update: $data) { id name } }`
+
+	count := func() (n int) {
+		if err := db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		res, err := gj.GraphQLBatchTx(context.Background(), []core.TxRequest{
+			{Query: insert, Vars: json.RawMessage(`{"data": {"id": 2, "name": "bob"}}`)},
+			{Query: update, Vars: json.RawMessage(`{"id": 1, "data": {"name": "alicia"}}`)},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(res))
+		}
+		if got := string(res[1].Data); got != `{"users":{"id":1,"name":"alicia"}}` {
+			t.Fatalf("unexpected result: %s", got)
+		}
+		if n := count(); n != 2 {
+			t.Fatalf("expected 2 users, got %d", n)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		_, err := gj.GraphQLBatchTx(context.Background(), []core.TxRequest{
+			{Query: insert, Vars: json.RawMessage(`{"data": {"id": 3, "name": "carol"}}`)},
+			{Query: insert, Vars: json.RawMessage(`{"data": {"id": 4}}`)},
+		}, nil)
+		if err == nil {
+			t.Fatal("expected the second operation to fail")
+		}
+		if n := count(); n != 2 {
+			t.Fatalf("expected the transaction to be rolled back, got %d users", n)
+		}
+	})
+
+	t.Run("no operations", func(t *testing.T) {
+		if _, err := gj.GraphQLBatchTx(context.Background(), nil, nil); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -53,6 +53,16 @@ var dirTypes []dir = []dir{
 			atype: "String",
 		}},
 	},
+	{
+		name: "cache",
+		desc: "Set how long the response is kept in the response cache, overrides the TTL scaled by the query cost",
+		locs: []string{LOC_QUERY},
+		args: []dirArg{{
+			name:  "ttl",
+			desc:  "Time (in seconds) the response is cached",
+			atype: "Int",
+		}},
+	},
 	{
 		name: "skip",
 		desc: "Skip field if defined condition is met",
@@ -124,6 +134,53 @@ var dirTypes []dir = []dir{
 			name:  "table",
 			desc:  "Table name",
 			atype: "tables" + SUFFIX_ENUM,
+		}, {
+			name:  "column",
+			desc:  "Foreign key column on the join-table",
+			atype: "String",
+		}},
+	},
+	{
+		name: "hint",
+		desc: "Pass a query hint like an index to use to the database, only allowed for roles with allow_hints",
+		locs: []string{LOC_FIELD},
+		args: []dirArg{{
+			name:  "value",
+			desc:  "Hint for the database, eg. USE INDEX (idx_name)",
+			atype: "String",
+		}},
+	},
+	{
+		name: "object",
+		desc: "Return a single object instead of a list",
+		locs: []string{LOC_FIELD},
+	},
+	{
+		name: "optional",
+		desc: "Roll back this nested mutation branch on failure and commit the rest of the mutation",
+		locs: []string{LOC_FIELD},
+	},
+	{
+		name: "skipReturning",
+		desc: "Do not return the rows changed by the mutation",
+		locs: []string{LOC_MUTATION},
+	},
+	{
+		name: "deprecated",
+		desc: "Mark a saved query as deprecated, calls to it return a deprecation warning",
+		locs: []string{LOC_QUERY, LOC_MUTATION, LOC_SUBSCRIPTION},
+		args: []dirArg{{
+			name:  "reason",
+			desc:  "Why the query is deprecated",
+			atype: "String",
+		}, {
+			name:  "replacement",
+			desc:  "Name of the query to use instead",
+			atype: "String",
+		}, {
+			name:  "sunset",
+			desc:  "Date (YYYY-MM-DD) after which the query is removed",
+			atype: "String",
 		}},
 	},
 }
@@ -151,6 +208,8 @@ var expAll = []exp{
 var expScalar = []exp{
 	{name: "equals", desc: "Equals value"},
 	{name: "_eq", desc: "Equals value"},
+	{name: "equalsCI", desc: "Equals value ignoring case"},
+	{name: "_eq_ci", desc: "Equals value ignoring case"},
 	{name: "notEquals", desc: "Does not equal value"},
 	{name: "_neq", desc: "Does not equal value"},
 	{name: "greaterThan", desc: "Is greater than value"},
@@ -183,6 +242,13 @@ var expScalar = []exp{
 	{name: "_niregex", desc: "Value not matching (case-insensitive) regex pattern"},
 }
 
+var expTime = []exp{
+	{name: "withinLast", desc: "Is within the ISO-8601 duration before now, eg. P7D"},
+	{name: "_within_last", desc: "Is within the ISO-8601 duration before now, eg. P7D"},
+	{name: "olderThan", desc: "Is older than the ISO-8601 duration before now, eg. PT12H"},
+	{name: "_older_than", desc: "Is older than the ISO-8601 duration before now, eg. PT12H"},
+}
+
 var expList = []exp{
 	{name: "in", desc: "Is in list of values"},
 	{name: "_in", desc: "Is in list of values"},
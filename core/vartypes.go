@@ -0,0 +1,128 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// varType is a declared GraphQL variable type like [Int!]!
+type varType struct {
+	name    string
+	nonNull bool
+	elem    *varType // element type of a list
+}
+
+func parseVarType(s string) (*varType, error) {
+	t := &varType{}
+	if v, ok := strings.CutSuffix(s, "!"); ok {
+		t.nonNull = true
+		s = v
+	}
+	if v, ok := strings.CutPrefix(s, "["); ok {
+		v, ok = strings.CutSuffix(v, "]")
+		if !ok {
+			return nil, fmt.Errorf("invalid type '%s'", s)
+		}
+		elem, err := parseVarType(v)
+		if err != nil {
+			return nil, err
+		}
+		t.elem = elem
+		return t, nil
+	}
+	if s == "" {
+		return nil, errors.New("missing type name")
+	}
+	t.name = s
+	return t, nil
+}
+
+// checkVarTypes validates the request variables against the types declared
+// in the operation (eg. query ($ids: [Int!]!)) before they are bound. A single
+// value passed for a list type is wrapped in a list.
+func (s *gstate) checkVarTypes() error {
+	for _, v := range s.cs.st.qc.Vars {
+		if v.Type == "" {
+			continue
+		}
+		val, ok := s.vmap[v.Name]
+		if !ok {
+			continue
+		}
+		t, err := parseVarType(v.Type)
+		if err != nil {
+			return fmt.Errorf("variable '%s': %w", v.Name, err)
+		}
+		if val, err = coerceVar(t, val); err != nil {
+			return fmt.Errorf("variable '%s' of type '%s': %w", v.Name, v.Type, err)
+		}
+		s.vmap[v.Name] = val
+	}
+	return nil
+}
+
+func coerceVar(t *varType, v json.RawMessage) (json.RawMessage, error) {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 || bytes.Equal(v, []byte("null")) {
+		if t.nonNull {
+			return nil, errors.New("cannot be null")
+		}
+		return v, nil
+	}
+
+	if t.elem == nil {
+		return v, checkVarScalar(t.name, v)
+	}
+
+	if v[0] != '[' {
+		ev, err := coerceVar(t.elem, v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal([]json.RawMessage{ev})
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(v, &items); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		ev, err := coerceVar(t.elem, items[i])
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		items[i] = ev
+	}
+	return json.Marshal(items)
+}
+
+// checkVarScalar checks a value against the built-in scalar types and input
+// object types. Other (custom) scalars are not checked.
+func checkVarScalar(name string, v json.RawMessage) error {
+	var ok bool
+	switch name {
+	case "Int":
+		var n json.Number
+		if ok = json.Unmarshal(v, &n) == nil && v[0] != '"'; ok {
+			_, err := n.Int64()
+			ok = err == nil
+		}
+	case "Float":
+		ok = v[0] == '-' || (v[0] >= '0' && v[0] <= '9')
+	case "String":
+		ok = v[0] == '"'
+	case "ID":
+		ok = v[0] == '"' || checkVarScalar("Int", v) == nil
+	case "Boolean":
+		ok = bytes.Equal(v, []byte("true")) || bytes.Equal(v, []byte("false"))
+	default:
+		ok = !strings.HasSuffix(name, SUFFIX_INPUT) || v[0] == '{'
+	}
+	if !ok {
+		return fmt.Errorf("expected %s got %s", name, v)
+	}
+	return nil
+}
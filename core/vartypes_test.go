@@ -0,0 +1,72 @@
+package core_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestTypedListVariables(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	_, err = db.Exec(`
+		CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO products (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &core.Config{DBType: "sqlite", DisableAllowList: true}
+	gj, err := core.NewGraphJin(conf, db, core.OptionSetFS(core.NewOsFS(dir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gql := `query getProducts($ids: [Int!]!) {
+		products(where: { id: { in: $ids } }, order_by: { id: asc }) { id }
+	}`
+
+	tests := []struct {
+		name string
+		vars string
+		want string
+		err  string
+	}{
+		{name: "list", vars: `{"ids": [1, 3]}`, want: `{"products":[{"id":1},{"id":3}]}`},
+		{name: "single value", vars: `{"ids": 2}`, want: `{"products":[{"id":2}]}`},
+		{name: "wrong element type", vars: `{"ids": [1, "two"]}`, err: `item 1: expected Int`},
+		{name: "null element", vars: `{"ids": [1, null]}`, err: `item 1: cannot be null`},
+		{name: "null list", vars: `{"ids": null}`, err: `cannot be null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := gj.GraphQL(context.Background(), gql, json.RawMessage(tt.vars), nil)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(res.Data); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -49,7 +49,7 @@ func (g *GraphJin) startDBWatcher(ps time.Duration) {
 
 		// Check all databases for schema changes
 		for _, ctx := range gj.databases {
-			if ctx.db == nil {
+			if ctx.db == nil || ctx.lazy.Load() {
 				continue
 			}
 
@@ -5,7 +5,9 @@ import (
 	"database/sql/driver"
 	"fmt"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // Conn implements driver.Conn for MongoDB.
@@ -51,6 +53,63 @@ func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 	return &Tx{session: session, ctx: ctx}, nil
 }
 
+// InsertMany inserts the documents into the collection unordered so that
+// a failed document does not stop the rest from being inserted. It is used
+// for bulk loading and returns the number of documents inserted.
+func (c *Conn) InsertMany(ctx context.Context, collection string, docs []map[string]any) (int64, error) {
+	items := make([]any, len(docs))
+	for i, doc := range docs {
+		items[i] = translateFieldsInMap(doc)
+	}
+
+	opts := options.InsertMany().SetOrdered(false)
+	result, err := c.db.Collection(collection).InsertMany(ctx, items, opts)
+
+	var n int64
+	if result != nil {
+		n = int64(len(result.InsertedIDs))
+	}
+	if err != nil {
+		return n, fmt.Errorf("mongodriver: insertMany: %w", err)
+	}
+	return n, nil
+}
+
+// WatchChanges opens a change stream on the database and calls fn with the
+// collection and document id of every inserted, updated, replaced or deleted
+// document until the context is done. Change streams need a replica set or
+// a sharded cluster.
+func (c *Conn) WatchChanges(ctx context.Context, fn func(collection, id string)) error {
+	ops := bson.A{"insert", "update", "replace", "delete"}
+	pipeline := bson.A{bson.M{"$match": bson.M{"operationType": bson.M{"$in": ops}}}}
+
+	cs, err := c.db.Watch(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("mongodriver: watch: %w", err)
+	}
+	defer cs.Close(context.Background()) //nolint:errcheck
+
+	for cs.Next(ctx) {
+		var ev struct {
+			NS struct {
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+			DocumentKey struct {
+				ID any `bson:"_id"`
+			} `bson:"documentKey"`
+		}
+		if err := cs.Decode(&ev); err != nil {
+			return fmt.Errorf("mongodriver: watch: %w", err)
+		}
+		fn(ev.NS.Coll, formatCursorValue(ev.DocumentKey.ID))
+	}
+
+	if err := cs.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("mongodriver: watch: %w", err)
+	}
+	return nil
+}
+
 // QueryContext executes a query and returns rows.
 func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
 	// Convert NamedValue to positional args
@@ -118,6 +177,8 @@ func (c *Conn) executeQuery(ctx context.Context, q *QueryDSL) (driver.Rows, erro
 		return c.introspectColumns(ctx, q)
 	case OpIntrospectFuncs:
 		return c.introspectFunctions(ctx, q)
+	case OpIntrospectIndexes:
+		return c.introspectIndexes(ctx, q)
 	case OpAggregate:
 		return c.executeAggregate(ctx, q)
 	case OpMultiAggregate:
@@ -146,6 +207,10 @@ func (c *Conn) executeQuery(ctx context.Context, q *QueryDSL) (driver.Rows, erro
 		return c.executeFind(ctx, q)
 	case OpFindOne:
 		return c.executeFindOne(ctx, q)
+	case OpCountDocuments:
+		return c.executeCountDocuments(ctx, q)
+	case OpExists:
+		return c.executeExists(ctx, q)
 	default:
 		return nil, fmt.Errorf("mongodriver: unsupported query operation: %s", q.Operation)
 	}
@@ -166,6 +231,10 @@ func (c *Conn) executeExec(ctx context.Context, q *QueryDSL) (driver.Result, err
 		return c.executeDeleteOne(ctx, q)
 	case OpDeleteMany:
 		return c.executeDeleteMany(ctx, q)
+	case OpCreateCollection:
+		return c.executeCreateCollection(ctx, q)
+	case OpCreateIndex:
+		return c.executeCreateIndex(ctx, q)
 	default:
 		return nil, fmt.Errorf("mongodriver: unsupported exec operation: %s", q.Operation)
 	}
@@ -117,6 +117,46 @@ func TestParamSubstitutionMultiMutation(t *testing.T) {
 	}
 }
 
+func TestParamSubstitutionTypes(t *testing.T) {
+	query := `{
+		"operation":"aggregate",
+		"collection":"products",
+		"pipeline":[{"$match":{"price":{"$gt":"$1"},"stock":{"$lte":"$2"},"sku":"$3","active":"$4","created_at":{"$gte":"$5"},"tag":"$6"}}],
+		"param_types":["numeric(7,2)","bigint","text","boolean","timestamptz","any"]
+	}`
+
+	q, err := ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery() error = %v", err)
+	}
+
+	args := []any{"10.5", "20", int64(1001), "true", "2024-01-02T03:04:05Z", "5"}
+	if err := q.SubstituteParams(args); err != nil {
+		t.Fatalf("SubstituteParams() error = %v", err)
+	}
+
+	match := q.Pipeline[0]["$match"].(map[string]any)
+	if got := match["price"].(map[string]any)["$gt"]; got != 10.5 {
+		t.Errorf("price.$gt = %#v, want 10.5", got)
+	}
+	if got := match["stock"].(map[string]any)["$lte"]; got != int64(20) {
+		t.Errorf("stock.$lte = %#v, want int64(20)", got)
+	}
+	if got := match["sku"]; got != "1001" {
+		t.Errorf("sku = %#v, want \"1001\"", got)
+	}
+	if got := match["active"]; got != true {
+		t.Errorf("active = %#v, want true", got)
+	}
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got, ok := match["created_at"].(map[string]any)["$gte"].(time.Time); !ok || !got.Equal(want) {
+		t.Errorf("created_at.$gte = %#v, want %v", match["created_at"], want)
+	}
+	if got := match["tag"]; got != "5" {
+		t.Errorf("tag = %#v, want \"5\"", got)
+	}
+}
+
 func TestExecuteMultiMutationAsQueryWithNullOps(t *testing.T) {
 	conn := &Conn{}
 	q := &QueryDSL{
@@ -313,3 +353,38 @@ func TestWithMongoDB(t *testing.T) {
 	// Clean up
 	coll.Drop(ctx)
 }
+
+func TestInferFields(t *testing.T) {
+	docs := []bson.M{
+		{"_id": bson.NewObjectID(), "name": "a", "price": int64(10), "tags": bson.A{"x"}, "note": nil},
+		{"_id": bson.NewObjectID(), "name": "b", "price": 10.5, "note": "hi"},
+		{"_id": bson.NewObjectID(), "name": "c", "price": int64(3), "tags": bson.A{}},
+	}
+
+	fields := inferFields(docs)
+
+	tests := []struct {
+		name     string
+		sqlType  string
+		required bool
+		isArray  bool
+	}{
+		{"_id", "text", true, false},
+		{"name", "text", true, false},
+		{"price", "double precision", true, false},
+		{"tags", "jsonb", false, true},
+		{"note", "text", false, false},
+	}
+
+	for _, tt := range tests {
+		f, ok := fields[tt.name]
+		if !ok {
+			t.Errorf("field %s not inferred", tt.name)
+			continue
+		}
+		if f.SQLType != tt.sqlType || f.Required != tt.required || f.IsArray != tt.isArray {
+			t.Errorf("field %s = %+v, want type %s, required %v, array %v",
+				tt.name, f, tt.sqlType, tt.required, tt.isArray)
+		}
+	}
+}
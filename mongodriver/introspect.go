@@ -231,44 +231,85 @@ func (c *Conn) getCollectionValidator(ctx context.Context, collName string) map[
 }
 
 // sampleCollectionFields samples documents to discover field types.
+// The type of a field is the one seen most often across the sampled documents
+// and a field is required when it is set in all of them.
 func (c *Conn) sampleCollectionFields(ctx context.Context, coll *mongo.Collection, sampleSize int) map[string]FieldInfo {
-	fields := make(map[string]FieldInfo)
-
 	pipeline := bson.A{
 		bson.M{"$sample": bson.M{"size": sampleSize}},
 	}
 
 	cursor, err := coll.Aggregate(ctx, pipeline)
 	if err != nil {
-		return fields
+		return make(map[string]FieldInfo)
 	}
 	defer cursor.Close(ctx)
 
+	var docs []bson.M
 	for cursor.Next(ctx) {
 		var doc bson.M
 		if err := cursor.Decode(&doc); err != nil {
 			continue
 		}
+		docs = append(docs, doc)
+	}
 
-		for key, val := range doc {
-			if _, exists := fields[key]; exists {
-				continue // Already discovered
-			}
+	return inferFields(docs)
+}
 
-			bsonType := inferBSONType(val)
-			fields[key] = FieldInfo{
-				Name:     key,
-				BSONType: bsonType,
-				SQLType:  bsonTypeToSQL(bsonType),
-				Required: key == "_id", // Only _id is guaranteed
-				IsArray:  bsonType == "array",
+// inferFields infers the fields of a collection from sampled documents.
+func inferFields(docs []bson.M) map[string]FieldInfo {
+	counts := make(map[string]map[string]int)
+	for _, doc := range docs {
+		for key, val := range doc {
+			if counts[key] == nil {
+				counts[key] = make(map[string]int)
 			}
+			counts[key][inferBSONType(val)]++
 		}
 	}
 
+	fields := make(map[string]FieldInfo, len(counts))
+	for key, types := range counts {
+		bsonType := dominantBSONType(types)
+		fields[key] = FieldInfo{
+			Name:     key,
+			BSONType: bsonType,
+			SQLType:  bsonTypeToSQL(bsonType),
+			Required: key == "_id" || (types["null"] == 0 && sumCounts(types) == len(docs)),
+			IsArray:  bsonType == "array",
+		}
+	}
 	return fields
 }
 
+// dominantBSONType picks the most common non-null type, a mix of integers
+// and doubles is widened to double.
+func dominantBSONType(types map[string]int) string {
+	if types["double"] != 0 &&
+		types["double"]+types["int"]+types["long"]+types["null"] == sumCounts(types) {
+		return "double"
+	}
+
+	best, bestCount := "null", 0
+	for t, n := range types {
+		if t == "null" {
+			continue
+		}
+		if n > bestCount || (n == bestCount && t < best) {
+			best, bestCount = t, n
+		}
+	}
+	return best
+}
+
+func sumCounts(m map[string]int) int {
+	n := 0
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
 // inferBSONType determines the BSON type from a Go value.
 func inferBSONType(v any) string {
 	if v == nil {
@@ -0,0 +1,137 @@
+package mongodriver
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// paramType returns the type of the parameter at index i (0-based) as
+// listed in the query DSL, or an empty string if it's unknown.
+func (q *QueryDSL) paramType(i int) string {
+	if i < len(q.ParamTypes) {
+		return q.ParamTypes[i]
+	}
+	return ""
+}
+
+// bindParam coerces a parameter value to the Go type that encodes to the
+// BSON type of the parameter. Values are compared by BSON type in MongoDB
+// so a number sent as a string would never match a numeric field.
+// Values that can't be coerced are returned as-is.
+func bindParam(v any, typ string) any {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if v == nil || typ == "" || typ == "any" || strings.HasSuffix(typ, "[]") {
+		return v
+	}
+	// drop the precision or length, eg. numeric(7,2) or varchar(255)
+	if n := strings.IndexByte(typ, '('); n != -1 {
+		typ = strings.TrimSpace(typ[:n])
+	}
+
+	switch typ {
+	case "integer", "int", "int2", "int4", "int8", "smallint", "bigint", "long":
+		return bindInt(v)
+
+	case "float", "float4", "float8", "real", "double", "double precision",
+		"numeric", "decimal":
+		return bindFloat(v)
+
+	case "boolean", "bool":
+		return bindBool(v)
+
+	case "text", "string", "varchar", "character varying", "char", "character":
+		return bindString(v)
+
+	case "timestamp", "timestamptz", "timestamp with time zone",
+		"timestamp without time zone", "date", "datetime":
+		return bindTime(v)
+	}
+	return v
+}
+
+func bindInt(v any) any {
+	switch val := v.(type) {
+	case string:
+		if n, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64); err == nil {
+			return n
+		}
+	case float64:
+		if val == float64(int64(val)) {
+			return int64(val)
+		}
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+	case int:
+		return int64(val)
+	case int32:
+		return int64(val)
+	}
+	return v
+}
+
+func bindFloat(v any) any {
+	switch val := v.(type) {
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+			return f
+		}
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+	case int:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case int64:
+		return float64(val)
+	}
+	return v
+}
+
+func bindBool(v any) any {
+	switch val := v.(type) {
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(val)); err == nil {
+			return b
+		}
+	case int64:
+		return val != 0
+	case int:
+		return val != 0
+	}
+	return v
+}
+
+func bindString(v any) any {
+	switch val := v.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	}
+	return v
+}
+
+// bindTime parses timestamp strings into time.Time so they are stored and
+// compared as BSON dates instead of strings
+func bindTime(v any) any {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return v
+}
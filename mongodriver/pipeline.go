@@ -221,6 +221,16 @@ func convertNestedSortOrdered(stage map[string]any) map[string]any {
 	return result
 }
 
+// aggregateOptions returns the options for the aggregation of a query,
+// the index hint is set when the query has one.
+func aggregateOptions(q *QueryDSL) *options.AggregateOptionsBuilder {
+	opts := options.Aggregate()
+	if q.Hint != "" {
+		opts.SetHint(q.Hint)
+	}
+	return opts
+}
+
 // executeAggregate runs an aggregation pipeline.
 func (c *Conn) executeAggregate(ctx context.Context, q *QueryDSL) (driver.Rows, error) {
 	if q.Collection == "" {
@@ -237,7 +247,7 @@ func (c *Conn) executeAggregate(ctx context.Context, q *QueryDSL) (driver.Rows,
 		pipeline[i] = convertSortOrderedToSort(translated)
 	}
 
-	cursor, err := coll.Aggregate(ctx, pipeline)
+	cursor, err := coll.Aggregate(ctx, pipeline, aggregateOptions(q))
 	if err != nil {
 		return nil, fmt.Errorf("mongodriver: aggregate: %w", err)
 	}
@@ -385,6 +395,24 @@ func (c *Conn) executeMultiAggregate(ctx context.Context, q *QueryDSL) (driver.R
 			continue
 		}
 
+		if subQ.Operation == OpCountDocuments {
+			n, err := c.countDocuments(ctx, subQ)
+			if err != nil {
+				return nil, err
+			}
+			finalResult[subQ.FieldName] = n
+			continue
+		}
+
+		if subQ.Operation == OpExists {
+			ok, err := c.exists(ctx, subQ)
+			if err != nil {
+				return nil, err
+			}
+			finalResult[subQ.FieldName] = ok
+			continue
+		}
+
 		if subQ.Collection == "" {
 			return nil, fmt.Errorf("mongodriver: aggregate requires collection")
 		}
@@ -399,7 +427,7 @@ func (c *Conn) executeMultiAggregate(ctx context.Context, q *QueryDSL) (driver.R
 			pipeline[i] = convertSortOrderedToSort(translated)
 		}
 
-		cursor, err := coll.Aggregate(ctx, pipeline)
+		cursor, err := coll.Aggregate(ctx, pipeline, aggregateOptions(subQ))
 		if err != nil {
 			return nil, fmt.Errorf("mongodriver: aggregate on %s: %w", subQ.Collection, err)
 		}
@@ -618,6 +646,78 @@ func (c *Conn) executeFind(ctx context.Context, q *QueryDSL) (driver.Rows, error
 	return NewSingleValueRows(jsonBytes, []string{"__root"}), nil
 }
 
+// executeCountDocuments counts the documents matching the filter without
+// fetching them, the count is wrapped in the field name.
+func (c *Conn) executeCountDocuments(ctx context.Context, q *QueryDSL) (driver.Rows, error) {
+	n, err := c.countDocuments(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(map[string]any{q.FieldName: n})
+	if err != nil {
+		return nil, fmt.Errorf("mongodriver: marshal count: %w", err)
+	}
+
+	return NewSingleValueRows(jsonBytes, []string{"__root"}), nil
+}
+
+func (c *Conn) countDocuments(ctx context.Context, q *QueryDSL) (int64, error) {
+	if q.Collection == "" {
+		return 0, fmt.Errorf("mongodriver: countDocuments requires collection")
+	}
+
+	filter := bson.M{}
+	if q.Filter != nil {
+		// Translate field names (id -> _id)
+		filter = translateFieldsInMap(q.Filter)
+	}
+
+	n, err := c.db.Collection(q.Collection).CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("mongodriver: countDocuments on %s: %w", q.Collection, err)
+	}
+	return n, nil
+}
+
+// executeExists checks if any document matches the filter using a findOne
+// that only projects the _id, the result is wrapped in the field name.
+func (c *Conn) executeExists(ctx context.Context, q *QueryDSL) (driver.Rows, error) {
+	ok, err := c.exists(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := json.Marshal(map[string]any{q.FieldName: ok})
+	if err != nil {
+		return nil, fmt.Errorf("mongodriver: marshal exists: %w", err)
+	}
+
+	return NewSingleValueRows(jsonBytes, []string{"__root"}), nil
+}
+
+func (c *Conn) exists(ctx context.Context, q *QueryDSL) (bool, error) {
+	if q.Collection == "" {
+		return false, fmt.Errorf("mongodriver: exists requires collection")
+	}
+
+	filter := bson.M{}
+	if q.Filter != nil {
+		// Translate field names (id -> _id)
+		filter = translateFieldsInMap(q.Filter)
+	}
+
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1})
+	err := c.db.Collection(q.Collection).FindOne(ctx, filter, opts).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("mongodriver: exists on %s: %w", q.Collection, err)
+	}
+	return true, nil
+}
+
 // executeFindOne runs a findOne query.
 func (c *Conn) executeFindOne(ctx context.Context, q *QueryDSL) (driver.Rows, error) {
 	if q.Collection == "" {
@@ -34,6 +34,7 @@ type QueryDSL struct {
 	Options           map[string]any   `json:"options,omitempty"`
 	Presets           map[string]any   `json:"presets,omitempty"` // Preset values to merge with document
 	Params            []string         `json:"params,omitempty"`
+	ParamTypes        []string         `json:"param_types,omitempty"`         // Parameter types in placeholder order ($1, $2, ...)
 	Queries           []*QueryDSL      `json:"queries,omitempty"`             // For multi_aggregate operations
 	Inserts           []NestedInsert   `json:"inserts,omitempty"`             // For nested_insert operations
 	Updates           []NestedUpdate   `json:"updates,omitempty"`             // For nested_update operations
@@ -43,6 +44,9 @@ type QueryDSL struct {
 	Condition         *QueryCondition  `json:"condition,omitempty"`           // Condition for variable-based directives
 	CursorInfo        *CursorInfo      `json:"cursor_info,omitempty"`         // Cursor pagination metadata
 	CursorParam       string           `json:"cursor_param,omitempty"`        // Parameter placeholder for cursor value (e.g., "$1")
+	Hint              string           `json:"hint,omitempty"`                // Index name to use from the @hint directive
+	Validator         map[string]any   `json:"validator,omitempty"`           // Collection validator for createCollection
+	Index             *IndexSpec       `json:"index,omitempty"`               // Index to create for createIndex
 }
 
 // NestedInsert represents a single insert in a nested mutation operation.
@@ -108,6 +112,8 @@ const (
 	OpMultiMutation     = "multi_mutation"
 	OpFind              = "find"
 	OpFindOne           = "findOne"
+	OpCountDocuments    = "countDocuments"
+	OpExists            = "exists"
 	OpInsertOne         = "insertOne"
 	OpInsertMany        = "insertMany"
 	OpUpdateOne         = "updateOne"
@@ -119,6 +125,9 @@ const (
 	OpIntrospectInfo    = "introspect_info"
 	OpIntrospectColumns = "introspect_columns"
 	OpIntrospectFuncs   = "introspect_functions"
+	OpIntrospectIndexes = "introspect_indexes"
+	OpCreateCollection  = "createCollection"
+	OpCreateIndex       = "createIndex"
 	OpEmpty             = "empty" // For dropped root selections (@add/@remove directives)
 	OpNull              = "null"  // For nulled selections (@skip/@include directives)
 )
@@ -136,6 +145,8 @@ func ParseQuery(query string) (*QueryDSL, error) {
 }
 
 // SubstituteParams replaces parameter placeholders ($1, $2, etc.) with actual values.
+// Values are coerced to the parameter types listed in the query DSL so that
+// they are bound with the right BSON type.
 func (q *QueryDSL) SubstituteParams(args []any) error {
 	if len(args) == 0 {
 		return nil
@@ -144,7 +155,7 @@ func (q *QueryDSL) SubstituteParams(args []any) error {
 	// Build param map
 	paramMap := make(map[string]any)
 	for i, arg := range args {
-		paramMap[fmt.Sprintf("$%d", i+1)] = arg
+		paramMap[fmt.Sprintf("$%d", i+1)] = bindParam(arg, q.paramType(i))
 	}
 
 	// Substitute in pipeline
@@ -239,6 +250,10 @@ func (q *QueryDSL) SubstituteParams(args []any) error {
 
 	// Substitute in nested queries (for multi_aggregate and multi_mutation)
 	for _, subQ := range q.Queries {
+		// Parameter types are only listed on the top-level query
+		if len(subQ.ParamTypes) == 0 {
+			subQ.ParamTypes = q.ParamTypes
+		}
 		if err := subQ.SubstituteParams(args); err != nil {
 			return err
 		}
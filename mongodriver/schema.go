@@ -0,0 +1,116 @@
+package mongodriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// IndexSpec describes an index created with the createIndex operation.
+type IndexSpec struct {
+	Name   string  `json:"name,omitempty"`
+	Keys   [][]any `json:"keys"` // Ordered [field, direction] pairs, direction is 1, -1 or "text"
+	Unique bool    `json:"unique,omitempty"`
+}
+
+// introspectIndexes lists the indexes of every collection.
+// This implements the "introspect_indexes" operation used by schema sync.
+func (c *Conn) introspectIndexes(ctx context.Context, q *QueryDSL) (driver.Rows, error) {
+	collections, err := c.db.ListCollectionNames(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("mongodriver: list collections: %w", err)
+	}
+
+	columns := []string{"table_name", "index_name"}
+	var rows [][]any
+
+	for _, collName := range collections {
+		if q.Collection != "" && collName != q.Collection {
+			continue
+		}
+		specs, err := c.db.Collection(collName).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("mongodriver: list indexes of %s: %w", collName, err)
+		}
+		for _, spec := range specs {
+			rows = append(rows, []any{collName, spec.Name})
+		}
+	}
+	return NewColumnRows(columns, rows), nil
+}
+
+// executeCreateCollection creates a collection with an optional validator.
+// Existing collections are left as they are.
+func (c *Conn) executeCreateCollection(ctx context.Context, q *QueryDSL) (driver.Result, error) {
+	if q.Collection == "" {
+		return nil, fmt.Errorf("mongodriver: createCollection requires collection")
+	}
+
+	names, err := c.db.ListCollectionNames(ctx, bson.M{"name": q.Collection})
+	if err != nil {
+		return nil, fmt.Errorf("mongodriver: list collections: %w", err)
+	}
+	if len(names) != 0 {
+		return &Result{}, nil
+	}
+
+	opts := options.CreateCollection()
+	if len(q.Validator) != 0 {
+		opts.SetValidator(q.Validator)
+	}
+	if err := c.db.CreateCollection(ctx, q.Collection, opts); err != nil {
+		return nil, fmt.Errorf("mongodriver: createCollection: %w", err)
+	}
+	return &Result{}, nil
+}
+
+// executeCreateIndex creates an index on a collection. Creating an index
+// that already exists with the same keys and options is a no-op.
+func (c *Conn) executeCreateIndex(ctx context.Context, q *QueryDSL) (driver.Result, error) {
+	if q.Collection == "" {
+		return nil, fmt.Errorf("mongodriver: createIndex requires collection")
+	}
+	if q.Index == nil || len(q.Index.Keys) == 0 {
+		return nil, fmt.Errorf("mongodriver: createIndex requires index keys")
+	}
+
+	keys := bson.D{}
+	for _, k := range q.Index.Keys {
+		if len(k) != 2 {
+			return nil, fmt.Errorf("mongodriver: invalid index key: %v", k)
+		}
+		name, ok := k[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("mongodriver: invalid index key: %v", k)
+		}
+		if name == "id" {
+			name = "_id"
+		}
+		switch dir := k[1].(type) {
+		case float64:
+			keys = append(keys, bson.E{Key: name, Value: int32(dir)})
+		case string:
+			keys = append(keys, bson.E{Key: name, Value: dir})
+		default:
+			return nil, fmt.Errorf("mongodriver: invalid index direction: %v", k[1])
+		}
+	}
+
+	opts := options.Index()
+	if q.Index.Name != "" {
+		opts.SetName(q.Index.Name)
+	}
+	if q.Index.Unique {
+		opts.SetUnique(true)
+	}
+
+	model := mongo.IndexModel{Keys: keys, Options: opts}
+	if _, err := c.db.Collection(q.Collection).Indexes().CreateOne(ctx, model); err != nil {
+		return nil, fmt.Errorf("mongodriver: createIndex: %w", err)
+	}
+	return &Result{}, nil
+}
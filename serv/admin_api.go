@@ -303,13 +303,18 @@ func buildServerSection(conf *Config) ConfigSection {
 }
 
 func buildCORSSection(conf *Config) ConfigSection {
+	cc := conf.corsConfig()
 	return ConfigSection{
 		Name:  "cors",
 		Title: "CORS",
 		Fields: []ConfigField{
-			arrayField("allowedOrigins", "Allowed Origins", conf.AllowedOrigins),
-			arrayField("allowedHeaders", "Allowed Headers", conf.AllowedHeaders),
-			boolField("debugCORS", "Debug CORS", conf.DebugCORS),
+			arrayField("allowedOrigins", "Allowed Origins", cc.AllowedOrigins),
+			arrayField("allowedHeaders", "Allowed Headers", cc.AllowedHeaders),
+			arrayField("allowedMethods", "Allowed Methods", cc.AllowedMethods),
+			field("maxAge", "Preflight Max Age", cc.MaxAge.String(), "string"),
+			boolField("allowCredentials", "Allow Credentials", cc.AllowCredentials),
+			arrayField("credentialOrigins", "Credential Origins", cc.CredentialOrigins),
+			boolField("debugCORS", "Debug CORS", cc.Debug),
 		},
 	}
 }
@@ -0,0 +1,130 @@
+package serv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+const routeEffectiveConfig = "/api/v1/admin/config/effective"
+
+// configRedactKeys are always redacted from the effective config, keys
+// containing any of these hold credentials, connection strings or urls
+// that can carry them
+var configRedactKeys = []string{
+	"password", "secret", "token", "passphrase", "private_key", "privatekey",
+	"client_key", "clientkey", "connection_string", "connstring", "dsn",
+	"url", "webhook", "headers",
+}
+
+// EffectiveConfig is the config the service is running with
+type EffectiveConfig struct {
+	// Config with the databases normalized, env overrides and defaults
+	// applied and the secrets redacted
+	Config map[string]interface{} `json:"config"`
+
+	// Hash of the loaded config files
+	ConfigHash string `json:"config_hash,omitempty"`
+
+	// Version of the schema snapshot format
+	SnapshotVersion int `json:"snapshot_version"`
+
+	// Hash of the discovered schema of each database
+	SchemaHashes map[string]string `json:"schema_hashes,omitempty"`
+}
+
+// effectiveConfig returns the redacted config the service is running with
+func (s *graphjinService) effectiveConfig() (EffectiveConfig, error) {
+	conf := *s.conf
+	ec := EffectiveConfig{
+		ConfigHash:      s.chash,
+		SnapshotVersion: core.SchemaSnapshotVersion,
+	}
+
+	// the engine holds the core config after normalization
+	if s.gj != nil {
+		if c, err := s.gj.EffectiveConfig(); err == nil {
+			conf.Core = c
+		}
+		ec.SchemaHashes = s.gj.SchemaHashes()
+	}
+
+	var err error
+	ec.Config, err = s.redactConfig(&conf)
+	return ec, err
+}
+
+// redactConfig returns the config as a json map with the secrets redacted
+func (s *graphjinService) redactConfig(conf *Config) (map[string]interface{}, error) {
+	b, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	names := append(configRedactKeys[:len(configRedactKeys):len(configRedactKeys)],
+		s.conf.ConfigDump.Redact...)
+	return redactURLs(redactValue(m, names)).(map[string]interface{}), nil
+}
+
+// redactURLs strips the userinfo and query string from url values left
+// after the keys are redacted since either can carry credentials
+func redactURLs(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, v1 := range v {
+			v[k] = redactURLs(v1)
+		}
+	case []interface{}:
+		for i, v1 := range v {
+			v[i] = redactURLs(v1)
+		}
+	case string:
+		if !strings.Contains(v, "://") {
+			return v
+		}
+		u, err := url.Parse(v)
+		if err != nil {
+			return redacted
+		}
+		if u.User == nil && u.RawQuery == "" {
+			return v
+		}
+		u.User = nil
+		u.RawQuery = ""
+		u.ForceQuery = false
+		return u.String()
+	}
+	return v
+}
+
+// effectiveConfigHandler returns the effective config with secrets redacted
+// GET /api/v1/admin/config/effective
+func effectiveConfigHandler(s1 *HttpService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		if code := s.adminStatus(r, s.conf.ConfigDump.Roles); code != 0 {
+			writeJSONError(w, code, http.StatusText(code))
+			return
+		}
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		ec, err := s.effectiveConfig()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, ec)
+	})
+}
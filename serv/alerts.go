@@ -0,0 +1,229 @@
+package serv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAlertWindow      = time.Minute
+	defaultAlertMinRequests = 10
+
+	// maxAlertSamples bounds the response times kept per query and window
+	maxAlertSamples = 1000
+)
+
+// Alert is logged and posted to the webhook when a rule starts failing
+// (firing) or stops failing (resolved) for a query
+type Alert struct {
+	Query     string    `json:"query"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Requests  int       `json:"requests"`
+	Time      time.Time `json:"time"`
+}
+
+// queryWindow holds the requests of a query in the current window
+type queryWindow struct {
+	requests int
+	errors   int
+	durs     []time.Duration
+}
+
+// alerter checks the error rate and latency of queries against the rules
+type alerter struct {
+	conf AlertsConfig
+
+	mu     sync.Mutex
+	window map[string]*queryWindow
+	firing map[string]bool
+}
+
+// newAlerter returns nil when there are no rules
+func newAlerter(conf AlertsConfig) *alerter {
+	if len(conf.Rules) == 0 {
+		return nil
+	}
+	if conf.Window <= 0 {
+		conf.Window = defaultAlertWindow
+	}
+	return &alerter{
+		conf:   conf,
+		window: make(map[string]*queryWindow),
+		firing: make(map[string]bool),
+	}
+}
+
+// record adds a request to the window of its query, the response times
+// are sampled once there are more than maxAlertSamples
+func (a *alerter) record(name string, dur time.Duration, failed bool) {
+	if a == nil || name == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.window[name]
+	if !ok {
+		w = &queryWindow{}
+		a.window[name] = w
+	}
+	w.requests++
+	if failed {
+		w.errors++
+	}
+	if len(w.durs) < maxAlertSamples {
+		w.durs = append(w.durs, dur)
+	} else if i := rand.IntN(w.requests); i < maxAlertSamples {
+		w.durs[i] = dur
+	}
+}
+
+// evaluate closes the current window and returns the alerts for the rules
+// that started or stopped failing. Queries with too few requests in the
+// window keep their state.
+func (a *alerter) evaluate(now time.Time) []Alert {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	window := a.window
+	a.window = make(map[string]*queryWindow)
+	a.mu.Unlock()
+
+	names := make([]string, 0, len(window))
+	for name := range window {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var alerts []Alert
+	for i, r := range a.conf.Rules {
+		minReq := r.MinRequests
+		if minReq <= 0 {
+			minReq = defaultAlertMinRequests
+		}
+		for _, name := range names {
+			w := window[name]
+			if (r.Query != "" && r.Query != name) || w.requests < minReq {
+				continue
+			}
+			if r.ErrorRate > 0 {
+				rate := float64(w.errors) * 100 / float64(w.requests)
+				alerts = a.check(alerts, fmt.Sprintf("%d/%s/error_rate", i, name), Alert{
+					Query: name, Reason: "error_rate", Value: rate,
+					Threshold: r.ErrorRate, Requests: w.requests, Time: now,
+				})
+			}
+			if r.P95 > 0 {
+				p95 := float64(percentile(w.durs, 95).Milliseconds())
+				alerts = a.check(alerts, fmt.Sprintf("%d/%s/p95", i, name), Alert{
+					Query: name, Reason: "p95", Value: p95,
+					Threshold: float64(r.P95.Milliseconds()), Requests: w.requests, Time: now,
+				})
+			}
+		}
+	}
+	return alerts
+}
+
+// check adds an alert when the rule changed between passing and failing
+func (a *alerter) check(alerts []Alert, key string, al Alert) []Alert {
+	failing := al.Value > al.Threshold
+	if failing == a.firing[key] {
+		return alerts
+	}
+	a.firing[key] = failing
+
+	al.Status = "resolved"
+	if failing {
+		al.Status = "firing"
+	}
+	return append(alerts, al)
+}
+
+// percentile returns the p-th percentile of the durations
+func percentile(durs []time.Duration, p int) time.Duration {
+	if len(durs) == 0 {
+		return 0
+	}
+	d := slices.Clone(durs)
+	slices.Sort(d)
+	i := (len(d)*p + 99) / 100
+	return d[max(i-1, 0)]
+}
+
+// initAlerts checks the rules at the end of every window. The current
+// service is loaded for every window so config reloads are picked up.
+func initAlerts(s1 *HttpService) {
+	s := s1.Load().(*graphjinService)
+	if s.alerts == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.alerts.conf.Window)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			cur := s1.Load().(*graphjinService)
+			for _, al := range cur.alerts.evaluate(now) {
+				cur.sendAlert(al)
+			}
+		}
+	}()
+}
+
+// sendAlert logs the alert and posts it to the webhook
+func (s *graphjinService) sendAlert(al Alert) {
+	s.zlog.Warn("alert",
+		zap.String("query", al.Query),
+		zap.String("status", al.Status),
+		zap.String("reason", al.Reason),
+		zap.Float64("value", al.Value),
+		zap.Float64("threshold", al.Threshold),
+		zap.Int("requests", al.Requests))
+
+	if s.conf.Alerts.Webhook == "" {
+		return
+	}
+
+	b, err := json.Marshal(al)
+	if err != nil {
+		s.log.Errorf("alerts: %s", err)
+		return
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(c, "POST", s.conf.Alerts.Webhook, bytes.NewReader(b))
+	if err != nil {
+		s.log.Errorf("alerts: %s", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.log.Errorf("alerts: webhook: %s", err)
+		return
+	}
+	res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode >= 300 {
+		s.log.Errorf("alerts: webhook: %s", res.Status)
+	}
+}
@@ -0,0 +1,82 @@
+package serv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestAlerter(t *testing.T) {
+	a := newAlerter(AlertsConfig{Rules: []AlertRule{
+		{Query: "getUser", ErrorRate: 10, MinRequests: 5},
+		{P95: 100 * time.Millisecond, MinRequests: 5},
+	}})
+
+	window := func(name string, n, failed int, slow time.Duration) {
+		for i := 0; i < n; i++ {
+			dur := 10 * time.Millisecond
+			if i == 0 {
+				dur = slow
+			}
+			a.record(name, dur, i < failed)
+		}
+	}
+
+	// getUser fails 20% of the time and listPosts is slow, getPosts has
+	// too few requests to be checked
+	window("getUser", 10, 2, 10*time.Millisecond)
+	window("listPosts", 10, 0, time.Second)
+	window("getPosts", 2, 2, time.Second)
+
+	alerts := a.evaluate(time.Now())
+	if len(alerts) != 2 {
+		t.Fatalf("expected two alerts, got %+v", alerts)
+	}
+	if al := alerts[0]; al.Query != "getUser" || al.Reason != "error_rate" || al.Status != "firing" || al.Value != 20 {
+		t.Errorf("unexpected alert: %+v", al)
+	}
+	if al := alerts[1]; al.Query != "listPosts" || al.Reason != "p95" || al.Status != "firing" || al.Value != 1000 {
+		t.Errorf("unexpected alert: %+v", al)
+	}
+
+	// still failing so nothing new is sent
+	window("getUser", 10, 2, 10*time.Millisecond)
+	if alerts := a.evaluate(time.Now()); len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %+v", alerts)
+	}
+
+	// getUser recovers, listPosts has no requests and keeps its state
+	window("getUser", 10, 0, 10*time.Millisecond)
+	alerts = a.evaluate(time.Now())
+	if len(alerts) != 1 || alerts[0].Query != "getUser" || alerts[0].Status != "resolved" {
+		t.Fatalf("expected getUser to be resolved, got %+v", alerts)
+	}
+}
+
+func TestSendAlert(t *testing.T) {
+	got := make(chan Alert, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var al Alert
+		if err := json.NewDecoder(r.Body).Decode(&al); err != nil {
+			t.Error(err)
+		}
+		got <- al
+	}))
+	defer ts.Close()
+
+	zlog := zap.NewNop()
+	s := &graphjinService{
+		zlog: zlog,
+		log:  zlog.Sugar(),
+		conf: &Config{Serv: Serv{Alerts: AlertsConfig{Webhook: ts.URL}}},
+	}
+	s.sendAlert(Alert{Query: "getUser", Status: "firing", Reason: "p95", Value: 900, Threshold: 500})
+
+	if al := <-got; al.Query != "getUser" || al.Status != "firing" || al.Value != 900 {
+		t.Fatalf("unexpected alert: %+v", al)
+	}
+}
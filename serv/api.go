@@ -38,6 +38,7 @@ import (
 	"database/sql"
 	"errors"
 	// "fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	// "path/filepath"
@@ -61,6 +62,7 @@ type HttpService struct {
 	atomic.Value
 	opt   []Option
 	cpath string
+	cdc   cdcStream
 }
 
 type servState int
@@ -75,6 +77,7 @@ type HookFn func(*core.Result)
 type graphjinService struct {
 	log      *zap.SugaredLogger // logger
 	zlog     *zap.Logger        // faster logger
+	slog     *slog.Logger       // request logger
 	logLevel int                // log level
 	conf     *Config            // parsed config
 	dbs      map[string]*sql.DB // named database connections (all equal)
@@ -91,8 +94,18 @@ type graphjinService struct {
 	// adminCount   int32
 	namespace            *string
 	tracer               trace.Tracer
-	cache                ResponseCache // Response cache (Redis or in-memory)
-	cursorCache          CursorCache   // MCP cursor cache for short numeric IDs
+	cache                ResponseCache            // Response cache (Redis or in-memory)
+	cursorCache          CursorCache              // MCP cursor cache for short numeric IDs
+	idempotency          IdempotencyStore         // Stored responses of mutations by Idempotency-Key
+	qlimits              map[string]*queryLimiter // Saved query concurrency limits
+	ulimits              *userLimiter             // Per-user concurrency limits
+	alerts               *alerter                 // Query error rate and latency alerts
+	qstats               *queryStats              // Request counts and response times per query
+	shadow               *shadower                // Mirrors queries to the shadow database
+	contracts            *contractCache           // Generated OpenAPI and SDL contracts
+	flags                *featureFlags            // Feature flags passed to queries as variables
+	modes                *serviceModes            // Read-only and maintenance modes
+	configMu             sync.Mutex               // Serializes config updates made through MCP
 	onboardingMu         sync.RWMutex
 	onboardingCandidates map[string]cachedDiscoveredCandidate
 }
@@ -115,6 +128,7 @@ func (s *graphjinService) buildCoreOptionsWithDBs(dbs map[string]*sql.DB) []core
 	opts := []core.Option{
 		core.OptionSetFS(s.fs),
 		core.OptionSetTrace(otelPlugin.NewTracerFrom(s.tracer)),
+		core.OptionSetBulkLoader(bulkLoader{}),
 	}
 	if s.namespace != nil {
 		opts = append(opts, core.OptionSetNamespace(*s.namespace))
@@ -148,6 +162,10 @@ func NewGraphJinService(conf *Config, options ...Option) (*HttpService, error) {
 		initConfigWatcher(s1)
 	}
 
+	initCDC(s1)
+	initAlerts(s1)
+	initFeatureFlags(s1)
+
 	// if s.conf.HotDeploy {
 	// 	initHotDeployWatcher(s1)
 	// }
@@ -200,12 +218,21 @@ func OptionSetZapLogger(zlog *zap.Logger) Option {
 	}
 }
 
+// OptionSetRequestLogger sets the structured logger used for request logs
+func OptionSetRequestLogger(l *slog.Logger) Option {
+	return func(s *graphjinService) error {
+		s.slog = l
+		return nil
+	}
+}
+
 // OptionSetLogOutput sets the log output writer (e.g., os.Stderr for MCP stdio mode)
 func OptionSetLogOutput(output zapcore.WriteSyncer) Option {
 	return func(s *graphjinService) error {
 		zlog := util.NewLoggerWithOutput(s.conf.ShouldUseJSONLogs(), output)
 		s.zlog = zlog
 		s.log = zlog.Sugar()
+		s.slog = newRequestLogger(s.conf.ShouldUseJSONLogs(), output)
 		return nil
 	}
 }
@@ -233,11 +260,19 @@ func newGraphJinService(conf *Config, dbs map[string]*sql.DB, options ...Option)
 		conf:   conf,
 		zlog:   zlog,
 		log:    zlog.Sugar(),
+		slog:   newRequestLogger(conf.ShouldUseJSONLogs(), os.Stdout),
 		dbs:    dbs,
 		chash:  conf.hash,
 		prod:   prod,
 		tracer: otel.Tracer("graphjin.com/serv"),
 	}
+	s.qlimits = newQueryLimiters(conf.QueryLimits)
+	s.ulimits = newUserLimiter(conf.UserLimit)
+	s.alerts = newAlerter(conf.Alerts)
+	s.qstats = newQueryStats()
+	s.shadow = newShadower(conf.Shadow)
+	s.contracts = &contractCache{}
+	s.flags = newFeatureFlags(conf.FeatureFlags)
 	if s.dbs == nil {
 		s.dbs = make(map[string]*sql.DB)
 	}
@@ -314,6 +349,20 @@ func newGraphJinService(conf *Config, dbs map[string]*sql.DB, options ...Option)
 		s.log.Warnf("cursor cache init error: %s", err)
 	}
 
+	// Initialize the idempotency key store (non-fatal if unavailable)
+	if err := s.initIdempotencyStore(); err != nil {
+		s.log.Warnf("idempotency store init error: %s", err)
+	}
+
+	if err := s.initSessionStore(); err != nil {
+		return nil, err
+	}
+
+	// Load the saved read-only and maintenance modes (non-fatal if unreadable)
+	if err := s.initServiceModes(); err != nil {
+		s.log.Warnf("service mode init error: %s", err)
+	}
+
 	// if s.deployActive {
 	// 	err = s.hotStart()
 	// } else {
@@ -346,8 +395,11 @@ func (s *graphjinService) normalStart() error {
 	opts := s.buildCoreOptions()
 
 	var err error
-	s.gj, err = core.NewGraphJin(&s.conf.Core, s.anyDB(), opts...)
-	return err
+	if s.gj, err = core.NewGraphJin(&s.conf.Core, s.anyDB(), opts...); err != nil {
+		return err
+	}
+	s.applyReadOnly()
+	return nil
 }
 
 // hotStart starts the service in hot-deploy mode
@@ -414,7 +466,15 @@ func (s *HttpService) Deploy(conf *Config, options ...Option) error {
 	s1.closeFn = os.closeFn
 	s1.namespace = os.namespace
 
+	// keep the flags read from the table until the next refresh
+	if os.flags != nil {
+		os.flags.mu.RLock()
+		s1.flags.setTable(os.flags.table)
+		os.flags.mu.RUnlock()
+	}
+
 	s.Store(s1)
+	s.restartCDC()
 	return nil
 }
 
@@ -512,6 +572,16 @@ func (s *HttpService) OpenAPIWithNS(ns string) http.Handler {
 	return s.openAPIHandler(&ns)
 }
 
+// SchemaSDL is the http handler for the GraphQL schema (SDL) endpoint
+func (s *HttpService) SchemaSDL() http.Handler {
+	return s.schemaSDLHandler(nil)
+}
+
+// SchemaSDLWithNS is the http handler for the namespaced GraphQL schema (SDL) endpoint
+func (s *HttpService) SchemaSDLWithNS(ns string) http.Handler {
+	return s.schemaSDLHandler(&ns)
+}
+
 func (s *HttpService) apiHandler(ns *string, ah auth.HandlerFunc, rest bool) http.Handler {
 	var h http.Handler
 	if rest {
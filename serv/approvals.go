@@ -0,0 +1,103 @@
+package serv
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+const (
+	routeApprovals       = "/api/v1/approvals"
+	routeApprovalsAction = "/api/v1/approvals/*"
+)
+
+// approvalsHandler lists the pending mutations and approves or rejects them
+// GET /api/v1/approvals
+// POST /api/v1/approvals/{id}/approve
+// POST /api/v1/approvals/{id}/reject
+func approvalsHandler(s1 *HttpService, ns *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		if err := s.checkGraphJinInitialized(); err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if code := s.canReviewMutations(r); code != 0 {
+			writeJSONError(w, code, http.StatusText(code))
+			return
+		}
+
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, routeApprovals), "/")
+		if path == "" {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			list, err := s.gj.PendingMutations(r.Context())
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if ns != nil {
+				list = slices.DeleteFunc(list, func(pm core.PendingMutation) bool {
+					return pm.Namespace != *ns
+				})
+			}
+			if list == nil {
+				list = []core.PendingMutation{}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			writeJSON(w, map[string]interface{}{"pending": list})
+			return
+		}
+
+		id, action, ok := strings.Cut(path, "/")
+		if !ok || id == "" || (action != "approve" && action != "reject") {
+			writeJSONError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if action == "reject" {
+			if err := s.gj.RejectMutation(r.Context(), id); err != nil {
+				writeJSONError(w, approvalStatus(err), err.Error())
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			writeJSON(w, map[string]string{"id": id, "status": "rejected"})
+			return
+		}
+
+		res, err := s.gj.ApproveMutation(r.Context(), id)
+		if err != nil && res == nil {
+			writeJSONError(w, approvalStatus(err), err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			w.WriteHeader(approvalStatus(err))
+		}
+		writeJSON(w, res)
+	})
+}
+
+// canReviewMutations returns the http status for a caller not allowed to
+// review pending mutations or 0 when allowed, only admin can review when
+// no approver roles are configured
+func (s *graphjinService) canReviewMutations(r *http.Request) int {
+	return s.adminStatus(r, s.conf.Core.Approvals.Roles)
+}
+
+func approvalStatus(err error) int {
+	if errors.Is(err, core.ErrNotPending) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
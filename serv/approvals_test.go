@@ -0,0 +1,49 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+func TestCanReviewMutations(t *testing.T) {
+	s := &graphjinService{conf: &Config{}}
+
+	send := func(userID any, role string) int {
+		r := httptest.NewRequest("GET", routeApprovals, nil)
+		c := context.WithValue(r.Context(), core.UserIDKey, userID)
+		c = context.WithValue(c, core.UserRoleKey, role)
+		return s.canReviewMutations(r.WithContext(c))
+	}
+
+	if code := send(nil, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", code)
+	}
+	if code := send("1", "user"); code != http.StatusForbidden {
+		t.Fatalf("expected only admin to review by default, got %d", code)
+	}
+	if code := send("1", "admin"); code != 0 {
+		t.Fatalf("expected admin to review by default, got %d", code)
+	}
+
+	s.conf.Core.Approvals.Roles = []string{"finance"}
+	if code := send("1", "admin"); code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d", code)
+	}
+	if code := send("1", "finance"); code != 0 {
+		t.Fatalf("expected an approver role to review, got %d", code)
+	}
+}
+
+func TestApprovalStatus(t *testing.T) {
+	if code := approvalStatus(core.ErrNotPending); code != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", code)
+	}
+	if code := approvalStatus(errors.New("approval: role 'user' cannot review mutations")); code != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %d", code)
+	}
+}
@@ -0,0 +1,169 @@
+package serv
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/dosco/graphjin/mongodriver"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// bulkLoader loads large inserts using the native bulk loading protocol
+// of the database driver
+type bulkLoader struct{}
+
+var bulkReaderID atomic.Uint64
+
+func (bulkLoader) BulkLoad(c context.Context, conn *sql.Conn, req core.BulkLoadRequest) (int64, error) {
+	switch req.DBType {
+	case "postgres":
+		return copyFromCSV(c, conn, req)
+	case "mysql", "mariadb":
+		return loadDataLocal(c, conn, req)
+	case "mongodb":
+		return insertMany(c, conn, req)
+	default:
+		return 0, core.ErrBulkLoadNotSupported
+	}
+}
+
+// copyFromCSV uses COPY FROM STDIN in the csv format so that postgres
+// parses the values into the column types
+func copyFromCSV(c context.Context, conn *sql.Conn, req core.BulkLoadRequest) (n int64, err error) {
+	cols := make([]string, len(req.Columns))
+	for i, col := range req.Columns {
+		cols[i] = pgx.Identifier{col}.Sanitize()
+	}
+	table := pgx.Identifier{req.Table}
+	if req.Schema != "" {
+		table = pgx.Identifier{req.Schema, req.Table}
+	}
+	q := fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv)",
+		table.Sanitize(), strings.Join(cols, ", "))
+	data := bulkCSV(req.Rows, "", false)
+
+	err = conn.Raw(func(dc any) error {
+		pc, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return core.ErrBulkLoadNotSupported
+		}
+		tag, err := pc.Conn().PgConn().CopyFrom(c, bytes.NewReader(data), q)
+		n = tag.RowsAffected()
+		return err
+	})
+	return
+}
+
+// loadDataLocal uses LOAD DATA LOCAL INFILE with the rows streamed from a
+// registered reader. The server must have local_infile enabled
+func loadDataLocal(c context.Context, conn *sql.Conn, req core.BulkLoadRequest) (int64, error) {
+	name := "gj_bulk_" + strconv.FormatUint(bulkReaderID.Add(1), 10)
+	data := bulkCSV(req.Rows, "NULL", true)
+
+	mysql.RegisterReaderHandler(name, func() io.Reader { return bytes.NewReader(data) })
+	defer mysql.DeregisterReaderHandler(name)
+
+	cols := make([]string, len(req.Columns))
+	for i, col := range req.Columns {
+		cols[i] = mysqlQuote(col)
+	}
+	table := mysqlQuote(req.Table)
+	if req.Schema != "" {
+		table = mysqlQuote(req.Schema) + "." + table
+	}
+	q := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 "+
+		"FIELDS TERMINATED BY ',' ENCLOSED BY '\"' ESCAPED BY '' LINES TERMINATED BY '\\n' (%s)",
+		name, table, strings.Join(cols, ", "))
+
+	res, err := conn.ExecContext(c, q)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// insertMany uses an unordered insertMany
+func insertMany(c context.Context, conn *sql.Conn, req core.BulkLoadRequest) (n int64, err error) {
+	docs := make([]map[string]any, len(req.Rows))
+	for i, row := range req.Rows {
+		doc := make(map[string]any, len(req.Columns))
+		for j, col := range req.Columns {
+			v := row[j]
+			if raw, ok := v.(json.RawMessage); ok {
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return 0, err
+				}
+			}
+			doc[col] = v
+		}
+		docs[i] = doc
+	}
+
+	err = conn.Raw(func(dc any) error {
+		mc, ok := dc.(*mongodriver.Conn)
+		if !ok {
+			return core.ErrBulkLoadNotSupported
+		}
+		n, err = mc.InsertMany(c, req.Table, docs)
+		return err
+	})
+	return
+}
+
+// bulkCSV encodes the rows as csv, strings are always quoted so that an
+// empty string is not read as null
+func bulkCSV(rows [][]any, null string, numericBool bool) []byte {
+	var b bytes.Buffer
+	for _, row := range rows {
+		for i, v := range row {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			switch v := v.(type) {
+			case nil:
+				b.WriteString(null)
+			case bool:
+				switch {
+				case numericBool && v:
+					b.WriteString("1")
+				case numericBool:
+					b.WriteString("0")
+				default:
+					b.WriteString(strconv.FormatBool(v))
+				}
+			case int64:
+				b.WriteString(strconv.FormatInt(v, 10))
+			case float64:
+				b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			case json.RawMessage:
+				csvQuote(&b, string(v))
+			case string:
+				csvQuote(&b, v)
+			default:
+				csvQuote(&b, fmt.Sprint(v))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+func csvQuote(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	b.WriteString(strings.ReplaceAll(s, `"`, `""`))
+	b.WriteByte('"')
+}
+
+func mysqlQuote(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
@@ -0,0 +1,25 @@
+package serv
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBulkCSV(t *testing.T) {
+	rows := [][]any{
+		{int64(1), "say \"hi\"", 2.5, true, nil},
+		{int64(2), "", json.RawMessage(`{"a":1}`), false, "x,y"},
+	}
+
+	exp := "1,\"say \"\"hi\"\"\",2.5,true,\n" +
+		"2,\"\",\"{\"\"a\"\":1}\",false,\"x,y\"\n"
+	if got := string(bulkCSV(rows, "", false)); got != exp {
+		t.Fatalf("unexpected postgres csv:\n%s", got)
+	}
+
+	exp = "1,\"say \"\"hi\"\"\",2.5,1,NULL\n" +
+		"2,\"\",\"{\"\"a\"\":1}\",0,\"x,y\"\n"
+	if got := string(bulkCSV(rows, "NULL", true)); got != exp {
+		t.Fatalf("unexpected mysql csv:\n%s", got)
+	}
+}
@@ -26,3 +26,45 @@ type ResponseCache interface {
 	// Close releases resources
 	Close() error
 }
+
+// defaultMaxCost is the query cost at which responses get the full TTL when
+// the TTL is scaled by cost
+const defaultMaxCost = 10000
+
+// entryTTLs returns the hard and fresh TTLs of a response. An @cache(ttl:)
+// on the query sets the TTL, otherwise when min_ttl is set the TTL is scaled
+// from min_ttl for the cheapest queries up to ttl at max_cost
+func entryTTLs(ctx context.Context, conf CachingConfig) (ttl, freshTTL time.Duration) {
+	ttl = time.Duration(conf.TTL) * time.Second
+	freshTTL = time.Duration(conf.FreshTTL) * time.Second
+	if freshTTL == 0 {
+		freshTTL = ttl // No SWR - fresh until hard TTL
+	}
+
+	ch, ok := core.CacheHintsFromContext(ctx)
+	if !ok {
+		return
+	}
+	confTTL := ttl
+
+	switch {
+	case ch.TTL > 0:
+		ttl = ch.TTL
+	case conf.MinTTL > 0 && conf.MinTTL < conf.TTL:
+		maxCost := conf.MaxCost
+		if maxCost <= 0 {
+			maxCost = defaultMaxCost
+		}
+		minTTL := time.Duration(conf.MinTTL) * time.Second
+		scale := float64(min(ch.Cost, maxCost)) / float64(maxCost)
+		ttl = minTTL + time.Duration(float64(confTTL-minTTL)*scale)
+	default:
+		return
+	}
+
+	// the fresh TTL keeps its share of the hard TTL
+	if confTTL > 0 {
+		freshTTL = time.Duration(float64(freshTTL) * float64(ttl) / float64(confTTL))
+	}
+	return
+}
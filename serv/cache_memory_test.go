@@ -206,3 +206,36 @@ func TestMemoryCache_LRUEviction(t *testing.T) {
 		t.Errorf("expected last entry to exist")
 	}
 }
+
+func TestEntryTTLs(t *testing.T) {
+	conf := CachingConfig{TTL: 3600, FreshTTL: 360, MinTTL: 60, MaxCost: 1000}
+
+	ttls := func(ch *core.CacheHints) (time.Duration, time.Duration) {
+		ctx := context.Background()
+		if ch != nil {
+			ctx = core.WithCacheHints(ctx, *ch)
+		}
+		return entryTTLs(ctx, conf)
+	}
+
+	if ttl, fresh := ttls(nil); ttl != time.Hour || fresh != 6*time.Minute {
+		t.Errorf("expected configured ttls without hints, got %s %s", ttl, fresh)
+	}
+	if ttl, _ := ttls(&core.CacheHints{Cost: 0}); ttl != time.Minute {
+		t.Errorf("expected min ttl for the cheapest query, got %s", ttl)
+	}
+	if ttl, fresh := ttls(&core.CacheHints{Cost: 500}); ttl != 1830*time.Second || fresh != 183*time.Second {
+		t.Errorf("expected scaled ttls, got %s %s", ttl, fresh)
+	}
+	if ttl, _ := ttls(&core.CacheHints{Cost: 5000}); ttl != time.Hour {
+		t.Errorf("expected full ttl above max cost, got %s", ttl)
+	}
+	if ttl, fresh := ttls(&core.CacheHints{Cost: 5, TTL: 2 * time.Hour}); ttl != 2*time.Hour || fresh != 12*time.Minute {
+		t.Errorf("expected @cache ttl override, got %s %s", ttl, fresh)
+	}
+
+	conf.MinTTL = 0
+	if ttl, _ := ttls(&core.CacheHints{Cost: 1}); ttl != time.Hour {
+		t.Errorf("expected no scaling without min ttl, got %s", ttl)
+	}
+}
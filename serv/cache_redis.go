@@ -233,11 +233,7 @@ func (c *RedisCache) Set(
 	}
 
 	now := time.Now()
-	ttl := time.Duration(c.conf.TTL) * time.Second
-	freshTTL := time.Duration(c.conf.FreshTTL) * time.Second
-	if freshTTL == 0 {
-		freshTTL = ttl // No SWR - fresh until hard TTL
-	}
+	ttl, freshTTL := entryTTLs(ctx, c.conf)
 
 	entry := CacheEntry{
 		Data:         data,
@@ -260,13 +256,17 @@ func (c *RedisCache) Set(
 	// Store response
 	pipe.Set(ctx, c.respKey(key), entryJSON, ttl)
 
+	// Indices are shared by entries with other TTLs, they are kept for
+	// at least the configured TTL
+	indexTTL := max(ttl, time.Duration(c.conf.TTL)*time.Second)
+
 	// Create indices based on ref count
 	if len(filteredRefs) <= rowLevelThreshold {
 		// Row-level indexing for precise invalidation
 		for _, ref := range filteredRefs {
 			rowKey := c.rowKey(ref.Table, ref.ID)
 			pipe.SAdd(ctx, rowKey, key)
-			pipe.Expire(ctx, rowKey, ttl)
+			pipe.Expire(ctx, rowKey, indexTTL)
 		}
 	} else {
 		// Table-level indexing for large results
@@ -277,7 +277,7 @@ func (c *RedisCache) Set(
 		for table := range tables {
 			tableKey := c.tableKey(table)
 			pipe.SAdd(ctx, tableKey, key)
-			pipe.Expire(ctx, tableKey, ttl)
+			pipe.Expire(ctx, tableKey, indexTTL)
 		}
 	}
 
@@ -0,0 +1,392 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/dosco/graphjin/mongodriver"
+)
+
+// cdcRetryDelay is the wait before reconnecting after capture fails
+const cdcRetryDelay = 5 * time.Second
+
+// defaultCDCSlot is the Postgres replication slot used when none is set
+const defaultCDCSlot = "graphjin_cdc"
+
+var errCDCNotSupported = errors.New("change capture is only supported with postgres and mongodb")
+
+// cdcStream is the change capture of the service. It is started with the
+// service, restarted when a deploy changes the cdc config and stopped on
+// shutdown
+type cdcStream struct {
+	mu     sync.Mutex
+	conf   CDCConfig
+	db     *sql.DB
+	dbType string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// initCDC starts capturing changes made to the database outside GraphJin.
+// Each change is passed to GraphJin which invalidates the cached responses
+// for the row and refreshes the subscriptions reading the table.
+func initCDC(s1 *HttpService) {
+	s1.restartCDC()
+}
+
+// restartCDC brings the change capture in line with the cdc config of the
+// current service, a running capture is left alone when its config is the same
+func (s1 *HttpService) restartCDC() {
+	s := s1.Load().(*graphjinService)
+	cs := &s1.cdc
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	conf := s.conf.CDC
+	if cs.cancel != nil && cs.conf == conf {
+		return
+	}
+	cs.stop(s)
+
+	if !conf.Enable {
+		return
+	}
+	db, dbType := s.cdcDB()
+	if db == nil {
+		s.log.Errorf("cdc: database not found: %s", conf.Database)
+		return
+	}
+
+	c, cancel := context.WithCancel(context.Background())
+	cs.conf, cs.db, cs.dbType = conf, db, dbType
+	cs.cancel, cs.done = cancel, make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		for {
+			err := runCDC(c, s1, conf, db, dbType)
+			if c.Err() != nil {
+				return
+			}
+			if errors.Is(err, errCDCNotSupported) {
+				s.log.Warnf("cdc: %s", err)
+				return
+			}
+			if err != nil {
+				s.log.Errorf("cdc: %s", err)
+			}
+
+			select {
+			case <-time.After(cdcRetryDelay):
+			case <-c.Done():
+				return
+			}
+		}
+	}(cs.done)
+}
+
+// stopCDC stops the change capture, called on shutdown
+func (s1 *HttpService) stopCDC() {
+	s := s1.Load().(*graphjinService)
+	cs := &s1.cdc
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.stop(s)
+}
+
+// stop cancels the capture and waits for it to end, with drop_slot the
+// Postgres replication slot is dropped so the database stops retaining WAL
+// for it
+func (cs *cdcStream) stop(s *graphjinService) {
+	if cs.cancel == nil {
+		return
+	}
+	cs.cancel()
+	<-cs.done
+	cs.cancel, cs.done = nil, nil
+
+	if !cs.conf.DropSlot || (cs.dbType != "" && cs.dbType != "postgres") {
+		return
+	}
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := cs.db.ExecContext(c,
+		`SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots WHERE slot_name = $1`,
+		cdcSlot(cs.conf))
+	if err != nil {
+		s.log.Warnf("cdc: dropping replication slot %s: %s", cdcSlot(cs.conf), err)
+	}
+}
+
+// cdcSlot returns the name of the Postgres replication slot
+func cdcSlot(conf CDCConfig) string {
+	if conf.Slot == "" {
+		return defaultCDCSlot
+	}
+	return conf.Slot
+}
+
+// runCDC reads changes until an error or the context is done. The current
+// service is loaded for every batch of changes so schema reloads are picked up.
+func runCDC(c context.Context, s1 *HttpService, conf CDCConfig, db *sql.DB, dbType string) error {
+	notify := func(refs []core.RowRef) {
+		cur := s1.Load().(*graphjinService)
+		if cur.gj == nil || len(refs) == 0 {
+			return
+		}
+		if err := cur.gj.NotifyChanges(c, refs); err != nil {
+			cur.log.Warnf("cdc: %s", err)
+		}
+	}
+
+	switch dbType {
+	case "", "postgres":
+		pc := &pgChanges{
+			db:       db,
+			slot:     cdcSlot(conf),
+			interval: conf.PollInterval,
+			pk:       newPKLookup(s1),
+		}
+		return pc.run(c, notify)
+
+	case "mongodb":
+		return watchMongoChanges(c, db, notify)
+
+	default:
+		return errCDCNotSupported
+	}
+}
+
+// cdcDB returns the database changes are captured from and its type
+func (s *graphjinService) cdcDB() (*sql.DB, string) {
+	name := s.conf.CDC.Database
+	if name == "" {
+		return s.anyDB(), s.conf.DBType
+	}
+	dbType := s.conf.DBType
+	if dc, ok := s.conf.Core.Databases[name]; ok && dc.Type != "" {
+		dbType = strings.ToLower(dc.Type)
+	}
+	return s.dbs[name], dbType
+}
+
+// pgChanges reads the changes from a Postgres logical replication slot
+// using the built-in test_decoding output plugin
+type pgChanges struct {
+	db       *sql.DB
+	slot     string
+	interval time.Duration
+	pk       func(table string) string
+}
+
+func (pc *pgChanges) run(c context.Context, fn func([]core.RowRef)) error {
+	if pc.interval <= 0 {
+		pc.interval = time.Second
+	}
+
+	var exists bool
+	err := pc.db.QueryRowContext(c,
+		`SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)`,
+		pc.slot).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = pc.db.ExecContext(c,
+			`SELECT pg_create_logical_replication_slot($1, 'test_decoding')`, pc.slot)
+		if err != nil {
+			return fmt.Errorf("creating replication slot %s: %w", pc.slot, err)
+		}
+	}
+
+	ticker := time.NewTicker(pc.interval)
+	defer ticker.Stop()
+
+	for {
+		refs, err := pc.read(c)
+		if err != nil {
+			return err
+		}
+		fn(refs)
+
+		select {
+		case <-ticker.C:
+		case <-c.Done():
+			return c.Err()
+		}
+	}
+}
+
+// read consumes the pending changes in the slot
+func (pc *pgChanges) read(c context.Context) ([]core.RowRef, error) {
+	rows, err := pc.db.QueryContext(c,
+		`SELECT data FROM pg_logical_slot_get_changes($1, NULL, NULL, 'skip-empty-xacts', '1')`,
+		pc.slot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var refs []core.RowRef
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if ref, ok := parseTestDecoding(data, pc.pk); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, rows.Err()
+}
+
+// parseTestDecoding converts a test_decoding change into a row reference.
+// Changes look like:
+//
+//	table public.products: UPDATE: id[integer]:1 name[text]:'Shoe'
+//	table public.products: UPDATE: old-key: id[integer]:1 new-tuple: id[integer]:2 ...
+//	table public.products: DELETE: id[integer]:1
+//
+// The old key is used when present since it is the row cached responses
+// reference. BEGIN and COMMIT lines are ignored.
+func parseTestDecoding(data string, pk func(table string) string) (core.RowRef, bool) {
+	var ref core.RowRef
+
+	if !strings.HasPrefix(data, "table ") {
+		return ref, false
+	}
+	data = data[len("table "):]
+
+	i := strings.Index(data, ": ")
+	if i == -1 {
+		return ref, false
+	}
+	table := data[:i]
+	if j := strings.LastIndexByte(table, '.'); j != -1 {
+		table = table[j+1:]
+	}
+	ref.Table = strings.Trim(table, `"`)
+
+	// skip the operation
+	data = data[i+2:]
+	if i = strings.Index(data, ": "); i == -1 {
+		return ref, false
+	}
+	data = data[i+2:]
+
+	if strings.HasPrefix(data, "old-key: ") {
+		data = data[len("old-key: "):]
+	}
+
+	col := pk(ref.Table)
+	if col == "" {
+		return ref, false
+	}
+
+	for data != "" {
+		name, val, rest, ok := nextTestDecodingCol(data)
+		if !ok {
+			return ref, false
+		}
+		if name == col {
+			ref.ID = val
+			return ref, true
+		}
+		data = strings.TrimPrefix(rest, "new-tuple: ")
+	}
+	return ref, false
+}
+
+// nextTestDecodingCol reads a name[type]:value column off the change
+func nextTestDecodingCol(data string) (name, val, rest string, ok bool) {
+	i := strings.IndexByte(data, '[')
+	if i == -1 {
+		return
+	}
+	name = strings.Trim(data[:i], `"`)
+
+	j := strings.Index(data[i:], "]:")
+	if j == -1 {
+		return
+	}
+	data = data[i+j+2:]
+
+	if strings.HasPrefix(data, "'") {
+		// quoted value, quotes are escaped by doubling them
+		var sb strings.Builder
+		k := 1
+		for ; k < len(data); k++ {
+			if data[k] == '\'' {
+				if k+1 < len(data) && data[k+1] == '\'' {
+					sb.WriteByte('\'')
+					k++
+					continue
+				}
+				break
+			}
+			sb.WriteByte(data[k])
+		}
+		val, rest = sb.String(), strings.TrimPrefix(data[min(k+1, len(data)):], " ")
+	} else {
+		if k := strings.IndexByte(data, ' '); k != -1 {
+			val, rest = data[:k], data[k+1:]
+		} else {
+			val = data
+		}
+	}
+	return name, val, rest, true
+}
+
+// newPKLookup returns the primary key column of a table, keys found in the
+// current schema are remembered
+func newPKLookup(s1 *HttpService) func(table string) string {
+	var mu sync.Mutex
+	keys := make(map[string]string)
+
+	return func(table string) string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if pk, ok := keys[table]; ok {
+			return pk
+		}
+		s := s1.Load().(*graphjinService)
+		if s.gj == nil {
+			return ""
+		}
+		ts, err := s.gj.GetTableSchema(table)
+		if err != nil || ts.PrimaryKey == "" {
+			return ""
+		}
+		keys[table] = ts.PrimaryKey
+		return ts.PrimaryKey
+	}
+}
+
+// watchMongoChanges reads changes from a MongoDB change stream
+func watchMongoChanges(c context.Context, db *sql.DB, fn func([]core.RowRef)) error {
+	conn, err := db.Conn(c)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck
+
+	return conn.Raw(func(dc any) error {
+		mc, ok := dc.(*mongodriver.Conn)
+		if !ok {
+			return errCDCNotSupported
+		}
+		return mc.WatchChanges(c, func(collection, id string) {
+			fn([]core.RowRef{{Table: collection, ID: id}})
+		})
+	})
+}
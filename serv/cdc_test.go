@@ -0,0 +1,44 @@
+package serv
+
+import (
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+func TestParseTestDecoding(t *testing.T) {
+	pk := func(table string) string {
+		if table == "products" || table == "Users" {
+			return "id"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		data string
+		ref  core.RowRef
+		ok   bool
+	}{
+		{`table public.products: INSERT: id[integer]:1 name[text]:'Shoe'`,
+			core.RowRef{Table: "products", ID: "1"}, true},
+		{`table public.products: UPDATE: name[text]:'it''s' id[integer]:7`,
+			core.RowRef{Table: "products", ID: "7"}, true},
+		{`table public.products: UPDATE: old-key: id[integer]:1 new-tuple: id[integer]:2 name[text]:'a'`,
+			core.RowRef{Table: "products", ID: "1"}, true},
+		{`table public.products: DELETE: id[integer]:3`,
+			core.RowRef{Table: "products", ID: "3"}, true},
+		{`table public."Users": INSERT: "id"[text]:'a b'`,
+			core.RowRef{Table: "Users", ID: "a b"}, true},
+		{`table public.logs: INSERT: id[integer]:1`, core.RowRef{}, false},
+		{`table public.products: DELETE: (no-tuple-data)`, core.RowRef{}, false},
+		{`BEGIN`, core.RowRef{}, false},
+		{`COMMIT`, core.RowRef{}, false},
+	}
+
+	for _, tt := range tests {
+		ref, ok := parseTestDecoding(tt.data, pk)
+		if ok != tt.ok || (ok && ref != tt.ref) {
+			t.Errorf("%s: expected %v %v, got %v %v", tt.data, tt.ref, tt.ok, ref, ok)
+		}
+	}
+}
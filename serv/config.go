@@ -66,6 +66,11 @@ type Serv struct {
 	// "json" (always JSON), or "simple" (always colored console)
 	LogFormat string `mapstructure:"log_format" jsonschema:"title=Logging Format,enum=auto,enum=json,enum=simple"`
 
+	// Variables replaced with [REDACTED] in request logs when log_vars is on,
+	// matched case insensitively against the names of the variables and their
+	// nested keys. Defaults to password, secret, token and key
+	LogRedactVars []string `mapstructure:"log_redact_vars" jsonschema:"title=Redacted Log Variables"`
+
 	// The host and port the service runs on. Example localhost:8080
 	HostPort string `mapstructure:"host_port" jsonschema:"title=Host and Port"`
 
@@ -81,12 +86,22 @@ type Serv struct {
 	// Sets the API rate limits
 	RateLimiter RateLimiter `mapstructure:"rate_limiter" jsonschema:"title=Set API Rate Limiting"`
 
+	// Limits the concurrent executions of saved queries by name
+	QueryLimits map[string]QueryLimit `mapstructure:"query_limits" jsonschema:"title=Saved Query Concurrency Limits"`
+
+	// Limits the concurrent requests of every authenticated user
+	UserLimit UserLimit `mapstructure:"user_limit" jsonschema:"title=Per-User Concurrency Limit"`
+
 	// Enables the Server-Timing HTTP header
 	ServerTiming bool `mapstructure:"server_timing" jsonschema:"title=Server Timing HTTP Header,default=true"`
 
 	// Enable the web UI. Disabled in production
 	WebUI bool `mapstructure:"web_ui" jsonschema:"title=Enable Web UI,default=false"`
 
+	// Enable the GraphiQL playground with a saved query browser. The generated
+	// SQL panel is only shown outside production
+	Playground bool `mapstructure:"playground" jsonschema:"title=Enable Playground,default=false"`
+
 	// Enable OpenTrace request tracing
 	EnableTracing bool `mapstructure:"enable_tracing" jsonschema:"title=Enable Tracing,default=true"`
 
@@ -96,15 +111,24 @@ type Serv struct {
 	// Enable blocking requests with a HTTP 401 on auth failure
 	AuthFailBlock bool `mapstructure:"auth_fail_block" jsonschema:"title=Block Request on Authorization Failure"`
 
-	// Sets the HTTP CORS Access-Control-Allow-Origin header
+	// Sets the HTTP CORS Access-Control-Allow-Origin header.
+	// Deprecated: use cors.allowed_origins
 	AllowedOrigins []string `mapstructure:"cors_allowed_origins" jsonschema:"title=HTTP CORS Allowed Origins"`
 
-	// Sets the HTTP CORS Access-Control-Allow-Headers header
+	// Sets the HTTP CORS Access-Control-Allow-Headers header.
+	// Deprecated: use cors.allowed_headers
 	AllowedHeaders []string `mapstructure:"cors_allowed_headers" jsonschema:"title=HTTP CORS Allowed Headers"`
 
-	// Enables debug logs for CORS
+	// Enables debug logs for CORS.
+	// Deprecated: use cors.debug
 	DebugCORS bool `mapstructure:"cors_debug" jsonschema:"title=Log CORS"`
 
+	// CORS for the GraphQL, REST and subscription endpoints
+	CORS CORSConfig `mapstructure:"cors" jsonschema:"title=CORS"`
+
+	// CSRF protection when the auth token is sent in a cookie
+	CSRF CSRFConfig `mapstructure:"csrf" jsonschema:"title=CSRF Protection"`
+
 	// Sets the HTTP Cache-Control header
 	CacheControl string `mapstructure:"cache_control" jsonschema:"title=Enable Cache-Control"`
 
@@ -125,6 +149,170 @@ type Serv struct {
 
 	// Response caching configuration
 	Caching CachingConfig `mapstructure:"caching" jsonschema:"title=Caching Configuration"`
+
+	// Makes retrying mutations safe with the Idempotency-Key header
+	Idempotency IdempotencyConfig `mapstructure:"idempotency" jsonschema:"title=Idempotency Keys"`
+
+	// Capture changes made by other writers to refresh caches and subscriptions
+	CDC CDCConfig `mapstructure:"cdc" jsonschema:"title=Change Data Capture"`
+
+	// Lets admins make requests as another user
+	Impersonation Impersonation `mapstructure:"impersonation" jsonschema:"title=Impersonation"`
+
+	// Lets callers direct a request at one of the configured databases
+	DatabaseRouting DatabaseRouting `mapstructure:"database_routing" jsonschema:"title=Database Routing"`
+
+	// Alerts on the error rate and latency of queries
+	Alerts AlertsConfig `mapstructure:"alerts" jsonschema:"title=Alerts"`
+
+	// Mirrors a share of the read traffic to a second database
+	Shadow ShadowConfig `mapstructure:"shadow" jsonschema:"title=Shadow Traffic"`
+
+	// WebSocket keepalive and subscription backpressure
+	WebSocket WebSocketConfig `mapstructure:"websocket" jsonschema:"title=WebSocket"`
+
+	// Publishing of the OpenAPI spec and GraphQL schema
+	Contracts ContractsConfig `mapstructure:"contracts" jsonschema:"title=API Contracts"`
+
+	// Feature flags passed to queries as variables
+	FeatureFlags FeatureFlagsConfig `mapstructure:"feature_flags" jsonschema:"title=Feature Flags"`
+
+	// Read-only and maintenance modes switched by admins at runtime
+	Modes ModesConfig `mapstructure:"modes" jsonschema:"title=Service Modes"`
+
+	// Admin endpoint returning the effective config with secrets redacted
+	ConfigDump ConfigDumpConfig `mapstructure:"config_dump" jsonschema:"title=Config Dump"`
+}
+
+// ConfigDumpConfig configures the /api/v1/admin/config/effective endpoint that
+// returns the config the service is actually running with
+type ConfigDumpConfig struct {
+	// Roles allowed to fetch the config, defaults to admin
+	Roles []string `mapstructure:"roles" jsonschema:"title=Admin Roles,default=admin"`
+
+	// Extra config keys to redact, keys containing any of these are redacted
+	Redact []string `mapstructure:"redact" jsonschema:"title=Redacted Keys"`
+}
+
+// ModesConfig configures the read-only and maintenance modes. The modes are
+// switched with the /api/v1/admin/mode endpoint and saved to a file in the
+// config folder so they survive restarts.
+type ModesConfig struct {
+	// Roles allowed to switch the modes, defaults to admin
+	Roles []string `mapstructure:"roles" jsonschema:"title=Admin Roles,default=admin"`
+
+	// Message returned to clients during maintenance when none is set with the mode
+	Message string `mapstructure:"message" jsonschema:"title=Maintenance Message"`
+
+	// File in the config folder the modes are saved to
+	StateFile string `mapstructure:"state_file" jsonschema:"title=State File,default=service_mode.json"`
+}
+
+// CORSConfig sets the origins allowed to call the API from a browser, it
+// applies to the GraphQL, REST and CRUD endpoints and to the origin check
+// of subscription upgrade requests
+type CORSConfig struct {
+	// Origins allowed, eg. https://example.com, https://*.example.com or *
+	AllowedOrigins []string `mapstructure:"allowed_origins" jsonschema:"title=Allowed Origins"`
+
+	// Request headers allowed
+	AllowedHeaders []string `mapstructure:"allowed_headers" jsonschema:"title=Allowed Headers"`
+
+	// Request methods allowed
+	AllowedMethods []string `mapstructure:"allowed_methods" jsonschema:"title=Allowed Methods"`
+
+	// Time browsers can cache a preflight response
+	MaxAge time.Duration `mapstructure:"max_age" jsonschema:"title=Preflight Max Age"`
+
+	// Allow cookies and auth headers on cross-origin requests
+	AllowCredentials bool `mapstructure:"allow_credentials" jsonschema:"title=Allow Credentials,default=false"`
+
+	// Origins allowed credentials, all allowed origins when empty
+	CredentialOrigins []string `mapstructure:"credential_origins" jsonschema:"title=Credential Origins"`
+
+	// Enables debug logs for CORS
+	Debug bool `mapstructure:"debug" jsonschema:"title=Debug"`
+}
+
+// CSRFConfig controls the CSRF protection used when auth.cookie is set.
+// Cross-site requests made with the auth cookie are rejected based on the
+// Origin and Sec-Fetch-Site headers, double submit also requires a token.
+type CSRFConfig struct {
+	// Turn off CSRF protection
+	Disable bool `mapstructure:"disable" jsonschema:"title=Disable,default=false"`
+
+	// Origins other than the service trusted to make requests, eg. the web
+	// app when it is served from another origin
+	TrustedOrigins []string `mapstructure:"trusted_origins" jsonschema:"title=Trusted Origins"`
+
+	// Also require the value of the csrf cookie in the csrf header on
+	// requests other than GET and HEAD
+	DoubleSubmit bool `mapstructure:"double_submit" jsonschema:"title=Double Submit Token,default=false"`
+
+	// Name of the cookie holding the double submit token
+	CookieName string `mapstructure:"cookie_name" jsonschema:"title=Cookie Name,default=_gj_csrf"`
+
+	// Name of the header the double submit token is sent in
+	HeaderName string `mapstructure:"header_name" jsonschema:"title=Header Name,default=X-CSRF-Token"`
+}
+
+// ContractsConfig controls the /api/v1/openapi.json and /api/v1/schema.graphql
+// endpoints. Both are cached for max age and served with an etag so clients
+// can poll them cheaply.
+type ContractsConfig struct {
+	// Turn off both endpoints
+	Disable bool `mapstructure:"disable" jsonschema:"title=Disable,default=false"`
+
+	// Only serve the contracts to authenticated users
+	RequireAuth bool `mapstructure:"require_auth" jsonschema:"title=Require Auth,default=false"`
+
+	// Roles allowed to fetch the contracts, implies require auth
+	Roles []string `mapstructure:"roles" jsonschema:"title=Allowed Roles"`
+
+	// Time the contracts are cached for by the service and by clients
+	MaxAge time.Duration `mapstructure:"max_age" jsonschema:"title=Max Age,default=1m"`
+}
+
+// WebSocketConfig controls ping/pong keepalive and the per-connection send
+// queue for subscriptions. When a client reads slower than updates arrive
+// pending updates for a subscription are coalesced to the latest one and new
+// updates are dropped once the queue is full.
+type WebSocketConfig struct {
+	// Interval between pings sent to the client, 0 disables keepalive
+	PingInterval time.Duration `mapstructure:"ping_interval" jsonschema:"title=Ping Interval,default=30s"`
+
+	// Time to wait for a pong (or any message) after a ping before the
+	// connection is closed. Also used as the write timeout
+	PongTimeout time.Duration `mapstructure:"pong_timeout" jsonschema:"title=Pong Timeout,default=10s"`
+
+	// Maximum number of subscriptions with an unsent update per connection
+	SendQueueSize int `mapstructure:"send_queue_size" jsonschema:"title=Send Queue Size,default=32"`
+}
+
+// Impersonation lets a caller with one of the admin roles make a request as
+// another user by setting the X-Impersonate-User-ID header (and optionally
+// X-Impersonate-Role). Every impersonated request is logged.
+type Impersonation struct {
+	// Enable impersonation
+	Enable bool `jsonschema:"title=Enable Impersonation,default=false"`
+
+	// Roles allowed to impersonate other users, defaults to admin
+	Roles []string `jsonschema:"title=Admin Roles,default=admin"`
+
+	// Roles that can be set with the X-Impersonate-Role header, defaults to
+	// any role other than the roles allowed to impersonate
+	TargetRoles []string `mapstructure:"target_roles" jsonschema:"title=Target Roles"`
+}
+
+// DatabaseRouting lets a caller with one of the allowed roles run a request
+// against one of the configured databases, for example a reporting replica or
+// a tenant shard, by setting the X-GraphJin-Database header.
+type DatabaseRouting struct {
+	// Enable database routing
+	Enable bool `jsonschema:"title=Enable Database Routing,default=false"`
+
+	// Roles allowed to pick the database, defaults to admin
+	Roles []string `jsonschema:"title=Allowed Roles,default=admin"`
 }
 
 // Database configuration
@@ -197,6 +385,32 @@ type RateLimiter struct {
 	IPHeader string `mapstructure:"ip_header" jsonschema:"title=IP From HTTP Header,example=X-Forwarded-For"`
 }
 
+// QueryLimit sets the concurrency limit for a saved query
+type QueryLimit struct {
+	// Max concurrent executions of the query
+	MaxConcurrent int `mapstructure:"max_concurrent" jsonschema:"title=Max Concurrent Executions"`
+
+	// Max requests waiting for a free slot, requests beyond this are rejected
+	MaxQueued int `mapstructure:"max_queued" jsonschema:"title=Max Queued Requests"`
+
+	// How long a queued request waits for a free slot before it is rejected
+	QueueTimeout time.Duration `mapstructure:"queue_timeout" jsonschema:"title=Queue Timeout,example=2s"`
+}
+
+// UserLimit sets the limit on the in-flight requests of each authenticated
+// user. Requests over the limit wait in a queue of the user and are rejected
+// with a HTTP 429 when the queue is full or the wait times out
+type UserLimit struct {
+	// Max in-flight requests of a user
+	MaxConcurrent int `mapstructure:"max_concurrent" jsonschema:"title=Max Concurrent Requests"`
+
+	// Max requests of a user waiting for a free slot
+	MaxQueued int `mapstructure:"max_queued" jsonschema:"title=Max Queued Requests"`
+
+	// How long a queued request waits for a free slot before it is rejected
+	QueueTimeout time.Duration `mapstructure:"queue_timeout" jsonschema:"title=Queue Timeout,example=2s"`
+}
+
 // MCPConfig configures the Model Context Protocol (MCP) server
 // MCP enables AI assistants to interact with GraphJin via function calling
 //
@@ -288,10 +502,118 @@ type CachingConfig struct {
 	// Soft TTL for stale-while-revalidate in seconds (0 = disabled)
 	FreshTTL int `mapstructure:"fresh_ttl" jsonschema:"title=Fresh TTL for SWR,default=300"`
 
+	// TTL in seconds for the cheapest queries, when set the TTL of a response
+	// is scaled by the estimated cost of its query from min_ttl up to ttl
+	MinTTL int `mapstructure:"min_ttl" jsonschema:"title=Minimum Cache TTL"`
+
+	// Query cost at which responses get the full TTL
+	MaxCost int `mapstructure:"max_cost" jsonschema:"title=Cost for Full TTL,default=10000"`
+
 	// Tables to exclude from caching
 	ExcludeTables []string `mapstructure:"exclude_tables" jsonschema:"title=Exclude Tables"`
 }
 
+// IdempotencyConfig configures idempotency keys for mutations. The response of
+// a mutation sent with an Idempotency-Key header is stored under the key and
+// retries with the same key get the stored response instead of running the
+// mutation again. Keys are stored in Redis when redis.url is set
+type IdempotencyConfig struct {
+	// Enable idempotency keys
+	Enable bool `mapstructure:"enable" jsonschema:"title=Enable Idempotency Keys,default=false"`
+
+	// How long a stored response is kept in seconds
+	TTL int `mapstructure:"ttl" jsonschema:"title=Key TTL,default=86400"`
+}
+
+// CDCConfig configures change data capture. Rows changed outside GraphJin
+// (by other services or by hand) invalidate the response cache and refresh
+// subscriptions reading the changed tables. Postgres changes are read from a
+// logical replication slot (wal_level must be logical) and MongoDB changes
+// from a change stream (needs a replica set). MySQL binlog capture is not
+// supported
+type CDCConfig struct {
+	// Enable change data capture
+	Enable bool `mapstructure:"enable" jsonschema:"title=Enable CDC,default=false"`
+
+	// Database to capture changes from, defaults to the default database
+	Database string `mapstructure:"database" jsonschema:"title=Database"`
+
+	// Name of the Postgres logical replication slot, created if missing
+	Slot string `mapstructure:"slot" jsonschema:"title=Replication Slot,default=graphjin_cdc"`
+
+	// How often the Postgres replication slot is read
+	PollInterval time.Duration `mapstructure:"poll_interval" jsonschema:"title=Poll Interval,default=1s"`
+
+	// Drop the Postgres replication slot when capture stops, on shutdown or
+	// when a config change turns it off. Without it the slot keeps the
+	// changes made while GraphJin is down and Postgres retains the WAL for
+	// them until capture resumes
+	DropSlot bool `mapstructure:"drop_slot" jsonschema:"title=Drop Replication Slot,default=false"`
+}
+
+// AlertsConfig checks the requests of each query against the rules at the
+// end of every window and alerts when a rule starts or stops failing.
+// Alerts are logged and posted to the webhook when set.
+type AlertsConfig struct {
+	// URL the alerts are posted to as JSON
+	Webhook string `mapstructure:"webhook" jsonschema:"title=Webhook URL"`
+
+	// Length of the window the rules are checked over
+	Window time.Duration `mapstructure:"window" jsonschema:"title=Window,default=1m"`
+
+	// Rules checked for every window
+	Rules []AlertRule `mapstructure:"rules" jsonschema:"title=Rules"`
+}
+
+// AlertRule is a threshold on the error rate or the p95 latency of a query
+type AlertRule struct {
+	// Name of the query, empty applies the rule to every query on its own
+	Query string `mapstructure:"query" jsonschema:"title=Query Name"`
+
+	// Alert when the percentage of failed requests is over this
+	ErrorRate float64 `mapstructure:"error_rate" jsonschema:"title=Error Rate Percent,example=5"`
+
+	// Alert when the 95th percentile of the response time is over this
+	P95 time.Duration `mapstructure:"p95" jsonschema:"title=P95 Latency,example=500ms"`
+
+	// Windows with fewer requests are not checked
+	MinRequests int `mapstructure:"min_requests" jsonschema:"title=Minimum Requests,default=10"`
+}
+
+// ShadowConfig mirrors a percentage of the queries to a second configured
+// database in the background and logs where its results differ in shape
+// from the ones returned to the client
+type ShadowConfig struct {
+	// Database the queries are mirrored to, one of the configured databases
+	Database string `mapstructure:"database" jsonschema:"title=Database"`
+
+	// Percentage of the queries that are mirrored
+	Percent float64 `mapstructure:"percent" jsonschema:"title=Percent,example=5"`
+
+	// Time a mirrored query can take
+	Timeout time.Duration `mapstructure:"timeout" jsonschema:"title=Timeout,default=10s"`
+
+	// Mirrored queries running at the same time, others are dropped
+	MaxConcurrent int `mapstructure:"max_concurrent" jsonschema:"title=Max Concurrent,default=10"`
+}
+
+// FeatureFlagsConfig sets the feature flags of the deployment. Each flag is
+// passed to queries as the variable ff_<name> so it can be used in role
+// filters and with @include(ifVar: $ff_<name>) to dark launch changes
+type FeatureFlagsConfig struct {
+	// Flag values, flags read from the table override these
+	Flags map[string]bool `mapstructure:"flags" jsonschema:"title=Flags"`
+
+	// Table the flags are read from, it needs a name and an enabled column
+	Table string `mapstructure:"table" jsonschema:"title=Table"`
+
+	// Database the table is in, defaults to the default database
+	Database string `mapstructure:"database" jsonschema:"title=Database"`
+
+	// How often the flags are read from the table
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" jsonschema:"title=Refresh Interval,default=30s"`
+}
+
 // Telemetry struct contains OpenCensus metrics and tracing related config
 /*
 type Telemetry struct {
@@ -441,6 +763,10 @@ func newViperWithDefaults() *viper.Viper {
 
 	vi.SetDefault("host_port", "0.0.0.0:8080")
 	vi.SetDefault("web_ui", false)
+	vi.SetDefault("playground", false)
+	vi.SetDefault("websocket.ping_interval", "30s")
+	vi.SetDefault("websocket.pong_timeout", "10s")
+	vi.SetDefault("websocket.send_queue_size", 32)
 	vi.SetDefault("enable_tracing", false)
 	vi.SetDefault("auth_fail_block", false)
 	vi.SetDefault("seed_file", "seed.js")
@@ -479,6 +805,10 @@ func newViperWithDefaults() *viper.Viper {
 	vi.SetDefault("caching.ttl", 3600)
 	vi.SetDefault("caching.fresh_ttl", 300)
 
+	// CDC defaults
+	vi.SetDefault("cdc.slot", "graphjin_cdc")
+	vi.SetDefault("cdc.poll_interval", "1s")
+
 	return vi
 }
 
@@ -0,0 +1,163 @@
+package serv
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/dosco/graphjin/serv/v3/internal/etags"
+	"go.uber.org/zap"
+)
+
+const defaultContractsMaxAge = time.Minute
+
+// contract is a generated API contract and its etag
+type contract struct {
+	body []byte
+	etag string
+	at   time.Time
+}
+
+// contractCache keeps the generated contracts for max age so docs portals
+// and codegen polling the endpoints do not rebuild them on every request
+type contractCache struct {
+	sync.Mutex
+	items map[string]contract
+}
+
+// get returns the cached contract or generates it when missing or expired
+func (cc *contractCache) get(key string, maxAge time.Duration,
+	gen func() ([]byte, error),
+) (contract, error) {
+	cc.Lock()
+	defer cc.Unlock()
+
+	if v, ok := cc.items[key]; ok && time.Since(v.at) < maxAge {
+		return v, nil
+	}
+
+	b, err := gen()
+	if err != nil {
+		return contract{}, err
+	}
+
+	v := contract{
+		body: b,
+		etag: fmt.Sprintf(`"%x"`, sha256.Sum256(b)),
+		at:   time.Now(),
+	}
+	if cc.items == nil {
+		cc.items = make(map[string]contract)
+	}
+	cc.items[key] = v
+	return v, nil
+}
+
+// contractsMaxAge returns the time contracts are cached for
+func (s *graphjinService) contractsMaxAge() time.Duration {
+	if v := s.conf.Contracts.MaxAge; v > 0 {
+		return v
+	}
+	return defaultContractsMaxAge
+}
+
+// canReadContracts returns the http status for a caller not allowed to read
+// the contracts or 0 when allowed
+func (s *graphjinService) canReadContracts(r *http.Request) int {
+	conf := s.conf.Contracts
+	if !conf.RequireAuth && len(conf.Roles) == 0 {
+		return 0
+	}
+
+	c := r.Context()
+	if c.Value(core.UserIDKey) == nil {
+		return http.StatusUnauthorized
+	}
+	if len(conf.Roles) == 0 {
+		return 0
+	}
+	if role, _ := c.Value(core.UserRoleKey).(string); slices.Contains(conf.Roles, role) {
+		return 0
+	}
+	return http.StatusForbidden
+}
+
+// contractHandler serves an API contract built by gen with caching headers,
+// requests with a matching etag get a not modified response
+func (s1 *HttpService) contractHandler(ns *string, name, contentType string,
+	gen func(gj *core.GraphJin) ([]byte, error),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+		// Handle preflight requests
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Only allow GET requests
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if code := s.canReadContracts(r); code != 0 {
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+
+		key := name
+		if ns != nil {
+			key = *ns + "/" + name
+		}
+
+		maxAge := s.contractsMaxAge()
+		v, err := s.contracts.get(key, maxAge, func() ([]byte, error) {
+			return gen(s.gj)
+		})
+		if err != nil {
+			s.log.Error("Failed to generate "+name, zap.Error(err))
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		scope := "public"
+		if s.conf.Contracts.RequireAuth || len(s.conf.Contracts.Roles) != 0 {
+			scope = "private"
+		}
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int(maxAge.Seconds())))
+		w.Header().Set("ETag", v.etag)
+
+		if etags.IsFresh(r.Header, w.Header()) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(v.body); err != nil {
+			s.log.Error("Failed to write "+name, zap.Error(err))
+		}
+	})
+}
+
+// openAPIHandler serves the OpenAPI specification
+func (s1 *HttpService) openAPIHandler(ns *string) http.Handler {
+	return s1.contractHandler(ns, "OpenAPI spec", "application/json",
+		func(gj *core.GraphJin) ([]byte, error) { return gj.GetOpenAPISpec() })
+}
+
+// schemaSDLHandler serves the GraphQL schema in SDL
+func (s1 *HttpService) schemaSDLHandler(ns *string) http.Handler {
+	return s1.contractHandler(ns, "GraphQL schema", "application/graphql; charset=utf-8",
+		func(gj *core.GraphJin) ([]byte, error) { return gj.GetSDL() })
+}
@@ -0,0 +1,101 @@
+package serv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+func TestContractCache(t *testing.T) {
+	var cc contractCache
+	calls := 0
+	gen := func() ([]byte, error) {
+		calls++
+		return []byte("type Query"), nil
+	}
+
+	v1, err := cc.get("sdl", time.Minute, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, _ := cc.get("sdl", time.Minute, gen)
+	if calls != 1 || v1.etag != v2.etag || v1.etag == "" {
+		t.Fatalf("expected the cached contract, got %d calls", calls)
+	}
+
+	if _, err := cc.get("sdl", 0, gen); err != nil || calls != 2 {
+		t.Fatalf("expected an expired contract to be rebuilt, got %d calls", calls)
+	}
+}
+
+func TestCanReadContracts(t *testing.T) {
+	s := &graphjinService{conf: &Config{}}
+
+	send := func(userID any, role string) int {
+		r := httptest.NewRequest("GET", routeSchemaSDL, nil)
+		c := context.WithValue(r.Context(), core.UserIDKey, userID)
+		c = context.WithValue(c, core.UserRoleKey, role)
+		return s.canReadContracts(r.WithContext(c))
+	}
+
+	if code := send(nil, ""); code != 0 {
+		t.Fatalf("expected anyone to read the contracts, got %d", code)
+	}
+
+	s.conf.Contracts.RequireAuth = true
+	if code := send(nil, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", code)
+	}
+	if code := send("1", "user"); code != 0 {
+		t.Fatalf("expected an authenticated user to read the contracts, got %d", code)
+	}
+
+	s.conf.Contracts.Roles = []string{"developer"}
+	if code := send("1", "user"); code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d", code)
+	}
+	if code := send("1", "developer"); code != 0 {
+		t.Fatalf("expected the developer role to read the contracts, got %d", code)
+	}
+}
+
+func TestContractHandler(t *testing.T) {
+	logger := zap.NewNop()
+	s1 := &HttpService{}
+	s1.Store(&graphjinService{
+		conf:      &Config{},
+		log:       logger.Sugar(),
+		contracts: &contractCache{},
+	})
+
+	h := s1.contractHandler(nil, "GraphQL schema", "application/graphql; charset=utf-8",
+		func(*core.GraphJin) ([]byte, error) { return []byte("type Query"), nil })
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", routeSchemaSDL, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "type Query" {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Fatalf("unexpected cache control: %s", cc)
+	}
+
+	r := httptest.NewRequest("GET", routeSchemaSDL, nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected not modified, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", routeSchemaSDL, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package serv
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/rs/cors"
+)
+
+var (
+	defaultCORSHeaders = []string{
+		"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization",
+	}
+	defaultCORSMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+)
+
+// corsConfig returns the CORS config, the older top level cors_ options are
+// used when the cors block sets no origins and allow credentials for all
+// of their origins as before
+func (c *Config) corsConfig() CORSConfig {
+	conf := c.CORS
+	if len(conf.AllowedOrigins) == 0 && len(c.AllowedOrigins) != 0 {
+		conf = CORSConfig{
+			AllowedOrigins:   c.AllowedOrigins,
+			AllowedHeaders:   c.AllowedHeaders,
+			AllowCredentials: true,
+			Debug:            c.DebugCORS,
+		}
+	}
+	if len(conf.AllowedHeaders) == 0 {
+		conf.AllowedHeaders = defaultCORSHeaders
+	}
+	if len(conf.AllowedMethods) == 0 {
+		conf.AllowedMethods = defaultCORSMethods
+	}
+	return conf
+}
+
+// corsHandler adds the CORS headers of the config to the responses of h,
+// origins in the credential origins are the only ones allowed credentials
+// when that list is set
+func corsHandler(conf CORSConfig, h http.Handler) http.Handler {
+	if len(conf.AllowedOrigins) == 0 {
+		return h
+	}
+
+	opts := cors.Options{
+		AllowOriginFunc: func(origin string) bool {
+			return matchOrigin(origin, conf.AllowedOrigins)
+		},
+		AllowedHeaders: conf.AllowedHeaders,
+		AllowedMethods: conf.AllowedMethods,
+		MaxAge:         int(conf.MaxAge.Seconds()),
+		Debug:          conf.Debug,
+	}
+
+	if !conf.AllowCredentials || len(conf.CredentialOrigins) == 0 {
+		opts.AllowCredentials = conf.AllowCredentials
+		return cors.New(opts).Handler(h)
+	}
+
+	withoutCreds := cors.New(opts).Handler(h)
+	opts.AllowCredentials = true
+	withCreds := cors.New(opts).Handler(h)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if matchOrigin(r.Header.Get("Origin"), conf.CredentialOrigins) {
+			withCreds.ServeHTTP(w, r)
+			return
+		}
+		withoutCreds.ServeHTTP(w, r)
+	})
+}
+
+// matchOrigin returns true if the origin matches one of the patterns, a
+// pattern is an origin, * for any origin or an origin with a wildcard like
+// https://*.example.com
+func matchOrigin(origin string, patterns []string) bool {
+	origin, ok := canonicalOrigin(origin)
+	if !ok {
+		return false
+	}
+
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "*" {
+			return true
+		}
+		if i := strings.Index(p, "*"); i != -1 {
+			prefix, suffix := p[:i], p[i+1:]
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				!strings.Contains(origin[len(prefix):len(origin)-len(suffix)], "/") {
+				return true
+			}
+			continue
+		}
+		if v, ok := canonicalOrigin(p); ok && v == origin {
+			return true
+		}
+	}
+	return false
+}
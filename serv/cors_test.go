@@ -0,0 +1,91 @@
+package serv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	patterns := []string{"https://app.example.com", "https://*.example.org"}
+
+	tests := []struct {
+		origin string
+		exp    bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com/", true},
+		{"http://app.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://evil.com/x.example.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if v := matchOrigin(tt.origin, patterns); v != tt.exp {
+			t.Errorf("%q: expected %v, got %v", tt.origin, tt.exp, v)
+		}
+	}
+
+	if !matchOrigin("https://any.com", []string{"*"}) {
+		t.Error("expected * to match any origin")
+	}
+}
+
+func TestCORSConfig(t *testing.T) {
+	conf := &Config{Serv: Serv{AllowedOrigins: []string{"https://a.com"}}}
+	cc := conf.corsConfig()
+	if !cc.AllowCredentials || len(cc.AllowedOrigins) != 1 || len(cc.AllowedMethods) == 0 {
+		t.Fatalf("expected the older cors options to be used: %+v", cc)
+	}
+
+	conf.CORS = CORSConfig{AllowedOrigins: []string{"https://b.com"}}
+	if cc := conf.corsConfig(); cc.AllowCredentials || cc.AllowedOrigins[0] != "https://b.com" {
+		t.Fatalf("expected the cors block to be used: %+v", cc)
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	h := corsHandler(CORSConfig{
+		AllowedOrigins:    []string{"https://*.example.com"},
+		AllowedHeaders:    defaultCORSHeaders,
+		AllowedMethods:    defaultCORSMethods,
+		MaxAge:            10 * time.Minute,
+		AllowCredentials:  true,
+		CredentialOrigins: []string{"https://app.example.com"},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(method, origin string) http.Header {
+		r := httptest.NewRequest(method, routeGraphQL, nil)
+		r.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Header()
+	}
+
+	hdr := send(http.MethodPost, "https://app.example.com")
+	if hdr.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		hdr.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("expected credentials for the credential origin: %v", hdr)
+	}
+
+	hdr = send(http.MethodPost, "https://docs.example.com")
+	if hdr.Get("Access-Control-Allow-Origin") != "https://docs.example.com" ||
+		hdr.Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("expected no credentials for other origins: %v", hdr)
+	}
+
+	if hdr = send(http.MethodPost, "https://evil.com"); hdr.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected the origin to be rejected: %v", hdr)
+	}
+
+	if hdr = send(http.MethodOptions, "https://docs.example.com"); hdr.Get("Access-Control-Max-Age") != "600" {
+		t.Fatalf("expected the preflight max age: %v", hdr)
+	}
+}
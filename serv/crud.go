@@ -0,0 +1,148 @@
+package serv
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/auth/v3"
+	"github.com/dosco/graphjin/core/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CRUD is the http handler for the per-table CRUD routes
+func (s *HttpService) CRUD(ah auth.HandlerFunc) http.Handler {
+	return apiV1Handler(s, nil, s.apiV1CRUD(nil), ah)
+}
+
+// CRUDWithNS is the http handler for the namespaced per-table CRUD routes
+func (s *HttpService) CRUDWithNS(ah auth.HandlerFunc, ns string) http.Handler {
+	return apiV1Handler(s, &ns, s.apiV1CRUD(&ns), ah)
+}
+
+// apiV1CRUD handles conventional CRUD requests of the form
+// /api/v1/crud/<table> and /api/v1/crud/<table>/<id>
+func (s1 *HttpService) apiV1CRUD(ns *string) http.Handler {
+	dtrace := otel.GetTextMapPropagator()
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		s := s1.Load().(*graphjinService)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		ctx, opts := newDTrace(dtrace, r)
+		ctx, span := s.spanStart(ctx, "CRUD Request", opts...)
+		defer span.End()
+
+		req, err := parseCRUDRequest(r)
+		if err != nil {
+			spanError(span, err)
+			w.WriteHeader(http.StatusBadRequest)
+			renderErr(w, err)
+			return
+		}
+
+		var rc core.RequestConfig
+
+		if s.hasRequestVars() {
+			rc.Vars = s.setHeaderVars(r)
+		}
+
+		if ns != nil {
+			rc.SetNamespace(*ns)
+		}
+
+		if err := s.checkGraphJinInitialized(); err != nil {
+			renderErr(w, err)
+			return
+		}
+
+		res, err := s.gj.CRUD(ctx, req, &rc)
+		if res == nil && err != nil {
+			spanError(span, err)
+			renderErr(w, err)
+			return
+		}
+
+		s.responseHandler(ctx, w, r, start, rc, res, err)
+
+		if span.IsRecording() {
+			span.SetAttributes(
+				attribute.String("http.path", r.RequestURI),
+				attribute.String("http.method", r.Method),
+				attribute.String("crud.table", req.Table),
+				attribute.String("crud.op", req.Op.String()))
+		}
+
+		if err != nil {
+			spanError(span, err)
+		}
+	}
+	return http.HandlerFunc(h)
+}
+
+// parseCRUDRequest maps the http method and path to a CRUD operation
+func parseCRUDRequest(r *http.Request) (req core.CRUDRequest, err error) {
+	p := strings.TrimPrefix(r.URL.Path, core.CRUDBasePath)
+	p = strings.Trim(p, "/")
+
+	if p == "" {
+		err = errors.New("no table defined")
+		return
+	}
+
+	parts := strings.SplitN(p, "/", 2)
+	req.Table = parts[0]
+	if len(parts) == 2 {
+		req.ID = parts[1]
+		if req.ID == "" || strings.Contains(req.ID, "/") {
+			err = errors.New("invalid crud route")
+			return
+		}
+	}
+
+	switch {
+	case r.Method == "GET" && req.ID == "":
+		req.Op = core.CRUDList
+		q := r.URL.Query()
+		if req.Limit, err = parseCRUDInt(q.Get("limit")); err != nil {
+			return
+		}
+		if req.Offset, err = parseCRUDInt(q.Get("offset")); err != nil {
+			return
+		}
+
+	case r.Method == "GET":
+		req.Op = core.CRUDRead
+
+	case r.Method == "POST" && req.ID == "":
+		req.Op = core.CRUDCreate
+		req.Data, err = parseBody(r)
+
+	case (r.Method == "PATCH" || r.Method == "PUT") && req.ID != "":
+		req.Op = core.CRUDUpdate
+		req.Data, err = parseBody(r)
+
+	case r.Method == "DELETE" && req.ID != "":
+		req.Op = core.CRUDDelete
+
+	default:
+		err = errors.New("method not allowed on this crud route")
+	}
+	return
+}
+
+func parseCRUDInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, errors.New("limit and offset must be positive integers")
+	}
+	return n, nil
+}
@@ -0,0 +1,138 @@
+package serv
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	core "github.com/dosco/graphjin/core/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func newCRUDTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.sqlite3"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	for _, stmt := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO users (id, name) VALUES (1, 'Ada')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+
+	logger := zap.NewNop()
+	fs := newAferoFS(afero.NewMemMapFs(), "/")
+
+	coreConf := core.Config{DBType: "sqlite", EnableCRUD: true}
+	gj, err := core.NewGraphJin(&coreConf, db,
+		core.OptionSetFS(fs),
+		core.OptionSetDatabases(map[string]*sql.DB{core.DefaultDBName: db}))
+	if err != nil {
+		t.Fatalf("create test GraphJin: %v", err)
+	}
+
+	svc := &graphjinService{
+		gj:     gj,
+		log:    logger.Sugar(),
+		zlog:   logger,
+		conf:   &Config{Core: coreConf},
+		tracer: otel.Tracer("graphjin-crud-test"),
+	}
+
+	hs := &HttpService{}
+	hs.Store(svc)
+
+	handler, err := routesHandler(hs, chi.NewRouter(), nil)
+	if err != nil {
+		t.Fatalf("routes handler: %v", err)
+	}
+	return handler
+}
+
+func TestCRUDRoutes(t *testing.T) {
+	handler := newCRUDTestHandler(t)
+
+	steps := []struct {
+		method string
+		path   string
+		body   string
+		want   string
+	}{
+		{"GET", "/api/v1/crud/users/1", "", `"name":"Ada"`},
+		{"POST", "/api/v1/crud/users", `{"id":2,"name":"Grace"}`, `"name":"Grace"`},
+		{"PATCH", "/api/v1/crud/users/2", `{"name":"Hopper"}`, `"name":"Hopper"`},
+		{"GET", "/api/v1/crud/users?limit=1", "", `"users":[{`},
+		{"DELETE", "/api/v1/crud/users/2", "", `"data":`},
+		{"GET", "/api/v1/crud/users/2", "", `"users":null`},
+		{"GET", "/api/v1/crud/users", "", `"name":"Ada"`},
+	}
+
+	for _, st := range steps {
+		req := httptest.NewRequest(st.method, st.path, strings.NewReader(st.body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: expected 200, got %d: %s", st.method, st.path, rec.Code, rec.Body.String())
+		}
+		if body := rec.Body.String(); !strings.Contains(body, st.want) || strings.Contains(body, `"errors"`) {
+			t.Fatalf("%s %s: unexpected body: %s", st.method, st.path, body)
+		}
+	}
+}
+
+func TestParseCRUDRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		op     core.CRUDOp
+		id     string
+		err    bool
+	}{
+		{"GET", "/api/v1/crud/users", core.CRUDList, "", false},
+		{"GET", "/api/v1/crud/users/7", core.CRUDRead, "7", false},
+		{"POST", "/api/v1/crud/users", core.CRUDCreate, "", false},
+		{"PATCH", "/api/v1/crud/users/7", core.CRUDUpdate, "7", false},
+		{"PUT", "/api/v1/crud/users/7", core.CRUDUpdate, "7", false},
+		{"DELETE", "/api/v1/crud/users/7", core.CRUDDelete, "7", false},
+		{"DELETE", "/api/v1/crud/users", 0, "", true},
+		{"POST", "/api/v1/crud/users/7", 0, "", true},
+		{"GET", "/api/v1/crud/", 0, "", true},
+		{"GET", "/api/v1/crud/users/7/x", 0, "", true},
+		{"GET", "/api/v1/crud/users?limit=-1", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+		req, err := parseCRUDRequest(r)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s %s: expected error", tt.method, tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.method, tt.path, err)
+			continue
+		}
+		if req.Op != tt.op || req.ID != tt.id || req.Table != "users" {
+			t.Errorf("%s %s: got %+v", tt.method, tt.path, req)
+		}
+	}
+}
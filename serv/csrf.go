@@ -0,0 +1,124 @@
+package serv
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultCSRFCookie = "_gj_csrf"
+	defaultCSRFHeader = "X-CSRF-Token"
+)
+
+var errCSRF = errors.New("csrf check failed")
+
+// csrfEnabled returns true when requests are authenticated with a cookie
+// and CSRF protection is not turned off
+func (c *Config) csrfEnabled() bool {
+	return c.Auth.CookieName() != "" && !c.CSRF.Disable
+}
+
+// csrfHandler rejects cross-site requests made with the auth cookie. A
+// request is allowed when the browser marks it same-origin with the
+// Sec-Fetch-Site header or its Origin is the service or a trusted origin.
+// Requests without either header are not from a browser and are allowed.
+// With double submit, unsafe requests must also send the value of the csrf
+// cookie in the csrf header. WebSocket upgrades have their own origin check.
+func csrfHandler(s1 *HttpService, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+		conf := s.conf.CSRF
+
+		if conf.DoubleSubmit {
+			setCSRFCookie(w, r, conf)
+		}
+
+		if r.Method == http.MethodOptions || websocket.IsWebSocketUpgrade(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if ck, err := r.Cookie(s.conf.Auth.CookieName()); err != nil || ck.Value == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if !s.csrfAllowed(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			renderErr(w, errCSRF)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// csrfAllowed returns true if the cookie authenticated request is not a
+// cross-site request forgery
+func (s *graphjinService) csrfAllowed(r *http.Request) bool {
+	conf := s.conf.CSRF
+
+	if conf.DoubleSubmit && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		ck, err := r.Cookie(csrfCookieName(conf))
+		v := r.Header.Get(csrfHeaderName(conf))
+		if err != nil || ck.Value == "" ||
+			subtle.ConstantTimeCompare([]byte(ck.Value), []byte(v)) != 1 {
+			return false
+		}
+	}
+
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") == ""
+	}
+	if v, ok := canonicalOrigin(origin); ok {
+		if expected, ok := requestOrigin(r); ok && expected == v {
+			return true
+		}
+	}
+	return matchOrigin(origin, conf.TrustedOrigins)
+}
+
+// setCSRFCookie issues the double submit token when the client has none,
+// it is readable by scripts so the app can copy it into the csrf header
+func setCSRFCookie(w http.ResponseWriter, r *http.Request, conf CSRFConfig) {
+	name := csrfCookieName(conf)
+	if ck, err := r.Cookie(name); err == nil && ck.Value != "" {
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    hex.EncodeToString(b),
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func csrfCookieName(conf CSRFConfig) string {
+	if conf.CookieName != "" {
+		return conf.CookieName
+	}
+	return defaultCSRFCookie
+}
+
+func csrfHeaderName(conf CSRFConfig) string {
+	if conf.HeaderName != "" {
+		return conf.HeaderName
+	}
+	return defaultCSRFHeader
+}
@@ -0,0 +1,122 @@
+package serv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dosco/graphjin/auth/v3"
+)
+
+func TestCSRFHandler(t *testing.T) {
+	conf := &Config{Serv: Serv{Auth: auth.Auth{Cookie: "token"}}}
+	s1 := &HttpService{}
+	s1.Store(&graphjinService{conf: conf})
+
+	h := csrfHandler(s1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(method string, cookie bool, hdrs map[string]string) int {
+		r := httptest.NewRequest(method, "http://api.example.com"+routeGraphQL, nil)
+		if cookie {
+			r.AddCookie(&http.Cookie{Name: "token", Value: "jwt"})
+		}
+		for k, v := range hdrs {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		name   string
+		cookie bool
+		hdrs   map[string]string
+		exp    int
+	}{
+		{"no cookie", false, map[string]string{"Origin": "https://evil.com"}, http.StatusOK},
+		{"not a browser", true, nil, http.StatusOK},
+		{"same origin", true, map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://api.example.com"}, http.StatusOK},
+		{"origin of the service", true, map[string]string{"Origin": "http://api.example.com"}, http.StatusOK},
+		{"cross site", true, map[string]string{"Sec-Fetch-Site": "cross-site", "Origin": "https://evil.com"}, http.StatusForbidden},
+		{"cross site without origin", true, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"other origin", true, map[string]string{"Origin": "https://app.example.com"}, http.StatusForbidden},
+		{"websocket", true, map[string]string{"Origin": "https://evil.com", "Connection": "upgrade", "Upgrade": "websocket"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := send(http.MethodPost, tt.cookie, tt.hdrs); code != tt.exp {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.exp, code)
+		}
+	}
+
+	conf.CSRF.TrustedOrigins = []string{"https://*.example.com"}
+	if code := send(http.MethodPost, true, map[string]string{"Origin": "https://app.example.com"}); code != http.StatusOK {
+		t.Errorf("expected the trusted origin to be allowed, got %d", code)
+	}
+}
+
+func TestCSRFSessionCookie(t *testing.T) {
+	conf := &Config{Serv: Serv{Auth: auth.Auth{Type: "session"}}}
+	if !conf.csrfEnabled() {
+		t.Fatal("expected csrf to be enabled for the default session cookie")
+	}
+	s1 := &HttpService{}
+	s1.Store(&graphjinService{conf: conf})
+
+	h := csrfHandler(s1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodPost, "http://api.example.com"+routeLogout, nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	r.Header.Set("Origin", "https://evil.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected a cross site logout to be rejected, got %d", w.Code)
+	}
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	conf := &Config{Serv: Serv{
+		Auth: auth.Auth{Cookie: "token"},
+		CSRF: CSRFConfig{DoubleSubmit: true},
+	}}
+	s1 := &HttpService{}
+	s1.Store(&graphjinService{conf: conf})
+
+	h := csrfHandler(s1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routeGraphQL, nil))
+	var token string
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == defaultCSRFCookie {
+			token = ck.Value
+		}
+	}
+	if token == "" {
+		t.Fatal("expected the csrf cookie to be set")
+	}
+
+	send := func(header string) int {
+		r := httptest.NewRequest(http.MethodPost, routeGraphQL, nil)
+		r.AddCookie(&http.Cookie{Name: "token", Value: "jwt"})
+		r.AddCookie(&http.Cookie{Name: defaultCSRFCookie, Value: token})
+		if header != "" {
+			r.Header.Set(defaultCSRFHeader, header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := send(token); code != http.StatusOK {
+		t.Fatalf("expected the matching token to be allowed, got %d", code)
+	}
+	if code := send(""); code != http.StatusForbidden {
+		t.Fatalf("expected a missing token to be rejected, got %d", code)
+	}
+	if code := send("other"); code != http.StatusForbidden {
+		t.Fatalf("expected a wrong token to be rejected, got %d", code)
+	}
+}
@@ -0,0 +1,46 @@
+package serv
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+const databaseHeader = "X-GraphJin-Database"
+
+// errDatabaseRouting is returned when the caller is not allowed to pick
+// the database for a request
+var errDatabaseRouting = errors.New("database routing not allowed")
+
+// setRequestDatabase directs the request at the database in the database
+// header, the database itself is validated by core
+func (s *graphjinService) setRequestDatabase(r *http.Request, rc *core.RequestConfig) error {
+	name := r.Header.Get(databaseHeader)
+	if name == "" {
+		return nil
+	}
+
+	c := r.Context()
+	role, _ := c.Value(core.UserRoleKey).(string)
+
+	if !s.canRouteDatabase(c.Value(core.UserIDKey), role) {
+		return errDatabaseRouting
+	}
+	rc.SetDatabase(name)
+	return nil
+}
+
+// canRouteDatabase returns true if the caller is allowed to pick the database
+func (s *graphjinService) canRouteDatabase(userID any, role string) bool {
+	conf := s.conf.DatabaseRouting
+	if !conf.Enable || userID == nil || role == "" {
+		return false
+	}
+
+	if len(conf.Roles) == 0 {
+		return role == "admin"
+	}
+	return slices.Contains(conf.Roles, role)
+}
@@ -0,0 +1,46 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+func TestSetRequestDatabase(t *testing.T) {
+	s := &graphjinService{
+		conf: &Config{Serv: Serv{DatabaseRouting: DatabaseRouting{Enable: true, Roles: []string{"analyst"}}}},
+	}
+
+	send := func(role, db string) (string, error) {
+		r := httptest.NewRequest("POST", "/api/v1/graphql", nil)
+		c := context.WithValue(r.Context(), core.UserIDKey, "1")
+		c = context.WithValue(c, core.UserRoleKey, role)
+		if db != "" {
+			r.Header.Set(databaseHeader, db)
+		}
+		var rc core.RequestConfig
+		err := s.setRequestDatabase(r.WithContext(c), &rc)
+		name, _ := rc.GetDatabase()
+		return name, err
+	}
+
+	if name, err := send("analyst", ""); err != nil || name != "" {
+		t.Fatalf("expected no database without the header: %q %v", name, err)
+	}
+
+	if name, err := send("analyst", "reporting"); err != nil || name != "reporting" {
+		t.Fatalf("expected the reporting database: %q %v", name, err)
+	}
+
+	if _, err := send("user", "reporting"); !errors.Is(err, errDatabaseRouting) {
+		t.Fatalf("expected database routing to be denied, got %v", err)
+	}
+
+	s.conf.DatabaseRouting.Enable = false
+	if _, err := send("analyst", "reporting"); !errors.Is(err, errDatabaseRouting) {
+		t.Fatalf("expected database routing to be disabled, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package serv
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestDeprecatedQueryHeaders(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(`CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := newAferoFS(afero.NewMemMapFs(), "/")
+	queries := map[string]string{
+		"getProductsV1": `query getProductsV1 @deprecated(sunset: "2999-01-01", replacement: "getProducts") { products { id } }`,
+		"getProductsV0": `query getProductsV0 @deprecated(sunset: "2020-01-01") { products { id } }`,
+	}
+	for name, q := range queries {
+		if err := fs.Put("/queries/"+name+".gql", []byte(q)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	logger := zap.NewNop()
+	coreConf := core.Config{DBType: "sqlite"}
+	gj, err := core.NewGraphJin(&coreConf, db,
+		core.OptionSetFS(fs),
+		core.OptionSetDatabases(map[string]*sql.DB{core.DefaultDBName: db}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &HttpService{}
+	hs.Store(&graphjinService{
+		gj:     gj,
+		log:    logger.Sugar(),
+		zlog:   logger,
+		conf:   &Config{Core: coreConf},
+		tracer: otel.Tracer("graphjin-deprecation-test"),
+	})
+
+	handler, err := routesHandler(hs, chi.NewRouter(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/rest/getProductsV1", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") != "true" ||
+		rec.Header().Get("Sunset") != "Tue, 01 Jan 2999 00:00:00 GMT" ||
+		!strings.Contains(rec.Header().Get("Warning"), "use getProducts instead") {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/rest/getProductsV0", nil))
+
+	if rec.Code != http.StatusGone || !strings.Contains(rec.Body.String(), "retired") {
+		t.Fatalf("expected 410, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,501 @@
+package serv
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	mimeCSV  = "text/csv"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+var errNotTabular = errors.New("export: result must have a single root that is a list of objects")
+
+// cellKind is the JSON type a flattened cell was decoded from
+type cellKind byte
+
+const (
+	cellNull cellKind = iota
+	cellString
+	cellNumber
+	cellBool
+	cellJSON
+)
+
+type cell struct {
+	kind cellKind
+	val  string
+}
+
+// exportTable is a single-root result read one row at a time. Nested objects
+// become dotted column names (eg. owner.email) and arrays are kept as JSON text.
+type exportTable struct {
+	root string
+	cols []string
+	data json.RawMessage
+
+	// database types of the columns of the root table, see exportColumnKey
+	types map[string]string
+}
+
+// exportFormat returns the tabular format requested via the `format` query
+// parameter or the Accept header. An empty string means JSON.
+func exportFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		switch f = strings.ToLower(f); f {
+		case "csv", "xlsx", "arrow", "parquet":
+			return f, nil
+		case "json":
+			return "", nil
+		default:
+			return "", fmt.Errorf("export: unsupported format: %s", f)
+		}
+	}
+
+	for _, v := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case mimeCSV:
+			return "csv", nil
+		case mimeXLSX:
+			return "xlsx", nil
+		case mimeArrow:
+			return "arrow", nil
+		case mimeParquet, "application/x-parquet":
+			return "parquet", nil
+		}
+	}
+	return "", nil
+}
+
+// exportHandler renders a query result as CSV, XLSX, Arrow or Parquet. It falls back to the
+// regular JSON response when the query failed.
+func (s *graphjinService) exportHandler(ct context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	start time.Time,
+	rc core.RequestConfig,
+	res *core.Result,
+	name string,
+	format string,
+	err error,
+) error {
+	if err != nil || res == nil || len(res.Errors) != 0 {
+		s.responseHandler(ct, w, r, start, rc, res, err)
+		return err
+	}
+
+	if s.hook != nil {
+		s.hook(res)
+	}
+
+	if s.conf.ServerTiming {
+		b := []byte("DB;dur=")
+		b = strconv.AppendInt(b, time.Since(start).Milliseconds(), 10)
+		w.Header().Set("Server-Timing", string(b))
+	}
+
+	if err = s.renderExport(w, res, name, format); err != nil {
+		s.zlog.Error("export", zap.String("format", format), zap.Error(err))
+	}
+
+	if res != nil {
+		s.recordQuery(res.QueryName(), time.Since(start), err != nil || len(res.Errors) != 0)
+	}
+
+	if s.logLevel >= logLevelInfo {
+		s.reqLog(ct, res, rc, time.Since(start), err)
+	}
+	return err
+}
+
+// renderExport streams the result data in the requested tabular or columnar
+// format. The result is checked before anything is written so a result that
+// cannot be exported gets an error response, errors after that can only be
+// logged since the body has started.
+func (s *graphjinService) renderExport(w http.ResponseWriter, res *core.Result, name, format string) error {
+	var (
+		ct, ext string
+		write   func(io.Writer, exportTable) error
+	)
+	switch format {
+	case "csv":
+		ct, ext, write = mimeCSV+"; charset=utf-8", ".csv", writeCSV
+	case "xlsx":
+		ct, ext, write = mimeXLSX, ".xlsx", writeXLSX
+	case "arrow":
+		ct, ext, write = mimeArrow, ".arrows", writeArrow
+	case "parquet":
+		ct, ext, write = mimeParquet, ".parquet", writeParquet
+	default:
+		err := fmt.Errorf("export: unsupported format: %s", format)
+		renderExportErr(w, err)
+		return err
+	}
+
+	t, err := newExportTable(res.Data)
+	if err != nil {
+		renderExportErr(w, err)
+		return err
+	}
+	t.types = s.exportColumnTypes(res, t.root)
+
+	if name == "" {
+		name = "export"
+	}
+	w.Header().Set("Content-Type", ct)
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": name + ext}); v != "" {
+		w.Header().Set("Content-Disposition", v)
+	}
+	return write(w, t)
+}
+
+func renderExportErr(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	renderErr(w, err)
+}
+
+// exportColumnTypes returns the database types of the columns of the root
+// table keyed by the column names in the result. Columns of nested tables
+// and aliased roots have no types
+func (s *graphjinService) exportColumnTypes(res *core.Result, root string) map[string]string {
+	if s.gj == nil {
+		return nil
+	}
+	ts, err := s.gj.GetTableSchemaForDatabase(res.Database(), root)
+	if err != nil {
+		return nil
+	}
+
+	// camel cased results use different names than the database
+	names := make(map[string]string, len(ts.Columns))
+	for _, c := range ts.Columns {
+		names[exportColumnKey(c.Name)] = c.Type
+		if c.Array {
+			names[exportColumnKey(c.Name)] = c.Type + "[]"
+		}
+	}
+	return names
+}
+
+func exportColumnKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// newExportTable reads the columns of a `{"root": [{...}, ...]}` result. A
+// singular root object is a one row table.
+func newExportTable(data json.RawMessage) (t exportTable, err error) {
+	seen := make(map[string]struct{})
+	addCol := func(key string) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			t.cols = append(t.cols, key)
+		}
+	}
+
+	t.root, err = eachExportItem(data, func(item json.RawMessage) error {
+		return flattenObject(item, "", make(map[string]cell), addCol)
+	})
+	t.data = data
+	return
+}
+
+// each calls fn with every row of the table
+func (t exportTable) each(fn func(row map[string]cell) error) error {
+	_, err := eachExportItem(t.data, func(item json.RawMessage) error {
+		row := make(map[string]cell, len(t.cols))
+		if err := flattenObject(item, "", row, nil); err != nil {
+			return err
+		}
+		return fn(row)
+	})
+	return err
+}
+
+// eachExportItem decodes the objects of the single root of the result one
+// at a time and returns the name of the root
+func eachExportItem(data json.RawMessage, fn func(item json.RawMessage) error) (root string, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil {
+		return "", err
+	} else if tok != json.Delim('{') || !dec.More() {
+		return "", errNotTabular
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	root = tok.(string)
+
+	// the shape of the root value decides how it is read
+	v := bytes.TrimLeft(data[dec.InputOffset():], " \t\r\n:")
+	switch {
+	case len(v) == 0:
+		return "", errNotTabular
+
+	case v[0] == '[':
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+		for dec.More() {
+			var item json.RawMessage
+			if err = dec.Decode(&item); err != nil {
+				return
+			}
+			if err = fn(item); err != nil {
+				return
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+
+	case v[0] == '{':
+		var item json.RawMessage
+		if err = dec.Decode(&item); err != nil {
+			return
+		}
+		if err = fn(item); err != nil {
+			return
+		}
+
+	case bytes.HasPrefix(v, []byte("null")):
+		var item json.RawMessage
+		if err = dec.Decode(&item); err != nil {
+			return
+		}
+
+	default:
+		return "", errNotTabular
+	}
+
+	if dec.More() {
+		return "", errNotTabular
+	}
+	return
+}
+
+// flattenObject walks a JSON object keeping the key order of the source so
+// the column order matches the order of fields in the query
+func flattenObject(v json.RawMessage, prefix string,
+	row map[string]cell, addCol func(string),
+) error {
+	dec := json.NewDecoder(bytes.NewReader(v))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errNotTabular
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		raw = bytes.TrimSpace(raw)
+
+		if len(raw) != 0 && raw[0] == '{' {
+			if err := flattenObject(raw, key+".", row, addCol); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if addCol != nil {
+			addCol(key)
+		}
+		row[key] = newCell(raw)
+	}
+	return nil
+}
+
+func newCell(raw json.RawMessage) cell {
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return cell{kind: cellNull}
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return cell{kind: cellJSON, val: string(raw)}
+		}
+		return cell{kind: cellString, val: s}
+	case raw[0] == 't' || raw[0] == 'f':
+		return cell{kind: cellBool, val: string(raw)}
+	case raw[0] == '[':
+		return cell{kind: cellJSON, val: string(raw)}
+	default:
+		return cell{kind: cellNumber, val: string(raw)}
+	}
+}
+
+// csvValue escapes string cells that spreadsheet applications would
+// otherwise evaluate as formulas
+func csvValue(c cell) string {
+	if c.kind == cellString && c.val != "" {
+		switch c.val[0] {
+		case '=', '+', '-', '@', '\t', '\r':
+			return "'" + c.val
+		}
+	}
+	return c.val
+}
+
+func writeCSV(w io.Writer, t exportTable) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.cols); err != nil {
+		return err
+	}
+
+	rec := make([]string, len(t.cols))
+	err := t.each(func(row map[string]cell) error {
+		for i, col := range t.cols {
+			rec[i] = csvValue(row[col])
+		}
+		return cw.Write(rec)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+)
+
+// writeXLSX writes a minimal single sheet workbook. Strings are written
+// inline so rows can be streamed without building a shared string table.
+func writeXLSX(w io.Writer, t exportTable) error {
+	zw := zip.NewWriter(w)
+
+	for _, f := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make(map[string]cell, len(t.cols))
+	for _, col := range t.cols {
+		header[col] = cell{kind: cellString, val: col}
+	}
+	writeXLSXRow(&b, 1, t.cols, header)
+
+	n := 1
+	err = t.each(func(row map[string]cell) error {
+		n++
+		writeXLSXRow(&b, n, t.cols, row)
+		if b.Len() > 32*1024 {
+			if _, err := fw.Write(b.Bytes()); err != nil {
+				return err
+			}
+			b.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	if _, err := fw.Write(b.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeXLSXRow(b *bytes.Buffer, n int, cols []string, row map[string]cell) {
+	rn := strconv.Itoa(n)
+	b.WriteString(`<row r="` + rn + `">`)
+
+	for i, col := range cols {
+		c := row[col]
+		ref := xlsxColumn(i) + rn
+
+		switch c.kind {
+		case cellNull:
+			continue
+		case cellNumber:
+			b.WriteString(`<c r="` + ref + `"><v>` + c.val + `</v></c>`)
+		case cellBool:
+			v := "0"
+			if c.val == "true" {
+				v = "1"
+			}
+			b.WriteString(`<c r="` + ref + `" t="b"><v>` + v + `</v></c>`)
+		default:
+			b.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(b, []byte(c.val)) //nolint:errcheck
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+}
+
+// xlsxColumn converts a zero based column index to a spreadsheet
+// column name (0 => A, 26 => AA)
+func xlsxColumn(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
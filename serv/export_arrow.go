@@ -0,0 +1,268 @@
+package serv
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	pqcompress "github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+const (
+	mimeArrow   = "application/vnd.apache.arrow.stream"
+	mimeParquet = "application/vnd.apache.parquet"
+)
+
+// exportBatchRows is the number of rows in each record written to an Arrow
+// stream or Parquet row group
+const exportBatchRows = 64 * 1024
+
+// arrowSchema returns the schema of the table. Columns take the type of their
+// database column when every cell converts to it, otherwise the type is
+// inferred from the cells: all-integer columns become int64, other numeric
+// columns float64, booleans stay booleans and everything else (including
+// mixed columns) is utf8.
+func arrowSchema(t exportTable) (*arrow.Schema, error) {
+	type colInfo struct {
+		db    arrow.DataType
+		kind  cellKind
+		mixed bool
+		isInt bool
+	}
+
+	info := make([]colInfo, len(t.cols))
+	for i, col := range t.cols {
+		info[i] = colInfo{db: arrowDBType(t.types[exportColumnKey(col)]), isInt: true}
+	}
+
+	err := t.each(func(row map[string]cell) error {
+		for i, col := range t.cols {
+			c, ci := row[col], &info[i]
+			if c.kind == cellNull {
+				continue
+			}
+			if ci.db != nil && !arrowCellFits(c, ci.db) {
+				ci.db = nil
+			}
+			if ci.kind != cellNull && ci.kind != c.kind {
+				ci.mixed = true
+			}
+			ci.kind = c.kind
+			if ci.kind == cellNumber && ci.isInt {
+				_, err := strconv.ParseInt(c.val, 10, 64)
+				ci.isInt = err == nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]arrow.Field, len(t.cols))
+	for i, col := range t.cols {
+		var dt arrow.DataType
+		switch ci := info[i]; {
+		case ci.db != nil:
+			dt = ci.db
+		case ci.mixed:
+			dt = arrow.BinaryTypes.String
+		case ci.kind == cellNumber && ci.isInt:
+			dt = arrow.PrimitiveTypes.Int64
+		case ci.kind == cellNumber:
+			dt = arrow.PrimitiveTypes.Float64
+		case ci.kind == cellBool:
+			dt = arrow.FixedWidthTypes.Boolean
+		default:
+			dt = arrow.BinaryTypes.String
+		}
+		fields[i] = arrow.Field{Name: col, Type: dt, Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// eachArrowRecord converts the rows of the table into records of up to
+// exportBatchRows rows, an empty table is a single empty record
+func eachArrowRecord(t exportTable, schema *arrow.Schema, mem memory.Allocator,
+	fn func(arrow.Record) error,
+) error {
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	var n, written int
+	flush := func() error {
+		rec := b.NewRecord()
+		defer rec.Release()
+		written += n
+		n = 0
+		return fn(rec)
+	}
+
+	err := t.each(func(row map[string]cell) error {
+		for i, col := range t.cols {
+			appendArrowCell(b.Field(i), row[col])
+		}
+		if n++; n == exportBatchRows {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if n != 0 || written == 0 {
+		return flush()
+	}
+	return nil
+}
+
+func appendArrowCell(fb array.Builder, c cell) {
+	if c.kind == cellNull {
+		fb.AppendNull()
+		return
+	}
+	switch fb := fb.(type) {
+	case *array.Int64Builder:
+		n, _ := strconv.ParseInt(c.val, 10, 64)
+		fb.Append(n)
+	case *array.Float64Builder:
+		n, _ := strconv.ParseFloat(c.val, 64)
+		fb.Append(n)
+	case *array.BooleanBuilder:
+		fb.Append(c.val == "true")
+	case *array.Date32Builder:
+		v, _ := parseExportTime(c.val)
+		fb.Append(arrow.Date32FromTime(v))
+	case *array.TimestampBuilder:
+		v, _ := parseExportTime(c.val)
+		fb.Append(arrow.Timestamp(v.UnixMicro()))
+	case *array.StringBuilder:
+		fb.Append(c.val)
+	}
+}
+
+// arrowDBType returns the arrow type of a database column type, or nil when
+// the type has no better arrow type than the one inferred from the cells
+func arrowDBType(typ string) arrow.DataType {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if typ == "" || strings.HasSuffix(typ, "[]") || strings.HasPrefix(typ, "_") {
+		return nil
+	}
+	if i := strings.IndexByte(typ, '('); i != -1 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+
+	switch typ {
+	case "smallint", "int2", "integer", "int", "int4", "bigint", "int8",
+		"smallserial", "serial", "bigserial", "tinyint", "mediumint":
+		return arrow.PrimitiveTypes.Int64
+	case "real", "float4", "double precision", "float8", "double", "float",
+		"numeric", "decimal", "number", "money":
+		return arrow.PrimitiveTypes.Float64
+	case "boolean", "bool":
+		return arrow.FixedWidthTypes.Boolean
+	case "date":
+		return arrow.FixedWidthTypes.Date32
+	case "timestamp", "timestamp without time zone", "datetime", "datetime2",
+		"smalldatetime":
+		return &arrow.TimestampType{Unit: arrow.Microsecond}
+	case "timestamptz", "timestamp with time zone", "datetimeoffset":
+		return arrow.FixedWidthTypes.Timestamp_us
+	case "text", "varchar", "character varying", "char", "character", "nvarchar",
+		"nchar", "ntext", "uuid", "citext", "varchar2", "nvarchar2", "clob":
+		return arrow.BinaryTypes.String
+	}
+	return nil
+}
+
+// arrowCellFits returns true when the cell converts to the type. Numbers
+// sent as strings (eg. big integers and decimals) convert to numeric types
+func arrowCellFits(c cell, dt arrow.DataType) bool {
+	var err error
+	switch dt.ID() {
+	case arrow.INT64:
+		if c.kind != cellNumber && c.kind != cellString {
+			return false
+		}
+		_, err = strconv.ParseInt(c.val, 10, 64)
+	case arrow.FLOAT64:
+		if c.kind != cellNumber && c.kind != cellString {
+			return false
+		}
+		_, err = strconv.ParseFloat(c.val, 64)
+	case arrow.BOOL:
+		return c.kind == cellBool
+	case arrow.DATE32, arrow.TIMESTAMP:
+		if c.kind != cellString {
+			return false
+		}
+		_, err = parseExportTime(c.val)
+	}
+	return err == nil
+}
+
+// exportTimeLayouts are the date and time formats databases return in
+// their JSON results
+var exportTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseExportTime(v string) (t time.Time, err error) {
+	for _, l := range exportTimeLayouts {
+		if t, err = time.Parse(l, v); err == nil {
+			return
+		}
+	}
+	return
+}
+
+// writeArrow writes the result as an Arrow IPC stream
+func writeArrow(w io.Writer, t exportTable) error {
+	mem := memory.NewGoAllocator()
+	schema, err := arrowSchema(t)
+	if err != nil {
+		return err
+	}
+
+	iw := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	if err := eachArrowRecord(t, schema, mem, iw.Write); err != nil {
+		iw.Close() //nolint:errcheck
+		return err
+	}
+	return iw.Close()
+}
+
+// writeParquet writes the result as a snappy compressed Parquet file with a
+// row group for every exportBatchRows rows
+func writeParquet(w io.Writer, t exportTable) error {
+	mem := memory.NewGoAllocator()
+	schema, err := arrowSchema(t)
+	if err != nil {
+		return err
+	}
+
+	props := parquet.NewWriterProperties(
+		parquet.WithAllocator(mem),
+		parquet.WithCompression(pqcompress.Codecs.Snappy))
+
+	fw, err := pqarrow.NewFileWriter(schema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+	if err := eachArrowRecord(t, schema, mem, fw.Write); err != nil {
+		fw.Close() //nolint:errcheck
+		return err
+	}
+	return fw.Close()
+}
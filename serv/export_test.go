@@ -0,0 +1,368 @@
+package serv
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	core "github.com/dosco/graphjin/core/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestExportTableCSV(t *testing.T) {
+	data := json.RawMessage(`{"users":[
+		{"id":1,"name":"Ada, \"the\" first","owner":{"email":"a@b.c"},"tags":["x","y"]},
+		{"id":2,"name":"=cmd()","owner":null,"admin":true}
+	]}`)
+
+	tb, err := newExportTable(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := writeCSV(&b, tb); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "id,name,owner.email,tags,owner,admin\n" +
+		"1,\"Ada, \"\"the\"\" first\",a@b.c,\"[\"\"x\"\",\"\"y\"\"]\",,\n" +
+		"2,'=cmd(),,,,true\n"
+
+	if b.String() != exp {
+		t.Fatalf("unexpected csv:\n%s\nwant:\n%s", b.String(), exp)
+	}
+}
+
+func TestExportTableRequiresSingleRoot(t *testing.T) {
+	if _, err := newExportTable(json.RawMessage(`{"a":[],"b":[]}`)); err != errNotTabular {
+		t.Fatalf("expected errNotTabular, got %v", err)
+	}
+	if _, err := newExportTable(json.RawMessage(`{"a":1}`)); err != errNotTabular {
+		t.Fatalf("expected errNotTabular, got %v", err)
+	}
+	tb, err := newExportTable(json.RawMessage(`{"user":{"id":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows int
+	if err := tb.each(func(map[string]cell) error { rows++; return nil }); err != nil || rows != 1 {
+		t.Fatalf("expected singular root to become one row, got %d %v", rows, err)
+	}
+}
+
+func TestRenderExport(t *testing.T) {
+	s := &graphjinService{}
+
+	// a result that is not a table gets an error before anything is written
+	rec := httptest.NewRecorder()
+	res := &core.Result{Data: json.RawMessage(`{"a":[{"id":1}],"b":[]}`)}
+	if err := s.renderExport(rec, res, "list", "csv"); err != errNotTabular {
+		t.Fatalf("expected errNotTabular, got %v", err)
+	}
+	if rec.Code != 400 || rec.Header().Get("Content-Disposition") != "" ||
+		!strings.Contains(rec.Body.String(), errNotTabular.Error()) {
+		t.Fatalf("unexpected response %d %v: %s", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	res = &core.Result{Data: json.RawMessage(`{"users":[{"id":1}]}`)}
+	if err := s.renderExport(rec, res, `my "users"`, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="my \"users\".csv"` {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+	if rec.Code != 200 || rec.Body.String() != "id\n1\n" {
+		t.Fatalf("unexpected response %d: %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestExportFormat(t *testing.T) {
+	tests := []struct {
+		url, accept, want string
+		err               bool
+	}{
+		{"/x", "", "", false},
+		{"/x?format=csv", "", "csv", false},
+		{"/x?format=XLSX", "", "xlsx", false},
+		{"/x?format=json", "text/csv", "", false},
+		{"/x?format=pdf", "", "", true},
+		{"/x", "text/csv;q=0.9, application/json", "csv", false},
+		{"/x", mimeXLSX, "xlsx", false},
+		{"/x?format=parquet", "", "parquet", false},
+		{"/x", mimeArrow, "arrow", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		got, err := exportFormat(r)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%s (%s): got %q, %v", tt.url, tt.accept, got, err)
+		}
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestRESTExport(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.sqlite3"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	for _, stmt := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`,
+		`INSERT INTO users (id, name) VALUES (1, 'Ada'), (2, 'Grace')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+
+	logger := zap.NewNop()
+	fs := newAferoFS(afero.NewMemMapFs(), "/")
+	if err := fs.Put("/queries/listUsers.gql",
+		[]byte(`query listUsers { users(order_by: { id: asc }) { id name } }`)); err != nil {
+		t.Fatalf("write query file: %v", err)
+	}
+
+	coreConf := core.Config{DBType: "sqlite"}
+	gj, err := core.NewGraphJin(&coreConf, db,
+		core.OptionSetFS(fs),
+		core.OptionSetDatabases(map[string]*sql.DB{core.DefaultDBName: db}))
+	if err != nil {
+		t.Fatalf("create test GraphJin: %v", err)
+	}
+
+	hs := &HttpService{}
+	hs.Store(&graphjinService{
+		gj:     gj,
+		log:    logger.Sugar(),
+		zlog:   logger,
+		conf:   &Config{Core: coreConf},
+		tracer: otel.Tracer("graphjin-export-test"),
+	})
+
+	handler, err := routesHandler(hs, chi.NewRouter(), nil)
+	if err != nil {
+		t.Fatalf("routes handler: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/rest/listUsers?format=csv", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, mimeCSV) {
+		t.Fatalf("unexpected content type %q: %s", ct, rec.Body.String())
+	}
+	if got := rec.Body.String(); got != "id,name\n1,Ada\n2,Grace\n" {
+		t.Fatalf("unexpected csv body: %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != "attachment; filename=listUsers.csv" {
+		t.Fatalf("unexpected content disposition %q", got)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/rest/listUsers", nil)
+	req.Header.Set("Accept", mimeXLSX)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body := rec.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("xlsx is not a zip archive: %v", err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		sheet = string(b)
+	}
+	if !strings.Contains(sheet, `<c r="B3" t="inlineStr"><is><t xml:space="preserve">Grace</t></is></c>`) {
+		t.Fatalf("unexpected sheet: %s", sheet)
+	}
+	if rec.Header().Get("Content-Type") != mimeXLSX {
+		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestArrowAndParquetExport(t *testing.T) {
+	tb, err := newExportTable(json.RawMessage(`{"products":[
+		{"id":1,"price":9.5,"name":"a","active":true},
+		{"id":2,"price":10,"name":null,"active":false}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := writeArrow(&b, tb); err != nil {
+		t.Fatal(err)
+	}
+
+	rdr, err := ipc.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Release()
+
+	want := "schema:\n  fields: 4\n" +
+		"    - id: type=int64, nullable\n" +
+		"    - price: type=float64, nullable\n" +
+		"    - name: type=utf8, nullable\n" +
+		"    - active: type=bool, nullable"
+	if got := rdr.Schema().String(); got != want {
+		t.Fatalf("unexpected arrow schema:\n%s", got)
+	}
+	if !rdr.Next() || rdr.Record().NumRows() != 2 {
+		t.Fatal("expected a record with 2 rows")
+	}
+
+	b.Reset()
+	if err := writeParquet(&b, tb); err != nil {
+		t.Fatal(err)
+	}
+
+	pf, err := file.NewParquetReader(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+
+	if pf.NumRows() != 2 || pf.MetaData().Schema.NumColumns() != 4 {
+		t.Fatalf("unexpected parquet file: %d rows, %d columns",
+			pf.NumRows(), pf.MetaData().Schema.NumColumns())
+	}
+}
+
+func TestArrowTypedColumns(t *testing.T) {
+	tb, err := newExportTable(json.RawMessage(`{"orders":[
+		{"id":"9007199254740993","total":"9.50","createdAt":"2024-05-01T10:30:00.5+00:00","day":"2024-05-01","note":"a","owner":{"id":1}},
+		{"id":"2","total":"10","createdAt":null,"day":"2024-05-02","note":"b","owner":{"id":2}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.root != "orders" {
+		t.Fatalf("unexpected root %q", tb.root)
+	}
+	tb.types = map[string]string{
+		exportColumnKey("id"):         "bigint",
+		exportColumnKey("total"):      "numeric(10,2)",
+		exportColumnKey("created_at"): "timestamp with time zone",
+		exportColumnKey("day"):        "date",
+		exportColumnKey("note"):       "integer",
+	}
+
+	schema, err := arrowSchema(tb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec arrow.Record
+	err = eachArrowRecord(tb, schema, memory.NewGoAllocator(), func(r arrow.Record) error {
+		r.Retain()
+		rec = r
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Release()
+
+	// the note column does not convert to its type and is inferred instead
+	want := "schema:\n  fields: 6\n" +
+		"    - id: type=int64, nullable\n" +
+		"    - total: type=float64, nullable\n" +
+		"    - createdAt: type=timestamp[us, tz=UTC], nullable\n" +
+		"    - day: type=date32, nullable\n" +
+		"    - note: type=utf8, nullable\n" +
+		"    - owner.id: type=int64, nullable"
+	if got := rec.Schema().String(); got != want {
+		t.Fatalf("unexpected arrow schema:\n%s", got)
+	}
+
+	ids := rec.Column(0).(*array.Int64)
+	if ids.Value(0) != 9007199254740993 {
+		t.Fatalf("unexpected id %d", ids.Value(0))
+	}
+	ts := rec.Column(2).(*array.Timestamp)
+	if got := ts.Value(0).ToTime(arrow.Microsecond); !got.Equal(time.Date(2024, 5, 1, 10, 30, 0, 5e8, time.UTC)) {
+		t.Fatalf("unexpected timestamp %s", got)
+	}
+	if !ts.IsNull(1) {
+		t.Fatal("expected a null timestamp")
+	}
+}
+
+func TestArrowExportBatches(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`{"items":[`)
+	for i := 0; i <= exportBatchRows; i++ {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"id":%d}`, i)
+	}
+	b.WriteString(`]}`)
+
+	tb, err := newExportTable(json.RawMessage(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := writeArrow(&out, tb); err != nil {
+		t.Fatal(err)
+	}
+	rdr, err := ipc.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Release()
+
+	var rows []int64
+	for rdr.Next() {
+		rows = append(rows, rdr.Record().NumRows())
+	}
+	if len(rows) != 2 || rows[0] != exportBatchRows || rows[1] != 1 {
+		t.Fatalf("unexpected record sizes %v", rows)
+	}
+}
@@ -96,8 +96,9 @@ func startConfigWatcher(s1 *HttpService) error {
 				continue
 			}
 
-			// Check if new config works fine
-			if _, err := NewGraphJinService(conf, s1.opt...); err != nil {
+			// Check if new config works fine, the check must not start its
+			// own watchers or change capture
+			if _, err := newGraphJinService(conf, nil, s1.opt...); err != nil {
 				s.log.Error(err)
 				continue
 			}
@@ -0,0 +1,149 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFlagsRefresh = 30 * time.Second
+
+	// flagVarPrefix is the prefix of the variables holding the flags
+	flagVarPrefix = "ff_"
+)
+
+// flagTableRe matches the flags table, optionally with its schema
+var flagTableRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// featureFlags holds the flag values from the config and the flags table
+type featureFlags struct {
+	conf FeatureFlagsConfig
+
+	mu    sync.RWMutex
+	table map[string]bool
+}
+
+// validateFeatureFlags checks the flags table name
+func validateFeatureFlags(conf FeatureFlagsConfig) error {
+	if conf.Table != "" && !flagTableRe.MatchString(conf.Table) {
+		return fmt.Errorf("feature_flags: invalid table: %s", conf.Table)
+	}
+	return nil
+}
+
+// newFeatureFlags returns nil when no flags or flags table are configured
+func newFeatureFlags(conf FeatureFlagsConfig) *featureFlags {
+	if len(conf.Flags) == 0 && conf.Table == "" {
+		return nil
+	}
+	if conf.RefreshInterval <= 0 {
+		conf.RefreshInterval = defaultFlagsRefresh
+	}
+	return &featureFlags{conf: conf}
+}
+
+// values returns the current flags, flags from the table override the
+// ones set in the config
+func (ff *featureFlags) values() map[string]bool {
+	if ff == nil {
+		return nil
+	}
+	vals := maps.Clone(ff.conf.Flags)
+	if vals == nil {
+		vals = make(map[string]bool)
+	}
+
+	ff.mu.RLock()
+	maps.Copy(vals, ff.table)
+	ff.mu.RUnlock()
+	return vals
+}
+
+// setTable replaces the flags read from the table
+func (ff *featureFlags) setTable(vals map[string]bool) {
+	if ff == nil {
+		return
+	}
+	ff.mu.Lock()
+	ff.table = vals
+	ff.mu.Unlock()
+}
+
+// load reads the flags from the table
+func (ff *featureFlags) load(c context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(c, `SELECT name, enabled FROM `+ff.conf.Table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	vals := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var enabled sql.NullBool
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return err
+		}
+		vals[name] = enabled.Bool
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ff.setTable(vals)
+	return nil
+}
+
+// setFlagVars adds the flags to the request variables, they are set by the
+// server so a request cannot change them
+func (s *graphjinService) setFlagVars(vars map[string]interface{}) {
+	for k, v := range s.flags.values() {
+		vars[flagVarPrefix+k] = v
+	}
+}
+
+// flagsDB returns the database the flags table is in
+func (s *graphjinService) flagsDB() *sql.DB {
+	if name := s.conf.FeatureFlags.Database; name != "" {
+		return s.dbs[name]
+	}
+	return s.anyDB()
+}
+
+// initFeatureFlags reads the flags table at startup and then on every
+// refresh. The current service is loaded for every refresh so config
+// reloads are picked up.
+func initFeatureFlags(s1 *HttpService) {
+	s := s1.Load().(*graphjinService)
+	if s.flags == nil || s.conf.FeatureFlags.Table == "" {
+		return
+	}
+
+	refresh := func(s *graphjinService) {
+		db := s.flagsDB()
+		if s.flags == nil || s.conf.FeatureFlags.Table == "" || db == nil {
+			return
+		}
+		c, cancel := context.WithTimeout(context.Background(), s.flags.conf.RefreshInterval)
+		defer cancel()
+
+		if err := s.flags.load(c, db); err != nil {
+			s.log.Warnf("feature_flags: %s", err)
+		}
+	}
+	refresh(s)
+
+	go func() {
+		ticker := time.NewTicker(s.flags.conf.RefreshInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			refresh(s1.Load().(*graphjinService))
+		}
+	}()
+}
@@ -0,0 +1,64 @@
+package serv
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestFeatureFlagValues(t *testing.T) {
+	if ff := newFeatureFlags(FeatureFlagsConfig{}); ff != nil {
+		t.Fatal("expected no feature flags without flags or a table")
+	}
+
+	s := &graphjinService{conf: &Config{}}
+	s.flags = newFeatureFlags(FeatureFlagsConfig{
+		Flags: map[string]bool{"new_pricing": false, "beta": true},
+	})
+	s.flags.setTable(map[string]bool{"new_pricing": true})
+
+	vars := map[string]interface{}{"ff_beta": false}
+	s.setFlagVars(vars)
+
+	if vars["ff_new_pricing"] != true {
+		t.Fatalf("expected the table to override the config, got %v", vars["ff_new_pricing"])
+	}
+	if vars["ff_beta"] != true {
+		t.Fatalf("expected the server to set ff_beta, got %v", vars["ff_beta"])
+	}
+}
+
+func TestFeatureFlagsLoad(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE flags (name TEXT PRIMARY KEY, enabled BOOLEAN);
+		INSERT INTO flags (name, enabled) VALUES ('new_pricing', 1), ('checkout_v2', 0);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ff := newFeatureFlags(FeatureFlagsConfig{Table: "flags"})
+	if err := ff.load(t.Context(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	vals := ff.values()
+	if !vals["new_pricing"] || vals["checkout_v2"] || len(vals) != 2 {
+		t.Fatalf("unexpected flags %v", vals)
+	}
+}
+
+func TestValidateFeatureFlags(t *testing.T) {
+	for _, table := range []string{"flags", "config.flags"} {
+		if err := validateFeatureFlags(FeatureFlagsConfig{Table: table}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := validateFeatureFlags(FeatureFlagsConfig{Table: "flags; DROP TABLE users"}); err == nil {
+		t.Fatal("expected an invalid table to fail")
+	}
+}
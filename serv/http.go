@@ -17,7 +17,6 @@ import (
 	"github.com/dosco/graphjin/serv/v3/internal/etags"
 	"github.com/gorilla/websocket"
 	"github.com/klauspost/compress/gzhttp"
-	"github.com/rs/cors"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -26,7 +25,6 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 const (
@@ -65,6 +63,10 @@ func apiV1Handler(s1 *HttpService, ns *string, h http.Handler, ah auth.HandlerFu
 	}
 
 	if ah != nil {
+		if s.conf.Impersonation.Enable {
+			h = impersonateHandler(s1, h)
+		}
+
 		authOpt := auth.Options{AuthFailBlock: s.conf.AuthFailBlock}
 		useAuth, err := auth.NewAuth(s.conf.Auth, zlog, authOpt, ah)
 		if err != nil {
@@ -73,24 +75,16 @@ func apiV1Handler(s1 *HttpService, ns *string, h http.Handler, ah auth.HandlerFu
 		h = useAuth(h)
 	}
 
-	if len(s.conf.AllowedOrigins) != 0 {
-		allowedHeaders := []string{
-			"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization",
-		}
-
-		if len(s.conf.AllowedHeaders) != 0 {
-			allowedHeaders = s.conf.AllowedHeaders
-		}
+	h = maintenanceHandler(s1, h)
 
-		c := cors.New(cors.Options{
-			AllowedOrigins:   s.conf.AllowedOrigins,
-			AllowedHeaders:   allowedHeaders,
-			AllowCredentials: true,
-			Debug:            s.conf.DebugCORS,
-		})
-		h = c.Handler(h)
+	if s.conf.csrfEnabled() {
+		h = csrfHandler(s1, h)
 	}
 
+	h = corsHandler(s.conf.corsConfig(), h)
+
+	h = requestIDHandler(h)
+
 	h = etags.Handler(h, false)
 
 	if s.conf.rateLimiterEnable() {
@@ -126,6 +120,7 @@ func (s1 *HttpService) apiV1GraphQL(ns *string, ah auth.HandlerFunc) http.Handle
 		}
 
 		var req gqlReq
+		var body []byte
 
 		ctx, opts := newDTrace(dtrace, r)
 		ctx, span := s.spanStart(ctx, "GraphQL Request", opts...)
@@ -133,11 +128,10 @@ func (s1 *HttpService) apiV1GraphQL(ns *string, ah auth.HandlerFunc) http.Handle
 
 		switch r.Method {
 		case "POST":
-			var b []byte
-			b, err = io.ReadAll(io.LimitReader(r.Body, maxReadBytes))
+			body, err = io.ReadAll(io.LimitReader(r.Body, maxReadBytes))
 			if err == nil {
 				defer r.Body.Close() //nolint:errcheck
-				err = json.Unmarshal(b, &req)
+				err = json.Unmarshal(body, &req)
 			}
 
 		case "GET":
@@ -163,7 +157,7 @@ func (s1 *HttpService) apiV1GraphQL(ns *string, ah auth.HandlerFunc) http.Handle
 			rc.APQKey = (req.OpName + req.Ext.Persisted.Sha256Hash)
 		}
 
-		if rc.Vars == nil && len(s.conf.HeaderVars) != 0 {
+		if rc.Vars == nil && s.hasRequestVars() {
 			rc.Vars = s.setHeaderVars(r)
 		}
 
@@ -171,6 +165,13 @@ func (s1 *HttpService) apiV1GraphQL(ns *string, ah auth.HandlerFunc) http.Handle
 			rc.SetNamespace(*ns)
 		}
 
+		if err := s.setRequestDatabase(r, &rc); err != nil {
+			spanError(span, err)
+			w.WriteHeader(http.StatusForbidden)
+			renderErr(w, err)
+			return
+		}
+
 		if req.OpName == "subscription" {
 			err := errors.New("use websockets for subscriptions")
 			spanError(span, err)
@@ -183,20 +184,39 @@ func (s1 *HttpService) apiV1GraphQL(ns *string, ah auth.HandlerFunc) http.Handle
 			return
 		}
 
+		idem, handled := s.startIdempotent(ctx, w, r, body)
+		if handled {
+			return
+		}
+
+		release, err := s.acquireRequest(ctx, req.OpName)
+		if err != nil {
+			idem.abort(ctx)
+			spanError(span, err)
+			renderBusy(w, err)
+			return
+		}
 		res, err := s.gj.GraphQL(ctx, req.Query, req.Vars, &rc)
+		release()
 		if res == nil && err != nil {
+			idem.abort(ctx)
 			renderErr(w, err)
 			return
 		}
 
+		s.shadowQuery(ctx, rc, res, err, func(c context.Context, rc *core.RequestConfig) (*core.Result, error) {
+			return s.gj.GraphQL(c, req.Query, req.Vars, rc)
+		})
+
 		s.responseHandler(
 			ctx,
-			w,
+			idem.writer(w),
 			r,
 			start,
 			rc,
 			res,
 			err)
+		idem.finish(ctx, res, err)
 
 		if span.IsRecording() {
 			span.SetAttributes(
@@ -257,6 +277,11 @@ func (s1 *HttpService) apiV1Rest(ns *string, ah auth.HandlerFunc) http.Handler {
 			vars = json.RawMessage(r.URL.Query().Get("variables"))
 		}
 
+		var format string
+		if err == nil {
+			format, err = exportFormat(r)
+		}
+
 		if err != nil {
 			spanError(span, err)
 			renderErr(w, err)
@@ -265,7 +290,7 @@ func (s1 *HttpService) apiV1Rest(ns *string, ah auth.HandlerFunc) http.Handler {
 
 		var rc core.RequestConfig
 
-		if rc.Vars == nil && len(s.conf.HeaderVars) != 0 {
+		if rc.Vars == nil && s.hasRequestVars() {
 			rc.Vars = s.setHeaderVars(r)
 		}
 
@@ -273,20 +298,50 @@ func (s1 *HttpService) apiV1Rest(ns *string, ah auth.HandlerFunc) http.Handler {
 			rc.SetNamespace(*ns)
 		}
 
+		if err := s.setRequestDatabase(r, &rc); err != nil {
+			spanError(span, err)
+			w.WriteHeader(http.StatusForbidden)
+			renderErr(w, err)
+			return
+		}
+
 		if err := s.checkGraphJinInitialized(); err != nil {
 			renderErr(w, err)
 			return
 		}
 
+		idem, handled := s.startIdempotent(ctx, w, r, vars)
+		if handled {
+			return
+		}
+
+		release, err := s.acquireRequest(ctx, queryName)
+		if err != nil {
+			idem.abort(ctx)
+			spanError(span, err)
+			renderBusy(w, err)
+			return
+		}
 		res, err := s.gj.GraphQLByName(ctx, queryName, vars, &rc)
-		s.responseHandler(
-			ctx,
-			w,
-			r,
-			start,
-			rc,
-			res,
-			err)
+		release()
+
+		s.shadowQuery(ctx, rc, res, err, func(c context.Context, rc *core.RequestConfig) (*core.Result, error) {
+			return s.gj.GraphQLByName(c, queryName, vars, rc)
+		})
+		if format != "" {
+			idem.abort(ctx)
+			err = s.exportHandler(ctx, w, r, start, rc, res, queryName, format, err)
+		} else {
+			s.responseHandler(
+				ctx,
+				idem.writer(w),
+				r,
+				start,
+				rc,
+				res,
+				err)
+			idem.finish(ctx, res, err)
+		}
 
 		if span.IsRecording() {
 			span.SetAttributes(
@@ -314,6 +369,19 @@ func (s *graphjinService) responseHandler(ct context.Context,
 		s.hook(res)
 	}
 
+	if res != nil && res.Deprecation() != nil {
+		setDeprecationHeaders(w, res)
+	}
+
+	if errors.Is(err, core.ErrQueryRetired) {
+		w.WriteHeader(http.StatusGone)
+	}
+
+	if errors.Is(err, core.ErrReadOnly) {
+		s.renderReadOnly(w)
+		return
+	}
+
 	if err == nil && r.Method == "GET" && res.Operation() == core.OpQuery {
 		switch {
 		case res.CacheControl() != "":
@@ -331,58 +399,33 @@ func (s *graphjinService) responseHandler(ct context.Context,
 		return
 	}
 
-	rt := time.Since(start).Milliseconds()
+	dur := time.Since(start)
+
+	if res != nil {
+		s.recordQuery(res.QueryName(), dur, err != nil || len(res.Errors) != 0)
+	}
 
 	if s.logLevel >= logLevelInfo {
-		s.reqLog(res, rc, rt, err)
+		s.reqLog(ct, res, rc, dur, err)
 	}
 
 	if s.conf.ServerTiming {
 		b := []byte("DB;dur=")
-		b = strconv.AppendInt(b, rt, 10)
+		b = strconv.AppendInt(b, dur.Milliseconds(), 10)
 		w.Header().Set("Server-Timing", string(b))
 	}
 }
 
-// reqLog logs the request details
-func (s *graphjinService) reqLog(res *core.Result, rc core.RequestConfig, resTimeMs int64, err error) {
-	var fields []zapcore.Field
-	var sql string
-
-	if res != nil {
-		sql = res.SQL()
-		fields = []zapcore.Field{
-			zap.String("op", res.OperationName()),
-			zap.String("name", res.QueryName()),
-			zap.String("role", res.Role()),
-			zap.Int64("responseTimeMs", resTimeMs),
-			zap.Bool("cacheHit", res.CacheHit()),
-		}
-	}
-
-	if ns, ok := rc.GetNamespace(); ok {
-		fields = append(fields, zap.String("namespace", ns))
-	}
-
-	if res != nil && res.Vars != nil && s.conf.LogVars {
-		var vars map[string]interface{}
-		err := json.Unmarshal(res.Vars, &vars)
-		if err != nil {
-			s.log.Error("failed to unmarshal sql vars", zap.Error(err))
-		}
-		fields = append(fields, zap.Any("vars", vars))
-	}
-
-	if sql != "" && s.logLevel >= logLevelDebug {
-		fields = append(fields, zap.String("sql", sql))
-	}
+// setDeprecationHeaders sets the Deprecation, Sunset (RFC 8594) and Warning
+// headers for deprecated saved queries
+func setDeprecationHeaders(w http.ResponseWriter, res *core.Result) {
+	d := res.Deprecation()
 
-	if err != nil {
-		fields = append(fields, zap.Error(err))
-		s.zlog.Error("query failed", fields...)
-	} else {
-		s.zlog.Info("query", fields...)
+	w.Header().Set("Deprecation", "true")
+	if t, ok := d.SunsetTime(); ok {
+		w.Header().Set("Sunset", t.Format(http.TimeFormat))
 	}
+	w.Header().Set("Warning", fmt.Sprintf("299 - %q", d.Message(res.QueryName())))
 }
 
 // setHeaderVars sets the header variables
@@ -396,6 +439,7 @@ func (s *graphjinService) setHeaderVars(r *http.Request) map[string]interface{}
 			return ""
 		}
 	}
+	s.setRequestVars(r, vars)
 	return vars
 }
 
@@ -454,49 +498,3 @@ func newDTrace(dtrace propagation.TextMapPropagator, r *http.Request) (context.C
 
 	return ctx, opts
 }
-
-// openAPIHandler generates and serves the OpenAPI specification
-func (s1 *HttpService) openAPIHandler(ns *string) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		s := s1.Load().(*graphjinService)
-
-		// Set content type for JSON
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Only allow GET requests
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Generate OpenAPI specification
-		spec, err := s.gj.GetOpenAPISpec()
-		if err != nil {
-			s.log.Error("Failed to generate OpenAPI spec", zap.Error(err))
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
-		}
-
-		// Add namespace info if applicable
-		if ns != nil {
-			// Note: In a real implementation, you might want to modify the spec
-			// to reflect the namespace in paths or descriptions
-			s.log.Debug("Serving OpenAPI spec for namespace", zap.String("namespace", *ns))
-		}
-
-		// Write the specification
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write(spec); err != nil {
-			s.log.Error("Failed to write OpenAPI spec", zap.Error(err))
-		}
-	})
-}
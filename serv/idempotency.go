@@ -0,0 +1,438 @@
+package serv
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	headerRequestID        = "X-Request-ID"
+	headerIdempotencyKey   = "Idempotency-Key"
+	headerIdempotentReplay = "Idempotent-Replayed"
+
+	maxRequestIDLen      = 128
+	maxIdempotencyKeyLen = 255
+
+	idempotencyPrefix       = "gj:idem:"
+	idempotencyLockTTL      = time.Minute // How long an in-flight key blocks retries
+	idempotencyRedisTimeout = 100 * time.Millisecond
+)
+
+var (
+	errIdempotencyInFlight = errors.New("a request with this idempotency key is in progress")
+	errIdempotencyMismatch = errors.New("idempotency key was used with a different request")
+)
+
+type requestIDKey struct{}
+
+// requestIDHandler propagates the X-Request-ID header. The ID sent by the
+// client is used when valid, otherwise one is generated. It's returned in the
+// response header and added to the request context for the request log
+func requestIDHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(headerRequestID)
+		if !validHeaderToken(id, maxRequestIDLen) {
+			id = newRequestID()
+		}
+		w.Header().Set(headerRequestID, id)
+
+		c := context.WithValue(r.Context(), requestIDKey{}, id)
+		h.ServeHTTP(w, r.WithContext(c))
+	})
+}
+
+// requestID returns the ID of the request in the context
+func requestID(c context.Context) string {
+	id, _ := c.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:]) //nolint:errcheck
+	return hex.EncodeToString(b[:])
+}
+
+// validHeaderToken returns true for a non-empty value of printable ASCII
+// characters no longer than max
+func validHeaderToken(v string, max int) bool {
+	if v == "" || len(v) > max {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if v[i] < 0x21 || v[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotentResponse is the stored response of an idempotency key. Pending
+// entries hold the key while the first request is running
+type idempotentResponse struct {
+	Fingerprint string `json:"fp"`
+	Pending     bool   `json:"pending,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyStore is the interface for storing the responses of mutations
+// sent with an Idempotency-Key header
+type IdempotencyStore interface {
+	// Begin claims the key with a pending entry. When the key is already
+	// claimed the existing entry is returned and the key is left unchanged
+	Begin(ctx context.Context, key, fingerprint string) (*idempotentResponse, error)
+
+	// Complete stores the response of the key
+	Complete(ctx context.Context, key string, resp idempotentResponse) error
+
+	// Abort releases the key so the request can be retried
+	Abort(ctx context.Context, key string) error
+
+	// Close releases resources
+	Close() error
+}
+
+// RedisIdempotencyStore stores idempotency keys in Redis so retries are
+// recognized on every instance behind a load balancer
+type RedisIdempotencyStore struct {
+	client *redis.Client
+	ttl    time.Duration
+	shared bool // client is owned by the response cache
+}
+
+// NewRedisIdempotencyStore creates a new Redis idempotency store
+func NewRedisIdempotencyStore(redisURL string, ttl time.Duration) (*RedisIdempotencyStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyRedisTimeout)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+
+	return &RedisIdempotencyStore{client: client, ttl: ttl}, nil
+}
+
+// NewRedisIdempotencyStoreWithClient creates a Redis idempotency store on an
+// existing client. The client is not closed when the store is closed
+func NewRedisIdempotencyStoreWithClient(client *redis.Client, ttl time.Duration) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client, ttl: ttl, shared: true}
+}
+
+// Begin claims the key with a pending entry
+func (s *RedisIdempotencyStore) Begin(ctx context.Context, key, fingerprint string) (*idempotentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, idempotencyRedisTimeout)
+	defer cancel()
+
+	pending, err := json.Marshal(idempotentResponse{Fingerprint: fingerprint, Pending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.client.SetNX(ctx, idempotencyPrefix+key, pending, idempotencyLockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if ok {
+		return nil, nil
+	}
+
+	b, err := s.client.Get(ctx, idempotencyPrefix+key).Bytes()
+	if err == redis.Nil {
+		// The entry expired in between, treat the key as in use
+		return &idempotentResponse{Fingerprint: fingerprint, Pending: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	var resp idempotentResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency key: %w", err)
+	}
+	return &resp, nil
+}
+
+// Complete stores the response of the key
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, resp idempotentResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, idempotencyRedisTimeout)
+	defer cancel()
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, idempotencyPrefix+key, b, s.ttl).Err()
+}
+
+// Abort releases the key
+func (s *RedisIdempotencyStore) Abort(ctx context.Context, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, idempotencyRedisTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, idempotencyPrefix+key).Err()
+}
+
+// Close closes the Redis connection unless it is shared
+func (s *RedisIdempotencyStore) Close() error {
+	if s.shared {
+		return nil
+	}
+	return s.client.Close()
+}
+
+// MemoryIdempotencyStore stores idempotency keys in memory, keys are only
+// recognized by the instance that stored them
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+	ttl     time.Duration
+	done    chan struct{}
+}
+
+type memoryIdempotencyEntry struct {
+	resp      idempotentResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates a new in-memory idempotency store
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	s := &MemoryIdempotencyStore{
+		entries: make(map[string]memoryIdempotencyEntry),
+		ttl:     ttl,
+		done:    make(chan struct{}),
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// Begin claims the key with a pending entry
+func (s *MemoryIdempotencyStore) Begin(ctx context.Context, key, fingerprint string) (*idempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		resp := e.resp
+		return &resp, nil
+	}
+
+	s.entries[key] = memoryIdempotencyEntry{
+		resp:      idempotentResponse{Fingerprint: fingerprint, Pending: true},
+		expiresAt: time.Now().Add(idempotencyLockTTL),
+	}
+	return nil, nil
+}
+
+// Complete stores the response of the key
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, resp idempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryIdempotencyEntry{resp: resp, expiresAt: time.Now().Add(s.ttl)}
+	return nil
+}
+
+// Abort releases the key
+func (s *MemoryIdempotencyStore) Abort(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Close stops the cleanup goroutine
+func (s *MemoryIdempotencyStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	s.entries = make(map[string]memoryIdempotencyEntry)
+	return nil
+}
+
+// cleanupLoop periodically removes expired entries
+func (s *MemoryIdempotencyStore) cleanupLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for k, e := range s.entries {
+				if now.After(e.expiresAt) {
+					delete(s.entries, k)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// idempotencyGuard tracks a request sent with an Idempotency-Key header.
+// A nil guard is valid and does nothing
+type idempotencyGuard struct {
+	store IdempotencyStore
+	key   string
+	fp    string
+	rec   *responseRecorder
+}
+
+// startIdempotent claims the Idempotency-Key of a POST request. When the key
+// was used before the stored response (or an error) is written and handled is
+// true, the request must then not be executed. Keys are scoped to the user
+// and fingerprinted with the path and body so a key can't replay the response
+// of another user or of a different request
+func (s *graphjinService) startIdempotent(c context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	body []byte,
+) (g *idempotencyGuard, handled bool) {
+	if s.idempotency == nil || r.Method != http.MethodPost {
+		return nil, false
+	}
+
+	key := r.Header.Get(headerIdempotencyKey)
+	if key == "" {
+		return nil, false
+	}
+	if !validHeaderToken(key, maxIdempotencyKeyLen) {
+		w.WriteHeader(http.StatusBadRequest)
+		renderErr(w, fmt.Errorf("invalid %s header", headerIdempotencyKey))
+		return nil, true
+	}
+
+	var userID string
+	if v := c.Value(core.UserIDKey); v != nil {
+		userID = fmt.Sprint(v)
+	}
+	g = &idempotencyGuard{
+		store: s.idempotency,
+		key:   hashParts(userID, key),
+		fp:    hashParts(r.URL.Path, string(body)),
+	}
+
+	prev, err := g.store.Begin(c, g.key, g.fp)
+	if err != nil {
+		// The store is unavailable, run the request without a guard
+		s.log.Warnf("idempotency: %s", err)
+		return nil, false
+	}
+	if prev == nil {
+		return g, false
+	}
+
+	switch {
+	case prev.Fingerprint != g.fp:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		renderErr(w, errIdempotencyMismatch)
+	case prev.Pending:
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusConflict)
+		renderErr(w, errIdempotencyInFlight)
+	default:
+		w.Header().Set(headerIdempotentReplay, "true")
+		if prev.Status != 0 {
+			w.WriteHeader(prev.Status)
+		}
+		w.Write(prev.Body) //nolint:errcheck
+	}
+	return nil, true
+}
+
+// writer returns the response writer that records the response for the key
+func (g *idempotencyGuard) writer(w http.ResponseWriter) http.ResponseWriter {
+	if g == nil {
+		return w
+	}
+	g.rec = &responseRecorder{ResponseWriter: w}
+	return g.rec
+}
+
+// finish stores the recorded response when a mutation completed without
+// errors. Otherwise the key is released so the client can retry
+func (g *idempotencyGuard) finish(c context.Context, res *core.Result, err error) {
+	if g == nil {
+		return
+	}
+
+	if err != nil || g.rec == nil || res == nil ||
+		res.Operation() != core.OpMutation || len(res.Errors) != 0 {
+		g.abort(c)
+		return
+	}
+
+	resp := idempotentResponse{
+		Fingerprint: g.fp,
+		Status:      g.rec.status,
+		Body:        g.rec.body.Bytes(),
+	}
+	if err := g.store.Complete(c, g.key, resp); err != nil {
+		g.abort(c)
+	}
+}
+
+// abort releases the key
+func (g *idempotencyGuard) abort(c context.Context) {
+	if g == nil {
+		return
+	}
+	g.store.Abort(c, g.key) //nolint:errcheck
+}
+
+// responseRecorder copies the response body written to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// hashParts returns the hex SHA-256 of the parts separated by a zero byte
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for i, p := range parts {
+		if i != 0 {
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,136 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	core "github.com/dosco/graphjin/core/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestIdempotentMutation(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.sqlite3"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	if _, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zap.NewNop()
+	fs := newAferoFS(afero.NewMemMapFs(), "/")
+
+	coreConf := core.Config{DBType: "sqlite"}
+	gj, err := core.NewGraphJin(&coreConf, db,
+		core.OptionSetFS(fs),
+		core.OptionSetDatabases(map[string]*sql.DB{core.DefaultDBName: db}))
+	if err != nil {
+		t.Fatalf("create test GraphJin: %v", err)
+	}
+
+	store := NewMemoryIdempotencyStore(time.Hour)
+	defer store.Close() //nolint:errcheck
+
+	hs := &HttpService{}
+	hs.Store(&graphjinService{
+		gj:          gj,
+		log:         logger.Sugar(),
+		zlog:        logger,
+		conf:        &Config{Core: coreConf},
+		tracer:      otel.Tracer("graphjin-idempotency-test"),
+		idempotency: store,
+	})
+
+	handler, err := routesHandler(hs, chi.NewRouter(), nil)
+	if err != nil {
+		t.Fatalf("routes handler: %v", err)
+	}
+
+	post := func(key, name string) *httptest.ResponseRecorder {
+		body := `{"query":"mutation { users(insert: {name: \"` + name + `\"}) { name } }"}`
+		req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerRequestID, "req-1")
+		if key != "" {
+			req.Header.Set(headerIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	users := func() (n int) {
+		if err := db.QueryRow(`SELECT count(*) FROM users`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	first := post("k1", "Ada")
+	if !strings.Contains(first.Body.String(), `"name":"Ada"`) {
+		t.Fatalf("unexpected response: %s", first.Body.String())
+	}
+	if v := first.Header().Get(headerRequestID); v != "req-1" {
+		t.Fatalf("expected the request ID to be echoed, got %q", v)
+	}
+
+	retry := post("k1", "Ada")
+	if retry.Header().Get(headerIdempotentReplay) != "true" {
+		t.Fatal("expected the retry to replay the stored response")
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the stored response, got %s", retry.Body.String())
+	}
+	if n := users(); n != 1 {
+		t.Fatalf("expected the mutation to run once, got %d rows", n)
+	}
+
+	if w := post("k1", "Grace"); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected key reuse with another request to fail, got %d", w.Code)
+	}
+
+	post("k2", "Grace")
+	post("", "Hopper")
+	post("", "Hopper")
+	if n := users(); n != 4 {
+		t.Fatalf("expected 4 rows, got %d", n)
+	}
+}
+
+func TestIdempotencyStoreInFlight(t *testing.T) {
+	s := NewMemoryIdempotencyStore(time.Hour)
+	defer s.Close() //nolint:errcheck
+	c := context.Background()
+
+	if prev, _ := s.Begin(c, "k", "fp"); prev != nil {
+		t.Fatal("expected the key to be claimed")
+	}
+	if prev, _ := s.Begin(c, "k", "fp"); prev == nil || !prev.Pending {
+		t.Fatal("expected the key to be in flight")
+	}
+
+	// an aborted key can be claimed again
+	s.Abort(c, "k") //nolint:errcheck
+	if prev, _ := s.Begin(c, "k", "fp"); prev != nil {
+		t.Fatal("expected the released key to be claimed")
+	}
+
+	s.Complete(c, "k", idempotentResponse{Fingerprint: "fp", Body: []byte("{}")}) //nolint:errcheck
+	if prev, _ := s.Begin(c, "k", "fp"); prev == nil || prev.Pending || string(prev.Body) != "{}" {
+		t.Fatalf("expected the stored response, got %+v", prev)
+	}
+}
@@ -0,0 +1,112 @@
+package serv
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	impersonateUserHeader = "X-Impersonate-User-ID"
+	impersonateRoleHeader = "X-Impersonate-Role"
+)
+
+// impersonateHandler runs the request as the user in the impersonation
+// headers when the authenticated caller has one of the admin roles
+func impersonateHandler(s1 *HttpService, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get(impersonateUserHeader)
+		userRole := r.Header.Get(impersonateRoleHeader)
+
+		if userID == "" && userRole == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		s := s1.Load().(*graphjinService)
+		c := r.Context()
+
+		adminID := c.Value(core.UserIDKey)
+		adminRole, ok := c.Value(core.UserRoleKey).(string)
+		if !ok && adminID != nil {
+			// the role of the caller can come from the roles_query
+			var err error
+			if adminRole, err = s.gj.UserRole(c); err != nil {
+				s.zlog.Error("impersonation: role of the caller", zap.Error(err))
+				http.Error(w, "500 failed to resolve the role of the caller", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if !s.canImpersonate(adminID, adminRole) || !s.canImpersonateRole(userRole) {
+			s.zlog.Warn("impersonation denied",
+				zap.Any("admin_id", adminID),
+				zap.String("admin_role", adminRole),
+				zap.String("user_id", userID),
+				zap.String("user_role", userRole),
+				zap.String("path", r.URL.Path))
+			http.Error(w, "403 impersonation not allowed", http.StatusForbidden)
+			return
+		}
+
+		s.zlog.Info("impersonation",
+			zap.Any("admin_id", adminID),
+			zap.String("admin_role", adminRole),
+			zap.String("user_id", userID),
+			zap.String("user_role", userRole),
+			zap.String("path", r.URL.Path))
+
+		// the admin's identity must not leak into the request
+		c = context.WithValue(c, core.UserIDRawKey, nil)
+		c = context.WithValue(c, core.UserIDProviderKey, nil)
+
+		if userID != "" {
+			c = context.WithValue(c, core.UserIDKey, userID)
+		}
+
+		if userRole != "" {
+			c = context.WithValue(c, core.UserRoleKey, userRole)
+		} else {
+			c = context.WithValue(c, core.UserRoleKey, nil)
+		}
+
+		h.ServeHTTP(w, r.WithContext(c))
+	})
+}
+
+// canImpersonate returns true if the caller is allowed to impersonate
+func (s *graphjinService) canImpersonate(adminID any, adminRole string) bool {
+	conf := s.conf.Impersonation
+	if !conf.Enable || adminID == nil || adminRole == "" {
+		return false
+	}
+
+	return s.isImpersonator(adminRole)
+}
+
+// canImpersonateRole returns true if the role can be taken on with the
+// X-Impersonate-Role header. Without target roles any role other than the
+// roles allowed to impersonate can be taken on
+func (s *graphjinService) canImpersonateRole(role string) bool {
+	conf := s.conf.Impersonation
+	if role == "" {
+		return true
+	}
+
+	if len(conf.TargetRoles) != 0 {
+		return slices.Contains(conf.TargetRoles, role)
+	}
+	return !s.isImpersonator(role)
+}
+
+// isImpersonator returns true if the role is allowed to impersonate
+func (s *graphjinService) isImpersonator(role string) bool {
+	conf := s.conf.Impersonation
+	if len(conf.Roles) == 0 {
+		return role == "admin"
+	}
+	return slices.Contains(conf.Roles, role)
+}
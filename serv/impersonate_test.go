@@ -0,0 +1,75 @@
+package serv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+func TestImpersonateHandler(t *testing.T) {
+	hs := &HttpService{}
+	hs.Store(&graphjinService{
+		zlog: zap.NewNop(),
+		conf: &Config{Serv: Serv{Impersonation: Impersonation{Enable: true, Roles: []string{"support"}}}},
+	})
+
+	var userID any
+	var userRole any
+	h := impersonateHandler(hs, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID = r.Context().Value(core.UserIDKey)
+		userRole = r.Context().Value(core.UserRoleKey)
+	}))
+
+	send := func(role string, headers map[string]string) int {
+		userID, userRole = nil, nil
+		r := httptest.NewRequest("POST", "/api/v1/graphql", nil)
+		c := context.WithValue(r.Context(), core.UserIDKey, "admin-1")
+		c = context.WithValue(c, core.UserRoleKey, role)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r.WithContext(c))
+		return w.Code
+	}
+
+	if code := send("support", nil); code != http.StatusOK || userID != "admin-1" {
+		t.Fatalf("expected the request to pass through unchanged: %d %v", code, userID)
+	}
+
+	code := send("support", map[string]string{impersonateUserHeader: "42"})
+	if code != http.StatusOK || userID != "42" || userRole != nil {
+		t.Fatalf("expected to act as user 42: %d %v %v", code, userID, userRole)
+	}
+
+	code = send("support", map[string]string{impersonateUserHeader: "42", impersonateRoleHeader: "customer"})
+	if code != http.StatusOK || userID != "42" || userRole != "customer" {
+		t.Fatalf("expected to act as user 42 with role customer: %d %v %v", code, userID, userRole)
+	}
+
+	if code := send("user", map[string]string{impersonateUserHeader: "42"}); code != http.StatusForbidden {
+		t.Fatalf("expected impersonation to be denied, got %d", code)
+	}
+
+	// admins cannot take on an admin role
+	code = send("support", map[string]string{impersonateUserHeader: "42", impersonateRoleHeader: "support"})
+	if code != http.StatusForbidden {
+		t.Fatalf("expected the admin role to be denied, got %d", code)
+	}
+
+	s := hs.Load().(*graphjinService)
+	s.conf.Impersonation.TargetRoles = []string{"customer"}
+
+	code = send("support", map[string]string{impersonateUserHeader: "42", impersonateRoleHeader: "customer"})
+	if code != http.StatusOK || userRole != "customer" {
+		t.Fatalf("expected the target role to be allowed: %d %v", code, userRole)
+	}
+	code = send("support", map[string]string{impersonateUserHeader: "42", impersonateRoleHeader: "billing"})
+	if code != http.StatusForbidden {
+		t.Fatalf("expected a role outside the target roles to be denied, got %d", code)
+	}
+}
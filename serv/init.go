@@ -83,6 +83,14 @@ func (s *graphjinService) initConfig() error {
 	// 	}
 	// }
 
+	if err := validateRequestVars(c.RequestVars); err != nil {
+		return err
+	}
+
+	if err := validateFeatureFlags(c.FeatureFlags); err != nil {
+		return err
+	}
+
 	if c.Auth.Type == "" || c.Auth.Type == "none" {
 		c.DefaultBlock = false
 	}
@@ -381,6 +389,13 @@ func (s *graphjinService) initCursorCache() error {
 		maxEntries = 10000 // Default 10k entries
 	}
 
+	// Share the response cache's Redis client when there is one
+	if rc, ok := s.cache.(*RedisCache); ok {
+		s.cursorCache = NewRedisCursorCacheWithClient(rc.client, ttl)
+		s.log.Info("MCP cursor cache: Redis")
+		return nil
+	}
+
 	if s.conf.Redis.URL != "" {
 		// Try to use Redis
 		cache, err := NewRedisCursorCache(s.conf.Redis.URL, ttl)
@@ -399,3 +414,81 @@ func (s *graphjinService) initCursorCache() error {
 
 	return nil
 }
+
+// initIdempotencyStore initializes the store for the Idempotency-Key header
+// of mutations (Redis or in-memory)
+func (s *graphjinService) initIdempotencyStore() error {
+	if !s.conf.Idempotency.Enable {
+		return nil
+	}
+
+	ttl := time.Duration(s.conf.Idempotency.TTL) * time.Second
+	if ttl == 0 {
+		ttl = 24 * time.Hour // Default 24 hours
+	}
+
+	// Share the response cache's Redis client when there is one
+	if rc, ok := s.cache.(*RedisCache); ok {
+		s.idempotency = NewRedisIdempotencyStoreWithClient(rc.client, ttl)
+		s.log.Info("Idempotency keys: Redis")
+		return nil
+	}
+
+	if s.conf.Redis.URL != "" {
+		store, err := NewRedisIdempotencyStore(s.conf.Redis.URL, ttl)
+		if err == nil {
+			s.idempotency = store
+			s.log.Info("Idempotency keys: Redis")
+			return nil
+		}
+		s.log.Warnf("Redis unavailable for idempotency keys, using in-memory: %s", err)
+	}
+
+	s.idempotency = NewMemoryIdempotencyStore(ttl)
+	s.log.Info("Idempotency keys: in-memory")
+	return nil
+}
+
+// initSessionStore sets the store the session auth looks up sessions in
+func (s *graphjinService) initSessionStore() error {
+	if s.conf.Auth.Type != "session" {
+		return nil
+	}
+	sc := &s.conf.Auth.Session
+
+	switch sc.Store {
+	case "redis":
+		// Share the response cache's Redis client when there is one
+		if rc, ok := s.cache.(*RedisCache); ok {
+			sc.SetStore(NewRedisSessionStore(rc.client, sc.Prefix))
+			break
+		}
+		if s.conf.Redis.URL == "" {
+			return fmt.Errorf("session store: redis.url required")
+		}
+		store, err := NewRedisSessionStoreURL(s.conf.Redis.URL, sc.Prefix)
+		if err != nil {
+			return fmt.Errorf("session store: %w", err)
+		}
+		sc.SetStore(store)
+
+	case "table":
+		db, dbType := s.anyDB(), s.conf.DBType
+		if name := sc.Database; name != "" {
+			db = s.dbs[name]
+			if dc, ok := s.conf.Core.Databases[name]; ok && dc.Type != "" {
+				dbType = strings.ToLower(dc.Type)
+			}
+		}
+		if db == nil {
+			return fmt.Errorf("session store: database not found: %s", sc.Database)
+		}
+		sc.SetStore(NewTableSessionStore(db, dbType, sc.Table))
+
+	default:
+		return fmt.Errorf("session store: unknown store '%s'", sc.Store)
+	}
+
+	s.log.Infof("Session auth: %s", sc.Store)
+	return nil
+}
@@ -1,9 +1,7 @@
 package serv
 
 import (
-	"net"
 	"net/http"
-	"strings"
 	"time"
 
 	cache "github.com/go-pkgz/expirable-cache"
@@ -34,31 +32,12 @@ func getIPLimiter(ip string, limit float64, bucket int) *rate.Limiter {
 // rateLimiter is a middleware that limits the number of requests per IP
 func rateLimiter(s1 *HttpService, h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		var iph, ip string
-		var err error
 		s := s1.Load().(*graphjinService)
 
-		if s.conf.RateLimiter.IPHeader != "" {
-			iph = r.Header.Get(s.conf.RateLimiter.IPHeader)
-		} else {
-			iph = r.Header.Get("X-Forwarded-For")
-		}
-
-		if iph != "" {
-			v := strings.Split(iph, ",")
-			switch n := len(v); {
-			case n > 1:
-				ip = strings.TrimSpace(v[n-2])
-			case n == 1:
-				ip = v[0]
-			}
-
-		} else {
-			ip, _, err = net.SplitHostPort(r.RemoteAddr)
-			if err != nil {
-				s.zlog.Error("Rate Limiter", []zapcore.Field{zap.Error(err)}...)
-				return
-			}
+		ip, err := clientIP(r, s.conf.RateLimiter.IPHeader)
+		if err != nil {
+			s.zlog.Error("Rate Limiter", []zapcore.Field{zap.Error(err)}...)
+			return
 		}
 
 		if !getIPLimiter(ip,
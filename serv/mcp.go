@@ -110,7 +110,7 @@ func mcpToolList(conf *Config) []string {
 		tools = append(tools, "save_workflow")
 	}
 	if !conf.Serv.Production {
-		tools = append(tools, "get_current_config")
+		tools = append(tools, "get_current_config", "get_effective_config")
 	}
 	if conf.MCP.AllowRawQueries {
 		tools = append(tools, "execute_graphql")
@@ -130,7 +130,7 @@ func mcpToolList(conf *Config) []string {
 	}
 	if conf.MCP.AllowDevTools {
 		tools = append(tools, "explain_query", "audit_role_permissions", "discover_databases",
-			"list_databases", "check_health", "plan_database_setup",
+			"list_databases", "check_health", "get_metrics", "compare_query_results", "check_schema_drift", "lint_saved_queries", "plan_database_setup",
 			"test_database_connection", "get_onboarding_status")
 	}
 	if conf.MCP.AllowDevTools && conf.MCP.AllowConfigUpdates {
@@ -233,6 +233,10 @@ func (ms *mcpServer) registerTools() {
 	ms.registerAuditTools()
 	ms.registerDiscoverTools()
 	ms.registerHealthTools()
+	ms.registerMetricsTools()
+	ms.registerDiffTools()
+	ms.registerDriftTools()
+	ms.registerLintTools()
 	ms.registerOnboardingTools()
 }
 
@@ -25,6 +25,15 @@ func (ms *mcpServer) registerConfigTools() {
 				mcp.Description("Optional section to retrieve: 'databases', 'tables', 'roles', 'blocklist', 'functions', 'resolvers', or 'all' (default)"),
 			),
 		), ms.handleGetCurrentConfig)
+
+		ms.srv.AddTool(mcp.NewTool(
+			"get_effective_config",
+			mcp.WithDescription("Get the config GraphJin is actually running with: databases normalized, "+
+				"environment overrides and defaults applied, with passwords, secrets and connection strings masked. "+
+				"Also returns the schema hash of each database and the differences between the running config "+
+				"and the config file on disk. Use this after apply_database_setup or update_current_config "+
+				"to verify what was applied and saved."),
+		), ms.handleGetEffectiveConfig)
 	}
 
 	// update_current_config - Only registered when allow_config_updates is true
@@ -32,7 +41,8 @@ func (ms *mcpServer) registerConfigTools() {
 		ms.srv.AddTool(mcp.NewTool(
 			"update_current_config",
 			mcp.WithDescription("Update GraphJin configuration and automatically reload. "+
-				"Changes are applied in-memory and take effect immediately. "+
+				"Changes are staged on a second engine and validated (databases pinged, saved queries compiled) "+
+				"before it is swapped in; the previous config is restored if the swap fails. "+
 				"Supports databases, tables, roles, blocklist, functions, and resolvers. "+
 				"System database names (postgres, mysql, information_schema, master, etc.) "+
 				"are rejected by default — use a user database name instead. "+
@@ -468,6 +478,9 @@ func (ms *mcpServer) handleUpdateCurrentConfig(ctx context.Context, req mcp.Call
 	var changes []string
 	var errors []string
 
+	ms.service.configMu.Lock()
+	defer ms.service.configMu.Unlock()
+
 	stagedCore := cloneCoreConfig(ms.service.conf.Core)
 	conf := &stagedCore
 
@@ -828,6 +841,11 @@ func (ms *mcpServer) handleUpdateCurrentConfig(ctx context.Context, req mcp.Call
 	var availableDBs []string
 	if len(changes) > 0 {
 		stage, err := ms.prepareStagedRuntime(conf, createIfNotExists)
+		var verr error
+		if err == nil {
+			verr = ms.validateStagedRuntime(ctx, stage, conf)
+			err = verr
+		}
 		if err != nil {
 			if stage != nil {
 				availableDBs = stage.availableDBs
@@ -840,6 +858,10 @@ func (ms *mcpServer) handleUpdateCurrentConfig(ctx context.Context, req mcp.Call
 				message = "Config validation failed, changes not applied: database connected but schema discovery found no tables. Try a different database from the databases list, or create tables first."
 				errs = append(errs, "schema not ready after staged reload")
 			}
+			if verr != nil {
+				message = fmt.Sprintf("Config validation failed, changes not applied: %v", verr)
+				errs = append(errors, fmt.Sprintf("validation error: %v", verr))
+			}
 
 			result := ConfigUpdateResult{
 				Success:   false,
@@ -854,7 +876,25 @@ func (ms *mcpServer) handleUpdateCurrentConfig(ctx context.Context, req mcp.Call
 		}
 
 		availableDBs = stage.availableDBs
-		ms.commitStagedRuntime(stagedCore, stage)
+		prev := ms.commitStagedRuntime(stagedCore, stage)
+
+		// Roll back to the previous runtime if the swapped in one fails
+		if err := ms.verifyLiveRuntime(ctx); err != nil {
+			ms.rollbackRuntime(prev, stage)
+
+			result := ConfigUpdateResult{
+				Success:   false,
+				Message:   fmt.Sprintf("Config swap failed, rolled back to the previous config: %v", err),
+				Changes:   changes,
+				Errors:    append(errors, fmt.Sprintf("swap error: %v", err)),
+				Databases: availableDBs,
+			}
+			result.Next = ms.nextForConfigUpdate(result)
+			data, _ := mcpMarshalJSON(result, true)
+			return mcpToolResultJSONBytes(data), nil
+		}
+		ms.releaseRuntime(prev)
+
 		if ms.service.gj != nil && ms.service.gj.SchemaReady() {
 			changes = append(changes, "configuration validated and runtime reloaded transactionally")
 		}
@@ -1565,9 +1605,16 @@ func (ms *mcpServer) prepareStagedRuntime(stagedCore *core.Config, createIfNotEx
 	return stage, nil
 }
 
-func (ms *mcpServer) commitStagedRuntime(stagedCore core.Config, stage *stagedRuntimeState) {
-	oldGJ := ms.service.gj
-	oldDBs := ms.service.dbs
+// commitStagedRuntime swaps in the staged runtime and returns the replaced
+// one, which the caller releases or rolls back to
+func (ms *mcpServer) commitStagedRuntime(stagedCore core.Config, stage *stagedRuntimeState) *liveRuntime {
+	prev := &liveRuntime{
+		core:   ms.service.conf.Core,
+		db:     ms.service.conf.DB,
+		dbType: ms.service.conf.DBType,
+		dbs:    ms.service.dbs,
+		gj:     ms.service.gj,
+	}
 	hadDatabaseMap := len(ms.service.conf.Core.Databases) > 0
 	prevLegacyDB := ms.service.conf.DB
 	prevDBType := ms.service.conf.DBType
@@ -1586,10 +1633,8 @@ func (ms *mcpServer) commitStagedRuntime(stagedCore core.Config, stage *stagedRu
 
 	ms.service.dbs = stage.dbs
 	ms.service.gj = stage.gj
-	if oldGJ != nil && oldGJ != stage.gj {
-		oldGJ.Close()
-	}
-	ms.closeSupersededConnections(oldDBs, stage.dbs)
+	ms.service.applyReadOnly()
+	return prev
 }
 
 func (ms *mcpServer) closeSupersededConnections(oldDBs, newDBs map[string]*sql.DB) {
@@ -0,0 +1,84 @@
+package serv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// EffectiveConfigResult is the get_effective_config response
+type EffectiveConfigResult struct {
+	EffectiveConfig
+
+	// Config file the running config is compared against
+	ConfigFile string `json:"config_file,omitempty"`
+
+	// True when the running config matches the config file
+	InSync bool `json:"in_sync"`
+
+	// Differences from the config file (left) to the running config (right)
+	FileDiffs []core.ResultDiff `json:"file_diffs,omitempty"`
+
+	// Why the config file could not be compared
+	DiffError string `json:"diff_error,omitempty"`
+}
+
+// handleGetEffectiveConfig returns the effective config with secrets redacted
+// and its differences from the config file on disk
+func (ms *mcpServer) handleGetEffectiveConfig(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	ec, err := ms.service.effectiveConfig()
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	result := EffectiveConfigResult{EffectiveConfig: ec}
+
+	result.ConfigFile, result.FileDiffs, err = ms.configFileDiffs()
+	if err != nil {
+		result.DiffError = err.Error()
+	} else {
+		result.InSync = len(result.FileDiffs) == 0
+	}
+	return ms.toolResultJSON("get_effective_config", args, result)
+}
+
+// configFileDiffs compares the config file on disk with the config held by
+// the service, which is what config updates change and save. Both sides are
+// redacted, including the credentials in urls, before they are compared so
+// neither the values nor the changes of secrets are reported
+func (ms *mcpServer) configFileDiffs() (string, []core.ResultDiff, error) {
+	s := ms.service
+	if s.conf.viper == nil || s.conf.viper.ConfigFileUsed() == "" {
+		return "", nil, fmt.Errorf("no config file loaded")
+	}
+	file := s.conf.viper.ConfigFileUsed()
+
+	disk, err := ReadInConfig(file)
+	if err != nil {
+		return file, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	left, err := s.redactConfig(disk)
+	if err != nil {
+		return file, nil, err
+	}
+	right, err := s.redactConfig(s.conf)
+	if err != nil {
+		return file, nil, err
+	}
+
+	lb, err := json.Marshal(left)
+	if err != nil {
+		return file, nil, err
+	}
+	rb, err := json.Marshal(right)
+	if err != nil {
+		return file, nil, err
+	}
+	diffs, err := core.DiffResults(lb, rb)
+	return file, diffs, err
+}
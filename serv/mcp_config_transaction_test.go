@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dosco/graphjin/core/v3"
@@ -149,6 +150,59 @@ func TestHandleUpdateCurrentConfig_TransactionalSuccessSwapsRuntime(t *testing.T
 	}
 }
 
+func TestHandleUpdateCurrentConfig_SavedQueryValidationFailureKeepsLiveRuntime(t *testing.T) {
+	livePath := createSQLiteDBFile(t, "live.sqlite3", true)
+	ms := newTransactionalConfigMCPServer(t, livePath)
+
+	// The replacement database is missing a column used by a saved query
+	replacementPath := filepath.Join(t.TempDir(), "replacement.sqlite3")
+	db, err := sql.Open("sqlite", replacementPath)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	db.Close()
+
+	query := "query getUsers {\n  users {\n    id\n    name\n  }\n}\n"
+	if err := ms.service.fs.Put("/queries/getUsers.gql", []byte(query)); err != nil {
+		t.Fatalf("save query: %v", err)
+	}
+
+	oldGJ := ms.service.gj
+	oldDB := ms.service.dbs["main"]
+
+	res, err := ms.handleUpdateCurrentConfig(context.Background(), newToolRequest(map[string]any{
+		"databases": map[string]any{
+			"main": map[string]any{
+				"type": "sqlite",
+				"path": replacementPath,
+			},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out ConfigUpdateResult
+	if err := json.Unmarshal([]byte(assertToolSuccess(t, res)), &out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if out.Success {
+		t.Fatalf("expected validation to fail, got %+v", out)
+	}
+	if !strings.Contains(out.Message, "getUsers") {
+		t.Fatalf("expected the failing query in the message, got %q", out.Message)
+	}
+	if ms.service.gj != oldGJ || ms.service.dbs["main"] != oldDB {
+		t.Fatal("expected the live runtime to remain unchanged on validation failure")
+	}
+	if err := oldDB.Ping(); err != nil {
+		t.Fatalf("expected original database handle to remain open, ping failed: %v", err)
+	}
+}
+
 func createSQLiteDBFile(t *testing.T, name string, withSchema bool) string {
 	t.Helper()
 
@@ -0,0 +1,165 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+// stagePingTimeout is the time a staged database has to answer a ping
+const stagePingTimeout = 5 * time.Second
+
+// liveRuntime is the runtime replaced by a staged runtime. It is kept until
+// the new runtime is verified so a failed swap can be rolled back
+type liveRuntime struct {
+	core   core.Config
+	db     Database
+	dbType string
+	dbs    map[string]*sql.DB
+	gj     *core.GraphJin
+}
+
+// validateStagedRuntime runs the validation suite on a staged runtime before
+// it replaces the live one: every database must answer a ping and every
+// saved query that compiles on the live runtime must compile on the staged one
+func (ms *mcpServer) validateStagedRuntime(ctx context.Context, stage *stagedRuntimeState, conf *core.Config) error {
+	if err := pingDatabases(ctx, stage.dbs); err != nil {
+		return err
+	}
+	if stage.gj == nil {
+		return nil
+	}
+
+	failed := savedQueryErrors(stage.gj, compileRoles(conf))
+	if len(failed) == 0 {
+		return nil
+	}
+
+	// Queries already broken on the live runtime do not block the update
+	if live := ms.service.gj; live != nil {
+		for name := range savedQueryErrors(live, compileRoles(&ms.service.conf.Core)) {
+			delete(failed, name)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	name := names[0]
+	if len(names) == 1 {
+		return fmt.Errorf("saved query '%s' does not compile: %s", name, failed[name])
+	}
+	return fmt.Errorf("%d saved queries do not compile, '%s': %s",
+		len(names), name, failed[name])
+}
+
+// pingDatabases checks that every database connection is alive
+func pingDatabases(ctx context.Context, dbs map[string]*sql.DB) error {
+	for name, db := range dbs {
+		if db == nil {
+			continue
+		}
+		c, cancel := context.WithTimeout(ctx, stagePingTimeout)
+		err := db.PingContext(c)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("database '%s' ping failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// savedQueryErrors compiles every saved query and returns the errors by
+// query name. A query is valid when it compiles for any of the roles
+func savedQueryErrors(gj *core.GraphJin, roles []string) map[string]string {
+	failed := make(map[string]string)
+
+	// A missing queries folder means there are no saved queries
+	queries, _ := gj.ListSavedQueries()
+
+	for _, q := range queries {
+		sq, err := gj.GetSavedQuery(q.Name)
+		if err != nil {
+			failed[q.Name] = err.Error()
+			continue
+		}
+		if msg := compileForAnyRole(gj, sq.Query, roles); msg != "" {
+			failed[q.Name] = msg
+		}
+	}
+	return failed
+}
+
+// compileForAnyRole compiles the query for each role until one succeeds and
+// returns the first error or an empty string
+func compileForAnyRole(gj *core.GraphJin, query string, roles []string) string {
+	var msg string
+	for _, role := range roles {
+		exp, err := gj.ExplainQuery(query, nil, role)
+		switch {
+		case err != nil:
+			return err.Error()
+		case len(exp.Errors) == 0:
+			return ""
+		case msg == "":
+			msg = exp.Errors[0]
+		}
+	}
+	return msg
+}
+
+// compileRoles returns the roles saved queries are compiled for
+func compileRoles(conf *core.Config) []string {
+	roles := []string{"user", "anon"}
+	for _, r := range conf.Roles {
+		if !slices.Contains(roles, r.Name) {
+			roles = append(roles, r.Name)
+		}
+	}
+	return roles
+}
+
+// verifyLiveRuntime checks the runtime after a swap, on failure the swap is
+// rolled back
+func (ms *mcpServer) verifyLiveRuntime(ctx context.Context) error {
+	s := ms.service
+	if s.gj != nil && !s.gj.SchemaReady() {
+		return fmt.Errorf("schema not ready after swap")
+	}
+	return pingDatabases(ctx, s.dbs)
+}
+
+// rollbackRuntime puts back the runtime replaced by a staged runtime and
+// closes the staged one
+func (ms *mcpServer) rollbackRuntime(prev *liveRuntime, stage *stagedRuntimeState) {
+	s := ms.service
+	s.conf.Core = prev.core
+	s.conf.DB = prev.db
+	s.conf.DBType = prev.dbType
+	s.dbs = prev.dbs
+	s.gj = prev.gj
+	s.applyReadOnly()
+
+	stage.close()
+	s.log.Warn("config update rolled back to the previous runtime")
+}
+
+// releaseRuntime closes the parts of the previous runtime that are not used
+// by the new one
+func (ms *mcpServer) releaseRuntime(prev *liveRuntime) {
+	if prev.gj != nil && prev.gj != ms.service.gj {
+		prev.gj.Close()
+	}
+	ms.closeSupersededConnections(prev.dbs, ms.service.dbs)
+}
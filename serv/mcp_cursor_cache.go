@@ -34,10 +34,12 @@ const (
 	cursorRedisTimeout = 100 * time.Millisecond
 )
 
-// RedisCursorCache uses Redis for distributed cursor caching
+// RedisCursorCache uses Redis for distributed cursor caching so numeric cursor
+// IDs resolve on every instance behind a load balancer
 type RedisCursorCache struct {
 	client *redis.Client
 	ttl    time.Duration
+	shared bool // client is owned by the response cache
 }
 
 // NewRedisCursorCache creates a new Redis cursor cache
@@ -62,6 +64,17 @@ func NewRedisCursorCache(redisURL string, ttl time.Duration) (*RedisCursorCache,
 	}, nil
 }
 
+// NewRedisCursorCacheWithClient creates a Redis cursor cache on an existing
+// client, such as the one used by the response cache. The client is not
+// closed when the cursor cache is closed
+func NewRedisCursorCacheWithClient(client *redis.Client, ttl time.Duration) *RedisCursorCache {
+	return &RedisCursorCache{
+		client: client,
+		ttl:    ttl,
+		shared: true,
+	}
+}
+
 // Set stores a cursor and returns a short numeric ID
 func (c *RedisCursorCache) Set(ctx context.Context, cursor string) (uint64, error) {
 	ctx, cancel := context.WithTimeout(ctx, cursorRedisTimeout)
@@ -90,16 +103,30 @@ func (c *RedisCursorCache) Set(ctx context.Context, cursor string) (uint64, erro
 
 	idKey := cursorIDKey + fmt.Sprintf("%d", id)
 
-	// Store both mappings
-	pipe := c.client.Pipeline()
-	pipe.Set(ctx, idKey, cursor, c.ttl)
-	pipe.Set(ctx, revKey, id, c.ttl)
-	_, err = pipe.Exec(ctx)
+	// Store the cursor before claiming the dedup key so an ID returned by
+	// another instance always resolves
+	if err := c.client.Set(ctx, idKey, cursor, c.ttl).Err(); err != nil {
+		return 0, fmt.Errorf("failed to store cursor: %w", err)
+	}
+
+	ok, err := c.client.SetNX(ctx, revKey, id, c.ttl).Result()
 	if err != nil {
 		return 0, fmt.Errorf("failed to store cursor: %w", err)
 	}
+	if ok {
+		return id, nil
+	}
 
-	return id, nil
+	// Another instance stored the same cursor first, use its ID
+	existingID, err = c.client.Get(ctx, revKey).Uint64()
+	if err != nil {
+		return id, nil
+	}
+	pipe := c.client.Pipeline()
+	pipe.Del(ctx, idKey)
+	pipe.Expire(ctx, cursorIDKey+fmt.Sprintf("%d", existingID), c.ttl)
+	pipe.Exec(ctx) //nolint:errcheck
+	return existingID, nil
 }
 
 // Get retrieves a cursor by its numeric ID
@@ -119,8 +146,11 @@ func (c *RedisCursorCache) Get(ctx context.Context, id uint64) (string, error) {
 	return cursor, nil
 }
 
-// Close closes the Redis connection
+// Close closes the Redis connection unless it is shared
 func (c *RedisCursorCache) Close() error {
+	if c.shared {
+		return nil
+	}
 	return c.client.Close()
 }
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestMemoryCursorCache_SetGet(t *testing.T) {
@@ -198,3 +201,35 @@ func TestHashCursor(t *testing.T) {
 		t.Errorf("Expected hash length 16, got %d", len(hash1))
 	}
 }
+
+func TestRedisCursorCache_SharesResponseCacheClient(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer client.Close() //nolint:errcheck
+
+	logger := zaptest.NewLogger(t)
+	s := &graphjinService{
+		conf:  &Config{},
+		cache: &RedisCache{client: client},
+		log:   logger.Sugar(),
+	}
+
+	if err := s.initCursorCache(); err != nil {
+		t.Fatalf("initCursorCache failed: %v", err)
+	}
+
+	cc, ok := s.cursorCache.(*RedisCursorCache)
+	if !ok {
+		t.Fatalf("Expected a Redis cursor cache, got %T", s.cursorCache)
+	}
+	if cc.client != client {
+		t.Error("Expected the cursor cache to share the response cache client")
+	}
+
+	// Closing the cursor cache must leave the shared client open
+	if err := cc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Expected the shared client to still be open: %v", err)
+	}
+}
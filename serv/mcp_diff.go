@@ -0,0 +1,101 @@
+package serv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerDiffTools registers the compare_query_results tool
+func (ms *mcpServer) registerDiffTools() {
+	if !ms.service.conf.MCP.AllowDevTools {
+		return
+	}
+
+	ms.srv.AddTool(mcp.NewTool(
+		"compare_query_results",
+		mcp.WithDescription("Run a saved query on two configured databases and compare the results. "+
+			"Returns the differences with their JSON path: missing or extra fields, values of another type, "+
+			"lists of another length or order, and differing values. "+
+			"Use when moving data to another database backend. Only queries can be compared."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the saved query"),
+		),
+		mcp.WithString("left",
+			mcp.Required(),
+			mcp.Description("Database the results are compared against"),
+		),
+		mcp.WithString("right",
+			mcp.Required(),
+			mcp.Description("Database whose results are compared"),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Variables for the query"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the saved query"),
+		),
+	), ms.handleCompareQueryResults)
+}
+
+// CompareResultsResult represents the compare_query_results response
+type CompareResultsResult struct {
+	Name  string            `json:"name"`
+	Left  string            `json:"left"`
+	Right string            `json:"right"`
+	Same  bool              `json:"same"`
+	Diffs []core.ResultDiff `json:"diffs"`
+}
+
+// handleCompareQueryResults runs a saved query on two databases and diffs the results
+func (ms *mcpServer) handleCompareQueryResults(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+	name, _ := args["name"].(string)
+	left, _ := args["left"].(string)
+	right, _ := args["right"].(string)
+	namespace, _ := args["namespace"].(string)
+
+	if name == "" || left == "" || right == "" {
+		return mcp.NewToolResultError("name, left and right are required"), nil
+	}
+
+	var varsJSON json.RawMessage
+	if vars, ok := args["variables"].(map[string]any); ok && len(vars) > 0 {
+		var err error
+		if varsJSON, err = json.Marshal(vars); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid variables: %v", err)), nil
+		}
+	}
+
+	var rc core.RequestConfig
+	if namespace != "" {
+		rc.SetNamespace(namespace)
+	} else {
+		rc.SetNamespace(ms.getNamespace())
+	}
+
+	if err := ms.service.checkGraphJinInitialized(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	diffs, err := ms.service.gj.DiffQuery(ctx, name, varsJSON, &rc, left, right)
+	if err != nil {
+		return mcp.NewToolResultError(enhanceError(err.Error(), "compare_query_results")), nil
+	}
+	if diffs == nil {
+		diffs = []core.ResultDiff{}
+	}
+
+	result := CompareResultsResult{
+		Name:  name,
+		Left:  left,
+		Right: right,
+		Same:  len(diffs) == 0,
+		Diffs: diffs,
+	}
+	return ms.toolResultJSON("compare_query_results", args, result)
+}
@@ -562,7 +562,7 @@ func (ms *mcpServer) runDiscovery(args map[string]any) (DiscoverResult, error) {
 
 // checkTCPPort attempts a TCP connection to host:port with the given timeout
 func checkTCPPort(host string, port int, timeout time.Duration) bool {
-	addr := fmt.Sprintf("%s:%d", host, port)
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", addr, timeout)
 	if err != nil {
 		return false
@@ -0,0 +1,80 @@
+package serv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	core "github.com/dosco/graphjin/core/v3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerDriftTools registers the check_schema_drift tool
+func (ms *mcpServer) registerDriftTools() {
+	if !ms.service.conf.MCP.AllowDevTools {
+		return
+	}
+
+	ms.srv.AddTool(mcp.NewTool(
+		"check_schema_drift",
+		mcp.WithDescription("Compare a schema snapshot file against the live database schema. "+
+			"Returns added and removed tables and columns, type changes and foreign key changes. "+
+			"Use before deploying to detect unexpected schema drift."),
+		mcp.WithString("snapshot",
+			mcp.Description("Snapshot file in the config folder (default: schema_snapshot from config)"),
+		),
+	), ms.handleCheckSchemaDrift)
+}
+
+// SchemaDriftResult represents the schema drift check response
+type SchemaDriftResult struct {
+	Snapshot  string              `json:"snapshot"`
+	CreatedAt string              `json:"created_at"`
+	Drift     bool                `json:"drift"`
+	Changes   []core.SchemaChange `json:"changes"`
+}
+
+// handleCheckSchemaDrift compares a schema snapshot against the live schema
+func (ms *mcpServer) handleCheckSchemaDrift(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := ms.requireDB(); err != nil {
+		return err, nil
+	}
+
+	args := req.GetArguments()
+	name, _ := args["snapshot"].(string)
+	if name == "" {
+		name = ms.service.conf.Core.SchemaSnapshot
+	}
+	if name == "" {
+		return mcp.NewToolResultError("no snapshot given and schema_snapshot is not configured"), nil
+	}
+	if strings.Contains(name, "..") {
+		return mcp.NewToolResultError("snapshot must be a file in the config folder"), nil
+	}
+
+	b, err := ms.service.fs.Get(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read snapshot: %v", err)), nil
+	}
+	ss, err := core.ParseSchemaSnapshot(b)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	changes, err := ms.service.gj.SchemaDrift(ss)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if changes == nil {
+		changes = []core.SchemaChange{}
+	}
+
+	result := SchemaDriftResult{
+		Snapshot:  name,
+		CreatedAt: ss.CreatedAt.Format(time.RFC3339),
+		Drift:     len(changes) != 0,
+		Changes:   changes,
+	}
+	return ms.toolResultJSON("check_schema_drift", args, result)
+}
@@ -0,0 +1,90 @@
+package serv
+
+import (
+	"context"
+
+	"github.com/dosco/graphjin/core/v3"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerLintTools registers the lint_saved_queries tool
+func (ms *mcpServer) registerLintTools() {
+	if !ms.service.conf.MCP.AllowDevTools {
+		return
+	}
+
+	ms.srv.AddTool(mcp.NewTool(
+		"lint_saved_queries",
+		mcp.WithDescription("Check every saved query against the live schema and role config WITHOUT executing it. "+
+			"Returns findings for unknown columns and tables, filters on columns without an index, "+
+			"selects without a limit, roles the query is blocked for and cross-database joins. "+
+			"Each finding includes a suggested rewrite (add a limit, add an index or filter on an indexed column). "+
+			"Use before deploying to fix queries broken by schema or role changes."),
+		mcp.WithString("name",
+			mcp.Description("Optional saved query name to lint. Omit to lint all saved queries."),
+		),
+		mcp.WithString("severity",
+			mcp.Description("Optional minimum severity to return: 'error', 'warning' or 'info' (default)"),
+		),
+	), ms.handleLintSavedQueries)
+}
+
+// LintResult represents the lint_saved_queries response
+type LintResult struct {
+	Findings []core.LintFinding `json:"findings"`
+	Count    int                `json:"count"`
+	Errors   int                `json:"errors"`
+	Warnings int                `json:"warnings"`
+}
+
+// lintSeverityRank orders the lint severities
+var lintSeverityRank = map[string]int{
+	core.LintSeverityInfo:    0,
+	core.LintSeverityWarning: 1,
+	core.LintSeverityError:   2,
+}
+
+// handleLintSavedQueries lints the saved queries
+func (ms *mcpServer) handleLintSavedQueries(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := ms.requireDB(); err != nil {
+		return err, nil
+	}
+
+	args := req.GetArguments()
+	name, _ := args["name"].(string)
+	severity, _ := args["severity"].(string)
+
+	minRank := 0
+	if severity != "" {
+		rank, ok := lintSeverityRank[severity]
+		if !ok {
+			return mcp.NewToolResultError("severity must be one of: error, warning, info"), nil
+		}
+		minRank = rank
+	}
+
+	findings, err := ms.service.gj.Lint()
+	if err != nil {
+		return mcp.NewToolResultError("lint failed: " + err.Error()), nil
+	}
+
+	result := LintResult{Findings: []core.LintFinding{}}
+	for _, f := range findings {
+		if name != "" && f.Query != name {
+			continue
+		}
+		if lintSeverityRank[f.Severity] < minRank {
+			continue
+		}
+		switch f.Severity {
+		case core.LintSeverityError:
+			result.Errors++
+		case core.LintSeverityWarning:
+			result.Warnings++
+		}
+		result.Findings = append(result.Findings, f)
+	}
+	result.Count = len(result.Findings)
+
+	return ms.toolResultJSON("lint_saved_queries", args, result)
+}
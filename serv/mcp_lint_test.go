@@ -0,0 +1,61 @@
+package serv
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHandleLintSavedQueries(t *testing.T) {
+	ms := newSQLiteReadyMCPServer(t, map[string]string{
+		"activeUsers": "query activeUsers { users(where: { active: { eq: true } }) { id name } }",
+		"userWeights": "query userWeights { users(limit: 5) { id weight } }",
+	}, nil)
+
+	res, err := ms.handleLintSavedQueries(context.Background(), newToolRequest(map[string]any{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := assertToolStructuredMap(t, res)
+	if out["errors"] != float64(1) || out["warnings"] != float64(2) {
+		t.Fatalf("expected 1 error and 2 warnings, got %#v", out)
+	}
+
+	findings, _ := out["findings"].([]any)
+	var suggestions []string
+	for _, v := range findings {
+		f, _ := v.(map[string]any)
+		if s, ok := f["suggestion"].(string); ok {
+			suggestions = append(suggestions, s)
+		}
+	}
+	if !containsAny(suggestions, "users(limit: 20)") || !containsAny(suggestions, "CREATE INDEX users_active_idx") {
+		t.Fatalf("expected limit and index suggestions, got %v", suggestions)
+	}
+
+	res, err = ms.handleLintSavedQueries(context.Background(), newToolRequest(map[string]any{
+		"severity": "error",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out = assertToolStructuredMap(t, res)
+	findings, _ = out["findings"].([]any)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 error finding, got %#v", out["findings"])
+	}
+	if f, _ := findings[0].(map[string]any); f["query"] != "userWeights" ||
+		!strings.Contains(f["message"].(string), "weight") {
+		t.Fatalf("expected the unknown column in userWeights, got %#v", f)
+	}
+
+	res, err = ms.handleLintSavedQueries(context.Background(), newToolRequest(map[string]any{
+		"severity": "fatal",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertToolError(t, res, "severity")
+}
@@ -0,0 +1,74 @@
+package serv
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const defaultSlowQueries = 10
+
+// registerMetricsTools registers the get_metrics tool
+func (ms *mcpServer) registerMetricsTools() {
+	if !ms.service.conf.MCP.AllowDevTools {
+		return
+	}
+
+	ms.srv.AddTool(mcp.NewTool(
+		"get_metrics",
+		mcp.WithDescription("Get runtime metrics of the GraphJin service. "+
+			"Returns the response cache hit rate and counters, connection pool statistics "+
+			"for every database, the slowest queries by average response time, and the "+
+			"in-flight and rejected requests of users over their concurrency limit. "+
+			"Use to diagnose why the API is slow."),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of slow queries to return (default: 10)")),
+	), ms.handleGetMetrics)
+}
+
+// MetricsResult represents the get_metrics response
+type MetricsResult struct {
+	Cache       *CacheMetricsInfo         `json:"cache,omitempty"`
+	Pools       map[string]*PoolStatsInfo `json:"pools"`
+	SlowQueries []QueryStat               `json:"slow_queries"`
+	UserLimits  []UserLimitStat           `json:"user_limits,omitempty"`
+}
+
+// CacheMetricsInfo represents the response cache metrics
+type CacheMetricsInfo struct {
+	HitRate  float64          `json:"hit_rate"`
+	Counters map[string]int64 `json:"counters"`
+}
+
+// handleGetMetrics returns the cache, pool and query metrics
+func (ms *mcpServer) handleGetMetrics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := req.GetArguments()
+
+	limit := defaultSlowQueries
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	result := MetricsResult{
+		Pools:       make(map[string]*PoolStatsInfo, len(ms.service.dbs)),
+		SlowQueries: ms.service.qstats.slowest(limit),
+		UserLimits:  ms.service.ulimits.stats(),
+	}
+	if result.SlowQueries == nil {
+		result.SlowQueries = []QueryStat{}
+	}
+
+	if ms.service.cache != nil {
+		if m := ms.service.cache.Metrics(); m != nil {
+			result.Cache = &CacheMetricsInfo{HitRate: m.HitRate(), Counters: m.Snapshot()}
+		}
+	}
+
+	for name, db := range ms.service.dbs {
+		if db != nil {
+			result.Pools[name] = poolStatsFromDB(db)
+		}
+	}
+
+	return ms.toolResultJSON("get_metrics", args, result)
+}
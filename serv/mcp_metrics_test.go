@@ -0,0 +1,59 @@
+package serv
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueryStatsSlowest(t *testing.T) {
+	qs := newQueryStats()
+	qs.record("fast", 10*time.Millisecond, false)
+	qs.record("slow", 300*time.Millisecond, false)
+	qs.record("slow", 100*time.Millisecond, true)
+	qs.record("medium", 50*time.Millisecond, false)
+	qs.record("", time.Second, false)
+
+	list := qs.slowest(2)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(list))
+	}
+	if list[0].Query != "slow" || list[1].Query != "medium" {
+		t.Fatalf("expected slow then medium, got %s then %s", list[0].Query, list[1].Query)
+	}
+	st := list[0]
+	if st.Requests != 2 || st.Errors != 1 || st.AvgMs != 200 || st.MaxMs != 300 || st.LastMs != 100 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestHandleGetMetrics(t *testing.T) {
+	ms := mockMcpServerWithConfig(MCPConfig{AllowDevTools: true})
+	ms.service.qstats = newQueryStats()
+	cache, err := NewMemoryCache(CachingConfig{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.service.cache = cache
+	ms.service.qstats.record("getUsers", 20*time.Millisecond, false)
+
+	res, err := ms.handleGetMetrics(context.Background(), newToolRequest(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := assertToolStructuredMap(t, res)
+
+	if _, ok := out["cache"].(map[string]any); !ok {
+		t.Errorf("expected cache metrics, got %v", out["cache"])
+	}
+	if _, ok := out["pools"].(map[string]any); !ok {
+		t.Errorf("expected pool stats, got %v", out["pools"])
+	}
+	slow, ok := out["slow_queries"].([]any)
+	if !ok || len(slow) != 1 {
+		t.Fatalf("expected one slow query, got %v", out["slow_queries"])
+	}
+	if q := slow[0].(map[string]any)["query"]; q != "getUsers" {
+		t.Errorf("expected getUsers, got %v", q)
+	}
+}
@@ -645,6 +645,19 @@ func (ms *mcpServer) nextForExistingToolCall(tool string, args map[string]any, p
 			),
 		})
 
+	case "lint_saved_queries":
+		return ms.newNextGuidance("saved_queries_linted", []NextOption{
+			optionWithTemplate(
+				nextOption("get_saved_query", 1, "Inspect a saved query that has findings before rewriting it.", "Review the query text next to the suggested rewrite.", []string{"name"}, []string{"namespace"}),
+				map[string]any{"name": "<query_name>"},
+			),
+			optionWithTemplate(
+				nextOption("explain_query", 2, "Compile the rewritten query to verify the fix.", "Check the limit and filters in the compiled query.", []string{"query"}, []string{"variables", "role"}),
+				map[string]any{"query": "<rewritten_query>"},
+			),
+			nextOption("audit_role_permissions", 3, "Review role permissions for queries blocked or failing for some roles.", "Compare the findings with the role config.", nil, []string{"role"}),
+		})
+
 	case "audit_role_permissions":
 		return ms.newNextGuidance("role_permissions_audited", []NextOption{
 			optionWithTemplate(
@@ -662,6 +675,25 @@ func (ms *mcpServer) nextForExistingToolCall(tool string, args map[string]any, p
 			nextOption("list_tables", 3, "Inspect tables from the currently active database connection.", "Continue with schema exploration if readiness is already good.", nil, []string{"database"}),
 		})
 
+	case "get_metrics":
+		return ms.newNextGuidance("metrics_reviewed", []NextOption{
+			nextOption("get_saved_query", 1, "Read the slowest saved query before optimizing it.", "Start with the query that has the highest average response time.", []string{"name"}, []string{"namespace"}),
+			nextOption("explain_query", 2, "Inspect the compiled SQL of a slow query.", "Look for missing filters, limits or indexes.", []string{"query"}, []string{"variables", "role"}),
+			nextOption("check_health", 3, "Check the database connection if the pool is saturated.", "Ping latency and pool waits point at database pressure.", nil, nil),
+		})
+
+	case "compare_query_results":
+		result, _ := payload.(CompareResultsResult)
+		if result.Same {
+			return ms.newNextGuidance("results_match", []NextOption{
+				nextOption("compare_query_results", 1, "Compare another saved query across the same databases.", "Cover the remaining saved queries before switching backends.", []string{"name", "left", "right"}, []string{"variables", "namespace"}),
+			})
+		}
+		return ms.newNextGuidance("results_differ", []NextOption{
+			nextOption("explain_query", 1, "Compare the statements compiled for both databases.", "Differences in order or types often come from the generated query.", []string{"query"}, []string{"variables", "role"}),
+			nextOption("describe_table", 2, "Check the column types of the table on both databases.", "Type differences usually point at the schema.", []string{"table"}, []string{"database"}),
+		})
+
 	case "check_health":
 		result, _ := payload.(HealthResult)
 		switch {
@@ -447,6 +447,9 @@ func (ms *mcpServer) handleGetWorkflowGuide(ctx context.Context, req mcp.CallToo
 	if has("explore_relationships") {
 		guide.Tips = append(guide.Tips, "Use explore_relationships to map out the data model neighborhood around any table")
 	}
+	if has("lint_saved_queries") {
+		guide.Tips = append(guide.Tips, "Use lint_saved_queries before deploying to find saved queries broken by schema or role changes")
+	}
 	if has("audit_role_permissions") {
 		guide.Tips = append(guide.Tips, "Use audit_role_permissions to understand what each role can access")
 	}
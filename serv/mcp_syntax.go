@@ -195,7 +195,10 @@ var querySyntaxReference = QuerySyntaxReference{
 		"@schema(name:)":         "Use specific database schema",
 		"@through(table:)":       "Specify join table for many-to-many",
 		"@notRelated":            "Disable automatic relationship detection for a field",
-		"@cacheControl(maxAge:)": "Set cache TTL in seconds for this query",
+		"@cacheControl(maxAge:)": "Set the Cache-Control max-age in seconds for this query",
+		"@cache(ttl:)":           "Set the response cache TTL in seconds for this query, overrides the TTL scaled by query cost",
+		"@skipReturning":         "On a mutation, return only { affected_rows } instead of the selected fields",
+		"@deprecated(sunset:)":   "On a saved query, mark it deprecated with optional sunset (YYYY-MM-DD), replacement and reason; it errors after the sunset date",
 		"@database(name:)":       "Assign table to a named database (REQUIRED on every table when multiple databases are configured). Used in schema definitions, e.g.: type users @database(name: \"mydb\") { ... }",
 	},
 	Variables: VariablesSyntax{
@@ -308,11 +311,13 @@ var mutationSyntaxReference = MutationSyntaxReference{
 		{Description: "Bulk insert", Query: "mutation { products(insert: $items) { id name } }", Variables: `{"items": [{"name": "A", "price": 10}, {"name": "B", "price": 20}]}`},
 		{Description: "Insert with nested create", Query: "mutation { purchases(insert: { quantity: 1, product: { name: $name, price: $price } }) { id } }"},
 		{Description: "Update by ID", Query: "mutation { products(id: $id, update: { price: $price }) { id price } }"},
+		{Description: "Bulk update (each row keyed by its primary key)", Query: "mutation { products(update: $rows) { id price } }", Variables: `{"rows": [{"id": 1, "price": 12}, {"id": 2, "price": 8}]}`},
 		{Description: "Update with where clause", Query: "mutation { products(where: { category: { eq: \"sale\" } }, update: { discount: 10 }) { id } }"},
 		{Description: "Upsert (insert or update)", Query: "mutation { products(upsert: { id: $id, name: $name }) { id name } }"},
 		{Description: "Delete by ID", Query: "mutation { products(delete: true, where: { id: { eq: $id } }) { id } }"},
 		{Description: "Connect existing record", Query: "mutation { products(insert: { name: $name, owner: { connect: { id: $owner_id } } }) { id } }"},
 		{Description: "Disconnect relationship", Query: "mutation { users(id: $id, update: { products: { disconnect: { id: $product_id } } }) { id } }"},
+		{Description: "Connect many-to-many (creates join table rows)", Query: "mutation { products(id: $id, update: { categories: { connect: { id: [1, 2] }, disconnect: { id: 3 } } }) { id } }"},
 	},
 }
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dosco/graphjin/core/v3"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -70,8 +71,9 @@ func (ms *mcpServer) registerExecutionTools() {
 
 // ExecuteResult represents the result of a query execution
 type ExecuteResult struct {
-	Data   json.RawMessage `json:"data"`
-	Errors []ErrorInfo     `json:"errors,omitempty"`
+	Data     json.RawMessage `json:"data"`
+	Errors   []ErrorInfo     `json:"errors,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
 }
 
 // ErrorInfo represents an error from query execution
@@ -124,7 +126,16 @@ func (ms *mcpServer) handleExecuteGraphQL(ctx context.Context, req mcp.CallToolR
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	release, err := ms.service.acquireUser(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	start := time.Now()
 	res, err := ms.service.gj.GraphQL(ctx, query, varsJSON, &rc)
+	release()
+	if res != nil {
+		ms.service.recordQuery(res.QueryName(), time.Since(start), err != nil || len(res.Errors) != 0)
+	}
 
 	result := ExecuteResult{}
 	if err != nil {
@@ -174,7 +185,16 @@ func (ms *mcpServer) handleExecuteSavedQuery(ctx context.Context, req mcp.CallTo
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	release, err := ms.service.acquireRequest(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	start := time.Now()
 	res, err := ms.service.gj.GraphQLByName(ctx, name, varsJSON, &rc)
+	release()
+	if res != nil {
+		ms.service.recordQuery(res.QueryName(), time.Since(start), err != nil || len(res.Errors) != 0)
+	}
 
 	result := ExecuteResult{}
 	if err != nil {
@@ -185,6 +205,9 @@ func (ms *mcpServer) handleExecuteSavedQuery(ctx context.Context, req mcp.CallTo
 		for _, e := range res.Errors {
 			result.Errors = append(result.Errors, ErrorInfo{Message: enhanceError(e.Message, "execute_saved_query")})
 		}
+		if d := res.Deprecation(); d != nil {
+			result.Warnings = append(result.Warnings, d.Message(res.QueryName()))
+		}
 	}
 	return ms.toolResultJSON("execute_saved_query", args, result)
 }
@@ -0,0 +1,250 @@
+package serv
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	routeMode = "/api/v1/admin/mode"
+
+	defaultModeStateFile  = "service_mode.json"
+	defaultMaintenanceMsg = "service is down for maintenance"
+	modeRetryAfter        = "60"
+	errCodeMaintenance    = "MAINTENANCE"
+	errCodeReadOnly       = "READ_ONLY"
+)
+
+// ServiceMode is the read-only and maintenance state of the service
+type ServiceMode struct {
+	// Mutations are rejected while queries keep working
+	ReadOnly bool `json:"read_only"`
+
+	// All API requests are rejected with the maintenance message
+	Maintenance bool `json:"maintenance"`
+
+	// Message returned to clients, defaults to the configured message
+	Message string `json:"message,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// serviceModes holds the current mode and saves it to the config folder so
+// the mode survives restarts
+type serviceModes struct {
+	mu   sync.RWMutex
+	cur  ServiceMode
+	fs   core.FS
+	file string
+}
+
+// modeError is an error in the GraphQL response format with a code in the
+// extensions so clients can tell the modes apart from other errors
+type modeError struct {
+	Message    string            `json:"message"`
+	Extensions map[string]string `json:"extensions"`
+}
+
+// initServiceModes loads the saved mode, a missing file means the service
+// is in the normal mode
+func (s *graphjinService) initServiceModes() error {
+	file := s.conf.Modes.StateFile
+	if file == "" {
+		file = defaultModeStateFile
+	}
+	s.modes = &serviceModes{fs: s.fs, file: file}
+
+	if s.fs == nil {
+		return nil
+	}
+	ok, err := s.fs.Exists(file)
+	if err != nil || !ok {
+		return err
+	}
+	b, err := s.fs.Get(file)
+	if err != nil {
+		return fmt.Errorf("failed to read service mode: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.modes.cur); err != nil {
+		return fmt.Errorf("failed to decode service mode: %w", err)
+	}
+
+	if s.modes.cur.Maintenance {
+		s.log.Warn("service is in maintenance mode")
+	}
+	if s.modes.cur.ReadOnly {
+		s.log.Warn("service is in read-only mode")
+	}
+	return nil
+}
+
+// mode returns the current service mode
+func (s *graphjinService) mode() ServiceMode {
+	if s.modes == nil {
+		return ServiceMode{}
+	}
+	s.modes.mu.RLock()
+	defer s.modes.mu.RUnlock()
+	return s.modes.cur
+}
+
+// setMode changes the service mode and saves it. The mode is applied even
+// when saving fails so an incident can still be handled
+func (s *graphjinService) setMode(m ServiceMode) error {
+	s.modes.mu.Lock()
+	s.modes.cur = m
+	s.modes.mu.Unlock()
+
+	s.applyReadOnly()
+
+	if s.modes.fs == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.modes.fs.Put(s.modes.file, b); err != nil {
+		return fmt.Errorf("failed to save service mode: %w", err)
+	}
+	return nil
+}
+
+// applyReadOnly sets the read-only mode on the query engine, it is called
+// whenever the mode or the engine changes
+func (s *graphjinService) applyReadOnly() {
+	if s.gj != nil {
+		s.gj.SetReadOnly(s.mode().ReadOnly)
+	}
+}
+
+// modeMessage returns the message for clients of the current mode
+func (s *graphjinService) modeMessage(m ServiceMode, fallback string) string {
+	if m.Message != "" {
+		return m.Message
+	}
+	if m.Maintenance && s.conf.Modes.Message != "" {
+		return s.conf.Modes.Message
+	}
+	return fallback
+}
+
+// canChangeMode returns the http status for a caller not allowed to change
+// the service mode or 0 when allowed
+func (s *graphjinService) canChangeMode(r *http.Request) int {
+	return s.adminStatus(r, s.conf.Modes.Roles)
+}
+
+// adminStatus returns the http status for a caller without one of the roles
+// or 0 when allowed, the roles default to admin
+func (s *graphjinService) adminStatus(r *http.Request, roles []string) int {
+	c := r.Context()
+	if c.Value(core.UserIDKey) == nil {
+		return http.StatusUnauthorized
+	}
+	role, ok := c.Value(core.UserRoleKey).(string)
+	if !ok && s.gj != nil {
+		// the role of the caller can come from the roles_query
+		var err error
+		if role, err = s.gj.UserRole(c); err != nil {
+			s.zlog.Error("admin: role of the caller", zap.Error(err))
+			return http.StatusInternalServerError
+		}
+	}
+	if len(roles) == 0 {
+		roles = []string{"admin"}
+	}
+	if slices.Contains(roles, role) {
+		return 0
+	}
+	return http.StatusForbidden
+}
+
+// modeHandler returns and changes the service mode
+// GET /api/v1/admin/mode
+// PUT /api/v1/admin/mode
+func modeHandler(s1 *HttpService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		if code := s.canChangeMode(r); code != 0 {
+			writeJSONError(w, code, http.StatusText(code))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+
+		case http.MethodPut, http.MethodPost:
+			var m ServiceMode
+
+			b, err := parseBody(r)
+			if err == nil {
+				err = json.Unmarshal(b, &m)
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			m.UpdatedAt = time.Now().UTC()
+			m.UpdatedBy = fmt.Sprint(r.Context().Value(core.UserIDKey))
+
+			if err := s.setMode(m); err != nil {
+				s.log.Warnf("service mode: %s", err)
+			}
+			s.log.Infow("service mode changed",
+				"read_only", m.ReadOnly,
+				"maintenance", m.Maintenance,
+				"user_id", m.UpdatedBy)
+
+		default:
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, s.mode())
+	})
+}
+
+// maintenanceHandler rejects all requests except to the mode route while
+// the service is in maintenance mode
+func maintenanceHandler(s1 *HttpService, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		if m := s.mode(); m.Maintenance && r.URL.Path != routeMode {
+			renderModeErr(w, errCodeMaintenance, s.modeMessage(m, defaultMaintenanceMsg))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// renderReadOnly writes the error for a mutation rejected in read-only mode
+func (s *graphjinService) renderReadOnly(w http.ResponseWriter) {
+	renderModeErr(w, errCodeReadOnly, s.modeMessage(s.mode(), core.ErrReadOnly.Error()))
+}
+
+// renderModeErr writes a 503 response with the error code of the mode
+func renderModeErr(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", modeRetryAfter)
+	w.WriteHeader(http.StatusServiceUnavailable)
+
+	err := json.NewEncoder(w).Encode(map[string][]modeError{
+		"errors": {{Message: msg, Extensions: map[string]string{"code": code}}},
+	})
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", msg, err))
+	}
+}
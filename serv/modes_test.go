@@ -0,0 +1,147 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+func TestServiceModes(t *testing.T) {
+	dir := t.TempDir()
+	zlog := zap.NewNop()
+
+	newService := func() *HttpService {
+		s := &graphjinService{
+			conf: &Config{Modes: ModesConfig{Message: "back soon"}},
+			log:  zlog.Sugar(),
+			zlog: zlog,
+			fs:   core.NewOsFS(dir),
+		}
+		if err := s.initServiceModes(); err != nil {
+			t.Fatal(err)
+		}
+		hs := &HttpService{}
+		hs.Store(s)
+		return hs
+	}
+	hs := newService()
+
+	api := maintenanceHandler(hs, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	mode := maintenanceHandler(hs, modeHandler(hs))
+
+	send := func(h http.Handler, method, path, body, role string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if role != "" {
+			c := context.WithValue(r.Context(), core.UserIDKey, "1")
+			c = context.WithValue(c, core.UserRoleKey, role)
+			r = r.WithContext(c)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := send(mode, "GET", routeMode, "", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", w.Code)
+	}
+	if w := send(mode, "PUT", routeMode, `{"maintenance": true}`, "user"); w.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d", w.Code)
+	}
+
+	w := send(mode, "PUT", routeMode, `{"maintenance": true, "read_only": true}`, "admin")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected ok, got %d: %s", w.Code, w.Body)
+	}
+
+	w = send(api, "POST", routeGraphQL, `{}`, "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected service unavailable, got %d", w.Code)
+	}
+	var res struct {
+		Errors []modeError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Message != "back soon" ||
+		res.Errors[0].Extensions["code"] != errCodeMaintenance {
+		t.Fatalf("unexpected maintenance error: %s", w.Body)
+	}
+
+	// the mode route stays open during maintenance
+	if w := send(mode, "GET", routeMode, "", "admin"); w.Code != http.StatusOK {
+		t.Fatalf("expected ok, got %d", w.Code)
+	}
+
+	// the mode is loaded again on a restart
+	hs = newService()
+	m := hs.Load().(*graphjinService).mode()
+	if !m.Maintenance || !m.ReadOnly || m.UpdatedBy != "1" {
+		t.Fatalf("expected the saved mode, got %+v", m)
+	}
+}
+
+func TestRenderReadOnly(t *testing.T) {
+	s := &graphjinService{conf: &Config{}}
+	w := httptest.NewRecorder()
+	s.renderReadOnly(w)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected service unavailable, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"code":"READ_ONLY"`) ||
+		!strings.Contains(w.Body.String(), core.ErrReadOnly.Error()) {
+		t.Fatalf("unexpected read-only error: %s", w.Body)
+	}
+}
+
+func TestAdminStatusRolesQuery(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	if _, err := db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, is_admin BOOLEAN);
+		INSERT INTO users (id, is_admin) VALUES (1, true), (2, false);`); err != nil {
+		t.Fatal(err)
+	}
+
+	coreConf := core.Config{
+		DBType:     "sqlite",
+		RolesQuery: `SELECT * FROM users WHERE id = $user_id`,
+		Roles:      []core.Role{{Name: "admin", Match: `is_admin = true`}},
+	}
+	gj, err := core.NewGraphJin(&coreConf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zlog := zap.NewNop()
+	s := &graphjinService{gj: gj, conf: &Config{Core: coreConf}, log: zlog.Sugar(), zlog: zlog}
+
+	// the role of the caller comes from the roles query when the auth
+	// handler does not set one
+	send := func(userID int) int {
+		r := httptest.NewRequest("GET", routeMode, nil)
+		c := context.WithValue(r.Context(), core.UserIDKey, userID)
+		return s.adminStatus(r.WithContext(c), nil)
+	}
+	if code := send(1); code != 0 {
+		t.Fatalf("expected the admin user to be allowed, got %d", code)
+	}
+	if code := send(2); code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got %d", code)
+	}
+}
@@ -0,0 +1,148 @@
+package serv
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	routePlayground        = "/playground"
+	routePlaygroundQueries = "/api/v1/playground/queries"
+	routePlaygroundSQL     = "/api/v1/playground/sql"
+)
+
+//go:embed playground.html
+var playgroundHTML string
+
+var playgroundTmpl = template.Must(template.New("playground").Parse(playgroundHTML))
+
+// playgroundQuery is a saved (allow list) query listed in the playground
+type playgroundQuery struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Operation string                 `json:"operation"`
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// playgroundHandler serves the GraphiQL playground page
+func playgroundHandler(s1 *HttpService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := s1.Load().(*graphjinService)
+
+		data := struct {
+			GraphQL string
+			Queries string
+			SQL     string
+		}{
+			GraphQL: routeGraphQL,
+			Queries: routePlaygroundQueries,
+		}
+		if !s.conf.Serv.Production {
+			data.SQL = routePlaygroundSQL
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := playgroundTmpl.Execute(w, data); err != nil {
+			s.zlog.Error("playground", zap.Error(err))
+		}
+	})
+}
+
+// playgroundQueriesHandler lists the saved queries with their query text and
+// variables so they can be run from the playground
+// GET /api/v1/playground/queries
+func playgroundQueriesHandler(s1 *HttpService, ns *string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		s := s1.Load().(*graphjinService)
+
+		items, err := s.gj.ListSavedQueries()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		queries := make([]playgroundQuery, 0, len(items))
+		for _, item := range items {
+			if ns != nil && item.Namespace != *ns {
+				continue
+			}
+			d, err := s.gj.GetSavedQuery(item.Name)
+			if err != nil {
+				continue
+			}
+			queries = append(queries, playgroundQuery{
+				Name:      d.Name,
+				Namespace: d.Namespace,
+				Operation: d.Operation,
+				Query:     d.Query,
+				Variables: d.Variables,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, map[string]interface{}{
+			"queries": queries,
+			"count":   len(queries),
+		})
+	})
+}
+
+// playgroundSQLHandler compiles a query for the role of the request and
+// returns the generated SQL. Only registered outside production
+// POST /api/v1/playground/sql
+func playgroundSQLHandler(s1 *HttpService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		s := s1.Load().(*graphjinService)
+
+		var req struct {
+			Query     string          `json:"query"`
+			Variables json.RawMessage `json:"variables"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReadBytes)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Query == "" {
+			writeJSONError(w, http.StatusBadRequest, "query required")
+			return
+		}
+
+		ex, err := s.gj.ExplainQuery(req.Query, req.Variables, requestRole(r))
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		writeJSON(w, ex)
+	})
+}
+
+// requestRole returns the role set on the request by the auth handler,
+// an empty role lets the compiler pick the default (anon)
+func requestRole(r *http.Request) string {
+	c := r.Context()
+	if v, ok := c.Value(core.UserRoleKey).(string); ok && v != "" {
+		return v
+	}
+	if c.Value(core.UserIDKey) != nil {
+		return "user"
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package serv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlayground(t *testing.T) {
+	handler := newSecuredTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, routePlayground, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the playground page, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `sql: "/api/v1/playground/sql"`) {
+		t.Fatalf("expected the sql endpoint to be set outside production, got %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, routePlaygroundQueries, nil)
+	req.Header.Set("X-Test-Auth", "secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var list struct {
+		Queries []playgroundQuery `json:"queries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Queries) != 1 || list.Queries[0].Name != "listUsers" ||
+		!strings.Contains(list.Queries[0].Query, "users") {
+		t.Fatalf("expected the listUsers saved query, got %s", rec.Body.String())
+	}
+
+	body := strings.NewReader(`{"query": "query { users { id } }"}`)
+	req = httptest.NewRequest(http.MethodPost, routePlaygroundSQL, body)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without auth, got %d", rec.Code)
+	}
+
+	body = strings.NewReader(`{"query": "query { users { id } }"}`)
+	req = httptest.NewRequest(http.MethodPost, routePlaygroundSQL, body)
+	req.Header.Set("X-Test-Auth", "secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var ex struct {
+		CompiledQuery string `json:"compiled_query"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ex); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ex.CompiledQuery, "users") {
+		t.Fatalf("expected generated sql, got %s", rec.Body.String())
+	}
+}
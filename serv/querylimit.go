@@ -0,0 +1,95 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// errQueryBusy is returned when a saved query is at its concurrency limit
+// and its wait queue is full or the wait timed out
+var errQueryBusy = errors.New("too many concurrent requests for query")
+
+// queryLimiter limits the concurrent executions of a saved query
+type queryLimiter struct {
+	slots   chan struct{}
+	timeout time.Duration
+
+	mu        sync.Mutex
+	queued    int
+	maxQueued int
+}
+
+// newQueryLimiters returns the limiters for the saved queries with a
+// concurrency limit set
+func newQueryLimiters(conf map[string]QueryLimit) map[string]*queryLimiter {
+	ql := make(map[string]*queryLimiter, len(conf))
+	for name, c := range conf {
+		if c.MaxConcurrent <= 0 {
+			continue
+		}
+		ql[name] = &queryLimiter{
+			slots:     make(chan struct{}, c.MaxConcurrent),
+			timeout:   c.QueueTimeout,
+			maxQueued: c.MaxQueued,
+		}
+	}
+	return ql
+}
+
+// acquire waits for a free slot and returns the function to release it
+func (l *queryLimiter) acquire(c context.Context) (func(), error) {
+	release := func() { <-l.slots }
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.queued >= l.maxQueued {
+		l.mu.Unlock()
+		return nil, errQueryBusy
+	}
+	l.queued++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, l.timeout)
+		defer cancel()
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-c.Done():
+		return nil, errQueryBusy
+	}
+}
+
+// acquireQuery waits for a slot for the named query, queries without a
+// concurrency limit return right away
+func (s *graphjinService) acquireQuery(c context.Context, name string) (func(), error) {
+	l, ok := s.qlimits[name]
+	if !ok {
+		return func() {}, nil
+	}
+	return l.acquire(c)
+}
+
+// renderBusy responds to requests rejected by the query concurrency limits
+func renderBusy(w http.ResponseWriter, err error) {
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusTooManyRequests)
+	renderErr(w, err)
+}
@@ -0,0 +1,62 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueryLimiter(t *testing.T) {
+	ql := newQueryLimiters(map[string]QueryLimit{
+		"report": {MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: time.Second},
+		"none":   {},
+	})
+	if _, ok := ql["none"]; ok {
+		t.Fatal("expected no limiter without max_concurrent")
+	}
+	l := ql["report"]
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second request waits in the queue for the first to finish
+	done := make(chan error)
+	go func() {
+		r, err := l.acquire(context.Background())
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+
+	for {
+		l.mu.Lock()
+		n := l.queued
+		l.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the queue is full so the third request is rejected
+	if _, err := l.acquire(context.Background()); !errors.Is(err, errQueryBusy) {
+		t.Fatalf("expected the request to be rejected, got %v", err)
+	}
+
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("expected the queued request to run, got %v", err)
+	}
+
+	// a queued request is rejected once its wait times out
+	l.timeout = 10 * time.Millisecond
+	release, _ = l.acquire(context.Background())
+	if _, err := l.acquire(context.Background()); !errors.Is(err, errQueryBusy) {
+		t.Fatalf("expected the queued request to time out, got %v", err)
+	}
+	release()
+}
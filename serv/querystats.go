@@ -0,0 +1,86 @@
+package serv
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueryStat holds the request counts and response times of a query since
+// the service was started or its config reloaded
+type QueryStat struct {
+	Query    string  `json:"query"`
+	Requests int64   `json:"requests"`
+	Errors   int64   `json:"errors"`
+	AvgMs    float64 `json:"avg_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	LastMs   float64 `json:"last_ms"`
+
+	total time.Duration
+}
+
+// queryStats tracks the requests of every named query
+type queryStats struct {
+	mu    sync.Mutex
+	stats map[string]*QueryStat
+}
+
+func newQueryStats() *queryStats {
+	return &queryStats{stats: make(map[string]*QueryStat)}
+}
+
+// record adds a request to the stats of its query
+func (qs *queryStats) record(name string, dur time.Duration, failed bool) {
+	if qs == nil || name == "" {
+		return
+	}
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	st, ok := qs.stats[name]
+	if !ok {
+		st = &QueryStat{Query: name}
+		qs.stats[name] = st
+	}
+	ms := float64(dur.Microseconds()) / 1000
+
+	st.Requests++
+	if failed {
+		st.Errors++
+	}
+	st.total += dur
+	st.AvgMs = float64((st.total / time.Duration(st.Requests)).Microseconds()) / 1000
+	st.MaxMs = max(st.MaxMs, ms)
+	st.LastMs = ms
+}
+
+// slowest returns the stats of the n queries with the highest average
+// response time
+func (qs *queryStats) slowest(n int) []QueryStat {
+	if qs == nil {
+		return nil
+	}
+	qs.mu.Lock()
+	list := make([]QueryStat, 0, len(qs.stats))
+	for _, st := range qs.stats {
+		list = append(list, *st)
+	}
+	qs.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].AvgMs != list[j].AvgMs {
+			return list[i].AvgMs > list[j].AvgMs
+		}
+		return list[i].Query < list[j].Query
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// recordQuery adds a finished request to the query stats and alerts
+func (s *graphjinService) recordQuery(name string, dur time.Duration, failed bool) {
+	s.qstats.record(name, dur, failed)
+	s.alerts.record(name, dur, failed)
+}
@@ -0,0 +1,127 @@
+package serv
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const redacted = "[REDACTED]"
+
+// defaultRedactVars are redacted from the logged variables when
+// log_redact_vars is not set
+var defaultRedactVars = []string{"password", "secret", "token", "key"}
+
+// newRequestLogger returns the structured logger for request log lines
+func newRequestLogger(json bool, output io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if json {
+		return slog.New(slog.NewJSONHandler(output, opts))
+	}
+	return slog.New(slog.NewTextHandler(output, opts))
+}
+
+// reqLog logs the request details with the trace and span of the request
+func (s *graphjinService) reqLog(c context.Context,
+	res *core.Result,
+	rc core.RequestConfig,
+	dur time.Duration,
+	err error,
+) {
+	attrs := make([]slog.Attr, 0, 12)
+
+	if id := requestID(c); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	if sc := trace.SpanContextFromContext(c); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()))
+	}
+
+	if res != nil {
+		attrs = append(attrs,
+			slog.String("op", res.OperationName()),
+			slog.String("name", res.QueryName()),
+			slog.String("role", res.Role()),
+			slog.String("database", res.Database()),
+			slog.Int64("duration_ms", dur.Milliseconds()),
+			slog.Bool("cache_hit", res.CacheHit()))
+	} else {
+		attrs = append(attrs, slog.Int64("duration_ms", dur.Milliseconds()))
+	}
+
+	if ns, ok := rc.GetNamespace(); ok {
+		attrs = append(attrs, slog.String("namespace", ns))
+	}
+
+	if res != nil && res.Vars != nil && s.conf.LogVars {
+		var vars map[string]interface{}
+		if err := json.Unmarshal(res.Vars, &vars); err != nil {
+			attrs = append(attrs, slog.String("vars_error", err.Error()))
+		} else {
+			attrs = append(attrs, slog.Any("vars", s.redactVars(vars)))
+		}
+	}
+
+	if res != nil && res.SQL() != "" && s.logLevel >= logLevelDebug {
+		attrs = append(attrs, slog.String("sql", res.SQL()))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		s.slog.LogAttrs(c, slog.LevelError, "query failed", attrs...)
+	} else {
+		s.slog.LogAttrs(c, slog.LevelInfo, "query", attrs...)
+	}
+}
+
+// redactVars replaces the values of the redacted variables, including keys
+// nested in objects and lists
+func (s *graphjinService) redactVars(vars map[string]interface{}) map[string]interface{} {
+	names := s.conf.LogRedactVars
+	if names == nil {
+		names = defaultRedactVars
+	}
+	return redactValue(vars, names).(map[string]interface{})
+}
+
+func redactValue(v interface{}, names []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, v1 := range v {
+			if isRedacted(k, names) {
+				out[k] = redacted
+			} else {
+				out[k] = redactValue(v1, names)
+			}
+		}
+		return out
+
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, v1 := range v {
+			out[i] = redactValue(v1, names)
+		}
+		return out
+	}
+	return v
+}
+
+func isRedacted(key string, names []string) bool {
+	key = strings.ToLower(key)
+	for _, n := range names {
+		if strings.Contains(key, strings.ToLower(n)) {
+			return true
+		}
+	}
+	return false
+}
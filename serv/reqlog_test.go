@@ -0,0 +1,68 @@
+package serv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestReqLog(t *testing.T) {
+	var buf bytes.Buffer
+	s := &graphjinService{
+		conf:     &Config{},
+		slog:     newRequestLogger(true, &buf),
+		logLevel: logLevelInfo,
+	}
+	s.conf.LogVars = true
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	c := trace.ContextWithSpanContext(context.Background(), sc)
+
+	res := &core.Result{Vars: json.RawMessage(
+		`{"email": "a@b.c", "auth_token": "t1", "data": [{"password": "p1"}]}`)}
+
+	var rc core.RequestConfig
+	rc.SetNamespace("acme")
+	s.reqLog(c, res, rc, 1500*time.Millisecond, errors.New("boom"))
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected a json log line: %s: %s", err, buf.String())
+	}
+
+	exp := map[string]interface{}{
+		"level":       "ERROR",
+		"msg":         "query failed",
+		"trace_id":    sc.TraceID().String(),
+		"span_id":     sc.SpanID().String(),
+		"namespace":   "acme",
+		"duration_ms": float64(1500),
+		"error":       "boom",
+	}
+	for k, v := range exp {
+		if line[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, line[k])
+		}
+	}
+
+	vars, _ := json.Marshal(line["vars"])
+	if v := `{"auth_token":"[REDACTED]","data":[{"password":"[REDACTED]"}],"email":"a@b.c"}`; string(vars) != v {
+		t.Errorf("expected vars %s, got %s", v, vars)
+	}
+
+	// the configured names replace the defaults
+	s.conf.LogRedactVars = []string{"email"}
+	v := s.redactVars(map[string]interface{}{"email": "a@b.c", "token": "t1"})
+	if v["email"] != redacted || v["token"] != "t1" {
+		t.Errorf("unexpected redacted vars: %v", v)
+	}
+}
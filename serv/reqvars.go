@@ -0,0 +1,93 @@
+package serv
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// geoHeaders are the headers set by CDNs and proxies with the location of
+// the client, the first one found is used
+var geoHeaders = map[string][]string{
+	"geo_country": {"CF-IPCountry", "X-Vercel-IP-Country", "CloudFront-Viewer-Country", "X-Country-Code"},
+	"geo_region":  {"CF-Region-Code", "X-Vercel-IP-Country-Region", "CloudFront-Viewer-Country-Region", "X-Region-Code"},
+	"geo_city":    {"CF-IPCity", "X-Vercel-IP-City", "CloudFront-Viewer-City", "X-City"},
+}
+
+// validateRequestVars checks the providers of the request variables
+func validateRequestVars(vars map[string]string) error {
+	for k, v := range vars {
+		switch {
+		case v == "client_ip", v == "user_agent":
+		case strings.HasPrefix(v, "header:") && len(v) > len("header:"):
+		case geoHeaders[v] != nil:
+		default:
+			return fmt.Errorf("request_variables: %s: invalid provider: %s", k, v)
+		}
+	}
+	return nil
+}
+
+// hasRequestVars returns true if any header or request variables or
+// feature flags are configured
+func (s *graphjinService) hasRequestVars() bool {
+	return len(s.conf.HeaderVars) != 0 || len(s.conf.RequestVars) != 0 || s.flags != nil
+}
+
+// setRequestVars sets the request variables, these are set by the server
+// so they take precedence over variables with the same name in the request
+func (s *graphjinService) setRequestVars(r *http.Request, vars map[string]interface{}) {
+	for k, v := range s.conf.RequestVars {
+		vars[k] = func() string {
+			return s.requestVar(r, v)
+		}
+	}
+	s.setFlagVars(vars)
+}
+
+// requestVar returns the value of a request variable provider
+func (s *graphjinService) requestVar(r *http.Request, provider string) string {
+	switch provider {
+	case "client_ip":
+		ip, _ := clientIP(r, s.conf.RateLimiter.IPHeader)
+		return ip
+
+	case "user_agent":
+		return r.UserAgent()
+	}
+
+	if h, ok := strings.CutPrefix(provider, "header:"); ok {
+		return r.Header.Get(h)
+	}
+
+	for _, h := range geoHeaders[provider] {
+		if v := r.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clientIP returns the ip of the client from the ip header (default
+// X-Forwarded-For) or the remote address
+func clientIP(r *http.Request, ipHeader string) (string, error) {
+	var iph string
+
+	if ipHeader != "" {
+		iph = r.Header.Get(ipHeader)
+	} else {
+		iph = r.Header.Get("X-Forwarded-For")
+	}
+
+	if iph != "" {
+		v := strings.Split(iph, ",")
+		if n := len(v); n > 1 {
+			return strings.TrimSpace(v[n-2]), nil
+		}
+		return strings.TrimSpace(v[0]), nil
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	return ip, err
+}
@@ -0,0 +1,116 @@
+package serv
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	core "github.com/dosco/graphjin/core/v3"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestValidateRequestVars(t *testing.T) {
+	ok := map[string]string{
+		"ip":      "client_ip",
+		"agent":   "user_agent",
+		"tenant":  "header:X-Tenant-ID",
+		"country": "geo_country",
+	}
+	if err := validateRequestVars(ok); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"remote_ip", "header:", "geo"} {
+		if err := validateRequestVars(map[string]string{"x": v}); err == nil {
+			t.Fatalf("expected an error for provider %q", v)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	if ip, _ := clientIP(r, ""); ip != "10.0.0.1" {
+		t.Fatalf("expected remote address, got %q", ip)
+	}
+
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 3.3.3.3")
+	if ip, _ := clientIP(r, ""); ip != "2.2.2.2" {
+		t.Fatalf("expected the ip added by the proxy, got %q", ip)
+	}
+
+	r.Header.Set("X-Real-IP", "4.4.4.4")
+	if ip, _ := clientIP(r, "X-Real-IP"); ip != "4.4.4.4" {
+		t.Fatalf("expected the ip header, got %q", ip)
+	}
+}
+
+func TestRequestVars(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT, ip TEXT, agent TEXT, country TEXT)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zap.NewNop()
+	coreConf := core.Config{
+		DBType: "sqlite",
+		RequestVars: map[string]string{
+			"client_ip": "client_ip",
+			"agent":     "user_agent",
+			"country":   "geo_country",
+		},
+	}
+	gj, err := core.NewGraphJin(&coreConf, db,
+		core.OptionSetFS(newAferoFS(afero.NewMemMapFs(), "/")),
+		core.OptionSetDatabases(map[string]*sql.DB{core.DefaultDBName: db}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs := &HttpService{}
+	hs.Store(&graphjinService{
+		gj:     gj,
+		log:    logger.Sugar(),
+		zlog:   logger,
+		conf:   &Config{Core: coreConf},
+		tracer: otel.Tracer("graphjin-reqvars-test"),
+	})
+
+	handler, err := routesHandler(hs, chi.NewRouter(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the client_ip sent in the variables must not override the server value
+	body := `{"query":"mutation { notes(insert: { body: \"hi\", ip: $client_ip, agent: $agent, country: $country }) { id } }",
+		"variables":{"client_ip":"6.6.6.6"}}`
+	req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("CF-IPCountry", "NZ")
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var ip, agent, country string
+	err = db.QueryRow(`SELECT ip, agent, country FROM notes`).Scan(&ip, &agent, &country)
+	if err != nil {
+		t.Fatalf("%v: %s", err, rec.Body.String())
+	}
+	if ip != "10.0.0.1" || agent != "test-agent" || country != "NZ" {
+		t.Fatalf("unexpected values: %q %q %q", ip, agent, country)
+	}
+}
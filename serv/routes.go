@@ -8,9 +8,12 @@ import (
 
 const (
 	routeGraphQL   = "/api/v1/graphql"
+	routeTx        = "/api/v1/graphql/tx"
 	routeREST      = "/api/v1/rest/*"
+	routeCRUD      = "/api/v1/crud/*"
 	routeWorkflows = "/api/v1/workflows/*"
 	routeOpenAPI   = "/api/v1/openapi.json"
+	routeSchemaSDL = "/api/v1/schema.graphql"
 	routeMCP       = "/api/v1/mcp"
 	routeMCPMsg    = "/api/v1/mcp/message"
 	healthRoute    = "/health"
@@ -45,6 +48,17 @@ func routesHandler(s1 *HttpService, mux Mux, ns *string) (http.Handler, error) {
 			s.log.Warn("api: auth.development=true this allows clients to bypass authentication")
 		}
 
+		if s.conf.Auth.Type == "session" && !s.conf.Auth.Development {
+			lh, err := auth.LogoutHandler(s.conf.Auth)
+			if err != nil {
+				s.log.Fatalf("api: error initializing logout handler: %s", err)
+			}
+			mux.Handle(routeLogout, apiV1Handler(s1, ns, lh, nil))
+		}
+
+		mux.Handle(routeMode, apiV1Handler(s1, ns, modeHandler(s1), ah))
+		mux.Handle(routeEffectiveConfig, apiV1Handler(s1, ns, effectiveConfigHandler(s1), ah))
+
 		if s.conf.WebUI {
 			mux.Handle("/*", s1.WebUI("/", routeGraphQL))
 
@@ -59,17 +73,47 @@ func routesHandler(s1 *HttpService, mux Mux, ns *string) (http.Handler, error) {
 			mux.Handle("/api/v1/admin/databases", apiV1Handler(s1, ns, adminDatabasesHandler(s1), ah))
 		}
 
+		if s.conf.Playground {
+			mux.Handle(routePlayground, playgroundHandler(s1))
+			mux.Handle(routePlaygroundQueries, apiV1Handler(s1, ns, playgroundQueriesHandler(s1, ns), ah))
+			if !s.conf.Serv.Production {
+				mux.Handle(routePlaygroundSQL, apiV1Handler(s1, ns, playgroundSQLHandler(s1), ah))
+			}
+		}
+
 		// GraphQL / REST API
 		if ns == nil {
 			mux.Handle(routeGraphQL, s1.GraphQL(ah))
+			mux.Handle(routeTx, s1.Transaction(ah))
 			mux.Handle(routeREST, s1.REST(ah))
 			mux.Handle(routeWorkflows, s1.Workflows(ah))
-			mux.Handle(routeOpenAPI, apiV1Handler(s1, nil, s1.OpenAPI(), ah))
+			if !s.conf.Contracts.Disable {
+				mux.Handle(routeOpenAPI, apiV1Handler(s1, nil, s1.OpenAPI(), ah))
+				mux.Handle(routeSchemaSDL, apiV1Handler(s1, nil, s1.SchemaSDL(), ah))
+			}
+			if s.conf.EnableCRUD {
+				mux.Handle(routeCRUD, s1.CRUD(ah))
+			}
+			if len(s.conf.Core.Approvals.Tables) != 0 {
+				mux.Handle(routeApprovals, apiV1Handler(s1, nil, approvalsHandler(s1, nil), ah))
+				mux.Handle(routeApprovalsAction, apiV1Handler(s1, nil, approvalsHandler(s1, nil), ah))
+			}
 		} else {
 			mux.Handle(routeGraphQL, s1.GraphQLWithNS(ah, *ns))
+			mux.Handle(routeTx, s1.TransactionWithNS(ah, *ns))
 			mux.Handle(routeREST, s1.RESTWithNS(ah, *ns))
 			mux.Handle(routeWorkflows, s1.WorkflowsWithNS(ah, *ns))
-			mux.Handle(routeOpenAPI, apiV1Handler(s1, ns, s1.OpenAPIWithNS(*ns), ah))
+			if !s.conf.Contracts.Disable {
+				mux.Handle(routeOpenAPI, apiV1Handler(s1, ns, s1.OpenAPIWithNS(*ns), ah))
+				mux.Handle(routeSchemaSDL, apiV1Handler(s1, ns, s1.SchemaSDLWithNS(*ns), ah))
+			}
+			if s.conf.EnableCRUD {
+				mux.Handle(routeCRUD, s1.CRUDWithNS(ah, *ns))
+			}
+			if len(s.conf.Core.Approvals.Tables) != 0 {
+				mux.Handle(routeApprovals, apiV1Handler(s1, ns, approvalsHandler(s1, ns), ah))
+				mux.Handle(routeApprovalsAction, apiV1Handler(s1, ns, approvalsHandler(s1, ns), ah))
+			}
 		}
 	}
 
@@ -11,6 +11,7 @@ import (
 	core "github.com/dosco/graphjin/core/v3"
 	"github.com/go-chi/chi/v5"
 	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	_ "modernc.org/sqlite"
 )
@@ -56,12 +57,14 @@ func newSecuredTestHandler(t *testing.T) http.Handler {
 	}
 
 	svc := &graphjinService{
-		gj:   gj,
-		log:  logger.Sugar(),
-		zlog: logger,
+		gj:     gj,
+		log:    logger.Sugar(),
+		zlog:   logger,
+		tracer: otel.Tracer("graphjin-routes-test"),
 		conf: &Config{
 			Serv: Serv{
-				WebUI: true,
+				WebUI:      true,
+				Playground: true,
 				Auth: auth.Auth{
 					Type: "header",
 					Header: struct {
@@ -97,6 +100,7 @@ func TestAdminAndOpenAPIRoutesRequireAuth(t *testing.T) {
 		"/api/v1/admin/queries",
 		"/api/v1/admin/config",
 		"/api/v1/openapi.json",
+		"/api/v1/playground/queries",
 	}
 
 	for _, path := range paths {
@@ -80,12 +80,17 @@ func startHTTP(s1 *HttpService) {
 	}()
 
 	s.srv.RegisterOnShutdown(func() {
+		// change capture reads from the databases closed below
+		s1.stopCDC()
 		if s.closeFn != nil {
 			s.closeFn()
 		}
 		if s.cache != nil {
 			s.cache.Close() //nolint:errcheck
 		}
+		if s.idempotency != nil {
+			s.idempotency.Close() //nolint:errcheck
+		}
 		for name, db := range s.dbs {
 			if db != nil {
 				db.Close() //nolint:errcheck
@@ -167,6 +172,9 @@ func printDevModeInfo(s *graphjinService) {
 	if s.conf.WebUI && !s.conf.MCP.Only {
 		fmt.Printf("  Web UI:      http://%s/\n", displayHost)
 	}
+	if s.conf.Playground && !s.conf.MCP.Only {
+		fmt.Printf("  Playground:  http://%s%s\n", displayHost, routePlayground)
+	}
 	if !s.conf.MCP.Only {
 		fmt.Printf("  GraphQL:     http://%s/api/v1/graphql\n", displayHost)
 		fmt.Printf("  REST API:    http://%s/api/v1/rest/<name>\n", displayHost)
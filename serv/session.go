@@ -0,0 +1,193 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dosco/graphjin/auth/v3"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	routeLogout = "/api/v1/auth/logout"
+
+	defaultSessionPrefix = "session:"
+	defaultSessionTable  = "sessions"
+	sessionRedisTimeout  = 100 * time.Millisecond
+)
+
+// redisSession is a session as stored in Redis, the expiry of the session
+// is the expiry of the key
+type redisSession struct {
+	UserID   json.RawMessage `json:"user_id"`
+	Role     string          `json:"role,omitempty"`
+	Provider string          `json:"provider,omitempty"`
+}
+
+// RedisSessionStore looks up sessions stored by the application in Redis
+// as JSON objects with the fields user_id, role and provider
+type RedisSessionStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisSessionStore creates a session store on an existing Redis client
+func NewRedisSessionStore(client *redis.Client, prefix string) *RedisSessionStore {
+	if prefix == "" {
+		prefix = defaultSessionPrefix
+	}
+	return &RedisSessionStore{client: client, prefix: prefix}
+}
+
+// NewRedisSessionStoreURL creates a session store connected to the Redis URL
+func NewRedisSessionStoreURL(redisURL, prefix string) (*RedisSessionStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	return NewRedisSessionStore(redis.NewClient(opts), prefix), nil
+}
+
+// Get returns the session, nil when the key does not exist
+func (s *RedisSessionStore) Get(ctx context.Context, id string) (*auth.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+
+	pipe := s.client.Pipeline()
+	get := pipe.Get(ctx, s.prefix+id)
+	ttl := pipe.PTTL(ctx, s.prefix+id)
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	b, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var rs redisSession
+	if err := json.Unmarshal(b, &rs); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	sess := &auth.Session{
+		UserID:   sessionUserID(rs.UserID),
+		Role:     rs.Role,
+		Provider: rs.Provider,
+	}
+	// Keys without an expiry never expire
+	if d := ttl.Val(); d > 0 {
+		sess.ExpiresAt = time.Now().Add(d)
+	}
+	return sess, nil
+}
+
+// Touch moves the expiry of the session key
+func (s *RedisSessionStore) Touch(ctx context.Context, id string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+
+	return s.client.PExpireAt(ctx, s.prefix+id, expiresAt).Err()
+}
+
+// Delete removes the session key
+func (s *RedisSessionStore) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionRedisTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, s.prefix+id).Err()
+}
+
+// sessionUserID returns the user id of a session as a string, numeric ids
+// are stored as json numbers
+func sessionUserID(v json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(v, &id); err == nil {
+		return id
+	}
+	var n json.Number
+	if err := json.Unmarshal(v, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// TableSessionStore looks up sessions stored by the application in a
+// database table with the columns id, user_id, role and expires_at
+type TableSessionStore struct {
+	db     *sql.DB
+	table  string
+	dbType string
+}
+
+// NewTableSessionStore creates a session store on a database table
+func NewTableSessionStore(db *sql.DB, dbType, table string) *TableSessionStore {
+	if table == "" {
+		table = defaultSessionTable
+	}
+	return &TableSessionStore{db: db, table: table, dbType: dbType}
+}
+
+// Get returns the session, nil when the row does not exist or has expired
+func (s *TableSessionStore) Get(ctx context.Context, id string) (*auth.Session, error) {
+	var userID, role sql.NullString
+	var expiresAt sql.NullTime
+
+	q := fmt.Sprintf(`SELECT user_id, role, expires_at FROM %s WHERE id = %s`,
+		s.table, s.param(1))
+
+	err := s.db.QueryRowContext(ctx, q, id).Scan(&userID, &role, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	// Expired rows are left to the application to clean up, rows without an
+	// expiry never expire
+	if expiresAt.Valid && time.Now().After(expiresAt.Time) {
+		return nil, nil
+	}
+	return &auth.Session{
+		UserID:    userID.String,
+		Role:      role.String,
+		ExpiresAt: expiresAt.Time,
+	}, nil
+}
+
+// Touch moves the expiry of the session row
+func (s *TableSessionStore) Touch(ctx context.Context, id string, expiresAt time.Time) error {
+	q := fmt.Sprintf(`UPDATE %s SET expires_at = %s WHERE id = %s`,
+		s.table, s.param(1), s.param(2))
+
+	_, err := s.db.ExecContext(ctx, q, expiresAt.UTC(), id)
+	return err
+}
+
+// Delete removes the session row
+func (s *TableSessionStore) Delete(ctx context.Context, id string) error {
+	q := fmt.Sprintf(`DELETE FROM %s WHERE id = %s`, s.table, s.param(1))
+
+	_, err := s.db.ExecContext(ctx, q, id)
+	return err
+}
+
+// param returns the nth placeholder of the database type
+func (s *TableSessionStore) param(n int) string {
+	switch s.dbType {
+	case "postgres", "cockroachdb", "snowflake":
+		return fmt.Sprintf("$%d", n)
+	case "oracle":
+		return fmt.Sprintf(":%d", n)
+	case "mssql":
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
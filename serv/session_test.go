@@ -0,0 +1,78 @@
+package serv
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestTableSessionStore(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.sqlite3"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec(`CREATE TABLE sessions (
+		id TEXT PRIMARY KEY, user_id INTEGER, role TEXT, expires_at DATETIME)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+
+	_, err = db.Exec(`INSERT INTO sessions (id, user_id, role, expires_at) VALUES
+		('live', 7, 'admin', ?), ('old', 8, NULL, ?), ('forever', 9, NULL, NULL)`,
+		now.Add(time.Hour), now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := context.Background()
+	store := NewTableSessionStore(db, "sqlite", "")
+
+	sess, err := store.Get(c, "live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess == nil || sess.UserID != "7" || sess.Role != "admin" {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+
+	sess, err = store.Get(c, "forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess == nil || sess.UserID != "9" || !sess.ExpiresAt.IsZero() {
+		t.Fatalf("expected a session without expiry, got %+v", sess)
+	}
+
+	for _, id := range []string{"old", "missing"} {
+		if sess, err := store.Get(c, id); err != nil || sess != nil {
+			t.Fatalf("%s: expected no session, got %+v %v", id, sess, err)
+		}
+	}
+
+	exp := now.Add(2 * time.Hour)
+	if err := store.Touch(c, "live", exp); err != nil {
+		t.Fatal(err)
+	}
+	if sess, err = store.Get(c, "live"); err != nil {
+		t.Fatal(err)
+	}
+	if d := sess.ExpiresAt.Sub(exp); d > time.Second || d < -time.Second {
+		t.Fatalf("expected the expiry to move to %s, got %s", exp, sess.ExpiresAt)
+	}
+
+	if err := store.Delete(c, "live"); err != nil {
+		t.Fatal(err)
+	}
+	if sess, err := store.Get(c, "live"); err != nil || sess != nil {
+		t.Fatalf("expected the session to be deleted, got %+v %v", sess, err)
+	}
+}
@@ -0,0 +1,126 @@
+package serv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultShadowTimeout       = 10 * time.Second
+	defaultShadowMaxConcurrent = 10
+
+	// maxShadowDiffs bounds the differences logged for a query
+	maxShadowDiffs = 10
+)
+
+// shadower mirrors queries to the shadow database
+type shadower struct {
+	conf ShadowConfig
+	sem  chan struct{}
+}
+
+// newShadower returns nil when no database or percentage is set
+func newShadower(conf ShadowConfig) *shadower {
+	if conf.Database == "" || conf.Percent <= 0 {
+		return nil
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultShadowTimeout
+	}
+	if conf.MaxConcurrent <= 0 {
+		conf.MaxConcurrent = defaultShadowMaxConcurrent
+	}
+	return &shadower{conf: conf, sem: make(chan struct{}, conf.MaxConcurrent)}
+}
+
+// sample returns true for the share of requests that are mirrored
+func (sh *shadower) sample() bool {
+	return sh.conf.Percent >= 100 || rand.Float64()*100 < sh.conf.Percent
+}
+
+// shadowRun runs the request again on the database set in rc
+type shadowRun func(c context.Context, rc *core.RequestConfig) (*core.Result, error)
+
+// shadowQuery mirrors a successful query to the shadow database in the
+// background. Requests sent to a database of their own are not mirrored
+// and neither are new ones while the max concurrent are running.
+func (s *graphjinService) shadowQuery(c context.Context,
+	rc core.RequestConfig,
+	res *core.Result,
+	err error,
+	run shadowRun,
+) {
+	sh := s.shadow
+	if sh == nil || err != nil || res == nil || len(res.Errors) != 0 ||
+		res.Operation() != core.OpQuery {
+		return
+	}
+	if _, ok := rc.GetDatabase(); ok || !sh.sample() {
+		return
+	}
+
+	select {
+	case sh.sem <- struct{}{}:
+	default:
+		return
+	}
+
+	// the request context ends with the response, its values are kept
+	c, cancel := context.WithTimeout(context.WithoutCancel(c), sh.conf.Timeout)
+	rc.SetDatabase(sh.conf.Database)
+
+	go func() {
+		defer func() { <-sh.sem }()
+		defer cancel()
+
+		sres, err := run(c, &rc)
+		if err == nil && sres != nil && len(sres.Errors) != 0 {
+			err = fmt.Errorf("%s", sres.Errors[0].Message)
+		}
+		if err != nil {
+			s.zlog.Warn("shadow query failed",
+				zap.String("query", res.QueryName()),
+				zap.String("database", sh.conf.Database),
+				zap.Error(err))
+			return
+		}
+
+		if diffs := shadowDiffs(res.Data, sres.Data); len(diffs) != 0 {
+			s.zlog.Warn("shadow query diverged",
+				zap.String("query", res.QueryName()),
+				zap.String("database", sh.conf.Database),
+				zap.Strings("diffs", diffs))
+		}
+	}()
+}
+
+// shadowDiffs returns where the shape of the shadow result differs from the
+// result, differing values are expected between databases and skipped
+func shadowDiffs(res, sres json.RawMessage) []string {
+	diffs, err := core.DiffResults(res, sres)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var list []string
+	for _, d := range diffs {
+		if d.Kind == core.DiffValue {
+			continue
+		}
+		if len(list) == maxShadowDiffs {
+			break
+		}
+		v := d.Path + ": " + d.Kind
+		if d.Left != "" || d.Right != "" {
+			v += fmt.Sprintf(" (%s != %s)", d.Left, d.Right)
+		}
+		list = append(list, v)
+	}
+	return list
+}
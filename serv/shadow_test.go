@@ -0,0 +1,43 @@
+package serv
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestShadowDiffs(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		exp  []string
+	}{
+		{"same shape", `{"users":[{"id":1,"name":"a"}]}`, `{"users":[{"id":2,"name":"b"}]}`, nil},
+		{"type", `{"users":[{"id":1}]}`, `{"users":[{"id":"1"}]}`, []string{"$.users[0].id: type (number != string)"}},
+		{"keys", `{"users":{"id":1,"name":"a"}}`, `{"users":{"id":1,"email":"a"}}`,
+			[]string{"$.users.email: extra", "$.users.name: missing"}},
+		{"length", `{"users":[{"id":1},{"id":2}]}`, `{"users":[{"id":1}]}`, []string{"$.users: length (2 != 1)"}},
+		{"order", `{"users":[{"id":1},{"id":2}]}`, `{"users":[{"id":2},{"id":1}]}`, []string{"$.users: order"}},
+		{"null", `{"user":{"id":1}}`, `{"user":null}`, []string{"$.user: type (object != null)"}},
+	}
+
+	for _, tt := range tests {
+		diffs := shadowDiffs(json.RawMessage(tt.a), json.RawMessage(tt.b))
+		if !reflect.DeepEqual(diffs, tt.exp) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.exp, diffs)
+		}
+	}
+}
+
+func TestNewShadower(t *testing.T) {
+	if newShadower(ShadowConfig{Database: "mongo"}) != nil {
+		t.Fatal("expected no shadower without a percentage")
+	}
+	sh := newShadower(ShadowConfig{Database: "mongo", Percent: 100})
+	if sh == nil || sh.conf.Timeout != defaultShadowTimeout || cap(sh.sem) != defaultShadowMaxConcurrent {
+		t.Fatalf("unexpected shadower: %+v", sh)
+	}
+	if !sh.sample() {
+		t.Fatal("expected every request to be sampled at 100 percent")
+	}
+}
@@ -0,0 +1,119 @@
+package serv
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dosco/graphjin/auth/v3"
+	"github.com/dosco/graphjin/core/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type txReq struct {
+	Operations []core.TxRequest `json:"operations"`
+}
+
+type txRes struct {
+	Results []*core.Result `json:"results"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+// Transaction is the http handler for running several operations in a
+// single database transaction
+func (s *HttpService) Transaction(ah auth.HandlerFunc) http.Handler {
+	return apiV1Handler(s, nil, s.apiV1Transaction(nil), ah)
+}
+
+// TransactionWithNS is the namespaced http handler for running several
+// operations in a single database transaction
+func (s *HttpService) TransactionWithNS(ah auth.HandlerFunc, ns string) http.Handler {
+	return apiV1Handler(s, &ns, s.apiV1Transaction(&ns), ah)
+}
+
+// apiV1Transaction handles requests of the form
+// POST /api/v1/graphql/tx {"operations": [{"query": ..., "variables": ...}, {"name": ...}]}
+// all operations are rolled back if any one of them fails
+func (s1 *HttpService) apiV1Transaction(ns *string) http.Handler {
+	dtrace := otel.GetTextMapPropagator()
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		s := s1.Load().(*graphjinService)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			renderErr(w, errors.New("method not allowed"))
+			return
+		}
+
+		ctx, opts := newDTrace(dtrace, r)
+		ctx, span := s.spanStart(ctx, "Transaction Request", opts...)
+		defer span.End()
+
+		var req txReq
+
+		b, err := parseBody(r)
+		if err == nil {
+			err = json.Unmarshal(b, &req)
+		}
+		if err != nil {
+			spanError(span, err)
+			w.WriteHeader(http.StatusBadRequest)
+			renderErr(w, err)
+			return
+		}
+
+		var rc core.RequestConfig
+
+		if s.hasRequestVars() {
+			rc.Vars = s.setHeaderVars(r)
+		}
+
+		if ns != nil {
+			rc.SetNamespace(*ns)
+		}
+
+		if err := s.checkGraphJinInitialized(); err != nil {
+			renderErr(w, err)
+			return
+		}
+
+		res, err := s.gj.GraphQLBatchTx(ctx, req.Operations, &rc)
+		if errors.Is(err, core.ErrReadOnly) {
+			spanError(span, err)
+			s.renderReadOnly(w)
+			return
+		}
+
+		out := txRes{Results: res}
+		if err != nil {
+			out.Errors = []string{err.Error()}
+		}
+		if err := json.NewEncoder(w).Encode(out); err != nil {
+			renderErr(w, err)
+			return
+		}
+
+		if s.logLevel >= logLevelInfo {
+			for _, r1 := range res {
+				s.reqLog(ctx, r1, rc, time.Since(start), err)
+			}
+		}
+
+		if span.IsRecording() {
+			span.SetAttributes(
+				attribute.String("http.path", r.RequestURI),
+				attribute.Int("tx.operations", len(req.Operations)))
+		}
+
+		if err != nil {
+			spanError(span, err)
+		}
+	}
+	return http.HandlerFunc(h)
+}
@@ -0,0 +1,52 @@
+package serv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTransactionRoute(t *testing.T) {
+	handler := newSecuredTestHandler(t)
+
+	body := `{"operations": [
+		{"query": "mutation { users(insert: $data) { id } }", "variables": {"data": {"id": 2, "name": "Grace"}}},
+		{"name": "listUsers"}
+	]}`
+
+	req := httptest.NewRequest(http.MethodPost, routeTx, strings.NewReader(body))
+	req.Header.Set("X-Test-Auth", "secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var res struct {
+		Results []struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"results"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	if len(res.Results) != 2 || !strings.Contains(string(res.Results[1].Data), "Grace") {
+		t.Fatalf("expected the query to see the inserted row, got %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, routeTx, nil)
+	req.Header.Set("X-Test-Auth", "secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rec.Code)
+	}
+}
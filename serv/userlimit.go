@@ -0,0 +1,211 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// errUserBusy is returned when a user is at their in-flight request limit
+// and their wait queue is full or the wait timed out
+var errUserBusy = errors.New("too many concurrent requests for user")
+
+// userLimiter caps the in-flight requests of every authenticated user. Each
+// user waits in a queue of their own so a client flooding the service only
+// waits behind its own requests, and queued requests get a slot in the order
+// they arrived
+type userLimiter struct {
+	max       int
+	maxQueued int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	users    map[string]*userSlots
+	rejected map[string]int64
+
+	rejectedCounter metric.Int64Counter
+}
+
+// userSlots holds the in-flight and queued requests of a user
+type userSlots struct {
+	active  int
+	waiters []chan struct{}
+}
+
+// UserLimitStat holds the in-flight, queued and rejected requests of a user
+type UserLimitStat struct {
+	User     string `json:"user"`
+	InFlight int    `json:"in_flight"`
+	Queued   int    `json:"queued"`
+	Rejected int64  `json:"rejected"`
+}
+
+// newUserLimiter returns the per-user limiter, nil when no limit is set
+func newUserLimiter(conf UserLimit) *userLimiter {
+	if conf.MaxConcurrent <= 0 {
+		return nil
+	}
+	l := &userLimiter{
+		max:       conf.MaxConcurrent,
+		maxQueued: conf.MaxQueued,
+		timeout:   conf.QueueTimeout,
+		users:     make(map[string]*userSlots),
+		rejected:  make(map[string]int64),
+	}
+	l.rejectedCounter, _ = otel.Meter("graphjin.com/serv").Int64Counter(
+		"graphjin.user_limit.rejected",
+		metric.WithDescription("Number of requests rejected by the per-user concurrency limit"))
+	return l
+}
+
+// acquire waits for a free slot of the user and returns the function to
+// release it
+func (l *userLimiter) acquire(c context.Context, user string) (func(), error) {
+	release := func() { l.release(user) }
+
+	l.mu.Lock()
+	u, ok := l.users[user]
+	if !ok {
+		u = &userSlots{}
+		l.users[user] = u
+	}
+	if u.active < l.max && len(u.waiters) == 0 {
+		u.active++
+		l.mu.Unlock()
+		return release, nil
+	}
+	if len(u.waiters) >= l.maxQueued {
+		l.reject(c, user)
+		l.mu.Unlock()
+		return nil, errUserBusy
+	}
+	ready := make(chan struct{})
+	u.waiters = append(u.waiters, ready)
+	l.mu.Unlock()
+
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		c, cancel = context.WithTimeout(c, l.timeout)
+		defer cancel()
+	}
+
+	select {
+	case <-ready:
+		return release, nil
+	case <-c.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, ch := range u.waiters {
+		if ch == ready {
+			u.waiters = append(u.waiters[:i], u.waiters[i+1:]...)
+			l.reject(c, user)
+			return nil, errUserBusy
+		}
+	}
+	// The slot was handed over while the wait timed out
+	return release, nil
+}
+
+// release hands the slot to the first queued request of the user
+func (l *userLimiter) release(user string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u, ok := l.users[user]
+	if !ok {
+		return
+	}
+	if len(u.waiters) != 0 {
+		close(u.waiters[0])
+		u.waiters = u.waiters[1:]
+		return
+	}
+	u.active--
+	if u.active <= 0 {
+		delete(l.users, user)
+	}
+}
+
+// reject counts a rejected request, must be called with the lock held
+func (l *userLimiter) reject(c context.Context, user string) {
+	l.rejected[user]++
+	if l.rejectedCounter != nil {
+		l.rejectedCounter.Add(context.WithoutCancel(c), 1)
+	}
+}
+
+// stats returns the requests of the users with in-flight or rejected
+// requests, the users with the most rejections first
+func (l *userLimiter) stats() []UserLimitStat {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	m := make(map[string]*UserLimitStat, len(l.users)+len(l.rejected))
+	get := func(user string) *UserLimitStat {
+		st, ok := m[user]
+		if !ok {
+			st = &UserLimitStat{User: user}
+			m[user] = st
+		}
+		return st
+	}
+	for user, u := range l.users {
+		st := get(user)
+		st.InFlight = u.active
+		st.Queued = len(u.waiters)
+	}
+	for user, n := range l.rejected {
+		get(user).Rejected = n
+	}
+	l.mu.Unlock()
+
+	list := make([]UserLimitStat, 0, len(m))
+	for _, st := range m {
+		list = append(list, *st)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rejected != list[j].Rejected {
+			return list[i].Rejected > list[j].Rejected
+		}
+		return list[i].User < list[j].User
+	})
+	return list
+}
+
+// acquireUser waits for a slot of the authenticated user of the request,
+// anonymous requests and services without a user limit return right away
+func (s *graphjinService) acquireUser(c context.Context) (func(), error) {
+	v := c.Value(core.UserIDKey)
+	if s.ulimits == nil || v == nil {
+		return func() {}, nil
+	}
+	return s.ulimits.acquire(c, fmt.Sprint(v))
+}
+
+// acquireRequest waits for a slot of the user and then of the named query
+func (s *graphjinService) acquireRequest(c context.Context, name string) (func(), error) {
+	releaseUser, err := s.acquireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	releaseQuery, err := s.acquireQuery(c, name)
+	if err != nil {
+		releaseUser()
+		return nil, err
+	}
+	return func() {
+		releaseQuery()
+		releaseUser()
+	}, nil
+}
@@ -0,0 +1,105 @@
+package serv
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dosco/graphjin/core/v3"
+)
+
+func TestUserLimiter(t *testing.T) {
+	l := newUserLimiter(UserLimit{MaxConcurrent: 1, MaxQueued: 2, QueueTimeout: time.Second})
+	if newUserLimiter(UserLimit{}) != nil {
+		t.Fatal("expected no limiter without max_concurrent")
+	}
+	c := context.Background()
+
+	release, err := l.acquire(c, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other users are not affected by alice being at her limit
+	r, err := l.acquire(c, "bob")
+	if err != nil {
+		t.Fatalf("expected bob to get a slot, got %v", err)
+	}
+	r()
+
+	// queued requests get the slot in the order they arrived
+	order := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		go func() {
+			r, err := l.acquire(c, "alice")
+			if err == nil {
+				order <- i
+				r()
+			}
+		}()
+		waitQueued(t, l, "alice", i)
+	}
+
+	// the queue is full so the next request is rejected
+	if _, err := l.acquire(c, "alice"); !errors.Is(err, errUserBusy) {
+		t.Fatalf("expected the request to be rejected, got %v", err)
+	}
+
+	release()
+	if a, b := <-order, <-order; a != 1 || b != 2 {
+		t.Fatalf("expected the queued requests to run in order, got %d, %d", a, b)
+	}
+
+	// a queued request is rejected once its wait times out
+	l.timeout = 10 * time.Millisecond
+	release, _ = l.acquire(c, "alice")
+	if _, err := l.acquire(c, "alice"); !errors.Is(err, errUserBusy) {
+		t.Fatalf("expected the queued request to time out, got %v", err)
+	}
+
+	stats := l.stats()
+	if len(stats) != 1 || stats[0].User != "alice" || stats[0].Rejected != 2 || stats[0].InFlight != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	release()
+}
+
+func TestAcquireUserAnonymous(t *testing.T) {
+	s := &graphjinService{ulimits: newUserLimiter(UserLimit{MaxConcurrent: 1})}
+
+	// anonymous requests are not limited
+	for i := 0; i < 3; i++ {
+		if _, err := s.acquireUser(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := context.WithValue(context.Background(), core.UserIDKey, 5)
+	release, err := s.acquireRequest(c, "getUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.acquireRequest(c, "getUser"); !errors.Is(err, errUserBusy) {
+		t.Fatalf("expected the second request of the user to be rejected, got %v", err)
+	}
+	release()
+}
+
+func waitQueued(t *testing.T, l *userLimiter, user string, n int) {
+	t.Helper()
+	for range 1000 {
+		l.mu.Lock()
+		u := l.users[user]
+		queued := 0
+		if u != nil {
+			queued = len(u.waiters)
+		}
+		l.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued requests", n)
+}
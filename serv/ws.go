@@ -62,13 +62,123 @@ type wsConn struct {
 	sessions  map[string]wsState
 	conn      *websocket.Conn
 	connMutex sync.Mutex
-	done      chan bool
+	done      chan struct{}
+	queue     *wsQueue
+
+	writeTimeout time.Duration
 
 	w  http.ResponseWriter
 	r  *http.Request
 	ah auth.HandlerFunc
 }
 
+const (
+	defaultWSPongTimeout   = 10 * time.Second
+	defaultWSSendQueueSize = 32
+)
+
+// wsQueue is the bounded send queue of a connection. It holds at most one
+// pending update per subscription, a newer update replaces the unsent one.
+type wsQueue struct {
+	mu      sync.Mutex
+	ids     []string
+	msgs    map[string][]byte
+	size    int
+	ready   chan struct{}
+	dropped int
+}
+
+func newWSQueue(size int) *wsQueue {
+	if size <= 0 {
+		size = defaultWSSendQueueSize
+	}
+	return &wsQueue{
+		msgs:  make(map[string][]byte),
+		size:  size,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// push queues a subscription update, it returns false if the update was
+// dropped because the queue is full
+func (q *wsQueue) push(id string, msg []byte) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.msgs[id]; !ok {
+		if len(q.ids) >= q.size {
+			q.dropped++
+			return false
+		}
+		q.ids = append(q.ids, id)
+	}
+	q.msgs[id] = msg
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// pop returns the pending updates in the order they were first queued
+func (q *wsQueue) pop() (msgs [][]byte) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, id := range q.ids {
+		msgs = append(msgs, q.msgs[id])
+		delete(q.msgs, id)
+	}
+	q.ids = q.ids[:0]
+	return
+}
+
+// write writes a message to the connection with the write timeout set
+func (wc *wsConn) write(msg []byte) error {
+	wc.connMutex.Lock()
+	defer wc.connMutex.Unlock()
+
+	if wc.writeTimeout != 0 {
+		wc.conn.SetWriteDeadline(time.Now().Add(wc.writeTimeout)) //nolint:errcheck
+	}
+	return wc.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+// writeLoop sends the queued subscription updates and the keepalive pings
+// till the connection is closed
+func (s *graphjinService) writeLoop(wc *wsConn, pingInterval time.Duration) {
+	var ping <-chan time.Time
+	if pingInterval > 0 {
+		t := time.NewTicker(pingInterval)
+		defer t.Stop()
+		ping = t.C
+	}
+
+	for {
+		select {
+		case <-wc.queue.ready:
+			for _, msg := range wc.queue.pop() {
+				if err := wc.write(msg); err != nil {
+					s.zlog.Error("Subscription", []zapcore.Field{zap.Error(err)}...)
+					wc.conn.Close() //nolint:errcheck
+					return
+				}
+			}
+
+		case <-ping:
+			deadline := time.Now().Add(wc.writeTimeout)
+			if err := wc.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				wc.conn.Close() //nolint:errcheck
+				return
+			}
+
+		case <-wc.done:
+			return
+		}
+	}
+}
+
 type wsState struct {
 	ID   string
 	m    *core.Member
@@ -88,16 +198,35 @@ func (s *graphjinService) apiV1Ws(w http.ResponseWriter, r *http.Request, ah aut
 	defer conn.Close() //nolint:errcheck
 	conn.SetReadLimit(2048)
 
+	wsConf := s.conf.WebSocket
+	if wsConf.PongTimeout <= 0 {
+		wsConf.PongTimeout = defaultWSPongTimeout
+	}
+
 	wc := wsConn{
-		c:        r.Context(),
-		sessions: make(map[string]wsState),
-		conn:     conn,
-		done:     make(chan bool),
-		w:        w,
-		r:        r,
-		ah:       ah,
+		c:            r.Context(),
+		sessions:     make(map[string]wsState),
+		conn:         conn,
+		done:         make(chan struct{}),
+		queue:        newWSQueue(wsConf.SendQueueSize),
+		writeTimeout: wsConf.PongTimeout,
+		w:            w,
+		r:            r,
+		ah:           ah,
+	}
+
+	// The client must reply to a ping (or send a message) within the pong
+	// timeout else the read below fails and the connection is closed
+	if wsConf.PingInterval > 0 {
+		readWait := wsConf.PingInterval + wsConf.PongTimeout
+		conn.SetReadDeadline(time.Now().Add(readWait)) //nolint:errcheck
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(readWait))
+		})
 	}
 
+	go s.writeLoop(&wc, wsConf.PingInterval)
+
 	for {
 		var b []byte
 		var req wsReq
@@ -106,6 +235,10 @@ func (s *graphjinService) apiV1Ws(w http.ResponseWriter, r *http.Request, ah aut
 			break
 		}
 
+		if wsConf.PingInterval > 0 {
+			conn.SetReadDeadline(time.Now().Add(wsConf.PingInterval + wsConf.PongTimeout)) //nolint:errcheck
+		}
+
 		if err = json.Unmarshal(b, &req); err != nil {
 			break
 		}
@@ -121,9 +254,19 @@ func (s *graphjinService) apiV1Ws(w http.ResponseWriter, r *http.Request, ah aut
 	}
 
 	for _, st := range wc.sessions {
-		st.m.Unsubscribe()
+		if st.m != nil {
+			st.m.Unsubscribe()
+		}
+	}
+	close(wc.done)
+
+	wc.queue.mu.Lock()
+	n := wc.queue.dropped
+	wc.queue.mu.Unlock()
+
+	if n != 0 {
+		s.zlog.Warn("Subscription: slow consumer, updates dropped", zap.Int("dropped", n))
 	}
-	wc.done <- true
 }
 
 func (s *graphjinService) checkWebSocketOrigin(r *http.Request) bool {
@@ -137,14 +280,8 @@ func (s *graphjinService) checkWebSocketOrigin(r *http.Request) bool {
 		return false
 	}
 
-	for _, allowed := range s.conf.AllowedOrigins {
-		allowed = strings.TrimSpace(allowed)
-		if allowed == "*" {
-			return true
-		}
-		if normalized, ok := canonicalOrigin(allowed); ok && normalized == origin {
-			return true
-		}
+	if matchOrigin(origin, s.conf.corsConfig().AllowedOrigins) {
+		return true
 	}
 
 	expected, ok := requestOrigin(r)
@@ -218,6 +355,7 @@ func (s *graphjinService) subSwitch(wc *wsConn, req wsReq) (err error) {
 		}
 
 		wc.connMutex.Lock()
+		wc.conn.SetWriteDeadline(time.Now().Add(wc.writeTimeout)) //nolint:errcheck
 		err = wc.conn.WritePreparedMessage(initMsg)
 		wc.connMutex.Unlock()
 
@@ -315,17 +453,12 @@ func (s *graphjinService) waitForData(wc *wsConn, st *wsState, useNext bool) {
 			if err = enc.Encode(res); err != nil {
 				break
 			}
-			msg := buf.Bytes()
+			msg := bytes.Clone(buf.Bytes())
 			buf.Reset()
 
-			wc.connMutex.Lock()
-			err = wc.conn.WriteMessage(websocket.TextMessage, msg)
-			wc.connMutex.Unlock()
-
-			if err != nil {
-				s.zlog.Error("Subscription", []zapcore.Field{zap.Error(err)}...)
-				sendError(wc, st.ID, err) //nolint:errcheck
-				return
+			if !wc.queue.push(st.ID, msg) {
+				s.zlog.Debug("Subscription: send queue full, update dropped",
+					zap.String("id", st.ID))
 			}
 
 		case v := <-st.done:
@@ -333,10 +466,8 @@ func (s *graphjinService) waitForData(wc *wsConn, st *wsState, useNext bool) {
 				return
 			}
 
-		case v := <-wc.done:
-			if v {
-				return
-			}
+		case <-wc.done:
+			return
 		}
 	}
 }
@@ -430,21 +561,18 @@ func (s *graphjinService) waitForDiscoveryData(wc *wsConn, st *wsState, ds *core
 			if err := enc.Encode(res); err != nil {
 				break
 			}
-			msg := buf.Bytes()
+			msg := bytes.Clone(buf.Bytes())
 			buf.Reset()
 
-			wc.connMutex.Lock()
-			err = wc.conn.WriteMessage(websocket.TextMessage, msg)
-			wc.connMutex.Unlock()
-
-			if err != nil {
-				ds.Unsubscribe()
-				return
-			}
+			wc.queue.push(st.ID, msg)
 
 		case <-st.done:
 			ds.Unsubscribe()
 			return
+
+		case <-wc.done:
+			ds.Unsubscribe()
+			return
 		}
 	}
 }
@@ -459,8 +587,5 @@ func sendError(wc *wsConn, id string, cerr error) (err error) {
 		return
 	}
 
-	wc.connMutex.Lock()
-	defer wc.connMutex.Unlock()
-	err = wc.conn.WriteMessage(websocket.TextMessage, msg)
-	return
+	return wc.write(msg)
 }
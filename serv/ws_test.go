@@ -14,13 +14,16 @@ import (
 
 func newWebSocketTestServer(t *testing.T, allowedOrigins []string) *httptest.Server {
 	t.Helper()
+	return newWebSocketTestServerWithConf(t, Serv{AllowedOrigins: allowedOrigins})
+}
+
+func newWebSocketTestServerWithConf(t *testing.T, conf Serv) *httptest.Server {
+	t.Helper()
 
 	logger := zap.NewNop()
 	svc := &graphjinService{
 		conf: &Config{
-			Serv: Serv{
-				AllowedOrigins: allowedOrigins,
-			},
+			Serv: conf,
 		},
 		log:  logger.Sugar(),
 		zlog: logger,
@@ -112,3 +115,61 @@ func TestWebSocketAllowsSameOriginAndNoOrigin(t *testing.T) {
 		})
 	}
 }
+
+func TestWebSocketClosesUnresponsiveClient(t *testing.T) {
+	server := newWebSocketTestServerWithConf(t, Serv{
+		WebSocket: WebSocketConfig{
+			PingInterval: 50 * time.Millisecond,
+			PongTimeout:  50 * time.Millisecond,
+		},
+	})
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	pings := 0
+	conn.SetPingHandler(func(string) error {
+		pings++
+		return nil
+	})
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second)) //nolint:errcheck
+
+	_, _, err = conn.ReadMessage()
+	if err == nil {
+		t.Fatal("expected the connection to be closed")
+	}
+	if ne, ok := err.(interface{ Timeout() bool }); ok && ne.Timeout() {
+		t.Fatal("expected the server to close the connection before the client timeout")
+	}
+	if pings == 0 {
+		t.Fatal("expected a ping before the connection was closed")
+	}
+}
+
+func TestWebSocketQueueCoalescesUpdates(t *testing.T) {
+	q := newWSQueue(2)
+
+	q.push("1", []byte("a"))
+	q.push("2", []byte("b"))
+	q.push("1", []byte("c"))
+
+	if q.push("3", []byte("d")) {
+		t.Fatal("expected the update to be dropped when the queue is full")
+	}
+
+	msgs := q.pop()
+	if len(msgs) != 2 || string(msgs[0]) != "c" || string(msgs[1]) != "b" {
+		t.Fatalf("expected the latest update per subscription, got %q", msgs)
+	}
+	if q.dropped != 1 {
+		t.Fatalf("expected 1 dropped update, got %d", q.dropped)
+	}
+	if !q.push("3", []byte("d")) {
+		t.Fatal("expected the update to be queued after the queue was drained")
+	}
+}
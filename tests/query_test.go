@@ -3,6 +3,7 @@ package tests_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -90,6 +91,198 @@ func Example_queryInTransaction() {
 	// Output: {"products":[{"id":1,"owner":{"fullName":"User 1","id":1}},{"id":2,"owner":{"fullName":"User 2","id":2}},{"id":3,"owner":{"fullName":"User 3","id":3}}]}
 }
 
+func Example_queryWithLockSkipLocked() {
+	// Row locking is only supported on Postgres and MySQL
+	if dbType != "postgres" && dbType != "mysql" {
+		fmt.Println(`{"products":[{"id":1},{"id":2},{"id":3}]}`)
+		fmt.Println(`{"products":[{"id":4},{"id":5}]}`)
+		return
+	}
+
+	gql := `query {
+		products(
+			where: { id: { lt: $max } },
+			order_by: { id: asc },
+			limit: 3,
+			lock: { mode: update, skip_locked: true }) {
+			id
+		}
+	}`
+
+	conf := newConfig(&core.Config{DBType: dbType, DisableAllowList: true})
+	err := conf.AddRoleTable("user", "products", core.Query{AllowLock: true})
+	if err != nil {
+		panic(err)
+	}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	c := context.WithValue(context.Background(), core.UserIDKey, 1)
+
+	// the first worker holds the lock on the rows it claimed while the
+	// second one skips over them
+	tx1, err := db.BeginTx(c, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer tx1.Rollback() //nolint:errcheck
+
+	tx2, err := db.BeginTx(c, nil)
+	if err != nil {
+		panic(err)
+	}
+	defer tx2.Rollback() //nolint:errcheck
+
+	for _, tx := range []*sql.Tx{tx1, tx2} {
+		res, err := gj.GraphQLTx(c, tx, gql, json.RawMessage(`{"max": 6}`), nil)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		printJSON(res.Data)
+	}
+	// Output:
+	// {"products":[{"id":1},{"id":2},{"id":3}]}
+	// {"products":[{"id":4},{"id":5}]}
+}
+
+func Example_queryWithHint() {
+	// Each database has its own hint grammar, Oracle and Snowflake do not
+	// support hints. Without pg_hint_plan Postgres ignores the hint comment
+	var hint string
+	switch dbType {
+	case "postgres":
+		hint = "IndexScan(products)"
+	case "mysql", "mariadb":
+		hint = "USE INDEX (PRIMARY)"
+	case "sqlite":
+		hint = "NOT INDEXED"
+	case "mssql":
+		hint = "MAXDOP 1"
+	case "mongodb":
+		hint = "_id_"
+	default:
+		fmt.Println(`{"products":[{"id":1},{"id":2}]}`)
+		return
+	}
+
+	gql := `query {
+		products(where: { id: { lt: 3 } }, order_by: { id: asc }) @hint(value: "` + hint + `") {
+			id
+		}
+	}`
+
+	conf := newConfig(&core.Config{
+		DBType:           dbType,
+		DisableAllowList: true,
+		Roles:            []core.Role{{Name: "anon", AllowHints: true}},
+	})
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		printJSON(res.Data)
+	}
+	// Output: {"products":[{"id":1},{"id":2}]}
+}
+
+func Example_queryWithDurationFilters() {
+	// MongoDB does not support the duration operators
+	if dbType == "mongodb" {
+		fmt.Println(`{"old":[{"id":1},{"id":2}],"recent":[]}`)
+		return
+	}
+
+	gql := `query {
+		old: products(
+			where: { id: { lt: 3 }, created_at: { older_than: "P7D" } },
+			order_by: { id: asc }) {
+			id
+		}
+		recent: products(where: { id: { lt: 3 }, created_at: { within_last: "P7D" } }) {
+			id
+		}
+	}`
+
+	conf := newConfig(&core.Config{DBType: dbType, DisableAllowList: true})
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		printJSON(res.Data)
+	}
+	// Output: {"old":[{"id":1},{"id":2}],"recent":[]}
+}
+
+func Example_queryWithTimezoneVariable() {
+	// SQLite always returns UTC and MongoDB does not convert timestamps
+	if dbType == "sqlite" || dbType == "mongodb" {
+		fmt.Println("2021-01-09 22:07")
+		return
+	}
+
+	// MySQL needs its timezone tables loaded for named zones and MSSQL
+	// takes Windows timezone names
+	tz := "Asia/Kolkata"
+	switch dbType {
+	case "mysql", "mariadb":
+		tz = "+05:30"
+	case "mssql":
+		tz = "India Standard Time"
+	}
+
+	gql := `query {
+		users(where: { id: { eq: 1 } }) {
+			created_at
+		}
+	}`
+
+	conf := newConfig(&core.Config{
+		DBType:           dbType,
+		DisableAllowList: true,
+		Timezone:         core.TimezoneConfig{Variable: "tz"},
+	})
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	vars := json.RawMessage(`{"tz": "` + tz + `"}`)
+	res, err := gj.GraphQL(context.Background(), gql, vars, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var data struct {
+		Users []struct {
+			CreatedAt string `json:"created_at"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		panic(err)
+	}
+
+	// The timestamp format differs between databases so only the date and
+	// the local time are printed
+	v := data.Users[0].CreatedAt
+	fmt.Println(v[:10], v[11:16])
+	// Output: 2021-01-09 22:07
+}
+
 func Example_queryJSONPathOperations() {
 	// Skip for Snowflake emulator: JSON path helper functions used by the dialect
 	// are not fully implemented by the emulator.
@@ -364,6 +557,59 @@ func Example_queryWithLimitOffsetOrderByDistinctAndWhere() {
 	// Output: {"products":[{"id":99,"name":"Product 99","price":109.5},{"id":98,"name":"Product 98","price":108.5},{"id":97,"name":"Product 97","price":107.5},{"id":96,"name":"Product 96","price":106.5},{"id":95,"name":"Product 95","price":105.5}]}
 }
 
+func Example_queryWithDistinctKeepsFields() {
+	gql := `query {
+		products(
+			distinct: [ country_code ],
+			order_by: { price: desc },
+			where: { id: { lt: 10 } }) {
+			id
+			name
+			country_code
+		}
+	}`
+
+	conf := newConfig(&core.Config{DBType: dbType, DisableAllowList: true})
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		printJSON(res.Data)
+	}
+	// Output: {"products":[{"country_code":"US","id":9,"name":"Product 9"}]}
+}
+
+func Example_queryWithCaseInsensitiveOrderAndFilter() {
+	gql := `query {
+		products(where: { id: { lt: 13 } }, order_by: { name: desc_ci }, limit: 3) {
+			id
+		}
+		users(where: { full_name: { eq_ci: "USER 5" } }) {
+			id
+			full_name
+		}
+	}`
+
+	conf := newConfig(&core.Config{DBType: dbType, DisableAllowList: true})
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		printJSON(res.Data)
+	}
+	// Output: {"products":[{"id":9},{"id":8},{"id":7}],"users":[{"full_name":"User 5","id":5}]}
+}
+
 func Example_queryWithWhere1() {
 	gql := `query {
 		products(where: {
@@ -1560,6 +1806,53 @@ func TestQueryWithJsonColumn(t *testing.T) {
 	}
 }
 
+func Example_queryWithEmbeddedJSONTableFilter() {
+	// The JSON column is unpacked with jsonb_to_recordset, JSON_TABLE or OPENJSON
+	// so the embedded table can be filtered like a real one
+	switch dbType {
+	case "postgres", "mysql", "mariadb", "mssql":
+	default:
+		fmt.Println(`{"users":{"category_counts":[{"category_id":2,"count":600}],"id":1}}`)
+		return
+	}
+
+	gql := `query {
+		users(id: 1) {
+			id
+			category_counts(where: { count: { gt: 500 } }) {
+				category_id
+				count
+			}
+		}
+	}`
+
+	conf := newConfig(&core.Config{DBType: dbType, DisableAllowList: true})
+	conf.Tables = []core.Table{
+		{
+			Name:  "category_counts",
+			Table: "users",
+			Type:  "json",
+			Columns: []core.Column{
+				{Name: "category_id", Type: "int", ForeignKey: "categories.id"},
+				{Name: "count", Type: "int"},
+			},
+		},
+	}
+
+	gj, err := core.NewGraphJin(conf, db)
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := gj.GraphQL(context.Background(), gql, nil, nil)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		printJSON(res.Data)
+	}
+	// Output: {"users":{"category_counts":[{"category_id":2,"count":600}],"id":1}}
+}
+
 func Example_queryViewByID() {
 	// Skip for MongoDB: hot_products view not set up
 	if dbType == "mongodb" {